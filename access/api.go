@@ -6,6 +6,7 @@ import (
 	"github.com/onflow/flow/protobuf/go/flow/access"
 	"github.com/onflow/flow/protobuf/go/flow/entities"
 
+	"github.com/onflow/flow-go/engine/access/subscription"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
 )
@@ -26,6 +27,9 @@ type API interface {
 	GetCollectionByID(ctx context.Context, id flow.Identifier) (*flow.LightCollection, error)
 
 	SendTransaction(ctx context.Context, tx *flow.TransactionBody) error
+	// SendAndSubscribeTransactionStatuses sends the transaction and streams a TransactionResult for
+	// each status the transaction reaches, until it is sealed or expired.
+	SendAndSubscribeTransactionStatuses(ctx context.Context, tx *flow.TransactionBody) subscription.Subscription
 	GetTransaction(ctx context.Context, id flow.Identifier) (*flow.TransactionBody, error)
 	GetTransactionsByBlockID(ctx context.Context, blockID flow.Identifier) ([]*flow.TransactionBody, error)
 	GetTransactionResult(ctx context.Context, id flow.Identifier) (*TransactionResult, error)
@@ -35,12 +39,25 @@ type API interface {
 	GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error)
 	GetAccountAtLatestBlock(ctx context.Context, address flow.Address) (*flow.Account, error)
 	GetAccountAtBlockHeight(ctx context.Context, address flow.Address, height uint64) (*flow.Account, error)
+	// GetAccountTransactions returns a page of the transactions the account was involved in, ordered
+	// from the most recent to the oldest. The cursor returned with a page is used to request the next.
+	GetAccountTransactions(ctx context.Context, address flow.Address, limit uint32, cursor *flow.AccountTransactionCursor) (*AccountTransactionsPage, error)
 
 	ExecuteScriptAtLatestBlock(ctx context.Context, script []byte, arguments [][]byte) ([]byte, error)
 	ExecuteScriptAtBlockHeight(ctx context.Context, blockHeight uint64, script []byte, arguments [][]byte) ([]byte, error)
 	ExecuteScriptAtBlockID(ctx context.Context, blockID flow.Identifier, script []byte, arguments [][]byte) ([]byte, error)
 
+	// SimulateTransaction executes the transaction against the execution state as of the block,
+	// without submitting it, optionally skipping the signature and sequence number checks.
+	SimulateTransaction(ctx context.Context, tx *flow.TransactionBody, blockID flow.Identifier, skipChecks bool) (*flow.TransactionSimulationResult, error)
+	// EstimateTransactionFees executes the transaction against the execution state as of the latest
+	// sealed block with the maximum gas limit, and returns its resource usage and fees.
+	EstimateTransactionFees(ctx context.Context, tx *flow.TransactionBody) (*flow.TransactionFeesEstimate, error)
+
 	GetEventsForHeightRange(ctx context.Context, eventType string, startHeight, endHeight uint64) ([]flow.BlockEvents, error)
+	// GetEventsForHeightRangePage returns a page of the events in the height range, ordered by block
+	// height, transaction index and event index. The cursor returned with a page is used to request the next.
+	GetEventsForHeightRangePage(ctx context.Context, eventType string, startHeight, endHeight uint64, limit uint32, cursor *EventsCursor) (*EventsPage, error)
 	GetEventsForBlockIDs(ctx context.Context, eventType string, blockIDs []flow.Identifier) ([]flow.BlockEvents, error)
 
 	GetLatestProtocolStateSnapshot(ctx context.Context) ([]byte, error)
@@ -61,6 +78,32 @@ type TransactionResult struct {
 	BlockHeight   uint64
 }
 
+// AccountTransactionsPage is a page of an account's transaction history, ordered from the most
+// recent to the oldest transaction.
+type AccountTransactionsPage struct {
+	Transactions []flow.AccountTransaction
+	// NextCursor is used to request the next page, and is nil if there are no older transactions.
+	NextCursor *flow.AccountTransactionCursor
+}
+
+// EventsCursor identifies the position of an event within a height range. Events are ordered by
+// block height, transaction index and event index.
+type EventsCursor struct {
+	BlockHeight      uint64
+	TransactionIndex uint32
+	EventIndex       uint32
+}
+
+// EventsPage is a page of the events within a height range.
+type EventsPage struct {
+	// BlockEvents contains the events of each block covered by the page. The first and last blocks
+	// may only contain part of their events if the page starts or ends within the block.
+	BlockEvents []flow.BlockEvents
+	// NextCursor is the position of the first event of the next page, and is nil if the page
+	// reaches the end of the range.
+	NextCursor *EventsCursor
+}
+
 func TransactionResultToMessage(result *TransactionResult) *access.TransactionResultResponse {
 	return &access.TransactionResultResponse{
 		Status:        entities.TransactionStatus(result.Status),
@@ -16,6 +16,9 @@ import (
 )
 
 type Handler struct {
+	// methods which aren't served by the access node, e.g. GetNodeVersionInfo, return codes.Unimplemented
+	access.UnimplementedAccessAPIServer
+
 	api                  API
 	chain                flow.Chain
 	signerIndicesDecoder hotstuff.BlockSignerDecoder
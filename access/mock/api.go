@@ -7,6 +7,8 @@ import (
 
 	access "github.com/onflow/flow-go/access"
 
+	subscription "github.com/onflow/flow-go/engine/access/subscription"
+
 	flow "github.com/onflow/flow-go/model/flow"
 
 	mock "github.com/stretchr/testify/mock"
@@ -17,6 +19,29 @@ type API struct {
 	mock.Mock
 }
 
+// EstimateTransactionFees provides a mock function with given fields: ctx, tx
+func (_m *API) EstimateTransactionFees(ctx context.Context, tx *flow.TransactionBody) (*flow.TransactionFeesEstimate, error) {
+	ret := _m.Called(ctx, tx)
+
+	var r0 *flow.TransactionFeesEstimate
+	if rf, ok := ret.Get(0).(func(context.Context, *flow.TransactionBody) *flow.TransactionFeesEstimate); ok {
+		r0 = rf(ctx, tx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*flow.TransactionFeesEstimate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *flow.TransactionBody) error); ok {
+		r1 = rf(ctx, tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExecuteScriptAtBlockHeight provides a mock function with given fields: ctx, blockHeight, script, arguments
 func (_m *API) ExecuteScriptAtBlockHeight(ctx context.Context, blockHeight uint64, script []byte, arguments [][]byte) ([]byte, error) {
 	ret := _m.Called(ctx, blockHeight, script, arguments)
@@ -155,6 +180,29 @@ func (_m *API) GetAccountAtLatestBlock(ctx context.Context, address flow.Address
 	return r0, r1
 }
 
+// GetAccountTransactions provides a mock function with given fields: ctx, address, limit, cursor
+func (_m *API) GetAccountTransactions(ctx context.Context, address flow.Address, limit uint32, cursor *flow.AccountTransactionCursor) (*access.AccountTransactionsPage, error) {
+	ret := _m.Called(ctx, address, limit, cursor)
+
+	var r0 *access.AccountTransactionsPage
+	if rf, ok := ret.Get(0).(func(context.Context, flow.Address, uint32, *flow.AccountTransactionCursor) *access.AccountTransactionsPage); ok {
+		r0 = rf(ctx, address, limit, cursor)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*access.AccountTransactionsPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, flow.Address, uint32, *flow.AccountTransactionCursor) error); ok {
+		r1 = rf(ctx, address, limit, cursor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBlockByHeight provides a mock function with given fields: ctx, height
 func (_m *API) GetBlockByHeight(ctx context.Context, height uint64) (*flow.Block, flow.BlockStatus, error) {
 	ret := _m.Called(ctx, height)
@@ -344,6 +392,29 @@ func (_m *API) GetEventsForHeightRange(ctx context.Context, eventType string, st
 	return r0, r1
 }
 
+// GetEventsForHeightRangePage provides a mock function with given fields: ctx, eventType, startHeight, endHeight, limit, cursor
+func (_m *API) GetEventsForHeightRangePage(ctx context.Context, eventType string, startHeight uint64, endHeight uint64, limit uint32, cursor *access.EventsCursor) (*access.EventsPage, error) {
+	ret := _m.Called(ctx, eventType, startHeight, endHeight, limit, cursor)
+
+	var r0 *access.EventsPage
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint64, uint64, uint32, *access.EventsCursor) *access.EventsPage); ok {
+		r0 = rf(ctx, eventType, startHeight, endHeight, limit, cursor)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*access.EventsPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, uint64, uint64, uint32, *access.EventsCursor) error); ok {
+		r1 = rf(ctx, eventType, startHeight, endHeight, limit, cursor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetExecutionResultByID provides a mock function with given fields: ctx, id
 func (_m *API) GetExecutionResultByID(ctx context.Context, id flow.Identifier) (*flow.ExecutionResult, error) {
 	ret := _m.Called(ctx, id)
@@ -616,6 +687,22 @@ func (_m *API) Ping(ctx context.Context) error {
 	return r0
 }
 
+// SendAndSubscribeTransactionStatuses provides a mock function with given fields: ctx, tx
+func (_m *API) SendAndSubscribeTransactionStatuses(ctx context.Context, tx *flow.TransactionBody) subscription.Subscription {
+	ret := _m.Called(ctx, tx)
+
+	var r0 subscription.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, *flow.TransactionBody) subscription.Subscription); ok {
+		r0 = rf(ctx, tx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(subscription.Subscription)
+		}
+	}
+
+	return r0
+}
+
 // SendTransaction provides a mock function with given fields: ctx, tx
 func (_m *API) SendTransaction(ctx context.Context, tx *flow.TransactionBody) error {
 	ret := _m.Called(ctx, tx)
@@ -630,6 +717,29 @@ func (_m *API) SendTransaction(ctx context.Context, tx *flow.TransactionBody) er
 	return r0
 }
 
+// SimulateTransaction provides a mock function with given fields: ctx, tx, blockID, skipChecks
+func (_m *API) SimulateTransaction(ctx context.Context, tx *flow.TransactionBody, blockID flow.Identifier, skipChecks bool) (*flow.TransactionSimulationResult, error) {
+	ret := _m.Called(ctx, tx, blockID, skipChecks)
+
+	var r0 *flow.TransactionSimulationResult
+	if rf, ok := ret.Get(0).(func(context.Context, *flow.TransactionBody, flow.Identifier, bool) *flow.TransactionSimulationResult); ok {
+		r0 = rf(ctx, tx, blockID, skipChecks)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*flow.TransactionSimulationResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *flow.TransactionBody, flow.Identifier, bool) error); ok {
+		r1 = rf(ctx, tx, blockID, skipChecks)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewAPI interface {
 	mock.TestingT
 	Cleanup(func())
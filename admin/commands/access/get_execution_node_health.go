@@ -0,0 +1,40 @@
+package access
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/admin/commands"
+	"github.com/onflow/flow-go/engine/access/rpc/backend"
+)
+
+var _ commands.AdminCommand = (*GetExecutionNodeHealthCommand)(nil)
+
+// GetExecutionNodeHealthCommand returns the tracked latency, error rate, lag and circuit breaker
+// state of each execution node the access node sent requests to.
+type GetExecutionNodeHealthCommand struct {
+	nodeHealth *backend.ExecutionNodeHealth
+}
+
+// NewGetExecutionNodeHealthCommand creates a new GetExecutionNodeHealthCommand. The tracker is
+// nil if execution node health tracking is disabled, in which case the command fails.
+func NewGetExecutionNodeHealthCommand(nodeHealth *backend.ExecutionNodeHealth) *GetExecutionNodeHealthCommand {
+	return &GetExecutionNodeHealthCommand{
+		nodeHealth: nodeHealth,
+	}
+}
+
+func (c *GetExecutionNodeHealthCommand) Handler(_ context.Context, _ *admin.CommandRequest) (interface{}, error) {
+	if c.nodeHealth == nil {
+		return nil, fmt.Errorf("execution node health tracking is not enabled")
+	}
+
+	return commands.ConvertToInterfaceList(c.nodeHealth.Status())
+}
+
+// Validator validates the request.
+// The command does not take any input.
+func (c *GetExecutionNodeHealthCommand) Validator(_ *admin.CommandRequest) error {
+	return nil
+}
@@ -0,0 +1,44 @@
+package access
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/engine/access/rpc/backend"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestGetExecutionNodeHealth(t *testing.T) {
+	nodeHealth := backend.NewExecutionNodeHealth(backend.DefaultExecutionNodeHealthConfig(), metrics.NewNoopCollector(), zerolog.Nop())
+	nodeID := unittest.IdentifierFixture()
+	nodeHealth.ReportResult(nodeID, 100*time.Millisecond, false, nil)
+
+	cmd := NewGetExecutionNodeHealthCommand(nodeHealth)
+	req := &admin.CommandRequest{}
+	require.NoError(t, cmd.Validator(req))
+
+	result, err := cmd.Handler(context.Background(), req)
+	require.NoError(t, err)
+
+	statuses, ok := result.([]interface{})
+	require.True(t, ok)
+	require.Len(t, statuses, 1)
+
+	status := statuses[0].(map[string]interface{})
+	assert.Equal(t, nodeID.String(), status["node_id"])
+	assert.Equal(t, float64(100*time.Millisecond), status["latency_ns"])
+	assert.Equal(t, false, status["circuit_open"])
+
+	t.Run("tracking disabled", func(t *testing.T) {
+		cmd := NewGetExecutionNodeHealthCommand(nil)
+		_, err := cmd.Handler(context.Background(), req)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,93 @@
+package access
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/admin/commands"
+	"github.com/onflow/flow-go/engine/access/rpc/backend"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+var _ commands.AdminCommand = (*InvalidateScriptCacheCommand)(nil)
+
+// InvalidateScriptCacheCommand removes results from the access node's script result cache, so
+// that subsequent executions are forwarded to execution nodes again.
+type InvalidateScriptCacheCommand struct {
+	cache *backend.ScriptCache
+}
+
+// NewInvalidateScriptCacheCommand creates a new InvalidateScriptCacheCommand. The cache is nil
+// if script result caching is disabled, in which case the command fails.
+func NewInvalidateScriptCacheCommand(cache *backend.ScriptCache) *InvalidateScriptCacheCommand {
+	return &InvalidateScriptCacheCommand{
+		cache: cache,
+	}
+}
+
+type invalidateScriptCacheReq struct {
+	// blockID is the block whose results are removed. If not set, all results are removed.
+	blockID *flow.Identifier
+}
+
+// Handler removes the cached results and returns the number of removed results.
+func (c *InvalidateScriptCacheCommand) Handler(_ context.Context, req *admin.CommandRequest) (interface{}, error) {
+	if c.cache == nil {
+		return nil, fmt.Errorf("script result cache is not enabled")
+	}
+
+	data := req.ValidatorData.(*invalidateScriptCacheReq)
+
+	var removed int
+	if data.blockID != nil {
+		removed = c.cache.Invalidate(*data.blockID)
+		log.Info().Msgf("admintool: invalidated %d script results for block %v", removed, data.blockID)
+	} else {
+		removed = c.cache.InvalidateAll()
+		log.Info().Msgf("admintool: invalidated all %d script results", removed)
+	}
+
+	return map[string]interface{}{
+		"invalidated": removed,
+	}, nil
+}
+
+// Validator validates the request.
+// The request data is optional. If it is provided, it must contain:
+//   - block_id, the hex encoded ID of the block whose results are removed
+//
+// Returns admin.InvalidAdminReqError for invalid/malformed requests.
+func (c *InvalidateScriptCacheCommand) Validator(req *admin.CommandRequest) error {
+	data := &invalidateScriptCacheReq{}
+	req.ValidatorData = data
+
+	if req.Data == nil {
+		return nil
+	}
+
+	input, ok := req.Data.(map[string]interface{})
+	if !ok {
+		return admin.NewInvalidAdminReqFormatError("expected map[string]any")
+	}
+
+	raw, ok := input["block_id"]
+	if !ok {
+		return nil
+	}
+
+	rawBlockID, ok := raw.(string)
+	if !ok {
+		return admin.NewInvalidAdminReqParameterError("block_id", "must be a string", raw)
+	}
+
+	blockID, err := flow.HexStringToIdentifier(rawBlockID)
+	if err != nil {
+		return admin.NewInvalidAdminReqParameterError("block_id", "must be 64-char hex string", raw)
+	}
+	data.blockID = &blockID
+
+	return nil
+}
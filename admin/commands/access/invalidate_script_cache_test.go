@@ -0,0 +1,72 @@
+package access
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/engine/access/rpc/backend"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestInvalidateScriptCache(t *testing.T) {
+	cache, err := backend.NewScriptCache(10, metrics.NewNoopCollector())
+	require.NoError(t, err)
+
+	cmd := NewInvalidateScriptCacheCommand(cache)
+	script := []byte("pub fun main() {}")
+	blockID := unittest.IdentifierFixture()
+	otherBlockID := unittest.IdentifierFixture()
+
+	t.Run("invalid block id", func(t *testing.T) {
+		for _, data := range []interface{}{
+			"foo",
+			map[string]interface{}{"block_id": 1},
+			map[string]interface{}{"block_id": "foo"},
+		} {
+			err := cmd.Validator(&admin.CommandRequest{Data: data})
+			assert.True(t, admin.IsInvalidAdminParameterError(err), "data %v", data)
+		}
+	})
+
+	t.Run("invalidate block", func(t *testing.T) {
+		cache.Add(blockID, script, nil, []byte("1"))
+		cache.Add(otherBlockID, script, nil, []byte("2"))
+
+		req := &admin.CommandRequest{
+			Data: map[string]interface{}{"block_id": blockID.String()},
+		}
+		require.NoError(t, cmd.Validator(req))
+
+		result, err := cmd.Handler(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"invalidated": 1}, result)
+		assert.Equal(t, 1, cache.Len())
+	})
+
+	t.Run("invalidate all", func(t *testing.T) {
+		cache.Add(blockID, script, nil, []byte("1"))
+
+		req := &admin.CommandRequest{}
+		require.NoError(t, cmd.Validator(req))
+
+		result, err := cmd.Handler(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"invalidated": 2}, result)
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("cache disabled", func(t *testing.T) {
+		cmd := NewInvalidateScriptCacheCommand(nil)
+
+		req := &admin.CommandRequest{}
+		require.NoError(t, cmd.Validator(req))
+
+		_, err := cmd.Handler(context.Background(), req)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,106 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/admin/commands"
+	"github.com/onflow/flow-go/engine/execution/pruner"
+)
+
+var _ commands.AdminCommand = (*ExecutionStatePrunerCommand)(nil)
+
+// ExecutionStatePrunerCommand reports the progress of the execution state pruner,
+// and optionally updates its height range target and threshold.
+type ExecutionStatePrunerCommand struct {
+	pruner *pruner.Pruner
+}
+
+// NewExecutionStatePrunerCommand creates a new ExecutionStatePrunerCommand object.
+// The pruner is nil if execution state pruning is disabled.
+func NewExecutionStatePrunerCommand(pruner *pruner.Pruner) *ExecutionStatePrunerCommand {
+	return &ExecutionStatePrunerCommand{
+		pruner: pruner,
+	}
+}
+
+type ExecutionStatePrunerReq struct {
+	heightRangeTarget *uint64
+	threshold         *uint64
+}
+
+// Handler method updates the given pruner parameters, and returns the progress of the pruner.
+// Errors if pruning is disabled, or if updating the parameters fails.
+func (e *ExecutionStatePrunerCommand) Handler(_ context.Context, req *admin.CommandRequest) (interface{}, error) {
+	if e.pruner == nil {
+		return nil, errors.New("execution state pruning is disabled")
+	}
+
+	data := req.ValidatorData.(ExecutionStatePrunerReq)
+
+	if data.heightRangeTarget != nil {
+		err := e.pruner.SetHeightRangeTarget(*data.heightRangeTarget)
+		if err != nil {
+			return nil, fmt.Errorf("could not set height range target: %w", err)
+		}
+		log.Info().Msgf("admintool: execution state pruner height range target set to %d", *data.heightRangeTarget)
+	}
+
+	if data.threshold != nil {
+		err := e.pruner.SetThreshold(*data.threshold)
+		if err != nil {
+			return nil, fmt.Errorf("could not set threshold: %w", err)
+		}
+		log.Info().Msgf("admintool: execution state pruner threshold set to %d", *data.threshold)
+	}
+
+	return commands.ConvertToMap(e.pruner.Progress())
+}
+
+// Validator checks the inputs for ExecutionStatePruner command.
+// It accepts the following optional fields in the Data field of the req object:
+//   - height-range-target, a positive integer
+//   - threshold, a non-negative integer
+//
+// If a float value is provided, only the integer part is used.
+// The following sentinel errors are expected during normal operations:
+// * `admin.InvalidAdminReqError` if any field is in a wrong format
+func (e *ExecutionStatePrunerCommand) Validator(req *admin.CommandRequest) error {
+	data := ExecutionStatePrunerReq{}
+
+	if req.Data == nil {
+		req.ValidatorData = data
+		return nil
+	}
+
+	input, ok := req.Data.(map[string]interface{})
+	if !ok {
+		return admin.NewInvalidAdminReqFormatError("expected map[string]any")
+	}
+
+	if result, ok := input["height-range-target"]; ok {
+		heightRangeTarget, ok := result.(float64)
+		if !ok || heightRangeTarget < 1 {
+			return admin.NewInvalidAdminReqParameterError("height-range-target", "must be number >=1", result)
+		}
+		value := uint64(heightRangeTarget)
+		data.heightRangeTarget = &value
+	}
+
+	if result, ok := input["threshold"]; ok {
+		threshold, ok := result.(float64)
+		if !ok || threshold < 0 {
+			return admin.NewInvalidAdminReqParameterError("threshold", "must be number >=0", result)
+		}
+		value := uint64(threshold)
+		data.threshold = &value
+	}
+
+	req.ValidatorData = data
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package execution
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/engine/execution/pruner"
+	"github.com/onflow/flow-go/engine/execution/pruner/mock"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestExecutionStatePrunerCommandParsing(t *testing.T) {
+	cmd := ExecutionStatePrunerCommand{}
+
+	t.Run("no data", func(t *testing.T) {
+		req := &admin.CommandRequest{}
+
+		err := cmd.Validator(req)
+		require.NoError(t, err)
+
+		parsedReq := req.ValidatorData.(ExecutionStatePrunerReq)
+		require.Nil(t, parsedReq.heightRangeTarget)
+		require.Nil(t, parsedReq.threshold)
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		req := &admin.CommandRequest{
+			Data: map[string]interface{}{
+				"height-range-target": float64(1000), // raw json parses to float64
+				"threshold":           float64(0),
+			},
+		}
+
+		err := cmd.Validator(req)
+		require.NoError(t, err)
+
+		parsedReq := req.ValidatorData.(ExecutionStatePrunerReq)
+		require.Equal(t, uint64(1000), *parsedReq.heightRangeTarget)
+		require.Equal(t, uint64(0), *parsedReq.threshold)
+	})
+
+	t.Run("wrong format", func(t *testing.T) {
+		req := &admin.CommandRequest{
+			Data: "abc",
+		}
+
+		err := cmd.Validator(req)
+		require.True(t, admin.IsInvalidAdminParameterError(err))
+	})
+
+	t.Run("zero height range target", func(t *testing.T) {
+		req := &admin.CommandRequest{
+			Data: map[string]interface{}{
+				"height-range-target": float64(0),
+			},
+		}
+
+		err := cmd.Validator(req)
+		require.True(t, admin.IsInvalidAdminParameterError(err))
+	})
+
+	t.Run("wrong threshold type", func(t *testing.T) {
+		req := &admin.CommandRequest{
+			Data: map[string]interface{}{
+				"threshold": "abc",
+			},
+		}
+
+		err := cmd.Validator(req)
+		require.True(t, admin.IsInvalidAdminParameterError(err))
+	})
+}
+
+func TestExecutionStatePrunerCommand(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		cmd := NewExecutionStatePrunerCommand(nil)
+
+		_, err := cmd.Handler(context.Background(), &admin.CommandRequest{
+			ValidatorData: ExecutionStatePrunerReq{},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("sets values", func(t *testing.T) {
+		storage := mock.NewStorage(t)
+		storage.On("GetPrunedHeight").Return(uint64(5), nil).Once()
+
+		p, err := pruner.NewPruner(
+			zerolog.Nop(),
+			metrics.NewNoopCollector(),
+			storage,
+			20,
+			pruner.WithHeightRangeTarget(100),
+			pruner.WithThreshold(10),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		p.Start(irrecoverable.NewMockSignalerContext(t, ctx))
+		unittest.AssertClosesBefore(t, p.Ready(), time.Second)
+
+		cmd := NewExecutionStatePrunerCommand(p)
+
+		heightRangeTarget := uint64(50)
+		threshold := uint64(20)
+		result, err := cmd.Handler(context.Background(), &admin.CommandRequest{
+			ValidatorData: ExecutionStatePrunerReq{
+				heightRangeTarget: &heightRangeTarget,
+				threshold:         &threshold,
+			},
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]interface{}{
+			"pruned_height":       float64(5),
+			"fulfilled_height":    float64(20),
+			"height_range_target": float64(50),
+			"threshold":           float64(20),
+		}, result)
+
+		cancel()
+		unittest.AssertClosesBefore(t, p.Done(), time.Second)
+	})
+}
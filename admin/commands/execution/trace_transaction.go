@@ -0,0 +1,118 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/admin/commands"
+	"github.com/onflow/flow-go/fvm/tracing"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+var _ commands.AdminCommand = (*TraceTransactionCommand)(nil)
+
+// TransactionTracer replays executed transactions and returns their execution traces.
+type TransactionTracer interface {
+	TraceTransaction(ctx context.Context, blockID flow.Identifier, txID flow.Identifier) (*tracing.ExecutionTrace, error)
+}
+
+// TraceTransactionCommand replays an executed transaction and returns its execution trace,
+// which records the register reads and writes, contract function invocations, emitted events
+// and metering charges of the transaction.
+type TraceTransactionCommand struct {
+	tracer TransactionTracer
+}
+
+// NewTraceTransactionCommand creates a new TraceTransactionCommand object
+func NewTraceTransactionCommand(tracer TransactionTracer) *TraceTransactionCommand {
+	return &TraceTransactionCommand{
+		tracer: tracer,
+	}
+}
+
+type TraceTransactionReq struct {
+	blockID flow.Identifier
+	txID    flow.Identifier
+	format  tracing.Format
+}
+
+// Handler method replays the transaction and returns its execution trace in the requested format.
+// Errors if the block, its collections or the execution state of its parent block are not available.
+func (t *TraceTransactionCommand) Handler(ctx context.Context, req *admin.CommandRequest) (interface{}, error) {
+	data := req.ValidatorData.(TraceTransactionReq)
+
+	trace, err := t.tracer.TraceTransaction(ctx, data.blockID, data.txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace transaction %v in block %v: %w", data.txID, data.blockID, err)
+	}
+
+	if data.format == tracing.FormatChrome {
+		return commands.ConvertToMap(trace.ChromeTrace())
+	}
+	return commands.ConvertToInterfaceList(trace.Entries())
+}
+
+// Validator checks the inputs for TraceTransaction command.
+// It expects the following fields in the Data field of the req object:
+//   - block_id, the hex encoded ID of the block containing the transaction
+//   - transaction_id, the hex encoded ID of the transaction
+//   - format, optional, either "json" (default) or "chrome"
+//
+// The following sentinel errors are expected during normal operations:
+// * `admin.InvalidAdminReqError` if any required field is missing or in a wrong format
+func (t *TraceTransactionCommand) Validator(req *admin.CommandRequest) error {
+	input, ok := req.Data.(map[string]interface{})
+	if !ok {
+		return admin.NewInvalidAdminReqFormatError("expected map[string]any")
+	}
+
+	blockID, err := parseIdentifier(input, "block_id")
+	if err != nil {
+		return err
+	}
+
+	txID, err := parseIdentifier(input, "transaction_id")
+	if err != nil {
+		return err
+	}
+
+	format := tracing.FormatJSON
+	if raw, ok := input["format"]; ok {
+		name, ok := raw.(string)
+		if !ok {
+			return admin.NewInvalidAdminReqParameterError("format", "must be a string", raw)
+		}
+		format, err = tracing.ParseFormat(name)
+		if err != nil {
+			return admin.NewInvalidAdminReqParameterError("format", "must be either 'json' or 'chrome'", raw)
+		}
+	}
+
+	req.ValidatorData = TraceTransactionReq{
+		blockID: blockID,
+		txID:    txID,
+		format:  format,
+	}
+
+	return nil
+}
+
+func parseIdentifier(input map[string]interface{}, field string) (flow.Identifier, error) {
+	raw, ok := input[field]
+	if !ok {
+		return flow.ZeroID, admin.NewInvalidAdminReqErrorf("missing required field: '%s'", field)
+	}
+
+	str, ok := raw.(string)
+	if !ok {
+		return flow.ZeroID, admin.NewInvalidAdminReqParameterError(field, "must be a string", raw)
+	}
+
+	id, err := flow.HexStringToIdentifier(str)
+	if err != nil {
+		return flow.ZeroID, admin.NewInvalidAdminReqParameterError(field, "must be 64-char hex string", raw)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,154 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/fvm/tracing"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+type testTransactionTracer struct {
+	trace *tracing.ExecutionTrace
+	err   error
+}
+
+func (t testTransactionTracer) TraceTransaction(_ context.Context, _ flow.Identifier, _ flow.Identifier) (*tracing.ExecutionTrace, error) {
+	return t.trace, t.err
+}
+
+func TestTraceTransactionCommandParsing(t *testing.T) {
+	cmd := TraceTransactionCommand{}
+
+	blockID := unittest.IdentifierFixture()
+	txID := unittest.IdentifierFixture()
+
+	t.Run("happy path", func(t *testing.T) {
+		req := &admin.CommandRequest{
+			Data: map[string]interface{}{
+				"block_id":       blockID.String(),
+				"transaction_id": txID.String(),
+				"format":         "chrome",
+			},
+		}
+
+		err := cmd.Validator(req)
+		require.NoError(t, err)
+
+		require.Equal(
+			t,
+			TraceTransactionReq{
+				blockID: blockID,
+				txID:    txID,
+				format:  tracing.FormatChrome,
+			},
+			req.ValidatorData)
+	})
+
+	t.Run("default format", func(t *testing.T) {
+		req := &admin.CommandRequest{
+			Data: map[string]interface{}{
+				"block_id":       blockID.String(),
+				"transaction_id": txID.String(),
+			},
+		}
+
+		err := cmd.Validator(req)
+		require.NoError(t, err)
+		require.Equal(t, tracing.FormatJSON, req.ValidatorData.(TraceTransactionReq).format)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		req := &admin.CommandRequest{
+			Data: map[string]interface{}{},
+		}
+
+		err := cmd.Validator(req)
+		require.True(t, admin.IsInvalidAdminParameterError(err))
+	})
+
+	t.Run("invalid block id", func(t *testing.T) {
+		req := &admin.CommandRequest{
+			Data: map[string]interface{}{
+				"block_id":       "abc",
+				"transaction_id": txID.String(),
+			},
+		}
+
+		err := cmd.Validator(req)
+		require.True(t, admin.IsInvalidAdminParameterError(err))
+	})
+
+	t.Run("wrong transaction id type", func(t *testing.T) {
+		req := &admin.CommandRequest{
+			Data: map[string]interface{}{
+				"block_id":       blockID.String(),
+				"transaction_id": float64(1),
+			},
+		}
+
+		err := cmd.Validator(req)
+		require.True(t, admin.IsInvalidAdminParameterError(err))
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		req := &admin.CommandRequest{
+			Data: map[string]interface{}{
+				"block_id":       blockID.String(),
+				"transaction_id": txID.String(),
+				"format":         "xml",
+			},
+		}
+
+		err := cmd.Validator(req)
+		require.True(t, admin.IsInvalidAdminParameterError(err))
+	})
+}
+
+func TestTraceTransactionCommandHandler(t *testing.T) {
+	trace := tracing.NewExecutionTrace()
+	trace.RecordFunctionEnter("f")
+	trace.RecordFunctionExit("f")
+
+	req := &admin.CommandRequest{
+		ValidatorData: TraceTransactionReq{
+			blockID: unittest.IdentifierFixture(),
+			txID:    unittest.IdentifierFixture(),
+			format:  tracing.FormatJSON,
+		},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		cmd := NewTraceTransactionCommand(testTransactionTracer{trace: trace})
+
+		result, err := cmd.Handler(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+	})
+
+	t.Run("chrome", func(t *testing.T) {
+		cmd := NewTraceTransactionCommand(testTransactionTracer{trace: trace})
+
+		chromeReq := &admin.CommandRequest{
+			ValidatorData: TraceTransactionReq{
+				format: tracing.FormatChrome,
+			},
+		}
+
+		result, err := cmd.Handler(context.Background(), chromeReq)
+		require.NoError(t, err)
+		require.Contains(t, result, "traceEvents")
+	})
+
+	t.Run("tracing fails", func(t *testing.T) {
+		cmd := NewTraceTransactionCommand(testTransactionTracer{err: fmt.Errorf("state not available")})
+
+		_, err := cmd.Handler(context.Background(), req)
+		require.Error(t, err)
+	})
+}
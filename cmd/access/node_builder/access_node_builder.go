@@ -21,6 +21,7 @@ import (
 	"github.com/onflow/go-bitswap"
 
 	"github.com/onflow/flow-go/admin/commands"
+	accessCommands "github.com/onflow/flow-go/admin/commands/access"
 	stateSyncCommands "github.com/onflow/flow-go/admin/commands/state_synchronization"
 	storageCommands "github.com/onflow/flow-go/admin/commands/storage"
 	"github.com/onflow/flow-go/cmd"
@@ -41,6 +42,11 @@ import (
 	followereng "github.com/onflow/flow-go/engine/common/follower"
 	"github.com/onflow/flow-go/engine/common/requester"
 	synceng "github.com/onflow/flow-go/engine/common/synchronization"
+	"github.com/onflow/flow-go/engine/execution/computation"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/derived"
+	"github.com/onflow/flow-go/ledger"
+	bootstrapFilenames "github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/module"
@@ -48,6 +54,7 @@ import (
 	"github.com/onflow/flow-go/module/buffer"
 	"github.com/onflow/flow-go/module/chainsync"
 	"github.com/onflow/flow-go/module/compliance"
+	"github.com/onflow/flow-go/module/execution"
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 	finalizer "github.com/onflow/flow-go/module/finalizer/consensus"
 	"github.com/onflow/flow-go/module/id"
@@ -55,6 +62,7 @@ import (
 	"github.com/onflow/flow-go/module/metrics"
 	"github.com/onflow/flow-go/module/metrics/unstaked"
 	"github.com/onflow/flow-go/module/state_synchronization"
+	"github.com/onflow/flow-go/module/state_synchronization/indexer"
 	edrequester "github.com/onflow/flow-go/module/state_synchronization/requester"
 	"github.com/onflow/flow-go/network"
 	netcache "github.com/onflow/flow-go/network/cache"
@@ -116,10 +124,17 @@ type AccessNodeConfig struct {
 	logTxTimeToFinalizedExecuted bool
 	retryEnabled                 bool
 	rpcMetricsEnabled            bool
+	accountTransactionsEnabled   bool
+	scriptResultCacheSize        uint
+	executionNodeHealthEnabled   bool
+	executionNodeHealthConf      backend.ExecutionNodeHealthConfig
 	executionDataSyncEnabled     bool
 	executionDataDir             string
 	executionDataStartHeight     uint64
 	executionDataConfig          edrequester.ExecutionDataConfig
+	executionDataIndexingEnabled bool
+	registersCheckpointFile      string
+	stateStreamConf              state_stream.Config
 	PublicNetworkConfig          PublicNetworkConfig
 }
 
@@ -152,6 +167,8 @@ func DefaultAccessNodeConfig() *AccessNodeConfig {
 			PreferredExecutionNodeIDs: nil,
 			FixedExecutionNodeIDs:     nil,
 			MaxExecutionDataMsgSize:   grpcutils.DefaultMaxMsgSize,
+			CircuitBreakerConfig:      backend.DefaultCircuitBreakerConfig(),
+			HedgeDelay:                0,
 		},
 		ExecutionNodeAddress:         "localhost:9000",
 		logTxTimeToFinalized:         false,
@@ -160,6 +177,10 @@ func DefaultAccessNodeConfig() *AccessNodeConfig {
 		pingEnabled:                  false,
 		retryEnabled:                 false,
 		rpcMetricsEnabled:            false,
+		accountTransactionsEnabled:   false,
+		scriptResultCacheSize:        0,
+		executionNodeHealthEnabled:   false,
+		executionNodeHealthConf:      backend.DefaultExecutionNodeHealthConfig(),
 		nodeInfoFile:                 "",
 		apiRatelimits:                nil,
 		apiBurstlimits:               nil,
@@ -178,6 +199,13 @@ func DefaultAccessNodeConfig() *AccessNodeConfig {
 			RetryDelay:         edrequester.DefaultRetryDelay,
 			MaxRetryDelay:      edrequester.DefaultMaxRetryDelay,
 		},
+		executionDataIndexingEnabled: false,
+		registersCheckpointFile:      "",
+		stateStreamConf: state_stream.Config{
+			ClientSendTimeout:    state_stream.DefaultSendTimeout,
+			ClientSendBufferSize: state_stream.DefaultSendBufferSize,
+			EventFilterConfig:    state_stream.DefaultEventFilterConfig,
+		},
 	}
 }
 
@@ -209,6 +237,11 @@ type FlowAccessNodeBuilder struct {
 	ExecutionDataDownloader    execution_data.Downloader
 	ExecutionDataRequester     state_synchronization.ExecutionDataRequester
 	ExecutionDataStore         execution_data.ExecutionDataStore
+	Registers                  *bstorage.Registers
+	ScriptExecutor             *execution.Scripts
+	AccountTransactions        *bstorage.AccountTransactions
+	ScriptCache                *backend.ScriptCache
+	ExecutionNodeHealth        *backend.ExecutionNodeHealth
 
 	// The sync engine participants provider is the libp2p peer store for the access node
 	// which is not available until after the network has started.
@@ -453,7 +486,70 @@ func (builder *FlowAccessNodeBuilder) BuildExecutionDataRequester() *FlowAccessN
 			blobstore := blobs.NewBlobstore(ds)
 			builder.ExecutionDataStore = execution_data.NewExecutionDataStore(blobstore, execution_data.DefaultSerializer)
 			return nil
-		}).
+		})
+
+	if builder.executionDataIndexingEnabled {
+		builder.
+			Module("register index", func(node *cmd.NodeConfig) error {
+				// uses the datastore's DB
+				registers, err := bstorage.NewRegisters(ds.DB)
+				if err != nil {
+					return fmt.Errorf("could not create register index: %w", err)
+				}
+
+				// the root seal's state commitment is the state after executing the sealed root block,
+				// which is the first height of the index
+				rootHeader, err := node.Storage.Headers.ByBlockID(node.RootSeal.BlockID)
+				if err != nil {
+					return fmt.Errorf("could not get sealed root block header: %w", err)
+				}
+
+				checkpointFile := builder.registersCheckpointFile
+				if checkpointFile == "" {
+					checkpointFile = filepath.Join(node.BootstrapDir, bootstrapFilenames.PathRootCheckpoint)
+				}
+
+				err = indexer.BootstrapFromCheckpoint(
+					node.Logger,
+					registers,
+					checkpointFile,
+					ledger.RootHash(node.RootSeal.FinalState),
+					rootHeader.Height,
+				)
+				if err != nil {
+					return fmt.Errorf("could not bootstrap register index: %w", err)
+				}
+
+				builder.Registers = registers
+				return nil
+			}).
+			Module("local script executor", func(node *cmd.NodeConfig) error {
+				manager, err := computation.New(
+					node.Logger,
+					metrics.NewNoopCollector(),
+					node.Tracer,
+					node.Me,
+					node.State,
+					fvm.NewContext(node.FvmOptions...),
+					nil,
+					nil,
+					nil,
+					computation.ComputationConfig{
+						DerivedDataCacheSize:     derived.DefaultDerivedDataCacheSize,
+						ScriptLogThreshold:       computation.DefaultScriptLogThreshold,
+						ScriptExecutionTimeLimit: computation.DefaultScriptExecutionTimeLimit,
+					},
+				)
+				if err != nil {
+					return fmt.Errorf("could not create computation manager: %w", err)
+				}
+
+				builder.ScriptExecutor = execution.NewScripts(node.Logger, manager, node.Storage.Headers, builder.Registers)
+				return nil
+			})
+	}
+
+	builder.
 		Component("execution data service", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
 
 			opts := []network.BlobServiceOption{
@@ -532,12 +628,49 @@ func (builder *FlowAccessNodeBuilder) BuildExecutionDataRequester() *FlowAccessN
 			return builder.ExecutionDataRequester, nil
 		})
 
+	if builder.executionDataIndexingEnabled {
+		builder.Component("register indexer", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+			registerIndexer := indexer.New(node.Logger, builder.Registers, node.Storage.Headers)
+			builder.ExecutionDataRequester.AddOnExecutionDataFetchedConsumer(registerIndexer.OnExecutionData)
+
+			// the indexer is driven by the requester's notifications and has no lifecycle of its own
+			return &module.NoopReadyDoneAware{}, nil
+		})
+	}
+
+	if builder.accountTransactionsEnabled {
+		builder.Component("account transactions indexer", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+			// transactions that emitted events from an account's contracts are indexed from execution data
+			builder.ExecutionDataRequester.AddOnExecutionDataFetchedConsumer(builder.IngestEng.OnExecutionData)
+
+			// the indexer is driven by the requester's notifications and has no lifecycle of its own
+			return &module.NoopReadyDoneAware{}, nil
+		})
+	}
+
 	if builder.rpcConf.StateStreamListenAddr != "" {
 		builder.Component("exec state stream engine", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
 			conf := state_stream.Config{
 				ListenAddr:              builder.rpcConf.StateStreamListenAddr,
 				MaxExecutionDataMsgSize: builder.rpcConf.MaxExecutionDataMsgSize,
 				RpcMetricsEnabled:       builder.rpcMetricsEnabled,
+				ClientSendTimeout:       builder.stateStreamConf.ClientSendTimeout,
+				ClientSendBufferSize:    builder.stateStreamConf.ClientSendBufferSize,
+				EventFilterConfig:       builder.stateStreamConf.EventFilterConfig,
+			}
+
+			// the requester's initial height is the last processed height, so the first height
+			// with execution data available locally is the next one
+			rootHeight := builder.executionDataConfig.InitialBlockHeight + 1
+
+			// execution data for all heights up to the last notified height is available locally.
+			// if nothing was notified yet, streaming starts at the root height
+			highestAvailableHeight, err := processedNotifications.ProcessedIndex()
+			if err != nil {
+				if !errors.Is(err, storage.ErrNotFound) {
+					return nil, fmt.Errorf("could not get highest notified execution data height: %w", err)
+				}
+				highestAvailableHeight = rootHeight
 			}
 
 			builder.StateStreamEng = state_stream.NewEng(
@@ -550,7 +683,12 @@ func (builder *FlowAccessNodeBuilder) BuildExecutionDataRequester() *FlowAccessN
 				node.RootChainID,
 				builder.apiRatelimits,
 				builder.apiBurstlimits,
+				rootHeight,
+				highestAvailableHeight,
 			)
+
+			builder.ExecutionDataRequester.AddOnExecutionDataFetchedConsumer(builder.StateStreamEng.OnExecutionData)
+
 			return builder.StateStreamEng, nil
 		})
 	}
@@ -596,12 +734,23 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 		flags.IntVar(&builder.rpcConf.MaxExecutionDataMsgSize, "max-block-msg-size", defaultConfig.rpcConf.MaxExecutionDataMsgSize, "maximum size for a gRPC message containing block execution data")
 		flags.StringSliceVar(&builder.rpcConf.PreferredExecutionNodeIDs, "preferred-execution-node-ids", defaultConfig.rpcConf.PreferredExecutionNodeIDs, "comma separated list of execution nodes ids to choose from when making an upstream call e.g. b4a4dbdcd443d...,fb386a6a... etc.")
 		flags.StringSliceVar(&builder.rpcConf.FixedExecutionNodeIDs, "fixed-execution-node-ids", defaultConfig.rpcConf.FixedExecutionNodeIDs, "comma separated list of execution nodes ids to choose from when making an upstream call if no matching preferred execution id is found e.g. b4a4dbdcd443d...,fb386a6a... etc.")
+		flags.BoolVar(&builder.rpcConf.CircuitBreakerConfig.Enabled, "circuit-breaker-enabled", defaultConfig.rpcConf.CircuitBreakerConfig.Enabled, "whether to enable the circuit breakers for requests to collection and execution nodes. Requests to execution nodes are suspended as configured by the execution-node-failure-threshold and execution-node-backoff flags")
+		flags.Uint32Var(&builder.rpcConf.CircuitBreakerConfig.MaxFailures, "circuit-breaker-max-failures", defaultConfig.rpcConf.CircuitBreakerConfig.MaxFailures, "number of consecutive failed requests after which the circuit breaker of a node opens")
+		flags.DurationVar(&builder.rpcConf.CircuitBreakerConfig.RestoreTimeout, "circuit-breaker-restore-timeout", defaultConfig.rpcConf.CircuitBreakerConfig.RestoreTimeout, "duration the circuit breaker of a node stays open before a request is let through to check whether the node recovered")
+		flags.DurationVar(&builder.rpcConf.HedgeDelay, "hedge-delay", defaultConfig.rpcConf.HedgeDelay, "delay after which read-only requests that have not completed are also sent to the next execution node, 0 disables hedged requests e.g. 500ms")
 		flags.BoolVar(&builder.logTxTimeToFinalized, "log-tx-time-to-finalized", defaultConfig.logTxTimeToFinalized, "log transaction time to finalized")
 		flags.BoolVar(&builder.logTxTimeToExecuted, "log-tx-time-to-executed", defaultConfig.logTxTimeToExecuted, "log transaction time to executed")
 		flags.BoolVar(&builder.logTxTimeToFinalizedExecuted, "log-tx-time-to-finalized-executed", defaultConfig.logTxTimeToFinalizedExecuted, "log transaction time to finalized and executed")
 		flags.BoolVar(&builder.pingEnabled, "ping-enabled", defaultConfig.pingEnabled, "whether to enable the ping process that pings all other peers and report the connectivity to metrics")
 		flags.BoolVar(&builder.retryEnabled, "retry-enabled", defaultConfig.retryEnabled, "whether to enable the retry mechanism at the access node level")
 		flags.BoolVar(&builder.rpcMetricsEnabled, "rpc-metrics-enabled", defaultConfig.rpcMetricsEnabled, "whether to enable the rpc metrics")
+		flags.UintVar(&builder.scriptResultCacheSize, "script-result-cache-size", defaultConfig.scriptResultCacheSize, "maximum number of results of scripts executed against sealed blocks to cache, size of 0 disables the cache")
+		flags.BoolVar(&builder.executionNodeHealthEnabled, "execution-node-health-enabled", defaultConfig.executionNodeHealthEnabled, "whether to track the latency, error rate and lag of execution nodes, and send requests to the healthiest execution nodes first")
+		flags.UintVar(&builder.executionNodeHealthConf.FailureThreshold, "execution-node-failure-threshold", defaultConfig.executionNodeHealthConf.FailureThreshold, "number of consecutive failed requests after which requests to an execution node are suspended")
+		flags.DurationVar(&builder.executionNodeHealthConf.Backoff, "execution-node-backoff", defaultConfig.executionNodeHealthConf.Backoff, "duration requests to a failing execution node are first suspended for, doubling on each subsequent failure")
+		flags.DurationVar(&builder.executionNodeHealthConf.MaxBackoff, "execution-node-max-backoff", defaultConfig.executionNodeHealthConf.MaxBackoff, "maximum duration requests to a failing execution node are suspended for")
+		flags.Uint64Var(&builder.executionNodeHealthConf.MaxLag, "execution-node-max-lag", defaultConfig.executionNodeHealthConf.MaxLag, "number of blocks an execution node may lag behind the sealed height before it is deprioritized")
+		flags.BoolVar(&builder.accountTransactionsEnabled, "account-transactions-index-enabled", defaultConfig.accountTransactionsEnabled, "whether to index the transactions each account was involved in and serve account transaction history. transactions that emitted events from an account's contracts are only indexed if execution-data-sync-enabled is set")
 		flags.StringVarP(&builder.nodeInfoFile, "node-info-file", "", defaultConfig.nodeInfoFile, "full path to a json file which provides more details about nodes when reporting its reachability metrics")
 		flags.StringToIntVar(&builder.apiRatelimits, "api-rate-limits", defaultConfig.apiRatelimits, "per second rate limits for Access API methods e.g. Ping=300,GetTransaction=500 etc.")
 		flags.StringToIntVar(&builder.apiBurstlimits, "api-burst-limits", defaultConfig.apiBurstlimits, "burst limits for Access API methods e.g. Ping=100,GetTransaction=100 etc.")
@@ -617,6 +766,17 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 		flags.DurationVar(&builder.executionDataConfig.MaxFetchTimeout, "execution-data-max-fetch-timeout", defaultConfig.executionDataConfig.MaxFetchTimeout, "maximum timeout to use when fetching execution data from the network e.g. 300s")
 		flags.DurationVar(&builder.executionDataConfig.RetryDelay, "execution-data-retry-delay", defaultConfig.executionDataConfig.RetryDelay, "initial delay for exponential backoff when fetching execution data fails e.g. 10s")
 		flags.DurationVar(&builder.executionDataConfig.MaxRetryDelay, "execution-data-max-retry-delay", defaultConfig.executionDataConfig.MaxRetryDelay, "maximum delay for exponential backoff when fetching execution data fails e.g. 5m")
+
+		// Execution data indexing
+		flags.BoolVar(&builder.executionDataIndexingEnabled, "execution-data-indexing-enabled", defaultConfig.executionDataIndexingEnabled, "whether to index registers from execution data and serve scripts and account reads from the local index. requires execution-data-sync-enabled")
+		flags.StringVar(&builder.registersCheckpointFile, "execution-data-indexing-checkpoint-file", defaultConfig.registersCheckpointFile, "checkpoint file used to bootstrap the register index. defaults to the root checkpoint in the bootstrap directory")
+
+		// Execution State Streaming API
+		flags.DurationVar(&builder.stateStreamConf.ClientSendTimeout, "state-stream-send-timeout", defaultConfig.stateStreamConf.ClientSendTimeout, "maximum wait before timing out while sending a response to a streaming client e.g. 30s")
+		flags.UintVar(&builder.stateStreamConf.ClientSendBufferSize, "state-stream-send-buffer-size", defaultConfig.stateStreamConf.ClientSendBufferSize, "maximum number of responses to buffer within a stream")
+		flags.IntVar(&builder.stateStreamConf.EventFilterConfig.MaxEventTypes, "state-stream-max-event-types", defaultConfig.stateStreamConf.EventFilterConfig.MaxEventTypes, "maximum number of event types in a SubscribeEvents filter")
+		flags.IntVar(&builder.stateStreamConf.EventFilterConfig.MaxAddresses, "state-stream-max-addresses", defaultConfig.stateStreamConf.EventFilterConfig.MaxAddresses, "maximum number of addresses in a SubscribeEvents filter")
+		flags.IntVar(&builder.stateStreamConf.EventFilterConfig.MaxContracts, "state-stream-max-contracts", defaultConfig.stateStreamConf.EventFilterConfig.MaxContracts, "maximum number of contracts in a SubscribeEvents filter")
 	}).ValidateFlags(func() error {
 		if builder.supportsObserver && (builder.PublicNetworkConfig.BindAddress == cmd.NotSet || builder.PublicNetworkConfig.BindAddress == "") {
 			return errors.New("public-network-address must be set if supports-observer is true")
@@ -638,6 +798,33 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 				return errors.New("execution-data-max-search-ahead must be greater than 0")
 			}
 		}
+		if builder.executionDataIndexingEnabled {
+			if !builder.executionDataSyncEnabled {
+				return errors.New("execution-data-sync-enabled must be set if execution-data-indexing-enabled is set")
+			}
+			// the register index is bootstrapped at the sealed root block, and must receive execution
+			// data for every height after it
+			if builder.executionDataStartHeight > 0 {
+				return errors.New("execution-data-start-height cannot be set if execution-data-indexing-enabled is set")
+			}
+		}
+		if builder.rpcConf.CircuitBreakerConfig.Enabled {
+			if builder.rpcConf.CircuitBreakerConfig.MaxFailures == 0 {
+				return errors.New("circuit-breaker-max-failures must be greater than 0")
+			}
+			if builder.rpcConf.CircuitBreakerConfig.RestoreTimeout <= 0 {
+				return errors.New("circuit-breaker-restore-timeout must be greater than 0")
+			}
+		}
+		if builder.rpcConf.HedgeDelay < 0 {
+			return errors.New("hedge-delay must not be negative")
+		}
+		if builder.stateStreamConf.ClientSendTimeout <= 0 {
+			return errors.New("state-stream-send-timeout must be greater than 0")
+		}
+		if builder.stateStreamConf.ClientSendBufferSize == 0 {
+			return errors.New("state-stream-send-buffer-size must be greater than 0")
+		}
 
 		return nil
 	})
@@ -834,6 +1021,12 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 			builder.BlocksToMarkExecuted, err = stdmap.NewTimes(1 * 300) // assume 1 block per second * 300 seconds
 			return err
 		}).
+		Module("account transactions index", func(node *cmd.NodeConfig) error {
+			if builder.accountTransactionsEnabled {
+				builder.AccountTransactions = bstorage.NewAccountTransactions(node.DB)
+			}
+			return nil
+		}).
 		Module("transaction metrics", func(node *cmd.NodeConfig) error {
 			builder.TransactionMetrics = metrics.NewTransactionCollector(builder.TransactionTimings, node.Logger, builder.logTxTimeToFinalized,
 				builder.logTxTimeToExecuted, builder.logTxTimeToFinalizedExecuted)
@@ -843,6 +1036,29 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 			builder.AccessMetrics = metrics.NewAccessCollector()
 			return nil
 		}).
+		Module("script result cache", func(node *cmd.NodeConfig) error {
+			if builder.scriptResultCacheSize == 0 {
+				return nil
+			}
+
+			var err error
+			builder.ScriptCache, err = backend.NewScriptCache(builder.scriptResultCacheSize, builder.AccessMetrics)
+			return err
+		}).
+		AdminCommand("invalidate-script-cache", func(config *cmd.NodeConfig) commands.AdminCommand {
+			return accessCommands.NewInvalidateScriptCacheCommand(builder.ScriptCache)
+		}).
+		Module("execution node health", func(node *cmd.NodeConfig) error {
+			// the execution node health tracker is also the circuit breaker of execution nodes
+			builder.executionNodeHealthConf.CircuitBreakerEnabled = builder.rpcConf.CircuitBreakerConfig.Enabled
+			if builder.executionNodeHealthEnabled || builder.executionNodeHealthConf.CircuitBreakerEnabled {
+				builder.ExecutionNodeHealth = backend.NewExecutionNodeHealth(builder.executionNodeHealthConf, builder.AccessMetrics, node.Logger)
+			}
+			return nil
+		}).
+		AdminCommand("get-execution-node-health", func(config *cmd.NodeConfig) commands.AdminCommand {
+			return accessCommands.NewGetExecutionNodeHealthCommand(builder.ExecutionNodeHealth)
+		}).
 		Module("ping metrics", func(node *cmd.NodeConfig) error {
 			builder.PingMetrics = metrics.NewPingCollector()
 			return nil
@@ -858,6 +1074,16 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 			return nil
 		}).
 		Component("RPC engine", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+			// the executor and index are optional, and must be passed as nil interfaces if they are disabled
+			if builder.ScriptExecutor != nil {
+				builder.rpcConf.BackendOptions.ScriptExecutor = builder.ScriptExecutor
+			}
+			if builder.AccountTransactions != nil {
+				builder.rpcConf.BackendOptions.AccountTransactions = builder.AccountTransactions
+			}
+			builder.rpcConf.BackendOptions.ScriptCache = builder.ScriptCache
+			builder.rpcConf.BackendOptions.ExecutionNodeHealth = builder.ExecutionNodeHealth
+
 			engineBuilder, err := rpc.NewBuilder(
 				node.Logger,
 				node.State,
@@ -884,10 +1110,11 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 				return nil, err
 			}
 
-			builder.RpcEng, err = engineBuilder.
+			engineBuilder = engineBuilder.
 				WithLegacy().
-				WithBlockSignerDecoder(signature.NewBlockSignerDecoder(builder.Committee)).
-				Build()
+				WithBlockSignerDecoder(signature.NewBlockSignerDecoder(builder.Committee))
+
+			builder.RpcEng, err = engineBuilder.Build()
 			if err != nil {
 				return nil, err
 			}
@@ -911,6 +1138,12 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 				return nil, fmt.Errorf("could not create requester engine: %w", err)
 			}
 
+			// the index is optional, and must be passed as a nil interface if it is disabled
+			var accountTransactions storage.AccountTransactions
+			if builder.AccountTransactions != nil {
+				accountTransactions = builder.AccountTransactions
+			}
+
 			builder.IngestEng, err = ingestion.New(
 				node.Logger,
 				node.Network,
@@ -928,6 +1161,8 @@ func (builder *FlowAccessNodeBuilder) Build() (cmd.Node, error) {
 				builder.CollectionsToMarkExecuted,
 				builder.BlocksToMarkExecuted,
 				builder.RpcEng,
+				accountTransactions,
+				builder.ExecutionNodeHealth,
 			)
 			if err != nil {
 				return nil, err
@@ -22,6 +22,7 @@ import (
 	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/mem"
 	"go.uber.org/atomic"
+	pb "google.golang.org/genproto/googleapis/devtools/cloudprofiler/v2"
 
 	"github.com/onflow/flow-go/admin/commands"
 	executionCommands "github.com/onflow/flow-go/admin/commands/execution"
@@ -44,8 +45,10 @@ import (
 	"github.com/onflow/flow-go/engine/execution/computation/committer"
 	"github.com/onflow/flow-go/engine/execution/computation/computer"
 	"github.com/onflow/flow-go/engine/execution/computation/computer/uploader"
+	"github.com/onflow/flow-go/engine/execution/computation/programcache"
 	"github.com/onflow/flow-go/engine/execution/ingestion"
 	exeprovider "github.com/onflow/flow-go/engine/execution/provider"
+	exepruner "github.com/onflow/flow-go/engine/execution/pruner"
 	"github.com/onflow/flow-go/engine/execution/rpc"
 	"github.com/onflow/flow-go/engine/execution/state"
 	"github.com/onflow/flow-go/engine/execution/state/bootstrap"
@@ -54,6 +57,8 @@ import (
 	"github.com/onflow/flow-go/fvm/systemcontracts"
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	ledger "github.com/onflow/flow-go/ledger/complete"
+	mtrienode "github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadstore"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	bootstrapFilenames "github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/flow"
@@ -70,6 +75,7 @@ import (
 	finalizer "github.com/onflow/flow-go/module/finalizer/consensus"
 	"github.com/onflow/flow-go/module/mempool/queue"
 	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/module/profiler"
 	"github.com/onflow/flow-go/network"
 	"github.com/onflow/flow-go/network/channels"
 	"github.com/onflow/flow-go/network/p2p/blob"
@@ -117,6 +123,7 @@ type ExecutionNode struct {
 	events                  *storage.Events
 	serviceEvents           *storage.ServiceEvents
 	txResults               *storage.TransactionResults
+	chunkDataPacks          *storage.ChunkDataPacks
 	results                 *storage.ExecutionResults
 	myReceipts              *storage.MyExecutionReceipts
 	providerEngine          *exeprovider.Engine
@@ -139,6 +146,7 @@ type ExecutionNode struct {
 	stopControl             *ingestion.StopControl // stop the node at given block height
 	executionDataDatastore  *badger.Datastore
 	executionDataPruner     *pruner.Pruner
+	executionStatePruner    *exepruner.Pruner
 	executionDataBlobstore  blobs.Blobstore
 	executionDataTracker    tracker.Storage
 	blobService             network.BlobService
@@ -163,6 +171,12 @@ func (builder *ExecutionNodeBuilder) LoadComponentsAndModules() {
 		AdminCommand("stop-at-height", func(config *NodeConfig) commands.AdminCommand {
 			return executionCommands.NewStopAtHeightCommand(exeNode.stopControl)
 		}).
+		AdminCommand("trace-transaction", func(config *NodeConfig) commands.AdminCommand {
+			return executionCommands.NewTraceTransactionCommand(exeNode.ingestionEng)
+		}).
+		AdminCommand("execution-state-pruner", func(config *NodeConfig) commands.AdminCommand {
+			return executionCommands.NewExecutionStatePrunerCommand(exeNode.executionStatePruner)
+		}).
 		AdminCommand("set-uploader-enabled", func(config *NodeConfig) commands.AdminCommand {
 			return uploaderCommands.NewToggleUploaderCommand()
 		}).
@@ -193,9 +207,12 @@ func (builder *ExecutionNodeBuilder) LoadComponentsAndModules() {
 		Component("stop control", exeNode.LoadStopControl).
 		Component("execution state ledger WAL compactor", exeNode.LoadExecutionStateLedgerWALCompactor).
 		Component("execution data pruner", exeNode.LoadExecutionDataPruner).
+		Component("execution state pruner", exeNode.LoadExecutionStatePruner).
 		Component("blob service", exeNode.LoadBlobService).
 		Component("GCP block data uploader", exeNode.LoadGCPBlockDataUploader).
 		Component("S3 block data uploader", exeNode.LoadS3BlockDataUploader).
+		Component("transaction profile collector", exeNode.LoadTransactionProfileCollector).
+		Component("programs cache", exeNode.LoadProgramsCache).
 		Component("provider engine", exeNode.LoadProviderEngine).
 		Component("checker engine", exeNode.LoadCheckerEngine).
 		Component("ingestion engine", exeNode.LoadIngestionEngine).
@@ -436,6 +453,69 @@ func (exeNode *ExecutionNode) LoadS3BlockDataUploader(
 	return &module.NoopReadyDoneAware{}, nil
 }
 
+func (exeNode *ExecutionNode) LoadTransactionProfileCollector(
+	node *NodeConfig,
+) (
+	module.ReadyDoneAware,
+	error,
+) {
+	if exeNode.exeConf.computationConfig.TransactionProfileSampleRate <= 0 {
+		// transaction profiling is disabled, TransactionProfileCollector stays nil
+		return &module.NoopReadyDoneAware{}, nil
+	}
+
+	profileUploader, err := exeNode.builder.createProfileUploader()
+	if err != nil {
+		node.Logger.Warn().Err(err).Msg("failed to create pprof uploader, falling back to noop")
+		profileUploader = &profiler.NoopUploader{}
+	}
+
+	// transaction computation is the analogue of CPU time of go profiles
+	collector, err := profiler.NewProfileCollector(
+		node.Logger,
+		profileUploader,
+		profiler.ProfileCollectorConfig{
+			Name:        "transaction-computation",
+			ProfileType: pb.ProfileType_CPU,
+			Dir:         node.BaseConfig.profilerConfig.Dir,
+			Interval:    exeNode.exeConf.transactionProfileInterval,
+		})
+	if err != nil {
+		return nil, fmt.Errorf("could not create transaction profile collector: %w", err)
+	}
+
+	exeNode.exeConf.computationConfig.TransactionProfileCollector = collector
+
+	return collector, nil
+}
+
+func (exeNode *ExecutionNode) LoadProgramsCache(
+	node *NodeConfig,
+) (
+	module.ReadyDoneAware,
+	error,
+) {
+	if exeNode.exeConf.programsCacheDir == "" {
+		// the programs cache is disabled, ProgramsCache stays nil
+		return &module.NoopReadyDoneAware{}, nil
+	}
+
+	cache, err := programcache.New(
+		node.Logger,
+		exeNode.collector,
+		programcache.Config{
+			Dir:             exeNode.exeConf.programsCacheDir,
+			PersistInterval: exeNode.exeConf.programsCachePersistInterval,
+		})
+	if err != nil {
+		return nil, fmt.Errorf("could not create programs cache: %w", err)
+	}
+
+	exeNode.exeConf.computationConfig.ProgramsCache = cache
+
+	return cache, nil
+}
+
 func (exeNode *ExecutionNode) LoadProviderEngine(
 	node *NodeConfig,
 ) (
@@ -513,6 +593,20 @@ func (exeNode *ExecutionNode) LoadProviderEngine(
 	}
 	blockView := exeNode.executionState.NewView(stateCommit)
 
+	if exeNode.exeConf.computationConfig.ProgramsCache != nil {
+		header, err := node.Storage.Headers.ByBlockID(blockID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get the header of the latest executed block %s: %w", blockID.String(), err)
+		}
+
+		// Load the programs persisted by the programs cache, before the
+		// execution of the next blocks.
+		err = exeNode.computationManager.WarmUpPrograms(header, exeNode.executionState.NewView(stateCommit))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Get the epoch counter from the smart contract at the last executed block.
 	contractEpochCounter, err := getContractEpochCounter(exeNode.computationManager.VM(), vmCtx, blockView)
 	// Failing to fetch the epoch counter from the smart contract is a fatal error.
@@ -604,7 +698,7 @@ func (exeNode *ExecutionNode) LoadExecutionState(
 	error,
 ) {
 
-	chunkDataPacks := storage.NewChunkDataPacks(node.Metrics.Cache, node.DB, node.Storage.Collections, exeNode.exeConf.chunkDataPackCacheSize)
+	exeNode.chunkDataPacks = storage.NewChunkDataPacks(node.Metrics.Cache, node.DB, node.Storage.Collections, exeNode.exeConf.chunkDataPackCacheSize)
 
 	// Needed for gRPC server, make sure to assign to main scoped vars
 	exeNode.events = storage.NewEvents(node.Metrics.Cache, node.DB)
@@ -617,7 +711,7 @@ func (exeNode *ExecutionNode) LoadExecutionState(
 		node.Storage.Blocks,
 		node.Storage.Headers,
 		node.Storage.Collections,
-		chunkDataPacks,
+		exeNode.chunkDataPacks,
 		exeNode.results,
 		exeNode.myReceipts,
 		exeNode.events,
@@ -664,8 +758,19 @@ func (exeNode *ExecutionNode) LoadExecutionStateLedger(
 		return nil, fmt.Errorf("failed to initialize wal: %w", err)
 	}
 
-	exeNode.ledgerStorage, err = ledger.NewLedger(exeNode.diskWAL, int(exeNode.exeConf.mTrieCacheSize), exeNode.collector, node.Logger.With().Str("subcomponent",
-		"ledger").Logger(), ledger.DefaultPathFinderVersion)
+	// the payloads of the trie leaves are kept in memory unless a directory to store them is configured
+	var payloads mtrienode.PayloadStorage
+	if exeNode.exeConf.mTriePayloadDir != "" {
+		store, err := payloadstore.Open(exeNode.exeConf.mTriePayloadDir, exeNode.exeConf.mTriePayloadCacheSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open mtrie payload store: %w", err)
+		}
+		exeNode.builder.ShutdownFunc(store.Close)
+		payloads = store
+	}
+
+	exeNode.ledgerStorage, err = ledger.NewLedgerWithPayloadStorage(exeNode.diskWAL, int(exeNode.exeConf.mTrieCacheSize), exeNode.collector, node.Logger.With().Str("subcomponent",
+		"ledger").Logger(), ledger.DefaultPathFinderVersion, payloads)
 	return exeNode.ledgerStorage, err
 }
 
@@ -675,13 +780,14 @@ func (exeNode *ExecutionNode) LoadExecutionStateLedgerWALCompactor(
 	module.ReadyDoneAware,
 	error,
 ) {
-	return ledger.NewCompactor(
+	return ledger.NewCompactorWithDeltaCheckpoints(
 		exeNode.ledgerStorage,
 		exeNode.diskWAL,
 		node.Logger.With().Str("subcomponent", "checkpointer").Logger(),
 		uint(exeNode.exeConf.mTrieCacheSize),
 		exeNode.exeConf.checkpointDistance,
 		exeNode.exeConf.checkpointsToKeep,
+		exeNode.exeConf.deltaCheckpoints,
 		exeNode.toTriggerCheckpoint, // compactor will listen to the signal from admin tool for force triggering checkpointing
 	)
 }
@@ -734,6 +840,61 @@ func (exeNode *ExecutionNode) LoadExecutionDataPruner(
 	return exeNode.executionDataPruner, err
 }
 
+func (exeNode *ExecutionNode) LoadExecutionStatePruner(
+	node *NodeConfig,
+) (
+	module.ReadyDoneAware,
+	error,
+) {
+	// by default, pruning is disabled
+	if exeNode.exeConf.statePrunerHeightRangeTarget == 0 {
+		return &module.NoopReadyDoneAware{}, nil
+	}
+
+	headers, ok := node.Storage.Headers.(*storage.Headers)
+	if !ok {
+		return nil, fmt.Errorf("only badger headers storage is supported for execution state pruning, got %T", node.Storage.Headers)
+	}
+
+	prunerStorage, err := exepruner.NewBadgerStorage(
+		node.DB,
+		node.RootBlock.Header.Height,
+		headers,
+		exeNode.results,
+		exeNode.chunkDataPacks,
+		exeNode.events,
+		exeNode.txResults,
+		storage.NewComputationResultUploadStatus(node.DB),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create execution state pruner storage: %w", err)
+	}
+
+	// the execution state of a height can be pruned once it is both executed and sealed
+	sealed, err := node.State.Sealed().Head()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the sealed block: %w", err)
+	}
+
+	fulfilledHeight, _, err := exeNode.executionState.GetHighestExecutedBlockID(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the latest executed block height: %w", err)
+	}
+	if sealed.Height < fulfilledHeight {
+		fulfilledHeight = sealed.Height
+	}
+
+	exeNode.executionStatePruner, err = exepruner.NewPruner(
+		node.Logger,
+		exeNode.collector,
+		prunerStorage,
+		fulfilledHeight,
+		exepruner.WithHeightRangeTarget(exeNode.exeConf.statePrunerHeightRangeTarget),
+		exepruner.WithThreshold(exeNode.exeConf.statePrunerThreshold),
+	)
+	return exeNode.executionStatePruner, err
+}
+
 func (exeNode *ExecutionNode) LoadCheckerEngine(
 	node *NodeConfig,
 ) (
@@ -803,8 +964,18 @@ func (exeNode *ExecutionNode) LoadIngestionEngine(
 		exeNode.exeConf.syncFast,
 		exeNode.checkAuthorizedAtBlock,
 		exeNode.executionDataPruner,
+		exeNode.executionStatePruner,
 		exeNode.blockDataUploaders,
 		exeNode.stopControl,
+		ingestion.NewForkDetector(
+			node.Logger,
+			node.DB,
+			node.Storage.Seals,
+			node.Storage.Results,
+			node.Storage.Collections,
+			exeNode.exeConf.forkDiagnosticsDir,
+			exeNode.exeConf.crashOnExecutionFork,
+		),
 	)
 
 	// TODO: we should solve these mutual dependencies better
@@ -973,6 +1144,7 @@ func (exeNode *ExecutionNode) LoadGrpcServer(
 	return rpc.New(
 		node.Logger,
 		exeNode.exeConf.rpcConf,
+		exeNode.collector,
 		exeNode.ingestionEng,
 		node.Storage.Headers,
 		node.State,
@@ -984,7 +1156,7 @@ func (exeNode *ExecutionNode) LoadGrpcServer(
 		signature.NewBlockSignerDecoder(exeNode.committee),
 		exeNode.exeConf.apiRatelimits,
 		exeNode.exeConf.apiBurstlimits,
-	), nil
+	)
 }
 
 func (exeNode *ExecutionNode) LoadBootstrapper(node *NodeConfig) error {
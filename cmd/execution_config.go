@@ -17,6 +17,7 @@ import (
 	"github.com/onflow/flow-go/engine/execution/computation"
 	"github.com/onflow/flow-go/engine/execution/rpc"
 	"github.com/onflow/flow-go/fvm/derived"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadstore"
 	storage "github.com/onflow/flow-go/storage/badger"
 )
 
@@ -26,9 +27,12 @@ type ExecutionConfig struct {
 	triedir                              string
 	executionDataDir                     string
 	mTrieCacheSize                       uint32
+	mTriePayloadDir                      string
+	mTriePayloadCacheSize                int
 	transactionResultsCacheSize          uint
 	checkpointDistance                   uint
 	checkpointsToKeep                    uint
+	deltaCheckpoints                     uint
 	stateDeltasLimit                     uint
 	chunkDataPackCacheSize               uint
 	chunkDataPackRequestsCacheSize       uint32
@@ -49,9 +53,16 @@ type ExecutionConfig struct {
 	executionDataAllowedPeers            string
 	executionDataPrunerHeightRangeTarget uint64
 	executionDataPrunerThreshold         uint64
+	statePrunerHeightRangeTarget         uint64
+	statePrunerThreshold                 uint64
+	forkDiagnosticsDir                   string
+	crashOnExecutionFork                 bool
 	blobstoreRateLimit                   int
 	blobstoreBurstLimit                  int
 	chunkDataPackRequestWorkers          uint
+	transactionProfileInterval           time.Duration
+	programsCacheDir                     string
+	programsCachePersistInterval         time.Duration
 
 	computationConfig        computation.ComputationConfig
 	receiptRequestWorkers    uint   // common provider engine workers
@@ -64,11 +75,32 @@ func (exeConf *ExecutionConfig) SetupFlags(flags *pflag.FlagSet) {
 
 	flags.StringVarP(&exeConf.rpcConf.ListenAddr, "rpc-addr", "i", "localhost:9000", "the address the gRPC server listens on")
 	flags.BoolVar(&exeConf.rpcConf.RpcMetricsEnabled, "rpc-metrics-enabled", false, "whether to enable the rpc metrics")
+	flags.IntVar(&exeConf.rpcConf.ScriptQuotas.MaxConcurrency, "script-caller-max-concurrency", 0,
+		"max number of scripts executed concurrently per caller, 0 means no limit")
+	flags.Float64Var(&exeConf.rpcConf.ScriptQuotas.RateLimit, "script-caller-rate-limit", 0,
+		"max number of scripts per second per caller, 0 means no limit")
+	flags.IntVar(&exeConf.rpcConf.ScriptQuotas.Burst, "script-caller-burst-limit", 10,
+		"max number of scripts per caller allowed at once by the script rate limit")
+	flags.DurationVar(&exeConf.rpcConf.ScriptQuotas.QueueTimeout, "script-caller-queue-timeout", 0,
+		"how long a script request over the caller's concurrency limit waits before it is rejected, 0 rejects it immediately")
+	flags.Float64Var(&exeConf.rpcConf.ScriptQuotas.ExecutionTimeRate, "script-caller-execution-time-rate", 0,
+		"script execution time a caller accrues per second, e.g. 0.5 allows a caller to keep scripts running half of the time, 0 means no limit")
+	flags.DurationVar(&exeConf.rpcConf.ScriptQuotas.ExecutionTimeBurst, "script-caller-execution-time-burst", rpc.DefaultScriptExecutionTimeBurst,
+		"max script execution time a caller can accrue")
+	flags.BoolVar(&exeConf.rpcConf.ScriptQuotas.ExemptAccessNodes, "script-caller-exempt-access-nodes", true,
+		"exempt script requests from the IP addresses of staked access nodes from the per caller quotas")
+	flags.StringVar(&exeConf.rpcConf.ScriptQuotas.APIKeyHeader, "script-caller-api-key-header", "",
+		"gRPC metadata key of the API key identifying script callers, callers are identified by their IP address if empty or not sent")
+	flags.IntVar(&exeConf.rpcConf.ScriptQuotas.MaxCallers, "script-caller-max-tracked", rpc.DefaultScriptQuotaMaxCallers,
+		"max number of callers whose script quotas are tracked")
 	flags.StringVar(&exeConf.triedir, "triedir", datadir, "directory to store the execution State")
 	flags.StringVar(&exeConf.executionDataDir, "execution-data-dir", filepath.Join(homedir, ".flow", "execution_data"), "directory to use for storing Execution Data")
 	flags.Uint32Var(&exeConf.mTrieCacheSize, "mtrie-cache-size", 500, "cache size for MTrie")
+	flags.StringVar(&exeConf.mTriePayloadDir, "mtrie-payload-dir", "", "directory to store the MTrie leaf payloads on disk rather than in memory, payloads are kept in memory if empty")
+	flags.IntVar(&exeConf.mTriePayloadCacheSize, "mtrie-payload-cache-size", payloadstore.DefaultCacheSize, "number of MTrie leaf payloads cached in memory if they are stored on disk")
 	flags.UintVar(&exeConf.checkpointDistance, "checkpoint-distance", 20, "number of WAL segments between checkpoints")
 	flags.UintVar(&exeConf.checkpointsToKeep, "checkpoints-to-keep", 5, "number of recent checkpoints to keep (0 to keep all)")
+	flags.UintVar(&exeConf.deltaCheckpoints, "delta-checkpoints", 0, "number of delta checkpoints, which only contain the trie nodes created since the previous checkpoint, between full checkpoints (0 to only create full checkpoints)")
 	flags.UintVar(&exeConf.stateDeltasLimit, "state-deltas-limit", 100, "maximum number of state deltas in the memory pool")
 	flags.UintVar(&exeConf.computationConfig.DerivedDataCacheSize, "cadence-execution-cache", derived.DefaultDerivedDataCacheSize,
 		"cache size for Cadence execution")
@@ -83,6 +115,16 @@ func (exeConf *ExecutionConfig) SetupFlags(flags *pflag.FlagSet) {
 		"threshold for logging script execution")
 	flags.DurationVar(&exeConf.computationConfig.ScriptExecutionTimeLimit, "script-execution-time-limit", computation.DefaultScriptExecutionTimeLimit,
 		"script execution time limit")
+	flags.UintVar(&exeConf.computationConfig.ParallelTransactionExecutionWorkers, "parallel-transaction-execution-workers", 0,
+		"number of transactions of a collection executed speculatively in parallel (0 or 1 to execute transactions sequentially)")
+	flags.Float64Var(&exeConf.computationConfig.TransactionProfileSampleRate, "transaction-profile-sample-rate", 0,
+		"fraction of the executed transactions whose computation is profiled, between 0 (disabled) and 1")
+	flags.DurationVar(&exeConf.transactionProfileInterval, "transaction-profile-interval", 10*time.Minute,
+		"the interval between writes of the merged transaction computation profiles to the profiler dir")
+	flags.StringVar(&exeConf.programsCacheDir, "programs-cache-dir", "",
+		"directory to persist the index of the cached Cadence programs, so that they are loaded on startup (empty to disable)")
+	flags.DurationVar(&exeConf.programsCachePersistInterval, "programs-cache-persist-interval", time.Minute,
+		"the interval between writes of the index of the cached Cadence programs")
 	flags.StringVar(&exeConf.preferredExeNodeIDStr, "preferred-exe-node-id", "", "node ID for preferred execution node used for state sync")
 	flags.UintVar(&exeConf.transactionResultsCacheSize, "transaction-results-cache-size", 10000, "number of transaction results to be cached")
 	flags.BoolVar(&exeConf.syncByBlocks, "sync-by-blocks", true, "deprecated, sync by blocks instead of execution state deltas")
@@ -102,6 +144,12 @@ func (exeConf *ExecutionConfig) SetupFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&exeConf.executionDataAllowedPeers, "execution-data-allowed-requesters", "", "comma separated list of Access node IDs that are allowed to request Execution Data. an empty list allows all peers")
 	flags.Uint64Var(&exeConf.executionDataPrunerHeightRangeTarget, "execution-data-height-range-target", 0, "target height range size used to limit the amount of Execution Data kept on disk")
 	flags.Uint64Var(&exeConf.executionDataPrunerThreshold, "execution-data-height-range-threshold", 100_000, "height threshold used to trigger Execution Data pruning")
+	flags.Uint64Var(&exeConf.statePrunerHeightRangeTarget, "execution-state-height-range-target", 0, "number of most recent sealed heights to keep the historical execution state (events, transaction results, chunk data packs) of. 0 disables pruning")
+	flags.StringVar(&exeConf.forkDiagnosticsDir, "execution-fork-diagnostics-dir", filepath.Join(homedir, ".flow", "execution_fork_diagnostics"),
+		"directory to write the diagnostics to when the execution result of a block conflicts with its sealed result")
+	flags.BoolVar(&exeConf.crashOnExecutionFork, "execution-fork-crash", false,
+		"crash instead of pausing execution when the execution result of a block conflicts with its sealed result. The pause persists across restarts until it is removed with the remove-execution-fork util")
+	flags.Uint64Var(&exeConf.statePrunerThreshold, "execution-state-height-range-threshold", 100_000, "height threshold used to trigger historical execution state pruning")
 	flags.StringToIntVar(&exeConf.apiRatelimits, "api-rate-limits", map[string]int{}, "per second rate limits for GRPC API methods e.g. Ping=300,ExecuteScriptAtBlockID=500 etc. note limits apply globally to all clients.")
 	flags.StringToIntVar(&exeConf.apiBurstlimits, "api-burst-limits", map[string]int{}, "burst limits for gRPC API methods e.g. Ping=100,ExecuteScriptAtBlockID=100 etc. note limits apply globally to all clients.")
 	flags.IntVar(&exeConf.blobstoreRateLimit, "blobstore-rate-limit", 0, "per second outgoing rate limit for Execution Data blobstore")
@@ -114,6 +162,15 @@ func (exeConf *ExecutionConfig) ValidateFlags() error {
 			return fmt.Errorf("invalid flag. gcp-bucket-name or s3-bucket-name required when blockdata-uploader is enabled")
 		}
 	}
+	if exeConf.computationConfig.TransactionProfileSampleRate < 0 || exeConf.computationConfig.TransactionProfileSampleRate > 1 {
+		return fmt.Errorf("invalid flag. transaction-profile-sample-rate must be between 0 and 1")
+	}
+	if exeConf.computationConfig.TransactionProfileSampleRate > 0 && exeConf.transactionProfileInterval <= 0 {
+		return fmt.Errorf("invalid flag. transaction-profile-interval must be positive when transaction profiling is enabled")
+	}
+	if exeConf.programsCacheDir != "" && exeConf.programsCachePersistInterval <= 0 {
+		return fmt.Errorf("invalid flag. programs-cache-persist-interval must be positive when the programs cache is enabled")
+	}
 	if exeConf.executionDataAllowedPeers != "" {
 		ids := strings.Split(exeConf.executionDataAllowedPeers, ",")
 		for _, id := range ids {
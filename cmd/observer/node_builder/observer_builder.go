@@ -114,6 +114,8 @@ type ObserverServiceConfig struct {
 	upstreamNodeAddresses     []string
 	upstreamNodePublicKeys    []string
 	upstreamIdentities        flow.IdentityList // the identity list of upstream peers the node uses to forward API requests to
+	upstreamCircuitBreaker    backend.CircuitBreakerConfig
+	upstreamHedgeDelay        time.Duration
 }
 
 // DefaultObserverServiceConfig defines all the default values for the ObserverServiceConfig
@@ -152,6 +154,8 @@ func DefaultObserverServiceConfig() *ObserverServiceConfig {
 		apiTimeout:             3 * time.Second,
 		upstreamNodeAddresses:  []string{},
 		upstreamNodePublicKeys: []string{},
+		upstreamCircuitBreaker: backend.DefaultCircuitBreakerConfig(),
+		upstreamHedgeDelay:     0,
 	}
 }
 
@@ -585,6 +589,10 @@ func (builder *ObserverServiceBuilder) extraFlags() {
 		flags.DurationVar(&builder.apiTimeout, "upstream-api-timeout", defaultConfig.apiTimeout, "tcp timeout for Flow API gRPC sockets to upstrem nodes")
 		flags.StringSliceVar(&builder.upstreamNodeAddresses, "upstream-node-addresses", defaultConfig.upstreamNodeAddresses, "the gRPC network addresses of the upstream access node. e.g. access-001.mainnet.flow.org:9000,access-002.mainnet.flow.org:9000")
 		flags.StringSliceVar(&builder.upstreamNodePublicKeys, "upstream-node-public-keys", defaultConfig.upstreamNodePublicKeys, "the networking public key of the upstream access node (in the same order as the upstream node addresses) e.g. \"d57a5e9c5.....\",\"44ded42d....\"")
+		flags.BoolVar(&builder.upstreamCircuitBreaker.Enabled, "upstream-circuit-breaker-enabled", defaultConfig.upstreamCircuitBreaker.Enabled, "whether to enable the circuit breakers for connections to upstream access nodes")
+		flags.Uint32Var(&builder.upstreamCircuitBreaker.MaxFailures, "upstream-circuit-breaker-max-failures", defaultConfig.upstreamCircuitBreaker.MaxFailures, "number of consecutive failed requests after which the circuit breaker of an upstream access node opens")
+		flags.DurationVar(&builder.upstreamCircuitBreaker.RestoreTimeout, "upstream-circuit-breaker-restore-timeout", defaultConfig.upstreamCircuitBreaker.RestoreTimeout, "duration the circuit breaker of an upstream access node stays open before a request is let through to check whether the node recovered")
+		flags.DurationVar(&builder.upstreamHedgeDelay, "upstream-hedge-delay", defaultConfig.upstreamHedgeDelay, "delay after which read-only requests that have not completed are also forwarded to the next upstream access node, 0 disables hedged requests e.g. 500ms")
 		flags.BoolVar(&builder.rpcMetricsEnabled, "rpc-metrics-enabled", defaultConfig.rpcMetricsEnabled, "whether to enable the rpc metrics")
 
 		// ExecutionDataRequester config
@@ -596,6 +604,17 @@ func (builder *ObserverServiceBuilder) extraFlags() {
 		flags.DurationVar(&builder.executionDataConfig.RetryDelay, "execution-data-retry-delay", defaultConfig.executionDataConfig.RetryDelay, "initial delay for exponential backoff when fetching execution data fails e.g. 10s")
 		flags.DurationVar(&builder.executionDataConfig.MaxRetryDelay, "execution-data-max-retry-delay", defaultConfig.executionDataConfig.MaxRetryDelay, "maximum delay for exponential backoff when fetching execution data fails e.g. 5m")
 	}).ValidateFlags(func() error {
+		if builder.upstreamCircuitBreaker.Enabled {
+			if builder.upstreamCircuitBreaker.MaxFailures == 0 {
+				return errors.New("upstream-circuit-breaker-max-failures must be greater than 0")
+			}
+			if builder.upstreamCircuitBreaker.RestoreTimeout <= 0 {
+				return errors.New("upstream-circuit-breaker-restore-timeout must be greater than 0")
+			}
+		}
+		if builder.upstreamHedgeDelay < 0 {
+			return errors.New("upstream-hedge-delay must not be negative")
+		}
 		if builder.executionDataSyncEnabled {
 			if builder.executionDataConfig.FetchTimeout <= 0 {
 				return errors.New("execution-data-fetch-timeout must be greater than 0")
@@ -999,7 +1018,12 @@ func (builder *ObserverServiceBuilder) enqueueRPCServer() {
 		}
 
 		// upstream access node forwarder
-		forwarder, err := apiproxy.NewFlowAccessAPIForwarder(builder.upstreamIdentities, builder.apiTimeout)
+		forwarder, err := apiproxy.NewFlowAccessAPIForwarder(
+			builder.upstreamIdentities,
+			builder.apiTimeout,
+			builder.upstreamCircuitBreaker,
+			builder.upstreamHedgeDelay,
+		)
 		if err != nil {
 			return nil, err
 		}
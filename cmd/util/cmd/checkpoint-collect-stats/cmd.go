@@ -3,7 +3,9 @@ package checkpoint_collect_stats
 import (
 	"bufio"
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"math"
 	"os"
 	"path/filepath"
@@ -27,9 +29,10 @@ import (
 )
 
 var (
-	flagCheckpointDir string
-	flagOutputDir     string
-	flagMemProfile    bool
+	flagCheckpointDir   string
+	flagOutputDir       string
+	flagMemProfile      bool
+	flagStateCommitment string
 )
 
 var Cmd = &cobra.Command{
@@ -49,6 +52,10 @@ func init() {
 
 	Cmd.Flags().BoolVar(&flagMemProfile, "mem-profile", false,
 		"Enable memory profiling")
+
+	Cmd.Flags().StringVar(&flagStateCommitment, "state-commitment", "",
+		"state commitment (hex-encoded, 64 characters) to only collect the payload stats of, "+
+			"which are read from the latest V6 checkpoint without loading the other tries")
 }
 
 type Stats struct {
@@ -82,37 +89,14 @@ func run(*cobra.Command, []string) {
 		defer profile.Start(profile.MemProfile).Stop()
 	}
 
-	memAllocBefore := debug.GetHeapAllocsBytes()
-	log.Info().Msgf("loading checkpoint(s) from %v", flagCheckpointDir)
-
-	diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, &metrics.NoopCollector{}, flagCheckpointDir, complete.DefaultCacheSize, pathfinder.PathByteSize, wal.SegmentSize)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create WAL")
-	}
-	led, err := complete.NewLedger(diskWal, complete.DefaultCacheSize, &metrics.NoopCollector{}, log.Logger, 0)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create ledger from write-a-head logs and checkpoints")
-	}
-	compactor, err := complete.NewCompactor(led, diskWal, zerolog.Nop(), complete.DefaultCacheSize, math.MaxInt, 1, atomic.NewBool(false))
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create compactor")
-	}
-	<-compactor.Ready()
-	defer func() {
-		<-led.Done()
-		<-compactor.Done()
-	}()
-
-	memAllocAfter := debug.GetHeapAllocsBytes()
-	log.Info().Msgf("the checkpoint is loaded, mem usage: %d", memAllocAfter-memAllocBefore)
-
 	var totalPayloadSize, totalPayloadValueSize uint64
 	var value ledger.Value
 	var key ledger.Key
 	var size, valueSize int
+	var err error
 
 	valueSizesByType := make(sizesByType, 0)
-	ledgerStats, err := led.CollectStats(func(p *ledger.Payload) {
+	payloadCallBack := func(p *ledger.Payload) {
 		key, err = p.Key()
 		if err != nil {
 			log.Fatal().Err(err).Msg("cannot load a key")
@@ -124,7 +108,18 @@ func run(*cobra.Command, []string) {
 		totalPayloadSize += uint64(size)
 		totalPayloadValueSize += uint64(valueSize)
 		valueSizesByType[getType(key)] = append(valueSizesByType[getType(key)], float64(valueSize))
-	})
+	}
+
+	// the ledger stats are only collected for all tries, as the nodes of a single trie aren't loaded
+	var ledgerStats *complete.LedgerStats
+	if flagStateCommitment != "" {
+		err = collectTriePayloadStats(flagCheckpointDir, flagStateCommitment, payloadCallBack)
+	} else {
+		ledgerStats, err = collectLedgerStats(flagCheckpointDir, payloadCallBack)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to collect stats")
+	}
 
 	statsByTypes := make([]RegisterStatsByTypes, 0)
 	for t, values := range valueSizesByType {
@@ -178,10 +173,6 @@ func run(*cobra.Command, []string) {
 			})
 	}
 
-	if err != nil {
-		log.Fatal().Err(err).Msg("failed to collect stats")
-	}
-
 	stats := &Stats{
 		LedgerStats: ledgerStats,
 		PayloadStats: &PayloadStats{
@@ -210,6 +201,84 @@ func run(*cobra.Command, []string) {
 	}
 }
 
+// collectLedgerStats loads all tries from the checkpoints and WAL segments in the given directory,
+// and collects the ledger stats of all tries and calls payloadCallBack for each payload.
+func collectLedgerStats(dir string, payloadCallBack func(payload *ledger.Payload)) (*complete.LedgerStats, error) {
+	memAllocBefore := debug.GetHeapAllocsBytes()
+	log.Info().Msgf("loading checkpoint(s) from %v", dir)
+
+	diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, &metrics.NoopCollector{}, dir, complete.DefaultCacheSize, pathfinder.PathByteSize, wal.SegmentSize)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create WAL: %w", err)
+	}
+	led, err := complete.NewLedger(diskWal, complete.DefaultCacheSize, &metrics.NoopCollector{}, log.Logger, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create ledger from write-a-head logs and checkpoints: %w", err)
+	}
+	compactor, err := complete.NewCompactor(led, diskWal, zerolog.Nop(), complete.DefaultCacheSize, math.MaxInt, 1, atomic.NewBool(false))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create compactor: %w", err)
+	}
+	<-compactor.Ready()
+	defer func() {
+		<-led.Done()
+		<-compactor.Done()
+	}()
+
+	memAllocAfter := debug.GetHeapAllocsBytes()
+	log.Info().Msgf("the checkpoint is loaded, mem usage: %d", memAllocAfter-memAllocBefore)
+
+	return led.CollectStats(payloadCallBack)
+}
+
+// collectTriePayloadStats calls payloadCallBack for each payload of the trie with the given state
+// commitment, which is read from the latest checkpoint in the given directory. Only the leaves of
+// the trie are read, so the checkpoint must be a V6 checkpoint.
+func collectTriePayloadStats(dir string, stateCommitment string, payloadCallBack func(payload *ledger.Payload)) error {
+	stateCommitmentBytes, err := hex.DecodeString(stateCommitment)
+	if err != nil {
+		return fmt.Errorf("cannot decode state commitment: %w", err)
+	}
+	state, err := ledger.ToState(stateCommitmentBytes)
+	if err != nil {
+		return fmt.Errorf("invalid state commitment: %w", err)
+	}
+
+	_, checkpoint, err := wal.ListCheckpoints(dir)
+	if err != nil {
+		return fmt.Errorf("cannot find latest checkpoint: %w", err)
+	}
+	if checkpoint < 0 {
+		return fmt.Errorf("no checkpoint found in %v", dir)
+	}
+
+	fileName := wal.NumberToFilename(checkpoint)
+	isV6, err := wal.IsCheckpointV6(filepath.Join(dir, fileName))
+	if err != nil {
+		return fmt.Errorf("cannot read header of checkpoint %v: %w", fileName, err)
+	}
+	if !isV6 {
+		return fmt.Errorf("checkpoint %v is not a V6 checkpoint, stats of a single trie can't be collected", fileName)
+	}
+
+	log.Info().Msgf("reading payloads of trie %v from checkpoint %v", state, fileName)
+
+	it, err := wal.OpenTrieLeafIteratorV6(dir, fileName, ledger.RootHash(state), wal.LeafFilter{}, &log.Logger)
+	if err != nil {
+		return fmt.Errorf("cannot open trie %v in checkpoint %v: %w", state, fileName, err)
+	}
+	defer it.Close()
+
+	for it.Next() {
+		_, payload := it.Value()
+		payloadCallBack(payload)
+	}
+	if it.Err() != nil {
+		return fmt.Errorf("cannot read payloads of trie %v: %w", state, it.Err())
+	}
+	return nil
+}
+
 func getType(key ledger.Key) string {
 	k := key.KeyParts[1].Value
 	kstr := string(k)
@@ -2,10 +2,12 @@ package checkpoint_list_tries
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
+	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 )
 
@@ -28,14 +30,33 @@ func init() {
 
 func run(*cobra.Command, []string) {
 
-	log.Info().Msgf("loading checkpoint %v", flagCheckpoint)
-	tries, err := wal.LoadCheckpoint(flagCheckpoint, &log.Logger)
+	isV6, err := wal.IsCheckpointV6(flagCheckpoint)
 	if err != nil {
-		log.Fatal().Err(err).Msg("error while loading checkpoint")
+		log.Fatal().Err(err).Msg("error while reading checkpoint header")
 	}
-	log.Info().Msgf("checkpoint loaded, total tries: %v", len(tries))
 
-	for _, trie := range tries {
-		fmt.Printf("trie root hash: %s\n", trie.RootHash())
+	var rootHashes []ledger.RootHash
+	if isV6 {
+		// the root hashes are read from the top level part file, without loading the tries
+		log.Info().Msgf("reading root hashes of checkpoint %v", flagCheckpoint)
+		dir, fileName := filepath.Split(flagCheckpoint)
+		rootHashes, err = wal.ReadTrieRootHashesV6(dir, fileName, &log.Logger)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error while reading root hashes of checkpoint")
+		}
+	} else {
+		log.Info().Msgf("loading checkpoint %v", flagCheckpoint)
+		tries, err := wal.LoadCheckpoint(flagCheckpoint, &log.Logger)
+		if err != nil {
+			log.Fatal().Err(err).Msg("error while loading checkpoint")
+		}
+		for _, trie := range tries {
+			rootHashes = append(rootHashes, trie.RootHash())
+		}
+	}
+	log.Info().Msgf("total tries: %v", len(rootHashes))
+
+	for _, rootHash := range rootHashes {
+		fmt.Printf("trie root hash: %s\n", rootHash)
 	}
 }
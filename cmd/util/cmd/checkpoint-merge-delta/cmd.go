@@ -0,0 +1,66 @@
+package checkpoint_merge_delta
+
+import (
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/ledger/complete/wal"
+)
+
+var (
+	flagCheckpoint string
+	flagOutputDir  string
+	flagOutputFile string
+)
+
+var Cmd = &cobra.Command{
+	Use:   "checkpoint-merge-delta",
+	Short: "Merges a delta checkpoint and the checkpoints it is based on into a full checkpoint",
+	Run:   run,
+}
+
+func init() {
+
+	Cmd.Flags().StringVar(&flagCheckpoint, "checkpoint", "",
+		"delta checkpoint file to merge, the checkpoints it is based on are read from the same directory")
+	_ = Cmd.MarkFlagRequired("checkpoint")
+
+	Cmd.Flags().StringVar(&flagOutputDir, "output-dir", "",
+		"directory to write the full checkpoint to")
+	_ = Cmd.MarkFlagRequired("output-dir")
+
+	Cmd.Flags().StringVar(&flagOutputFile, "output-file", "",
+		"file name of the full checkpoint, defaults to the file name of the delta checkpoint")
+}
+
+func run(*cobra.Command, []string) {
+
+	outputFile := flagOutputFile
+	if outputFile == "" {
+		outputFile = filepath.Base(flagCheckpoint)
+	}
+
+	_, isDelta, err := wal.DeltaCheckpointBase(flagCheckpoint)
+	if err != nil {
+		log.Fatal().Err(err).Msg("error while reading checkpoint header")
+	}
+	if !isDelta {
+		log.Warn().Msgf("checkpoint %v is not a delta checkpoint, it is copied as full checkpoint", flagCheckpoint)
+	}
+
+	log.Info().Msgf("loading checkpoint %v", flagCheckpoint)
+	tries, err := wal.LoadCheckpoint(flagCheckpoint, &log.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("error while loading checkpoint")
+	}
+	log.Info().Msgf("checkpoint loaded, total tries: %v", len(tries))
+
+	err = wal.StoreCheckpointV6Concurrently(tries, flagOutputDir, outputFile, &log.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("error while storing full checkpoint")
+	}
+
+	log.Info().Msgf("full checkpoint stored to %v", filepath.Join(flagOutputDir, outputFile))
+}
@@ -0,0 +1,235 @@
+package execution_state_diff
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+var (
+	flagExecutionStateDir string
+	flagFromState         string
+	flagToState           string
+	flagOutputFile        string
+)
+
+var Cmd = &cobra.Command{
+	Use:   "execution-state-diff",
+	Short: "prints the registers added, removed and modified between two state commitments as JSON lines per owner",
+	Run:   run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagExecutionStateDir, "execution-state-dir", "",
+		"Execution Node state dir (where WAL logs are written), holding both states")
+	_ = Cmd.MarkFlagRequired("execution-state-dir")
+
+	Cmd.Flags().StringVar(&flagFromState, "from-state", "",
+		"State commitment to diff from (hex-encoded, 64 characters)")
+	_ = Cmd.MarkFlagRequired("from-state")
+
+	Cmd.Flags().StringVar(&flagToState, "to-state", "",
+		"State commitment to diff to (hex-encoded, 64 characters)")
+	_ = Cmd.MarkFlagRequired("to-state")
+
+	Cmd.Flags().StringVar(&flagOutputFile, "output-file", "",
+		"File to write the diff to, defaults to stdout")
+}
+
+func run(*cobra.Command, []string) {
+	from, err := parseState(flagFromState)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid from state")
+	}
+
+	to, err := parseState(flagToState)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid to state")
+	}
+
+	var writer io.Writer = os.Stdout
+	if flagOutputFile != "" {
+		file, err := os.Create(flagOutputFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot create output file")
+		}
+		defer file.Close()
+
+		fileWriter := bufio.NewWriter(file)
+		defer fileWriter.Flush()
+		writer = fileWriter
+	}
+
+	log.Info().Msgf("diffing execution state from %v to %v", from, to)
+
+	err = DiffExecutionState(flagExecutionStateDir, from, to, writer)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot diff execution state")
+	}
+}
+
+func parseState(s string) (ledger.State, error) {
+	stateBytes, err := hex.DecodeString(s)
+	if err != nil {
+		return ledger.DummyState, fmt.Errorf("failed to decode hex code of state: %w", err)
+	}
+	return ledger.ToState(stateBytes)
+}
+
+// registerDiff captures a register, whose value differs between two states.
+// Register keys and values are hex-encoded, as they are not necessarily valid UTF-8.
+type registerDiff struct {
+	Key    string `json:"key"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// ownerDiff captures the registers of an owner, whose values differ between two states
+type ownerDiff struct {
+	Owner    string         `json:"owner"`
+	Added    []registerDiff `json:"added"`
+	Removed  []registerDiff `json:"removed"`
+	Modified []registerDiff `json:"modified"`
+}
+
+// DiffExecutionState writes the registers, whose values differ between the given states, to the writer.
+// A JSON object is written per line for each owner with differing registers, in ascending owner order.
+func DiffExecutionState(ledgerPath string, from ledger.State, to ledger.State, writer io.Writer) error {
+
+	noopMetrics := &metrics.NoopCollector{}
+
+	diskWal, err := wal.NewDiskWAL(
+		zerolog.Nop(),
+		nil,
+		noopMetrics,
+		ledgerPath,
+		complete.DefaultCacheSize,
+		pathfinder.PathByteSize,
+		wal.SegmentSize,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot create WAL: %w", err)
+	}
+	defer func() {
+		<-diskWal.Done()
+	}()
+
+	led, err := complete.NewLedger(diskWal, complete.DefaultCacheSize, noopMetrics, log.Logger, complete.DefaultPathFinderVersion)
+	if err != nil {
+		return fmt.Errorf("cannot create ledger from write-a-head logs and checkpoints: %w", err)
+	}
+
+	diff, err := led.Diff(from, to)
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Int("added", len(diff.Added)).
+		Int("removed", len(diff.Removed)).
+		Int("modified", len(diff.Modified)).
+		Msg("execution state diffed")
+
+	owners, err := groupByOwner(diff)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(writer)
+	for _, owner := range owners {
+		err = encoder.Encode(owner)
+		if err != nil {
+			return fmt.Errorf("cannot write diff of owner %v: %w", owner.Owner, err)
+		}
+	}
+
+	return nil
+}
+
+// groupByOwner groups the registers of the diff by owner, in ascending owner order.
+func groupByOwner(diff *ledger.TrieDiff) ([]*ownerDiff, error) {
+	owners := make(map[string]*ownerDiff)
+
+	getOwner := func(payload *ledger.Payload) (*ownerDiff, string, error) {
+		key, err := payload.Key()
+		if err != nil {
+			return nil, "", fmt.Errorf("cannot decode payload key: %w", err)
+		}
+		registerID, err := state.KeyToRegisterID(key)
+		if err != nil {
+			return nil, "", err
+		}
+
+		owner := hex.EncodeToString([]byte(registerID.Owner))
+		od, ok := owners[owner]
+		if !ok {
+			od = &ownerDiff{
+				Owner:    owner,
+				Added:    make([]registerDiff, 0),
+				Removed:  make([]registerDiff, 0),
+				Modified: make([]registerDiff, 0),
+			}
+			owners[owner] = od
+		}
+		return od, hex.EncodeToString([]byte(registerID.Key)), nil
+	}
+
+	for _, payload := range diff.Added {
+		od, key, err := getOwner(payload)
+		if err != nil {
+			return nil, err
+		}
+		od.Added = append(od.Added, registerDiff{
+			Key:   key,
+			After: hex.EncodeToString(payload.Value()),
+		})
+	}
+
+	for _, payload := range diff.Removed {
+		od, key, err := getOwner(payload)
+		if err != nil {
+			return nil, err
+		}
+		od.Removed = append(od.Removed, registerDiff{
+			Key:    key,
+			Before: hex.EncodeToString(payload.Value()),
+		})
+	}
+
+	for _, modification := range diff.Modified {
+		od, key, err := getOwner(modification.After)
+		if err != nil {
+			return nil, err
+		}
+		od.Modified = append(od.Modified, registerDiff{
+			Key:    key,
+			Before: hex.EncodeToString(modification.Before.Value()),
+			After:  hex.EncodeToString(modification.After.Value()),
+		})
+	}
+
+	result := make([]*ownerDiff, 0, len(owners))
+	for _, od := range owners {
+		result = append(result, od)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Owner < result[j].Owner
+	})
+
+	return result, nil
+}
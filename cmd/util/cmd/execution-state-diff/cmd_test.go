@@ -0,0 +1,99 @@
+package execution_state_diff
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestDiffExecutionState(t *testing.T) {
+	unittest.RunWithTempDir(t, func(execdir string) {
+		const (
+			capacity           = 10
+			checkpointDistance = math.MaxInt // A large number to prevent checkpoint creation.
+			checkpointsToKeep  = 1
+		)
+
+		diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), execdir, capacity, pathfinder.PathByteSize, wal.SegmentSize)
+		require.NoError(t, err)
+		led, err := complete.NewLedger(diskWal, capacity, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+		require.NoError(t, err)
+		compactor, err := complete.NewCompactor(led, diskWal, zerolog.Nop(), capacity, checkpointDistance, checkpointsToKeep, atomic.NewBool(false))
+		require.NoError(t, err)
+		<-compactor.Ready()
+
+		owner1 := string(flow.HexToAddress("01").Bytes())
+		owner2 := string(flow.HexToAddress("02").Bytes())
+
+		keys := []ledger.Key{
+			state.RegisterIDToKey(flow.NewRegisterID(owner1, "a")),
+			state.RegisterIDToKey(flow.NewRegisterID(owner1, "b")),
+			state.RegisterIDToKey(flow.NewRegisterID(owner2, "c")),
+		}
+
+		update, err := ledger.NewUpdate(led.InitialState(), keys, []ledger.Value{{1}, {2}, {3}})
+		require.NoError(t, err)
+		from, _, err := led.Set(update)
+		require.NoError(t, err)
+
+		// modify a, remove b, add d
+		keys = append(keys[:2], state.RegisterIDToKey(flow.NewRegisterID(owner2, "d")))
+		update, err = ledger.NewUpdate(from, keys, []ledger.Value{{4}, {}, {5}})
+		require.NoError(t, err)
+		to, _, err := led.Set(update)
+		require.NoError(t, err)
+
+		<-led.Done()
+		<-compactor.Done()
+
+		var buf bytes.Buffer
+		err = DiffExecutionState(execdir, from, to, &buf)
+		require.NoError(t, err)
+
+		decoder := json.NewDecoder(&buf)
+		diffs := make([]ownerDiff, 0)
+		for decoder.More() {
+			var diff ownerDiff
+			require.NoError(t, decoder.Decode(&diff))
+			diffs = append(diffs, diff)
+		}
+
+		hexString := func(s string) string {
+			return hex.EncodeToString([]byte(s))
+		}
+
+		require.Equal(t, []ownerDiff{
+			{
+				Owner:    hexString(owner1),
+				Added:    []registerDiff{},
+				Removed:  []registerDiff{{Key: hexString("b"), Before: "02"}},
+				Modified: []registerDiff{{Key: hexString("a"), Before: "01", After: "04"}},
+			},
+			{
+				Owner:    hexString(owner2),
+				Added:    []registerDiff{{Key: hexString("d"), After: "05"}},
+				Removed:  []registerDiff{},
+				Modified: []registerDiff{},
+			},
+		}, diffs)
+
+		// unknown states can't be diffed
+		err = DiffExecutionState(execdir, from, ledger.DummyState, &buf)
+		require.Error(t, err)
+	})
+}
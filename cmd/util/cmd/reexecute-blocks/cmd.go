@@ -0,0 +1,254 @@
+package reexecute_blocks
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/crypto"
+	"github.com/onflow/flow-go/engine/execution/computation/committer"
+	"github.com/onflow/flow-go/engine/execution/computation/computer"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/environment"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/module/executiondatasync/provider"
+	"github.com/onflow/flow-go/module/local"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/module/trace"
+	"github.com/onflow/flow-go/storage"
+)
+
+var (
+	flagCheckpoint string
+	flagDatadir    string
+	flagChain      string
+	flagFromHeight uint64
+	flagToHeight   uint64
+)
+
+var Cmd = &cobra.Command{
+	Use:   "reexecute-blocks",
+	Short: "Re-executes a range of sealed blocks against a checkpoint, and reports the first divergence from the stored execution results",
+	Run:   run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagCheckpoint, "checkpoint", "",
+		"checkpoint file containing the execution state of the parent of the first block")
+	_ = Cmd.MarkFlagRequired("checkpoint")
+
+	Cmd.Flags().StringVar(&flagDatadir, "datadir", "",
+		"directory that stores the protocol state of the execution node")
+	_ = Cmd.MarkFlagRequired("datadir")
+
+	Cmd.Flags().StringVar(&flagChain, "chain", "", "Chain name")
+	_ = Cmd.MarkFlagRequired("chain")
+
+	Cmd.Flags().Uint64Var(&flagFromHeight, "from-height", 0,
+		"height of the first block to re-execute")
+	_ = Cmd.MarkFlagRequired("from-height")
+
+	Cmd.Flags().Uint64Var(&flagToHeight, "to-height", 0,
+		"height of the last block to re-execute")
+	_ = Cmd.MarkFlagRequired("to-height")
+}
+
+func run(*cobra.Command, []string) {
+	log.Info().
+		Str("checkpoint", flagCheckpoint).
+		Str("datadir", flagDatadir).
+		Str("chain", flagChain).
+		Uint64("from_height", flagFromHeight).
+		Uint64("to_height", flagToHeight).
+		Msg("flags")
+
+	if flagFromHeight > flagToHeight {
+		log.Fatal().Msg("--from-height must not be above --to-height")
+	}
+
+	chain, err := getChain(flagChain)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid chain name")
+	}
+
+	db := common.InitStorage(flagDatadir)
+	defer db.Close()
+
+	storages := common.InitStorages(db)
+	state, err := common.InitProtocolState(db, storages)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not init protocol state")
+	}
+
+	sealed, err := state.Sealed().Head()
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not get latest sealed block")
+	}
+	if flagToHeight > sealed.Height {
+		log.Fatal().Msgf("--to-height %d is above the latest sealed height %d", flagToHeight, sealed.Height)
+	}
+
+	// only the trie of the parent of the first block is needed, the tries of the
+	// following blocks are created by re-executing the blocks
+	first, err := storages.Headers.ByHeight(flagFromHeight)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("could not get block at height %d", flagFromHeight)
+	}
+	startState, err := storages.Commits.ByBlockID(first.ParentID)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("could not get state commitment of parent block %v", first.ParentID)
+	}
+
+	log.Info().Msgf("loading trie %v from checkpoint %v", startState, flagCheckpoint)
+	startTrie, err := loadTrie(flagCheckpoint, ledger.RootHash(startState))
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not load trie from checkpoint")
+	}
+	log.Info().Msgf("trie loaded, total registers: %v", startTrie.AllocatedRegCount())
+
+	led, err := complete.NewLedger(
+		&checkpointWAL{tries: []*trie.MTrie{startTrie}},
+		1+complete.DefaultCacheSize,
+		&metrics.NoopCollector{},
+		log.Logger,
+		complete.DefaultPathFinderVersion)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create ledger from checkpoint")
+	}
+
+	blockComputer, err := newBlockComputer(chain, storages.Headers, led)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create block computer")
+	}
+
+	reExecutor := &blockReExecutor{
+		log:                log.Logger,
+		headers:            storages.Headers,
+		blocks:             storages.Blocks,
+		collections:        storages.Collections,
+		commits:            storages.Commits,
+		results:            storages.Results,
+		chunkDataPacks:     storages.ChunkDataPacks,
+		events:             storages.Events,
+		transactionResults: storages.TransactionResults,
+		ledger:             led,
+		computer:           blockComputer,
+	}
+
+	divergence, err := reExecutor.reExecuteRange(context.Background(), flagFromHeight, flagToHeight)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not re-execute blocks")
+	}
+
+	if divergence != nil {
+		log.Fatal().
+			Hex("block_id", divergence.BlockID[:]).
+			Uint64("height", divergence.Height).
+			Str("field", divergence.Field).
+			Str("expected", divergence.Expected).
+			Str("actual", divergence.Actual).
+			Msg(divergence.String())
+	}
+
+	log.Info().Msgf("re-execution of blocks %d to %d matches the stored execution results", flagFromHeight, flagToHeight)
+}
+
+// loadTrie loads the trie with the given root hash from the checkpoint file. Only the leaves of
+// the trie are read from a V6 checkpoint, while all tries are loaded from other checkpoints.
+func loadTrie(checkpointFile string, rootHash ledger.RootHash) (*trie.MTrie, error) {
+	isV6, err := wal.IsCheckpointV6(checkpointFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read checkpoint header: %w", err)
+	}
+
+	if isV6 {
+		dir, fileName := filepath.Split(checkpointFile)
+		return wal.LoadTrieV6(dir, fileName, rootHash, &log.Logger)
+	}
+
+	tries, err := wal.LoadCheckpoint(checkpointFile, &log.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not load checkpoint: %w", err)
+	}
+	for _, t := range tries {
+		if t.RootHash() == rootHash {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find trie %v in checkpoint: %w", rootHash, wal.ErrTrieNotFound)
+}
+
+// newBlockComputer creates a block computer configured the same way as the one of an execution
+// node of the given chain, which commits the execution state to the given ledger.
+func newBlockComputer(chain flow.Chain, headers storage.Headers, led *complete.Ledger) (computer.BlockComputer, error) {
+	vmOpts := []fvm.Option{
+		fvm.WithChain(chain),
+		fvm.WithBlocks(environment.NewBlockFinder(headers)),
+		fvm.WithAccountStorageLimit(true),
+	}
+	chainID := chain.ChainID()
+	if chainID == flow.Testnet || chainID == flow.Sandboxnet || chainID == flow.Mainnet {
+		vmOpts = append(vmOpts,
+			fvm.WithTransactionFeesEnabled(true),
+		)
+	}
+	if chainID == flow.Testnet || chainID == flow.Sandboxnet || chainID == flow.Localnet || chainID == flow.Benchnet {
+		vmOpts = append(vmOpts,
+			fvm.WithContractDeploymentRestricted(false),
+		)
+	}
+
+	// the SPoCKs of the re-executed blocks are not compared, so they are signed with a random key
+	seed := make([]byte, crypto.KeyGenSeedMinLenBLSBLS12381)
+	_, err := rand.Read(seed)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate seed: %w", err)
+	}
+	stakingKey, err := crypto.GeneratePrivateKey(crypto.BLSBLS12381, seed)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate staking key: %w", err)
+	}
+	me, err := local.New(&flow.Identity{Role: flow.RoleExecution, StakingPubKey: stakingKey.PublicKey()}, stakingKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not create local: %w", err)
+	}
+
+	executionDataProvider := provider.NewProvider(
+		log.Logger,
+		metrics.NewNoopCollector(),
+		execution_data.DefaultSerializer,
+		newDiscardingBlobService(),
+		&noopTrackerStorage{},
+	)
+
+	return computer.NewBlockComputer(
+		fvm.NewVirtualMachine(),
+		fvm.NewContext(vmOpts...),
+		metrics.NewNoopCollector(),
+		trace.NewNoopTracer(),
+		log.Logger,
+		committer.NewLedgerViewCommitter(led, trace.NewNoopTracer()),
+		me,
+		executionDataProvider,
+	)
+}
+
+func getChain(chainName string) (chain flow.Chain, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid chain: %s", r)
+		}
+	}()
+	chain = flow.ChainID(chainName).Chain()
+	return
+}
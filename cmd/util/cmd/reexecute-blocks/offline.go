@@ -0,0 +1,95 @@
+package reexecute_blocks
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/executiondatasync/tracker"
+	"github.com/onflow/flow-go/network"
+)
+
+// checkpointWAL is a write-ahead log which only contains the tries of a checkpoint, and does not
+// record any updates, so that re-executed blocks are never persisted.
+type checkpointWAL struct {
+	fixtures.NoopWAL
+	tries []*trie.MTrie
+}
+
+func (w *checkpointWAL) ReplayOnForest(forest *mtrie.Forest) error {
+	return forest.AddTries(w.tries)
+}
+
+// discardingBlobService is a blob service which discards all added blobs.  Re-executed blocks only
+// need the IDs of their execution data, which do not depend on the blobs being stored.
+type discardingBlobService struct {
+	component.Component
+}
+
+var _ network.BlobService = (*discardingBlobService)(nil)
+
+func newDiscardingBlobService() *discardingBlobService {
+	return &discardingBlobService{
+		Component: component.NewComponentManagerBuilder().Build(),
+	}
+}
+
+func (bs *discardingBlobService) GetBlob(context.Context, cid.Cid) (blobs.Blob, error) {
+	return nil, network.ErrBlobNotFound
+}
+
+func (bs *discardingBlobService) GetBlobs(context.Context, []cid.Cid) <-chan blobs.Blob {
+	ch := make(chan blobs.Blob)
+	close(ch)
+	return ch
+}
+
+func (bs *discardingBlobService) AddBlob(context.Context, blobs.Blob) error {
+	return nil
+}
+
+func (bs *discardingBlobService) AddBlobs(context.Context, []blobs.Blob) error {
+	return nil
+}
+
+func (bs *discardingBlobService) DeleteBlob(context.Context, cid.Cid) error {
+	return nil
+}
+
+func (bs *discardingBlobService) GetSession(context.Context) network.BlobGetter {
+	return bs
+}
+
+func (bs *discardingBlobService) TriggerReprovide(context.Context) error {
+	return nil
+}
+
+// noopTrackerStorage is an execution data tracker storage which does not track any blobs.
+type noopTrackerStorage struct{}
+
+var _ tracker.Storage = (*noopTrackerStorage)(nil)
+
+func (s *noopTrackerStorage) Update(f tracker.UpdateFn) error {
+	return f(func(uint64, ...cid.Cid) error { return nil })
+}
+
+func (s *noopTrackerStorage) GetFulfilledHeight() (uint64, error) {
+	return 0, nil
+}
+
+func (s *noopTrackerStorage) SetFulfilledHeight(uint64) error {
+	return nil
+}
+
+func (s *noopTrackerStorage) GetPrunedHeight() (uint64, error) {
+	return 0, nil
+}
+
+func (s *noopTrackerStorage) PruneUpToHeight(uint64) error {
+	return nil
+}
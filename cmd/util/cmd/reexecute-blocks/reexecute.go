@@ -0,0 +1,383 @@
+package reexecute_blocks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution"
+	"github.com/onflow/flow-go/engine/execution/computation/computer"
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/fvm/derived"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/mempool/entity"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/storage"
+)
+
+// Divergence describes the first difference found between the stored execution of a block and
+// its re-execution.
+type Divergence struct {
+	BlockID flow.Identifier
+	Height  uint64
+	// Field is the part of the execution that diverged, e.g. "chunk 1 end state".
+	Field    string
+	Expected string
+	Actual   string
+}
+
+func (d *Divergence) String() string {
+	return fmt.Sprintf(
+		"execution of block %v (height %d) diverged at %s: expected %s, got %s",
+		d.BlockID,
+		d.Height,
+		d.Field,
+		d.Expected,
+		d.Actual)
+}
+
+// blockExecution contains the outputs of executing a block.
+type blockExecution struct {
+	result             *flow.ExecutionResult
+	chunkDataPacks     []*flow.ChunkDataPack
+	events             []flow.Event
+	transactionResults []flow.TransactionResult
+}
+
+// blockReExecutor re-executes sealed blocks against a ledger, and compares the outputs against
+// the execution stored in the protocol database.
+type blockReExecutor struct {
+	log                zerolog.Logger
+	headers            storage.Headers
+	blocks             storage.Blocks
+	collections        storage.Collections
+	commits            storage.Commits
+	results            storage.ExecutionResults
+	chunkDataPacks     storage.ChunkDataPacks
+	events             storage.Events
+	transactionResults storage.TransactionResults
+	ledger             ledger.Ledger
+	computer           computer.BlockComputer
+}
+
+// reExecuteRange re-executes the blocks with heights in [from, to], and returns the first
+// divergence found, or nil if the re-execution of all blocks matches their stored execution.
+func (r *blockReExecutor) reExecuteRange(ctx context.Context, from uint64, to uint64) (*Divergence, error) {
+	for height := from; height <= to; height++ {
+		divergence, err := r.reExecuteHeight(ctx, height)
+		if err != nil {
+			return nil, fmt.Errorf("could not re-execute block at height %d: %w", height, err)
+		}
+
+		if divergence != nil {
+			return divergence, nil
+		}
+
+		r.log.Info().Uint64("height", height).Msg("re-execution matches stored execution")
+	}
+
+	return nil, nil
+}
+
+// reExecuteHeight re-executes the finalized block at the given height, starting from the stored
+// state commitment of its parent, and returns the first divergence from its stored execution.
+func (r *blockReExecutor) reExecuteHeight(ctx context.Context, height uint64) (*Divergence, error) {
+	header, err := r.headers.ByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get header: %w", err)
+	}
+	blockID := header.ID()
+
+	expected, err := r.storedExecution(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get stored execution of block %v: %w", blockID, err)
+	}
+
+	actual, err := r.execute(ctx, blockID, expected.result.PreviousResultID)
+	if err != nil {
+		return nil, fmt.Errorf("could not execute block %v: %w", blockID, err)
+	}
+
+	return findDivergence(header, expected, actual), nil
+}
+
+// storedExecution returns the outputs of the execution of the block stored in the protocol database.
+func (r *blockReExecutor) storedExecution(blockID flow.Identifier) (*blockExecution, error) {
+	result, err := r.results.ByBlockID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get execution result: %w", err)
+	}
+
+	chunkDataPacks := make([]*flow.ChunkDataPack, 0, len(result.Chunks))
+	for _, chunk := range result.Chunks {
+		chunkDataPack, err := r.chunkDataPacks.ByChunkID(chunk.ID())
+		if err != nil {
+			return nil, fmt.Errorf("could not get chunk data pack of chunk %d: %w", chunk.Index, err)
+		}
+		chunkDataPacks = append(chunkDataPacks, chunkDataPack)
+	}
+
+	events, err := r.events.ByBlockID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get events: %w", err)
+	}
+
+	// events are stored by transaction ID, so they are sorted into execution order
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].TransactionIndex != events[j].TransactionIndex {
+			return events[i].TransactionIndex < events[j].TransactionIndex
+		}
+		return events[i].EventIndex < events[j].EventIndex
+	})
+
+	transactionResults, err := r.transactionResults.ByBlockID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get transaction results: %w", err)
+	}
+
+	return &blockExecution{
+		result:             result,
+		chunkDataPacks:     chunkDataPacks,
+		events:             events,
+		transactionResults: transactionResults,
+	}, nil
+}
+
+// execute executes the block against the ledger, starting from the stored state commitment of
+// its parent, and returns the outputs of the execution.
+func (r *blockReExecutor) execute(
+	ctx context.Context,
+	blockID flow.Identifier,
+	previousResultID flow.Identifier,
+) (*blockExecution, error) {
+	block, err := r.blocks.ByID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get block: %w", err)
+	}
+
+	startState, err := r.commits.ByBlockID(block.Header.ParentID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get state commitment of parent block %v: %w", block.Header.ParentID, err)
+	}
+
+	if !r.ledger.HasState(ledger.State(startState)) {
+		return nil, fmt.Errorf("state commitment %v of parent block %v is not in the checkpoint", startState, block.Header.ParentID)
+	}
+
+	collections := make(map[flow.Identifier]*entity.CompleteCollection, len(block.Payload.Guarantees))
+	for _, guarantee := range block.Payload.Guarantees {
+		collection, err := r.collections.ByID(guarantee.CollectionID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get collection %v: %w", guarantee.CollectionID, err)
+		}
+
+		collections[guarantee.ID()] = &entity.CompleteCollection{
+			Guarantee:    guarantee,
+			Transactions: collection.Transactions,
+		}
+	}
+
+	executableBlock := &entity.ExecutableBlock{
+		Block:               block,
+		CompleteCollections: collections,
+		StartState:          &startState,
+	}
+
+	view := delta.NewView(state.LedgerGetRegister(r.ledger, startState))
+
+	computationResult, err := r.computer.ExecuteBlock(
+		ctx,
+		executableBlock,
+		view,
+		derived.NewEmptyDerivedBlockData())
+	if err != nil {
+		return nil, err
+	}
+
+	_, chunkDataPacks, result, err := execution.GenerateExecutionResultAndChunkDataPacks(
+		metrics.NewNoopCollector(),
+		previousResultID,
+		startState,
+		computationResult)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate execution result: %w", err)
+	}
+
+	var events []flow.Event
+	for _, collectionEvents := range computationResult.Events {
+		events = append(events, collectionEvents...)
+	}
+
+	return &blockExecution{
+		result:             result,
+		chunkDataPacks:     chunkDataPacks,
+		events:             events,
+		transactionResults: computationResult.TransactionResults,
+	}, nil
+}
+
+// findDivergence compares the re-execution of the block against its stored execution, and returns
+// the first difference found, or nil if they match.
+//
+// SPoCKs are not compared, since the block is not re-executed with the staking key of the
+// execution node that executed it.
+func findDivergence(header *flow.Header, expected *blockExecution, actual *blockExecution) *Divergence {
+	diverged := func(field string, expected interface{}, actual interface{}) *Divergence {
+		return &Divergence{
+			BlockID:  header.ID(),
+			Height:   header.Height,
+			Field:    field,
+			Expected: fmt.Sprint(expected),
+			Actual:   fmt.Sprint(actual),
+		}
+	}
+
+	expectedChunks := expected.result.Chunks
+	actualChunks := actual.result.Chunks
+	if len(expectedChunks) != len(actualChunks) {
+		return diverged("number of chunks", len(expectedChunks), len(actualChunks))
+	}
+
+	for i, expectedChunk := range expectedChunks {
+		actualChunk := actualChunks[i]
+
+		if expectedChunk.StartState != actualChunk.StartState {
+			return diverged(fmt.Sprintf("chunk %d start state", i), expectedChunk.StartState, actualChunk.StartState)
+		}
+
+		if expectedChunk.NumberOfTransactions != actualChunk.NumberOfTransactions {
+			return diverged(fmt.Sprintf("chunk %d number of transactions", i), expectedChunk.NumberOfTransactions, actualChunk.NumberOfTransactions)
+		}
+
+		if expectedChunk.EventCollection != actualChunk.EventCollection {
+			// report the diverging event, which is more useful than the events hash
+			divergence := findEventDivergence(expected.events, actual.events, diverged)
+			if divergence != nil {
+				return divergence
+			}
+			return diverged(fmt.Sprintf("chunk %d event collection", i), expectedChunk.EventCollection, actualChunk.EventCollection)
+		}
+
+		if expectedChunk.EndState != actualChunk.EndState {
+			// a transaction failing differently is the most likely cause of diverging states
+			divergence := findTransactionResultDivergence(expected.transactionResults, actual.transactionResults, diverged)
+			if divergence != nil {
+				return divergence
+			}
+			return diverged(fmt.Sprintf("chunk %d end state", i), expectedChunk.EndState, actualChunk.EndState)
+		}
+	}
+
+	divergence := findEventDivergence(expected.events, actual.events, diverged)
+	if divergence != nil {
+		return divergence
+	}
+
+	divergence = findTransactionResultDivergence(expected.transactionResults, actual.transactionResults, diverged)
+	if divergence != nil {
+		return divergence
+	}
+
+	if len(expected.result.ServiceEvents) != len(actual.result.ServiceEvents) {
+		return diverged("number of service events", len(expected.result.ServiceEvents), len(actual.result.ServiceEvents))
+	}
+	for i, expectedEvent := range expected.result.ServiceEvents {
+		actualEvent := actual.result.ServiceEvents[i]
+		equal, err := expectedEvent.EqualTo(&actualEvent)
+		if err != nil || !equal {
+			return diverged(fmt.Sprintf("service event %d", i), expectedEvent.Type, actualEvent.Type)
+		}
+	}
+
+	if expected.result.ExecutionDataID != actual.result.ExecutionDataID {
+		return diverged("execution data ID", expected.result.ExecutionDataID, actual.result.ExecutionDataID)
+	}
+
+	for i, expectedChunkDataPack := range expected.chunkDataPacks {
+		actualChunkDataPack := actual.chunkDataPacks[i]
+
+		if expectedChunkDataPack.StartState != actualChunkDataPack.StartState {
+			return diverged(fmt.Sprintf("chunk data pack %d start state", i), expectedChunkDataPack.StartState, actualChunkDataPack.StartState)
+		}
+
+		if !bytes.Equal(expectedChunkDataPack.Proof, actualChunkDataPack.Proof) {
+			return diverged(fmt.Sprintf("chunk data pack %d proof", i), fmt.Sprintf("%x", expectedChunkDataPack.Proof), fmt.Sprintf("%x", actualChunkDataPack.Proof))
+		}
+	}
+
+	if expected.result.ID() != actual.result.ID() {
+		return diverged("execution result ID", expected.result.ID(), actual.result.ID())
+	}
+
+	return nil
+}
+
+// findEventDivergence returns the first event which differs between the expected and actual events.
+func findEventDivergence(
+	expected []flow.Event,
+	actual []flow.Event,
+	diverged func(string, interface{}, interface{}) *Divergence,
+) *Divergence {
+	for i := 0; i < len(expected) && i < len(actual); i++ {
+		expectedEvent := expected[i]
+		actualEvent := actual[i]
+
+		field := fmt.Sprintf(
+			"event %d (transaction %d, event index %d)",
+			i,
+			expectedEvent.TransactionIndex,
+			expectedEvent.EventIndex)
+
+		if expectedEvent.TransactionID != actualEvent.TransactionID ||
+			expectedEvent.TransactionIndex != actualEvent.TransactionIndex ||
+			expectedEvent.EventIndex != actualEvent.EventIndex ||
+			expectedEvent.Type != actualEvent.Type {
+			return diverged(field, expectedEvent.String(), actualEvent.String())
+		}
+
+		if !bytes.Equal(expectedEvent.Payload, actualEvent.Payload) {
+			return diverged(field+" payload", string(expectedEvent.Payload), string(actualEvent.Payload))
+		}
+	}
+
+	if len(expected) != len(actual) {
+		return diverged("number of events", len(expected), len(actual))
+	}
+
+	return nil
+}
+
+// findTransactionResultDivergence returns the first transaction result which differs between the
+// expected and actual transaction results.
+func findTransactionResultDivergence(
+	expected []flow.TransactionResult,
+	actual []flow.TransactionResult,
+	diverged func(string, interface{}, interface{}) *Divergence,
+) *Divergence {
+	for i := 0; i < len(expected) && i < len(actual); i++ {
+		expectedResult := expected[i]
+		actualResult := actual[i]
+
+		if expectedResult.TransactionID != actualResult.TransactionID {
+			return diverged(fmt.Sprintf("transaction %d ID", i), expectedResult.TransactionID, actualResult.TransactionID)
+		}
+
+		if expectedResult.ErrorMessage != actualResult.ErrorMessage {
+			return diverged(
+				fmt.Sprintf("transaction %d (%v) error message", i, expectedResult.TransactionID),
+				fmt.Sprintf("%q", expectedResult.ErrorMessage),
+				fmt.Sprintf("%q", actualResult.ErrorMessage))
+		}
+	}
+
+	if len(expected) != len(actual) {
+		return diverged("number of transactions", len(expected), len(actual))
+	}
+
+	return nil
+}
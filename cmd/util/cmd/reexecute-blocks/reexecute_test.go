@@ -0,0 +1,121 @@
+package reexecute_blocks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func blockExecutionFixture(header *flow.Header) *blockExecution {
+	txID := unittest.IdentifierFixture()
+	result := unittest.ExecutionResultFixture(unittest.WithChunks(2))
+	result.BlockID = header.ID()
+	result.ServiceEvents = nil
+
+	chunkDataPacks := make([]*flow.ChunkDataPack, 0, len(result.Chunks))
+	for _, chunk := range result.Chunks {
+		chunkDataPacks = append(chunkDataPacks, unittest.ChunkDataPackFixture(chunk.ID()))
+	}
+
+	return &blockExecution{
+		result:         result,
+		chunkDataPacks: chunkDataPacks,
+		events: []flow.Event{
+			unittest.EventFixture(flow.EventAccountCreated, 0, 0, txID, 0),
+			unittest.EventFixture(flow.EventAccountCreated, 0, 1, txID, 0),
+		},
+		transactionResults: []flow.TransactionResult{
+			{TransactionID: txID},
+		},
+	}
+}
+
+// copyExecution returns a deep copy of the block execution, which can be modified without
+// affecting the original.
+func copyExecution(execution *blockExecution) *blockExecution {
+	result := *execution.result
+	result.Chunks = make(flow.ChunkList, 0, len(execution.result.Chunks))
+	for _, chunk := range execution.result.Chunks {
+		chunkCopy := *chunk
+		result.Chunks = append(result.Chunks, &chunkCopy)
+	}
+
+	chunkDataPacks := make([]*flow.ChunkDataPack, 0, len(execution.chunkDataPacks))
+	for _, chunkDataPack := range execution.chunkDataPacks {
+		chunkDataPackCopy := *chunkDataPack
+		chunkDataPacks = append(chunkDataPacks, &chunkDataPackCopy)
+	}
+
+	return &blockExecution{
+		result:             &result,
+		chunkDataPacks:     chunkDataPacks,
+		events:             append([]flow.Event(nil), execution.events...),
+		transactionResults: append([]flow.TransactionResult(nil), execution.transactionResults...),
+	}
+}
+
+func TestFindDivergence(t *testing.T) {
+	header := unittest.BlockHeaderFixture()
+	expected := blockExecutionFixture(header)
+
+	t.Run("identical execution", func(t *testing.T) {
+		divergence := findDivergence(header, expected, copyExecution(expected))
+		require.Nil(t, divergence)
+	})
+
+	t.Run("diverging events", func(t *testing.T) {
+		actual := copyExecution(expected)
+		actual.result.Chunks[0].EventCollection = unittest.IdentifierFixture()
+		actual.events[1].Payload = []byte("diverged")
+
+		divergence := findDivergence(header, expected, actual)
+		require.NotNil(t, divergence)
+		require.Equal(t, header.ID(), divergence.BlockID)
+		require.Equal(t, header.Height, divergence.Height)
+		require.Equal(t, "event 1 (transaction 0, event index 1) payload", divergence.Field)
+		require.Equal(t, "diverged", divergence.Actual)
+	})
+
+	t.Run("diverging transaction error", func(t *testing.T) {
+		actual := copyExecution(expected)
+		actual.result.Chunks[0].EndState = unittest.StateCommitmentFixture()
+		actual.transactionResults[0].ErrorMessage = "failed"
+
+		divergence := findDivergence(header, expected, actual)
+		require.NotNil(t, divergence)
+		require.Contains(t, divergence.Field, "transaction 0")
+		require.Equal(t, `""`, divergence.Expected)
+		require.Equal(t, `"failed"`, divergence.Actual)
+	})
+
+	t.Run("diverging end state", func(t *testing.T) {
+		actual := copyExecution(expected)
+		actual.result.Chunks[1].EndState = unittest.StateCommitmentFixture()
+
+		divergence := findDivergence(header, expected, actual)
+		require.NotNil(t, divergence)
+		require.Equal(t, "chunk 1 end state", divergence.Field)
+	})
+
+	t.Run("diverging execution data", func(t *testing.T) {
+		actual := copyExecution(expected)
+		actual.result.ExecutionDataID = unittest.IdentifierFixture()
+
+		divergence := findDivergence(header, expected, actual)
+		require.NotNil(t, divergence)
+		require.Equal(t, "execution data ID", divergence.Field)
+	})
+
+	t.Run("diverging chunk data pack", func(t *testing.T) {
+		actual := copyExecution(expected)
+		actual.chunkDataPacks[1].Proof = []byte{1, 2, 3}
+
+		divergence := findDivergence(header, expected, actual)
+		require.NotNil(t, divergence)
+		require.Equal(t, "chunk data pack 1 proof", divergence.Field)
+		require.Equal(t, "010203", divergence.Actual)
+	})
+}
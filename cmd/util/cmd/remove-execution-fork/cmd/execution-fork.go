@@ -24,14 +24,25 @@ func run(*cobra.Command, []string) {
 	// err := db.Update(operation.InsertExecutionForkEvidence(expectedSeals))
 
 	if err == storage.ErrNotFound {
-		log.Info().Msg("no execution fork was found, exit")
+		log.Info().Msg("no execution fork was found")
+	} else if err != nil {
+		log.Fatal().Err(err).Msg("could not remove execution fork")
+		return
+	} else {
+		log.Info().Msg("execution fork removed")
+	}
+
+	// execution nodes pause execution while the block whose result conflicts with its sealed result is stored
+	err = db.Update(operation.RemoveExecutionForkedBlock())
+	if err == storage.ErrNotFound {
+		log.Info().Msg("no forked block was found, exit")
 		return
 	}
 
 	if err != nil {
-		log.Fatal().Err(err).Msg("could not remove execution fork")
+		log.Fatal().Err(err).Msg("could not remove forked block")
 		return
 	}
 
-	log.Info().Msg("execution fork removed")
+	log.Info().Msg("forked block removed, execution resumes after restarting the execution node")
 }
@@ -59,12 +59,20 @@ func (h *FlowAccessAPIForwarder) reconnectingClient(i int) error {
 		identity := h.ids[i]
 		var connection *grpc.ClientConn
 		var err error
+
+		opts := []grpc.DialOption{
+			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(grpcutils.DefaultMaxMsgSize)),
+			backend.WithClientUnaryInterceptor(timeout),
+		}
+		if h.circuitBreakers[i] != nil {
+			opts = append(opts, backend.WithClientCircuitBreakerInterceptor(h.circuitBreakers[i]))
+		}
+
 		if identity.NetworkPubKey == nil {
 			connection, err = grpc.Dial(
 				identity.Address,
-				grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(grpcutils.DefaultMaxMsgSize)),
-				grpc.WithInsecure(), //nolint:staticcheck
-				backend.WithClientUnaryInterceptor(timeout))
+				append(opts, grpc.WithInsecure())..., //nolint:staticcheck
+			)
 			if err != nil {
 				return err
 			}
@@ -76,9 +84,8 @@ func (h *FlowAccessAPIForwarder) reconnectingClient(i int) error {
 
 			connection, err = grpc.Dial(
 				identity.Address,
-				grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(grpcutils.DefaultMaxMsgSize)),
-				grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
-				backend.WithClientUnaryInterceptor(timeout))
+				append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))...,
+			)
 			if err != nil {
 				return fmt.Errorf("cannot connect to %s %w", identity.Address, err)
 			}
@@ -118,6 +125,10 @@ func (h *FlowAccessAPIForwarder) faultTolerantClient() (access.AccessAPIClient,
 	for i := 0; i < retryMax; i++ {
 		h.roundRobin++
 		h.roundRobin = h.roundRobin % len(h.upstream)
+		if h.circuitOpen(h.roundRobin) {
+			err = fmt.Errorf("circuit breaker is open for %s", h.ids[h.roundRobin].Address)
+			continue
+		}
 		err = h.reconnectingClient(h.roundRobin)
 		if err != nil {
 			continue
@@ -291,10 +302,25 @@ type FlowAccessAPIForwarder struct {
 	upstream    []access.AccessAPIClient
 	connections []*grpc.ClientConn
 	timeout     time.Duration
-}
 
-func NewFlowAccessAPIForwarder(identities flow.IdentityList, timeout time.Duration) (*FlowAccessAPIForwarder, error) {
-	forwarder := &FlowAccessAPIForwarder{}
+	// circuitBreakerConfig configures the circuit breaker of each upstream node
+	circuitBreakerConfig backend.CircuitBreakerConfig
+	circuitBreakers      []*backend.CircuitBreaker
+	// hedgeDelay is the delay after which a read-only request that has not completed is also
+	// forwarded to the next upstream node. A delay of 0 disables hedged requests.
+	hedgeDelay time.Duration
+}
+
+func NewFlowAccessAPIForwarder(
+	identities flow.IdentityList,
+	timeout time.Duration,
+	circuitBreakerConfig backend.CircuitBreakerConfig,
+	hedgeDelay time.Duration,
+) (*FlowAccessAPIForwarder, error) {
+	forwarder := &FlowAccessAPIForwarder{
+		circuitBreakerConfig: circuitBreakerConfig,
+		hedgeDelay:           hedgeDelay,
+	}
 	err := forwarder.setFlowAccessAPI(identities, timeout)
 	return forwarder, err
 }
@@ -308,11 +334,16 @@ func (ret *FlowAccessAPIForwarder) setFlowAccessAPI(accessNodeAddressAndPort flo
 	ret.ids = accessNodeAddressAndPort
 	ret.upstream = make([]access.AccessAPIClient, accessNodeAddressAndPort.Count())
 	ret.connections = make([]*grpc.ClientConn, accessNodeAddressAndPort.Count())
+	ret.circuitBreakers = make([]*backend.CircuitBreaker, accessNodeAddressAndPort.Count())
 	for i, identity := range accessNodeAddressAndPort {
 		// Store the faultTolerantClient setup parameters such as address, public, key and timeout, so that
 		// we can refresh the API on connection loss
 		ret.ids[i] = identity
 
+		if ret.circuitBreakerConfig.Enabled {
+			ret.circuitBreakers[i] = backend.NewCircuitBreaker(ret.circuitBreakerConfig)
+		}
+
 		// We fail on any single error on startup, so that
 		// we identify bootstrapping errors early
 		err := ret.reconnectingClient(i)
@@ -325,78 +356,127 @@ func (ret *FlowAccessAPIForwarder) setFlowAccessAPI(accessNodeAddressAndPort flo
 	return nil
 }
 
+// circuitOpen returns true if the circuit breaker of the upstream node with the given index is
+// open. It must be called with the lock held.
+func (h *FlowAccessAPIForwarder) circuitOpen(i int) bool {
+	return h.circuitBreakers[i] != nil && h.circuitBreakers[i].IsOpen()
+}
+
+// hedgedUpstreams returns the indices of the upstream nodes in the order the next hedged request
+// is forwarded to them. Upstream nodes with an open circuit breaker are skipped, unless the
+// circuits of all upstream nodes are open.
+func (h *FlowAccessAPIForwarder) hedgedUpstreams() []int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.roundRobin++
+	h.roundRobin = h.roundRobin % len(h.upstream)
+
+	var available, open []int
+	for i := 0; i < len(h.upstream); i++ {
+		index := (h.roundRobin + i) % len(h.upstream)
+		if h.circuitOpen(index) {
+			open = append(open, index)
+			continue
+		}
+		available = append(available, index)
+	}
+
+	if len(available) == 0 {
+		return open
+	}
+	return available
+}
+
+// upstreamClient returns a client for the upstream node with the given index, reconnecting if
+// the connection is not ready.
+func (h *FlowAccessAPIForwarder) upstreamClient(i int) (access.AccessAPIClient, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	err := h.reconnectingClient(i)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, err.Error())
+	}
+	return h.upstream[i], nil
+}
+
+// forward forwards a read-only request to the upstream nodes. If hedged requests are enabled, the
+// request is also forwarded to the next upstream node when no response was received within the
+// hedge delay, and the first response is returned.
+func forward[Req any, Resp any](
+	h *FlowAccessAPIForwarder,
+	ctx context.Context,
+	req Req,
+	method func(access.AccessAPIClient, context.Context, Req, ...grpc.CallOption) (Resp, error),
+) (Resp, error) {
+	if h.hedgeDelay == 0 || len(h.upstream) == 0 {
+		upstream, err := h.faultTolerantClient()
+		if err != nil {
+			var empty Resp
+			return empty, err
+		}
+		return method(upstream, ctx, req)
+	}
+
+	var resp Resp
+	var respErr error
+	backend.HedgedCall(ctx, h.hedgeDelay, h.hedgedUpstreams(), func(ctx context.Context, i int) (Resp, error) {
+		upstream, err := h.upstreamClient(i)
+		if err != nil {
+			var empty Resp
+			return empty, err
+		}
+		return method(upstream, ctx, req)
+	}, func(_ int, result Resp, err error) bool {
+		resp, respErr = result, err
+		// errors that do not indicate a failure of the upstream node are valid responses
+		return !backend.IsNodeFailure(err)
+	})
+
+	return resp, respErr
+}
+
 // Ping pings the service. It is special in the sense that it responds successful,
 // only if all underlying services are ready.
 func (h *FlowAccessAPIForwarder) Ping(context context.Context, req *access.PingRequest) (*access.PingResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.Ping(context, req)
+	return forward(h, context, req, access.AccessAPIClient.Ping)
 }
 
 func (h *FlowAccessAPIForwarder) GetLatestBlockHeader(context context.Context, req *access.GetLatestBlockHeaderRequest) (*access.BlockHeaderResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetLatestBlockHeader(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetLatestBlockHeader)
 }
 
 func (h *FlowAccessAPIForwarder) GetBlockHeaderByID(context context.Context, req *access.GetBlockHeaderByIDRequest) (*access.BlockHeaderResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetBlockHeaderByID(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetBlockHeaderByID)
 }
 
 func (h *FlowAccessAPIForwarder) GetBlockHeaderByHeight(context context.Context, req *access.GetBlockHeaderByHeightRequest) (*access.BlockHeaderResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetBlockHeaderByHeight(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetBlockHeaderByHeight)
 }
 
 func (h *FlowAccessAPIForwarder) GetLatestBlock(context context.Context, req *access.GetLatestBlockRequest) (*access.BlockResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetLatestBlock(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetLatestBlock)
 }
 
 func (h *FlowAccessAPIForwarder) GetBlockByID(context context.Context, req *access.GetBlockByIDRequest) (*access.BlockResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetBlockByID(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetBlockByID)
 }
 
 func (h *FlowAccessAPIForwarder) GetBlockByHeight(context context.Context, req *access.GetBlockByHeightRequest) (*access.BlockResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetBlockByHeight(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetBlockByHeight)
 }
 
 func (h *FlowAccessAPIForwarder) GetCollectionByID(context context.Context, req *access.GetCollectionByIDRequest) (*access.CollectionResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetCollectionByID(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetCollectionByID)
 }
 
 func (h *FlowAccessAPIForwarder) SendTransaction(context context.Context, req *access.SendTransactionRequest) (*access.SendTransactionResponse, error) {
@@ -410,143 +490,79 @@ func (h *FlowAccessAPIForwarder) SendTransaction(context context.Context, req *a
 
 func (h *FlowAccessAPIForwarder) GetTransaction(context context.Context, req *access.GetTransactionRequest) (*access.TransactionResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetTransaction(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetTransaction)
 }
 
 func (h *FlowAccessAPIForwarder) GetTransactionResult(context context.Context, req *access.GetTransactionRequest) (*access.TransactionResultResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetTransactionResult(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetTransactionResult)
 }
 
 func (h *FlowAccessAPIForwarder) GetTransactionResultByIndex(context context.Context, req *access.GetTransactionByIndexRequest) (*access.TransactionResultResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetTransactionResultByIndex(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetTransactionResultByIndex)
 }
 
 func (h *FlowAccessAPIForwarder) GetTransactionResultsByBlockID(context context.Context, req *access.GetTransactionsByBlockIDRequest) (*access.TransactionResultsResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetTransactionResultsByBlockID(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetTransactionResultsByBlockID)
 }
 
 func (h *FlowAccessAPIForwarder) GetTransactionsByBlockID(context context.Context, req *access.GetTransactionsByBlockIDRequest) (*access.TransactionsResponse, error) {
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetTransactionsByBlockID(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetTransactionsByBlockID)
 }
 
 func (h *FlowAccessAPIForwarder) GetAccount(context context.Context, req *access.GetAccountRequest) (*access.GetAccountResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetAccount(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetAccount)
 }
 
 func (h *FlowAccessAPIForwarder) GetAccountAtLatestBlock(context context.Context, req *access.GetAccountAtLatestBlockRequest) (*access.AccountResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetAccountAtLatestBlock(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetAccountAtLatestBlock)
 }
 
 func (h *FlowAccessAPIForwarder) GetAccountAtBlockHeight(context context.Context, req *access.GetAccountAtBlockHeightRequest) (*access.AccountResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetAccountAtBlockHeight(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetAccountAtBlockHeight)
 }
 
 func (h *FlowAccessAPIForwarder) ExecuteScriptAtLatestBlock(context context.Context, req *access.ExecuteScriptAtLatestBlockRequest) (*access.ExecuteScriptResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.ExecuteScriptAtLatestBlock(context, req)
+	return forward(h, context, req, access.AccessAPIClient.ExecuteScriptAtLatestBlock)
 }
 
 func (h *FlowAccessAPIForwarder) ExecuteScriptAtBlockID(context context.Context, req *access.ExecuteScriptAtBlockIDRequest) (*access.ExecuteScriptResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.ExecuteScriptAtBlockID(context, req)
+	return forward(h, context, req, access.AccessAPIClient.ExecuteScriptAtBlockID)
 }
 
 func (h *FlowAccessAPIForwarder) ExecuteScriptAtBlockHeight(context context.Context, req *access.ExecuteScriptAtBlockHeightRequest) (*access.ExecuteScriptResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.ExecuteScriptAtBlockHeight(context, req)
+	return forward(h, context, req, access.AccessAPIClient.ExecuteScriptAtBlockHeight)
 }
 
 func (h *FlowAccessAPIForwarder) GetEventsForHeightRange(context context.Context, req *access.GetEventsForHeightRangeRequest) (*access.EventsResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetEventsForHeightRange(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetEventsForHeightRange)
 }
 
 func (h *FlowAccessAPIForwarder) GetEventsForBlockIDs(context context.Context, req *access.GetEventsForBlockIDsRequest) (*access.EventsResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetEventsForBlockIDs(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetEventsForBlockIDs)
 }
 
 func (h *FlowAccessAPIForwarder) GetNetworkParameters(context context.Context, req *access.GetNetworkParametersRequest) (*access.GetNetworkParametersResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetNetworkParameters(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetNetworkParameters)
 }
 
 func (h *FlowAccessAPIForwarder) GetLatestProtocolStateSnapshot(context context.Context, req *access.GetLatestProtocolStateSnapshotRequest) (*access.ProtocolStateSnapshotResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetLatestProtocolStateSnapshot(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetLatestProtocolStateSnapshot)
 }
 
 func (h *FlowAccessAPIForwarder) GetExecutionResultForBlockID(context context.Context, req *access.GetExecutionResultForBlockIDRequest) (*access.ExecutionResultForBlockIDResponse, error) {
 	// This is a passthrough request
-	upstream, err := h.faultTolerantClient()
-	if err != nil {
-		return nil, err
-	}
-	return upstream.GetExecutionResultForBlockID(context, req)
+	return forward(h, context, req, access.AccessAPIClient.GetExecutionResultForBlockID)
 }
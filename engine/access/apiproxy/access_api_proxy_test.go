@@ -11,6 +11,7 @@ import (
 	"google.golang.org/grpc"
 	grpcinsecure "google.golang.org/grpc/credentials/insecure"
 
+	"github.com/onflow/flow-go/engine/access/rpc/backend"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/utils/grpcutils"
 	"github.com/onflow/flow-go/utils/unittest"
@@ -189,6 +190,47 @@ func TestNewFlowCachedAccessAPIProxy(t *testing.T) {
 	<-done
 }
 
+// TestHedgedFlowAccessAPIProxy tests that a slow upstream node does not stall hedged requests
+func TestHedgedFlowAccessAPIProxy(t *testing.T) {
+	done := make(chan int)
+
+	// Bring up a slow and a fast upstream server
+	slow, _, err := newFlowLiteWithAPI("tcp", unittest.IPPort("11636"), SlowMockFlowAccessAPI{delay: 2 * time.Second}, done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, _, err := newFlowLite("tcp", unittest.IPPort("11637"), done)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Prepare a proxy that hedges requests after 50ms
+	l := flow.IdentityList{{Address: unittest.IPPort("11636")}, {Address: unittest.IPPort("11637")}}
+	c, err := NewFlowAccessAPIForwarder(l, 5*time.Second, backend.DefaultCircuitBreakerConfig(), 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Requests are answered by the fast server, regardless of the server tried first
+	background := context.Background()
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		_, err = c.Ping(background, &access.PingRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if time.Since(start) > time.Second {
+			t.Fatal(fmt.Errorf("hedged request was stalled by the slow server"))
+		}
+	}
+
+	slow.Stop()
+	fast.Stop()
+
+	<-done
+	<-done
+}
+
 func makeFlowLite(address string, done chan int) (net.Listener, error) {
 	l, err := net.Listen("unix", address)
 	if err != nil {
@@ -230,13 +272,17 @@ func callFlowLite(address string) error {
 }
 
 func newFlowLite(network string, address string, done chan int) (*grpc.Server, *net.Listener, error) {
+	return newFlowLiteWithAPI(network, address, MockFlowAccessAPI{}, done)
+}
+
+func newFlowLiteWithAPI(network string, address string, api access.AccessAPIServer, done chan int) (*grpc.Server, *net.Listener, error) {
 	l, err := net.Listen(network, address)
 	if err != nil {
 		return nil, nil, err
 	}
 	s := grpc.NewServer()
 	go func(done chan int) {
-		access.RegisterAccessAPIServer(s, MockFlowAccessAPI{})
+		access.RegisterAccessAPIServer(s, api)
 		_ = s.Serve(l)
 		done <- 1
 	}(done)
@@ -271,3 +317,14 @@ type MockFlowAccessAPI struct {
 func (p MockFlowAccessAPI) Ping(context.Context, *access.PingRequest) (*access.PingResponse, error) {
 	return &access.PingResponse{}, nil
 }
+
+type SlowMockFlowAccessAPI struct {
+	access.AccessAPIServer
+	delay time.Duration
+}
+
+// Ping responds after a delay, like an overloaded access node.
+func (p SlowMockFlowAccessAPI) Ping(context.Context, *access.PingRequest) (*access.PingResponse, error) {
+	time.Sleep(p.delay)
+	return &access.PingResponse{}, nil
+}
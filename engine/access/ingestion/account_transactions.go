@@ -0,0 +1,206 @@
+package ingestion
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/logging"
+)
+
+// indexAccountTransactionsForCollection indexes the account transactions of the finalized block
+// containing the collection, once all of the block's collections were received. Collections of
+// blocks that are not finalized yet are indexed when the block is finalized.
+// No errors are expected during normal operation.
+func (e *Engine) indexAccountTransactionsForCollection(collectionID flow.Identifier) error {
+	if e.accountTransactions == nil {
+		return nil
+	}
+
+	block, err := e.blocks.ByCollectionID(collectionID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("could not get block for collection: %w", err)
+	}
+
+	return e.indexAccountTransactions(block)
+}
+
+// indexAccountTransactions indexes the payer, proposer and authorizers of all transactions in the
+// finalized block. Transactions are only indexed once all of the block's collections were
+// received, since their index within the block depends on the preceding collections.
+// No errors are expected during normal operation.
+func (e *Engine) indexAccountTransactions(block *flow.Block) error {
+	if e.accountTransactions == nil {
+		return nil
+	}
+
+	collections := make([]*flow.LightCollection, 0, len(block.Payload.Guarantees))
+	for _, guarantee := range block.Payload.Guarantees {
+		collection, err := e.collections.LightByID(guarantee.CollectionID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				// the block is indexed when its last missing collection is received
+				return nil
+			}
+			return fmt.Errorf("could not get collection %v: %w", guarantee.CollectionID, err)
+		}
+		collections = append(collections, collection)
+	}
+
+	var entries []flow.AccountTransaction
+	txIndex := uint32(0)
+	for _, collection := range collections {
+		for _, txID := range collection.Transactions {
+			tx, err := e.transactions.ByID(txID)
+			if err != nil {
+				return fmt.Errorf("could not get transaction %v: %w", txID, err)
+			}
+
+			entries = append(entries, transactionRoles(block.Header.Height, txIndex, tx)...)
+			txIndex++
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	err := e.accountTransactions.Store(entries)
+	if err != nil {
+		return fmt.Errorf("could not store account transactions: %w", err)
+	}
+
+	return nil
+}
+
+// OnExecutionData indexes the accounts whose contracts emitted events in the block. It is called
+// by the execution data requester when execution data sync is enabled, and is a noop if the account
+// transaction index is disabled.
+func (e *Engine) OnExecutionData(executionData *execution_data.BlockExecutionData) {
+	if e.accountTransactions == nil {
+		return
+	}
+
+	lg := e.log.With().Hex("block_id", logging.ID(executionData.BlockID)).Logger()
+
+	err := e.indexAccountInteractions(executionData)
+	if err != nil {
+		lg.Error().Err(err).Msg("could not index account interactions from execution data")
+		return
+	}
+
+	lg.Trace().Msg("indexed account interactions from execution data")
+}
+
+// indexAccountInteractions indexes the accounts whose contracts emitted events in the block.
+// No errors are expected during normal operation.
+func (e *Engine) indexAccountInteractions(executionData *execution_data.BlockExecutionData) error {
+	header, err := e.headers.ByBlockID(executionData.BlockID)
+	if err != nil {
+		return fmt.Errorf("could not get header for block %v: %w", executionData.BlockID, err)
+	}
+
+	type interaction struct {
+		address flow.Address
+		txIndex uint32
+	}
+
+	var entries []flow.AccountTransaction
+	seen := make(map[interaction]struct{})
+	for _, chunk := range executionData.ChunkExecutionDatas {
+		for _, event := range chunk.Events {
+			address, ok := eventContractAddress(event.Type)
+			if !ok {
+				continue
+			}
+
+			key := interaction{address: address, txIndex: event.TransactionIndex}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			entries = append(entries, flow.AccountTransaction{
+				Address:          address,
+				BlockHeight:      header.Height,
+				TransactionID:    event.TransactionID,
+				TransactionIndex: event.TransactionIndex,
+				Roles:            []flow.TransactionRole{flow.TransactionRoleInteraction},
+			})
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	err = e.accountTransactions.Store(entries)
+	if err != nil {
+		return fmt.Errorf("could not store account interactions: %w", err)
+	}
+
+	return nil
+}
+
+// transactionRoles returns an entry for each account that was the payer, proposer or an
+// authorizer of the transaction.
+func transactionRoles(height uint64, txIndex uint32, tx *flow.TransactionBody) []flow.AccountTransaction {
+	roles := make(map[flow.Address][]flow.TransactionRole)
+	addresses := make([]flow.Address, 0, len(tx.Authorizers)+2)
+
+	add := func(address flow.Address, role flow.TransactionRole) {
+		existing, ok := roles[address]
+		if !ok {
+			addresses = append(addresses, address)
+		}
+		for _, r := range existing {
+			if r == role {
+				return
+			}
+		}
+		roles[address] = append(existing, role)
+	}
+
+	add(tx.Payer, flow.TransactionRolePayer)
+	add(tx.ProposalKey.Address, flow.TransactionRoleProposer)
+	for _, authorizer := range tx.Authorizers {
+		add(authorizer, flow.TransactionRoleAuthorizer)
+	}
+
+	txID := tx.ID()
+	entries := make([]flow.AccountTransaction, 0, len(addresses))
+	for _, address := range addresses {
+		entries = append(entries, flow.AccountTransaction{
+			Address:          address,
+			BlockHeight:      height,
+			TransactionID:    txID,
+			TransactionIndex: txIndex,
+			Roles:            roles[address],
+		})
+	}
+
+	return entries
+}
+
+// eventContractAddress returns the address of the contract that emitted an account event with a
+// type in the format `A.<address>.<contract>.<event>`. Core events are not emitted by contracts.
+func eventContractAddress(eventType flow.EventType) (flow.Address, bool) {
+	parts := strings.Split(string(eventType), ".")
+	if len(parts) != 4 || parts[0] != "A" {
+		return flow.EmptyAddress, false
+	}
+
+	address, err := hex.DecodeString(parts[1])
+	if err != nil || len(address) != flow.AddressLength {
+		return flow.EmptyAddress, false
+	}
+
+	return flow.BytesToAddress(address), true
+}
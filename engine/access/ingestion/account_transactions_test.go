@@ -0,0 +1,174 @@
+package ingestion
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/storage"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestIndexAccountTransactions(t *testing.T) {
+	collections := storagemock.NewCollections(t)
+	transactions := storagemock.NewTransactions(t)
+	index := storagemock.NewAccountTransactions(t)
+	blocks := storagemock.NewBlocks(t)
+
+	e := &Engine{
+		log:                 zerolog.Nop(),
+		blocks:              blocks,
+		collections:         collections,
+		transactions:        transactions,
+		accountTransactions: index,
+	}
+
+	payer := unittest.RandomAddressFixture()
+	authorizer := unittest.RandomAddressFixture()
+
+	// the first transaction is proposed and paid by the same account, which also authorizes it
+	tx1 := unittest.TransactionBodyFixture(func(tx *flow.TransactionBody) {
+		tx.Payer = payer
+		tx.ProposalKey.Address = payer
+		tx.Authorizers = []flow.Address{payer}
+	})
+	tx2 := unittest.TransactionBodyFixture(func(tx *flow.TransactionBody) {
+		tx.Payer = payer
+		tx.ProposalKey.Address = payer
+		tx.Authorizers = []flow.Address{authorizer}
+	})
+
+	col1 := &flow.LightCollection{Transactions: []flow.Identifier{tx1.ID()}}
+	col2 := &flow.LightCollection{Transactions: []flow.Identifier{tx2.ID()}}
+	guarantees := []*flow.CollectionGuarantee{
+		{CollectionID: unittest.IdentifierFixture()},
+		{CollectionID: unittest.IdentifierFixture()},
+	}
+	block := &flow.Block{
+		Header:  &flow.Header{Height: 42},
+		Payload: &flow.Payload{Guarantees: guarantees},
+	}
+
+	t.Run("incomplete block is skipped", func(t *testing.T) {
+		collections.On("LightByID", guarantees[0].CollectionID).Return(col1, nil).Once()
+		collections.On("LightByID", guarantees[1].CollectionID).Return(nil, storage.ErrNotFound).Once()
+
+		err := e.indexAccountTransactions(block)
+		require.NoError(t, err)
+		index.AssertNotCalled(t, "Store", mock.Anything)
+	})
+
+	t.Run("unfinalized collection is skipped", func(t *testing.T) {
+		blocks.On("ByCollectionID", guarantees[1].CollectionID).Return(nil, storage.ErrNotFound).Once()
+
+		err := e.indexAccountTransactionsForCollection(guarantees[1].CollectionID)
+		require.NoError(t, err)
+		index.AssertNotCalled(t, "Store", mock.Anything)
+	})
+
+	t.Run("complete block is indexed", func(t *testing.T) {
+		blocks.On("ByCollectionID", guarantees[1].CollectionID).Return(block, nil).Once()
+		collections.On("LightByID", guarantees[0].CollectionID).Return(col1, nil).Once()
+		collections.On("LightByID", guarantees[1].CollectionID).Return(col2, nil).Once()
+		transactions.On("ByID", tx1.ID()).Return(&tx1, nil).Once()
+		transactions.On("ByID", tx2.ID()).Return(&tx2, nil).Once()
+
+		expected := []flow.AccountTransaction{
+			{
+				Address:          payer,
+				BlockHeight:      42,
+				TransactionID:    tx1.ID(),
+				TransactionIndex: 0,
+				Roles: []flow.TransactionRole{
+					flow.TransactionRolePayer,
+					flow.TransactionRoleProposer,
+					flow.TransactionRoleAuthorizer,
+				},
+			},
+			{
+				Address:          payer,
+				BlockHeight:      42,
+				TransactionID:    tx2.ID(),
+				TransactionIndex: 1,
+				Roles:            []flow.TransactionRole{flow.TransactionRolePayer, flow.TransactionRoleProposer},
+			},
+			{
+				Address:          authorizer,
+				BlockHeight:      42,
+				TransactionID:    tx2.ID(),
+				TransactionIndex: 1,
+				Roles:            []flow.TransactionRole{flow.TransactionRoleAuthorizer},
+			},
+		}
+		index.On("Store", expected).Return(nil).Once()
+
+		err := e.indexAccountTransactionsForCollection(guarantees[1].CollectionID)
+		require.NoError(t, err)
+	})
+}
+
+func TestIndexAccountInteractions(t *testing.T) {
+	headers := storagemock.NewHeaders(t)
+	index := storagemock.NewAccountTransactions(t)
+
+	e := &Engine{
+		log:                 zerolog.Nop(),
+		headers:             headers,
+		accountTransactions: index,
+	}
+
+	contract := unittest.RandomAddressFixture()
+	txID := unittest.IdentifierFixture()
+	header := unittest.BlockHeaderFixture()
+	contractEvent := flow.EventType("A." + contract.Hex() + ".Foo.Bar")
+
+	executionData := &execution_data.BlockExecutionData{
+		BlockID: header.ID(),
+		ChunkExecutionDatas: []*execution_data.ChunkExecutionData{
+			{
+				Events: flow.EventsList{
+					// multiple events from the same contract in a transaction are indexed once
+					{Type: contractEvent, TransactionID: txID, TransactionIndex: 3, EventIndex: 0},
+					{Type: contractEvent, TransactionID: txID, TransactionIndex: 3, EventIndex: 1},
+					// core events are not emitted by contracts
+					{Type: flow.EventAccountCreated, TransactionID: txID, TransactionIndex: 3, EventIndex: 2},
+				},
+			},
+		},
+	}
+
+	headers.On("ByBlockID", header.ID()).Return(header, nil).Once()
+	index.On("Store", []flow.AccountTransaction{{
+		Address:          contract,
+		BlockHeight:      header.Height,
+		TransactionID:    txID,
+		TransactionIndex: 3,
+		Roles:            []flow.TransactionRole{flow.TransactionRoleInteraction},
+	}}).Return(nil).Once()
+
+	e.OnExecutionData(executionData)
+}
+
+func TestEventContractAddress(t *testing.T) {
+	address := unittest.RandomAddressFixture()
+
+	actual, ok := eventContractAddress(flow.EventType("A." + address.Hex() + ".Foo.Bar"))
+	assert.True(t, ok)
+	assert.Equal(t, address, actual)
+
+	for _, eventType := range []flow.EventType{
+		flow.EventAccountCreated,
+		"A.invalid.Foo.Bar",
+		"A." + flow.EventType(address.Hex()) + ".Foo",
+		"B." + flow.EventType(address.Hex()) + ".Foo.Bar",
+	} {
+		_, ok := eventContractAddress(eventType)
+		assert.False(t, ok, "event type %s", eventType)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/onflow/flow-go/consensus/hotstuff/model"
 	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/access/rpc"
+	"github.com/onflow/flow-go/engine/access/rpc/backend"
 	"github.com/onflow/flow-go/engine/common/fifoqueue"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
@@ -77,6 +78,12 @@ type Engine struct {
 	executionReceipts storage.ExecutionReceipts
 	executionResults  storage.ExecutionResults
 
+	// optional index of the transactions each account was involved in, nil if disabled
+	accountTransactions storage.AccountTransactions
+
+	// optional tracker of the health of execution nodes, nil if disabled
+	nodeHealth *backend.ExecutionNodeHealth
+
 	// metrics
 	transactionMetrics         module.TransactionMetrics
 	collectionsToMarkFinalized *stdmap.Times
@@ -104,6 +111,8 @@ func New(
 	collectionsToMarkExecuted *stdmap.Times,
 	blocksToMarkExecuted *stdmap.Times,
 	rpcEngine *rpc.Engine,
+	accountTransactions storage.AccountTransactions,
+	nodeHealth *backend.ExecutionNodeHealth,
 ) (*Engine, error) {
 	executionReceiptsRawQueue, err := fifoqueue.NewFifoQueue(defaultQueueCapacity)
 	if err != nil {
@@ -150,6 +159,8 @@ func New(
 		transactions:               transactions,
 		executionResults:           executionResults,
 		executionReceipts:          executionReceipts,
+		accountTransactions:        accountTransactions,
+		nodeHealth:                 nodeHealth,
 		transactionMetrics:         transactionMetrics,
 		collectionsToMarkFinalized: collectionsToMarkFinalized,
 		collectionsToMarkExecuted:  collectionsToMarkExecuted,
@@ -392,6 +403,15 @@ func (e *Engine) processFinalizedBlock(blockID flow.Identifier) error {
 	// Notify rpc handler of new finalized block height
 	e.rpcEngine.SubmitLocal(block)
 
+	// update the sealed height the lag of execution nodes is measured against
+	if e.nodeHealth != nil {
+		sealed, err := e.state.Sealed().Head()
+		if err != nil {
+			return fmt.Errorf("could not get sealed header: %w", err)
+		}
+		e.nodeHealth.ReportSealed(sealed.Height)
+	}
+
 	// FIX: we can't index guarantees here, as we might have more than one block
 	// with the same collection as long as it is not finalized
 
@@ -403,6 +423,12 @@ func (e *Engine) processFinalizedBlock(blockID flow.Identifier) error {
 		return fmt.Errorf("could not index block for collections: %w", err)
 	}
 
+	// index the account transactions of the block, if all of its collections were already received
+	err = e.indexAccountTransactions(block)
+	if err != nil {
+		return fmt.Errorf("could not index account transactions: %w", err)
+	}
+
 	// loop through seals and index ID -> result ID
 	for _, seal := range block.Payload.Seals {
 		err := e.executionResults.Index(seal.BlockID, seal.ResultID)
@@ -475,9 +501,34 @@ func (e *Engine) handleExecutionReceipt(originID flow.Identifier, r *flow.Execut
 	}
 
 	e.trackExecutionReceiptMetrics(r)
+	e.reportExecutedHeight(r)
+
+	// notify rpc handler of the new execution receipt
+	e.rpcEngine.SubmitLocal(r)
+
 	return nil
 }
 
+// reportExecutedHeight records the height of the executed block for the executor of the receipt in
+// the execution node health tracker, so that the lag of execution nodes is known for every receipt.
+func (e *Engine) reportExecutedHeight(r *flow.ExecutionReceipt) {
+	if e.nodeHealth == nil {
+		return
+	}
+
+	header, err := e.headers.ByBlockID(r.ExecutionResult.BlockID)
+	if err != nil {
+		// receipts for blocks that are not known yet don't update the executed height, the
+		// executor's next receipt does
+		e.log.Debug().Err(err).
+			Hex("block_id", logging.ID(r.ExecutionResult.BlockID)).
+			Msg("could not report executed height: executed block not found locally")
+		return
+	}
+
+	e.nodeHealth.ReportExecuted(r.ExecutorID, header.Height)
+}
+
 func (e *Engine) trackExecutionReceiptMetrics(r *flow.ExecutionReceipt) {
 	// TODO add actual execution time to execution receipt?
 	now := time.Now().UTC()
@@ -571,6 +622,12 @@ func (e *Engine) handleCollection(originID flow.Identifier, entity flow.Entity)
 		}
 	}
 
+	// if the collection completes a finalized block, index the block's account transactions
+	err = e.indexAccountTransactionsForCollection(light.ID())
+	if err != nil {
+		return fmt.Errorf("could not index account transactions for collection (%x): %w", light.ID(), err)
+	}
+
 	return nil
 }
 
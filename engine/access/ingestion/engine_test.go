@@ -16,6 +16,7 @@ import (
 
 	hotmodel "github.com/onflow/flow-go/consensus/hotstuff/model"
 	"github.com/onflow/flow-go/engine/access/rpc"
+	"github.com/onflow/flow-go/engine/access/rpc/backend"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/module/component"
@@ -113,7 +114,7 @@ func (suite *Suite) SetupTest() {
 
 	eng, err := New(log, net, suite.proto.state, suite.me, suite.request, suite.blocks, suite.headers, suite.collections,
 		suite.transactions, suite.results, suite.receipts, metrics.NewNoopCollector(), collectionsToMarkFinalized, collectionsToMarkExecuted,
-		blocksToMarkExecuted, rpcEng)
+		blocksToMarkExecuted, rpcEng, nil, nil)
 	require.NoError(suite.T(), err)
 
 	suite.blocks.On("GetLastFullBlockHeight").Once().Return(uint64(0), errors.New("do nothing"))
@@ -284,6 +285,40 @@ func (suite *Suite) TestExecutionReceiptsAreIndexed() {
 	suite.receipts.AssertExpectations(suite.T())
 }
 
+// TestExecutionReceiptsUpdateNodeHealth checks that every execution receipt updates the executed
+// height of its executor in the execution node health tracker
+func (suite *Suite) TestExecutionReceiptsUpdateNodeHealth() {
+	nodeHealth := backend.NewExecutionNodeHealth(backend.DefaultExecutionNodeHealthConfig(), metrics.NewNoopCollector(), zerolog.Nop())
+	suite.eng.nodeHealth = nodeHealth
+
+	receipt := unittest.ExecutionReceiptFixture()
+	header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(100))
+
+	suite.receipts.On("Store", receipt).Return(nil).Once()
+	suite.blocks.On("ByID", receipt.ExecutionResult.BlockID).Return(nil, storerr.ErrNotFound)
+	suite.headers.On("ByBlockID", receipt.ExecutionResult.BlockID).Return(header, nil).Once()
+
+	err := suite.eng.handleExecutionReceipt(unittest.IdentifierFixture(), receipt)
+	require.NoError(suite.T(), err)
+
+	statuses := nodeHealth.Status()
+	require.Len(suite.T(), statuses, 1)
+	suite.Assert().Equal(receipt.ExecutorID, statuses[0].NodeID)
+	suite.Assert().Equal(header.Height, statuses[0].ExecutedHeight)
+
+	// receipts for unknown blocks don't update the executed height
+	unknown := unittest.ExecutionReceiptFixture(unittest.WithExecutorID(receipt.ExecutorID))
+	suite.receipts.On("Store", unknown).Return(nil).Once()
+	suite.blocks.On("ByID", unknown.ExecutionResult.BlockID).Return(nil, storerr.ErrNotFound)
+	suite.headers.On("ByBlockID", unknown.ExecutionResult.BlockID).Return(nil, storerr.ErrNotFound).Once()
+
+	err = suite.eng.handleExecutionReceipt(unittest.IdentifierFixture(), unknown)
+	require.NoError(suite.T(), err)
+	suite.Assert().Equal(header.Height, nodeHealth.Status()[0].ExecutedHeight)
+
+	suite.headers.AssertExpectations(suite.T())
+}
+
 // TestOnCollection checks that when a duplicate collection is received, the node doesn't
 // crash but just ignores its transactions.
 func (suite *Suite) TestOnCollectionDuplicate() {
@@ -31,3 +31,25 @@ func GetAccount(r *request.Request, backend access.API, link models.LinkGenerato
 	err = response.Build(account, link, r.ExpandFields)
 	return response, err
 }
+
+// GetAccountTransactions handler retrieves a page of the transactions the account was involved in
+func GetAccountTransactions(r *request.Request, backend access.API, link models.LinkGenerator) (interface{}, error) {
+	req, err := r.GetAccountTransactionsRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	page, err := backend.GetAccountTransactions(r.Context(), req.Address, req.Limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	if page.NextCursor != nil {
+		nextCursor = request.EncodeAccountTransactionCursor(*page.NextCursor)
+	}
+
+	var response models.AccountTransactions
+	err = response.Build(page.Transactions, nextCursor, link)
+	return response, err
+}
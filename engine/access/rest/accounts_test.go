@@ -10,9 +10,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	mocktestify "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/access/mock"
 	"github.com/onflow/flow-go/engine/access/rest/middleware"
+	"github.com/onflow/flow-go/engine/access/rest/request"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/utils/unittest"
 )
@@ -127,6 +131,123 @@ func TestGetAccount(t *testing.T) {
 	})
 }
 
+func accountTransactionsURL(t *testing.T, address string, limit string, cursor string) string {
+	u, err := url.ParseRequestURI(fmt.Sprintf("/v1/accounts/%s/transactions", address))
+	require.NoError(t, err)
+	q := u.Query()
+
+	if limit != "" {
+		q.Add("limit", limit)
+	}
+	if cursor != "" {
+		q.Add("cursor", cursor)
+	}
+
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func TestGetAccountTransactions(t *testing.T) {
+	backend := &mock.API{}
+	address := unittest.AddressFixture()
+
+	entry := flow.AccountTransaction{
+		Address:          address,
+		BlockHeight:      100,
+		TransactionID:    unittest.IdentifierFixture(),
+		TransactionIndex: 2,
+		Roles:            []flow.TransactionRole{flow.TransactionRolePayer, flow.TransactionRoleAuthorizer},
+	}
+
+	t.Run("get first page", func(t *testing.T) {
+		next := entry.Cursor()
+		backend.Mock.
+			On("GetAccountTransactions", mocktestify.Anything, address, uint32(1), (*flow.AccountTransactionCursor)(nil)).
+			Return(&access.AccountTransactionsPage{
+				Transactions: []flow.AccountTransaction{entry},
+				NextCursor:   &next,
+			}, nil).
+			Once()
+
+		req, err := http.NewRequest("GET", accountTransactionsURL(t, address.String(), "1", ""), nil)
+		require.NoError(t, err)
+
+		expected := fmt.Sprintf(`{
+			"transactions": [{
+				"transaction_id": "%s",
+				"block_height": "100",
+				"transaction_index": "2",
+				"roles": ["payer", "authorizer"],
+				"_links": {"_self": "/v1/transactions/%s"}
+			}],
+			"next_cursor": "%s"
+		}`, entry.TransactionID, entry.TransactionID, request.EncodeAccountTransactionCursor(next))
+
+		assertOKResponse(t, req, expected, backend)
+		mocktestify.AssertExpectationsForObjects(t, backend)
+	})
+
+	t.Run("get last page", func(t *testing.T) {
+		cursor := flow.AccountTransactionCursor{BlockHeight: 101, TransactionIndex: 0}
+		backend.Mock.
+			On("GetAccountTransactions", mocktestify.Anything, address, uint32(0), &cursor).
+			Return(&access.AccountTransactionsPage{
+				Transactions: []flow.AccountTransaction{entry},
+			}, nil).
+			Once()
+
+		req, err := http.NewRequest("GET", accountTransactionsURL(t, address.String(), "", request.EncodeAccountTransactionCursor(cursor)), nil)
+		require.NoError(t, err)
+
+		expected := fmt.Sprintf(`{
+			"transactions": [{
+				"transaction_id": "%s",
+				"block_height": "100",
+				"transaction_index": "2",
+				"roles": ["payer", "authorizer"],
+				"_links": {"_self": "/v1/transactions/%s"}
+			}]
+		}`, entry.TransactionID, entry.TransactionID)
+
+		assertOKResponse(t, req, expected, backend)
+		mocktestify.AssertExpectationsForObjects(t, backend)
+	})
+
+	t.Run("index not enabled", func(t *testing.T) {
+		other := unittest.RandomAddressFixture()
+		backend.Mock.
+			On("GetAccountTransactions", mocktestify.Anything, other, uint32(0), (*flow.AccountTransactionCursor)(nil)).
+			Return(nil, status.Error(codes.Unimplemented, "account transaction index is not enabled")).
+			Once()
+
+		req, err := http.NewRequest("GET", accountTransactionsURL(t, other.String(), "", ""), nil)
+		require.NoError(t, err)
+
+		expected := `{"code":501, "message":"Not supported by this node: account transaction index is not enabled"}`
+		assertResponse(t, req, http.StatusNotImplemented, expected, backend)
+	})
+
+	t.Run("get invalid", func(t *testing.T) {
+		tests := []struct {
+			url string
+			out string
+		}{
+			{accountTransactionsURL(t, "123", "", ""), `{"code":400, "message":"invalid address"}`},
+			{accountTransactionsURL(t, address.String(), "-1", ""), `{"code":400, "message":"invalid limit: value must be an unsigned 32 bit integer"}`},
+			{accountTransactionsURL(t, address.String(), "", "foo"), `{"code":400, "message":"invalid cursor"}`},
+		}
+
+		for i, test := range tests {
+			req, _ := http.NewRequest("GET", test.url, nil)
+			rr, err := executeRequest(req, backend)
+			assert.NoError(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.JSONEq(t, test.out, rr.Body.String(), fmt.Sprintf("test #%d failed: %v", i, test))
+		}
+	})
+}
+
 func expectedExpandedResponse(account *flow.Account) string {
 	return fmt.Sprintf(`{
 			  "address":"%s",
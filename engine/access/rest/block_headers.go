@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/engine/access/rest/request"
+)
+
+// GetBlockHeadersByIDs gets block headers by provided ID or list of IDs.
+func GetBlockHeadersByIDs(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
+	req, err := r.GetBlockByIDsRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	headers := make([]models.BlockHeader, len(req.IDs))
+	for i, id := range req.IDs {
+		header, _, err := backend.GetBlockHeaderByID(r.Context(), id)
+		if err != nil {
+			return nil, err
+		}
+		headers[i].Build(header)
+	}
+
+	return headers, nil
+}
+
+// GetBlockHeadersByHeight gets block headers by provided heights, height range or the special
+// "final" and "sealed" heights.
+func GetBlockHeadersByHeight(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
+	req, err := r.GetBlockRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	if req.FinalHeight || req.SealedHeight {
+		header, _, err := backend.GetLatestBlockHeader(r.Context(), req.SealedHeight)
+		if err != nil {
+			return nil, err
+		}
+
+		var response models.BlockHeader
+		response.Build(header)
+		return []models.BlockHeader{response}, nil
+	}
+
+	heights := req.Heights
+	if !req.HasHeights() {
+		// support providing end height as "sealed" or "final"
+		if req.EndHeight == request.FinalHeight || req.EndHeight == request.SealedHeight {
+			latest, _, err := backend.GetLatestBlockHeader(r.Context(), req.EndHeight == request.SealedHeight)
+			if err != nil {
+				return nil, err
+			}
+
+			req.EndHeight = latest.Height // overwrite special value height with fetched
+
+			if req.StartHeight > req.EndHeight {
+				return nil, NewBadRequestError(fmt.Errorf("start height must be less than or equal to end height"))
+			}
+		}
+
+		// start and end height inclusive
+		for h := req.StartHeight; h <= req.EndHeight; h++ {
+			heights = append(heights, h)
+		}
+	}
+
+	headers := make([]models.BlockHeader, len(heights))
+	for i, h := range heights {
+		header, _, err := backend.GetBlockHeaderByHeight(r.Context(), h)
+		if err != nil {
+			return nil, err
+		}
+		headers[i].Build(header)
+	}
+
+	return headers, nil
+}
@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	mocks "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access/mock"
+	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func getBlockHeadersReq(t *testing.T, path string) *http.Request {
+	req, err := http.NewRequest("GET", "/v1/block_headers"+path, nil)
+	require.NoError(t, err)
+	return req
+}
+
+func expectedBlockHeaders(headers ...*flow.Header) string {
+	responses := make([]string, len(headers))
+	for i, h := range headers {
+		responses[i] = fmt.Sprintf(`{
+			"id": "%s",
+			"parent_id": "%s",
+			"height": "%d",
+			"timestamp": "%s",
+			"parent_voter_signature": "%s"
+		}`, h.ID(), h.ParentID, h.Height, h.Timestamp.Format(time.RFC3339Nano), util.ToBase64(h.ParentVoterSigData))
+	}
+	return fmt.Sprintf("[%s]", strings.Join(responses, ","))
+}
+
+func TestGetBlockHeaders(t *testing.T) {
+	backend := &mock.API{}
+
+	headers := make([]*flow.Header, 3)
+	ids := make([]string, len(headers))
+	for i := range headers {
+		header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(uint64(100 + i)))
+		headers[i] = header
+		ids[i] = header.ID().String()
+
+		backend.Mock.
+			On("GetBlockHeaderByID", mocks.Anything, header.ID()).
+			Return(header, flow.BlockStatusSealed, nil)
+		backend.Mock.
+			On("GetBlockHeaderByHeight", mocks.Anything, header.Height).
+			Return(header, flow.BlockStatusSealed, nil)
+	}
+
+	t.Run("get by IDs", func(t *testing.T) {
+		req := getBlockHeadersReq(t, "/"+strings.Join(ids, ","))
+		assertOKResponse(t, req, expectedBlockHeaders(headers...), backend)
+	})
+
+	t.Run("get by heights", func(t *testing.T) {
+		req := getBlockHeadersReq(t, "?height=100,102")
+		assertOKResponse(t, req, expectedBlockHeaders(headers[0], headers[2]), backend)
+	})
+
+	t.Run("get by start and end height", func(t *testing.T) {
+		req := getBlockHeadersReq(t, "?start_height=100&end_height=102")
+		assertOKResponse(t, req, expectedBlockHeaders(headers...), backend)
+	})
+
+	t.Run("get sealed", func(t *testing.T) {
+		backend.Mock.
+			On("GetLatestBlockHeader", mocks.Anything, true).
+			Return(headers[2], flow.BlockStatusSealed, nil).
+			Once()
+
+		req := getBlockHeadersReq(t, "?height=sealed")
+		assertOKResponse(t, req, expectedBlockHeaders(headers[2]), backend)
+	})
+
+	t.Run("get by start and final end height", func(t *testing.T) {
+		backend.Mock.
+			On("GetLatestBlockHeader", mocks.Anything, false).
+			Return(headers[2], flow.BlockStatusFinalized, nil).
+			Once()
+
+		req := getBlockHeadersReq(t, "?start_height=101&end_height=final")
+		assertOKResponse(t, req, expectedBlockHeaders(headers[1], headers[2]), backend)
+	})
+
+	t.Run("get by ID not found", func(t *testing.T) {
+		id := unittest.IdentifierFixture()
+		backend.Mock.
+			On("GetBlockHeaderByID", mocks.Anything, id).
+			Return(nil, flow.BlockStatusUnknown, status.Error(codes.NotFound, "not found"))
+
+		req := getBlockHeadersReq(t, "/"+id.String())
+		assertResponse(t, req, http.StatusNotFound, `{"code":404, "message":"Flow resource not found: not found"}`, backend)
+	})
+
+	t.Run("get without heights", func(t *testing.T) {
+		req := getBlockHeadersReq(t, "")
+		assertResponse(t, req, http.StatusBadRequest, `{"code":400, "message":"must provide either heights or start and end height range"}`, backend)
+	})
+}
@@ -45,6 +45,23 @@ func GetEvents(r *request.Request, backend access.API, _ models.LinkGenerator) (
 		}
 	}
 
+	// if the request provided a limit or cursor then return a page of events for the height range
+	if req.Paged {
+		page, err := backend.GetEventsForHeightRangePage(r.Context(), req.Type, req.StartHeight, req.EndHeight, req.Limit, req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		var nextCursor string
+		if page.NextCursor != nil {
+			nextCursor = request.EncodeEventsCursor(*page.NextCursor)
+		}
+
+		var response models.BlockEventsPage
+		response.Build(page.BlockEvents, nextCursor)
+		return response, nil
+	}
+
 	// if request provided block height range then return events for that range
 	events, err := backend.GetEventsForHeightRange(r.Context(), req.Type, req.StartHeight, req.EndHeight)
 	if err != nil {
@@ -8,8 +8,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/onflow/flow-go/engine/access/rest/request"
 	"github.com/onflow/flow-go/engine/access/rest/util"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/access/mock"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/utils/unittest"
@@ -116,6 +118,62 @@ func TestGetEvents(t *testing.T) {
 
 }
 
+func TestGetEventsPage(t *testing.T) {
+	backend := &mock.API{}
+	header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(10))
+	events := []flow.BlockEvents{unittest.BlockEventsFixture(header, 2)}
+	eventType := "A.179b6b1cb6755e31.Foo.Bar"
+
+	t.Run("get first page", func(t *testing.T) {
+		next := access.EventsCursor{BlockHeight: 11, TransactionIndex: 1, EventIndex: 0}
+		backend.Mock.
+			On("GetEventsForHeightRangePage", mocks.Anything, eventType, uint64(0), uint64(5000), uint32(2), (*access.EventsCursor)(nil)).
+			Return(&access.EventsPage{BlockEvents: events, NextCursor: &next}, nil).
+			Once()
+
+		req := getEventPageReq(t, eventType, "0", "5000", "2", "")
+		expected := fmt.Sprintf(`{"results": %s, "next_cursor": "%s"}`,
+			testBlockEventResponse(events), request.EncodeEventsCursor(next))
+
+		assertOKResponse(t, req, expected, backend)
+		mocks.AssertExpectationsForObjects(t, backend)
+	})
+
+	t.Run("get last page", func(t *testing.T) {
+		cursor := access.EventsCursor{BlockHeight: 10, TransactionIndex: 1, EventIndex: 0}
+		backend.Mock.
+			On("GetEventsForHeightRangePage", mocks.Anything, eventType, uint64(0), uint64(5000), uint32(0), &cursor).
+			Return(&access.EventsPage{BlockEvents: events}, nil).
+			Once()
+
+		req := getEventPageReq(t, eventType, "0", "5000", "", request.EncodeEventsCursor(cursor))
+		expected := fmt.Sprintf(`{"results": %s}`, testBlockEventResponse(events))
+
+		assertOKResponse(t, req, expected, backend)
+		mocks.AssertExpectationsForObjects(t, backend)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		req := getEventPageReq(t, eventType, "0", "5000", "", "foo")
+		assertResponse(t, req, http.StatusBadRequest, `{"code":400,"message":"invalid cursor"}`, backend)
+	})
+}
+
+func getEventPageReq(t *testing.T, eventType string, start string, end string, limit string, cursor string) *http.Request {
+	req := getEventReq(t, eventType, start, end, nil)
+
+	q := req.URL.Query()
+	if limit != "" {
+		q.Add("limit", limit)
+	}
+	if cursor != "" {
+		q.Add("cursor", cursor)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	return req
+}
+
 func getEventReq(t *testing.T, eventType string, start string, end string, blockIDs []string) *http.Request {
 	u, _ := url.Parse("/v1/events")
 	q := u.Query()
@@ -92,44 +92,50 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) errorHandler(w http.ResponseWriter, err error, errorLogger zerolog.Logger) {
+	modelError := toModelError(err, errorLogger)
+	h.jsonResponse(w, int(modelError.Code), modelError, errorLogger)
+}
+
+// toModelError converts the error returned by a handler into the error model returned to the
+// client, using the HTTP status code matching the error
+func toModelError(err error, errorLogger zerolog.Logger) models.ModelError {
 	// rest status type error should be returned with status and user message provided
 	var statusErr StatusError
 	if errors.As(err, &statusErr) {
-		h.errorResponse(w, statusErr.Status(), statusErr.UserMessage(), errorLogger)
-		return
+		return newModelError(statusErr.Status(), statusErr.UserMessage())
 	}
 
 	// handle cadence errors
 	cadenceError := fvmErrors.Find(err, fvmErrors.ErrCodeCadenceRunTimeError)
 	if cadenceError != nil {
 		msg := fmt.Sprintf("Cadence error: %s", cadenceError.Error())
-		h.errorResponse(w, http.StatusBadRequest, msg, errorLogger)
-		return
+		return newModelError(http.StatusBadRequest, msg)
 	}
 
 	// handle grpc status error returned from the backend calls, we are forwarding the message to the client
 	if se, ok := status.FromError(err); ok {
 		if se.Code() == codes.NotFound {
 			msg := fmt.Sprintf("Flow resource not found: %s", se.Message())
-			h.errorResponse(w, http.StatusNotFound, msg, errorLogger)
-			return
+			return newModelError(http.StatusNotFound, msg)
 		}
 		if se.Code() == codes.InvalidArgument {
 			msg := fmt.Sprintf("Invalid Flow argument: %s", se.Message())
-			h.errorResponse(w, http.StatusBadRequest, msg, errorLogger)
-			return
+			return newModelError(http.StatusBadRequest, msg)
 		}
 		if se.Code() == codes.Internal {
 			msg := fmt.Sprintf("Invalid Flow request: %s", se.Message())
-			h.errorResponse(w, http.StatusBadRequest, msg, errorLogger)
-			return
+			return newModelError(http.StatusBadRequest, msg)
+		}
+		if se.Code() == codes.Unimplemented {
+			msg := fmt.Sprintf("Not supported by this node: %s", se.Message())
+			return newModelError(http.StatusNotImplemented, msg)
 		}
 	}
 
 	// stop going further - catch all error
 	msg := "internal server error"
 	errorLogger.Error().Err(err).Msg(msg)
-	h.errorResponse(w, http.StatusInternalServerError, msg, errorLogger)
+	return newModelError(http.StatusInternalServerError, msg)
 }
 
 // jsonResponse builds a JSON response and send it to the client
@@ -152,18 +158,10 @@ func (h *Handler) jsonResponse(w http.ResponseWriter, code int, response interfa
 	}
 }
 
-// errorResponse sends an HTTP error response to the client with the given return code
-// and a model error with the given response message in the response body
-func (h *Handler) errorResponse(
-	w http.ResponseWriter,
-	returnCode int,
-	responseMessage string,
-	logger zerolog.Logger,
-) {
-	// create error response model
-	modelError := models.ModelError{
+// newModelError creates an error model with the given return code and response message
+func newModelError(returnCode int, responseMessage string) models.ModelError {
+	return models.ModelError{
 		Code:    int32(returnCode),
 		Message: responseMessage,
 	}
-	h.jsonResponse(w, returnCode, modelError, logger)
 }
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func (a *AccountTransaction) Build(entry flow.AccountTransaction, link LinkGenerator) error {
+	a.TransactionId = entry.TransactionID.String()
+	a.BlockHeight = util.FromUint64(entry.BlockHeight)
+	a.TransactionIndex = util.FromUint64(uint64(entry.TransactionIndex))
+
+	roles := make([]string, len(entry.Roles))
+	for i, role := range entry.Roles {
+		roles[i] = role.String()
+	}
+	a.Roles = roles
+
+	self, err := SelfLink(entry.TransactionID, link.TransactionLink)
+	if err != nil {
+		return err
+	}
+	a.Links = self
+
+	return nil
+}
+
+// Build builds the page of account transactions. The cursor of the next page is encoded by the
+// caller, and is empty if there are no older transactions.
+func (a *AccountTransactions) Build(entries []flow.AccountTransaction, nextCursor string, link LinkGenerator) error {
+	transactions := make([]AccountTransaction, len(entries))
+	for i, entry := range entries {
+		err := transactions[i].Build(entry, link)
+		if err != nil {
+			return err
+		}
+	}
+
+	a.Transactions = transactions
+	a.NextCursor = nextCursor
+
+	return nil
+}
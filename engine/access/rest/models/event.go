@@ -53,3 +53,13 @@ func (b *BlocksEvents) Build(blocksEvents []flow.BlockEvents) {
 
 	*b = evs
 }
+
+// Build builds the page of block events. The cursor of the next page is encoded by the caller, and
+// is empty if there are no more events in the range.
+func (b *BlockEventsPage) Build(blocksEvents []flow.BlockEvents, nextCursor string) {
+	var results BlocksEvents
+	results.Build(blocksEvents)
+
+	b.Results = results
+	b.NextCursor = nextCursor
+}
@@ -0,0 +1,18 @@
+/*
+ * Access API
+ *
+ * No description provided (generated by Swagger Codegen https://github.com/swagger-api/swagger-codegen)
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package models
+
+type RegisterEntry struct {
+	// Base64 encoded register owner, empty for global registers.
+	Owner string `json:"owner"`
+	// Base64 encoded register key.
+	Key string `json:"key"`
+	// Base64 encoded register value.
+	Value string `json:"value"`
+}
@@ -0,0 +1,27 @@
+/*
+ * Access API
+ *
+ * No description provided (generated by Swagger Codegen https://github.com/swagger-api/swagger-codegen)
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package models
+
+type TransactionFeesEstimate struct {
+	TransactionId   string `json:"transaction_id"`
+	BlockId         string `json:"block_id"`
+	ComputationUsed string `json:"computation_used"`
+	// Computation intensities keyed by computation kind.
+	ComputationIntensities map[string]string `json:"computation_intensities"`
+	MemoryEstimate         string            `json:"memory_estimate"`
+	StorageBytesRead       string            `json:"storage_bytes_read"`
+	StorageBytesWritten    string            `json:"storage_bytes_written"`
+	// Fees in FLOW, as decimal strings.
+	InclusionFee string `json:"inclusion_fee"`
+	ExecutionFee string `json:"execution_fee"`
+	TotalFee     string `json:"total_fee"`
+	ErrorCode    int32  `json:"error_code"`
+	// Provided transaction error in case the estimated transaction wasn't successful.
+	ErrorMessage string `json:"error_message"`
+}
@@ -0,0 +1,21 @@
+/*
+ * Access API
+ *
+ * No description provided (generated by Swagger Codegen https://github.com/swagger-api/swagger-codegen)
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package models
+
+type TransactionSimulation struct {
+	TransactionId string `json:"transaction_id"`
+	BlockId       string `json:"block_id"`
+	ErrorCode     int32  `json:"error_code"`
+	// Provided transaction error in case the simulated transaction wasn't successful.
+	ErrorMessage    string          `json:"error_message"`
+	ComputationUsed string          `json:"computation_used"`
+	MemoryEstimate  string          `json:"memory_estimate"`
+	Events          []Event         `json:"events"`
+	WriteSet        []RegisterEntry `json:"write_set"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+
+	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func (t *TransactionFeesEstimate) Build(estimate *flow.TransactionFeesEstimate) {
+	intensities := make(map[string]string, len(estimate.ComputationIntensities))
+	for kind, intensity := range estimate.ComputationIntensities {
+		intensities[common.ComputationKind(kind).String()] = util.FromUint64(uint64(intensity))
+	}
+
+	t.TransactionId = estimate.TransactionID.String()
+	t.BlockId = estimate.BlockID.String()
+	t.ComputationUsed = util.FromUint64(estimate.ComputationUsed)
+	t.ComputationIntensities = intensities
+	t.MemoryEstimate = util.FromUint64(estimate.MemoryEstimate)
+	t.StorageBytesRead = util.FromUint64(estimate.StorageBytesRead)
+	t.StorageBytesWritten = util.FromUint64(estimate.StorageBytesWritten)
+	t.InclusionFee = cadence.UFix64(estimate.InclusionFee).String()
+	t.ExecutionFee = cadence.UFix64(estimate.ExecutionFee).String()
+	t.TotalFee = cadence.UFix64(estimate.TotalFee()).String()
+	t.ErrorCode = int32(estimate.ErrorCode)
+	t.ErrorMessage = estimate.ErrorMessage
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func (t *TransactionSimulation) Build(result *flow.TransactionSimulationResult) {
+	var events Events
+	events.Build(result.Events)
+
+	writeSet := make([]RegisterEntry, len(result.WriteSet))
+	for i, entry := range result.WriteSet {
+		writeSet[i] = RegisterEntry{
+			Owner: util.ToBase64([]byte(entry.Key.Owner)),
+			Key:   util.ToBase64([]byte(entry.Key.Key)),
+			Value: util.ToBase64(entry.Value),
+		}
+	}
+
+	t.TransactionId = result.TransactionID.String()
+	t.BlockId = result.BlockID.String()
+	t.ErrorCode = int32(result.ErrorCode)
+	t.ErrorMessage = result.ErrorMessage
+	t.ComputationUsed = util.FromUint64(result.ComputationUsed)
+	t.MemoryEstimate = util.FromUint64(result.MemoryEstimate)
+	t.Events = events
+	t.WriteSet = writeSet
+}
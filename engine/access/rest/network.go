@@ -1,6 +1,8 @@
 package rest
 
 import (
+	"encoding/json"
+
 	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/engine/access/rest/models"
 	"github.com/onflow/flow-go/engine/access/rest/request"
@@ -14,3 +16,14 @@ func GetNetworkParameters(r *request.Request, backend access.API, link models.Li
 	response.Build(&params)
 	return response, nil
 }
+
+// GetLatestProtocolStateSnapshot returns the latest sealed protocol state snapshot, which can be
+// used to bootstrap a node. The snapshot is returned in its JSON encoding.
+func GetLatestProtocolStateSnapshot(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
+	snapshot, err := backend.GetLatestProtocolStateSnapshot(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(snapshot), nil
+}
@@ -54,3 +54,18 @@ func getNetworkParametersRequest(t *testing.T) *http.Request {
 	require.NoError(t, err)
 	return req
 }
+
+func TestGetLatestProtocolStateSnapshot(t *testing.T) {
+	backend := &mock.API{}
+
+	snapshot := []byte(`{"Head":{"Height":10},"SealingSegment":{}}`)
+	backend.Mock.
+		On("GetLatestProtocolStateSnapshot", mocktestify.Anything).
+		Return(snapshot, nil)
+
+	req, err := http.NewRequest("GET", "/v1/protocol_state_snapshot", nil)
+	require.NoError(t, err)
+
+	// the snapshot is returned as is
+	assertOKResponse(t, req, string(snapshot), backend)
+}
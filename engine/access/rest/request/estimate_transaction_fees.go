@@ -0,0 +1,28 @@
+package request
+
+import (
+	"io"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+type EstimateTransactionFees struct {
+	Transaction flow.TransactionBody
+}
+
+func (e *EstimateTransactionFees) Build(r *Request) error {
+	return e.Parse(r.Body, r.Chain)
+}
+
+// Parse parses the transaction to estimate. The transaction does not need to be signed, since the
+// signature checks are skipped when estimating fees.
+func (e *EstimateTransactionFees) Parse(rawTransaction io.Reader, chain flow.Chain) error {
+	var tx Transaction
+	err := tx.ParseUnsigned(rawTransaction, chain)
+	if err != nil {
+		return err
+	}
+
+	e.Transaction = tx.Flow()
+	return nil
+}
@@ -0,0 +1,93 @@
+package request
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const limitQuery = "limit"
+const cursorQuery = "cursor"
+
+// accountTransactionCursorLength is the length of an encoded cursor, which contains the block
+// height and transaction index.
+const accountTransactionCursorLength = 8 + 4
+
+type GetAccountTransactions struct {
+	Address flow.Address
+	Limit   uint32
+	Cursor  *flow.AccountTransactionCursor
+}
+
+func (g *GetAccountTransactions) Build(r *Request) error {
+	return g.Parse(
+		r.GetVar(addressVar),
+		r.GetQueryParam(limitQuery),
+		r.GetQueryParam(cursorQuery),
+	)
+}
+
+func (g *GetAccountTransactions) Parse(rawAddress string, rawLimit string, rawCursor string) error {
+	var address Address
+	err := address.Parse(rawAddress)
+	if err != nil {
+		return err
+	}
+	g.Address = address.Flow()
+
+	g.Limit, err = parseLimit(rawLimit)
+	if err != nil {
+		return err
+	}
+
+	g.Cursor = nil
+	if rawCursor != "" {
+		cursor, err := DecodeAccountTransactionCursor(rawCursor)
+		if err != nil {
+			return err
+		}
+		g.Cursor = &cursor
+	}
+
+	return nil
+}
+
+// parseLimit parses the page size of a paged request. A missing limit is returned as 0, which
+// uses the default page size.
+func parseLimit(raw string) (uint32, error) {
+	if raw == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid limit: value must be an unsigned 32 bit integer")
+	}
+
+	return uint32(limit), nil
+}
+
+// EncodeAccountTransactionCursor encodes the cursor as an opaque string that clients pass back
+// to request the next page.
+func EncodeAccountTransactionCursor(cursor flow.AccountTransactionCursor) string {
+	b := make([]byte, accountTransactionCursorLength)
+	binary.BigEndian.PutUint64(b[:8], cursor.BlockHeight)
+	binary.BigEndian.PutUint32(b[8:], cursor.TransactionIndex)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeAccountTransactionCursor decodes a cursor encoded with EncodeAccountTransactionCursor.
+func DecodeAccountTransactionCursor(raw string) (flow.AccountTransactionCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil || len(b) != accountTransactionCursorLength {
+		return flow.AccountTransactionCursor{}, fmt.Errorf("invalid cursor")
+	}
+
+	return flow.AccountTransactionCursor{
+		BlockHeight:      binary.BigEndian.Uint64(b[:8]),
+		TransactionIndex: binary.BigEndian.Uint32(b[8:]),
+	}, nil
+}
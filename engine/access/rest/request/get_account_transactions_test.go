@@ -0,0 +1,51 @@
+package request
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func Test_GetAccountTransactions_InvalidParse(t *testing.T) {
+	var getAccountTransactions GetAccountTransactions
+
+	tests := []struct {
+		address string
+		limit   string
+		cursor  string
+		err     string
+	}{
+		{"", "", "", "invalid address"},
+		{"f8d6e0586b0a20c7", "-1", "", "invalid limit: value must be an unsigned 32 bit integer"},
+		{"f8d6e0586b0a20c7", "4294967296", "", "invalid limit: value must be an unsigned 32 bit integer"},
+		{"f8d6e0586b0a20c7", "", "foo", "invalid cursor"},
+		{"f8d6e0586b0a20c7", "", "!!!!", "invalid cursor"},
+	}
+
+	for i, test := range tests {
+		err := getAccountTransactions.Parse(test.address, test.limit, test.cursor)
+		assert.EqualError(t, err, test.err, fmt.Sprintf("test #%d failed", i))
+	}
+}
+
+func Test_GetAccountTransactions_ValidParse(t *testing.T) {
+	var getAccountTransactions GetAccountTransactions
+
+	addr := "f8d6e0586b0a20c7"
+	err := getAccountTransactions.Parse(addr, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, getAccountTransactions.Address.String(), addr)
+	assert.Equal(t, getAccountTransactions.Limit, uint32(0))
+	assert.Nil(t, getAccountTransactions.Cursor)
+
+	cursor := flow.AccountTransactionCursor{BlockHeight: 1337, TransactionIndex: 7}
+	err = getAccountTransactions.Parse(addr, "25", EncodeAccountTransactionCursor(cursor))
+	assert.NoError(t, err)
+	assert.Equal(t, getAccountTransactions.Limit, uint32(25))
+	require.NotNil(t, getAccountTransactions.Cursor)
+	assert.Equal(t, cursor, *getAccountTransactions.Cursor)
+}
@@ -1,9 +1,12 @@
 package request
 
 import (
+	"encoding/base64"
+	"encoding/binary"
 	"fmt"
 	"regexp"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/model/flow"
 )
 
@@ -11,11 +14,21 @@ const eventTypeQuery = "type"
 const blockQuery = "block_ids"
 const MaxEventRequestHeightRange = 250
 
+// eventsCursorLength is the length of an encoded events cursor, which contains the block height,
+// transaction index and event index.
+const eventsCursorLength = 8 + 4 + 4
+
 type GetEvents struct {
 	StartHeight uint64
 	EndHeight   uint64
 	Type        string
 	BlockIDs    []flow.Identifier
+
+	// Paged is set if the request provided a limit or a cursor, in which case the events in the
+	// height range are returned in pages.
+	Paged  bool
+	Limit  uint32
+	Cursor *access.EventsCursor
 }
 
 func (g *GetEvents) Build(r *Request) error {
@@ -24,10 +37,19 @@ func (g *GetEvents) Build(r *Request) error {
 		r.GetQueryParam(startHeightQuery),
 		r.GetQueryParam(endHeightQuery),
 		r.GetQueryParams(blockQuery),
+		r.GetQueryParam(limitQuery),
+		r.GetQueryParam(cursorQuery),
 	)
 }
 
-func (g *GetEvents) Parse(rawType string, rawStart string, rawEnd string, rawBlockIDs []string) error {
+func (g *GetEvents) Parse(
+	rawType string,
+	rawStart string,
+	rawEnd string,
+	rawBlockIDs []string,
+	rawLimit string,
+	rawCursor string,
+) error {
 	var height Height
 	err := height.Parse(rawStart)
 	if err != nil {
@@ -57,6 +79,25 @@ func (g *GetEvents) Parse(rawType string, rawStart string, rawEnd string, rawBlo
 		return fmt.Errorf("must provide either block IDs or start and end height range")
 	}
 
+	g.Paged = rawLimit != "" || rawCursor != ""
+	if g.Paged && len(blockIDs) > 0 {
+		return fmt.Errorf("limit and cursor can only be provided with a start and end height range")
+	}
+
+	g.Limit, err = parseLimit(rawLimit)
+	if err != nil {
+		return err
+	}
+
+	g.Cursor = nil
+	if rawCursor != "" {
+		cursor, err := DecodeEventsCursor(rawCursor)
+		if err != nil {
+			return err
+		}
+		g.Cursor = &cursor
+	}
+
 	g.Type = rawType
 	if g.Type == "" {
 		return fmt.Errorf("event type must be provided")
@@ -76,11 +117,36 @@ func (g *GetEvents) Parse(rawType string, rawStart string, rawEnd string, rawBlo
 		if g.StartHeight > g.EndHeight {
 			return fmt.Errorf("start height must be less than or equal to end height")
 		}
-		// check if range exceeds maximum but only if end is not equal to special value which is not known yet
-		if g.EndHeight-g.StartHeight >= MaxEventRequestHeightRange && g.EndHeight != FinalHeight && g.EndHeight != SealedHeight {
+		// check if range exceeds maximum but only if end is not equal to special value which is not known yet,
+		// paged requests are not limited since each page is limited instead
+		if !g.Paged && g.EndHeight-g.StartHeight >= MaxEventRequestHeightRange && g.EndHeight != FinalHeight && g.EndHeight != SealedHeight {
 			return fmt.Errorf("height range %d exceeds maximum allowed of %d", g.EndHeight-g.StartHeight, MaxEventRequestHeightRange)
 		}
 	}
 
 	return nil
 }
+
+// EncodeEventsCursor encodes the cursor as an opaque string that clients pass back to request the
+// next page.
+func EncodeEventsCursor(cursor access.EventsCursor) string {
+	b := make([]byte, eventsCursorLength)
+	binary.BigEndian.PutUint64(b[:8], cursor.BlockHeight)
+	binary.BigEndian.PutUint32(b[8:12], cursor.TransactionIndex)
+	binary.BigEndian.PutUint32(b[12:], cursor.EventIndex)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeEventsCursor decodes a cursor encoded with EncodeEventsCursor.
+func DecodeEventsCursor(raw string) (access.EventsCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil || len(b) != eventsCursorLength {
+		return access.EventsCursor{}, fmt.Errorf("invalid cursor")
+	}
+
+	return access.EventsCursor{
+		BlockHeight:      binary.BigEndian.Uint64(b[:8]),
+		TransactionIndex: binary.BigEndian.Uint32(b[8:12]),
+		EventIndex:       binary.BigEndian.Uint32(b[12:]),
+	}, nil
+}
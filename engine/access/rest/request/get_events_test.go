@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/onflow/flow-go/access"
 )
 
 func TestGetEvents_InvalidParse(t *testing.T) {
@@ -15,21 +17,26 @@ func TestGetEvents_InvalidParse(t *testing.T) {
 		start     string
 		end       string
 		ids       []string
+		limit     string
+		cursor    string
 		err       string
 	}{
-		{"flow.AccountCreated", "", "", nil, "must provide either block IDs or start and end height range"},
-		{"flow.AccountCreated", "10", "", nil, "must provide either block IDs or start and end height range"},
-		{"flow.AccountCreated", "", "10", nil, "must provide either block IDs or start and end height range"},
-		{"flow.AccountCreated", "5", "10", []string{"7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7"}, "can only provide either block IDs or start and end height range"},
-		{"foo", "5", "10", nil, "invalid event type format"},
-		{"A.123.Foo.Bar", "5", "10", nil, "invalid event type format"},
-		{"A.f8d6e0586b0a20c7.Foo.Bar", "20", "10", nil, "start height must be less than or equal to end height"},
-		{"A.f8d6e0586b0a20c7.Foo.Bar", "0", "500", nil, "height range 500 exceeds maximum allowed of 250"},
-		{"A.f8d6e0586b0a20c7.Foo.Bar", "0", "", make([]string, 100), "at most 50 IDs can be requested at a time"},
+		{"flow.AccountCreated", "", "", nil, "", "", "must provide either block IDs or start and end height range"},
+		{"flow.AccountCreated", "10", "", nil, "", "", "must provide either block IDs or start and end height range"},
+		{"flow.AccountCreated", "", "10", nil, "", "", "must provide either block IDs or start and end height range"},
+		{"flow.AccountCreated", "5", "10", []string{"7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7"}, "", "", "can only provide either block IDs or start and end height range"},
+		{"foo", "5", "10", nil, "", "", "invalid event type format"},
+		{"A.123.Foo.Bar", "5", "10", nil, "", "", "invalid event type format"},
+		{"A.f8d6e0586b0a20c7.Foo.Bar", "20", "10", nil, "", "", "start height must be less than or equal to end height"},
+		{"A.f8d6e0586b0a20c7.Foo.Bar", "0", "500", nil, "", "", "height range 500 exceeds maximum allowed of 250"},
+		{"A.f8d6e0586b0a20c7.Foo.Bar", "0", "", make([]string, 100), "", "", "at most 50 IDs can be requested at a time"},
+		{"A.f8d6e0586b0a20c7.Foo.Bar", "", "", []string{"7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7"}, "10", "", "limit and cursor can only be provided with a start and end height range"},
+		{"A.f8d6e0586b0a20c7.Foo.Bar", "0", "500", nil, "-1", "", "invalid limit: value must be an unsigned 32 bit integer"},
+		{"A.f8d6e0586b0a20c7.Foo.Bar", "0", "500", nil, "", "foo", "invalid cursor"},
 	}
 
 	for i, test := range tests {
-		err := getEvents.Parse(test.eventType, test.start, test.end, test.ids)
+		err := getEvents.Parse(test.eventType, test.start, test.end, test.ids, test.limit, test.cursor)
 		assert.EqualError(t, err, test.err, fmt.Sprintf("test #%d failed", i))
 	}
 }
@@ -38,7 +45,7 @@ func TestGetEvents_ValidParse(t *testing.T) {
 	var getEvents GetEvents
 
 	event := "A.f8d6e0586b0a20c7.Foo.Bar"
-	err := getEvents.Parse(event, "5", "10", nil)
+	err := getEvents.Parse(event, "5", "10", nil, "", "")
 	assert.NoError(t, err)
 	assert.Equal(t, getEvents.Type, event)
 	assert.Equal(t, getEvents.StartHeight, uint64(5))
@@ -50,7 +57,7 @@ func TestGetEvents_ValidParse(t *testing.T) {
 		"7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7",
 		"7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7", // intentional duplication
 		"2ab81061b12d95fb81f2923001e340bc808e67e1eaae3c62479057cc14eb57fd",
-	})
+	}, "", "")
 	assert.NoError(t, err)
 	assert.Equal(t, getEvents.Type, event)
 	assert.Equal(t, getEvents.StartHeight, EmptyHeight)
@@ -59,4 +66,11 @@ func TestGetEvents_ValidParse(t *testing.T) {
 	assert.Equal(t, getEvents.BlockIDs[0].String(), "7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7")
 	assert.Equal(t, getEvents.BlockIDs[1].String(), "2ab81061b12d95fb81f2923001e340bc808e67e1eaae3c62479057cc14eb57fd")
 
+	// paged requests are not limited by the maximum height range
+	cursor := access.EventsCursor{BlockHeight: 100, TransactionIndex: 2, EventIndex: 3}
+	err = getEvents.Parse(event, "0", "500", nil, "25", EncodeEventsCursor(cursor))
+	assert.NoError(t, err)
+	assert.True(t, getEvents.Paged)
+	assert.Equal(t, getEvents.Limit, uint32(25))
+	assert.Equal(t, getEvents.Cursor, &cursor)
 }
@@ -1,5 +1,12 @@
 package request
 
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
 const resultExpandable = "result"
 
 type GetTransaction struct {
@@ -17,3 +24,50 @@ func (g *GetTransaction) Build(r *Request) error {
 type GetTransactionResult struct {
 	GetByIDRequest
 }
+
+type GetBlockTransactions struct {
+	GetByIDRequest
+	ExpandsResult bool
+}
+
+func (g *GetBlockTransactions) Build(r *Request) error {
+	err := g.GetByIDRequest.Build(r)
+	g.ExpandsResult = r.Expands(resultExpandable)
+
+	return err
+}
+
+type GetBlockTransactionResults struct {
+	GetByIDRequest
+}
+
+const indexVar = "index"
+
+type GetTransactionResultByIndex struct {
+	BlockID flow.Identifier
+	Index   uint32
+}
+
+func (g *GetTransactionResultByIndex) Build(r *Request) error {
+	return g.Parse(
+		r.GetVar(idQuery),
+		r.GetVar(indexVar),
+	)
+}
+
+func (g *GetTransactionResultByIndex) Parse(rawBlockID string, rawIndex string) error {
+	var blockID ID
+	err := blockID.Parse(rawBlockID)
+	if err != nil {
+		return err
+	}
+	g.BlockID = blockID.Flow()
+
+	index, err := strconv.ParseUint(rawIndex, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid transaction index: value must be an unsigned 32 bit integer")
+	}
+	g.Index = uint32(index)
+
+	return nil
+}
@@ -54,6 +54,12 @@ func (rd *Request) GetAccountRequest() (GetAccount, error) {
 	return req, err
 }
 
+func (rd *Request) GetAccountTransactionsRequest() (GetAccountTransactions, error) {
+	var req GetAccountTransactions
+	err := req.Build(rd)
+	return req, err
+}
+
 func (rd *Request) GetExecutionResultByBlockIDsRequest() (GetExecutionResultByBlockIDs, error) {
 	var req GetExecutionResultByBlockIDs
 	err := req.Build(rd)
@@ -78,6 +84,24 @@ func (rd *Request) GetTransactionResultRequest() (GetTransactionResult, error) {
 	return req, err
 }
 
+func (rd *Request) GetBlockTransactionsRequest() (GetBlockTransactions, error) {
+	var req GetBlockTransactions
+	err := req.Build(rd)
+	return req, err
+}
+
+func (rd *Request) GetBlockTransactionResultsRequest() (GetBlockTransactionResults, error) {
+	var req GetBlockTransactionResults
+	err := req.Build(rd)
+	return req, err
+}
+
+func (rd *Request) GetTransactionResultByIndexRequest() (GetTransactionResultByIndex, error) {
+	var req GetTransactionResultByIndex
+	err := req.Build(rd)
+	return req, err
+}
+
 func (rd *Request) GetEventsRequest() (GetEvents, error) {
 	var req GetEvents
 	err := req.Build(rd)
@@ -90,6 +114,18 @@ func (rd *Request) CreateTransactionRequest() (CreateTransaction, error) {
 	return req, err
 }
 
+func (rd *Request) SimulateTransactionRequest() (SimulateTransaction, error) {
+	var req SimulateTransaction
+	err := req.Build(rd)
+	return req, err
+}
+
+func (rd *Request) EstimateTransactionFeesRequest() (EstimateTransactionFees, error) {
+	var req EstimateTransactionFees
+	err := req.Build(rd)
+	return req, err
+}
+
 func (rd *Request) Expands(field string) bool {
 	return rd.ExpandFields[field]
 }
@@ -0,0 +1,56 @@
+package request
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const skipChecksQuery = "skip_checks"
+
+type SimulateTransaction struct {
+	BlockID     flow.Identifier
+	SkipChecks  bool
+	Transaction flow.TransactionBody
+}
+
+func (s *SimulateTransaction) Build(r *Request) error {
+	return s.Parse(
+		r.GetQueryParam(blockIDQuery),
+		r.GetQueryParam(skipChecksQuery),
+		r.Body,
+		r.Chain,
+	)
+}
+
+func (s *SimulateTransaction) Parse(rawID string, rawSkipChecks string, rawTransaction io.Reader, chain flow.Chain) error {
+	var id ID
+	err := id.Parse(rawID)
+	if err != nil {
+		return err
+	}
+	s.BlockID = id.Flow()
+
+	if rawSkipChecks != "" {
+		s.SkipChecks, err = strconv.ParseBool(rawSkipChecks)
+		if err != nil {
+			return fmt.Errorf("invalid value for skip checks: %s", rawSkipChecks)
+		}
+	}
+
+	// signatures are only required if the signature checks are not skipped
+	var tx Transaction
+	if s.SkipChecks {
+		err = tx.ParseUnsigned(rawTransaction, chain)
+	} else {
+		err = tx.Parse(rawTransaction, chain)
+	}
+	if err != nil {
+		return err
+	}
+	s.Transaction = tx.Flow()
+
+	return nil
+}
@@ -31,6 +31,16 @@ func newRouter(backend access.API, logger zerolog.Logger, chain flow.Chain) (*mu
 			Name(r.Name).
 			Handler(h)
 	}
+
+	for _, r := range WSRoutes {
+		h := NewWSHandler(logger, backend, r.Handler, linkGenerator, chain)
+		v1SubRouter.
+			Methods(http.MethodGet).
+			Path(r.Pattern).
+			Name(r.Name).
+			Handler(h)
+	}
+
 	return router, nil
 }
 
@@ -41,6 +51,12 @@ type route struct {
 	Handler ApiHandlerFunc
 }
 
+type wsRoute struct {
+	Name    string
+	Pattern string
+	Handler WSHandlerFunc
+}
+
 var Routes = []route{{
 	Method:  http.MethodGet,
 	Pattern: "/transactions/{id}",
@@ -51,6 +67,16 @@ var Routes = []route{{
 	Pattern: "/transactions",
 	Name:    "createTransaction",
 	Handler: CreateTransaction,
+}, {
+	Method:  http.MethodPost,
+	Pattern: "/transactions/simulate",
+	Name:    "simulateTransaction",
+	Handler: SimulateTransaction,
+}, {
+	Method:  http.MethodPost,
+	Pattern: "/transactions/estimate_fees",
+	Name:    "estimateTransactionFees",
+	Handler: EstimateTransactionFees,
 }, {
 	Method:  http.MethodGet,
 	Pattern: "/transaction_results/{id}",
@@ -71,6 +97,31 @@ var Routes = []route{{
 	Pattern: "/blocks/{id}/payload",
 	Name:    "getBlockPayloadByID",
 	Handler: GetBlockPayloadByID,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/blocks/{id}/transactions",
+	Name:    "getTransactionsByBlockID",
+	Handler: GetTransactionsByBlockID,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/blocks/{id}/transaction_results",
+	Name:    "getTransactionResultsByBlockID",
+	Handler: GetTransactionResultsByBlockID,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/blocks/{id}/transaction_results/{index}",
+	Name:    "getTransactionResultByIndex",
+	Handler: GetTransactionResultByIndex,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/block_headers/{id}",
+	Name:    "getBlockHeadersByIDs",
+	Handler: GetBlockHeadersByIDs,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/block_headers",
+	Name:    "getBlockHeadersByHeight",
+	Handler: GetBlockHeadersByHeight,
 }, {
 	Method:  http.MethodGet,
 	Pattern: "/execution_results/{id}",
@@ -96,6 +147,11 @@ var Routes = []route{{
 	Pattern: "/accounts/{address}",
 	Name:    "getAccount",
 	Handler: GetAccount,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/accounts/{address}/transactions",
+	Name:    "getAccountTransactions",
+	Handler: GetAccountTransactions,
 }, {
 	Method:  http.MethodGet,
 	Pattern: "/events",
@@ -106,4 +162,17 @@ var Routes = []route{{
 	Pattern: "/network/parameters",
 	Name:    "getNetworkParameters",
 	Handler: GetNetworkParameters,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/protocol_state_snapshot",
+	Name:    "getLatestProtocolStateSnapshot",
+	Handler: GetLatestProtocolStateSnapshot,
+}}
+
+// WSRoutes are the websocket endpoints. Clients connect with a GET request, then send the
+// subscription request as the first message.
+var WSRoutes = []wsRoute{{
+	Pattern: "/subscribe_transaction_statuses",
+	Name:    "subscribeTransactionStatuses",
+	Handler: SubscribeTransactionStatuses,
 }}
@@ -1,9 +1,14 @@
 package rest
 
 import (
+	"context"
+	"fmt"
+	"io"
+
 	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/engine/access/rest/models"
 	"github.com/onflow/flow-go/engine/access/rest/request"
+	"github.com/onflow/flow-go/model/flow"
 )
 
 // GetTransactionByID gets a transaction by requested ID.
@@ -49,6 +54,83 @@ func GetTransactionResultByID(r *request.Request, backend access.API, link model
 	return response, nil
 }
 
+// GetTransactionsByBlockID gets all transactions in the block with the requested ID.
+func GetTransactionsByBlockID(r *request.Request, backend access.API, link models.LinkGenerator) (interface{}, error) {
+	req, err := r.GetBlockTransactionsRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	txs, err := backend.GetTransactionsByBlockID(r.Context(), req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// only lookup results if transaction results are to be expanded
+	results := make(map[flow.Identifier]*access.TransactionResult)
+	if req.ExpandsResult {
+		txrs, err := backend.GetTransactionResultsByBlockID(r.Context(), req.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, txr := range txrs {
+			results[txr.TransactionID] = txr
+		}
+	}
+
+	response := make(models.Transactions, len(txs))
+	for i, tx := range txs {
+		response[i].Build(tx, results[tx.ID()], link)
+	}
+	return response, nil
+}
+
+// GetTransactionResultsByBlockID gets the results of all transactions in the block with the requested ID.
+func GetTransactionResultsByBlockID(r *request.Request, backend access.API, link models.LinkGenerator) (interface{}, error) {
+	req, err := r.GetBlockTransactionResultsRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	txrs, err := backend.GetTransactionResultsByBlockID(r.Context(), req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.TransactionResult, len(txrs))
+	for i, txr := range txrs {
+		response[i].Build(txr, txr.TransactionID, link)
+	}
+	return response, nil
+}
+
+// GetTransactionResultByIndex gets the result of the transaction at the requested index in the block.
+func GetTransactionResultByIndex(r *request.Request, backend access.API, link models.LinkGenerator) (interface{}, error) {
+	req, err := r.GetTransactionResultByIndexRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	txr, err := backend.GetTransactionResultByIndex(r.Context(), req.BlockID, req.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	// the result returned by index does not include the transaction ID, which is needed for the link
+	txs, err := backend.GetTransactionsByBlockID(r.Context(), req.BlockID)
+	if err != nil {
+		return nil, err
+	}
+	if int(req.Index) >= len(txs) {
+		err := fmt.Errorf("transaction index %d not found in block %v", req.Index, req.BlockID)
+		return nil, NewNotFoundError(err.Error(), err)
+	}
+
+	var response models.TransactionResult
+	response.Build(txr, txs[req.Index].ID(), link)
+	return response, nil
+}
+
 // CreateTransaction creates a new transaction from provided payload.
 func CreateTransaction(r *request.Request, backend access.API, link models.LinkGenerator) (interface{}, error) {
 	req, err := r.CreateTransactionRequest()
@@ -65,3 +147,85 @@ func CreateTransaction(r *request.Request, backend access.API, link models.LinkG
 	response.Build(&req.Transaction, nil, link)
 	return response, nil
 }
+
+// SimulateTransaction executes the provided transaction against the execution state as of the
+// requested block, or the latest sealed block if none is requested, without submitting it.
+func SimulateTransaction(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
+	req, err := r.SimulateTransactionRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	// default to latest sealed block
+	if req.BlockID == flow.ZeroID {
+		header, _, err := backend.GetLatestBlockHeader(r.Context(), true)
+		if err != nil {
+			return nil, err
+		}
+		req.BlockID = header.ID()
+	}
+
+	result, err := backend.SimulateTransaction(r.Context(), &req.Transaction, req.BlockID, req.SkipChecks)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.TransactionSimulation
+	response.Build(result)
+	return response, nil
+}
+
+// EstimateTransactionFees executes the provided transaction against the execution state as of the
+// latest sealed block with the maximum gas limit, and returns its resource usage and fees.
+func EstimateTransactionFees(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
+	req, err := r.EstimateTransactionFeesRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	estimate, err := backend.EstimateTransactionFees(r.Context(), &req.Transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.TransactionFeesEstimate
+	response.Build(estimate)
+	return response, nil
+}
+
+// SubscribeTransactionStatuses sends the transaction provided in the subscription request and
+// streams a transaction result each time its status changes, until it is sealed or expired.
+func SubscribeTransactionStatuses(
+	ctx context.Context,
+	r *request.Request,
+	msg io.Reader,
+	backend access.API,
+	link models.LinkGenerator,
+	send func(response interface{}) error,
+) error {
+	var req request.CreateTransaction
+	err := req.Parse(msg, r.Chain)
+	if err != nil {
+		return NewBadRequestError(err)
+	}
+
+	txID := req.Transaction.ID()
+	sub := backend.SendAndSubscribeTransactionStatuses(ctx, &req.Transaction)
+
+	for v := range sub.Channel() {
+		txr, ok := v.(*access.TransactionResult)
+		if !ok {
+			return fmt.Errorf("unexpected response type: %T", v)
+		}
+
+		var response models.TransactionResult
+		response.Build(txr, txID, link)
+
+		err = send(response)
+		if err != nil {
+			return err
+		}
+	}
+
+	return sub.Err()
+}
@@ -2,14 +2,21 @@ package rest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
 	mocks "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 	"google.golang.org/grpc/codes"
@@ -19,6 +26,7 @@ import (
 	"github.com/onflow/flow-go/access/mock"
 	"github.com/onflow/flow-go/engine/access/rest/models"
 	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/engine/access/state_stream"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/utils/unittest"
 )
@@ -47,6 +55,28 @@ func createTransactionReq(body interface{}) *http.Request {
 	return req
 }
 
+func simulateTransactionReq(body interface{}, blockID string, skipChecks string) *http.Request {
+	u, _ := url.Parse("/v1/transactions/simulate")
+	q := u.Query()
+	if blockID != "" {
+		q.Add("block_id", blockID)
+	}
+	if skipChecks != "" {
+		q.Add("skip_checks", skipChecks)
+	}
+	u.RawQuery = q.Encode()
+
+	jsonBody, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", u.String(), bytes.NewBuffer(jsonBody))
+	return req
+}
+
+func estimateTransactionFeesReq(body interface{}) *http.Request {
+	jsonBody, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", "/v1/transactions/estimate_fees", bytes.NewBuffer(jsonBody))
+	return req
+}
+
 func validCreateBody(tx flow.TransactionBody) map[string]interface{} {
 	tx.Arguments = [][]uint8{} // fix how fixture creates nil values
 	auth := make([]string, len(tx.Authorizers))
@@ -404,6 +434,310 @@ func TestCreateTransaction(t *testing.T) {
 	})
 }
 
+func TestSimulateTransaction(t *testing.T) {
+	tx := unittest.TransactionBodyFixture()
+	tx.PayloadSignatures = []flow.TransactionSignature{unittest.TransactionSignatureFixture()}
+	tx.Arguments = [][]uint8{}
+
+	event := unittest.EventFixture(flow.EventAccountCreated, 0, 0, tx.ID(), 0)
+	blockID := unittest.IdentifierFixture()
+	result := &flow.TransactionSimulationResult{
+		TransactionID:   tx.ID(),
+		BlockID:         blockID,
+		Events:          []flow.Event{event},
+		ErrorCode:       1101,
+		ErrorMessage:    "cadence runtime error",
+		ComputationUsed: 10,
+		MemoryEstimate:  20,
+		WriteSet: flow.RegisterEntries{
+			{Key: flow.NewRegisterID("owner", "key"), Value: []byte{1}},
+		},
+	}
+
+	expected := fmt.Sprintf(`{
+		"transaction_id": "%s",
+		"block_id": "%s",
+		"error_code": 1101,
+		"error_message": "cadence runtime error",
+		"computation_used": "10",
+		"memory_estimate": "20",
+		"events": [{
+			"type": "flow.AccountCreated",
+			"transaction_id": "%s",
+			"transaction_index": "0",
+			"event_index": "0",
+			"payload": "%s"
+		}],
+		"write_set": [{
+			"owner": "%s",
+			"key": "%s",
+			"value": "AQ=="
+		}]
+	}`,
+		tx.ID(), blockID, tx.ID(), util.ToBase64(event.Payload),
+		util.ToBase64([]byte("owner")), util.ToBase64([]byte("key")))
+
+	t.Run("at block ID", func(t *testing.T) {
+		backend := &mock.API{}
+		req := simulateTransactionReq(validCreateBody(tx), blockID.String(), "")
+
+		backend.Mock.
+			On("SimulateTransaction", mocks.Anything, &tx, blockID, false).
+			Return(result, nil)
+
+		assertOKResponse(t, req, expected, backend)
+	})
+
+	t.Run("at latest sealed block", func(t *testing.T) {
+		backend := &mock.API{}
+		req := simulateTransactionReq(validCreateBody(tx), "", "true")
+
+		header := unittest.BlockHeaderFixture()
+		backend.Mock.
+			On("GetLatestBlockHeader", mocks.Anything, true).
+			Return(header, flow.BlockStatusSealed, nil)
+		backend.Mock.
+			On("SimulateTransaction", mocks.Anything, &tx, header.ID(), true).
+			Return(result, nil)
+
+		assertOKResponse(t, req, expected, backend)
+	})
+
+	t.Run("unsigned transaction with checks skipped", func(t *testing.T) {
+		backend := &mock.API{}
+		body := validCreateBody(tx)
+		delete(body, "envelope_signatures")
+		req := simulateTransactionReq(body, blockID.String(), "true")
+
+		backend.Mock.
+			On("SimulateTransaction", mocks.Anything, mocks.Anything, blockID, true).
+			Return(result, nil)
+
+		assertOKResponse(t, req, expected, backend)
+	})
+
+	t.Run("unsigned transaction with checks enforced", func(t *testing.T) {
+		backend := &mock.API{}
+		body := validCreateBody(tx)
+		delete(body, "envelope_signatures")
+		req := simulateTransactionReq(body, blockID.String(), "")
+
+		assertResponse(t, req, http.StatusBadRequest, `{"code":400, "message":"envelope signatures not provided"}`, backend)
+	})
+
+	t.Run("invalid skip checks", func(t *testing.T) {
+		backend := &mock.API{}
+		req := simulateTransactionReq(validCreateBody(tx), blockID.String(), "maybe")
+
+		assertResponse(t, req, http.StatusBadRequest, `{"code":400, "message":"invalid value for skip checks: maybe"}`, backend)
+	})
+
+	t.Run("simulation not supported", func(t *testing.T) {
+		backend := &mock.API{}
+		req := simulateTransactionReq(validCreateBody(tx), blockID.String(), "")
+
+		backend.Mock.
+			On("SimulateTransaction", mocks.Anything, &tx, blockID, false).
+			Return(nil, status.Error(codes.Unimplemented, "not supported"))
+
+		assertResponse(t, req, http.StatusNotImplemented, `{"code":501, "message":"Not supported by this node: not supported"}`, backend)
+	})
+}
+
+func TestEstimateTransactionFees(t *testing.T) {
+	tx := unittest.TransactionBodyFixture()
+	tx.PayloadSignatures = []flow.TransactionSignature{unittest.TransactionSignatureFixture()}
+	tx.Arguments = [][]uint8{}
+
+	t.Run("estimate", func(t *testing.T) {
+		backend := &mock.API{}
+		blockID := unittest.IdentifierFixture()
+
+		// the transaction does not need to be signed
+		body := validCreateBody(tx)
+		delete(body, "envelope_signatures")
+		req := estimateTransactionFeesReq(body)
+
+		backend.Mock.
+			On("EstimateTransactionFees", mocks.Anything, mocks.Anything).
+			Return(&flow.TransactionFeesEstimate{
+				TransactionID:          tx.ID(),
+				BlockID:                blockID,
+				ComputationUsed:        42,
+				ComputationIntensities: map[uint]uint{1002: 40},
+				MemoryEstimate:         100,
+				StorageBytesRead:       200,
+				StorageBytesWritten:    50,
+				InclusionFee:           1000,
+				ExecutionFee:           42,
+			}, nil)
+
+		expected := fmt.Sprintf(`{
+			"transaction_id": "%s",
+			"block_id": "%s",
+			"computation_used": "42",
+			"computation_intensities": {"Loop": "40"},
+			"memory_estimate": "100",
+			"storage_bytes_read": "200",
+			"storage_bytes_written": "50",
+			"inclusion_fee": "0.00001000",
+			"execution_fee": "0.00000042",
+			"total_fee": "0.00001042",
+			"error_code": 0,
+			"error_message": ""
+		}`, tx.ID(), blockID)
+
+		assertOKResponse(t, req, expected, backend)
+	})
+
+	t.Run("invalid transaction", func(t *testing.T) {
+		backend := &mock.API{}
+		body := validCreateBody(tx)
+		delete(body, "script")
+		req := estimateTransactionFeesReq(body)
+
+		assertResponse(t, req, http.StatusBadRequest, `{"code":400, "message":"script not provided"}`, backend)
+	})
+
+	t.Run("estimation not supported", func(t *testing.T) {
+		backend := &mock.API{}
+		req := estimateTransactionFeesReq(validCreateBody(tx))
+
+		backend.Mock.
+			On("EstimateTransactionFees", mocks.Anything, &tx).
+			Return(nil, status.Error(codes.Unimplemented, "not supported"))
+
+		assertResponse(t, req, http.StatusNotImplemented, `{"code":501, "message":"Not supported by this node: not supported"}`, backend)
+	})
+}
+
+func TestGetTransactionsByBlockID(t *testing.T) {
+	backend := &mock.API{}
+	blockID := unittest.IdentifierFixture()
+
+	tx1 := unittest.TransactionBodyFixture()
+	tx2 := unittest.TransactionBodyFixture(unittest.WithTransactionDSL(unittest.TransactionDSLFixture(flow.Emulator.Chain())))
+	txs := []*flow.TransactionBody{&tx1, &tx2}
+
+	results := []*access.TransactionResult{
+		{Status: flow.TransactionStatusSealed, BlockID: blockID, TransactionID: tx1.ID()},
+		{Status: flow.TransactionStatusSealed, BlockID: blockID, TransactionID: tx2.ID(), ErrorMessage: "failed"},
+	}
+
+	backend.Mock.
+		On("GetTransactionsByBlockID", mocks.Anything, blockID).
+		Return(txs, nil)
+	backend.Mock.
+		On("GetTransactionResultsByBlockID", mocks.Anything, blockID).
+		Return(results, nil)
+
+	t.Run("get transactions", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/blocks/%s/transactions", blockID), nil)
+		rr, err := executeRequest(req, backend)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response []models.Transaction
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Len(t, response, len(txs))
+		for i, tx := range txs {
+			assert.Equal(t, tx.ID().String(), response[i].Id)
+			assert.Nil(t, response[i].Result)
+			assert.Equal(t, fmt.Sprintf("/v1/transaction_results/%s", tx.ID()), response[i].Expandable.Result)
+		}
+	})
+
+	t.Run("get transactions with results", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/blocks/%s/transactions?expand=result", blockID), nil)
+		rr, err := executeRequest(req, backend)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response []models.Transaction
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Len(t, response, len(txs))
+		for i, tx := range txs {
+			assert.Equal(t, tx.ID().String(), response[i].Id)
+			require.NotNil(t, response[i].Result)
+			assert.Equal(t, results[i].ErrorMessage, response[i].Result.ErrorMessage)
+		}
+	})
+
+	t.Run("get transaction results", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/blocks/%s/transaction_results", blockID), nil)
+		rr, err := executeRequest(req, backend)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var response []models.TransactionResult
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Len(t, response, len(results))
+		for i, txr := range results {
+			assert.Equal(t, blockID.String(), response[i].BlockId)
+			assert.Equal(t, txr.ErrorMessage, response[i].ErrorMessage)
+			assert.Equal(t, fmt.Sprintf("/v1/transaction_results/%s", txr.TransactionID), response[i].Links.Self)
+		}
+	})
+
+	t.Run("get invalid block ID", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/blocks/invalid/transaction_results", nil)
+		assertResponse(t, req, http.StatusBadRequest, `{"code":400, "message":"invalid ID format"}`, backend)
+	})
+}
+
+func TestGetTransactionResultByIndex(t *testing.T) {
+	backend := &mock.API{}
+	blockID := unittest.IdentifierFixture()
+
+	tx1 := unittest.TransactionBodyFixture()
+	tx2 := unittest.TransactionBodyFixture(unittest.WithTransactionDSL(unittest.TransactionDSLFixture(flow.Emulator.Chain())))
+	txs := []*flow.TransactionBody{&tx1, &tx2}
+
+	backend.Mock.
+		On("GetTransactionsByBlockID", mocks.Anything, blockID).
+		Return(txs, nil)
+
+	t.Run("get by index", func(t *testing.T) {
+		txr := &access.TransactionResult{
+			Status:     flow.TransactionStatusSealed,
+			StatusCode: 1,
+			BlockID:    blockID,
+		}
+		backend.Mock.
+			On("GetTransactionResultByIndex", mocks.Anything, blockID, uint32(1)).
+			Return(txr, nil)
+
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/blocks/%s/transaction_results/1", blockID), nil)
+		expected := fmt.Sprintf(`{
+			"block_id": "%s",
+			"execution": "Success",
+			"status": "Sealed",
+			"status_code": 1,
+			"error_message": "",
+			"computation_used": "0",
+			"events": [],
+			"_links": {
+				"_self": "/v1/transaction_results/%s"
+			}
+		}`, blockID, tx2.ID())
+		assertOKResponse(t, req, expected, backend)
+	})
+
+	t.Run("get by invalid index", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/blocks/%s/transaction_results/-1", blockID), nil)
+		assertResponse(t, req, http.StatusBadRequest, `{"code":400, "message":"invalid transaction index: value must be an unsigned 32 bit integer"}`, backend)
+	})
+
+	t.Run("get by index not found", func(t *testing.T) {
+		backend.Mock.
+			On("GetTransactionResultByIndex", mocks.Anything, blockID, uint32(5)).
+			Return(nil, status.Error(codes.NotFound, "transaction result not found"))
+
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/blocks/%s/transaction_results/5", blockID), nil)
+		assertResponse(t, req, http.StatusNotFound, `{"code":404, "message":"Flow resource not found: transaction result not found"}`, backend)
+	})
+}
+
 func transactionResultFixture(tx flow.Transaction) *access.TransactionResult {
 	return &access.TransactionResult{
 		Status:     flow.TransactionStatusSealed,
@@ -415,3 +749,103 @@ func transactionResultFixture(tx flow.Transaction) *access.TransactionResult {
 		BlockID:      tx.ReferenceBlockID,
 	}
 }
+
+func TestSubscribeTransactionStatuses(t *testing.T) {
+	// dial starts a test server with the REST API and opens a websocket connection to the
+	// transaction status subscription endpoint
+	dial := func(t *testing.T, backend *mock.API) *websocket.Conn {
+		router, err := newRouter(backend, zerolog.Nop(), flow.Testnet.Chain())
+		require.NoError(t, err)
+
+		server := httptest.NewServer(router)
+		t.Cleanup(server.Close)
+
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/subscribe_transaction_statuses"
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		return conn
+	}
+
+	t.Run("stream statuses", func(t *testing.T) {
+		backend := &mock.API{}
+		tx := unittest.TransactionBodyFixture()
+		tx.PayloadSignatures = []flow.TransactionSignature{unittest.TransactionSignatureFixture()}
+		tx.Arguments = [][]uint8{}
+		bid := unittest.IdentifierFixture()
+
+		statuses := []flow.TransactionStatus{
+			flow.TransactionStatusPending,
+			flow.TransactionStatusFinalized,
+			flow.TransactionStatusExecuted,
+			flow.TransactionStatusSealed,
+		}
+
+		sub := state_stream.NewSubscription(len(statuses))
+		for _, s := range statuses {
+			err := sub.Send(context.Background(), &access.TransactionResult{
+				Status:        s,
+				BlockID:       bid,
+				TransactionID: tx.ID(),
+			}, time.Second)
+			require.NoError(t, err)
+		}
+		sub.Close()
+
+		backend.Mock.
+			On("SendAndSubscribeTransactionStatuses", mocks.Anything, &tx).
+			Return(sub)
+
+		conn := dial(t, backend)
+		require.NoError(t, conn.WriteJSON(validCreateBody(tx)))
+
+		for _, s := range statuses {
+			var response models.TransactionResult
+			require.NoError(t, conn.ReadJSON(&response))
+
+			var expectedStatus models.TransactionStatus
+			expectedStatus.Build(s)
+			assert.Equal(t, expectedStatus, *response.Status)
+			assert.Equal(t, bid.String(), response.BlockId)
+		}
+
+		// the server closes the connection after the last status
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+	})
+
+	t.Run("send error", func(t *testing.T) {
+		backend := &mock.API{}
+		tx := unittest.TransactionBodyFixture()
+		tx.PayloadSignatures = []flow.TransactionSignature{unittest.TransactionSignatureFixture()}
+		tx.Arguments = [][]uint8{}
+
+		sub := state_stream.NewFailedSubscription(status.Error(codes.InvalidArgument, "invalid transaction"), "failed to send transaction")
+
+		backend.Mock.
+			On("SendAndSubscribeTransactionStatuses", mocks.Anything, &tx).
+			Return(sub)
+
+		conn := dial(t, backend)
+		require.NoError(t, conn.WriteJSON(validCreateBody(tx)))
+
+		var response models.ModelError
+		require.NoError(t, conn.ReadJSON(&response))
+		assert.Equal(t, int32(http.StatusBadRequest), response.Code)
+		assert.Equal(t, "Invalid Flow argument: failed to send transaction: invalid transaction", response.Message)
+	})
+
+	t.Run("invalid transaction", func(t *testing.T) {
+		backend := &mock.API{}
+
+		conn := dial(t, backend)
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"payer": "yo"}`)))
+
+		var response models.ModelError
+		require.NoError(t, conn.ReadJSON(&response))
+		assert.Equal(t, int32(http.StatusBadRequest), response.Code)
+
+		backend.AssertNotCalled(t, "SendAndSubscribeTransactionStatuses", mocks.Anything, mocks.Anything)
+	})
+}
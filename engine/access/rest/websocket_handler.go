@@ -0,0 +1,159 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/engine/access/rest/request"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const (
+	// wsReadTimeout is the maximum time to wait for the client's subscription request
+	wsReadTimeout = 15 * time.Second
+
+	// wsWriteTimeout is the maximum time to wait for a message to be written to the client
+	wsWriteTimeout = 15 * time.Second
+)
+
+// WSHandlerFunc is a function that contains websocket endpoint handling logic.
+// It reads the subscription request from the first message sent by the client, then calls send
+// for each response until the subscription ends. The returned error is sent to the client before
+// the connection is closed.
+type WSHandlerFunc func(
+	ctx context.Context,
+	r *request.Request,
+	msg io.Reader,
+	backend access.API,
+	generator models.LinkGenerator,
+	send func(response interface{}) error,
+) error
+
+// WSHandler is a custom http handler which upgrades the connection to a websocket, and streams
+// the responses of a subscription to the client.
+type WSHandler struct {
+	logger        zerolog.Logger
+	backend       access.API
+	linkGenerator models.LinkGenerator
+	wsHandlerFunc WSHandlerFunc
+	chain         flow.Chain
+	upgrader      websocket.Upgrader
+}
+
+func NewWSHandler(
+	logger zerolog.Logger,
+	backend access.API,
+	handlerFunc WSHandlerFunc,
+	generator models.LinkGenerator,
+	chain flow.Chain,
+) *WSHandler {
+	return &WSHandler{
+		logger:        logger,
+		backend:       backend,
+		wsHandlerFunc: handlerFunc,
+		linkGenerator: generator,
+		chain:         chain,
+		upgrader: websocket.Upgrader{
+			// allow connections from any origin, consistent with the CORS policy of the REST API
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// ServeHTTP upgrades the connection to a websocket, reads the subscription request and streams
+// responses until the subscription ends or the client disconnects.
+func (h *WSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	errLog := h.logger.With().Str("request_url", r.URL.String()).Logger()
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already replied to the client with an HTTP error
+		errLog.Debug().Err(err).Msg("could not upgrade connection to websocket")
+		return
+	}
+	defer conn.Close()
+
+	conn.SetReadLimit(MaxRequestSize)
+
+	// the connection inherits the deadlines of the HTTP server, so they must be reset
+	err = conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+	if err != nil {
+		errLog.Debug().Err(err).Msg("could not set read deadline")
+		return
+	}
+
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		errLog.Debug().Err(err).Msg("could not read subscription request")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	send := func(response interface{}) error {
+		err := conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err != nil {
+			return err
+		}
+		return conn.WriteJSON(response)
+	}
+
+	handlerDone := make(chan error, 1)
+	go func() {
+		handlerDone <- h.wsHandlerFunc(ctx, request.Decorate(r, h.chain), bytes.NewReader(msg), h.backend, h.linkGenerator, send)
+	}()
+
+	go h.cancelOnClose(ctx, conn, cancel)
+
+	err = <-handlerDone
+	if err != nil {
+		if ctx.Err() != nil {
+			// the client disconnected
+			return
+		}
+
+		modelError := toModelError(err, errLog)
+		if sendErr := send(modelError); sendErr != nil {
+			errLog.Debug().Err(sendErr).Msg("could not send error to client")
+			return
+		}
+	}
+
+	err = conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(wsWriteTimeout),
+	)
+	if err != nil {
+		errLog.Debug().Err(err).Msg("could not send close message")
+	}
+}
+
+// cancelOnClose reads from the connection until it is closed by the client, then cancels the
+// subscription's context. Messages sent by the client after the subscription request are ignored.
+func (h *WSHandler) cancelOnClose(ctx context.Context, conn *websocket.Conn, cancel context.CancelFunc) {
+	defer cancel()
+
+	err := conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		return
+	}
+
+	for {
+		if _, _, err := conn.NextReader(); err != nil {
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -10,11 +10,13 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/common/rpc"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/execution"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
 )
@@ -55,6 +57,8 @@ var fixedENIdentifiers flow.IdentifierList
 // Block details related calls are handled by backendBlockDetails.
 // Event related calls are handled by backendEvents.
 // Account related calls are handled by backendAccounts.
+// Account transaction history calls are handled by backendAccountTransactions.
+// Transaction simulation and fee estimation calls are handled by backendSimulation.
 //
 // All remaining calls are handled by the base Backend in this file.
 type Backend struct {
@@ -64,6 +68,8 @@ type Backend struct {
 	backendBlockHeaders
 	backendBlockDetails
 	backendAccounts
+	backendAccountTransactions
+	backendSimulation
 	backendExecutionResults
 	backendNetwork
 
@@ -74,6 +80,30 @@ type Backend struct {
 	connFactory       ConnectionFactory
 }
 
+// Options holds the optional dependencies of the backend. The zero value disables all of them.
+type Options struct {
+	// ScriptExecutor executes scripts, reads accounts, simulates transactions and estimates
+	// transaction fees against locally indexed state. Execution nodes are only queried for
+	// scripts and accounts if the state is not indexed locally.
+	ScriptExecutor execution.ScriptExecutor
+
+	// AccountTransactions is the index account transaction history is served from. Without an
+	// index, account transaction requests are not supported.
+	AccountTransactions storage.AccountTransactions
+
+	// ScriptCache caches the results of scripts executed against sealed blocks, and serves
+	// repeated executions from the cache.
+	ScriptCache *ScriptCache
+
+	// ExecutionNodeHealth tracks the health of execution nodes, so requests are sent to the
+	// healthiest execution nodes first. Without it, execution nodes are chosen randomly.
+	ExecutionNodeHealth *ExecutionNodeHealth
+
+	// HedgeDelay is the delay after which a read-only request that did not complete is also sent
+	// to the next execution node. The first response is used. A delay of 0 disables hedging.
+	HedgeDelay time.Duration
+}
+
 func New(
 	state protocol.State,
 	collectionRPC accessproto.AccessAPIClient,
@@ -93,6 +123,51 @@ func New(
 	fixedExecutionNodeIDs []string,
 	log zerolog.Logger,
 	snapshotHistoryLimit int,
+) *Backend {
+	return NewWithOptions(
+		state,
+		collectionRPC,
+		historicalAccessNodes,
+		blocks,
+		headers,
+		collections,
+		transactions,
+		executionReceipts,
+		executionResults,
+		chainID,
+		transactionMetrics,
+		connFactory,
+		retryEnabled,
+		maxHeightRange,
+		preferredExecutionNodeIDs,
+		fixedExecutionNodeIDs,
+		log,
+		snapshotHistoryLimit,
+		Options{},
+	)
+}
+
+// NewWithOptions creates a new Backend with the given optional dependencies.
+func NewWithOptions(
+	state protocol.State,
+	collectionRPC accessproto.AccessAPIClient,
+	historicalAccessNodes []accessproto.AccessAPIClient,
+	blocks storage.Blocks,
+	headers storage.Headers,
+	collections storage.Collections,
+	transactions storage.Transactions,
+	executionReceipts storage.ExecutionReceipts,
+	executionResults storage.ExecutionResults,
+	chainID flow.ChainID,
+	transactionMetrics module.TransactionMetrics,
+	connFactory ConnectionFactory,
+	retryEnabled bool,
+	maxHeightRange uint,
+	preferredExecutionNodeIDs []string,
+	fixedExecutionNodeIDs []string,
+	log zerolog.Logger,
+	snapshotHistoryLimit int,
+	options Options,
 ) *Backend {
 	retry := newRetry()
 	if retryEnabled {
@@ -115,6 +190,10 @@ func New(
 			log:               log,
 			metrics:           transactionMetrics,
 			loggedScripts:     loggedScripts,
+			scriptExecutor:    options.ScriptExecutor,
+			scriptCache:       options.ScriptCache,
+			nodeHealth:        options.ExecutionNodeHealth,
+			hedgeDelay:        options.HedgeDelay,
 		},
 		backendTransactions: backendTransactions{
 			staticCollectionRPC:  collectionRPC,
@@ -130,6 +209,9 @@ func New(
 			connFactory:          connFactory,
 			previousAccessNodes:  historicalAccessNodes,
 			log:                  log,
+			statusBroadcaster:    engine.NewBroadcaster(),
+			nodeHealth:           options.ExecutionNodeHealth,
+			hedgeDelay:           options.HedgeDelay,
 		},
 		backendEvents: backendEvents{
 			state:             state,
@@ -138,6 +220,8 @@ func New(
 			connFactory:       connFactory,
 			log:               log,
 			maxHeightRange:    maxHeightRange,
+			nodeHealth:        options.ExecutionNodeHealth,
+			hedgeDelay:        options.HedgeDelay,
 		},
 		backendBlockHeaders: backendBlockHeaders{
 			headers: headers,
@@ -153,6 +237,17 @@ func New(
 			executionReceipts: executionReceipts,
 			connFactory:       connFactory,
 			log:               log,
+			scriptExecutor:    options.ScriptExecutor,
+			nodeHealth:        options.ExecutionNodeHealth,
+			hedgeDelay:        options.HedgeDelay,
+		},
+		backendAccountTransactions: backendAccountTransactions{
+			accountTransactions: options.AccountTransactions,
+		},
+		backendSimulation: backendSimulation{
+			state:          state,
+			headers:        headers,
+			scriptExecutor: options.ScriptExecutor,
 		},
 		backendExecutionResults: backendExecutionResults{
 			executionResults: executionResults,
@@ -267,6 +362,7 @@ func executionNodesForBlockID(
 	blockID flow.Identifier,
 	executionReceipts storage.ExecutionReceipts,
 	state protocol.State,
+	nodeHealth *ExecutionNodeHealth,
 	log zerolog.Logger) (flow.IdentityList, error) {
 
 	var executorIDs flow.IdentifierList
@@ -330,14 +426,15 @@ func executionNodesForBlockID(
 		return nil, fmt.Errorf("failed to retreive execution IDs for block ID %v: %w", blockID, err)
 	}
 
-	// randomly choose upto maxExecutionNodesCnt identities
-	executionIdentitiesRandom := subsetENs.Sample(maxExecutionNodesCnt)
+	// choose upto maxExecutionNodesCnt identities, healthiest first if node health is tracked
+	// and randomly otherwise
+	executionIdentities := nodeHealth.Select(subsetENs, maxExecutionNodesCnt)
 
-	if len(executionIdentitiesRandom) == 0 {
+	if len(executionIdentities) == 0 {
 		return nil, fmt.Errorf("no matching execution node found for block ID %v", blockID)
 	}
 
-	return executionIdentitiesRandom, nil
+	return executionIdentities, nil
 }
 
 // findAllExecutionNodes find all the execution nodes ids from the execution receipts that have been received for the
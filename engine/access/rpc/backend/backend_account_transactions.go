@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+const (
+	// DefaultAccountTransactionsPageSize is the number of transactions returned per page if the
+	// request does not specify a limit
+	DefaultAccountTransactionsPageSize = 50
+
+	// MaxAccountTransactionsPageSize is the maximum number of transactions returned per page
+	MaxAccountTransactionsPageSize = 500
+)
+
+type backendAccountTransactions struct {
+	// accountTransactions is an optional index of the transactions each account was involved in.
+	// When not set, account transaction requests are not supported.
+	accountTransactions storage.AccountTransactions
+}
+
+// GetAccountTransactions returns a page of the transactions the account was involved in, ordered
+// from the most recent to the oldest. If cursor is not nil, the page starts after the cursor.
+// A limit of 0 uses DefaultAccountTransactionsPageSize.
+//
+// Expected errors:
+// - codes.Unimplemented if the account transaction index is not enabled
+// - codes.InvalidArgument if the limit exceeds MaxAccountTransactionsPageSize
+func (b *backendAccountTransactions) GetAccountTransactions(
+	_ context.Context,
+	address flow.Address,
+	limit uint32,
+	cursor *flow.AccountTransactionCursor,
+) (*access.AccountTransactionsPage, error) {
+	if b.accountTransactions == nil {
+		return nil, status.Errorf(codes.Unimplemented, "account transaction index is not enabled")
+	}
+
+	if limit == 0 {
+		limit = DefaultAccountTransactionsPageSize
+	}
+	if limit > MaxAccountTransactionsPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must not exceed %d", MaxAccountTransactionsPageSize)
+	}
+
+	// request one more entry than the limit to find out if there is a next page
+	entries, err := b.accountTransactions.ByAddress(address, uint(limit)+1, cursor)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get transactions for account %v: %v", address, err)
+	}
+
+	page := &access.AccountTransactionsPage{
+		Transactions: entries,
+	}
+	if len(entries) > int(limit) {
+		page.Transactions = entries[:limit]
+		next := page.Transactions[limit-1].Cursor()
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/model/flow"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestGetAccountTransactions(t *testing.T) {
+	address := unittest.RandomAddressFixture()
+
+	entries := make([]flow.AccountTransaction, 3)
+	for i := range entries {
+		entries[i] = flow.AccountTransaction{
+			Address:          address,
+			BlockHeight:      uint64(100 - i),
+			TransactionID:    unittest.IdentifierFixture(),
+			TransactionIndex: 0,
+			Roles:            []flow.TransactionRole{flow.TransactionRolePayer},
+		}
+	}
+
+	t.Run("index not enabled", func(t *testing.T) {
+		backend := backendAccountTransactions{}
+
+		_, err := backend.GetAccountTransactions(context.Background(), address, 0, nil)
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("limit too large", func(t *testing.T) {
+		backend := backendAccountTransactions{
+			accountTransactions: storagemock.NewAccountTransactions(t),
+		}
+
+		_, err := backend.GetAccountTransactions(context.Background(), address, MaxAccountTransactionsPageSize+1, nil)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("page with next cursor", func(t *testing.T) {
+		index := storagemock.NewAccountTransactions(t)
+		backend := backendAccountTransactions{
+			accountTransactions: index,
+		}
+
+		index.On("ByAddress", address, uint(3), (*flow.AccountTransactionCursor)(nil)).Return(entries, nil).Once()
+
+		page, err := backend.GetAccountTransactions(context.Background(), address, 2, nil)
+		require.NoError(t, err)
+		assert.Equal(t, entries[:2], page.Transactions)
+		require.NotNil(t, page.NextCursor)
+		assert.Equal(t, entries[1].Cursor(), *page.NextCursor)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		index := storagemock.NewAccountTransactions(t)
+		backend := backendAccountTransactions{
+			accountTransactions: index,
+		}
+
+		cursor := entries[1].Cursor()
+		index.On("ByAddress", address, uint(DefaultAccountTransactionsPageSize+1), &cursor).Return(entries[2:], nil).Once()
+
+		page, err := backend.GetAccountTransactions(context.Background(), address, 0, &cursor)
+		require.NoError(t, err)
+		assert.Equal(t, entries[2:], page.Transactions)
+		assert.Nil(t, page.NextCursor)
+	})
+}
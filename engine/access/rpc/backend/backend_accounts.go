@@ -2,6 +2,7 @@ package backend
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
@@ -13,6 +14,7 @@ import (
 	"github.com/onflow/flow-go/engine/common/rpc"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/execution"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
 )
@@ -23,6 +25,18 @@ type backendAccounts struct {
 	executionReceipts storage.ExecutionReceipts
 	connFactory       ConnectionFactory
 	log               zerolog.Logger
+
+	// scriptExecutor is an optional executor used to read accounts from locally indexed state.
+	// When set, execution nodes are only queried if the state is not available locally.
+	scriptExecutor execution.ScriptExecutor
+
+	// nodeHealth optionally tracks the health of execution nodes, to send requests to the
+	// healthiest nodes first. When not set, execution nodes are chosen randomly.
+	nodeHealth *ExecutionNodeHealth
+
+	// hedgeDelay is the delay after which a request that has not completed is also sent to the
+	// next execution node. A delay of 0 disables hedged requests.
+	hedgeDelay time.Duration
 }
 
 func (b *backendAccounts) GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error) {
@@ -37,12 +51,9 @@ func (b *backendAccounts) GetAccountAtLatestBlock(ctx context.Context, address f
 		return nil, status.Errorf(codes.Internal, "failed to get latest sealed header: %v", err)
 	}
 
-	// get the block id of the latest sealed header
-	latestBlockID := latestHeader.ID()
-
-	account, err := b.getAccountAtBlockID(ctx, address, latestBlockID)
+	account, err := b.getAccountAtBlock(ctx, address, latestHeader)
 	if err != nil {
-		b.log.Error().Err(err).Msgf("failed to get account at blockID: %v", latestBlockID)
+		b.log.Error().Err(err).Msgf("failed to get account at blockID: %v", latestHeader.ID())
 		return nil, err
 	}
 
@@ -61,10 +72,7 @@ func (b *backendAccounts) GetAccountAtBlockHeight(
 		return nil, err
 	}
 
-	// get block ID of the header at the given height
-	blockID := header.ID()
-
-	account, err := b.getAccountAtBlockID(ctx, address, blockID)
+	account, err := b.getAccountAtBlock(ctx, address, header)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +80,43 @@ func (b *backendAccounts) GetAccountAtBlockHeight(
 	return account, nil
 }
 
+// getAccountAtBlock returns the account at the given block, reading it from the local script
+// executor if the state is available locally, and falling back to the execution nodes otherwise.
+func (b *backendAccounts) getAccountAtBlock(
+	ctx context.Context,
+	address flow.Address,
+	header *flow.Header,
+) (*flow.Account, error) {
+	blockID := header.ID()
+
+	if b.scriptExecutor == nil {
+		return b.getAccountAtBlockID(ctx, address, blockID)
+	}
+
+	account, err := b.scriptExecutor.GetAccountAtBlockHeight(ctx, address, header.Height)
+	if err == nil {
+		return account, nil
+	}
+
+	// only fall back to the execution nodes if the state is not available locally. other
+	// failures are deterministic, so the execution nodes would fail the same way.
+	if errors.Is(err, storage.ErrHeightNotIndexed) || errors.Is(err, storage.ErrNotFound) {
+		b.log.Debug().Err(err).
+			Hex("block_id", blockID[:]).
+			Uint64("height", header.Height).
+			Str("address", address.String()).
+			Msg("state not available locally, getting account from execution nodes")
+		return b.getAccountAtBlockID(ctx, address, blockID)
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, status.FromContextError(ctxErr).Err()
+	}
+
+	// execution nodes return all account lookup failures as internal errors
+	return nil, status.Errorf(codes.Internal, "failed to get account: %v", err)
+}
+
 func (b *backendAccounts) getAccountAtBlockID(
 	ctx context.Context,
 	address flow.Address,
@@ -83,7 +128,7 @@ func (b *backendAccounts) getAccountAtBlockID(
 		BlockId: blockID[:],
 	}
 
-	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.nodeHealth, b.log)
 	if err != nil {
 		return nil, getAccountError(err)
 	}
@@ -113,11 +158,12 @@ func getAccountError(err error) error {
 
 func (b *backendAccounts) getAccountFromAnyExeNode(ctx context.Context, execNodes flow.IdentityList, req execproto.GetAccountAtBlockIDRequest) (*execproto.GetAccountAtBlockIDResponse, error) {
 	var errors *multierror.Error // captures all error except
-	for _, execNode := range execNodes {
+	var account *execproto.GetAccountAtBlockIDResponse
+	start := time.Now()
+	HedgedCall(ctx, b.hedgeDelay, execNodes, func(ctx context.Context, execNode *flow.Identity) (*execproto.GetAccountAtBlockIDResponse, error) {
+		return b.tryGetAccount(ctx, execNode, req)
+	}, func(execNode *flow.Identity, resp *execproto.GetAccountAtBlockIDResponse, err error) bool {
 		// TODO: use the GRPC Client interceptor
-		start := time.Now()
-
-		resp, err := b.tryGetAccount(ctx, execNode, req)
 		duration := time.Since(start)
 		if err == nil {
 			// return if any execution node replied successfully
@@ -127,7 +173,8 @@ func (b *backendAccounts) getAccountFromAnyExeNode(ctx context.Context, execNode
 				Hex("address", req.GetAddress()).
 				Int64("rtt_ms", duration.Milliseconds()).
 				Msg("Successfully got account info")
-			return resp, nil
+			account = resp
+			return true
 		}
 		b.log.Error().
 			Str("execution_node", execNode.String()).
@@ -137,7 +184,12 @@ func (b *backendAccounts) getAccountFromAnyExeNode(ctx context.Context, execNode
 			Err(err).
 			Msg("failed to execute GetAccount")
 		errors = multierror.Append(errors, err)
+		return false
+	})
+	if account != nil {
+		return account, nil
 	}
+
 	// if we made it till here means there was at least one error
 	errToReturn := errors.ErrorOrNil()
 
@@ -160,7 +212,14 @@ func (b *backendAccounts) tryGetAccount(ctx context.Context, execNode *flow.Iden
 	}
 	defer closer.Close()
 
+	allowed, probe := b.nodeHealth.Allow(execNode.NodeID)
+	if !allowed {
+		return nil, NewCircuitBreakerOpenError(execNode.Address)
+	}
+
+	start := time.Now()
 	resp, err := execRPCClient.GetAccountAtBlockID(ctx, &req)
+	b.nodeHealth.ReportResult(execNode.NodeID, time.Since(start), probe, err)
 	if err != nil {
 		if status.Code(err) == codes.Unavailable {
 			b.connFactory.InvalidateExecutionAPIClient(execNode.Address)
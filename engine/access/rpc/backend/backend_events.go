@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
@@ -12,6 +14,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/engine/common/rpc"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
@@ -19,6 +22,15 @@ import (
 	"github.com/onflow/flow-go/storage"
 )
 
+const (
+	// DefaultEventsPageSize is the number of events returned per page if the request does not
+	// specify a limit
+	DefaultEventsPageSize = 100
+
+	// MaxEventsPageSize is the maximum number of events returned per page
+	MaxEventsPageSize = 1000
+)
+
 type backendEvents struct {
 	headers           storage.Headers
 	executionReceipts storage.ExecutionReceipts
@@ -26,6 +38,14 @@ type backendEvents struct {
 	connFactory       ConnectionFactory
 	log               zerolog.Logger
 	maxHeightRange    uint
+
+	// nodeHealth optionally tracks the health of execution nodes, to send requests to the
+	// healthiest nodes first. When not set, execution nodes are chosen randomly.
+	nodeHealth *ExecutionNodeHealth
+
+	// hedgeDelay is the delay after which a request that has not completed is also sent to the
+	// next execution node. A delay of 0 disables hedged requests.
+	hedgeDelay time.Duration
 }
 
 // GetEventsForHeightRange retrieves events for all sealed blocks between the start block height and
@@ -78,6 +98,153 @@ func (b *backendEvents) GetEventsForHeightRange(
 	return b.getBlockEventsFromExecutionNode(ctx, blockHeaders, eventType)
 }
 
+// GetEventsForHeightRangePage retrieves a page of the events with the given type for the sealed
+// blocks between the start and end height (inclusive). Events are ordered by block height,
+// transaction index and event index, and a page contains at most limit events. A limit of 0 uses
+// DefaultEventsPageSize. If cursor is not nil, the page starts at the cursor's position, which must
+// be within the height range.
+//
+// Unlike GetEventsForHeightRange, the height range is not limited. Each page covers at most
+// maxHeightRange blocks, so a page may contain fewer events than the limit even if there are more
+// events in the range.
+//
+// Expected errors:
+// - codes.InvalidArgument if the range, limit or cursor are invalid
+// - codes.OutOfRange if the start of the page is above the last sealed height
+func (b *backendEvents) GetEventsForHeightRangePage(
+	ctx context.Context,
+	eventType string,
+	startHeight, endHeight uint64,
+	limit uint32,
+	cursor *access.EventsCursor,
+) (*access.EventsPage, error) {
+
+	if endHeight < startHeight {
+		return nil, status.Error(codes.InvalidArgument, "invalid start or end height")
+	}
+
+	if limit == 0 {
+		limit = DefaultEventsPageSize
+	}
+	if limit > MaxEventsPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must not exceed %d", MaxEventsPageSize)
+	}
+
+	if cursor != nil {
+		if cursor.BlockHeight < startHeight || cursor.BlockHeight > endHeight {
+			return nil, status.Errorf(codes.InvalidArgument, "cursor height %d is outside of the requested range", cursor.BlockHeight)
+		}
+		startHeight = cursor.BlockHeight
+	}
+
+	// get the latest sealed block header
+	head, err := b.state.Sealed().Head()
+	if err != nil {
+		// sealed block must be in the store, so return an Internal code even if we got NotFound
+		return nil, status.Errorf(codes.Internal, "failed to get events: %v", err)
+	}
+
+	// start height should not be beyond the last sealed height
+	if head.Height < startHeight {
+		return nil, status.Errorf(codes.OutOfRange,
+			"start height %d is greater than the last sealed block height %d", startHeight, head.Height)
+	}
+
+	// limit max height to last sealed block in the chain
+	if head.Height < endHeight {
+		endHeight = head.Height
+	}
+
+	// limit the number of blocks requested from the execution node for a single page
+	pageEndHeight := endHeight
+	if pageEndHeight-startHeight >= uint64(b.maxHeightRange) {
+		pageEndHeight = startHeight + uint64(b.maxHeightRange) - 1
+	}
+
+	blockHeaders := make([]*flow.Header, 0, pageEndHeight-startHeight+1)
+	for i := startHeight; i <= pageEndHeight; i++ {
+		header, err := b.headers.ByHeight(i)
+		if err != nil {
+			return nil, rpc.ConvertStorageError(fmt.Errorf("failed to get events: %w", err))
+		}
+
+		blockHeaders = append(blockHeaders, header)
+	}
+
+	blocksEvents, err := b.getBlockEventsFromExecutionNode(ctx, blockHeaders, eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &access.EventsPage{
+		BlockEvents: make([]flow.BlockEvents, 0, len(blocksEvents)),
+	}
+
+	count := uint32(0)
+	for _, blockEvents := range blocksEvents {
+		events := sortedEvents(blockEvents.Events)
+
+		// skip the events of the first block that precede the cursor
+		if cursor != nil && blockEvents.BlockHeight == cursor.BlockHeight {
+			events = eventsFrom(events, cursor.TransactionIndex, cursor.EventIndex)
+		}
+
+		remaining := limit - count
+		if uint32(len(events)) > remaining {
+			next := events[remaining]
+			page.NextCursor = &access.EventsCursor{
+				BlockHeight:      blockEvents.BlockHeight,
+				TransactionIndex: next.TransactionIndex,
+				EventIndex:       next.EventIndex,
+			}
+
+			// the block is only included if some of its events fit in the page, otherwise the next
+			// page starts with it
+			if remaining > 0 {
+				blockEvents.Events = events[:remaining]
+				page.BlockEvents = append(page.BlockEvents, blockEvents)
+			}
+			return page, nil
+		}
+
+		blockEvents.Events = events
+		page.BlockEvents = append(page.BlockEvents, blockEvents)
+		count += uint32(len(events))
+	}
+
+	if pageEndHeight < endHeight {
+		page.NextCursor = &access.EventsCursor{
+			BlockHeight: pageEndHeight + 1,
+		}
+	}
+
+	return page, nil
+}
+
+// sortedEvents returns the events ordered by transaction index and event index.
+func sortedEvents(events []flow.Event) []flow.Event {
+	sorted := make([]flow.Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].TransactionIndex != sorted[j].TransactionIndex {
+			return sorted[i].TransactionIndex < sorted[j].TransactionIndex
+		}
+		return sorted[i].EventIndex < sorted[j].EventIndex
+	})
+	return sorted
+}
+
+// eventsFrom returns the sorted events starting at the given transaction and event index.
+func eventsFrom(events []flow.Event, txIndex uint32, eventIndex uint32) []flow.Event {
+	i := sort.Search(len(events), func(i int) bool {
+		if events[i].TransactionIndex != txIndex {
+			return events[i].TransactionIndex > txIndex
+		}
+		return events[i].EventIndex >= eventIndex
+	})
+	return events[i:]
+}
+
 // GetEventsForBlockIDs retrieves events for all the specified block IDs that have the given type
 func (b *backendEvents) GetEventsForBlockIDs(
 	ctx context.Context,
@@ -128,7 +295,7 @@ func (b *backendEvents) getBlockEventsFromExecutionNode(
 	// choose the last block ID to find the list of execution nodes
 	lastBlockID := blockIDs[len(blockIDs)-1]
 
-	execNodes, err := executionNodesForBlockID(ctx, lastBlockID, b.executionReceipts, b.state, b.log)
+	execNodes, err := executionNodesForBlockID(ctx, lastBlockID, b.executionReceipts, b.state, b.nodeHealth, b.log)
 	if err != nil {
 		b.log.Error().Err(err).Msg("failed to retrieve events from execution node")
 		return nil, status.Errorf(codes.Internal, "failed to retrieve events from execution node: %v", err)
@@ -195,13 +362,21 @@ func (b *backendEvents) getEventsFromAnyExeNode(ctx context.Context,
 	execNodes flow.IdentityList,
 	req execproto.GetEventsForBlockIDsRequest) (*execproto.GetEventsForBlockIDsResponse, *flow.Identity, error) {
 	var errors *multierror.Error
+	var events *execproto.GetEventsForBlockIDsResponse
+	var eventsNode *flow.Identity
 	// try to get events from one of the execution nodes
-	for _, execNode := range execNodes {
-		resp, err := b.tryGetEvents(ctx, execNode, req)
+	HedgedCall(ctx, b.hedgeDelay, execNodes, func(ctx context.Context, execNode *flow.Identity) (*execproto.GetEventsForBlockIDsResponse, error) {
+		return b.tryGetEvents(ctx, execNode, req)
+	}, func(execNode *flow.Identity, resp *execproto.GetEventsForBlockIDsResponse, err error) bool {
 		if err == nil {
-			return resp, execNode, nil
+			events, eventsNode = resp, execNode
+			return true
 		}
 		errors = multierror.Append(errors, err)
+		return false
+	})
+	if events != nil {
+		return events, eventsNode, nil
 	}
 	return nil, nil, errors.ErrorOrNil()
 }
@@ -215,7 +390,14 @@ func (b *backendEvents) tryGetEvents(ctx context.Context,
 	}
 	defer closer.Close()
 
+	allowed, probe := b.nodeHealth.Allow(execNode.NodeID)
+	if !allowed {
+		return nil, NewCircuitBreakerOpenError(execNode.Address)
+	}
+
+	start := time.Now()
 	resp, err := execRPCClient.GetEventsForBlockIDs(ctx, &req)
+	b.nodeHealth.ReportResult(execNode.NodeID, time.Since(start), probe, err)
 	if err != nil {
 		if status.Code(err) == codes.Unavailable {
 			b.connFactory.InvalidateExecutionAPIClient(execNode.Address)
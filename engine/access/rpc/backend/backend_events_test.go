@@ -0,0 +1,205 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	accessmock "github.com/onflow/flow-go/engine/access/mock"
+	backendmock "github.com/onflow/flow-go/engine/access/rpc/backend/mock"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/model/flow"
+	protocol "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/storage"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestGetEventsForHeightRangePage(t *testing.T) {
+	const startHeight uint64 = 10
+	const endHeight uint64 = 14
+	const sealedHeight uint64 = 13
+
+	ctx := context.Background()
+	eventType := string(flow.EventAccountCreated)
+
+	// blocks 10 to 13 are sealed. Each block has events from 2 transactions, except for block 11
+	// which has no events.
+	headersByHeight := make(map[uint64]*flow.Header)
+	eventsByBlockID := make(map[flow.Identifier][]flow.Event)
+	for height := startHeight; height <= sealedHeight; height++ {
+		header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(height))
+		headersByHeight[height] = header
+
+		if height == startHeight+1 {
+			continue
+		}
+		// events are returned by the execution node out of order
+		eventsByBlockID[header.ID()] = []flow.Event{
+			{Type: flow.EventAccountCreated, TransactionIndex: 1, EventIndex: 2},
+			{Type: flow.EventAccountCreated, TransactionIndex: 0, EventIndex: 0},
+			{Type: flow.EventAccountCreated, TransactionIndex: 1, EventIndex: 1},
+		}
+	}
+
+	headers := storagemock.NewHeaders(t)
+	headers.On("ByHeight", mock.Anything).Return(
+		func(height uint64) *flow.Header {
+			return headersByHeight[height]
+		},
+		func(height uint64) error {
+			if _, ok := headersByHeight[height]; !ok {
+				return storage.ErrNotFound
+			}
+			return nil
+		}).Maybe()
+
+	// every block was executed by the same execution nodes
+	executionNodes := flow.IdentityList{
+		{NodeID: unittest.IdentifierFixture(), Role: flow.RoleExecution, Address: "en-1:9000"},
+		{NodeID: unittest.IdentifierFixture(), Role: flow.RoleExecution, Address: "en-2:9000"},
+	}
+	result := unittest.ExecutionResultFixture()
+	receipts := storagemock.NewExecutionReceipts(t)
+	receipts.On("ByBlockID", mock.Anything).Return(flow.ExecutionReceiptList{
+		{ExecutorID: executionNodes[0].NodeID, ExecutionResult: *result},
+		{ExecutorID: executionNodes[1].NodeID, ExecutionResult: *result},
+	}, nil).Maybe()
+
+	snapshot := protocol.NewSnapshot(t)
+	snapshot.On("Head").Return(headersByHeight[sealedHeight], nil).Maybe()
+	snapshot.On("Identities", mock.Anything).Return(executionNodes, nil).Maybe()
+
+	params := protocol.NewParams(t)
+	params.On("Root").Return(unittest.BlockHeaderFixture(), nil).Maybe()
+
+	state := protocol.NewState(t)
+	state.On("Sealed").Return(snapshot).Maybe()
+	state.On("Final").Return(snapshot).Maybe()
+	state.On("Params").Return(params).Maybe()
+
+	execClient := accessmock.NewExecutionAPIClient(t)
+	execClient.On("GetEventsForBlockIDs", mock.Anything, mock.Anything).Return(
+		func(_ context.Context, req *execproto.GetEventsForBlockIDsRequest, _ ...grpc.CallOption) *execproto.GetEventsForBlockIDsResponse {
+			results := make([]*execproto.GetEventsForBlockIDsResponse_Result, len(req.BlockIds))
+			for i, id := range req.BlockIds {
+				blockID := convert.MessageToIdentifier(id)
+				var height uint64
+				for h, header := range headersByHeight {
+					if header.ID() == blockID {
+						height = h
+					}
+				}
+				results[i] = &execproto.GetEventsForBlockIDsResponse_Result{
+					BlockId:     id,
+					BlockHeight: height,
+					Events:      convert.EventsToMessages(eventsByBlockID[blockID]),
+				}
+			}
+			return &execproto.GetEventsForBlockIDsResponse{Results: results}
+		},
+		nil,
+	).Maybe()
+
+	connFactory := backendmock.NewConnectionFactory(t)
+	connFactory.On("GetExecutionAPIClient", mock.Anything).Return(execClient, &mockCloser{}, nil).Maybe()
+
+	backend := backendEvents{
+		headers:           headers,
+		executionReceipts: receipts,
+		state:             state,
+		connFactory:       connFactory,
+		log:               zerolog.Nop(),
+		maxHeightRange:    2,
+	}
+
+	// positions returns the position of each event in the page
+	positions := func(page *access.EventsPage) []access.EventsCursor {
+		var result []access.EventsCursor
+		for _, blockEvents := range page.BlockEvents {
+			for _, event := range blockEvents.Events {
+				result = append(result, access.EventsCursor{
+					BlockHeight:      blockEvents.BlockHeight,
+					TransactionIndex: event.TransactionIndex,
+					EventIndex:       event.EventIndex,
+				})
+			}
+		}
+		return result
+	}
+
+	t.Run("walks the range in order", func(t *testing.T) {
+		var actual []access.EventsCursor
+		var cursor *access.EventsCursor
+		pages := 0
+		for {
+			page, err := backend.GetEventsForHeightRangePage(ctx, eventType, startHeight, endHeight, 2, cursor)
+			require.NoError(t, err)
+			pages++
+
+			actual = append(actual, positions(page)...)
+			if page.NextCursor == nil {
+				break
+			}
+			cursor = page.NextCursor
+			require.Less(t, pages, 10, "pagination did not terminate")
+		}
+
+		// the range is limited to the sealed height, and events are ordered by transaction and
+		// event index within each block
+		var expected []access.EventsCursor
+		for _, height := range []uint64{10, 12, 13} {
+			expected = append(expected,
+				access.EventsCursor{BlockHeight: height, TransactionIndex: 0, EventIndex: 0},
+				access.EventsCursor{BlockHeight: height, TransactionIndex: 1, EventIndex: 1},
+				access.EventsCursor{BlockHeight: height, TransactionIndex: 1, EventIndex: 2},
+			)
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("page ends within a block", func(t *testing.T) {
+		page, err := backend.GetEventsForHeightRangePage(ctx, eventType, startHeight, endHeight, 2, nil)
+		require.NoError(t, err)
+
+		require.Len(t, page.BlockEvents, 1)
+		assert.Len(t, page.BlockEvents[0].Events, 2)
+		assert.Equal(t, &access.EventsCursor{BlockHeight: 10, TransactionIndex: 1, EventIndex: 2}, page.NextCursor)
+	})
+
+	t.Run("page ends at the block range limit", func(t *testing.T) {
+		cursor := &access.EventsCursor{BlockHeight: 10, TransactionIndex: 1, EventIndex: 2}
+		page, err := backend.GetEventsForHeightRangePage(ctx, eventType, startHeight, endHeight, 100, cursor)
+		require.NoError(t, err)
+
+		// the page covers blocks 10 and 11, even though block 11 has no events
+		require.Len(t, page.BlockEvents, 2)
+		assert.Len(t, page.BlockEvents[0].Events, 1)
+		assert.Empty(t, page.BlockEvents[1].Events)
+		assert.Equal(t, &access.EventsCursor{BlockHeight: 12}, page.NextCursor)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := backend.GetEventsForHeightRangePage(ctx, eventType, endHeight, startHeight, 0, nil)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = backend.GetEventsForHeightRangePage(ctx, eventType, startHeight, endHeight, MaxEventsPageSize+1, nil)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		cursor := &access.EventsCursor{BlockHeight: endHeight + 1}
+		_, err = backend.GetEventsForHeightRangePage(ctx, eventType, startHeight, endHeight, 0, cursor)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = backend.GetEventsForHeightRangePage(ctx, eventType, sealedHeight+1, endHeight, 0, nil)
+		assert.Equal(t, codes.OutOfRange, status.Code(err))
+	})
+}
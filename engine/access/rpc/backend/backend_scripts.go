@@ -3,6 +3,8 @@ package backend
 import (
 	"context"
 	"crypto/md5" //nolint:gosec
+	"errors"
+	"fmt"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
@@ -16,6 +18,7 @@ import (
 	"github.com/onflow/flow-go/engine/common/rpc"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/execution"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
 )
@@ -31,6 +34,21 @@ type backendScripts struct {
 	log               zerolog.Logger
 	metrics           module.BackendScriptsMetrics
 	loggedScripts     *lru.Cache
+
+	// scriptExecutor is an optional executor used to run scripts against locally indexed state.
+	// When set, execution nodes are only queried if the state is not available locally.
+	scriptExecutor execution.ScriptExecutor
+
+	// scriptCache is an optional cache of the results of scripts executed against sealed blocks.
+	scriptCache *ScriptCache
+
+	// nodeHealth optionally tracks the health of execution nodes, to send requests to the
+	// healthiest nodes first. When not set, execution nodes are chosen randomly.
+	nodeHealth *ExecutionNodeHealth
+
+	// hedgeDelay is the delay after which a request that has not completed is also sent to the
+	// next execution node. A delay of 0 disables hedged requests.
+	hedgeDelay time.Duration
 }
 
 func (b *backendScripts) ExecuteScriptAtLatestBlock(
@@ -45,11 +63,8 @@ func (b *backendScripts) ExecuteScriptAtLatestBlock(
 		return nil, status.Errorf(codes.Internal, "failed to get latest sealed header: %v", err)
 	}
 
-	// get the block id of the latest sealed header
-	latestBlockID := latestHeader.ID()
-
-	// execute script on the execution node at that block id
-	return b.executeScriptOnExecutionNode(ctx, latestBlockID, script, arguments)
+	// execute script at the latest sealed block
+	return b.executeCachedScript(ctx, latestHeader, true, script, arguments)
 }
 
 func (b *backendScripts) ExecuteScriptAtBlockID(
@@ -58,8 +73,15 @@ func (b *backendScripts) ExecuteScriptAtBlockID(
 	script []byte,
 	arguments [][]byte,
 ) ([]byte, error) {
-	// execute script on the execution node at that block id
-	return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
+	// get header for the given block id
+	header, err := b.headers.ByBlockID(blockID)
+	if err != nil {
+		// the block is not known locally, so the script can neither be executed locally nor cached.
+		// it is sent to the execution nodes as is, so the errors returned to clients are unchanged.
+		return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
+	}
+
+	return b.executeCachedScript(ctx, header, false, script, arguments)
 }
 
 func (b *backendScripts) ExecuteScriptAtBlockHeight(
@@ -75,10 +97,111 @@ func (b *backendScripts) ExecuteScriptAtBlockHeight(
 		return nil, err
 	}
 
+	return b.executeCachedScript(ctx, header, false, script, arguments)
+}
+
+// executeCachedScript executes the script at the given block, serving the result from the script
+// result cache if it is enabled and the block is sealed. sealed indicates that the caller already
+// knows the block to be sealed.
+func (b *backendScripts) executeCachedScript(
+	ctx context.Context,
+	header *flow.Header,
+	sealed bool,
+	script []byte,
+	arguments [][]byte,
+) ([]byte, error) {
+	if b.scriptCache == nil {
+		return b.executeScript(ctx, header, script, arguments)
+	}
+
+	blockID := header.ID()
+	if !sealed {
+		var err error
+		sealed, err = b.isSealed(header)
+		if err != nil {
+			// the cache is an optimization, so the script is still executed
+			b.log.Warn().Err(err).
+				Hex("block_id", blockID[:]).
+				Msg("could not check if block is sealed, skipping script result cache")
+		}
+	}
+
+	// results at unsealed blocks may still change, so they are never cached
+	if !sealed {
+		return b.executeScript(ctx, header, script, arguments)
+	}
+
+	if result, ok := b.scriptCache.Get(blockID, script, arguments); ok {
+		return result, nil
+	}
+
+	result, err := b.executeScript(ctx, header, script, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	b.scriptCache.Add(blockID, script, arguments, result)
+	return result, nil
+}
+
+// isSealed returns true if the block is finalized and at or below the latest sealed height.
+// No errors are expected during normal operation.
+func (b *backendScripts) isSealed(header *flow.Header) (bool, error) {
+	sealedHead, err := b.state.Sealed().Head()
+	if err != nil {
+		return false, fmt.Errorf("could not get latest sealed header: %w", err)
+	}
+	if header.Height > sealedHead.Height {
+		return false, nil
+	}
+
+	// blocks on forks below the sealed height are never sealed
+	finalized, err := b.headers.ByHeight(header.Height)
+	if err != nil {
+		return false, fmt.Errorf("could not get finalized header at height %d: %w", header.Height, err)
+	}
+
+	return finalized.ID() == header.ID(), nil
+}
+
+// executeScript executes the script at the given block, using the local script executor if the
+// state is available locally, and falling back to the execution nodes otherwise.
+func (b *backendScripts) executeScript(
+	ctx context.Context,
+	header *flow.Header,
+	script []byte,
+	arguments [][]byte,
+) ([]byte, error) {
 	blockID := header.ID()
 
-	// execute script on the execution node at that block id
-	return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
+	if b.scriptExecutor == nil {
+		return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
+	}
+
+	execStartTime := time.Now()
+	result, err := b.scriptExecutor.ExecuteAtBlockHeight(ctx, script, arguments, header.Height)
+	if err == nil {
+		b.metrics.ScriptExecuted(time.Since(execStartTime), len(script))
+		return result, nil
+	}
+
+	// only fall back to the execution nodes if the state is not available locally. script
+	// failures are deterministic, so executing the script again on the execution nodes would
+	// fail the same way.
+	if errors.Is(err, storage.ErrHeightNotIndexed) || errors.Is(err, storage.ErrNotFound) {
+		b.log.Debug().Err(err).
+			Hex("block_id", blockID[:]).
+			Uint64("height", header.Height).
+			Msg("state not available locally, executing script on execution nodes")
+		return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, status.FromContextError(ctxErr).Err()
+	}
+
+	// execution nodes return all script execution failures as invalid arguments
+	return nil, status.Errorf(codes.InvalidArgument, "failed to execute script: %v", err)
 }
 
 // executeScriptOnExecutionNode forwards the request to the execution node using the execution node
@@ -97,7 +220,7 @@ func (b *backendScripts) executeScriptOnExecutionNode(
 	}
 
 	// find few execution nodes which have executed the block earlier and provided an execution receipt for it
-	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.nodeHealth, b.log)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to find execution nodes at blockId %v: %v", blockID.String(), err)
 	}
@@ -108,10 +231,20 @@ func (b *backendScripts) executeScriptOnExecutionNode(
 
 	// try each of the execution nodes found
 	var errors *multierror.Error
+	var result []byte
+	var scriptErr error
+	executed := false
+	// hedged attempts start at different times, so each attempt records its own execution time
+	type attempt struct {
+		value    []byte
+		duration time.Duration
+	}
 	// try to execute the script on one of the execution nodes
-	for _, execNode := range execNodes {
-		execStartTime := time.Now() // record start time
-		result, err := b.tryExecuteScript(ctx, execNode, execReq)
+	HedgedCall(ctx, b.hedgeDelay, execNodes, func(ctx context.Context, execNode *flow.Identity) (attempt, error) {
+		start := time.Now()
+		value, err := b.tryExecuteScript(ctx, execNode, execReq)
+		return attempt{value: value, duration: time.Since(start)}, err
+	}, func(execNode *flow.Identity, res attempt, err error) bool {
 		if err == nil {
 			if b.log.GetLevel() == zerolog.DebugLevel {
 				executionTime := time.Now()
@@ -128,11 +261,13 @@ func (b *backendScripts) executeScriptOnExecutionNode(
 
 			// log execution time
 			b.metrics.ScriptExecuted(
-				time.Since(execStartTime),
+				res.duration,
 				len(script),
 			)
 
-			return result, nil
+			result = res.value
+			executed = true
+			return true
 		}
 		// return if it's just a script failure as opposed to an EN failure and skip trying other ENs
 		if status.Code(err) == codes.InvalidArgument {
@@ -142,10 +277,17 @@ func (b *backendScripts) executeScriptOnExecutionNode(
 				Hex("script_hash", insecureScriptHash[:]).
 				Str("script", string(script)).
 				Msg("script failed to execute on the execution node")
-			return nil, err
+			scriptErr = err
+			executed = true
+			return true
 		}
 		errors = multierror.Append(errors, err)
+		return false
+	})
+	if executed {
+		return result, scriptErr
 	}
+
 	errToReturn := errors.ErrorOrNil()
 	if errToReturn != nil {
 		b.log.Error().Err(err).Msg("script execution failed for execution node internal reasons")
@@ -172,7 +314,16 @@ func (b *backendScripts) tryExecuteScript(ctx context.Context, execNode *flow.Id
 	}
 	defer closer.Close()
 
+	// requests rejected by the circuit breaker never reach the node, so they are neither reported
+	// as node failures nor invalidate the connection
+	allowed, probe := b.nodeHealth.Allow(execNode.NodeID)
+	if !allowed {
+		return nil, NewCircuitBreakerOpenError(execNode.Address)
+	}
+
+	start := time.Now()
 	execResp, err := execRPCClient.ExecuteScriptAtBlockID(ctx, &req)
+	b.nodeHealth.ReportResult(execNode.NodeID, time.Since(start), probe, err)
 	if err != nil {
 		if status.Code(err) == codes.Unavailable {
 			b.connFactory.InvalidateExecutionAPIClient(execNode.Address)
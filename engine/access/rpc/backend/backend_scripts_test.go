@@ -0,0 +1,136 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	accessmock "github.com/onflow/flow-go/engine/access/mock"
+	connectionmock "github.com/onflow/flow-go/engine/access/rpc/backend/mock"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	protocol "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/storage"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// failingScriptExecutor fails all local script executions with the configured error
+type failingScriptExecutor struct {
+	countingScriptExecutor
+	err error
+}
+
+func (e *failingScriptExecutor) ExecuteAtBlockHeight(_ context.Context, _ []byte, _ [][]byte, _ uint64) ([]byte, error) {
+	e.executed++
+	return nil, e.err
+}
+
+func TestExecuteScriptLocally(t *testing.T) {
+	header := unittest.BlockHeaderFixture()
+	script := []byte("pub fun main() { panic(\"failed\") }")
+
+	headers := storagemock.NewHeaders(t)
+
+	// the execution nodes are only queried if the state is not available locally. as no
+	// execution nodes are known, falling back to them fails with an internal error.
+	params := protocol.NewParams(t)
+	params.On("Root").Return(nil, fmt.Errorf("no execution nodes")).Maybe()
+	state := protocol.NewState(t)
+	state.On("Params").Return(params).Maybe()
+
+	newBackend := func(executor *failingScriptExecutor) *backendScripts {
+		return &backendScripts{
+			headers:        headers,
+			state:          state,
+			log:            zerolog.Nop(),
+			metrics:        metrics.NewNoopCollector(),
+			scriptExecutor: executor,
+		}
+	}
+
+	t.Run("script failures are not retried on execution nodes", func(t *testing.T) {
+		headers.On("ByBlockID", header.ID()).Return(header, nil).Once()
+		executor := &failingScriptExecutor{err: fmt.Errorf("cadence runtime error")}
+
+		_, err := newBackend(executor).ExecuteScriptAtBlockID(context.Background(), header.ID(), script, nil)
+		require.Error(t, err)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, 1, executor.executed)
+	})
+
+	t.Run("state not indexed falls back to execution nodes", func(t *testing.T) {
+		headers.On("ByBlockID", header.ID()).Return(header, nil).Once()
+		executor := &failingScriptExecutor{err: storage.ErrHeightNotIndexed}
+
+		_, err := newBackend(executor).ExecuteScriptAtBlockID(context.Background(), header.ID(), script, nil)
+		require.Error(t, err)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Equal(t, 1, executor.executed)
+	})
+
+	t.Run("unknown blocks are sent to execution nodes", func(t *testing.T) {
+		headers.On("ByBlockID", header.ID()).Return(nil, storage.ErrNotFound).Once()
+		executor := &failingScriptExecutor{}
+
+		// the error code is the same as when local execution is disabled
+		_, err := newBackend(executor).ExecuteScriptAtBlockID(context.Background(), header.ID(), script, nil)
+		require.Error(t, err)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Equal(t, 0, executor.executed)
+	})
+}
+
+func TestTryExecuteScriptCircuitBreaker(t *testing.T) {
+	execNode := &flow.Identity{
+		NodeID:  unittest.IdentifierFixture(),
+		Address: "exec-1:9000",
+		Role:    flow.RoleExecution,
+	}
+
+	execClient := accessmock.NewExecutionAPIClient(t)
+	connFactory := connectionmock.NewConnectionFactory(t)
+	connFactory.On("GetExecutionAPIClient", execNode.Address).Return(execClient, &noopCloser{}, nil)
+
+	nodeHealth := NewExecutionNodeHealth(ExecutionNodeHealthConfig{
+		FailureThreshold:      1,
+		Backoff:               time.Minute,
+		MaxBackoff:            time.Minute,
+		CircuitBreakerEnabled: true,
+	}, metrics.NewNoopCollector(), zerolog.Nop())
+
+	backend := &backendScripts{
+		connFactory: connFactory,
+		nodeHealth:  nodeHealth,
+		log:         zerolog.Nop(),
+	}
+
+	// the failure of the node opens the circuit, and invalidates the connection
+	execClient.On("ExecuteScriptAtBlockID", mock.Anything, mock.Anything).
+		Return(nil, status.Error(codes.Unavailable, "unavailable")).Once()
+	connFactory.On("InvalidateExecutionAPIClient", execNode.Address).Once()
+
+	_, err := backend.tryExecuteScript(context.Background(), execNode, execproto.ExecuteScriptAtBlockIDRequest{})
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrCircuitBreakerOpen))
+
+	// requests rejected by the open circuit neither reach the node nor invalidate the connection
+	_, err = backend.tryExecuteScript(context.Background(), execNode, execproto.ExecuteScriptAtBlockIDRequest{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrCircuitBreakerOpen))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	status := nodeHealth.Status()
+	require.Len(t, status, 1)
+	assert.Equal(t, uint(1), status[0].ConsecutiveFailures)
+}
@@ -0,0 +1,91 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/engine/common/rpc"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/execution"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/storage"
+)
+
+type backendSimulation struct {
+	state   protocol.State
+	headers storage.Headers
+
+	// scriptExecutor is an optional executor used to simulate transactions against locally indexed
+	// state. When not set, transaction simulation is not supported, since execution nodes do not
+	// expose transaction simulation over their API.
+	scriptExecutor execution.ScriptExecutor
+}
+
+// SimulateTransaction executes the transaction against the execution state as of the given block,
+// without submitting it, and returns the events, error, resource usage and register updates of
+// the transaction. The signature and sequence number checks are skipped if skipChecks is true.
+//
+// Expected errors:
+// - codes.Unimplemented if local execution state is not enabled
+// - codes.NotFound if the block is unknown
+// - codes.OutOfRange if the execution state at the block is not available locally
+func (b *backendSimulation) SimulateTransaction(
+	ctx context.Context,
+	tx *flow.TransactionBody,
+	blockID flow.Identifier,
+	skipChecks bool,
+) (*flow.TransactionSimulationResult, error) {
+	if b.scriptExecutor == nil {
+		return nil, status.Errorf(codes.Unimplemented, "transaction simulation requires local execution state, which is not enabled")
+	}
+
+	header, err := b.headers.ByBlockID(blockID)
+	if err != nil {
+		return nil, rpc.ConvertStorageError(err)
+	}
+
+	result, err := b.scriptExecutor.SimulateTransactionAtBlockHeight(ctx, tx, header.Height, skipChecks)
+	if err != nil {
+		if errors.Is(err, storage.ErrHeightNotIndexed) {
+			return nil, status.Errorf(codes.OutOfRange, "execution state for block %v is not available: %v", blockID, err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to simulate transaction %v at block %v: %v", tx.ID(), blockID, err)
+	}
+
+	return result, nil
+}
+
+// EstimateTransactionFees executes the transaction against the execution state as of the latest
+// sealed block with the maximum gas limit, and returns the computation, memory and storage used
+// by the transaction, and the fees it would be charged.
+//
+// Expected errors:
+// - codes.Unimplemented if local execution state is not enabled
+// - codes.OutOfRange if the execution state at the latest sealed block is not available locally
+func (b *backendSimulation) EstimateTransactionFees(
+	ctx context.Context,
+	tx *flow.TransactionBody,
+) (*flow.TransactionFeesEstimate, error) {
+	if b.scriptExecutor == nil {
+		return nil, status.Errorf(codes.Unimplemented, "transaction fee estimation requires local execution state, which is not enabled")
+	}
+
+	// get the latest sealed header
+	header, err := b.state.Sealed().Head()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get latest sealed header: %v", err)
+	}
+
+	estimate, err := b.scriptExecutor.EstimateTransactionFeesAtBlockHeight(ctx, tx, header.Height)
+	if err != nil {
+		if errors.Is(err, storage.ErrHeightNotIndexed) {
+			return nil, status.Errorf(codes.OutOfRange, "execution state for latest sealed block %v is not available: %v", header.ID(), err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to estimate fees of transaction %v: %v", tx.ID(), err)
+	}
+
+	return estimate, nil
+}
@@ -0,0 +1,151 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/model/flow"
+	protocol "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/storage"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// simulatingScriptExecutor returns the configured result or error for transaction simulations
+// and fee estimations.
+type simulatingScriptExecutor struct {
+	countingScriptExecutor
+	result   *flow.TransactionSimulationResult
+	estimate *flow.TransactionFeesEstimate
+	err      error
+	height   uint64
+}
+
+func (e *simulatingScriptExecutor) SimulateTransactionAtBlockHeight(_ context.Context, _ *flow.TransactionBody, height uint64, _ bool) (*flow.TransactionSimulationResult, error) {
+	e.height = height
+	return e.result, e.err
+}
+
+func (e *simulatingScriptExecutor) EstimateTransactionFeesAtBlockHeight(_ context.Context, _ *flow.TransactionBody, height uint64) (*flow.TransactionFeesEstimate, error) {
+	e.height = height
+	return e.estimate, e.err
+}
+
+func TestSimulateTransaction(t *testing.T) {
+	header := unittest.BlockHeaderFixture()
+	blockID := header.ID()
+	tx := unittest.TransactionBodyFixture()
+
+	t.Run("local execution state not enabled", func(t *testing.T) {
+		backend := backendSimulation{}
+
+		_, err := backend.SimulateTransaction(context.Background(), &tx, blockID, true)
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("unknown block", func(t *testing.T) {
+		headers := storagemock.NewHeaders(t)
+		headers.On("ByBlockID", blockID).Return(nil, storage.ErrNotFound)
+
+		backend := backendSimulation{
+			headers:        headers,
+			scriptExecutor: &simulatingScriptExecutor{},
+		}
+
+		_, err := backend.SimulateTransaction(context.Background(), &tx, blockID, true)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("state not indexed", func(t *testing.T) {
+		headers := storagemock.NewHeaders(t)
+		headers.On("ByBlockID", blockID).Return(header, nil)
+
+		backend := backendSimulation{
+			headers:        headers,
+			scriptExecutor: &simulatingScriptExecutor{err: storage.ErrHeightNotIndexed},
+		}
+
+		_, err := backend.SimulateTransaction(context.Background(), &tx, blockID, true)
+		assert.Equal(t, codes.OutOfRange, status.Code(err))
+	})
+
+	t.Run("simulated at the block height", func(t *testing.T) {
+		headers := storagemock.NewHeaders(t)
+		headers.On("ByBlockID", blockID).Return(header, nil)
+
+		expected := &flow.TransactionSimulationResult{
+			TransactionID:   tx.ID(),
+			BlockID:         blockID,
+			ComputationUsed: 42,
+			WriteSet: flow.RegisterEntries{
+				{Key: flow.NewRegisterID("owner", "key"), Value: []byte{1}},
+			},
+		}
+		executor := &simulatingScriptExecutor{result: expected}
+		backend := backendSimulation{
+			headers:        headers,
+			scriptExecutor: executor,
+		}
+
+		result, err := backend.SimulateTransaction(context.Background(), &tx, blockID, true)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+		assert.Equal(t, header.Height, executor.height)
+	})
+}
+
+func TestEstimateTransactionFees(t *testing.T) {
+	header := unittest.BlockHeaderFixture()
+	tx := unittest.TransactionBodyFixture()
+
+	sealedState := func(t *testing.T) *protocol.State {
+		snapshot := protocol.NewSnapshot(t)
+		snapshot.On("Head").Return(header, nil)
+		state := protocol.NewState(t)
+		state.On("Sealed").Return(snapshot)
+		return state
+	}
+
+	t.Run("local execution state not enabled", func(t *testing.T) {
+		backend := backendSimulation{}
+
+		_, err := backend.EstimateTransactionFees(context.Background(), &tx)
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("state not indexed", func(t *testing.T) {
+		backend := backendSimulation{
+			state:          sealedState(t),
+			scriptExecutor: &simulatingScriptExecutor{err: storage.ErrHeightNotIndexed},
+		}
+
+		_, err := backend.EstimateTransactionFees(context.Background(), &tx)
+		assert.Equal(t, codes.OutOfRange, status.Code(err))
+	})
+
+	t.Run("estimated at the latest sealed block", func(t *testing.T) {
+		expected := &flow.TransactionFeesEstimate{
+			TransactionID:          tx.ID(),
+			BlockID:                header.ID(),
+			ComputationUsed:        42,
+			ComputationIntensities: map[uint]uint{1001: 42},
+			InclusionFee:           1000,
+			ExecutionFee:           42,
+		}
+		executor := &simulatingScriptExecutor{estimate: expected}
+		backend := backendSimulation{
+			state:          sealedState(t),
+			scriptExecutor: executor,
+		}
+
+		estimate, err := backend.EstimateTransactionFees(context.Background(), &tx)
+		require.NoError(t, err)
+		assert.Equal(t, expected, estimate)
+		assert.Equal(t, header.Height, executor.height)
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/common/rpc"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/fvm/blueprints"
@@ -41,6 +42,18 @@ type backendTransactions struct {
 
 	previousAccessNodes []accessproto.AccessAPIClient
 	log                 zerolog.Logger
+
+	// statusBroadcaster notifies transaction status subscriptions when new blocks are finalized
+	// or executed, which may change the status of their transaction
+	statusBroadcaster *engine.Broadcaster
+
+	// nodeHealth optionally tracks the health of execution nodes, to send requests to the
+	// healthiest nodes first. When not set, execution nodes are chosen randomly.
+	nodeHealth *ExecutionNodeHealth
+
+	// hedgeDelay is the delay after which a request that has not completed is also sent to the
+	// next execution node. A delay of 0 disables hedged requests.
+	hedgeDelay time.Duration
 }
 
 // SendTransaction forwards the transaction to the collection node
@@ -154,7 +167,8 @@ func (b *backendTransactions) sendTransactionToCollector(ctx context.Context,
 
 	err = b.grpcTxSend(ctx, collectionRPC, tx)
 	if err != nil {
-		if status.Code(err) == codes.Unavailable {
+		// requests rejected by the circuit breaker never reached the node, so the connection is kept
+		if status.Code(err) == codes.Unavailable && !errors.Is(err, ErrCircuitBreakerOpen) {
 			b.connFactory.InvalidateAccessAPIClient(collectionNodeAddr)
 		}
 		return fmt.Errorf("failed to send transaction to collection node at %s: %v", collectionNodeAddr, err)
@@ -312,7 +326,7 @@ func (b *backendTransactions) GetTransactionResultsByBlockID(
 	req := execproto.GetTransactionsByBlockIDRequest{
 		BlockId: blockID[:],
 	}
-	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.nodeHealth, b.log)
 	if err != nil {
 		_, isInsufficientExecReceipts := err.(*InsufficientExecutionReceipts)
 		if isInsufficientExecReceipts {
@@ -425,7 +439,7 @@ func (b *backendTransactions) GetTransactionResultByIndex(
 		BlockId: blockID[:],
 		Index:   index,
 	}
-	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.nodeHealth, b.log)
 	if err != nil {
 		_, isInsufficientExecReceipts := err.(*InsufficientExecutionReceipts)
 		if isInsufficientExecReceipts {
@@ -646,7 +660,7 @@ func (b *backendTransactions) getTransactionResultFromExecutionNode(
 		TransactionId: transactionID,
 	}
 
-	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.nodeHealth, b.log)
 	if err != nil {
 		// if no execution receipt were found, return a NotFound GRPC error
 		if errors.As(err, &InsufficientExecutionReceipts{}) {
@@ -670,6 +684,13 @@ func (b *backendTransactions) getTransactionResultFromExecutionNode(
 
 func (b *backendTransactions) NotifyFinalizedBlockHeight(height uint64) {
 	b.retry.Retry(height)
+	b.statusBroadcaster.Publish()
+}
+
+// NotifyExecutionReceipt notifies the backend that a new execution receipt was received, which
+// may mark transactions in the executed block as executed.
+func (b *backendTransactions) NotifyExecutionReceipt() {
+	b.statusBroadcaster.Publish()
 }
 
 func (b *backendTransactions) getTransactionResultFromAnyExeNode(
@@ -686,20 +707,29 @@ func (b *backendTransactions) getTransactionResultFromAnyExeNode(
 	}
 	defer logAnyError()
 	// try to execute the script on one of the execution nodes
-	for _, execNode := range execNodes {
-		resp, err := b.tryGetTransactionResult(ctx, execNode, req)
+	var result *execproto.GetTransactionResultResponse
+	var resultErr error
+	HedgedCall(ctx, b.hedgeDelay, execNodes, func(ctx context.Context, execNode *flow.Identity) (*execproto.GetTransactionResultResponse, error) {
+		return b.tryGetTransactionResult(ctx, execNode, req)
+	}, func(execNode *flow.Identity, resp *execproto.GetTransactionResultResponse, err error) bool {
 		if err == nil {
 			b.log.Debug().
 				Str("execution_node", execNode.String()).
 				Hex("block_id", req.GetBlockId()).
 				Hex("transaction_id", req.GetTransactionId()).
 				Msg("Successfully got transaction results from any node")
-			return resp, nil
+			result = resp
+			return true
 		}
 		if status.Code(err) == codes.NotFound {
-			return nil, err
+			resultErr = err
+			return true
 		}
 		errs = multierror.Append(errs, err)
+		return false
+	})
+	if result != nil || resultErr != nil {
+		return result, resultErr
 	}
 	return nil, errs.ErrorOrNil()
 }
@@ -715,7 +745,14 @@ func (b *backendTransactions) tryGetTransactionResult(
 	}
 	defer closer.Close()
 
+	allowed, probe := b.nodeHealth.Allow(execNode.NodeID)
+	if !allowed {
+		return nil, NewCircuitBreakerOpenError(execNode.Address)
+	}
+
+	start := time.Now()
 	resp, err := execRPCClient.GetTransactionResult(ctx, &req)
+	b.nodeHealth.ReportResult(execNode.NodeID, time.Since(start), probe, err)
 	if err != nil {
 		if status.Code(err) == codes.Unavailable {
 			b.connFactory.InvalidateExecutionAPIClient(execNode.Address)
@@ -743,19 +780,28 @@ func (b *backendTransactions) getTransactionResultsByBlockIDFromAnyExeNode(
 		return nil, errors.New("zero execution nodes")
 	}
 
-	for _, execNode := range execNodes {
-		resp, err := b.tryGetTransactionResultsByBlockID(ctx, execNode, req)
+	var result *execproto.GetTransactionResultsResponse
+	var resultErr error
+	HedgedCall(ctx, b.hedgeDelay, execNodes, func(ctx context.Context, execNode *flow.Identity) (*execproto.GetTransactionResultsResponse, error) {
+		return b.tryGetTransactionResultsByBlockID(ctx, execNode, req)
+	}, func(execNode *flow.Identity, resp *execproto.GetTransactionResultsResponse, err error) bool {
 		if err == nil {
 			b.log.Debug().
 				Str("execution_node", execNode.String()).
 				Hex("block_id", req.GetBlockId()).
 				Msg("Successfully got transaction results from any node")
-			return resp, nil
+			result = resp
+			return true
 		}
 		if status.Code(err) == codes.NotFound {
-			return nil, err
+			resultErr = err
+			return true
 		}
 		errs = multierror.Append(errs, err)
+		return false
+	})
+	if result != nil || resultErr != nil {
+		return result, resultErr
 	}
 
 	// log the errors
@@ -773,7 +819,14 @@ func (b *backendTransactions) tryGetTransactionResultsByBlockID(
 	}
 	defer closer.Close()
 
+	allowed, probe := b.nodeHealth.Allow(execNode.NodeID)
+	if !allowed {
+		return nil, NewCircuitBreakerOpenError(execNode.Address)
+	}
+
+	start := time.Now()
 	resp, err := execRPCClient.GetTransactionResultsByBlockID(ctx, &req)
+	b.nodeHealth.ReportResult(execNode.NodeID, time.Since(start), probe, err)
 	if err != nil {
 		if status.Code(err) == codes.Unavailable {
 			b.connFactory.InvalidateExecutionAPIClient(execNode.Address)
@@ -802,20 +855,29 @@ func (b *backendTransactions) getTransactionResultByIndexFromAnyExeNode(
 	}
 
 	// try to execute the script on one of the execution nodes
-	for _, execNode := range execNodes {
-		resp, err := b.tryGetTransactionResultByIndex(ctx, execNode, req)
+	var result *execproto.GetTransactionResultResponse
+	var resultErr error
+	HedgedCall(ctx, b.hedgeDelay, execNodes, func(ctx context.Context, execNode *flow.Identity) (*execproto.GetTransactionResultResponse, error) {
+		return b.tryGetTransactionResultByIndex(ctx, execNode, req)
+	}, func(execNode *flow.Identity, resp *execproto.GetTransactionResultResponse, err error) bool {
 		if err == nil {
 			b.log.Debug().
 				Str("execution_node", execNode.String()).
 				Hex("block_id", req.GetBlockId()).
 				Uint32("index", req.GetIndex()).
 				Msg("Successfully got transaction results from any node")
-			return resp, nil
+			result = resp
+			return true
 		}
 		if status.Code(err) == codes.NotFound {
-			return nil, err
+			resultErr = err
+			return true
 		}
 		errs = multierror.Append(errs, err)
+		return false
+	})
+	if result != nil || resultErr != nil {
+		return result, resultErr
 	}
 
 	return nil, errs.ErrorOrNil()
@@ -832,7 +894,14 @@ func (b *backendTransactions) tryGetTransactionResultByIndex(
 	}
 	defer closer.Close()
 
+	allowed, probe := b.nodeHealth.Allow(execNode.NodeID)
+	if !allowed {
+		return nil, NewCircuitBreakerOpenError(execNode.Address)
+	}
+
+	start := time.Now()
 	resp, err := execRPCClient.GetTransactionResultByIndex(ctx, &req)
+	b.nodeHealth.ReportResult(execNode.NodeID, time.Since(start), probe, err)
 	if err != nil {
 		if status.Code(err) == codes.Unavailable {
 			b.connFactory.InvalidateExecutionAPIClient(execNode.Address)
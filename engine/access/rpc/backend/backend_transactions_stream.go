@@ -0,0 +1,159 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/state_stream"
+	"github.com/onflow/flow-go/engine/access/subscription"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// SendAndSubscribeTransactionStatuses sends the transaction to a collection node and returns a
+// subscription that streams a transaction result each time the status of the transaction changes.
+//
+// A result is sent for every status the transaction reaches in order (Pending, Finalized, Executed,
+// Sealed), even if several statuses were reached between two checks. If the transaction expires,
+// a result with the Expired status is sent instead. The subscription is closed after the
+// transaction is sealed or expired.
+//
+// Statuses are checked each time the ingestion engine processes a finalized block or an
+// execution receipt. If the status can't be determined temporarily, e.g. because no execution node
+// is available, it is checked again at the next block or receipt.
+func (b *backendTransactions) SendAndSubscribeTransactionStatuses(
+	ctx context.Context,
+	tx *flow.TransactionBody,
+) subscription.Subscription {
+	err := b.SendTransaction(ctx, tx)
+	if err != nil {
+		return state_stream.NewFailedSubscription(err, "failed to send transaction")
+	}
+
+	sub := &transactionStatusSubscription{
+		SubscriptionImpl: state_stream.NewSubscription(state_stream.DefaultSendBufferSize),
+		log:              b.log,
+		txID:             tx.ID(),
+		lastStatus:       flow.TransactionStatusUnknown,
+		getResult:        b.GetTransactionResult,
+	}
+
+	go state_stream.NewStreamer(b.log, b.statusBroadcaster, state_stream.DefaultSendTimeout, sub).Stream(ctx)
+
+	return sub
+}
+
+// transactionStatusSubscription is a subscription that sends a result each time the status of a
+// transaction changes.
+type transactionStatusSubscription struct {
+	*state_stream.SubscriptionImpl
+
+	log        zerolog.Logger
+	txID       flow.Identifier
+	lastStatus flow.TransactionStatus
+	getResult  func(ctx context.Context, txID flow.Identifier) (*access.TransactionResult, error)
+
+	// queued contains results for statuses that were reached but not sent yet
+	queued []*access.TransactionResult
+}
+
+// Next returns the result for the next status reached by the transaction.
+// Expected errors:
+// - storage.ErrNotFound if the status did not change since the last result, or could not be
+// determined temporarily
+// - state_stream.ErrEndOfData if the transaction reached a final status
+func (s *transactionStatusSubscription) Next(ctx context.Context) (interface{}, error) {
+	if len(s.queued) == 0 {
+		if isFinalTransactionStatus(s.lastStatus) {
+			return nil, state_stream.ErrEndOfData
+		}
+
+		result, err := s.getResult(ctx, s.txID)
+		if err != nil {
+			if ctx.Err() == nil && isTransientResultError(err) {
+				// keep the last status and check again at the next notification
+				s.log.Debug().Err(err).
+					Str("sub_id", s.ID()).
+					Hex("tx_id", s.txID[:]).
+					Msg("could not get transaction result, retrying")
+				return nil, fmt.Errorf("status of transaction %v not available: %v: %w", s.txID, err, storage.ErrNotFound)
+			}
+			return nil, fmt.Errorf("could not get transaction result: %w", err)
+		}
+
+		s.queued = statusTransitions(s.lastStatus, result)
+		if len(s.queued) == 0 {
+			return nil, fmt.Errorf("status of transaction %v did not change: %w", s.txID, storage.ErrNotFound)
+		}
+	}
+
+	next := s.queued[0]
+	s.queued = s.queued[1:]
+	s.lastStatus = next.Status
+
+	return next, nil
+}
+
+// statusTransitions returns a result for each status between the last status (exclusive) and the
+// status of the given result (inclusive). Intermediate results are copies of the given result
+// with their status set to the intermediate status, and without the fields that are not known yet
+// at that status.
+func statusTransitions(last flow.TransactionStatus, result *access.TransactionResult) []*access.TransactionResult {
+	if result.Status <= last {
+		return nil
+	}
+
+	// expired transactions never progressed past pending
+	if result.Status == flow.TransactionStatusExpired {
+		return []*access.TransactionResult{result}
+	}
+
+	transitions := make([]*access.TransactionResult, 0, result.Status-last)
+	for status := last + 1; status < result.Status; status++ {
+		if status == flow.TransactionStatusUnknown {
+			continue
+		}
+		intermediate := *result
+		intermediate.Status = status
+		if status < flow.TransactionStatusExecuted {
+			intermediate.StatusCode = 0
+			intermediate.Events = nil
+			intermediate.ErrorMessage = ""
+		}
+		if status < flow.TransactionStatusFinalized {
+			intermediate.BlockID = flow.ZeroID
+			intermediate.CollectionID = flow.ZeroID
+			intermediate.BlockHeight = 0
+		}
+		transitions = append(transitions, &intermediate)
+	}
+
+	return append(transitions, result)
+}
+
+// isTransientResultError returns true if the transaction result could not be retrieved because of
+// an error that may resolve itself, e.g. the transaction was not indexed yet, or no execution node
+// could be reached.
+func isTransientResultError(err error) bool {
+	if errors.Is(err, storage.ErrNotFound) {
+		return true
+	}
+
+	switch status.Code(err) {
+	case codes.NotFound, codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// isFinalTransactionStatus returns true if the transaction status can not change anymore.
+func isFinalTransactionStatus(status flow.TransactionStatus) bool {
+	return status == flow.TransactionStatusSealed || status == flow.TransactionStatusExpired
+}
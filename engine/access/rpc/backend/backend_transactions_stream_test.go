@@ -0,0 +1,139 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/state_stream"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestTransactionStatusSubscription_Next(t *testing.T) {
+	txID := unittest.IdentifierFixture()
+	blockID := unittest.IdentifierFixture()
+	events := []flow.Event{unittest.EventFixture(flow.EventAccountCreated, 0, 0, txID, 0)}
+
+	var current *access.TransactionResult
+	sub := &transactionStatusSubscription{
+		SubscriptionImpl: state_stream.NewSubscription(state_stream.DefaultSendBufferSize),
+		log:              zerolog.Nop(),
+		txID:             txID,
+		lastStatus:       flow.TransactionStatusUnknown,
+		getResult: func(_ context.Context, id flow.Identifier) (*access.TransactionResult, error) {
+			assert.Equal(t, txID, id)
+			return current, nil
+		},
+	}
+
+	next := func() *access.TransactionResult {
+		v, err := sub.Next(context.Background())
+		require.NoError(t, err)
+		return v.(*access.TransactionResult)
+	}
+
+	current = &access.TransactionResult{Status: flow.TransactionStatusPending, TransactionID: txID}
+	assert.Equal(t, flow.TransactionStatusPending, next().Status)
+
+	// no change since the last result
+	_, err := sub.Next(context.Background())
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	// the transaction was finalized and executed between two checks
+	current = &access.TransactionResult{
+		Status:        flow.TransactionStatusExecuted,
+		TransactionID: txID,
+		BlockID:       blockID,
+		BlockHeight:   10,
+		Events:        events,
+	}
+
+	finalized := next()
+	assert.Equal(t, flow.TransactionStatusFinalized, finalized.Status)
+	assert.Equal(t, blockID, finalized.BlockID)
+	assert.Empty(t, finalized.Events)
+
+	executed := next()
+	assert.Equal(t, flow.TransactionStatusExecuted, executed.Status)
+	assert.Equal(t, events, executed.Events)
+
+	current = &access.TransactionResult{Status: flow.TransactionStatusSealed, TransactionID: txID, BlockID: blockID}
+	assert.Equal(t, flow.TransactionStatusSealed, next().Status)
+
+	// sealed is the final status
+	_, err = sub.Next(context.Background())
+	assert.ErrorIs(t, err, state_stream.ErrEndOfData)
+}
+
+func TestTransactionStatusSubscription_NextErrors(t *testing.T) {
+	txID := unittest.IdentifierFixture()
+
+	var current *access.TransactionResult
+	var currentErr error
+	sub := &transactionStatusSubscription{
+		SubscriptionImpl: state_stream.NewSubscription(state_stream.DefaultSendBufferSize),
+		log:              zerolog.Nop(),
+		txID:             txID,
+		lastStatus:       flow.TransactionStatusUnknown,
+		getResult: func(_ context.Context, _ flow.Identifier) (*access.TransactionResult, error) {
+			return current, currentErr
+		},
+	}
+
+	current = &access.TransactionResult{Status: flow.TransactionStatusPending, TransactionID: txID}
+	_, err := sub.Next(context.Background())
+	require.NoError(t, err)
+
+	// transient errors are reported as not available, so the status is checked again at the next block
+	for _, transientErr := range []error{
+		status.Error(codes.Unavailable, "no execution node available"),
+		status.Error(codes.NotFound, "transaction result not indexed yet"),
+		fmt.Errorf("not indexed yet: %w", storage.ErrNotFound),
+	} {
+		current, currentErr = nil, transientErr
+		_, err = sub.Next(context.Background())
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+		assert.Equal(t, flow.TransactionStatusPending, sub.lastStatus)
+	}
+
+	// the subscription continues after the transient errors
+	current, currentErr = &access.TransactionResult{Status: flow.TransactionStatusFinalized, TransactionID: txID}, nil
+	v, err := sub.Next(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, flow.TransactionStatusFinalized, v.(*access.TransactionResult).Status)
+
+	// irrecoverable errors end the subscription
+	current, currentErr = nil, status.Error(codes.Internal, "failed to convert result")
+	_, err = sub.Next(context.Background())
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, storage.ErrNotFound)
+
+	// errors caused by the client going away end the subscription
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	current, currentErr = nil, status.Error(codes.Unavailable, "context canceled")
+	_, err = sub.Next(ctx)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestStatusTransitions_Expired(t *testing.T) {
+	result := &access.TransactionResult{Status: flow.TransactionStatusExpired}
+
+	transitions := statusTransitions(flow.TransactionStatusPending, result)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, flow.TransactionStatusExpired, transitions[0].Status)
+	assert.True(t, isFinalTransactionStatus(transitions[0].Status))
+
+	// statuses never go backwards
+	assert.Empty(t, statusTransitions(flow.TransactionStatusExpired, result))
+}
@@ -0,0 +1,188 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultCircuitBreakerMaxFailures is the default number of consecutive failed requests after
+	// which the circuit to a node opens.
+	DefaultCircuitBreakerMaxFailures = 5
+
+	// DefaultCircuitBreakerRestoreTimeout is the default duration the circuit to a node stays open
+	// before a request is let through to check whether the node recovered.
+	DefaultCircuitBreakerRestoreTimeout = 60 * time.Second
+)
+
+// ErrCircuitBreakerOpen is matched by the errors of requests that were rejected without contacting
+// the node, because its circuit breaker is open. Such rejections say nothing new about the health of
+// the node, so they must not be reported as node failures.
+var ErrCircuitBreakerOpen = errors.New("circuit breaker is open")
+
+// circuitBreakerOpenError is the error of a request rejected by an open circuit breaker. It matches
+// ErrCircuitBreakerOpen, and is returned to gRPC clients as codes.Unavailable.
+type circuitBreakerOpenError struct {
+	target string
+}
+
+// NewCircuitBreakerOpenError returns the error of a request to the target that was rejected by an
+// open circuit breaker.
+func NewCircuitBreakerOpenError(target string) error {
+	return circuitBreakerOpenError{target: target}
+}
+
+func (e circuitBreakerOpenError) Error() string {
+	return fmt.Sprintf("%v for %s", ErrCircuitBreakerOpen, e.target)
+}
+
+func (e circuitBreakerOpenError) Is(target error) bool {
+	return target == ErrCircuitBreakerOpen
+}
+
+func (e circuitBreakerOpenError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+// CircuitBreakerConfig configures the circuit breakers of the connections to upstream nodes.
+// Requests to execution nodes are guarded by the ExecutionNodeHealth instead.
+type CircuitBreakerConfig struct {
+	// Enabled enables circuit breaking of the requests to upstream nodes.
+	Enabled bool
+	// MaxFailures is the number of consecutive failed requests after which the circuit opens.
+	MaxFailures uint32
+	// RestoreTimeout is the duration the circuit stays open before a single request is let
+	// through to check whether the node recovered.
+	RestoreTimeout time.Duration
+}
+
+// DefaultCircuitBreakerConfig returns the default circuit breaker configuration, which is disabled.
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		Enabled:        false,
+		MaxFailures:    DefaultCircuitBreakerMaxFailures,
+		RestoreTimeout: DefaultCircuitBreakerRestoreTimeout,
+	}
+}
+
+// CircuitBreaker tracks the requests sent to a single upstream node. Once MaxFailures consecutive
+// requests failed, the circuit opens and requests are rejected without contacting the node. After
+// RestoreTimeout, a single probe request is let through: if it succeeds the circuit closes,
+// otherwise it stays open for another RestoreTimeout. While the circuit is open, only the result of
+// the probe changes its state.
+// CircuitBreaker is safe for concurrent use.
+type CircuitBreaker struct {
+	mu       sync.Mutex
+	config   CircuitBreakerConfig
+	failures uint32
+	// openUntil is the time until which requests are rejected once the circuit is open
+	openUntil time.Time
+	// probing is true while the single request let through an open circuit is in flight
+	probing bool
+	now     func() time.Time
+}
+
+// NewCircuitBreaker creates a new closed circuit breaker.
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Allow returns whether a request can be sent to the node, and whether the request is the probe
+// let through the open circuit to check whether the node recovered. Every allowed request must be
+// followed by a call to Report with its result.
+func (cb *CircuitBreaker) Allow() (allowed bool, probe bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !cb.open() {
+		return true, false
+	}
+	if cb.probing || cb.now().Before(cb.openUntil) {
+		return false, false
+	}
+
+	cb.probing = true
+	return true, true
+}
+
+// Report records the result of a request allowed by Allow. probe must be the value Allow returned
+// for the request.
+func (cb *CircuitBreaker) Report(probe bool, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if probe {
+		cb.probing = false
+	}
+
+	// requests cancelled by the caller, for example hedged requests answered by another node,
+	// say nothing about the health of the node. A cancelled probe lets the next request probe.
+	if status.Code(err) == codes.Canceled {
+		return
+	}
+
+	// results of requests sent before the circuit opened neither close nor extend it
+	if cb.open() && !probe {
+		return
+	}
+
+	if !IsNodeFailure(err) {
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	if cb.open() {
+		cb.openUntil = cb.now().Add(cb.config.RestoreTimeout)
+	}
+}
+
+// IsOpen returns true if requests to the node are currently rejected.
+func (cb *CircuitBreaker) IsOpen() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.open() && (cb.probing || cb.now().Before(cb.openUntil))
+}
+
+func (cb *CircuitBreaker) open() bool {
+	return cb.failures >= cb.config.MaxFailures
+}
+
+// WithClientCircuitBreakerInterceptor returns a dial option that rejects requests with an error
+// matching ErrCircuitBreakerOpen while the circuit breaker is open, and reports the result of all
+// other requests to the circuit breaker.
+func WithClientCircuitBreakerInterceptor(breaker *CircuitBreaker) grpc.DialOption {
+
+	circuitBreakerInterceptor := func(
+		ctx context.Context,
+		method string,
+		req interface{},
+		reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		allowed, probe := breaker.Allow()
+		if !allowed {
+			return NewCircuitBreakerOpenError(cc.Target())
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		breaker.Report(probe, err)
+
+		return err
+	}
+
+	return grpc.WithChainUnaryInterceptor(circuitBreakerInterceptor)
+}
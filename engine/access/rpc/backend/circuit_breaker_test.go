@@ -0,0 +1,135 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	config := CircuitBreakerConfig{
+		Enabled:        true,
+		MaxFailures:    2,
+		RestoreTimeout: time.Second,
+	}
+
+	// newBreaker returns a circuit breaker with a manually advanced clock
+	newBreaker := func() (*CircuitBreaker, *time.Time) {
+		now := time.Now()
+		breaker := NewCircuitBreaker(config)
+		breaker.now = func() time.Time { return now }
+		return breaker, &now
+	}
+
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	// request sends a request allowed by the breaker, and reports its result
+	request := func(t *testing.T, breaker *CircuitBreaker, err error) {
+		allowed, probe := breaker.Allow()
+		require.True(t, allowed)
+		breaker.Report(probe, err)
+	}
+
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		breaker, _ := newBreaker()
+
+		request(t, breaker, unavailable)
+		// a success resets the consecutive failures
+		request(t, breaker, nil)
+		request(t, breaker, unavailable)
+		assert.False(t, breaker.IsOpen())
+
+		request(t, breaker, unavailable)
+		assert.True(t, breaker.IsOpen())
+		allowed, _ := breaker.Allow()
+		assert.False(t, allowed)
+	})
+
+	t.Run("errors returned by healthy nodes are not failures", func(t *testing.T) {
+		breaker, _ := newBreaker()
+
+		for i := 0; i < 5; i++ {
+			request(t, breaker, status.Error(codes.NotFound, "not found"))
+			request(t, breaker, status.Error(codes.Canceled, "canceled"))
+		}
+		assert.False(t, breaker.IsOpen())
+	})
+
+	t.Run("a single probe is let through after the restore timeout", func(t *testing.T) {
+		breaker, now := newBreaker()
+		request(t, breaker, unavailable)
+		request(t, breaker, unavailable)
+		assert.True(t, breaker.IsOpen())
+
+		*now = now.Add(config.RestoreTimeout)
+		assert.False(t, breaker.IsOpen())
+		allowed, probe := breaker.Allow()
+		assert.True(t, allowed)
+		assert.True(t, probe)
+		// other requests are rejected while the probe is in flight
+		assert.True(t, breaker.IsOpen())
+		allowed, _ = breaker.Allow()
+		assert.False(t, allowed)
+
+		// a failed probe opens the circuit for another restore timeout
+		breaker.Report(probe, unavailable)
+		allowed, _ = breaker.Allow()
+		assert.False(t, allowed)
+		*now = now.Add(config.RestoreTimeout)
+		allowed, probe = breaker.Allow()
+		assert.True(t, allowed)
+		assert.True(t, probe)
+
+		// a successful probe closes the circuit
+		breaker.Report(probe, nil)
+		assert.False(t, breaker.IsOpen())
+		allowed, probe = breaker.Allow()
+		assert.True(t, allowed)
+		assert.False(t, probe)
+	})
+
+	t.Run("only the probe closes an open circuit", func(t *testing.T) {
+		breaker, now := newBreaker()
+
+		// a slow request is sent before the circuit opens
+		allowed, slowRequestProbe := breaker.Allow()
+		require.True(t, allowed)
+		require.False(t, slowRequestProbe)
+
+		request(t, breaker, unavailable)
+		request(t, breaker, unavailable)
+		assert.True(t, breaker.IsOpen())
+
+		// its success says nothing about the node's current health
+		breaker.Report(slowRequestProbe, nil)
+		assert.True(t, breaker.IsOpen())
+
+		// nor does the failure of such a request extend the circuit
+		*now = now.Add(config.RestoreTimeout)
+		breaker.Report(false, unavailable)
+		assert.False(t, breaker.IsOpen())
+
+		// a cancelled probe lets the next request probe
+		allowed, probe := breaker.Allow()
+		require.True(t, allowed)
+		breaker.Report(probe, status.Error(codes.Canceled, "canceled"))
+		allowed, probe = breaker.Allow()
+		assert.True(t, allowed)
+		assert.True(t, probe)
+	})
+}
+
+func TestCircuitBreakerOpenError(t *testing.T) {
+	err := NewCircuitBreakerOpenError("node:9000")
+
+	assert.True(t, errors.Is(err, ErrCircuitBreakerOpen))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "node:9000")
+
+	assert.False(t, errors.Is(status.Error(codes.Unavailable, "unavailable"), ErrCircuitBreakerOpen))
+}
@@ -60,7 +60,13 @@ type ConnectionFactoryImpl struct {
 	CacheSize                 uint
 	AccessMetrics             module.AccessMetrics
 	Log                       zerolog.Logger
+	CircuitBreakerConfig      CircuitBreakerConfig
 	mutex                     sync.Mutex
+
+	// circuitBreakers holds the circuit breaker of each upstream node by gRPC address, so that
+	// the state of the circuit is kept when connections are closed and recreated
+	circuitBreakers     map[string]*CircuitBreaker
+	circuitBreakerMutex sync.Mutex
 }
 
 type CachedClient struct {
@@ -70,8 +76,9 @@ type CachedClient struct {
 	timeout    time.Duration
 }
 
-// createConnection creates new gRPC connections to remote node
-func (cf *ConnectionFactoryImpl) createConnection(address string, timeout time.Duration) (*grpc.ClientConn, error) {
+// createConnection creates new gRPC connections to remote node. Requests to execution nodes are guarded by
+// the ExecutionNodeHealth of the backend instead, so the circuit breaker is only installed if requested.
+func (cf *ConnectionFactoryImpl) createConnection(address string, timeout time.Duration, withCircuitBreaker bool) (*grpc.ClientConn, error) {
 
 	if timeout == 0 {
 		timeout = DefaultClientTimeout
@@ -88,19 +95,50 @@ func (cf *ConnectionFactoryImpl) createConnection(address string, timeout time.D
 	// The connections should be safe to be persisted and reused
 	// https://pkg.go.dev/google.golang.org/grpc#WithKeepaliveParams
 	// https://grpc.io/blog/grpc-on-http2/#keeping-connections-alive
-	conn, err := grpc.Dial(
-		address,
+	opts := []grpc.DialOption{
 		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(grpcutils.DefaultMaxMsgSize)),
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithKeepaliveParams(keepaliveParams),
-		WithClientUnaryInterceptor(timeout))
+		WithClientUnaryInterceptor(timeout),
+	}
+	if withCircuitBreaker && cf.CircuitBreakerConfig.Enabled {
+		opts = append(opts, WithClientCircuitBreakerInterceptor(cf.circuitBreaker(address)))
+	}
+
+	conn, err := grpc.Dial(address, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to address %s: %w", address, err)
 	}
 	return conn, nil
 }
 
-func (cf *ConnectionFactoryImpl) retrieveConnection(grpcAddress string, timeout time.Duration) (*grpc.ClientConn, error) {
+// circuitBreaker returns the circuit breaker for requests to the node at the given gRPC address.
+func (cf *ConnectionFactoryImpl) circuitBreaker(address string) *CircuitBreaker {
+	cf.circuitBreakerMutex.Lock()
+	defer cf.circuitBreakerMutex.Unlock()
+
+	if cf.circuitBreakers == nil {
+		cf.circuitBreakers = make(map[string]*CircuitBreaker)
+	}
+
+	breaker, ok := cf.circuitBreakers[address]
+	if !ok {
+		breaker = NewCircuitBreaker(cf.CircuitBreakerConfig)
+		cf.circuitBreakers[address] = breaker
+	}
+	return breaker
+}
+
+// checkCircuitBreaker returns an error if the circuit breaker of the node at the given gRPC address
+// is open.
+func (cf *ConnectionFactoryImpl) checkCircuitBreaker(grpcAddress string) error {
+	if cf.CircuitBreakerConfig.Enabled && cf.circuitBreaker(grpcAddress).IsOpen() {
+		return NewCircuitBreakerOpenError(grpcAddress)
+	}
+	return nil
+}
+
+func (cf *ConnectionFactoryImpl) retrieveConnection(grpcAddress string, timeout time.Duration, withCircuitBreaker bool) (*grpc.ClientConn, error) {
 	var conn *grpc.ClientConn
 	var store *CachedClient
 	cacheHit := false
@@ -127,7 +165,7 @@ func (cf *ConnectionFactoryImpl) retrieveConnection(grpcAddress string, timeout
 
 	if conn == nil || conn.GetState() == connectivity.Shutdown {
 		var err error
-		conn, err = cf.createConnection(grpcAddress, timeout)
+		conn, err = cf.createConnection(grpcAddress, timeout, withCircuitBreaker)
 		if err != nil {
 			return nil, err
 		}
@@ -152,16 +190,22 @@ func (cf *ConnectionFactoryImpl) GetAccessAPIClient(address string) (access.Acce
 		return nil, nil, err
 	}
 
+	// fail fast without contacting a node that is known to be failing
+	err = cf.checkCircuitBreaker(grpcAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var conn *grpc.ClientConn
 	if cf.ConnectionsCache != nil {
-		conn, err = cf.retrieveConnection(grpcAddress, cf.CollectionNodeGRPCTimeout)
+		conn, err = cf.retrieveConnection(grpcAddress, cf.CollectionNodeGRPCTimeout, true)
 		if err != nil {
 			return nil, nil, err
 		}
 		return access.NewAccessAPIClient(conn), &noopCloser{}, err
 	}
 
-	conn, err = cf.createConnection(grpcAddress, cf.CollectionNodeGRPCTimeout)
+	conn, err = cf.createConnection(grpcAddress, cf.CollectionNodeGRPCTimeout, true)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -187,14 +231,14 @@ func (cf *ConnectionFactoryImpl) GetExecutionAPIClient(address string) (executio
 
 	var conn *grpc.ClientConn
 	if cf.ConnectionsCache != nil {
-		conn, err = cf.retrieveConnection(grpcAddress, cf.ExecutionNodeGRPCTimeout)
+		conn, err = cf.retrieveConnection(grpcAddress, cf.ExecutionNodeGRPCTimeout, false)
 		if err != nil {
 			return nil, nil, err
 		}
 		return execution.NewExecutionAPIClient(conn), &noopCloser{}, nil
 	}
 
-	conn, err = cf.createConnection(grpcAddress, cf.ExecutionNodeGRPCTimeout)
+	conn, err = cf.createConnection(grpcAddress, cf.ExecutionNodeGRPCTimeout, false)
 	if err != nil {
 		return nil, nil, err
 	}
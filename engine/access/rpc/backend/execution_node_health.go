@@ -0,0 +1,420 @@
+package backend
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+)
+
+const (
+	// DefaultExecutionNodeFailureThreshold is the default number of consecutive failed requests
+	// after which requests to an execution node are suspended
+	DefaultExecutionNodeFailureThreshold = 3
+
+	// DefaultExecutionNodeBackoff is the default duration requests to a failing execution node are
+	// suspended for. The duration doubles each time the node fails again after the backoff.
+	DefaultExecutionNodeBackoff = 5 * time.Second
+
+	// DefaultExecutionNodeMaxBackoff is the default maximum duration requests to a failing
+	// execution node are suspended for
+	DefaultExecutionNodeMaxBackoff = 2 * time.Minute
+
+	// DefaultExecutionNodeMaxLag is the default number of blocks an execution node may lag behind
+	// the sealed height before it is only used if no other nodes are available
+	DefaultExecutionNodeMaxLag = 30
+
+	// healthSampleWeight is the weight of the latest request in the moving averages of the
+	// latency and error rate of an execution node
+	healthSampleWeight = 0.2
+
+	// errorRatePenalty scales the latency of an execution node by its error rate when ranking
+	// nodes, e.g. a node with a 10% error rate ranks like a node with twice its latency
+	errorRatePenalty = 10
+)
+
+// ExecutionNodeHealthConfig configures how the health of execution nodes is tracked.
+type ExecutionNodeHealthConfig struct {
+	// FailureThreshold is the number of consecutive failed requests after which requests to a node
+	// are suspended.
+	FailureThreshold uint
+	// Backoff is the duration requests to a failing node are first suspended for.
+	Backoff time.Duration
+	// MaxBackoff is the maximum duration requests to a failing node are suspended for.
+	MaxBackoff time.Duration
+	// MaxLag is the number of blocks a node may lag behind the sealed height before it is
+	// deprioritized.
+	MaxLag uint64
+	// CircuitBreakerEnabled rejects requests to suspended nodes with an error matching
+	// ErrCircuitBreakerOpen. Otherwise, suspended nodes are only deprioritized, and requests are
+	// still sent to them if no other nodes are available.
+	CircuitBreakerEnabled bool
+}
+
+// DefaultExecutionNodeHealthConfig returns the default ExecutionNodeHealthConfig.
+func DefaultExecutionNodeHealthConfig() ExecutionNodeHealthConfig {
+	return ExecutionNodeHealthConfig{
+		FailureThreshold: DefaultExecutionNodeFailureThreshold,
+		Backoff:          DefaultExecutionNodeBackoff,
+		MaxBackoff:       DefaultExecutionNodeMaxBackoff,
+		MaxLag:           DefaultExecutionNodeMaxLag,
+	}
+}
+
+// ExecutionNodeHealthStatus is a snapshot of the tracked health of an execution node.
+type ExecutionNodeHealthStatus struct {
+	NodeID              flow.Identifier `json:"node_id"`
+	Latency             time.Duration   `json:"latency_ns"`
+	ErrorRate           float64         `json:"error_rate"`
+	ConsecutiveFailures uint            `json:"consecutive_failures"`
+	ExecutedHeight      uint64          `json:"executed_height"`
+	Lag                 uint64          `json:"lag"`
+	CircuitOpen         bool            `json:"circuit_open"`
+	CircuitOpenUntil    time.Time       `json:"circuit_open_until,omitempty"`
+}
+
+// executionNodeHealth is the tracked health of an execution node.
+type executionNodeHealth struct {
+	latency             time.Duration
+	errorRate           float64
+	consecutiveFailures uint
+	// backoff is the duration of the last suspension, it is reset after a successful request
+	backoff time.Duration
+	// openUntil is the time until which requests to the node are suspended
+	openUntil time.Time
+	// probing is true while the probe sent after the backoff elapsed is in flight
+	probing bool
+	// executedHeight is the highest block height the node was seen to have a receipt for
+	executedHeight uint64
+}
+
+// ExecutionNodeHealth tracks the latency, error rate and lag of execution nodes, and ranks
+// execution nodes by their health when choosing the nodes to send a request to.
+//
+// ExecutionNodeHealth is the circuit breaker of execution nodes. Requests to a node are suspended
+// after FailureThreshold consecutive failures. Once the backoff elapsed, a single probe request is
+// allowed through. If it fails, requests are suspended again for twice the previous backoff,
+// otherwise the node is considered healthy again. While a node is suspended, only the result of
+// the probe changes its state.
+//
+// A nil ExecutionNodeHealth does not track anything, and chooses random execution nodes.
+// ExecutionNodeHealth is safe for concurrent use.
+type ExecutionNodeHealth struct {
+	config  ExecutionNodeHealthConfig
+	metrics module.AccessMetrics
+	log     zerolog.Logger
+
+	mu           sync.Mutex
+	nodes        map[flow.Identifier]*executionNodeHealth
+	sealedHeight uint64
+
+	// now returns the current time, it is replaced in tests
+	now func() time.Time
+}
+
+// NewExecutionNodeHealth creates a new ExecutionNodeHealth.
+func NewExecutionNodeHealth(
+	config ExecutionNodeHealthConfig,
+	metrics module.AccessMetrics,
+	log zerolog.Logger,
+) *ExecutionNodeHealth {
+	return &ExecutionNodeHealth{
+		config:  config,
+		metrics: metrics,
+		log:     log.With().Str("component", "execution_node_health").Logger(),
+		nodes:   make(map[flow.Identifier]*executionNodeHealth),
+		now:     time.Now,
+	}
+}
+
+// Allow returns whether a request can be sent to the execution node, and whether the request is
+// the probe sent once the node's suspension elapsed, to check whether it recovered. Requests to
+// suspended nodes are only rejected if the circuit breaker is enabled.
+// Every allowed request must be followed by a call to ReportResult with its result.
+func (h *ExecutionNodeHealth) Allow(nodeID flow.Identifier) (allowed bool, probe bool) {
+	if h == nil {
+		return true, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	node, ok := h.nodes[nodeID]
+	if !ok || node.backoff == 0 {
+		return true, false
+	}
+	if h.suspended(node, h.now()) {
+		return !h.config.CircuitBreakerEnabled, false
+	}
+
+	node.probing = true
+	return true, true
+}
+
+// ReportResult records the outcome of a request to the execution node. probe must be the value
+// Allow returned for the request. Only errors that indicate that the node is unavailable or unable
+// to serve requests count as failures. Other errors, such as invalid arguments or missing data, are
+// responses of a healthy node.
+func (h *ExecutionNodeHealth) ReportResult(nodeID flow.Identifier, latency time.Duration, probe bool, err error) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	node := h.node(nodeID)
+	if probe {
+		node.probing = false
+	}
+
+	// requests cancelled by the caller, for example hedged requests answered by another node, say
+	// nothing about the health of the node. A cancelled probe lets the next request probe.
+	if status.Code(err) == codes.Canceled {
+		return
+	}
+
+	failed := IsNodeFailure(err)
+	sample := 0.0
+	if failed {
+		sample = 1.0
+	}
+	node.errorRate = (1-healthSampleWeight)*node.errorRate + healthSampleWeight*sample
+	if node.latency == 0 {
+		node.latency = latency
+	} else {
+		node.latency = time.Duration((1-healthSampleWeight)*float64(node.latency) + healthSampleWeight*float64(latency))
+	}
+
+	switch {
+	case node.backoff > 0 && !probe:
+		// results of requests sent before the node was suspended, or sent to the suspended node
+		// because no other node was available, neither end nor extend the suspension
+	case !failed:
+		node.consecutiveFailures = 0
+		node.backoff = 0
+		node.openUntil = time.Time{}
+	default:
+		node.consecutiveFailures++
+		// a failed probe suspends the node again, regardless of the threshold
+		if probe || node.consecutiveFailures >= h.config.FailureThreshold {
+			h.suspend(nodeID, node, err)
+		}
+	}
+
+	h.reportMetrics(nodeID, node)
+}
+
+// suspend suspends requests to the node for twice its previous backoff, or for the initial backoff
+// if it was not suspended before.
+// Must be called with the lock held.
+func (h *ExecutionNodeHealth) suspend(nodeID flow.Identifier, node *executionNodeHealth, err error) {
+	if node.backoff == 0 {
+		node.backoff = h.config.Backoff
+	} else {
+		node.backoff *= 2
+	}
+	if node.backoff > h.config.MaxBackoff {
+		node.backoff = h.config.MaxBackoff
+	}
+	node.openUntil = h.now().Add(node.backoff)
+
+	h.log.Warn().
+		Hex("execution_node", nodeID[:]).
+		Uint("consecutive_failures", node.consecutiveFailures).
+		Dur("backoff", node.backoff).
+		Err(err).
+		Msg("suspending requests to failing execution node")
+}
+
+// ReportExecuted records that the execution node has a receipt for the block at the given height,
+// so that its lag behind the sealed height can be tracked.
+func (h *ExecutionNodeHealth) ReportExecuted(nodeID flow.Identifier, height uint64) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	node := h.node(nodeID)
+	if height > node.executedHeight {
+		node.executedHeight = height
+		h.reportMetrics(nodeID, node)
+	}
+}
+
+// ReportSealed updates the latest sealed height the lag of execution nodes is measured against.
+func (h *ExecutionNodeHealth) ReportSealed(height uint64) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if height > h.sealedHeight {
+		h.sealedHeight = height
+	}
+}
+
+// Select returns up to n of the candidate nodes, ordered from the healthiest to the least healthy
+// node. Nodes with suspended requests are only returned if no other nodes are available. Nodes
+// with the same health are returned in random order, so that load is spread across them.
+func (h *ExecutionNodeHealth) Select(candidates flow.IdentityList, n uint) flow.IdentityList {
+	if h == nil {
+		return candidates.Sample(n)
+	}
+
+	// shuffle the candidates to break ties randomly
+	candidates = candidates.Sample(uint(len(candidates)))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	var available, suspended flow.IdentityList
+	for _, identity := range candidates {
+		node, ok := h.nodes[identity.NodeID]
+		if ok && h.suspended(node, now) {
+			suspended = append(suspended, identity)
+			continue
+		}
+		available = append(available, identity)
+	}
+
+	if len(available) == 0 {
+		// rather than failing the request, try the nodes whose suspension ends first
+		sort.SliceStable(suspended, func(i, j int) bool {
+			return h.nodes[suspended[i].NodeID].openUntil.Before(h.nodes[suspended[j].NodeID].openUntil)
+		})
+		available = suspended
+	} else {
+		sort.SliceStable(available, func(i, j int) bool {
+			return h.less(available[i].NodeID, available[j].NodeID)
+		})
+	}
+
+	if uint(len(available)) > n {
+		available = available[:n]
+	}
+	return available
+}
+
+// less returns true if node a is healthier than node b. Nodes lagging behind the sealed height
+// rank after all other nodes, and nodes are otherwise ranked by their latency weighted by their
+// error rate. Nodes without any requests yet rank first, so that they get measured.
+// Must be called with the lock held.
+func (h *ExecutionNodeHealth) less(a, b flow.Identifier) bool {
+	nodeA, okA := h.nodes[a]
+	nodeB, okB := h.nodes[b]
+	if !okA || !okB {
+		return !okA && okB
+	}
+
+	laggingA := h.lag(nodeA) > h.config.MaxLag
+	laggingB := h.lag(nodeB) > h.config.MaxLag
+	if laggingA != laggingB {
+		return laggingB
+	}
+
+	return score(nodeA) < score(nodeB)
+}
+
+// Status returns the tracked health of all execution nodes, ordered by node ID.
+func (h *ExecutionNodeHealth) Status() []ExecutionNodeHealthStatus {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	statuses := make([]ExecutionNodeHealthStatus, 0, len(h.nodes))
+	for nodeID, node := range h.nodes {
+		status := ExecutionNodeHealthStatus{
+			NodeID:              nodeID,
+			Latency:             node.latency,
+			ErrorRate:           node.errorRate,
+			ConsecutiveFailures: node.consecutiveFailures,
+			ExecutedHeight:      node.executedHeight,
+			Lag:                 h.lag(node),
+			CircuitOpen:         h.suspended(node, now),
+		}
+		if status.CircuitOpen {
+			status.CircuitOpenUntil = node.openUntil
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].NodeID.String() < statuses[j].NodeID.String()
+	})
+
+	return statuses
+}
+
+// suspended returns true if requests to the node are suspended, either because its backoff did not
+// elapse yet, or because the probe sent after the backoff is still in flight.
+// Must be called with the lock held.
+func (h *ExecutionNodeHealth) suspended(node *executionNodeHealth, now time.Time) bool {
+	return node.backoff > 0 && (node.probing || now.Before(node.openUntil))
+}
+
+// node returns the tracked health of the node, adding it if it is not tracked yet.
+// Must be called with the lock held.
+func (h *ExecutionNodeHealth) node(nodeID flow.Identifier) *executionNodeHealth {
+	node, ok := h.nodes[nodeID]
+	if !ok {
+		node = &executionNodeHealth{}
+		h.nodes[nodeID] = node
+	}
+	return node
+}
+
+// lag returns the number of blocks the node lags behind the sealed height. The lag of nodes that
+// were not seen to have a receipt yet is unknown, and reported as 0.
+// Must be called with the lock held.
+func (h *ExecutionNodeHealth) lag(node *executionNodeHealth) uint64 {
+	if node.executedHeight == 0 || node.executedHeight >= h.sealedHeight {
+		return 0
+	}
+	return h.sealedHeight - node.executedHeight
+}
+
+// reportMetrics reports the health of the node.
+// Must be called with the lock held.
+func (h *ExecutionNodeHealth) reportMetrics(nodeID flow.Identifier, node *executionNodeHealth) {
+	h.metrics.ExecutionNodeHealth(nodeID, node.latency, node.errorRate, h.lag(node), h.suspended(node, h.now()))
+}
+
+// score returns the latency of the node weighted by its error rate. Lower is better.
+func score(node *executionNodeHealth) float64 {
+	return float64(node.latency) * (1 + errorRatePenalty*node.errorRate)
+}
+
+// IsNodeFailure returns true if the error returned by an upstream node indicates that the node
+// is unavailable or unable to serve requests.
+func IsNodeFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable,
+		codes.DeadlineExceeded,
+		codes.ResourceExhausted,
+		codes.Internal,
+		codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,219 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestExecutionNodeHealth(t *testing.T) {
+	config := ExecutionNodeHealthConfig{
+		FailureThreshold: 2,
+		Backoff:          time.Second,
+		MaxBackoff:       3 * time.Second,
+		MaxLag:           10,
+	}
+
+	// newHealth returns a tracker with a manually advanced clock
+	newHealth := func() (*ExecutionNodeHealth, *time.Time) {
+		now := time.Now()
+		health := NewExecutionNodeHealth(config, metrics.NewNoopCollector(), zerolog.Nop())
+		health.now = func() time.Time { return now }
+		return health, &now
+	}
+
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	// request sends a request allowed by the tracker, and reports its result
+	request := func(t *testing.T, health *ExecutionNodeHealth, nodeID flow.Identifier, latency time.Duration, err error) {
+		allowed, probe := health.Allow(nodeID)
+		require.True(t, allowed)
+		health.ReportResult(nodeID, latency, probe, err)
+	}
+
+	t.Run("healthiest nodes are selected first", func(t *testing.T) {
+		health, _ := newHealth()
+		nodes := flow.IdentityList{
+			{NodeID: unittest.IdentifierFixture()},
+			{NodeID: unittest.IdentifierFixture()},
+			{NodeID: unittest.IdentifierFixture()},
+			{NodeID: unittest.IdentifierFixture()},
+		}
+
+		request(t, health, nodes[0].NodeID, 300*time.Millisecond, nil)
+		request(t, health, nodes[1].NodeID, 100*time.Millisecond, nil)
+		// a faster node ranks after a slower node if its requests fail
+		request(t, health, nodes[2].NodeID, 50*time.Millisecond, nil)
+		request(t, health, nodes[2].NodeID, 50*time.Millisecond, unavailable)
+		// nodes without requests rank first, so that they get measured
+
+		for i := 0; i < 10; i++ {
+			selected := health.Select(nodes, 3)
+			assert.Equal(t, flow.IdentifierList{nodes[3].NodeID, nodes[1].NodeID, nodes[2].NodeID}, selected.NodeIDs())
+		}
+	})
+
+	t.Run("script failures do not count as node failures", func(t *testing.T) {
+		health, _ := newHealth()
+		nodeID := unittest.IdentifierFixture()
+
+		for i := 0; i < 5; i++ {
+			request(t, health, nodeID, time.Millisecond, status.Error(codes.InvalidArgument, "script failed"))
+		}
+
+		status := health.Status()
+		require.Len(t, status, 1)
+		assert.Zero(t, status[0].ErrorRate)
+		assert.Zero(t, status[0].ConsecutiveFailures)
+		assert.False(t, status[0].CircuitOpen)
+	})
+
+	t.Run("failing nodes are suspended", func(t *testing.T) {
+		health, now := newHealth()
+		failing := &flow.Identity{NodeID: unittest.IdentifierFixture()}
+		healthy := &flow.Identity{NodeID: unittest.IdentifierFixture()}
+		nodes := flow.IdentityList{failing, healthy}
+		request(t, health, healthy.NodeID, time.Second, nil)
+
+		// the circuit opens after the failure threshold
+		request(t, health, failing.NodeID, time.Millisecond, unavailable)
+		assert.Len(t, health.Select(nodes, 2), 2)
+		request(t, health, failing.NodeID, time.Millisecond, unavailable)
+		assert.Equal(t, flow.IdentityList{healthy}, health.Select(nodes, 2))
+
+		// suspended nodes are selected if no other nodes are available
+		assert.Equal(t, flow.IdentityList{failing}, health.Select(flow.IdentityList{failing}, 2))
+
+		// failures while the node is suspended do not extend the suspension
+		request(t, health, failing.NodeID, time.Millisecond, unavailable)
+
+		// after the backoff, a single failure suspends the node for twice the backoff
+		*now = now.Add(config.Backoff)
+		assert.Len(t, health.Select(nodes, 2), 2)
+		request(t, health, failing.NodeID, time.Millisecond, unavailable)
+		*now = now.Add(config.Backoff)
+		assert.Equal(t, flow.IdentityList{healthy}, health.Select(nodes, 2))
+
+		// the backoff is capped
+		*now = now.Add(config.Backoff)
+		request(t, health, failing.NodeID, time.Millisecond, unavailable)
+		status := health.Status()
+		require.Len(t, status, 2)
+		for _, s := range status {
+			if s.NodeID == failing.NodeID {
+				assert.True(t, s.CircuitOpen)
+				assert.Equal(t, now.Add(config.MaxBackoff), s.CircuitOpenUntil)
+			}
+		}
+
+		// a successful probe after the backoff closes the circuit
+		*now = now.Add(config.MaxBackoff)
+		request(t, health, failing.NodeID, time.Millisecond, nil)
+		*now = now.Add(time.Millisecond)
+		request(t, health, failing.NodeID, time.Millisecond, unavailable)
+		assert.Len(t, health.Select(nodes, 2), 2)
+	})
+
+	t.Run("lagging nodes are selected last", func(t *testing.T) {
+		health, _ := newHealth()
+		lagging := &flow.Identity{NodeID: unittest.IdentifierFixture()}
+		synced := &flow.Identity{NodeID: unittest.IdentifierFixture()}
+		nodes := flow.IdentityList{lagging, synced}
+
+		request(t, health, lagging.NodeID, time.Millisecond, nil)
+		request(t, health, synced.NodeID, time.Second, nil)
+		health.ReportSealed(100)
+		health.ReportExecuted(lagging.NodeID, 100)
+		health.ReportExecuted(synced.NodeID, 100)
+		assert.Equal(t, flow.IdentityList{lagging, synced}, health.Select(nodes, 2))
+
+		health.ReportSealed(120)
+		health.ReportExecuted(synced.NodeID, 120)
+		assert.Equal(t, flow.IdentityList{synced, lagging}, health.Select(nodes, 2))
+
+		for _, s := range health.Status() {
+			if s.NodeID == lagging.NodeID {
+				assert.Equal(t, uint64(20), s.Lag)
+			}
+		}
+	})
+
+	t.Run("only the probe ends a suspension", func(t *testing.T) {
+		health, now := newHealth()
+		nodeID := unittest.IdentifierFixture()
+
+		// a slow request is sent before the node is suspended
+		allowed, slowRequestProbe := health.Allow(nodeID)
+		require.True(t, allowed)
+		require.False(t, slowRequestProbe)
+
+		request(t, health, nodeID, time.Millisecond, unavailable)
+		request(t, health, nodeID, time.Millisecond, unavailable)
+
+		// its success says nothing about the node's current health
+		*now = now.Add(config.Backoff)
+		health.ReportResult(nodeID, time.Second, slowRequestProbe, nil)
+		allowed, probe := health.Allow(nodeID)
+		require.True(t, allowed)
+		assert.True(t, probe)
+
+		// the node stays suspended while the probe is in flight
+		require.Len(t, health.Status(), 1)
+		assert.True(t, health.Status()[0].CircuitOpen)
+
+		health.ReportResult(nodeID, time.Millisecond, probe, nil)
+		assert.False(t, health.Status()[0].CircuitOpen)
+	})
+
+	t.Run("circuit breaker rejects requests to suspended nodes", func(t *testing.T) {
+		health, now := newHealth()
+		health.config.CircuitBreakerEnabled = true
+		nodeID := unittest.IdentifierFixture()
+
+		request(t, health, nodeID, time.Millisecond, unavailable)
+		request(t, health, nodeID, time.Millisecond, unavailable)
+		allowed, _ := health.Allow(nodeID)
+		assert.False(t, allowed)
+
+		// a single probe is let through after the backoff
+		*now = now.Add(config.Backoff)
+		allowed, probe := health.Allow(nodeID)
+		require.True(t, allowed)
+		require.True(t, probe)
+		allowed, _ = health.Allow(nodeID)
+		assert.False(t, allowed)
+
+		// a cancelled probe lets the next request probe
+		health.ReportResult(nodeID, time.Millisecond, probe, status.Error(codes.Canceled, "canceled"))
+		allowed, probe = health.Allow(nodeID)
+		require.True(t, allowed)
+		require.True(t, probe)
+
+		health.ReportResult(nodeID, time.Millisecond, probe, nil)
+		allowed, probe = health.Allow(nodeID)
+		assert.True(t, allowed)
+		assert.False(t, probe)
+	})
+
+	t.Run("nil tracker selects random nodes", func(t *testing.T) {
+		var health *ExecutionNodeHealth
+		nodes := unittest.IdentifierListFixture(5)
+		identities := make(flow.IdentityList, len(nodes))
+		for i, nodeID := range nodes {
+			identities[i] = &flow.Identity{NodeID: nodeID}
+		}
+
+		health.ReportResult(nodes[0], time.Millisecond, false, unavailable)
+		assert.Len(t, health.Select(identities, 3), 3)
+		assert.Nil(t, health.Status())
+	})
+}
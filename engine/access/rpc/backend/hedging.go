@@ -0,0 +1,87 @@
+package backend
+
+import (
+	"context"
+	"time"
+)
+
+// HedgedCall sends a read-only request to the given nodes, in order, and passes the result of each
+// call to handle in the order the calls complete. The next node is called as soon as a call fails,
+// or when no call completed within delay, so that a single slow or hung node does not stall the
+// request until it times out. HedgedCall returns once handle returns true or all calls completed,
+// and cancels the calls that are still in flight.
+//
+// A delay of 0 disables hedging, in which case the nodes are called one after the other.
+//
+// handle is always called from the calling goroutine, so it can access the caller's state without
+// synchronization. Only read-only requests may be hedged, since the same request may be processed
+// by several nodes.
+func HedgedCall[N any, T any](
+	ctx context.Context,
+	delay time.Duration,
+	nodes []N,
+	call func(ctx context.Context, node N) (T, error),
+	handle func(node N, result T, err error) bool,
+) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type hedgedResult struct {
+		node   N
+		result T
+		err    error
+	}
+
+	// the channel is large enough to hold a result for every node, so calls still in flight when
+	// HedgedCall returns never block
+	results := make(chan hedgedResult, len(nodes))
+
+	var timer *time.Timer
+	var hedge <-chan time.Time
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	next := 0
+	inFlight := 0
+	callNext := func() {
+		node := nodes[next]
+		next++
+		inFlight++
+		go func() {
+			result, err := call(ctx, node)
+			results <- hedgedResult{node: node, result: result, err: err}
+		}()
+
+		// hedge the request with the next node if no call completes within the delay
+		if timer != nil {
+			timer.Stop()
+		}
+		hedge = nil
+		if delay > 0 && next < len(nodes) {
+			timer = time.NewTimer(delay)
+			hedge = timer.C
+		}
+	}
+
+	for next < len(nodes) || inFlight > 0 {
+		if inFlight == 0 {
+			callNext()
+		}
+
+		select {
+		case r := <-results:
+			inFlight--
+			if handle(r.node, r.result, r.err) {
+				return
+			}
+			if r.err != nil && next < len(nodes) {
+				callNext()
+			}
+		case <-hedge:
+			callNext()
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHedgedCall(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	// call returns the result of the node after its delay, or an error if the call is cancelled
+	type node struct {
+		name  string
+		delay time.Duration
+		err   error
+	}
+	call := func(ctx context.Context, n node) (string, error) {
+		select {
+		case <-time.After(n.delay):
+			return n.name, n.err
+		case <-ctx.Done():
+			return "", status.Error(codes.Canceled, ctx.Err().Error())
+		}
+	}
+
+	t.Run("without hedging nodes are called one after the other", func(t *testing.T) {
+		nodes := []node{
+			{name: "slow", delay: 100 * time.Millisecond, err: unavailable},
+			{name: "fast", delay: time.Millisecond},
+		}
+
+		var handled []string
+		HedgedCall(context.Background(), 0, nodes, call, func(n node, result string, err error) bool {
+			handled = append(handled, n.name)
+			return err == nil
+		})
+		assert.Equal(t, []string{"slow", "fast"}, handled)
+	})
+
+	t.Run("slow requests are hedged", func(t *testing.T) {
+		nodes := []node{
+			{name: "hung", delay: time.Minute},
+			{name: "fast", delay: time.Millisecond},
+			{name: "unused", delay: time.Millisecond},
+		}
+
+		start := time.Now()
+		var result string
+		HedgedCall(context.Background(), 10*time.Millisecond, nodes, call, func(n node, r string, err error) bool {
+			result = r
+			return err == nil
+		})
+		assert.Equal(t, "fast", result)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("failed requests are retried immediately", func(t *testing.T) {
+		nodes := []node{
+			{name: "failing", delay: time.Millisecond, err: unavailable},
+			{name: "fast", delay: time.Millisecond},
+		}
+
+		var handled []string
+		HedgedCall(context.Background(), time.Minute, nodes, call, func(n node, result string, err error) bool {
+			handled = append(handled, n.name)
+			return err == nil
+		})
+		assert.Equal(t, []string{"failing", "fast"}, handled)
+	})
+
+	t.Run("all results are handled if no call succeeds", func(t *testing.T) {
+		nodes := []node{
+			{name: "a", delay: 20 * time.Millisecond, err: unavailable},
+			{name: "b", delay: time.Millisecond, err: unavailable},
+		}
+
+		var handled []string
+		HedgedCall(context.Background(), 5*time.Millisecond, nodes, call, func(n node, result string, err error) bool {
+			handled = append(handled, n.name)
+			return err == nil
+		})
+		assert.ElementsMatch(t, []string{"a", "b"}, handled)
+	})
+}
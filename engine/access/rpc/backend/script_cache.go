@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+)
+
+// scriptCacheKey identifies the result of executing a script with the given arguments at a block.
+type scriptCacheKey struct {
+	blockID       flow.Identifier
+	scriptHash    [sha256.Size]byte
+	argumentsHash [sha256.Size]byte
+}
+
+// ScriptCache is a bounded cache of the results of scripts executed against sealed blocks. The
+// result of a script at a sealed block never changes, so repeated executions can be served from
+// the cache without querying execution nodes.
+// ScriptCache is safe for concurrent use.
+type ScriptCache struct {
+	cache   *lru.Cache
+	metrics module.AccessMetrics
+}
+
+// NewScriptCache creates a new script result cache holding at most size results. When the cache
+// is full, the least recently used result is evicted.
+func NewScriptCache(size uint, metrics module.AccessMetrics) (*ScriptCache, error) {
+	cache, err := lru.New(int(size))
+	if err != nil {
+		return nil, fmt.Errorf("could not create script result cache: %w", err)
+	}
+
+	return &ScriptCache{
+		cache:   cache,
+		metrics: metrics,
+	}, nil
+}
+
+// Get returns the cached result of executing the script with the given arguments at the block.
+func (c *ScriptCache) Get(blockID flow.Identifier, script []byte, arguments [][]byte) ([]byte, bool) {
+	value, ok := c.cache.Get(newScriptCacheKey(blockID, script, arguments))
+	if !ok {
+		c.metrics.ScriptResultCacheMiss()
+		return nil, false
+	}
+
+	c.metrics.ScriptResultCacheHit()
+	return value.([]byte), true
+}
+
+// Add caches the result of executing the script with the given arguments at the block. The block
+// must be sealed.
+func (c *ScriptCache) Add(blockID flow.Identifier, script []byte, arguments [][]byte, result []byte) {
+	c.cache.Add(newScriptCacheKey(blockID, script, arguments), result)
+}
+
+// Invalidate removes all cached results for the block, and returns the number of removed results.
+func (c *ScriptCache) Invalidate(blockID flow.Identifier) int {
+	removed := 0
+	for _, key := range c.cache.Keys() {
+		if key.(scriptCacheKey).blockID != blockID {
+			continue
+		}
+		if c.cache.Remove(key) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// InvalidateAll removes all cached results, and returns the number of removed results.
+func (c *ScriptCache) InvalidateAll() int {
+	removed := c.cache.Len()
+	c.cache.Purge()
+	return removed
+}
+
+// Len returns the number of cached results.
+func (c *ScriptCache) Len() int {
+	return c.cache.Len()
+}
+
+func newScriptCacheKey(blockID flow.Identifier, script []byte, arguments [][]byte) scriptCacheKey {
+	// arguments are length-prefixed, so that moving bytes between arguments changes the hash
+	argumentsHasher := sha256.New()
+	length := make([]byte, 8)
+	for _, argument := range arguments {
+		binary.BigEndian.PutUint64(length, uint64(len(argument)))
+		_, _ = argumentsHasher.Write(length)
+		_, _ = argumentsHasher.Write(argument)
+	}
+
+	key := scriptCacheKey{
+		blockID:    blockID,
+		scriptHash: sha256.Sum256(script),
+	}
+	copy(key.argumentsHash[:], argumentsHasher.Sum(nil))
+
+	return key
+}
@@ -0,0 +1,137 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	modulemock "github.com/onflow/flow-go/module/mock"
+	protocol "github.com/onflow/flow-go/state/protocol/mock"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestScriptCache(t *testing.T) {
+	accessMetrics := modulemock.NewAccessMetrics(t)
+	cache, err := NewScriptCache(10, accessMetrics)
+	require.NoError(t, err)
+
+	blockID := unittest.IdentifierFixture()
+	script := []byte("pub fun main(a: Int, b: Int): Int { return a + b }")
+	arguments := [][]byte{[]byte("1"), []byte("23")}
+	result := []byte("24")
+
+	accessMetrics.On("ScriptResultCacheMiss").Return().Once()
+	_, ok := cache.Get(blockID, script, arguments)
+	assert.False(t, ok)
+
+	cache.Add(blockID, script, arguments, result)
+
+	accessMetrics.On("ScriptResultCacheHit").Return().Once()
+	actual, ok := cache.Get(blockID, script, arguments)
+	require.True(t, ok)
+	assert.Equal(t, result, actual)
+
+	// moving bytes between arguments results in a different key
+	accessMetrics.On("ScriptResultCacheMiss").Return().Once()
+	_, ok = cache.Get(blockID, script, [][]byte{[]byte("12"), []byte("3")})
+	assert.False(t, ok)
+
+	// results are scoped to the block
+	accessMetrics.On("ScriptResultCacheMiss").Return().Once()
+	_, ok = cache.Get(unittest.IdentifierFixture(), script, arguments)
+	assert.False(t, ok)
+}
+
+// countingScriptExecutor counts the scripts executed locally, and returns the height as the result.
+type countingScriptExecutor struct {
+	executed int
+}
+
+func (e *countingScriptExecutor) ExecuteAtBlockHeight(_ context.Context, _ []byte, _ [][]byte, height uint64) ([]byte, error) {
+	e.executed++
+	return []byte{byte(height)}, nil
+}
+
+func (e *countingScriptExecutor) GetAccountAtBlockHeight(_ context.Context, _ flow.Address, _ uint64) (*flow.Account, error) {
+	return nil, nil
+}
+
+func (e *countingScriptExecutor) SimulateTransactionAtBlockHeight(_ context.Context, _ *flow.TransactionBody, _ uint64, _ bool) (*flow.TransactionSimulationResult, error) {
+	return nil, nil
+}
+
+func (e *countingScriptExecutor) EstimateTransactionFeesAtBlockHeight(_ context.Context, _ *flow.TransactionBody, _ uint64) (*flow.TransactionFeesEstimate, error) {
+	return nil, nil
+}
+
+func TestExecuteScriptWithCache(t *testing.T) {
+	sealed := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(10))
+	unsealed := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(11))
+	fork := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(10))
+
+	snapshot := protocol.NewSnapshot(t)
+	snapshot.On("Head").Return(sealed, nil)
+	state := protocol.NewState(t)
+	state.On("Sealed").Return(snapshot)
+
+	headers := storagemock.NewHeaders(t)
+	headers.On("ByBlockID", sealed.ID()).Return(sealed, nil)
+	headers.On("ByBlockID", unsealed.ID()).Return(unsealed, nil)
+	headers.On("ByBlockID", fork.ID()).Return(fork, nil)
+	headers.On("ByHeight", sealed.Height).Return(sealed, nil)
+
+	cache, err := NewScriptCache(10, metrics.NewNoopCollector())
+	require.NoError(t, err)
+
+	executor := &countingScriptExecutor{}
+	backend := backendScripts{
+		headers:        headers,
+		state:          state,
+		log:            zerolog.Nop(),
+		metrics:        metrics.NewNoopCollector(),
+		scriptExecutor: executor,
+		scriptCache:    cache,
+	}
+
+	ctx := context.Background()
+	script := []byte("pub fun main() {}")
+
+	t.Run("results at sealed blocks are cached", func(t *testing.T) {
+		executor.executed = 0
+		for i := 0; i < 3; i++ {
+			result, err := backend.ExecuteScriptAtBlockID(ctx, sealed.ID(), script, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []byte{10}, result)
+		}
+		assert.Equal(t, 1, executor.executed)
+
+		// the latest sealed block shares the cached result
+		_, err := backend.ExecuteScriptAtLatestBlock(ctx, script, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1, executor.executed)
+	})
+
+	t.Run("results at unsealed blocks are not cached", func(t *testing.T) {
+		executor.executed = 0
+		for i := 0; i < 3; i++ {
+			_, err := backend.ExecuteScriptAtBlockID(ctx, unsealed.ID(), script, nil)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 3, executor.executed)
+	})
+
+	t.Run("results at forks below the sealed height are not cached", func(t *testing.T) {
+		executor.executed = 0
+		for i := 0; i < 3; i++ {
+			_, err := backend.ExecuteScriptAtBlockID(ctx, fork.ID(), script, nil)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 3, executor.executed)
+	})
+}
@@ -47,6 +47,9 @@ type Config struct {
 	MaxHeightRange            uint                             // max size of height range requests
 	PreferredExecutionNodeIDs []string                         // preferred list of upstream execution node IDs
 	FixedExecutionNodeIDs     []string                         // fixed list of execution node IDs to choose from if no node node ID can be chosen from the PreferredExecutionNodeIDs
+	CircuitBreakerConfig      backend.CircuitBreakerConfig     // circuit breaker configuration for connections to collection nodes, execution nodes are guarded by BackendOptions.ExecutionNodeHealth
+	HedgeDelay                time.Duration                    // delay after which read-only requests are also sent to the next execution node (0 disables hedged requests)
+	BackendOptions            backend.Options                  // optional dependencies of the backend, e.g. a local script executor
 }
 
 // Engine exposes the server with a simplified version of the Access API.
@@ -167,9 +170,13 @@ func NewBuilder(log zerolog.Logger,
 		CacheSize:                 cacheSize,
 		AccessMetrics:             accessMetrics,
 		Log:                       log,
+		CircuitBreakerConfig:      config.CircuitBreakerConfig,
 	}
 
-	backend := backend.New(state,
+	backendOptions := config.BackendOptions
+	backendOptions.HedgeDelay = config.HedgeDelay
+
+	backend := backend.NewWithOptions(state,
 		collectionRPC,
 		historicalAccessNodes,
 		blocks,
@@ -187,6 +194,7 @@ func NewBuilder(log zerolog.Logger,
 		config.FixedExecutionNodeIDs,
 		log,
 		backend.DefaultSnapshotHistoryLimit,
+		backendOptions,
 	)
 
 	eng := &Engine{
@@ -279,6 +287,9 @@ func (e *Engine) process(event interface{}) error {
 	case *flow.Block:
 		e.backend.NotifyFinalizedBlockHeight(entity.Header.Height)
 		return nil
+	case *flow.ExecutionReceipt:
+		e.backend.NotifyExecutionReceipt()
+		return nil
 	default:
 		return fmt.Errorf("invalid event type (%T)", event)
 	}
@@ -2,9 +2,14 @@ package state_stream
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/rs/zerolog"
+	"go.uber.org/atomic"
 
+	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/common/rpc"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
@@ -12,55 +17,118 @@ import (
 	"github.com/onflow/flow-go/storage"
 )
 
+const (
+	// DefaultSendTimeout is the default timeout for sending a message to the client. After the timeout
+	// expires, the connection is closed.
+	DefaultSendTimeout = 30 * time.Second
+)
+
 type API interface {
 	GetExecutionDataByBlockID(ctx context.Context, blockID flow.Identifier) (*entities.BlockExecutionData, error)
+	SubscribeExecutionData(ctx context.Context, startBlockID flow.Identifier, startBlockHeight uint64) Subscription
+	SubscribeEvents(ctx context.Context, startBlockID flow.Identifier, startHeight uint64, filter EventFilter) Subscription
 }
 
 type StateStreamBackend struct {
+	log           zerolog.Logger
 	headers       storage.Headers
 	seals         storage.Seals
 	results       storage.ExecutionResults
 	execDataStore execution_data.ExecutionDataStore
+	broadcaster   *engine.Broadcaster
+
+	// rootHeight is the lowest height for which execution data may be available locally
+	rootHeight uint64
+
+	// highestHeight is the highest height for which execution data has been received and is
+	// available locally
+	highestHeight *atomic.Uint64
+
+	sendTimeout    time.Duration
+	sendBufferSize int
 }
 
 func New(
+	log zerolog.Logger,
+	config Config,
 	headers storage.Headers,
 	seals storage.Seals,
 	results storage.ExecutionResults,
 	execDataStore execution_data.ExecutionDataStore,
+	broadcaster *engine.Broadcaster,
+	rootHeight uint64,
+	highestAvailableHeight uint64,
 ) *StateStreamBackend {
 	return &StateStreamBackend{
-		headers:       headers,
-		seals:         seals,
-		results:       results,
-		execDataStore: execDataStore,
+		log:            log.With().Str("module", "state_stream_api").Logger(),
+		headers:        headers,
+		seals:          seals,
+		results:        results,
+		execDataStore:  execDataStore,
+		broadcaster:    broadcaster,
+		rootHeight:     rootHeight,
+		highestHeight:  atomic.NewUint64(highestAvailableHeight),
+		sendTimeout:    config.ClientSendTimeout,
+		sendBufferSize: int(config.ClientSendBufferSize),
 	}
 }
 
 func (s *StateStreamBackend) GetExecutionDataByBlockID(ctx context.Context, blockID flow.Identifier) (*entities.BlockExecutionData, error) {
-	header, err := s.headers.ByBlockID(blockID)
+	blockExecData, err := s.getExecutionData(ctx, blockID)
 	if err != nil {
+		if execution_data.IsBlobNotFoundError(err) || execution_data.IsMalformedDataError(err) {
+			return nil, err
+		}
 		return nil, rpc.ConvertStorageError(err)
 	}
 
+	message, err := convert.BlockExecutionDataToMessage(blockExecData)
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// getExecutionData returns the execution data for the sealed block with the given ID from the
+// local execution data store.
+// Expected errors:
+// - storage.ErrNotFound if the block or its seal, or execution result are not found
+// - execution_data.BlobNotFoundError if the execution data is not available locally
+func (s *StateStreamBackend) getExecutionData(ctx context.Context, blockID flow.Identifier) (*execution_data.BlockExecutionData, error) {
+	header, err := s.headers.ByBlockID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get block header for %s: %w", blockID, err)
+	}
+
 	seal, err := s.seals.FinalizedSealForBlock(header.ID())
 	if err != nil {
-		return nil, rpc.ConvertStorageError(err)
+		return nil, fmt.Errorf("could not get finalized seal for block %s: %w", blockID, err)
 	}
 
 	result, err := s.results.ByID(seal.ResultID)
 	if err != nil {
-		return nil, rpc.ConvertStorageError(err)
+		return nil, fmt.Errorf("could not get execution result (id: %s): %w", seal.ResultID, err)
 	}
 
 	blockExecData, err := s.execDataStore.GetExecutionData(ctx, result.ExecutionDataID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("could not get execution data (id: %s): %w", result.ExecutionDataID, err)
 	}
 
-	message, err := convert.BlockExecutionDataToMessage(blockExecData)
-	if err != nil {
-		return nil, err
+	return blockExecData, nil
+}
+
+// setHighestHeight sets the highest height for which execution data is available locally. The
+// update is ignored if the height is not greater than the current value.
+// Returns true if the value was updated.
+func (s *StateStreamBackend) setHighestHeight(height uint64) bool {
+	for {
+		current := s.highestHeight.Load()
+		if height <= current {
+			return false
+		}
+		if s.highestHeight.CAS(current, height) {
+			return true
+		}
 	}
-	return message, nil
 }
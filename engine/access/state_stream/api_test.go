@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/testutils"
@@ -46,7 +47,17 @@ func (suite *Suite) TestGetExecutionDataByBlockID() {
 	// create the handler with the mock
 	bs := blobs.NewBlobstore(dssync.MutexWrap(datastore.NewMapDatastore()))
 	eds := execution_data.NewExecutionDataStore(bs, execution_data.DefaultSerializer)
-	client := New(suite.headers, suite.seals, suite.results, eds)
+	client := New(
+		unittest.Logger(),
+		Config{},
+		suite.headers,
+		suite.seals,
+		suite.results,
+		eds,
+		engine.NewBroadcaster(),
+		0,
+		0,
+	)
 
 	// mock parameters
 	ctx := context.Background()
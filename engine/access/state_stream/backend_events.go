@@ -0,0 +1,58 @@
+package state_stream
+
+import (
+	"context"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// EventsResponse is the response sent to SubscribeEvents subscribers for each sealed block. Events
+// contains the block's events that match the subscription's filter, and may be empty.
+type EventsResponse struct {
+	BlockID flow.Identifier
+	Height  uint64
+	Events  flow.EventsList
+}
+
+// SubscribeEvents streams the events matching the filter for all sealed blocks starting at the
+// requested block, in height order. Events are read from the locally downloaded execution data,
+// so no requests are made to execution nodes.
+//
+// A response is sent for every block, even if no events matched, so clients can track their
+// progress and resume from the next height after a disconnect. Start block semantics are the same
+// as for SubscribeExecutionData.
+func (s *StateStreamBackend) SubscribeEvents(ctx context.Context, startBlockID flow.Identifier, startHeight uint64, filter EventFilter) Subscription {
+	nextHeight, err := s.getStartHeight(startBlockID, startHeight)
+	if err != nil {
+		return NewFailedSubscription(err, "could not get start height")
+	}
+
+	sub := NewHeightBasedSubscription(s.sendBufferSize, nextHeight, s.getEventsResponseFactory(filter))
+
+	go NewStreamer(s.log, s.broadcaster, s.sendTimeout, sub).Stream(ctx)
+
+	return sub
+}
+
+// getEventsResponseFactory returns a GetDataByHeightFunc that returns the filtered events for the
+// sealed block at a given height.
+func (s *StateStreamBackend) getEventsResponseFactory(filter EventFilter) GetDataByHeightFunc {
+	return func(ctx context.Context, height uint64) (interface{}, error) {
+		v, err := s.getExecutionDataResponse(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+		executionData := v.(*ExecutionDataResponse).ExecutionData
+
+		events := flow.EventsList{}
+		for _, chunkExecutionData := range executionData.ChunkExecutionDatas {
+			events = append(events, filter.Filter(chunkExecutionData.Events)...)
+		}
+
+		return &EventsResponse{
+			BlockID: executionData.BlockID,
+			Height:  height,
+			Events:  events,
+		}, nil
+	}
+}
@@ -0,0 +1,84 @@
+package state_stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+type BackendEventsSuite struct {
+	BackendExecutionDataSuite
+}
+
+func TestBackendEventsSuite(t *testing.T) {
+	suite.Run(t, new(BackendEventsSuite))
+}
+
+// TestSubscribeEvents tests that events matching the filter are streamed for each block in height order
+func (s *BackendEventsSuite) TestSubscribeEvents() {
+	chain := flow.Testnet.Chain()
+
+	tests := []struct {
+		name       string
+		eventTypes []string
+		expected   func(events flow.EventsList) flow.EventsList
+	}{
+		{
+			name: "all events",
+			expected: func(events flow.EventsList) flow.EventsList {
+				return events
+			},
+		},
+		{
+			name:       "filtered by event type",
+			eventTypes: []string{string(testEventTypes[0])},
+			expected: func(events flow.EventsList) flow.EventsList {
+				return events[:1]
+			},
+		},
+		{
+			name:       "no matching events",
+			eventTypes: []string{"flow.AccountKeyAdded"},
+			expected: func(events flow.EventsList) flow.EventsList {
+				return flow.EventsList{}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			s.backend.highestHeight.Store(s.blocks[len(s.blocks)-1].Header.Height)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			filter, err := NewEventFilter(DefaultEventFilterConfig, chain, test.eventTypes, nil, nil)
+			require.NoError(s.T(), err)
+
+			sub := s.backend.SubscribeEvents(ctx, flow.ZeroID, s.blocks[0].Header.Height, filter)
+
+			for _, block := range s.blocks {
+				unittest.RequireReturnsBefore(s.T(), func() {
+					v, ok := <-sub.Channel()
+					require.True(s.T(), ok, "channel closed unexpectedly: %v", sub.Err())
+
+					resp, ok := v.(*EventsResponse)
+					require.True(s.T(), ok, "unexpected response type: %T", v)
+
+					expected := test.expected(s.execDataMap[block.ID()].ChunkExecutionDatas[0].Events)
+
+					assert.Equal(s.T(), block.ID(), resp.BlockID)
+					assert.Equal(s.T(), block.Header.Height, resp.Height)
+					assert.Equal(s.T(), expected, resp.Events)
+				}, time.Second, "timed out waiting for events")
+			}
+		})
+	}
+}
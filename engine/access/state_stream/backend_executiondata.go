@@ -0,0 +1,112 @@
+package state_stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/engine/common/rpc"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/storage"
+)
+
+// ExecutionDataResponse is the response sent to SubscribeExecutionData subscribers for each
+// sealed block, in height order.
+type ExecutionDataResponse struct {
+	Height        uint64
+	ExecutionData *execution_data.BlockExecutionData
+}
+
+// SubscribeExecutionData streams the execution data for all sealed blocks starting at the
+// requested block, in height order. New data is sent as soon as it is received by the execution
+// data requester.
+//
+// Only one of startBlockID and startHeight may be set. If neither is set, streaming starts from the
+// highest block for which execution data is available locally. Clients can resume a stream after
+// a disconnect by subscribing again from the height after the last one they received.
+func (s *StateStreamBackend) SubscribeExecutionData(ctx context.Context, startBlockID flow.Identifier, startHeight uint64) Subscription {
+	nextHeight, err := s.getStartHeight(startBlockID, startHeight)
+	if err != nil {
+		return NewFailedSubscription(err, "could not get start height")
+	}
+
+	sub := NewHeightBasedSubscription(s.sendBufferSize, nextHeight, s.getExecutionDataResponse)
+
+	go NewStreamer(s.log, s.broadcaster, s.sendTimeout, sub).Stream(ctx)
+
+	return sub
+}
+
+// getExecutionDataResponse returns the ExecutionDataResponse for the sealed block at the given
+// height.
+// Expected errors:
+// - storage.ErrNotFound if execution data for the height has not been received yet
+// - execution_data.BlobNotFoundError if the execution data is not available locally
+func (s *StateStreamBackend) getExecutionDataResponse(ctx context.Context, height uint64) (interface{}, error) {
+	if height > s.highestHeight.Load() {
+		return nil, fmt.Errorf("execution data for block %d is not available yet: %w", height, storage.ErrNotFound)
+	}
+
+	header, err := s.headers.ByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get block header for height %d: %w", height, err)
+	}
+
+	executionData, err := s.getExecutionData(ctx, header.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecutionDataResponse{
+		Height:        height,
+		ExecutionData: executionData,
+	}, nil
+}
+
+// getStartHeight returns the height of the first block to stream, based on the provided start
+// block ID or height.
+// Expected errors:
+// - codes.InvalidArgument if both startBlockID and startHeight are provided, or the start height
+// is below the lowest height with execution data available
+// - codes.NotFound if the start block is not found
+func (s *StateStreamBackend) getStartHeight(startBlockID flow.Identifier, startHeight uint64) (uint64, error) {
+	// make sure only one of start block ID and start height is provided
+	if startBlockID != flow.ZeroID && startHeight > 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "only one of start block ID and start height may be provided")
+	}
+
+	// if the start block is provided, use its height
+	if startBlockID != flow.ZeroID {
+		header, err := s.headers.ByBlockID(startBlockID)
+		if err != nil {
+			return 0, rpc.ConvertStorageError(fmt.Errorf("could not get header for block %v: %w", startBlockID, err))
+		}
+		startHeight = header.Height
+	}
+
+	// if neither is provided, start from the latest block with execution data available
+	// never start below the root height, even if no execution data was received yet
+	if startHeight == 0 {
+		highestHeight := s.highestHeight.Load()
+		if highestHeight < s.rootHeight {
+			return s.rootHeight, nil
+		}
+		return highestHeight, nil
+	}
+
+	if startHeight < s.rootHeight {
+		return 0, status.Errorf(codes.InvalidArgument, "start height must be greater than or equal to the root height %d", s.rootHeight)
+	}
+
+	return startHeight, nil
+}
+
+// isNotAvailableError returns true if the error indicates that the requested data is not (yet)
+// available locally.
+func isNotAvailableError(err error) bool {
+	return errors.Is(err, storage.ErrNotFound) || execution_data.IsBlobNotFoundError(err)
+}
@@ -0,0 +1,264 @@
+package state_stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/blobs"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/storage"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+var testEventTypes = []flow.EventType{
+	"A.7e60df042a9c0868.FlowToken.TokensDeposited",
+	"A.9a0766d93b6608b7.FungibleToken.Withdrawn",
+	"flow.AccountCreated",
+}
+
+type BackendExecutionDataSuite struct {
+	suite.Suite
+
+	headers *storagemock.Headers
+	seals   *storagemock.Seals
+	results *storagemock.ExecutionResults
+
+	eds         execution_data.ExecutionDataStore
+	broadcaster *engine.Broadcaster
+	backend     *StateStreamBackend
+
+	blocks      []*flow.Block
+	blockMap    map[uint64]*flow.Block
+	sealMap     map[flow.Identifier]*flow.Seal
+	resultMap   map[flow.Identifier]*flow.ExecutionResult
+	execDataMap map[flow.Identifier]*execution_data.BlockExecutionData
+}
+
+func TestBackendExecutionDataSuite(t *testing.T) {
+	suite.Run(t, new(BackendExecutionDataSuite))
+}
+
+func (s *BackendExecutionDataSuite) SetupTest() {
+	s.headers = storagemock.NewHeaders(s.T())
+	s.seals = storagemock.NewSeals(s.T())
+	s.results = storagemock.NewExecutionResults(s.T())
+
+	bs := blobs.NewBlobstore(dssync.MutexWrap(datastore.NewMapDatastore()))
+	s.eds = execution_data.NewExecutionDataStore(bs, execution_data.DefaultSerializer)
+	s.broadcaster = engine.NewBroadcaster()
+
+	blockCount := 5
+	s.blocks = make([]*flow.Block, 0, blockCount)
+	s.blockMap = make(map[uint64]*flow.Block, blockCount)
+	s.sealMap = make(map[flow.Identifier]*flow.Seal, blockCount)
+	s.resultMap = make(map[flow.Identifier]*flow.ExecutionResult, blockCount)
+	s.execDataMap = make(map[flow.Identifier]*execution_data.BlockExecutionData, blockCount)
+
+	rootBlock := unittest.BlockFixture()
+	parent := rootBlock.Header
+	for i := 0; i < blockCount; i++ {
+		block := unittest.BlockWithParentFixture(parent)
+		parent = block.Header
+
+		chunkData := generateChunkExecutionData(s.T(), 0)
+		chunkData.Events = flow.EventsList{
+			unittest.EventFixture(testEventTypes[0], 0, 0, unittest.IdentifierFixture(), 0),
+			unittest.EventFixture(testEventTypes[1], 0, 1, unittest.IdentifierFixture(), 0),
+			unittest.EventFixture(testEventTypes[2], 1, 0, unittest.IdentifierFixture(), 0),
+		}
+
+		execData := &execution_data.BlockExecutionData{
+			BlockID:             block.ID(),
+			ChunkExecutionDatas: []*execution_data.ChunkExecutionData{chunkData},
+		}
+		execDataID, err := s.eds.AddExecutionData(context.Background(), execData)
+		require.NoError(s.T(), err)
+
+		result := unittest.ExecutionResultFixture(unittest.WithExecutionResultBlockID(block.ID()))
+		result.ExecutionDataID = execDataID
+		seal := unittest.Seal.Fixture(unittest.Seal.WithResult(result))
+
+		s.blocks = append(s.blocks, block)
+		s.blockMap[block.Header.Height] = block
+		s.sealMap[block.ID()] = seal
+		s.resultMap[seal.ResultID] = result
+		s.execDataMap[block.ID()] = execData
+	}
+
+	s.headers.On("ByHeight", mock.AnythingOfType("uint64")).Return(
+		func(height uint64) *flow.Header {
+			if block, ok := s.blockMap[height]; ok {
+				return block.Header
+			}
+			return nil
+		},
+		func(height uint64) error {
+			if _, ok := s.blockMap[height]; ok {
+				return nil
+			}
+			return storage.ErrNotFound
+		},
+	).Maybe()
+
+	s.headers.On("ByBlockID", mock.AnythingOfType("flow.Identifier")).Return(
+		func(blockID flow.Identifier) *flow.Header {
+			for _, block := range s.blocks {
+				if block.ID() == blockID {
+					return block.Header
+				}
+			}
+			return nil
+		},
+		func(blockID flow.Identifier) error {
+			for _, block := range s.blocks {
+				if block.ID() == blockID {
+					return nil
+				}
+			}
+			return storage.ErrNotFound
+		},
+	).Maybe()
+
+	s.seals.On("FinalizedSealForBlock", mock.AnythingOfType("flow.Identifier")).Return(
+		func(blockID flow.Identifier) *flow.Seal {
+			return s.sealMap[blockID]
+		},
+		func(blockID flow.Identifier) error {
+			if _, ok := s.sealMap[blockID]; ok {
+				return nil
+			}
+			return storage.ErrNotFound
+		},
+	).Maybe()
+
+	s.results.On("ByID", mock.AnythingOfType("flow.Identifier")).Return(
+		func(resultID flow.Identifier) *flow.ExecutionResult {
+			return s.resultMap[resultID]
+		},
+		func(resultID flow.Identifier) error {
+			if _, ok := s.resultMap[resultID]; ok {
+				return nil
+			}
+			return storage.ErrNotFound
+		},
+	).Maybe()
+
+	// only the first block's execution data has been received when the backend starts
+	s.backend = New(
+		unittest.Logger(),
+		Config{
+			ClientSendTimeout:    DefaultSendTimeout,
+			ClientSendBufferSize: DefaultSendBufferSize,
+		},
+		s.headers,
+		s.seals,
+		s.results,
+		s.eds,
+		s.broadcaster,
+		s.blocks[0].Header.Height,
+		s.blocks[0].Header.Height,
+	)
+}
+
+// TestSubscribeExecutionData tests that execution data is streamed in height order, both for data
+// that was available when the subscription started and for data received afterwards.
+func (s *BackendExecutionDataSuite) TestSubscribeExecutionData() {
+	tests := []struct {
+		name          string
+		highestHeight uint64
+		startBlockID  flow.Identifier
+		startHeight   uint64
+	}{
+		{
+			name:          "start from height",
+			highestHeight: s.blocks[0].Header.Height,
+			startHeight:   s.blocks[0].Header.Height,
+		},
+		{
+			name:          "start from block ID",
+			highestHeight: s.blocks[0].Header.Height,
+			startBlockID:  s.blocks[0].ID(),
+		},
+		{
+			name:          "start from latest",
+			highestHeight: s.blocks[0].Header.Height,
+		},
+		{
+			// before any execution data was received, streaming starts at the root height
+			name:          "start from latest without execution data",
+			highestHeight: s.blocks[0].Header.Height - 1,
+		},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			s.backend.highestHeight.Store(test.highestHeight)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			sub := s.backend.SubscribeExecutionData(ctx, test.startBlockID, test.startHeight)
+
+			for _, block := range s.blocks {
+				// simulate the requester delivering new execution data
+				if block.Header.Height > test.highestHeight {
+					s.backend.setHighestHeight(block.Header.Height)
+					s.broadcaster.Publish()
+				}
+
+				unittest.RequireReturnsBefore(s.T(), func() {
+					v, ok := <-sub.Channel()
+					require.True(s.T(), ok, "channel closed unexpectedly: %v", sub.Err())
+
+					resp, ok := v.(*ExecutionDataResponse)
+					require.True(s.T(), ok, "unexpected response type: %T", v)
+
+					assert.Equal(s.T(), block.Header.Height, resp.Height)
+					assert.Equal(s.T(), s.execDataMap[block.ID()], resp.ExecutionData)
+				}, time.Second, "timed out waiting for execution data")
+			}
+
+			// once the client disconnects, the subscription is closed with an error
+			cancel()
+			unittest.RequireReturnsBefore(s.T(), func() {
+				for range sub.Channel() {
+				}
+			}, time.Second, "timed out waiting for subscription to close")
+			assert.ErrorIs(s.T(), sub.Err(), context.Canceled)
+		})
+	}
+}
+
+// TestSubscribeExecutionDataHandlesErrors tests that invalid start arguments fail the subscription
+func (s *BackendExecutionDataSuite) TestSubscribeExecutionDataHandlesErrors() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.Run("returns error if both start block ID and start height are provided", func() {
+		sub := s.backend.SubscribeExecutionData(ctx, s.blocks[0].ID(), s.blocks[0].Header.Height)
+		assert.Equal(s.T(), codes.InvalidArgument, status.Code(sub.Err()))
+	})
+
+	s.Run("returns error if start height is below the root height", func() {
+		sub := s.backend.SubscribeExecutionData(ctx, flow.ZeroID, s.blocks[0].Header.Height-1)
+		assert.Equal(s.T(), codes.InvalidArgument, status.Code(sub.Err()))
+	})
+
+	s.Run("returns error for unknown start block", func() {
+		sub := s.backend.SubscribeExecutionData(ctx, unittest.IdentifierFixture(), 0)
+		assert.Equal(s.T(), codes.NotFound, status.Code(sub.Err()))
+	})
+}
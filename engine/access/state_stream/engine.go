@@ -3,18 +3,21 @@ package state_stream
 import (
 	"fmt"
 	"net"
+	"time"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	access "github.com/onflow/flow/protobuf/go/flow/executiondata"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 
+	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/common/rpc"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module/component"
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 	"github.com/onflow/flow-go/module/irrecoverable"
 	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/logging"
 )
 
 // Config defines the configurable options for the ingress server.
@@ -22,6 +25,16 @@ type Config struct {
 	ListenAddr              string
 	MaxExecutionDataMsgSize int  // in bytes
 	RpcMetricsEnabled       bool // enable GRPC metrics
+
+	// ClientSendTimeout is the timeout for sending a message to the client. After the timeout,
+	// the stream is closed with an error.
+	ClientSendTimeout time.Duration
+
+	// ClientSendBufferSize is the size of the response buffer for sending messages to the client.
+	ClientSendBufferSize uint
+
+	// EventFilterConfig limits the size of the event filters of SubscribeEvents requests.
+	EventFilterConfig EventFilterConfig
 }
 
 // Engine exposes the server with the state stream API.
@@ -35,6 +48,10 @@ type Engine struct {
 	config  Config
 	chain   flow.Chain
 	handler *Handler
+	headers storage.Headers
+
+	// broadcaster notifies active subscriptions when new execution data is available
+	broadcaster *engine.Broadcaster
 
 	stateStreamGrpcAddress net.Addr
 }
@@ -50,6 +67,8 @@ func NewEng(
 	chainID flow.ChainID,
 	apiRatelimits map[string]int, // the api rate limit (max calls per second) for each of the gRPC API e.g. Ping->100, GetExecutionDataByBlockID->300
 	apiBurstLimits map[string]int, // the api burst limit (max calls at the same time) for each of the gRPC API e.g. Ping->50, GetExecutionDataByBlockID->10
+	rootHeight uint64, // the lowest height for which execution data may be available locally
+	highestAvailableHeight uint64, // the highest height for which execution data was already received
 ) *Engine {
 	// create a GRPC server to serve GRPC clients
 	grpcOpts := []grpc.ServerOption{
@@ -77,17 +96,26 @@ func NewEng(
 	chainedInterceptors := grpc.ChainUnaryInterceptor(interceptors...)
 	grpcOpts = append(grpcOpts, chainedInterceptors)
 
+	// the unary interceptors don't apply to the streaming endpoints
+	if config.RpcMetricsEnabled {
+		grpcOpts = append(grpcOpts, grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor))
+	}
+
 	server := grpc.NewServer(grpcOpts...)
 
-	backend := New(headers, seals, results, execDataStore)
+	broadcaster := engine.NewBroadcaster()
+
+	backend := New(log, config, headers, seals, results, execDataStore, broadcaster, rootHeight, highestAvailableHeight)
 
 	e := &Engine{
-		log:     log.With().Str("engine", "state_stream_rpc").Logger(),
-		backend: backend,
-		server:  server,
-		chain:   chainID.Chain(),
-		config:  config,
-		handler: NewHandler(backend, chainID.Chain()),
+		log:         log.With().Str("engine", "state_stream_rpc").Logger(),
+		backend:     backend,
+		server:      server,
+		chain:       chainID.Chain(),
+		config:      config,
+		handler:     NewHandler(backend, chainID.Chain(), WithEventFilterConfig(config.EventFilterConfig)),
+		headers:     headers,
+		broadcaster: broadcaster,
 	}
 
 	e.ComponentManager = component.NewComponentManagerBuilder().
@@ -98,6 +126,32 @@ func NewEng(
 	return e
 }
 
+// OnExecutionData is called to notify the engine when a new execution data is received.
+// It is registered as a consumer of the ExecutionDataRequester, which delivers notifications
+// in consecutive height order.
+func (e *Engine) OnExecutionData(executionData *execution_data.BlockExecutionData) {
+	lg := e.log.With().Hex("block_id", logging.ID(executionData.BlockID)).Logger()
+
+	lg.Trace().Msg("received execution data")
+
+	header, err := e.headers.ByBlockID(executionData.BlockID)
+	if err != nil {
+		// if the execution data is available, the block must be locally finalized
+		lg.Fatal().Err(err).Msg("could not get header for execution data")
+		return
+	}
+
+	if ok := e.backend.setHighestHeight(header.Height); !ok {
+		// this means that the height was lower than the current highest height
+		// OnExecutionData is guaranteed by the requester to be called in order, but may be called
+		// multiple times for the same block.
+		lg.Debug().Msg("execution data for block already received")
+		return
+	}
+
+	e.broadcaster.Publish()
+}
+
 // serve starts the gRPC server.
 // When this function returns, the server is considered ready.
 func (e *Engine) serve(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
@@ -0,0 +1,170 @@
+package state_stream_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/access/state_stream"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+var eventTypes = map[flow.EventType]bool{
+	"flow.AccountCreated":                            true,
+	"flow.AccountKeyAdded":                           true,
+	"A.7e60df042a9c0868.FlowToken.TokensDeposited":   true,
+	"A.7e60df042a9c0868.FlowToken.TokensWithdrawn":   true,
+	"A.9a0766d93b6608b7.FungibleToken.Withdrawn":     true,
+	"A.9a0766d93b6608b7.FungibleToken.Deposited":     true,
+	"A.8c5303eaa26202d6.EVM.BridgedAccountCreated":   true,
+	"A.0ae53cb6e3f42a79.SomeOtherContract.SomeEvent": true,
+}
+
+func TestEventFilterParsing(t *testing.T) {
+	chain := flow.Testnet.Chain()
+
+	tests := []struct {
+		name       string
+		eventTypes []string
+		addresses  []string
+		contracts  []string
+		err        bool
+	}{
+		{
+			name:       "valid filter",
+			eventTypes: []string{"flow.AccountCreated", "A.7e60df042a9c0868.FlowToken.TokensDeposited"},
+			addresses:  []string{"7e60df042a9c0868"},
+			contracts:  []string{"A.9a0766d93b6608b7.FungibleToken"},
+		},
+		{
+			name:       "empty filter",
+			eventTypes: []string{},
+			addresses:  []string{},
+			contracts:  []string{},
+		},
+		{
+			name:       "invalid event type",
+			eventTypes: []string{"A.7e60df042a9c0868.FlowToken"},
+			err:        true,
+		},
+		{
+			name:      "invalid address",
+			addresses: []string{unittest.InvalidAddressFixture().String()},
+			err:       true,
+		},
+		{
+			name:      "invalid contract",
+			contracts: []string{"A.7e60df042a9c0868.FlowToken.TokensDeposited"},
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := state_stream.NewEventFilter(state_stream.DefaultEventFilterConfig, chain, test.eventTypes, test.addresses, test.contracts)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEventFilterLimits(t *testing.T) {
+	chain := flow.Testnet.Chain()
+	config := state_stream.EventFilterConfig{
+		MaxEventTypes: 1,
+		MaxAddresses:  1,
+		MaxContracts:  1,
+	}
+
+	_, err := state_stream.NewEventFilter(config, chain, []string{"flow.AccountCreated", "flow.AccountKeyAdded"}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = state_stream.NewEventFilter(config, chain, nil, []string{"7e60df042a9c0868", "9a0766d93b6608b7"}, nil)
+	assert.Error(t, err)
+
+	_, err = state_stream.NewEventFilter(config, chain, nil, nil, []string{"A.7e60df042a9c0868.FlowToken", "A.9a0766d93b6608b7.FungibleToken"})
+	assert.Error(t, err)
+}
+
+func TestEventFilterMatch(t *testing.T) {
+	chain := flow.Testnet.Chain()
+
+	tests := []struct {
+		name       string
+		eventTypes []string
+		addresses  []string
+		contracts  []string
+		matches    map[flow.EventType]bool
+	}{
+		{
+			name: "no filters matches all events",
+			matches: map[flow.EventType]bool{
+				"flow.AccountCreated":                          true,
+				"A.7e60df042a9c0868.FlowToken.TokensDeposited": true,
+			},
+		},
+		{
+			name:       "event type filter",
+			eventTypes: []string{"flow.AccountCreated", "A.7e60df042a9c0868.FlowToken.TokensDeposited"},
+			matches: map[flow.EventType]bool{
+				"flow.AccountCreated":                          true,
+				"A.7e60df042a9c0868.FlowToken.TokensDeposited": true,
+			},
+		},
+		{
+			name:      "address filter",
+			addresses: []string{"0x7e60df042a9c0868"},
+			matches: map[flow.EventType]bool{
+				"A.7e60df042a9c0868.FlowToken.TokensDeposited": true,
+				"A.7e60df042a9c0868.FlowToken.TokensWithdrawn": true,
+			},
+		},
+		{
+			name:      "contract filter",
+			contracts: []string{"A.9a0766d93b6608b7.FungibleToken"},
+			matches: map[flow.EventType]bool{
+				"A.9a0766d93b6608b7.FungibleToken.Withdrawn": true,
+				"A.9a0766d93b6608b7.FungibleToken.Deposited": true,
+			},
+		},
+		{
+			name:       "combined filters match any criteria",
+			eventTypes: []string{"flow.AccountKeyAdded"},
+			addresses:  []string{"8c5303eaa26202d6"},
+			contracts:  []string{"A.0ae53cb6e3f42a79.SomeOtherContract"},
+			matches: map[flow.EventType]bool{
+				"flow.AccountKeyAdded":                           true,
+				"A.8c5303eaa26202d6.EVM.BridgedAccountCreated":   true,
+				"A.0ae53cb6e3f42a79.SomeOtherContract.SomeEvent": true,
+			},
+		},
+	}
+
+	events := make(flow.EventsList, 0, len(eventTypes))
+	for eventType := range eventTypes {
+		events = append(events, unittest.EventFixture(eventType, 0, 0, unittest.IdentifierFixture(), 0))
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := state_stream.NewEventFilter(state_stream.DefaultEventFilterConfig, chain, test.eventTypes, test.addresses, test.contracts)
+			require.NoError(t, err)
+
+			noFilters := len(test.eventTypes) == 0 && len(test.addresses) == 0 && len(test.contracts) == 0
+			for _, event := range events {
+				expected := noFilters || test.matches[event.Type]
+				assert.Equal(t, expected, filter.Match(event), "event type: %s", event.Type)
+			}
+
+			filtered := filter.Filter(events)
+			for _, event := range filtered {
+				assert.True(t, noFilters || test.matches[event.Type], "event type: %s", event.Type)
+			}
+		})
+	}
+}
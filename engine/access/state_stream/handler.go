@@ -2,16 +2,23 @@ package state_stream
 
 import (
 	"context"
+	"errors"
 
 	access "github.com/onflow/flow/protobuf/go/flow/executiondata"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
 )
 
 type Handler struct {
+	access.UnimplementedExecutionDataAPIServer
+
 	api   API
 	chain flow.Chain
+
+	eventFilterConfig EventFilterConfig
 }
 
 // HandlerOption is used to hand over optional constructor parameters
@@ -19,8 +26,9 @@ type HandlerOption func(*Handler)
 
 func NewHandler(api API, chain flow.Chain, options ...HandlerOption) *Handler {
 	h := &Handler{
-		api:   api,
-		chain: chain,
+		api:               api,
+		chain:             chain,
+		eventFilterConfig: DefaultEventFilterConfig,
 	}
 	for _, opt := range options {
 		opt(h)
@@ -28,6 +36,13 @@ func NewHandler(api API, chain flow.Chain, options ...HandlerOption) *Handler {
 	return h
 }
 
+// WithEventFilterConfig configures the Handler to limit the size of event filters with the given config
+func WithEventFilterConfig(config EventFilterConfig) HandlerOption {
+	return func(handler *Handler) {
+		handler.eventFilterConfig = config
+	}
+}
+
 func (h *Handler) GetExecutionDataByBlockID(ctx context.Context, request *access.GetExecutionDataByBlockIDRequest) (*access.GetExecutionDataByBlockIDResponse, error) {
 	blockID, err := convert.BlockID(request.GetBlockId())
 	if err != nil {
@@ -41,3 +56,110 @@ func (h *Handler) GetExecutionDataByBlockID(ctx context.Context, request *access
 
 	return &access.GetExecutionDataByBlockIDResponse{BlockExecutionData: execData}, nil
 }
+
+// SubscribeExecutionData streams the execution data of all sealed blocks, starting at the requested
+// block, to the client. The stream stays open until the client disconnects or an error occurs.
+func (h *Handler) SubscribeExecutionData(request *access.SubscribeExecutionDataRequest, stream access.ExecutionDataAPI_SubscribeExecutionDataServer) error {
+	startBlockID, err := startBlockID(request.GetStartBlockId())
+	if err != nil {
+		return err
+	}
+
+	sub := h.api.SubscribeExecutionData(stream.Context(), startBlockID, request.GetStartBlockHeight())
+
+	for v := range sub.Channel() {
+		resp, ok := v.(*ExecutionDataResponse)
+		if !ok {
+			return status.Errorf(codes.Internal, "unexpected response type: %T", v)
+		}
+
+		execData, err := convert.BlockExecutionDataToMessage(resp.ExecutionData)
+		if err != nil {
+			return status.Errorf(codes.Internal, "could not convert execution data to entity: %v", err)
+		}
+
+		err = stream.Send(&access.SubscribeExecutionDataResponse{
+			BlockHeight:        resp.Height,
+			BlockExecutionData: execData,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return subscriptionError(sub.Err())
+}
+
+// SubscribeEvents streams the events matching the request's filter for all sealed blocks, starting
+// at the requested block, to the client. A response is sent for every block, even if it has no
+// matching events. The stream stays open until the client disconnects or an error occurs.
+func (h *Handler) SubscribeEvents(request *access.SubscribeEventsRequest, stream access.ExecutionDataAPI_SubscribeEventsServer) error {
+	startBlockID, err := startBlockID(request.GetStartBlockId())
+	if err != nil {
+		return err
+	}
+
+	filter := EventFilter{}
+	if reqFilter := request.GetFilter(); reqFilter != nil {
+		filter, err = NewEventFilter(h.eventFilterConfig, h.chain, reqFilter.GetEventType(), reqFilter.GetAddress(), reqFilter.GetContract())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid event filter: %v", err)
+		}
+	}
+
+	sub := h.api.SubscribeEvents(stream.Context(), startBlockID, request.GetStartBlockHeight(), filter)
+
+	for v := range sub.Channel() {
+		resp, ok := v.(*EventsResponse)
+		if !ok {
+			return status.Errorf(codes.Internal, "unexpected response type: %T", v)
+		}
+
+		err = stream.Send(&access.SubscribeEventsResponse{
+			BlockHeight: resp.Height,
+			BlockId:     convert.IdentifierToMessage(resp.BlockID),
+			Events:      convert.EventsToMessages(resp.Events),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return subscriptionError(sub.Err())
+}
+
+// startBlockID returns the start block ID of a subscription request, or flow.ZeroID if the
+// request has none.
+func startBlockID(blockID []byte) (flow.Identifier, error) {
+	if len(blockID) == 0 {
+		return flow.ZeroID, nil
+	}
+
+	id, err := convert.BlockID(blockID)
+	if err != nil {
+		return flow.ZeroID, status.Errorf(codes.InvalidArgument, "could not convert start block ID: %v", err)
+	}
+	return id, nil
+}
+
+// subscriptionError converts the error a subscription failed with to a gRPC status error.
+// It returns nil if the subscription was closed without an error.
+func subscriptionError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// errors returned while setting up the subscription already have a status code
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Errorf(codes.Canceled, "stream canceled: %v", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Errorf(codes.DeadlineExceeded, "timed out sending response: %v", err)
+	default:
+		return status.Errorf(codes.Internal, "stream encountered an error: %v", err)
+	}
+}
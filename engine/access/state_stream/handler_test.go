@@ -0,0 +1,211 @@
+package state_stream
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	access "github.com/onflow/flow/protobuf/go/flow/executiondata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// subscriptionAPI is an API whose subscriptions all return the same subscription, and which
+// records the start block of the last subscription.
+type subscriptionAPI struct {
+	API
+	sub Subscription
+
+	startBlockID flow.Identifier
+	startHeight  uint64
+	filter       EventFilter
+}
+
+func (a *subscriptionAPI) SubscribeExecutionData(_ context.Context, startBlockID flow.Identifier, startHeight uint64) Subscription {
+	a.startBlockID = startBlockID
+	a.startHeight = startHeight
+	return a.sub
+}
+
+func (a *subscriptionAPI) SubscribeEvents(_ context.Context, startBlockID flow.Identifier, startHeight uint64, filter EventFilter) Subscription {
+	a.startBlockID = startBlockID
+	a.startHeight = startHeight
+	a.filter = filter
+	return a.sub
+}
+
+// executionDataStream collects the responses sent to a SubscribeExecutionData client.
+type executionDataStream struct {
+	grpc.ServerStream
+	responses []*access.SubscribeExecutionDataResponse
+}
+
+func (s *executionDataStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *executionDataStream) Send(resp *access.SubscribeExecutionDataResponse) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+// eventsStream collects the responses sent to a SubscribeEvents client.
+type eventsStream struct {
+	grpc.ServerStream
+	responses []*access.SubscribeEventsResponse
+}
+
+func (s *eventsStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *eventsStream) Send(resp *access.SubscribeEventsResponse) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+func TestHandler_SubscribeExecutionData(t *testing.T) {
+	chain := flow.Testnet.Chain()
+
+	t.Run("streams responses until the subscription is closed", func(t *testing.T) {
+		sub := NewSubscription(2)
+		api := &subscriptionAPI{sub: sub}
+		handler := NewHandler(api, chain)
+
+		execData := []*execution_data.BlockExecutionData{
+			{BlockID: unittest.IdentifierFixture()},
+			{BlockID: unittest.IdentifierFixture()},
+		}
+		for i, data := range execData {
+			require.NoError(t, sub.Send(context.Background(), &ExecutionDataResponse{Height: uint64(10 + i), ExecutionData: data}, DefaultSendTimeout))
+		}
+		sub.Close()
+
+		startBlockID := unittest.IdentifierFixture()
+		stream := &executionDataStream{}
+		err := handler.SubscribeExecutionData(&access.SubscribeExecutionDataRequest{StartBlockId: startBlockID[:]}, stream)
+		require.NoError(t, err)
+		assert.Equal(t, startBlockID, api.startBlockID)
+
+		require.Len(t, stream.responses, len(execData))
+		for i, data := range execData {
+			expected, err := convert.BlockExecutionDataToMessage(data)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(10+i), stream.responses[i].BlockHeight)
+			assert.Equal(t, expected, stream.responses[i].BlockExecutionData)
+		}
+	})
+
+	t.Run("starts without a start block", func(t *testing.T) {
+		sub := NewSubscription(0)
+		sub.Close()
+		api := &subscriptionAPI{sub: sub}
+
+		err := NewHandler(api, chain).SubscribeExecutionData(&access.SubscribeExecutionDataRequest{StartBlockHeight: 5}, &executionDataStream{})
+		require.NoError(t, err)
+		assert.Equal(t, flow.ZeroID, api.startBlockID)
+		assert.Equal(t, uint64(5), api.startHeight)
+	})
+
+	t.Run("invalid start block", func(t *testing.T) {
+		handler := NewHandler(&subscriptionAPI{}, chain)
+
+		err := handler.SubscribeExecutionData(&access.SubscribeExecutionDataRequest{StartBlockId: []byte{1, 2, 3}}, &executionDataStream{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("failed subscriptions return status errors", func(t *testing.T) {
+		tests := []struct {
+			err  error
+			code codes.Code
+		}{
+			{err: status.Error(codes.NotFound, "start block not found"), code: codes.NotFound},
+			{err: fmt.Errorf("client disconnected: %w", context.Canceled), code: codes.Canceled},
+			{err: context.DeadlineExceeded, code: codes.DeadlineExceeded},
+			{err: fmt.Errorf("could not get response"), code: codes.Internal},
+		}
+
+		for _, test := range tests {
+			sub := NewSubscription(0)
+			sub.Fail(test.err)
+
+			err := NewHandler(&subscriptionAPI{sub: sub}, chain).SubscribeExecutionData(&access.SubscribeExecutionDataRequest{}, &executionDataStream{})
+			assert.Equal(t, test.code, status.Code(err), "unexpected code for %v", test.err)
+		}
+	})
+}
+
+func TestHandler_SubscribeEvents(t *testing.T) {
+	chain := flow.Testnet.Chain()
+
+	t.Run("streams the filtered events of every block", func(t *testing.T) {
+		sub := NewSubscription(2)
+		api := &subscriptionAPI{sub: sub}
+		handler := NewHandler(api, chain)
+
+		txID := unittest.IdentifierFixture()
+		events := flow.EventsList{
+			unittest.EventFixture(flow.EventAccountCreated, 0, 0, txID, 0),
+			unittest.EventFixture(flow.EventAccountCreated, 0, 1, txID, 0),
+		}
+
+		responses := []*EventsResponse{
+			{BlockID: unittest.IdentifierFixture(), Height: 10, Events: events},
+			{BlockID: unittest.IdentifierFixture(), Height: 11, Events: flow.EventsList{}},
+		}
+		for _, resp := range responses {
+			require.NoError(t, sub.Send(context.Background(), resp, DefaultSendTimeout))
+		}
+		sub.Close()
+
+		stream := &eventsStream{}
+		err := handler.SubscribeEvents(&access.SubscribeEventsRequest{
+			StartBlockHeight: 10,
+			Filter:           &access.EventFilter{EventType: []string{string(flow.EventAccountCreated)}},
+		}, stream)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(10), api.startHeight)
+		assert.Contains(t, api.filter.EventTypes, flow.EventAccountCreated)
+
+		require.Len(t, stream.responses, len(responses))
+		for i, resp := range responses {
+			assert.Equal(t, resp.Height, stream.responses[i].BlockHeight)
+			assert.Equal(t, convert.IdentifierToMessage(resp.BlockID), stream.responses[i].BlockId)
+			assert.Equal(t, convert.EventsToMessages(resp.Events), stream.responses[i].Events)
+		}
+	})
+
+	t.Run("no filter matches all events", func(t *testing.T) {
+		sub := NewSubscription(0)
+		sub.Close()
+		api := &subscriptionAPI{sub: sub}
+
+		err := NewHandler(api, chain).SubscribeEvents(&access.SubscribeEventsRequest{}, &eventsStream{})
+		require.NoError(t, err)
+		assert.True(t, api.filter.Match(unittest.EventFixture(flow.EventAccountCreated, 0, 0, unittest.IdentifierFixture(), 0)))
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		config := EventFilterConfig{MaxEventTypes: 1, MaxAddresses: 1, MaxContracts: 1}
+		handler := NewHandler(&subscriptionAPI{}, chain, WithEventFilterConfig(config))
+
+		filters := []*access.EventFilter{
+			{EventType: []string{"invalid"}},
+			{EventType: []string{string(flow.EventAccountCreated), string(flow.EventAccountUpdated)}},
+			{Address: []string{"invalid"}},
+		}
+		for _, filter := range filters {
+			err := handler.SubscribeEvents(&access.SubscribeEventsRequest{Filter: filter}, &eventsStream{})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err), "unexpected code for filter %v", filter)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package subscription
+
+// Subscription represents a streaming request, and handles the communication between the grpc handler
+// and the backend implementation.
+//
+// It is defined here instead of the state_stream package, so public APIs returning subscriptions
+// (e.g. access.API) don't depend on the execution state streaming engine.
+type Subscription interface {
+	// ID returns the unique identifier for this subscription used for logging
+	ID() string
+
+	// Channel returns the channel from which subscription data can be read
+	Channel() <-chan interface{}
+
+	// Err returns the error that caused the subscription to fail
+	Err() error
+}
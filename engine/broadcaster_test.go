@@ -0,0 +1,85 @@
+package engine_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// TestBroadcaster_Publish verifies that all subscribers are notified when Publish is called
+func TestBroadcaster_Publish(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no subscribers", func(t *testing.T) {
+		b := engine.NewBroadcaster()
+		unittest.RequireReturnsBefore(t, b.Publish, 100*time.Millisecond, "publish never finished")
+	})
+
+	t.Run("all subscribers notified", func(t *testing.T) {
+		subscriberCount := 10
+		notifiedCounter := atomic.NewInt32(0)
+
+		var wg sync.WaitGroup
+		wg.Add(subscriberCount)
+
+		b := engine.NewBroadcaster()
+		for i := 0; i < subscriberCount; i++ {
+			notifier := engine.NewNotifier()
+			b.Subscribe(notifier)
+			go func() {
+				defer wg.Done()
+				<-notifier.Channel()
+				notifiedCounter.Inc()
+			}()
+		}
+
+		b.Publish()
+
+		unittest.RequireReturnsBefore(t, wg.Wait, 100*time.Millisecond, "wait never finished")
+		assert.Equal(t, int32(subscriberCount), notifiedCounter.Load())
+	})
+
+	t.Run("repeated publishes are coalesced", func(t *testing.T) {
+		b := engine.NewBroadcaster()
+		notifier := engine.NewNotifier()
+		b.Subscribe(notifier)
+
+		for i := 0; i < 10; i++ {
+			b.Publish()
+		}
+
+		// exactly one notification should be pending
+		<-notifier.Channel()
+		select {
+		case <-notifier.Channel():
+			t.Fail()
+		default:
+		}
+	})
+	t.Run("unsubscribed subscribers are not notified", func(t *testing.T) {
+		b := engine.NewBroadcaster()
+		notifier1 := engine.NewNotifier()
+		notifier2 := engine.NewNotifier()
+		b.Subscribe(notifier1)
+		b.Subscribe(notifier2)
+
+		b.Unsubscribe(notifier1)
+		// unsubscribing twice is a no-op
+		b.Unsubscribe(notifier1)
+
+		b.Publish()
+
+		<-notifier2.Channel()
+		select {
+		case <-notifier1.Channel():
+			t.Fail()
+		default:
+		}
+	})
+}
@@ -3,8 +3,10 @@ package computer
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"time"
 
+	"github.com/google/pprof/profile"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel/attribute"
 	otelTrace "go.opentelemetry.io/otel/trace"
@@ -16,6 +18,7 @@ import (
 	"github.com/onflow/flow-go/fvm/blueprints"
 	"github.com/onflow/flow-go/fvm/derived"
 	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/fvm/tracing"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
@@ -59,6 +62,12 @@ type BlockComputer interface {
 	)
 }
 
+// TransactionProfileCollector receives the computation profiles of the
+// transactions sampled for profiling.
+type TransactionProfileCollector interface {
+	AddProfile(prof *profile.Profile)
+}
+
 type blockComputer struct {
 	vm                    VirtualMachine
 	vmCtx                 fvm.Context
@@ -70,6 +79,47 @@ type blockComputer struct {
 	executionDataProvider *provider.Provider
 	signer                module.Local
 	spockHasher           hash.Hasher
+
+	// parallelWorkers is the number of transactions of a collection executed
+	// speculatively in parallel.  Transactions are executed sequentially when
+	// parallelWorkers is less than 2.
+	parallelWorkers uint
+
+	// profileSampleRate is the fraction of the executed transactions whose
+	// computation profile is passed to the profileCollector.
+	profileSampleRate float64
+	profileCollector  TransactionProfileCollector
+}
+
+// BlockComputerOption configures optional features of the block computer.
+type BlockComputerOption func(*blockComputer)
+
+// WithParallelTransactionExecution enables the optimistic parallel execution
+// of the transactions within a collection, using the given number of workers.
+// The execution results are identical to sequential execution: transactions
+// which conflict with a transaction earlier in the collection are re-executed.
+//
+// Parallel execution requires a virtual machine which supports speculative
+// execution (see fvm.VirtualMachine.RunUncommitted); otherwise, transactions
+// are executed sequentially.
+func WithParallelTransactionExecution(workers uint) BlockComputerOption {
+	return func(e *blockComputer) {
+		e.parallelWorkers = workers
+	}
+}
+
+// WithTransactionProfiling enables the computation profiling of a random
+// sample of the executed transactions, where sampleRate is the fraction of
+// the transactions that are profiled.  The profile of a sampled transaction
+// is passed to the collector once the transaction is committed.
+func WithTransactionProfiling(
+	sampleRate float64,
+	collector TransactionProfileCollector,
+) BlockComputerOption {
+	return func(e *blockComputer) {
+		e.profileSampleRate = sampleRate
+		e.profileCollector = collector
+	}
 }
 
 func SystemChunkContext(vmCtx fvm.Context, logger zerolog.Logger) fvm.Context {
@@ -96,13 +146,14 @@ func NewBlockComputer(
 	committer ViewCommitter,
 	signer module.Local,
 	executionDataProvider *provider.Provider,
+	opts ...BlockComputerOption,
 ) (BlockComputer, error) {
 	systemChunkCtx := SystemChunkContext(vmCtx, logger)
 	vmCtx = fvm.NewContextFromParent(
 		vmCtx,
 		fvm.WithMetricsReporter(metrics),
 		fvm.WithTracer(tracer))
-	return &blockComputer{
+	e := &blockComputer{
 		vm:                    vm,
 		vmCtx:                 vmCtx,
 		metrics:               metrics,
@@ -113,7 +164,11 @@ func NewBlockComputer(
 		executionDataProvider: executionDataProvider,
 		signer:                signer,
 		spockHasher:           utils.NewSPOCKHasher(),
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
 }
 
 // ExecuteBlock executes a block and returns the resulting chunks.
@@ -339,12 +394,20 @@ func (e *blockComputer) executeCollection(
 
 	defer colSpan.End()
 
-	for _, txBody := range collection.Transactions {
-		err := e.executeTransaction(collection.blockId, txBody, colSpan, collectionView, collection.ctx, collectionIndex, txIndex, collector, collection.isSystemCollection)
-		txIndex++
+	var err error
+	if vm, ok := e.speculativeVM(collection, collectionView); ok {
+		txIndex, err = e.executeTransactionsInParallel(vm, collection, colSpan, collectionView, collectionIndex, txIndex, collector)
 		if err != nil {
 			return txIndex, err
 		}
+	} else {
+		for _, txBody := range collection.Transactions {
+			err = e.executeTransaction(collection.blockId, txBody, colSpan, collectionView, collection.ctx, collectionIndex, txIndex, collector, collection.isSystemCollection)
+			txIndex++
+			if err != nil {
+				return txIndex, err
+			}
+		}
 	}
 
 	if !collection.isSystemCollection {
@@ -367,17 +430,39 @@ func (e *blockComputer) executeCollection(
 	return txIndex, nil
 }
 
-func (e *blockComputer) executeTransaction(
+// transaction holds the tracing and logging state of a transaction executed
+// by the block computer.
+type transaction struct {
+	blockIdStr          string
+	txBody              *flow.TransactionBody
+	txID                flow.Identifier
+	txIndex             uint32
+	collectionIndex     int
+	isSystemTransaction bool
+
+	ctx  fvm.Context
+	proc *fvm.TransactionProcedure
+
+	// profile is nil unless the transaction is sampled for profiling.
+	profile *tracing.ComputationProfile
+
+	startedAt      time.Time
+	memAllocBefore uint64
+	txSpan         otelTrace.Span
+	txInternalSpan otelTrace.Span
+	isSampled      bool
+	traceID        string
+}
+
+func (e *blockComputer) newTransaction(
 	blockIdStr string,
 	txBody *flow.TransactionBody,
 	colSpan otelTrace.Span,
-	collectionView state.View,
 	ctx fvm.Context,
 	collectionIndex int,
 	txIndex uint32,
-	collector *resultCollector,
 	isSystemTransaction bool,
-) error {
+) *transaction {
 	startedAt := time.Now()
 	memAllocBefore := debug.GetHeapAllocsBytes()
 	txID := txBody.ID()
@@ -389,7 +474,6 @@ func (e *blockComputer) executeTransaction(
 		attribute.Int64("tx_index", int64(txIndex)),
 		attribute.Int("col_index", collectionIndex),
 	)
-	defer txSpan.End()
 
 	var traceID string
 	txInternalSpan, _, isSampled := e.tracer.StartTransactionSpan(context.Background(), txID, trace.EXERunTransaction)
@@ -397,7 +481,6 @@ func (e *blockComputer) executeTransaction(
 		txInternalSpan.SetAttributes(attribute.String("tx_id", txID.String()))
 		traceID = txInternalSpan.SpanContext().TraceID().String()
 	}
-	defer txInternalSpan.End()
 
 	e.log.Info().
 		Str("tx_id", txID.String()).
@@ -408,12 +491,15 @@ func (e *blockComputer) executeTransaction(
 		Bool("system_transaction", isSystemTransaction).
 		Msg("executing transaction in fvm")
 
-	tx := fvm.Transaction(txBody, txIndex)
-	if isSampled {
-		tx.SetTraceSpan(txInternalSpan)
+	var profile *tracing.ComputationProfile
+	if e.isProfileSampled() {
+		if isSystemTransaction {
+			profile = tracing.NewComputationProfile("system_transaction")
+		} else {
+			profile = tracing.NewComputationProfile("transaction")
+		}
 	}
 
-	txView := collectionView.NewChild()
 	childCtx := fvm.NewContextFromParent(ctx,
 		fvm.WithLogger(ctx.Logger.With().
 			Str("tx_id", txID.String()).
@@ -423,40 +509,123 @@ func (e *blockComputer) executeTransaction(
 			Bool("system_chunk", isSystemTransaction).
 			Bool("system_transaction", isSystemTransaction).
 			Logger()),
+		fvm.WithComputationProfile(profile),
 	)
-	err := e.vm.Run(childCtx, tx, txView)
+
+	txn := &transaction{
+		blockIdStr:          blockIdStr,
+		txBody:              txBody,
+		txID:                txID,
+		txIndex:             txIndex,
+		collectionIndex:     collectionIndex,
+		isSystemTransaction: isSystemTransaction,
+		ctx:                 childCtx,
+		profile:             profile,
+		startedAt:           startedAt,
+		memAllocBefore:      memAllocBefore,
+		txSpan:              txSpan,
+		txInternalSpan:      txInternalSpan,
+		isSampled:           isSampled,
+		traceID:             traceID,
+	}
+	txn.resetProcedure()
+	return txn
+}
+
+// resetProcedure replaces the transaction procedure with a new one, which
+// executes the transaction against the state at the transaction index.
+func (txn *transaction) resetProcedure() {
+	txn.proc = fvm.Transaction(txn.txBody, txn.txIndex)
+	if txn.isSampled {
+		txn.proc.SetTraceSpan(txn.txInternalSpan)
+	}
+	txn.profile.Reset()
+}
+
+// isProfileSampled returns whether a transaction should be profiled.
+func (e *blockComputer) isProfileSampled() bool {
+	if e.profileCollector == nil || e.profileSampleRate <= 0 {
+		return false
+	}
+	return rand.Float64() < e.profileSampleRate
+}
+
+func (txn *transaction) runError(err error) error {
+	return fmt.Errorf("failed to execute transaction %v for block %s at height %v: %w",
+		txn.txID.String(),
+		txn.blockIdStr,
+		txn.ctx.BlockHeader.Height,
+		err)
+}
+
+func (txn *transaction) end() {
+	txn.txInternalSpan.End()
+	txn.txSpan.End()
+}
+
+func (e *blockComputer) executeTransaction(
+	blockIdStr string,
+	txBody *flow.TransactionBody,
+	colSpan otelTrace.Span,
+	collectionView state.View,
+	ctx fvm.Context,
+	collectionIndex int,
+	txIndex uint32,
+	collector *resultCollector,
+	isSystemTransaction bool,
+) error {
+	txn := e.newTransaction(blockIdStr, txBody, colSpan, ctx, collectionIndex, txIndex, isSystemTransaction)
+	defer txn.end()
+
+	txView := collectionView.NewChild()
+	err := e.vm.Run(txn.ctx, txn.proc, txView)
 	if err != nil {
-		return fmt.Errorf("failed to execute transaction %v for block %s at height %v: %w",
-			txID.String(),
-			blockIdStr,
-			ctx.BlockHeader.Height,
-			err)
+		return txn.runError(err)
 	}
 
-	postProcessSpan := e.tracer.StartSpanFromParent(txSpan, trace.EXEPostProcessTransaction)
+	return e.commitTransaction(txn, collectionView, txView, collector)
+}
+
+// commitTransaction merges the view of an executed transaction into the
+// collection view and records the transaction's results.
+func (e *blockComputer) commitTransaction(
+	txn *transaction,
+	collectionView state.View,
+	txView state.View,
+	collector *resultCollector,
+) error {
+	postProcessSpan := e.tracer.StartSpanFromParent(txn.txSpan, trace.EXEPostProcessTransaction)
 	defer postProcessSpan.End()
 
 	// always merge the view, fvm take cares of reverting changes
 	// of failed transaction invocation
 
-	err = e.mergeView(collectionView, txView, postProcessSpan, trace.EXEMergeTransactionView)
+	err := e.mergeView(collectionView, txView, postProcessSpan, trace.EXEMergeTransactionView)
 	if err != nil {
 		return fmt.Errorf("merging tx view to collection view failed for tx %v: %w",
-			txID.String(), err)
+			txn.txID.String(), err)
 	}
 
-	collector.AddTransactionResult(collectionIndex, tx)
+	tx := txn.proc
+	collector.AddTransactionResult(txn.collectionIndex, tx)
+
+	if txn.profile != nil {
+		e.profileCollector.AddProfile(txn.profile.Profile(map[string]string{
+			"transaction_id": txn.txID.String(),
+			"block_id":       txn.blockIdStr,
+		}))
+	}
 
 	memAllocAfter := debug.GetHeapAllocsBytes()
 
 	lg := e.log.With().
-		Str("tx_id", txID.String()).
-		Str("block_id", blockIdStr).
-		Str("traceID", traceID).
+		Str("tx_id", txn.txID.String()).
+		Str("block_id", txn.blockIdStr).
+		Str("traceID", txn.traceID).
 		Uint64("computation_used", tx.ComputationUsed).
 		Uint64("memory_used", tx.MemoryEstimate).
-		Uint64("memAlloc", memAllocAfter-memAllocBefore).
-		Int64("timeSpentInMS", time.Since(startedAt).Milliseconds()).
+		Uint64("memAlloc", memAllocAfter-txn.memAllocBefore).
+		Int64("timeSpentInMS", time.Since(txn.startedAt).Milliseconds()).
 		Logger()
 
 	if tx.Err != nil {
@@ -467,14 +636,14 @@ func (e *blockComputer) executeTransaction(
 			Uint16("error_code", uint16(tx.Err.Code())).
 			Msg("transaction execution failed")
 
-		if isSystemTransaction {
+		if txn.isSystemTransaction {
 			// This log is used as the data source for an alert on grafana.
 			// The system_chunk_error field must not be changed without adding
 			// the corresponding changes in grafana.
 			// https://github.com/dapperlabs/flow-internal/issues/1546
 			e.log.Error().
 				Str("error_message", errMsg).
-				Hex("block_id", logging.Entity(ctx.BlockHeader)).
+				Hex("block_id", logging.Entity(txn.ctx.BlockHeader)).
 				Bool("system_chunk_error", true).
 				Bool("system_transaction_error", true).
 				Bool("critical_error", true).
@@ -485,10 +654,10 @@ func (e *blockComputer) executeTransaction(
 	}
 
 	e.metrics.ExecutionTransactionExecuted(
-		time.Since(startedAt),
+		time.Since(txn.startedAt),
 		tx.ComputationUsed,
 		tx.MemoryEstimate,
-		memAllocAfter-memAllocBefore,
+		memAllocAfter-txn.memAllocBefore,
 		len(tx.Events),
 		flow.EventsList(tx.Events).ByteSize(),
 		tx.Err != nil,
@@ -13,6 +13,7 @@ import (
 	"github.com/onflow/cadence/runtime/sema"
 	"github.com/onflow/cadence/runtime/stdlib"
 
+	"github.com/google/pprof/profile"
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
 	dssync "github.com/ipfs/go-datastore/sync"
@@ -32,6 +33,7 @@ import (
 	"github.com/onflow/flow-go/fvm/derived"
 	"github.com/onflow/flow-go/fvm/environment"
 	fvmErrors "github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/fvm/meter"
 	reusableRuntime "github.com/onflow/flow-go/fvm/runtime"
 	"github.com/onflow/flow-go/fvm/state"
 	"github.com/onflow/flow-go/fvm/systemcontracts"
@@ -142,6 +144,89 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 		vm.AssertExpectations(t)
 	})
 
+	t.Run("sampled transactions are profiled", func(t *testing.T) {
+
+		execCtx := fvm.NewContext()
+
+		vm := new(computermock.VirtualMachine)
+		vm.On("Run", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				ctx := args[0].(fvm.Context)
+				require.NotNil(t, ctx.ComputationProfile)
+				ctx.ComputationProfile.RecordComputation(
+					common.ComputationKindStatement,
+					2,
+					meter.DefaultComputationWeights)
+			}).
+			Times(2 + 1) // 2 txs in collection + system chunk
+
+		committer := new(computermock.ViewCommitter)
+		committer.On("CommitView", mock.Anything, mock.Anything).
+			Return(nil, nil, nil, nil).
+			Times(2 + 1) // 2 txs in collection + system chunk
+
+		bservice := requesterunit.MockBlobService(blockstore.NewBlockstore(dssync.MutexWrap(datastore.NewMapDatastore())))
+		trackerStorage := new(mocktracker.Storage)
+		trackerStorage.On("Update", mock.Anything).Return(func(fn tracker.UpdateFn) error {
+			return fn(func(uint64, ...cid.Cid) error { return nil })
+		})
+
+		prov := provider.NewProvider(
+			zerolog.Nop(),
+			metrics.NewNoopCollector(),
+			execution_data.DefaultSerializer,
+			bservice,
+			trackerStorage,
+		)
+
+		profiles := &testProfileCollector{}
+
+		exe, err := computer.NewBlockComputer(
+			vm,
+			execCtx,
+			metrics.NewNoopCollector(),
+			trace.NewNoopTracer(),
+			zerolog.Nop(),
+			committer,
+			me,
+			prov,
+			computer.WithTransactionProfiling(1, profiles))
+		require.NoError(t, err)
+
+		// create a block with 1 collection with 2 transactions
+		block := generateBlock(1, 2, rag)
+
+		view := delta.NewView(func(owner, key string) (flow.RegisterValue, error) {
+			return nil, nil
+		})
+
+		_, err = exe.ExecuteBlock(
+			context.Background(),
+			block,
+			view,
+			derived.NewEmptyDerivedBlockData())
+		require.NoError(t, err)
+
+		require.Len(t, profiles.profiles, 2+1)
+
+		transactions := block.Collections()[0].Transactions
+		roots := []string{"transaction", "transaction", "system_transaction"}
+		for i, prof := range profiles.profiles {
+			require.Len(t, prof.Sample, 1)
+
+			sample := prof.Sample[0]
+			require.Equal(t, []int64{2, 0}, sample.Value)
+			require.Equal(t, roots[i], sample.Location[len(sample.Location)-1].Line[0].Function.Name)
+			require.Equal(t, []string{block.ID().String()}, sample.Label["block_id"])
+			if i < len(transactions) {
+				require.Equal(t, []string{transactions[i].ID().String()}, sample.Label["transaction_id"])
+			}
+		}
+
+		vm.AssertExpectations(t)
+	})
+
 	t.Run("empty block still computes system chunk", func(t *testing.T) {
 
 		execCtx := fvm.NewContext()
@@ -702,6 +787,14 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 	})
 }
 
+type testProfileCollector struct {
+	profiles []*profile.Profile
+}
+
+func (c *testProfileCollector) AddProfile(prof *profile.Profile) {
+	c.profiles = append(c.profiles, prof)
+}
+
 func assertEventHashesMatch(t *testing.T, expectedNoOfChunks int, result *execution.ComputationResult) {
 
 	require.Len(t, result.Events, expectedNoOfChunks)
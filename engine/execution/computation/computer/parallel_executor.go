@@ -0,0 +1,208 @@
+package computer
+
+import (
+	"sync"
+
+	otelTrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/derived"
+	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// SpeculativeVirtualMachine is a virtual machine which can execute
+// transactions speculatively, without committing their derived data.
+type SpeculativeVirtualMachine interface {
+	RunUncommitted(
+		fvm.Context,
+		fvm.Procedure,
+		state.View,
+	) (
+		*derived.DerivedTransactionData,
+		error,
+	)
+}
+
+// speculativeTransaction is a transaction executed against the collection
+// state at the start of its execution round.
+type speculativeTransaction struct {
+	*transaction
+
+	view           state.View
+	derivedTxnData *derived.DerivedTransactionData
+	err            error
+}
+
+// speculativeVM returns the virtual machine used to execute the transactions
+// of the collection in parallel, or false if the collection's transactions
+// must be executed sequentially.
+func (e *blockComputer) speculativeVM(
+	collection collectionItem,
+	collectionView state.View,
+) (
+	SpeculativeVirtualMachine,
+	bool,
+) {
+	if e.parallelWorkers < 2 ||
+		collection.isSystemCollection ||
+		len(collection.Transactions) < 2 {
+		return nil, false
+	}
+
+	// speculative views read from the collection view without recording
+	// the reads in it, which requires a delta view.
+	if _, ok := collectionView.(*delta.View); !ok {
+		return nil, false
+	}
+
+	vm, ok := e.vm.(SpeculativeVirtualMachine)
+	return vm, ok
+}
+
+// executeTransactionsInParallel executes the transactions of the collection
+// optimistically, in rounds of up to parallelWorkers transactions.
+//
+// All transactions of a round are executed concurrently, each on its own view
+// of the collection state at the start of the round.  The transactions are
+// then committed in transaction index order.  A transaction is re-executed
+// against the committed state if it touched a register updated by an earlier
+// transaction of the round, or if its derived data is invalidated by an
+// earlier transaction of the round.  Since the remaining transactions observe
+// exactly the same state as they would have when executed sequentially, the
+// execution results (including SPoCK secrets) are identical to sequential
+// execution.
+func (e *blockComputer) executeTransactionsInParallel(
+	vm SpeculativeVirtualMachine,
+	collection collectionItem,
+	colSpan otelTrace.Span,
+	collectionView state.View,
+	collectionIndex int,
+	txIndex uint32,
+	collector *resultCollector,
+) (uint32, error) {
+	baseView := collectionView.(*delta.View)
+
+	// The underlying storage of the collection view is not necessarily safe
+	// for concurrent use (e.g., the ledger read cache), so the reads of the
+	// speculative transactions are serialized.  The collection view is not
+	// modified while a round is executing.
+	var readLock sync.Mutex
+	readFunc := func(owner, key string) (flow.RegisterValue, error) {
+		readLock.Lock()
+		defer readLock.Unlock()
+
+		return baseView.Peek(owner, key)
+	}
+
+	transactions := collection.Transactions
+	workers := int(e.parallelWorkers)
+	for start := 0; start < len(transactions); start += workers {
+		end := start + workers
+		if end > len(transactions) {
+			end = len(transactions)
+		}
+
+		snapshotTxIndex := txIndex
+		round := make([]*speculativeTransaction, 0, end-start)
+
+		wg := sync.WaitGroup{}
+		for _, txBody := range transactions[start:end] {
+			txn := &speculativeTransaction{
+				transaction: e.newTransaction(
+					collection.blockId,
+					txBody,
+					colSpan,
+					collection.ctx,
+					collectionIndex,
+					txIndex,
+					collection.isSystemCollection),
+				view: delta.NewView(readFunc),
+			}
+			txn.proc.InitialSnapshotTxIndex = snapshotTxIndex
+			round = append(round, txn)
+			txIndex++
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				txn.derivedTxnData, txn.err = vm.RunUncommitted(
+					txn.ctx,
+					txn.proc,
+					txn.view)
+			}()
+		}
+		wg.Wait()
+
+		// registers updated by the transactions committed in this round
+		updated := make(map[flow.RegisterID]struct{})
+		for i, txn := range round {
+			err := e.commitSpeculativeTransaction(txn, updated, collectionView, collector)
+			txn.end()
+			if err != nil {
+				for _, skipped := range round[i+1:] {
+					skipped.end()
+				}
+				return snapshotTxIndex + uint32(i) + 1, err
+			}
+		}
+	}
+
+	return txIndex, nil
+}
+
+// commitSpeculativeTransaction commits the speculatively executed transaction
+// if it does not conflict with the transactions committed earlier in its
+// round, and re-executes it against the committed state otherwise.
+func (e *blockComputer) commitSpeculativeTransaction(
+	txn *speculativeTransaction,
+	updated map[flow.RegisterID]struct{},
+	collectionView state.View,
+	collector *resultCollector,
+) error {
+	txView := txn.view
+
+	conflict := txn.err != nil
+	if !conflict {
+		for _, id := range txView.AllRegisters() {
+			if _, ok := updated[id]; ok {
+				conflict = true
+				break
+			}
+		}
+	}
+	if !conflict {
+		conflict = txn.derivedTxnData.Validate() != nil
+	}
+
+	if conflict {
+		e.log.Debug().
+			Str("tx_id", txn.txID.String()).
+			Uint32("tx_index", txn.txIndex).
+			Str("block_id", txn.blockIdStr).
+			Msg("re-executing conflicting transaction")
+
+		txn.resetProcedure()
+		txView = collectionView.NewChild()
+		err := e.vm.Run(txn.ctx, txn.proc, txView)
+		if err != nil {
+			return txn.runError(err)
+		}
+	} else {
+		// NOTE: It is not safe to ignore derivedTxnData' commit error for
+		// transactions that trigger derived data invalidation.
+		err := txn.derivedTxnData.Commit()
+		if err != nil {
+			return txn.runError(err)
+		}
+	}
+
+	ids, _ := txView.RegisterUpdates()
+	for _, id := range ids {
+		updated[id] = struct{}{}
+	}
+
+	return e.commitTransaction(txn.transaction, collectionView, txView, collector)
+}
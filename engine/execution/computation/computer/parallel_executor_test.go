@@ -0,0 +1,180 @@
+package computer_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/stdlib"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution"
+	"github.com/onflow/flow-go/engine/execution/computation/committer"
+	"github.com/onflow/flow-go/engine/execution/computation/computer"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/derived"
+	"github.com/onflow/flow-go/fvm/environment"
+	reusableRuntime "github.com/onflow/flow-go/fvm/runtime"
+	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/module/executiondatasync/provider"
+	"github.com/onflow/flow-go/module/executiondatasync/tracker"
+	mocktracker "github.com/onflow/flow-go/module/executiondatasync/tracker/mock"
+	"github.com/onflow/flow-go/module/metrics"
+	modulemock "github.com/onflow/flow-go/module/mock"
+	requesterunit "github.com/onflow/flow-go/module/state_synchronization/requester/unittest"
+	"github.com/onflow/flow-go/module/trace"
+)
+
+func TestBlockExecutor_ParallelExecution(t *testing.T) {
+
+	const collectionCount = 3
+	const transactionCount = 8
+
+	// transactions in the same group update the same register, and conflict
+	// with each other
+	const groupCount = 3
+
+	address := common.Address{0x1}
+
+	ordinaryEvent := cadence.Event{
+		EventType: &cadence.EventType{
+			Location:            stdlib.FlowLocation{},
+			QualifiedIdentifier: "what.ever",
+		},
+	}
+
+	var executions int64
+	rt := &testRuntime{
+		executeTransaction: func(script runtime.Script, r runtime.Context) error {
+			atomic.AddInt64(&executions, 1)
+
+			key := []byte(fmt.Sprintf("counter_%s", script.Source))
+			if len(script.Source) > 1 {
+				// system chunk transaction
+				key = []byte("system")
+			}
+
+			value, err := r.Interface.GetValue(address.Bytes(), key)
+			if err != nil {
+				return err
+			}
+
+			updated := make([]byte, 0, len(value)+len(script.Source))
+			updated = append(updated, value...)
+			updated = append(updated, script.Source...)
+
+			err = r.Interface.SetValue(address.Bytes(), key, updated)
+			if err != nil {
+				return err
+			}
+
+			return r.Interface.EmitEvent(ordinaryEvent)
+		},
+		readStored: func(address common.Address, path cadence.Path, r runtime.Context) (cadence.Value, error) {
+			return nil, nil
+		},
+	}
+
+	txCount := 0
+	block := generateBlockWithVisitor(
+		collectionCount,
+		transactionCount,
+		&RandomAddressGenerator{},
+		func(body *flow.TransactionBody) {
+			body.Script = []byte(fmt.Sprintf("%d", txCount%groupCount))
+			txCount++
+		})
+
+	execute := func(opts ...computer.BlockComputerOption) *execution.ComputationResult {
+		execCtx := fvm.NewContext(
+			fvm.WithAuthorizationChecksEnabled(false),
+			fvm.WithSequenceNumberCheckAndIncrementEnabled(false),
+			fvm.WithReusableCadenceRuntimePool(
+				reusableRuntime.NewCustomReusableCadenceRuntimePool(
+					0,
+					func() runtime.Runtime {
+						return rt
+					})),
+		)
+
+		me := new(modulemock.Local)
+		me.On("SignFunc", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, nil)
+
+		bservice := requesterunit.MockBlobService(blockstore.NewBlockstore(dssync.MutexWrap(datastore.NewMapDatastore())))
+		trackerStorage := new(mocktracker.Storage)
+		trackerStorage.On("Update", mock.Anything).Return(func(fn tracker.UpdateFn) error {
+			return fn(func(uint64, ...cid.Cid) error { return nil })
+		})
+
+		prov := provider.NewProvider(
+			zerolog.Nop(),
+			metrics.NewNoopCollector(),
+			execution_data.DefaultSerializer,
+			bservice,
+			trackerStorage,
+		)
+
+		exe, err := computer.NewBlockComputer(
+			fvm.NewVirtualMachine(),
+			execCtx,
+			metrics.NewNoopCollector(),
+			trace.NewNoopTracer(),
+			zerolog.Nop(),
+			committer.NewNoopViewCommitter(),
+			me,
+			prov,
+			opts...)
+		require.NoError(t, err)
+
+		view := delta.NewView(func(owner, key string) (flow.RegisterValue, error) {
+			return nil, nil
+		})
+		err = view.Set(string(address.Bytes()), state.AccountStatusKey, environment.NewAccountStatus().ToBytes())
+		require.NoError(t, err)
+
+		result, err := exe.ExecuteBlock(context.Background(), block, view, derived.NewEmptyDerivedBlockData())
+		require.NoError(t, err)
+		return result
+	}
+
+	expected := execute()
+	require.Equal(t, int64(collectionCount*transactionCount+1), atomic.LoadInt64(&executions))
+
+	for _, workers := range []uint{2, 4, transactionCount} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			atomic.StoreInt64(&executions, 0)
+
+			result := execute(computer.WithParallelTransactionExecution(workers))
+
+			// conflicting transactions are re-executed
+			require.Greater(t, atomic.LoadInt64(&executions), int64(collectionCount*transactionCount+1))
+
+			requireEqualResults(t, expected, result)
+		})
+	}
+}
+
+func requireEqualResults(t *testing.T, expected, actual *execution.ComputationResult) {
+	require.Equal(t, expected.StateSnapshots, actual.StateSnapshots)
+	require.Equal(t, expected.StateCommitments, actual.StateCommitments)
+	require.Equal(t, expected.Events, actual.Events)
+	require.Equal(t, expected.EventsHashes, actual.EventsHashes)
+	require.Equal(t, expected.ServiceEvents, actual.ServiceEvents)
+	require.Equal(t, expected.TransactionResults, actual.TransactionResults)
+	require.Equal(t, expected.TrieUpdates, actual.TrieUpdates)
+	require.Equal(t, expected.ExecutionDataID, actual.ExecutionDataID)
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/onflow/cadence"
 	jsoncdc "github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/cadence/runtime"
 	"github.com/rs/zerolog"
@@ -18,10 +19,15 @@ import (
 	"github.com/onflow/flow-go/engine/execution"
 	"github.com/onflow/flow-go/engine/execution/computation/computer"
 	"github.com/onflow/flow-go/engine/execution/computation/computer/uploader"
+	"github.com/onflow/flow-go/engine/execution/computation/programcache"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
 	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/blueprints"
 	"github.com/onflow/flow-go/fvm/derived"
+	"github.com/onflow/flow-go/fvm/environment"
 	reusableRuntime "github.com/onflow/flow-go/fvm/runtime"
 	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/fvm/tracing"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/module/executiondatasync/provider"
@@ -61,6 +67,23 @@ type ComputationManager interface {
 		view state.View,
 	) (*execution.ComputationResult, error)
 	GetAccount(addr flow.Address, header *flow.Header, view state.View) (*flow.Account, error)
+	SimulateTransaction(
+		tx *flow.TransactionBody,
+		header *flow.Header,
+		view state.View,
+		skipChecks bool,
+	) (*flow.TransactionSimulationResult, error)
+	EstimateTransactionFees(
+		tx *flow.TransactionBody,
+		header *flow.Header,
+		view state.View,
+	) (*flow.TransactionFeesEstimate, error)
+	TraceTransaction(
+		header *flow.Header,
+		transactions []*flow.TransactionBody,
+		txIndex uint32,
+		view state.View,
+	) (*tracing.ExecutionTrace, error)
 }
 
 type ComputationConfig struct {
@@ -70,6 +93,23 @@ type ComputationConfig struct {
 	ScriptLogThreshold       time.Duration
 	ScriptExecutionTimeLimit time.Duration
 
+	// ParallelTransactionExecutionWorkers is the number of transactions of a
+	// collection executed speculatively in parallel.  Values less than 2
+	// disable parallel execution.
+	ParallelTransactionExecutionWorkers uint
+
+	// TransactionProfileSampleRate is the fraction of the executed
+	// transactions whose computation profile is passed to the
+	// TransactionProfileCollector.  Profiling is disabled when the rate is
+	// not positive, or when the collector is nil.
+	TransactionProfileSampleRate float64
+	TransactionProfileCollector  computer.TransactionProfileCollector
+
+	// ProgramsCache persists the programs cached in the derived data of the
+	// executed blocks, so that they can be loaded on startup (see
+	// Manager.WarmUpPrograms).  The cache is disabled when nil.
+	ProgramsCache *programcache.Cache
+
 	// When NewCustomVirtualMachine is nil, the manager will create a standard
 	// fvm virtual machine via fvm.NewVirtualMachine.  Otherwise, the manager
 	// will create a virtual machine using this function.
@@ -89,6 +129,7 @@ type Manager struct {
 	vmCtx                    fvm.Context
 	blockComputer            computer.BlockComputer
 	derivedChainData         *derived.DerivedChainData
+	programsCache            *programcache.Cache
 	scriptLogThreshold       time.Duration
 	scriptExecutionTimeLimit time.Duration
 	uploaders                []uploader.Uploader
@@ -140,6 +181,10 @@ func New(
 		committer,
 		me,
 		executionDataProvider,
+		computer.WithParallelTransactionExecution(params.ParallelTransactionExecutionWorkers),
+		computer.WithTransactionProfiling(
+			params.TransactionProfileSampleRate,
+			params.TransactionProfileCollector),
 	)
 
 	if err != nil {
@@ -161,6 +206,7 @@ func New(
 		vmCtx:                    vmCtx,
 		blockComputer:            blockComputer,
 		derivedChainData:         derivedChainData,
+		programsCache:            params.ProgramsCache,
 		scriptLogThreshold:       params.ScriptLogThreshold,
 		scriptExecutionTimeLimit: params.ScriptExecutionTimeLimit,
 		uploaders:                uploaders,
@@ -175,6 +221,37 @@ func (e *Manager) VM() computer.VirtualMachine {
 	return e.vm
 }
 
+// WarmUpPrograms loads the programs persisted by the programs cache into the
+// derived data of the given block, whose execution state at the end of the
+// block is read by the view.  It is a no-op when the programs cache is
+// disabled.
+func (e *Manager) WarmUpPrograms(blockHeader *flow.Header, view state.View) error {
+	if e.programsCache == nil {
+		return nil
+	}
+
+	vm, ok := e.vm.(programcache.VirtualMachine)
+	if !ok {
+		e.log.Warn().Msg("virtual machine can't load programs, skipping programs cache warm-up")
+		return nil
+	}
+
+	blockCtx := fvm.NewContextFromParent(
+		e.vmCtx,
+		fvm.WithBlockHeader(blockHeader),
+		fvm.WithDerivedBlockData(
+			e.derivedChainData.GetOrCreateDerivedBlockData(
+				blockHeader.ID(),
+				blockHeader.ParentID)))
+
+	err := e.programsCache.WarmUp(vm, blockCtx, view)
+	if err != nil {
+		return fmt.Errorf("failed to warm up programs cache: %w", err)
+	}
+
+	return nil
+}
+
 func (e *Manager) ExecuteScript(
 	ctx context.Context,
 	code []byte,
@@ -303,6 +380,14 @@ func (e *Manager) ComputeBlock(
 		Hex("block_id", logging.Entity(block.Block)).
 		Msg("block result computed / derived data cache updated")
 
+	if e.programsCache != nil {
+		// the programs cache reads the block's execution state from the
+		// block view, which requires a delta view.
+		if blockView, ok := view.(*delta.View); ok {
+			e.programsCache.BlockExecuted(block.ID(), derivedBlockData, blockView.Peek)
+		}
+	}
+
 	if uploadEnabled {
 		var group errgroup.Group
 
@@ -345,3 +430,238 @@ func (e *Manager) GetAccount(address flow.Address, blockHeader *flow.Header, vie
 
 	return account, nil
 }
+
+// SimulateTransaction executes the transaction against the state as of the given block, without
+// committing its changes to the view, and returns the events, error, resource usage and register
+// updates of the transaction. The signature and sequence number checks are skipped if skipChecks
+// is true.
+func (e *Manager) SimulateTransaction(
+	tx *flow.TransactionBody,
+	blockHeader *flow.Header,
+	view state.View,
+	skipChecks bool,
+) (*flow.TransactionSimulationResult, error) {
+	startedAt := time.Now()
+	txID := tx.ID()
+
+	options := []fvm.Option{
+		fvm.WithBlockHeader(blockHeader),
+		fvm.WithDerivedBlockData(
+			e.derivedChainData.NewDerivedBlockDataForScript(blockHeader.ID())),
+	}
+	if skipChecks {
+		options = append(options,
+			fvm.WithAuthorizationChecksEnabled(false),
+			fvm.WithSequenceNumberCheckAndIncrementEnabled(false))
+	}
+	blockCtx := fvm.NewContextFromParent(e.vmCtx, options...)
+
+	proc := fvm.Transaction(tx, 0)
+	txView := view.NewChild()
+
+	err := e.vm.Run(blockCtx, proc, txView)
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate transaction %v (internal error): %w", txID, err)
+	}
+
+	ids, values := txView.RegisterUpdates()
+	writeSet := make(flow.RegisterEntries, len(ids))
+	for i, id := range ids {
+		writeSet[i] = flow.RegisterEntry{
+			Key:   id,
+			Value: values[i],
+		}
+	}
+
+	result := &flow.TransactionSimulationResult{
+		TransactionID:   txID,
+		BlockID:         blockHeader.ID(),
+		Events:          proc.Events,
+		ComputationUsed: proc.ComputationUsed,
+		MemoryEstimate:  proc.MemoryEstimate,
+		WriteSet:        writeSet,
+	}
+	if proc.Err != nil {
+		result.ErrorCode = uint16(proc.Err.Code())
+		result.ErrorMessage = proc.Err.Error()
+	}
+
+	e.log.Debug().
+		Hex("tx_id", txID[:]).
+		Hex("block_id", logging.ID(result.BlockID)).
+		Bool("skip_checks", skipChecks).
+		Bool("failed", proc.Err != nil).
+		Uint64("computation_used", proc.ComputationUsed).
+		Int("registers_updated", len(writeSet)).
+		Dur("duration", time.Since(startedAt)).
+		Msg("transaction simulated")
+
+	return result, nil
+}
+
+// EstimateTransactionFees executes the transaction against the state as of the given block with
+// the maximum gas limit, without committing its changes to the view, and returns the resources
+// used by the transaction together with the fees the FlowFees contract would charge for it.
+// The signature and sequence number checks are skipped, since raising the gas limit invalidates
+// the signatures of the transaction.
+func (e *Manager) EstimateTransactionFees(
+	tx *flow.TransactionBody,
+	blockHeader *flow.Header,
+	view state.View,
+) (*flow.TransactionFeesEstimate, error) {
+	startedAt := time.Now()
+	txID := tx.ID()
+
+	estimated := *tx
+	estimated.GasLimit = flow.DefaultMaxTransactionGasLimit
+
+	// fees are computed separately, so that the estimation does not depend
+	// on the balance of the payer
+	blockCtx := fvm.NewContextFromParent(
+		e.vmCtx,
+		fvm.WithBlockHeader(blockHeader),
+		fvm.WithDerivedBlockData(
+			e.derivedChainData.NewDerivedBlockDataForScript(blockHeader.ID())),
+		fvm.WithAuthorizationChecksEnabled(false),
+		fvm.WithSequenceNumberCheckAndIncrementEnabled(false),
+		fvm.WithTransactionFeesEnabled(false))
+
+	proc := fvm.Transaction(&estimated, 0)
+	err := e.vm.Run(blockCtx, proc, view.NewChild())
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate fees of transaction %v (internal error): %w", txID, err)
+	}
+
+	intensities := make(map[uint]uint, len(proc.ComputationIntensities))
+	for kind, intensity := range proc.ComputationIntensities {
+		intensities[uint(kind)] = intensity
+	}
+
+	estimate := &flow.TransactionFeesEstimate{
+		TransactionID:          txID,
+		BlockID:                blockHeader.ID(),
+		ComputationUsed:        proc.ComputationUsed,
+		ComputationIntensities: intensities,
+		MemoryEstimate:         proc.MemoryEstimate,
+		StorageBytesRead:       proc.StorageBytesRead,
+		StorageBytesWritten:    proc.StorageBytesWritten,
+	}
+	if proc.Err != nil {
+		estimate.ErrorCode = uint16(proc.Err.Code())
+		estimate.ErrorMessage = proc.Err.Error()
+	}
+
+	if e.vmCtx.TransactionFeesEnabled {
+		estimate.InclusionFee, estimate.ExecutionFee, err = e.computeFees(
+			blockCtx,
+			view,
+			tx.InclusionEffort(),
+			proc.ComputationUsed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute fees of transaction %v: %w", txID, err)
+		}
+	}
+
+	e.log.Debug().
+		Hex("tx_id", txID[:]).
+		Hex("block_id", logging.ID(estimate.BlockID)).
+		Bool("failed", proc.Err != nil).
+		Uint64("computation_used", estimate.ComputationUsed).
+		Uint64("total_fee", estimate.TotalFee()).
+		Dur("duration", time.Since(startedAt)).
+		Msg("transaction fees estimated")
+
+	return estimate, nil
+}
+
+// TraceTransaction replays the transactions of the given block, starting from the execution state
+// at the start of the block, and returns the execution trace of the transaction at txIndex.  The
+// transactions preceding txIndex are executed untraced, so that the traced transaction observes the
+// same state as it did when the block was executed.
+func (e *Manager) TraceTransaction(
+	blockHeader *flow.Header,
+	transactions []*flow.TransactionBody,
+	txIndex uint32,
+	view state.View,
+) (*tracing.ExecutionTrace, error) {
+	if int(txIndex) >= len(transactions) {
+		return nil, fmt.Errorf("transaction index %d out of range, block %v has %d transactions", txIndex, blockHeader.ID(), len(transactions))
+	}
+
+	startedAt := time.Now()
+
+	blockCtx := fvm.NewContextFromParent(
+		e.vmCtx,
+		fvm.WithBlockHeader(blockHeader),
+		fvm.WithDerivedBlockData(derived.NewEmptyDerivedBlockData()))
+
+	for i, tx := range transactions[:txIndex] {
+		txView := view.NewChild()
+		err := e.vm.Run(blockCtx, fvm.Transaction(tx, uint32(i)), txView)
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay transaction %v (internal error): %w", tx.ID(), err)
+		}
+
+		err = view.MergeView(txView)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge view of transaction %v: %w", tx.ID(), err)
+		}
+	}
+
+	tx := transactions[txIndex]
+	trace := tracing.NewExecutionTrace()
+	traceCtx := fvm.NewContextFromParent(blockCtx, fvm.WithExecutionTrace(trace))
+
+	err := e.vm.Run(traceCtx, fvm.Transaction(tx, txIndex), view.NewChild())
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace transaction %v (internal error): %w", tx.ID(), err)
+	}
+
+	e.log.Debug().
+		Hex("tx_id", logging.Entity(tx)).
+		Hex("block_id", logging.ID(blockHeader.ID())).
+		Uint32("tx_index", txIndex).
+		Int("trace_entries", len(trace.Entries())).
+		Dur("duration", time.Since(startedAt)).
+		Msg("transaction traced")
+
+	return trace, nil
+}
+
+// computeFees returns the inclusion and execution fees the FlowFees contract
+// charges for the given inclusion and execution effort.
+func (e *Manager) computeFees(
+	blockCtx fvm.Context,
+	view state.View,
+	inclusionEffort uint64,
+	executionEffort uint64,
+) (uint64, uint64, error) {
+	code, args := blueprints.ComputeFeesScript(
+		environment.FlowFeesAddress(blockCtx.Chain),
+		inclusionEffort,
+		executionEffort)
+
+	script := fvm.Script(code).WithArguments(args...)
+	err := e.vm.Run(blockCtx, script, view.NewChild())
+	if err != nil {
+		return 0, 0, err
+	}
+	if script.Err != nil {
+		return 0, 0, script.Err
+	}
+
+	fees, ok := script.Value.(cadence.Array)
+	if !ok || len(fees.Values) != 2 {
+		return 0, 0, fmt.Errorf("unexpected fees value: %v", script.Value)
+	}
+	inclusionFee, ok := fees.Values[0].(cadence.UFix64)
+	if !ok {
+		return 0, 0, fmt.Errorf("unexpected inclusion fee value: %v", fees.Values[0])
+	}
+	totalFee, ok := fees.Values[1].(cadence.UFix64)
+	if !ok {
+		return 0, 0, fmt.Errorf("unexpected total fee value: %v", fees.Values[1])
+	}
+
+	return uint64(inclusionFee), uint64(totalFee - inclusionFee), nil
+}
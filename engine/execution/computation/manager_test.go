@@ -3,6 +3,7 @@ package computation
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"fmt"
 	"math"
 	"sync"
@@ -39,6 +40,7 @@ import (
 	"github.com/onflow/flow-go/fvm/derived"
 	fvmErrors "github.com/onflow/flow-go/fvm/errors"
 	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/fvm/tracing"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
 	"github.com/onflow/flow-go/module/executiondatasync/provider"
@@ -288,6 +290,329 @@ func TestExecuteScript(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestSimulateTransaction(t *testing.T) {
+
+	logger := zerolog.Nop()
+
+	chain := flow.Testnet.Chain()
+	execCtx := fvm.NewContext(fvm.WithLogger(logger), fvm.WithChain(chain))
+
+	me := new(module.Local)
+	me.On("NodeID").Return(flow.ZeroID)
+	me.On("SignFunc", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil)
+
+	vm := fvm.NewVirtualMachine()
+
+	ledger := testutil.RootBootstrappedLedger(vm, execCtx, fvm.WithExecutionMemoryLimit(math.MaxUint64))
+
+	view := delta.NewView(ledger.Get)
+
+	bservice := requesterunit.MockBlobService(blockstore.NewBlockstore(dssync.MutexWrap(datastore.NewMapDatastore())))
+	trackerStorage := new(mocktracker.Storage)
+	trackerStorage.On("Update", mock.Anything).Return(func(fn tracker.UpdateFn) error {
+		return fn(func(uint64, ...cid.Cid) error { return nil })
+	})
+
+	prov := provider.NewProvider(
+		zerolog.Nop(),
+		metrics.NewNoopCollector(),
+		execution_data.DefaultSerializer,
+		bservice,
+		trackerStorage,
+	)
+
+	manager, err := New(logger,
+		metrics.NewNoopCollector(),
+		trace.NewNoopTracer(),
+		me,
+		nil,
+		execCtx,
+		committer.NewNoopViewCommitter(),
+		nil,
+		prov,
+		ComputationConfig{
+			DerivedDataCacheSize:     derived.DefaultDerivedDataCacheSize,
+			ScriptLogThreshold:       scriptLogThreshold,
+			ScriptExecutionTimeLimit: DefaultScriptExecutionTimeLimit,
+		},
+	)
+	require.NoError(t, err)
+
+	// an unsigned transaction, which saves a value in the service account's storage
+	tx := flow.NewTransactionBody().
+		SetScript([]byte(`
+			transaction {
+				prepare(signer: AuthAccount) {
+					signer.save(42, to: /storage/simulated)
+				}
+			}
+		`)).
+		SetProposalKey(chain.ServiceAddress(), 0, 0).
+		SetPayer(chain.ServiceAddress()).
+		AddAuthorizer(chain.ServiceAddress())
+
+	header := unittest.BlockHeaderFixture()
+
+	t.Run("checks skipped", func(t *testing.T) {
+		result, err := manager.SimulateTransaction(tx, header, view, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, tx.ID(), result.TransactionID)
+		assert.Equal(t, header.ID(), result.BlockID)
+		assert.Empty(t, result.ErrorMessage)
+		assert.Zero(t, result.ErrorCode)
+		assert.NotZero(t, result.ComputationUsed)
+		assert.NotZero(t, result.MemoryEstimate)
+
+		serviceOwner := string(chain.ServiceAddress().Bytes())
+		require.NotEmpty(t, result.WriteSet)
+		updatesServiceAccount := false
+		for _, entry := range result.WriteSet {
+			if entry.Key.Owner == serviceOwner {
+				updatesServiceAccount = true
+			}
+		}
+		assert.True(t, updatesServiceAccount)
+
+		// the changes of the simulated transaction are not committed
+		ids, _ := view.RegisterUpdates()
+		assert.Empty(t, ids)
+	})
+
+	t.Run("checks enforced", func(t *testing.T) {
+		result, err := manager.SimulateTransaction(tx, header, view, false)
+		require.NoError(t, err)
+
+		// the transaction is not signed
+		assert.NotEmpty(t, result.ErrorMessage)
+		assert.NotZero(t, result.ErrorCode)
+	})
+}
+
+func TestEstimateTransactionFees(t *testing.T) {
+
+	logger := zerolog.Nop()
+
+	chain := flow.Testnet.Chain()
+	execCtx := fvm.NewContext(
+		fvm.WithLogger(logger),
+		fvm.WithChain(chain),
+		fvm.WithTransactionFeesEnabled(true))
+
+	me := new(module.Local)
+	me.On("NodeID").Return(flow.ZeroID)
+	me.On("SignFunc", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil)
+
+	vm := fvm.NewVirtualMachine()
+
+	inclusionEffortCost, err := cadence.NewUFix64("0.00001")
+	require.NoError(t, err)
+	executionEffortCost, err := cadence.NewUFix64("1.0")
+	require.NoError(t, err)
+
+	ledger := testutil.RootBootstrappedLedger(
+		vm,
+		execCtx,
+		fvm.WithExecutionMemoryLimit(math.MaxUint64),
+		fvm.WithTransactionFee(fvm.BootstrapProcedureFeeParameters{
+			SurgeFactor:         cadence.UFix64(100_000_000),
+			InclusionEffortCost: inclusionEffortCost,
+			ExecutionEffortCost: executionEffortCost,
+		}))
+
+	view := delta.NewView(ledger.Get)
+
+	bservice := requesterunit.MockBlobService(blockstore.NewBlockstore(dssync.MutexWrap(datastore.NewMapDatastore())))
+	trackerStorage := new(mocktracker.Storage)
+	trackerStorage.On("Update", mock.Anything).Return(func(fn tracker.UpdateFn) error {
+		return fn(func(uint64, ...cid.Cid) error { return nil })
+	})
+
+	prov := provider.NewProvider(
+		zerolog.Nop(),
+		metrics.NewNoopCollector(),
+		execution_data.DefaultSerializer,
+		bservice,
+		trackerStorage,
+	)
+
+	manager, err := New(logger,
+		metrics.NewNoopCollector(),
+		trace.NewNoopTracer(),
+		me,
+		nil,
+		execCtx,
+		committer.NewNoopViewCommitter(),
+		nil,
+		prov,
+		ComputationConfig{
+			DerivedDataCacheSize:     derived.DefaultDerivedDataCacheSize,
+			ScriptLogThreshold:       scriptLogThreshold,
+			ScriptExecutionTimeLimit: DefaultScriptExecutionTimeLimit,
+		},
+	)
+	require.NoError(t, err)
+
+	header := unittest.BlockHeaderFixture()
+
+	t.Run("successful transaction", func(t *testing.T) {
+		// an unsigned transaction with a gas limit too low to execute it
+		tx := flow.NewTransactionBody().
+			SetScript([]byte(`
+				transaction {
+					prepare(signer: AuthAccount) {
+						var i = 0
+						while i < 100 {
+							i = i + 1
+						}
+						signer.save(i, to: /storage/estimated)
+					}
+				}
+			`)).
+			SetGasLimit(1).
+			SetProposalKey(chain.ServiceAddress(), 0, 0).
+			SetPayer(chain.ServiceAddress()).
+			AddAuthorizer(chain.ServiceAddress())
+
+		estimate, err := manager.EstimateTransactionFees(tx, header, view)
+		require.NoError(t, err)
+
+		assert.Equal(t, tx.ID(), estimate.TransactionID)
+		assert.Equal(t, header.ID(), estimate.BlockID)
+		assert.Empty(t, estimate.ErrorMessage)
+		assert.Zero(t, estimate.ErrorCode)
+
+		assert.Greater(t, estimate.ComputationUsed, uint64(1))
+		assert.NotZero(t, estimate.ComputationIntensities[uint(common.ComputationKindLoop)])
+		assert.NotZero(t, estimate.MemoryEstimate)
+		assert.NotZero(t, estimate.StorageBytesRead)
+		assert.NotZero(t, estimate.StorageBytesWritten)
+
+		// the inclusion effort is 1.0, and the execution effort is the computation used
+		assert.Equal(t, uint64(inclusionEffortCost), estimate.InclusionFee)
+		assert.Equal(t, estimate.ComputationUsed, estimate.ExecutionFee)
+		assert.Equal(t, uint64(inclusionEffortCost)+estimate.ComputationUsed, estimate.TotalFee())
+
+		// the changes of the estimated transaction are not committed
+		ids, _ := view.RegisterUpdates()
+		assert.Empty(t, ids)
+	})
+
+	t.Run("failed transaction", func(t *testing.T) {
+		tx := flow.NewTransactionBody().
+			SetScript([]byte(`
+				transaction {
+					prepare(signer: AuthAccount) {
+						panic("estimated")
+					}
+				}
+			`)).
+			SetProposalKey(chain.ServiceAddress(), 0, 0).
+			SetPayer(chain.ServiceAddress()).
+			AddAuthorizer(chain.ServiceAddress())
+
+		estimate, err := manager.EstimateTransactionFees(tx, header, view)
+		require.NoError(t, err)
+
+		assert.Contains(t, estimate.ErrorMessage, "estimated")
+		assert.NotZero(t, estimate.ErrorCode)
+		assert.Equal(t, uint64(inclusionEffortCost), estimate.InclusionFee)
+	})
+}
+
+func TestTraceTransaction(t *testing.T) {
+
+	logger := zerolog.Nop()
+
+	chain := flow.Testnet.Chain()
+	execCtx := fvm.NewContext(
+		fvm.WithLogger(logger),
+		fvm.WithChain(chain),
+		fvm.WithAuthorizationChecksEnabled(false),
+		fvm.WithSequenceNumberCheckAndIncrementEnabled(false))
+
+	me := new(module.Local)
+	me.On("NodeID").Return(flow.ZeroID)
+	me.On("SignFunc", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, nil)
+
+	vm := fvm.NewVirtualMachine()
+	ledger := testutil.RootBootstrappedLedger(vm, execCtx)
+
+	manager, err := New(logger,
+		metrics.NewNoopCollector(),
+		trace.NewNoopTracer(),
+		me,
+		nil,
+		execCtx,
+		committer.NewNoopViewCommitter(),
+		nil,
+		nil,
+		ComputationConfig{
+			DerivedDataCacheSize:     derived.DefaultDerivedDataCacheSize,
+			ScriptLogThreshold:       scriptLogThreshold,
+			ScriptExecutionTimeLimit: DefaultScriptExecutionTimeLimit,
+		},
+	)
+	require.NoError(t, err)
+
+	header := unittest.BlockHeaderFixture()
+
+	createAccount := func() *flow.TransactionBody {
+		return flow.NewTransactionBody().
+			SetScript([]byte(`
+				transaction {
+					prepare(signer: AuthAccount) {
+						AuthAccount(payer: signer)
+					}
+				}
+			`)).
+			SetPayer(chain.ServiceAddress()).
+			AddAuthorizer(chain.ServiceAddress())
+	}
+	transactions := []*flow.TransactionBody{createAccount(), createAccount()}
+
+	// addressStates returns the values of the address generator state read by the traced transaction
+	addressStates := func(trace *tracing.ExecutionTrace) []string {
+		var values []string
+		for _, entry := range trace.Entries() {
+			if entry.Kind == tracing.EntryKindRegisterRead &&
+				entry.Key == hex.EncodeToString([]byte(state.AddressStateKey)) {
+				values = append(values, entry.Value)
+			}
+		}
+		return values
+	}
+
+	first, err := manager.TraceTransaction(header, transactions, 0, delta.NewView(ledger.Get))
+	require.NoError(t, err)
+
+	second, err := manager.TraceTransaction(header, transactions, 1, delta.NewView(ledger.Get))
+	require.NoError(t, err)
+
+	// the second transaction observes the account created by the first transaction
+	require.NotEmpty(t, addressStates(first))
+	require.NotEmpty(t, addressStates(second))
+	require.NotEqual(t, addressStates(first), addressStates(second))
+
+	for _, trace := range []*tracing.ExecutionTrace{first, second} {
+		kinds := make(map[tracing.EntryKind]struct{})
+		for _, entry := range trace.Entries() {
+			kinds[entry.Kind] = struct{}{}
+		}
+		require.Contains(t, kinds, tracing.EntryKindRegisterWrite)
+		require.Contains(t, kinds, tracing.EntryKindFunctionEnter)
+		require.Contains(t, kinds, tracing.EntryKindEvent)
+	}
+
+	t.Run("index out of range", func(t *testing.T) {
+		_, err := manager.TraceTransaction(header, transactions, 2, delta.NewView(ledger.Get))
+		require.Error(t, err)
+	})
+}
+
 // Balance script used to swallow errors, which meant that even if the view was empty, a script that did nothing but get
 // the balance of an account would succeed and return 0.
 func TestExecuteScript_BalanceScriptFailsIfViewIsEmpty(t *testing.T) {
@@ -13,6 +13,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	state "github.com/onflow/flow-go/fvm/state"
+
+	tracing "github.com/onflow/flow-go/fvm/tracing"
 )
 
 // ComputationManager is an autogenerated mock type for the ComputationManager type
@@ -43,6 +45,29 @@ func (_m *ComputationManager) ComputeBlock(ctx context.Context, block *entity.Ex
 	return r0, r1
 }
 
+// EstimateTransactionFees provides a mock function with given fields: tx, header, view
+func (_m *ComputationManager) EstimateTransactionFees(tx *flow.TransactionBody, header *flow.Header, view state.View) (*flow.TransactionFeesEstimate, error) {
+	ret := _m.Called(tx, header, view)
+
+	var r0 *flow.TransactionFeesEstimate
+	if rf, ok := ret.Get(0).(func(*flow.TransactionBody, *flow.Header, state.View) *flow.TransactionFeesEstimate); ok {
+		r0 = rf(tx, header, view)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*flow.TransactionFeesEstimate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*flow.TransactionBody, *flow.Header, state.View) error); ok {
+		r1 = rf(tx, header, view)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExecuteScript provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
 func (_m *ComputationManager) ExecuteScript(_a0 context.Context, _a1 []byte, _a2 [][]byte, _a3 *flow.Header, _a4 state.View) ([]byte, error) {
 	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
@@ -89,6 +114,52 @@ func (_m *ComputationManager) GetAccount(addr flow.Address, header *flow.Header,
 	return r0, r1
 }
 
+// SimulateTransaction provides a mock function with given fields: tx, header, view, skipChecks
+func (_m *ComputationManager) SimulateTransaction(tx *flow.TransactionBody, header *flow.Header, view state.View, skipChecks bool) (*flow.TransactionSimulationResult, error) {
+	ret := _m.Called(tx, header, view, skipChecks)
+
+	var r0 *flow.TransactionSimulationResult
+	if rf, ok := ret.Get(0).(func(*flow.TransactionBody, *flow.Header, state.View, bool) *flow.TransactionSimulationResult); ok {
+		r0 = rf(tx, header, view, skipChecks)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*flow.TransactionSimulationResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*flow.TransactionBody, *flow.Header, state.View, bool) error); ok {
+		r1 = rf(tx, header, view, skipChecks)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TraceTransaction provides a mock function with given fields: header, transactions, txIndex, view
+func (_m *ComputationManager) TraceTransaction(header *flow.Header, transactions []*flow.TransactionBody, txIndex uint32, view state.View) (*tracing.ExecutionTrace, error) {
+	ret := _m.Called(header, transactions, txIndex, view)
+
+	var r0 *tracing.ExecutionTrace
+	if rf, ok := ret.Get(0).(func(*flow.Header, []*flow.TransactionBody, uint32, state.View) *tracing.ExecutionTrace); ok {
+		r0 = rf(header, transactions, txIndex, view)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*tracing.ExecutionTrace)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*flow.Header, []*flow.TransactionBody, uint32, state.View) error); ok {
+		r1 = rf(header, transactions, txIndex, view)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewComputationManager interface {
 	mock.TestingT
 	Cleanup(func())
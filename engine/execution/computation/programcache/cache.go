@@ -0,0 +1,494 @@
+package programcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/multierr"
+
+	"github.com/onflow/flow-go/crypto/hash"
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/derived"
+	"github.com/onflow/flow-go/fvm/environment"
+	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+)
+
+const (
+	// IndexFileName is the name of the file storing the index of the cached
+	// programs in the cache dir.
+	IndexFileName = "programs.json"
+
+	// indexVersion is the version of the index format.  Indices of other
+	// versions are discarded.
+	indexVersion = 1
+)
+
+// Config programs cache parameters.
+type Config struct {
+	// Dir is the directory in which the index of the cached programs is
+	// stored.
+	Dir string
+	// PersistInterval is the interval between writes of the index.
+	PersistInterval time.Duration
+}
+
+// VirtualMachine is the virtual machine used to load the cached programs.
+type VirtualMachine interface {
+	RunUncommitted(
+		fvm.Context,
+		fvm.Procedure,
+		state.View,
+	) (
+		*derived.DerivedTransactionData,
+		error,
+	)
+}
+
+type registerHash struct {
+	Owner []byte    `json:"owner"`
+	Key   []byte    `json:"key"`
+	Hash  hash.Hash `json:"hash"`
+}
+
+// dependency is a contract loaded while parsing and checking a program
+// (including the program's own contract).
+type dependency struct {
+	Address  flow.Address `json:"address"`
+	Name     string       `json:"name"`
+	CodeHash hash.Hash    `json:"code_hash"`
+}
+
+type program struct {
+	Address      flow.Address `json:"address"`
+	Name         string       `json:"name"`
+	Dependencies []dependency `json:"dependencies"`
+}
+
+type index struct {
+	Version int             `json:"version"`
+	BlockID flow.Identifier `json:"block_id"`
+
+	// MeterParamOverrides are the registers from which the block's meter
+	// param overrides were computed.
+	MeterParamOverrides []registerHash `json:"meter_param_overrides"`
+
+	Programs []program `json:"programs"`
+}
+
+type executedBlock struct {
+	blockID          flow.Identifier
+	derivedBlockData *derived.DerivedBlockData
+	getRegister      delta.GetRegisterFunc
+}
+
+// Cache persists the programs cached in the derived data of the latest
+// executed block, so that they can be loaded on startup instead of being
+// parsed and checked again by the first transactions executed after a restart.
+//
+// Parsed and checked programs cannot be serialized, so the cache persists an
+// index of the cached programs instead: the location of every program, along
+// with the code hashes of the contracts loaded while parsing and checking the
+// program (i.e. the program's dependencies).  On startup, the indexed programs
+// are loaded from the execution state of the latest executed block, by
+// importing them in scripts executed at the end of the block.
+//
+// Indexed programs are dropped following the rules of the derived data
+// invalidator: a program is dropped if any of its dependencies was updated,
+// or if the account of any of its dependencies was frozen, and all programs
+// are dropped if the meter param overrides were updated.
+type Cache struct {
+	unit    *engine.Unit
+	log     zerolog.Logger
+	metrics module.ExecutionMetrics
+	cfg     Config
+
+	// persistLock serializes the writes of the index.
+	persistLock sync.Mutex
+
+	mu sync.Mutex
+	// latest is nil when the latest executed block was already persisted.
+	latest *executedBlock
+}
+
+// New creates a new programs cache, which writes the index of the cached
+// programs every interval once it is ready, and when it is done.
+func New(log zerolog.Logger, metrics module.ExecutionMetrics, cfg Config) (*Cache, error) {
+	err := os.MkdirAll(cfg.Dir, os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("could not create programs cache dir %v: %w", cfg.Dir, err)
+	}
+
+	return &Cache{
+		unit:    engine.NewUnit(),
+		log:     log.With().Str("component", "programs_cache").Logger(),
+		metrics: metrics,
+		cfg:     cfg,
+	}, nil
+}
+
+func (c *Cache) Ready() <-chan struct{} {
+	c.unit.LaunchPeriodically(c.persist, c.cfg.PersistInterval, c.cfg.PersistInterval)
+	return c.unit.Ready()
+}
+
+func (c *Cache) Done() <-chan struct{} {
+	return c.unit.Done(c.persist)
+}
+
+// BlockExecuted records the derived data of an executed block, whose programs
+// are indexed by the next write of the index.  getRegister must read the
+// execution state at the end of the block, without recording the reads.
+func (c *Cache) BlockExecuted(
+	blockID flow.Identifier,
+	derivedBlockData *derived.DerivedBlockData,
+	getRegister delta.GetRegisterFunc,
+) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.latest = &executedBlock{
+		blockID:          blockID,
+		derivedBlockData: derivedBlockData,
+		getRegister:      getRegister,
+	}
+}
+
+func (c *Cache) takeLatest() *executedBlock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	latest := c.latest
+	c.latest = nil
+	return latest
+}
+
+func (c *Cache) persist() {
+	c.persistLock.Lock()
+	defer c.persistLock.Unlock()
+
+	block := c.takeLatest()
+	if block == nil {
+		return
+	}
+
+	logger := c.log.With().Hex("block_id", block.blockID[:]).Logger()
+
+	idx, err := newIndex(block)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to index cached programs")
+		return
+	}
+
+	err = c.writeIndex(idx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to write programs cache index")
+		return
+	}
+
+	logger.Debug().Int("programs", len(idx.Programs)).Msg("programs cache index written")
+}
+
+func hashValue(value flow.RegisterValue) hash.Hash {
+	return hash.NewSHA3_256().ComputeHash(value)
+}
+
+func newIndex(block *executedBlock) (*index, error) {
+	idx := &index{
+		Version: indexVersion,
+		BlockID: block.blockID,
+	}
+
+	meterParamOverridesState := block.derivedBlockData.MeterParamOverridesState()
+	if meterParamOverridesState != nil {
+		for _, id := range meterParamOverridesState.View().AllRegisters() {
+			value, err := block.getRegister(id.Owner, id.Key)
+			if err != nil {
+				return nil, fmt.Errorf("could not read meter param overrides register: %w", err)
+			}
+
+			idx.MeterParamOverrides = append(idx.MeterParamOverrides, registerHash{
+				Owner: []byte(id.Owner),
+				Key:   []byte(id.Key),
+				Hash:  hashValue(value),
+			})
+		}
+	}
+
+	codeHashes := make(map[flow.RegisterID]hash.Hash)
+	for location, programState := range block.derivedBlockData.ProgramStates() {
+		p := program{
+			Address: flow.Address(location.Address),
+			Name:    location.Name,
+		}
+
+		for _, id := range programState.View().AllRegisters() {
+			if !strings.HasPrefix(id.Key, state.CodeKeyPrefix) {
+				continue
+			}
+
+			codeHash, ok := codeHashes[id]
+			if !ok {
+				code, err := block.getRegister(id.Owner, id.Key)
+				if err != nil {
+					return nil, fmt.Errorf("could not read code of dependency of %v: %w", location, err)
+				}
+
+				codeHash = hashValue(code)
+				codeHashes[id] = codeHash
+			}
+
+			p.Dependencies = append(p.Dependencies, dependency{
+				Address:  flow.BytesToAddress([]byte(id.Owner)),
+				Name:     strings.TrimPrefix(id.Key, state.CodeKeyPrefix),
+				CodeHash: codeHash,
+			})
+		}
+
+		sort.Slice(p.Dependencies, func(i, j int) bool {
+			return lessContract(p.Dependencies[i].Address, p.Dependencies[i].Name, p.Dependencies[j].Address, p.Dependencies[j].Name)
+		})
+		idx.Programs = append(idx.Programs, p)
+	}
+
+	sort.Slice(idx.Programs, func(i, j int) bool {
+		return lessContract(idx.Programs[i].Address, idx.Programs[i].Name, idx.Programs[j].Address, idx.Programs[j].Name)
+	})
+
+	return idx, nil
+}
+
+func lessContract(address1 flow.Address, name1 string, address2 flow.Address, name2 string) bool {
+	if address1 != address2 {
+		return address1.Hex() < address2.Hex()
+	}
+	return name1 < name2
+}
+
+func (c *Cache) indexPath() string {
+	return filepath.Join(c.cfg.Dir, IndexFileName)
+}
+
+// writeIndex writes the index to a temp file which is renamed once complete,
+// so that a partially written index is never read.
+func (c *Cache) writeIndex(idx *index) (err error) {
+	f, err := os.CreateTemp(c.cfg.Dir, "programs")
+	if err != nil {
+		return fmt.Errorf("failed to create temp index: %w", err)
+	}
+
+	// Remove temp file if it still exists.
+	defer func() {
+		if _, statErr := os.Stat(f.Name()); errors.Is(statErr, os.ErrNotExist) {
+			return
+		}
+		multierr.AppendInto(&err, os.Remove(f.Name()))
+	}()
+
+	err = json.NewEncoder(f).Encode(idx)
+	multierr.AppendInto(&err, f.Close())
+	if err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+
+	err = os.Rename(f.Name(), c.indexPath())
+	if err != nil {
+		return fmt.Errorf("failed to rename index: %w", err)
+	}
+
+	return nil
+}
+
+// readIndex returns the persisted index, or nil if no index was persisted.
+func (c *Cache) readIndex() (*index, error) {
+	data, err := os.ReadFile(c.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	var idx index
+	err = json.Unmarshal(data, &idx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode index: %w", err)
+	}
+
+	if idx.Version != indexVersion {
+		return nil, fmt.Errorf("unsupported index version: %d", idx.Version)
+	}
+
+	return &idx, nil
+}
+
+// WarmUp loads the persisted programs, whose dependencies did not change,
+// into the derived block data of the given context.  The programs are loaded
+// by scripts executed against the view, so the context's block header and
+// derived block data must be those of the block at the end of which the view
+// reads the execution state.
+//
+// An index which can't be read is ignored, since it will be replaced by the
+// next write.  An error is only returned if the execution state can't be
+// read, or the scripts can't be executed.
+func (c *Cache) WarmUp(vm VirtualMachine, ctx fvm.Context, view state.View) error {
+	start := time.Now()
+
+	idx, err := c.readIndex()
+	if err != nil {
+		c.log.Warn().Err(err).Msg("ignoring programs cache index")
+		return nil
+	}
+	if idx == nil {
+		return nil
+	}
+
+	valid, err := meterParamOverridesUnchanged(idx, view)
+	if err != nil {
+		return err
+	}
+
+	checker := &dependencyChecker{
+		view:   view,
+		frozen: make(map[flow.Address]bool),
+	}
+
+	hits := 0
+	if valid {
+		for _, p := range idx.Programs {
+			ok, err := checker.unchanged(p.Dependencies)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+
+			ok, err = loadProgram(vm, ctx, view, p)
+			if err != nil {
+				return fmt.Errorf("could not load program %s.%s: %w", p.Address, p.Name, err)
+			}
+			if ok {
+				hits++
+			}
+		}
+	}
+
+	misses := len(idx.Programs) - hits
+	c.metrics.ExecutionProgramsCacheWarmedUp(time.Since(start), hits, misses)
+
+	c.log.Info().
+		Hex("persisted_block_id", idx.BlockID[:]).
+		Bool("meter_param_overrides_unchanged", valid).
+		Int("hits", hits).
+		Int("misses", misses).
+		Dur("duration", time.Since(start)).
+		Msg("programs cache warmed up")
+
+	return nil
+}
+
+func meterParamOverridesUnchanged(idx *index, view state.View) (bool, error) {
+	for _, register := range idx.MeterParamOverrides {
+		value, err := view.Get(string(register.Owner), string(register.Key))
+		if err != nil {
+			return false, fmt.Errorf("could not read meter param overrides register: %w", err)
+		}
+
+		if !register.Hash.Equal(hashValue(value)) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+type dependencyChecker struct {
+	view   state.View
+	frozen map[flow.Address]bool
+}
+
+func (checker *dependencyChecker) isFrozen(address flow.Address) (bool, error) {
+	frozen, ok := checker.frozen[address]
+	if ok {
+		return frozen, nil
+	}
+
+	value, err := checker.view.Get(string(address.Bytes()), state.AccountStatusKey)
+	if err != nil {
+		return false, fmt.Errorf("could not read account status of %s: %w", address, err)
+	}
+
+	// accounts which don't exist can't be frozen, their code hashes are
+	// checked instead.
+	if len(value) > 0 {
+		status, err := environment.AccountStatusFromBytes(value)
+		if err != nil {
+			return false, fmt.Errorf("could not decode account status of %s: %w", address, err)
+		}
+		frozen = status.IsAccountFrozen()
+	}
+
+	checker.frozen[address] = frozen
+	return frozen, nil
+}
+
+// unchanged returns true if the code of none of the dependencies was updated,
+// and the account of none of the dependencies is frozen.
+func (checker *dependencyChecker) unchanged(dependencies []dependency) (bool, error) {
+	for _, dep := range dependencies {
+		frozen, err := checker.isFrozen(dep.Address)
+		if err != nil {
+			return false, err
+		}
+		if frozen {
+			return false, nil
+		}
+
+		code, err := checker.view.Get(string(dep.Address.Bytes()), state.CodeKeyPrefix+dep.Name)
+		if err != nil {
+			return false, fmt.Errorf("could not read code of %s.%s: %w", dep.Address, dep.Name, err)
+		}
+
+		if !dep.CodeHash.Equal(hashValue(code)) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// loadProgram loads the program by importing it in a script, and commits the
+// script's derived data.  It returns false if the script failed.
+func loadProgram(vm VirtualMachine, ctx fvm.Context, view state.View, p program) (bool, error) {
+	script := fvm.Script([]byte(fmt.Sprintf(
+		"import %s from %s\n\npub fun main() {}\n",
+		p.Name,
+		p.Address.HexWithPrefix())))
+
+	derivedTxnData, err := vm.RunUncommitted(ctx, script, view.NewChild())
+	if err != nil {
+		return false, err
+	}
+	if script.Err != nil {
+		return false, nil
+	}
+
+	err = derivedTxnData.Commit()
+	if err != nil {
+		return false, fmt.Errorf("could not commit derived data: %w", err)
+	}
+
+	return true, nil
+}
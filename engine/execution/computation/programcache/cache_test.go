@@ -0,0 +1,185 @@
+package programcache_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/computation/programcache"
+	"github.com/onflow/flow-go/engine/execution/testutil"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/derived"
+	"github.com/onflow/flow-go/fvm/environment"
+	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+type warmUpMetrics struct {
+	*metrics.NoopCollector
+
+	calls  int
+	hits   int
+	misses int
+}
+
+func (m *warmUpMetrics) ExecutionProgramsCacheWarmedUp(_ time.Duration, hits, misses int) {
+	m.calls++
+	m.hits = hits
+	m.misses = misses
+}
+
+func TestCache(t *testing.T) {
+	chain := flow.Mainnet.Chain()
+	vm := fvm.NewVirtualMachine()
+	ctx := fvm.NewContext(
+		fvm.WithChain(chain),
+		fvm.WithAuthorizationChecksEnabled(false),
+		fvm.WithSequenceNumberCheckAndIncrementEnabled(false))
+
+	view := testutil.RootBootstrappedLedger(vm, ctx)
+
+	flowToken := common.AddressLocation{
+		Address: common.Address(fvm.FlowTokenAddress(chain)),
+		Name:    "FlowToken",
+	}
+	fungibleToken := common.AddressLocation{
+		Address: common.Address(fvm.FungibleTokenAddress(chain)),
+		Name:    "FungibleToken",
+	}
+
+	// executing a transaction which imports FlowToken caches the FlowToken
+	// program and the programs it imports, e.g. FungibleToken.
+	derivedBlockData := derived.NewEmptyDerivedBlockData()
+	tx := flow.NewTransactionBody().
+		SetScript([]byte(fmt.Sprintf(
+			`
+			import FlowToken from %s
+
+			transaction {}
+			`,
+			fvm.FlowTokenAddress(chain).HexWithPrefix())))
+	proc := fvm.Transaction(tx, 0)
+	err := vm.Run(
+		fvm.NewContextFromParent(ctx, fvm.WithDerivedBlockData(derivedBlockData)),
+		proc,
+		view)
+	require.NoError(t, err)
+	require.NoError(t, proc.Err)
+
+	require.NotNil(t, derivedBlockData.GetProgramForTestingOnly(flowToken))
+	require.NotNil(t, derivedBlockData.GetProgramForTestingOnly(fungibleToken))
+	cachedPrograms := len(derivedBlockData.ProgramStates())
+
+	unittest.RunWithTempDir(t, func(dir string) {
+		cfg := programcache.Config{
+			Dir:             dir,
+			PersistInterval: time.Hour,
+		}
+
+		cache, err := programcache.New(zerolog.Nop(), metrics.NewNoopCollector(), cfg)
+		require.NoError(t, err)
+
+		unittest.AssertClosesBefore(t, cache.Ready(), time.Second)
+		cache.BlockExecuted(unittest.IdentifierFixture(), derivedBlockData, view.Get)
+		// the index is written when the cache is done
+		unittest.AssertClosesBefore(t, cache.Done(), 10*time.Second)
+		require.FileExists(t, filepath.Join(dir, programcache.IndexFileName))
+
+		warmUp := func(t *testing.T, view state.View) (*derived.DerivedBlockData, *warmUpMetrics) {
+			m := &warmUpMetrics{NoopCollector: metrics.NewNoopCollector()}
+			cache, err := programcache.New(zerolog.Nop(), m, cfg)
+			require.NoError(t, err)
+
+			derivedBlockData := derived.NewEmptyDerivedBlockData()
+			warmUpCtx := fvm.NewContextFromParent(
+				ctx,
+				fvm.WithBlockHeader(unittest.BlockHeaderFixture()),
+				fvm.WithDerivedBlockData(derivedBlockData))
+
+			err = cache.WarmUp(vm, warmUpCtx, view)
+			require.NoError(t, err)
+
+			return derivedBlockData, m
+		}
+
+		t.Run("unchanged dependencies", func(t *testing.T) {
+			derivedBlockData, m := warmUp(t, view.NewChild())
+
+			require.Equal(t, 1, m.calls)
+			require.Equal(t, cachedPrograms, m.hits)
+			require.Equal(t, 0, m.misses)
+
+			require.NotNil(t, derivedBlockData.GetProgramForTestingOnly(flowToken))
+			require.NotNil(t, derivedBlockData.GetProgramForTestingOnly(fungibleToken))
+			require.Equal(t, cachedPrograms, len(derivedBlockData.ProgramStates()))
+
+			// the loaded programs are used by the block's transactions
+			txnDerivedData, err := derivedBlockData.NewDerivedTransactionData(0, 0)
+			require.NoError(t, err)
+			_, _, ok := txnDerivedData.GetProgram(flowToken)
+			require.True(t, ok)
+		})
+
+		t.Run("updated dependency", func(t *testing.T) {
+			updated := view.NewChild()
+			owner := string(fungibleToken.Address.Bytes())
+			key := state.CodeKeyPrefix + fungibleToken.Name
+
+			code, err := updated.Get(owner, key)
+			require.NoError(t, err)
+			err = updated.Set(owner, key, append(code, []byte("\n// updated")...))
+			require.NoError(t, err)
+
+			derivedBlockData, m := warmUp(t, updated)
+
+			// FungibleToken and the programs which import it are dropped
+			require.Equal(t, 1, m.calls)
+			require.Positive(t, m.misses)
+			require.Equal(t, cachedPrograms, m.hits+m.misses)
+
+			require.Nil(t, derivedBlockData.GetProgramForTestingOnly(flowToken))
+			require.Nil(t, derivedBlockData.GetProgramForTestingOnly(fungibleToken))
+			require.Equal(t, m.hits, len(derivedBlockData.ProgramStates()))
+		})
+
+		t.Run("frozen dependency account", func(t *testing.T) {
+			frozen := view.NewChild()
+			owner := string(flowToken.Address.Bytes())
+
+			value, err := frozen.Get(owner, state.AccountStatusKey)
+			require.NoError(t, err)
+			status, err := environment.AccountStatusFromBytes(value)
+			require.NoError(t, err)
+			status.SetFrozenFlag(true)
+			err = frozen.Set(owner, state.AccountStatusKey, status.ToBytes())
+			require.NoError(t, err)
+
+			derivedBlockData, m := warmUp(t, frozen)
+
+			require.Equal(t, 1, m.calls)
+			require.Positive(t, m.misses)
+			require.Equal(t, cachedPrograms, m.hits+m.misses)
+
+			require.Nil(t, derivedBlockData.GetProgramForTestingOnly(flowToken))
+		})
+
+		t.Run("corrupted index", func(t *testing.T) {
+			err := os.WriteFile(filepath.Join(dir, programcache.IndexFileName), []byte("{"), 0644)
+			require.NoError(t, err)
+
+			derivedBlockData, m := warmUp(t, view.NewChild())
+
+			// the index is ignored
+			require.Equal(t, 0, m.calls)
+			require.Empty(t, derivedBlockData.ProgramStates())
+		})
+	})
+}
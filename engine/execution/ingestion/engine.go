@@ -18,8 +18,10 @@ import (
 	"github.com/onflow/flow-go/engine/execution/computation"
 	"github.com/onflow/flow-go/engine/execution/computation/computer/uploader"
 	"github.com/onflow/flow-go/engine/execution/provider"
+	exepruner "github.com/onflow/flow-go/engine/execution/pruner"
 	"github.com/onflow/flow-go/engine/execution/state"
 	"github.com/onflow/flow-go/engine/execution/utils"
+	"github.com/onflow/flow-go/fvm/tracing"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/module"
@@ -67,8 +69,10 @@ type Engine struct {
 	syncFast               bool                // sync fast allows execution node to skip fetching collection during state syncing, and rely on state syncing to catch up
 	checkAuthorizedAtBlock func(blockID flow.Identifier) (bool, error)
 	executionDataPruner    *pruner.Pruner
+	executionStatePruner   *exepruner.Pruner
 	uploaders              []uploader.Uploader
 	stopControl            *StopControl
+	forkDetector           *ForkDetector
 }
 
 func New(
@@ -94,8 +98,10 @@ func New(
 	syncFast bool,
 	checkAuthorizedAtBlock func(blockID flow.Identifier) (bool, error),
 	pruner *pruner.Pruner,
+	statePruner *exepruner.Pruner,
 	uploaders []uploader.Uploader,
 	stopControl *StopControl,
+	forkDetector *ForkDetector,
 ) (*Engine, error) {
 	log := logger.With().Str("engine", "ingestion").Logger()
 
@@ -127,8 +133,10 @@ func New(
 		syncFast:               syncFast,
 		checkAuthorizedAtBlock: checkAuthorizedAtBlock,
 		executionDataPruner:    pruner,
+		executionStatePruner:   statePruner,
 		uploaders:              uploaders,
 		stopControl:            stopControl,
+		forkDetector:           forkDetector,
 	}
 
 	// move to state syncing engine
@@ -139,6 +147,21 @@ func New(
 
 	eng.syncConduit = syncConduit
 
+	// execution stays paused on a fork detected before the node was restarted
+	if forkDetector != nil {
+		forkedBlockID, err := forkDetector.forkedBlock()
+		if err != nil {
+			return nil, fmt.Errorf("could not check for execution fork: %w", err)
+		}
+		if forkedBlockID != nil {
+			forkedBlock, err := blocks.ByID(*forkedBlockID)
+			if err != nil {
+				return nil, fmt.Errorf("could not get forked block %v: %w", *forkedBlockID, err)
+			}
+			stopControl.executionForkDetected(forkedBlock.Header, forkDetector.crash)
+		}
+	}
+
 	return &eng, nil
 }
 
@@ -456,6 +479,13 @@ func (e *Engine) BlockProcessable(b *flow.Header) {
 // Method gets called for every finalized block
 func (e *Engine) BlockFinalized(h *flow.Header) {
 	e.stopControl.blockFinalized(e.unit.Ctx(), e.execState, h)
+
+	if e.forkDetector != nil {
+		// reading the sealed blocks and results must not hold up the finalization consumers
+		e.unit.Launch(func() {
+			e.checkSealedResults(h)
+		})
+	}
 }
 
 // Main handling
@@ -598,6 +628,16 @@ func (e *Engine) enqueueBlockAndCheckExecutable(
 // When finish executing, it will check if the children becomes executable and execute them if yes.
 func (e *Engine) executeBlock(ctx context.Context, executableBlock *entity.ExecutableBlock) {
 
+	// execution might have been paused after the block became executable,
+	// e.g. because an execution fork was detected while executing its parent
+	if e.stopControl.IsPaused() {
+		e.log.Warn().
+			Hex("block_id", logging.Entity(executableBlock)).
+			Uint64("height", executableBlock.Block.Header.Height).
+			Msg("skipping block execution, execution is paused")
+		return
+	}
+
 	e.log.Info().
 		Hex("block_id", logging.Entity(executableBlock)).
 		Uint64("height", executableBlock.Block.Header.Height).
@@ -641,6 +681,16 @@ func (e *Engine) executeBlock(ctx context.Context, executableBlock *entity.Execu
 	isExecutedBlockSealed := executableBlock.Block.Header.Height <= lastSealed.Height
 	broadcasted := false
 
+	// the block was sealed before it was executed by this node, so its result can be
+	// compared with the sealed result right away. Otherwise, the result is compared
+	// once the seal is finalized.
+	if isExecutedBlockSealed && e.forkDetector != nil {
+		forked := e.checkSealedResult(executableBlock.Block, &receipt.ExecutionResult, nil)
+		if forked {
+			return
+		}
+	}
+
 	if !isExecutedBlockSealed {
 		authorizedAtBlock, err := e.checkAuthorizedAtBlock(executableBlock.ID())
 		if err != nil {
@@ -688,11 +738,88 @@ func (e *Engine) executeBlock(ctx context.Context, executableBlock *entity.Execu
 		e.executionDataPruner.NotifyFulfilledHeight(executableBlock.Height())
 	}
 
+	// the execution state of a height can be pruned once it is both executed and sealed
+	if e.executionStatePruner != nil {
+		fulfilledHeight := executableBlock.Height()
+		if lastSealed.Height < fulfilledHeight {
+			fulfilledHeight = lastSealed.Height
+		}
+		e.executionStatePruner.NotifyFulfilledHeight(fulfilledHeight)
+	}
+
 	e.unit.Ctx()
 
 	e.stopControl.blockExecuted(executableBlock.Block.Header)
 }
 
+// checkSealedResults compares the sealed results of the seals included in the given finalized
+// block with the results computed by this node, and halts execution if they conflict.
+// Seals of blocks this node has not executed, or whose result it does not have, are skipped.
+func (e *Engine) checkSealedResults(h *flow.Header) {
+	finalized, err := e.blocks.ByID(h.ID())
+	if err != nil {
+		e.log.Err(err).Hex("block_id", logging.ID(h.ID())).Msg("could not get finalized block to check for execution fork")
+		return
+	}
+
+	for _, seal := range finalized.Payload.Seals {
+		ownResultID, err := e.execState.GetExecutionResultID(e.unit.Ctx(), seal.BlockID)
+		if errors.Is(err, storage.ErrNotFound) {
+			// the sealed block was not executed yet, its result is compared once it is executed
+			continue
+		}
+		if err != nil {
+			e.log.Err(err).Hex("block_id", logging.ID(seal.BlockID)).Msg("could not get own execution result ID")
+			continue
+		}
+
+		ownResult, err := e.forkDetector.results.ByID(ownResultID)
+		if err != nil {
+			// e.g. the block's execution state was synced from other nodes
+			e.log.Err(err).Hex("result_id", logging.ID(ownResultID)).Msg("could not get own execution result")
+			continue
+		}
+
+		block, err := e.blocks.ByID(seal.BlockID)
+		if err != nil {
+			e.log.Err(err).Hex("block_id", logging.ID(seal.BlockID)).Msg("could not get sealed block")
+			continue
+		}
+
+		if e.checkSealedResult(block, ownResult, seal) {
+			return
+		}
+	}
+}
+
+// checkSealedResult compares the result computed by this node for the given block with the
+// block's sealed result. If the seal is nil, the block's finalized seal is used.
+// If the results conflict, execution is halted and true is returned.
+func (e *Engine) checkSealedResult(block *flow.Block, ownResult *flow.ExecutionResult, seal *flow.Seal) bool {
+	if seal == nil {
+		var err error
+		seal, err = e.forkDetector.finalizedSeal(block.ID())
+		if err != nil {
+			e.log.Err(err).Hex("block_id", logging.Entity(block)).Msg("could not get seal of executed block")
+			return false
+		}
+		if seal == nil {
+			return false
+		}
+	}
+
+	forked, err := e.forkDetector.check(block, ownResult, seal)
+	if err != nil {
+		e.log.Err(err).Hex("block_id", logging.Entity(block)).Msg("could not check execution result for fork")
+	}
+	if !forked {
+		return false
+	}
+
+	e.stopControl.executionForkDetected(block.Header, e.forkDetector.crash)
+	return true
+}
+
 // we've executed the block, now we need to check:
 // 1. whether the state syncing can be turned off
 // 2. whether its children can be executed
@@ -1132,6 +1259,134 @@ func (e *Engine) GetAccount(ctx context.Context, addr flow.Address, blockID flow
 	return e.computationManager.GetAccount(addr, block, blockView)
 }
 
+// SimulateTransaction executes the transaction against the execution state of the given block,
+// without committing its changes, and returns the result of the transaction.
+func (e *Engine) SimulateTransaction(
+	ctx context.Context,
+	tx *flow.TransactionBody,
+	blockID flow.Identifier,
+	skipChecks bool,
+) (*flow.TransactionSimulationResult, error) {
+	stateCommit, err := e.execState.StateCommitmentByBlockID(ctx, blockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state commitment for block (%s): %w", blockID, err)
+	}
+
+	// return early if state with the given state commitment is not in memory
+	// and already purged.
+	if !e.execState.HasState(stateCommit) {
+		return nil, fmt.Errorf("failed to simulate transaction at block (%s): state commitment not found (%s). this error usually happens if the reference block for this transaction is not set to a recent block", blockID.String(), hex.EncodeToString(stateCommit[:]))
+	}
+
+	block, err := e.state.AtBlockID(blockID).Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block (%s): %w", blockID, err)
+	}
+
+	blockView := e.execState.NewView(stateCommit)
+
+	if e.extensiveLogging {
+		e.log.Debug().
+			Hex("block_id", logging.ID(blockID)).
+			Uint64("block_height", block.Height).
+			Hex("state_commitment", stateCommit[:]).
+			Hex("tx_id", logging.Entity(tx)).
+			Hex("script_hex", tx.Script).
+			Bool("skip_checks", skipChecks).
+			Msg("extensive log: simulated transaction content")
+	}
+
+	return e.computationManager.SimulateTransaction(tx, block, blockView, skipChecks)
+}
+
+// EstimateTransactionFees executes the transaction against the execution state of the latest
+// sealed block with the maximum gas limit, and returns its resource usage and fees.
+func (e *Engine) EstimateTransactionFees(
+	ctx context.Context,
+	tx *flow.TransactionBody,
+) (*flow.TransactionFeesEstimate, error) {
+	block, err := e.state.Sealed().Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest sealed block: %w", err)
+	}
+	blockID := block.ID()
+
+	stateCommit, err := e.execState.StateCommitmentByBlockID(ctx, blockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state commitment for block (%s): %w", blockID, err)
+	}
+
+	// return early if state with the given state commitment is not in memory
+	// and already purged.
+	if !e.execState.HasState(stateCommit) {
+		return nil, fmt.Errorf("failed to estimate transaction fees at block (%s): state commitment not found (%s)", blockID.String(), hex.EncodeToString(stateCommit[:]))
+	}
+
+	blockView := e.execState.NewView(stateCommit)
+
+	if e.extensiveLogging {
+		e.log.Debug().
+			Hex("block_id", logging.ID(blockID)).
+			Uint64("block_height", block.Height).
+			Hex("state_commitment", stateCommit[:]).
+			Hex("tx_id", logging.Entity(tx)).
+			Hex("script_hex", tx.Script).
+			Msg("extensive log: estimated transaction content")
+	}
+
+	return e.computationManager.EstimateTransactionFees(tx, block, blockView)
+}
+
+// TraceTransaction replays the given executed block up to the given transaction, starting from the
+// execution state of its parent block, and returns the execution trace of the transaction.
+// Transactions of the system chunk cannot be traced.
+func (e *Engine) TraceTransaction(
+	ctx context.Context,
+	blockID flow.Identifier,
+	txID flow.Identifier,
+) (*tracing.ExecutionTrace, error) {
+	block, err := e.blocks.ByID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block (%s): %w", blockID, err)
+	}
+
+	var transactions []*flow.TransactionBody
+	txIndex := -1
+	for _, guarantee := range block.Payload.Guarantees {
+		collection, err := e.collections.ByID(guarantee.CollectionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get collection (%s) of block (%s): %w", guarantee.CollectionID, blockID, err)
+		}
+
+		for _, tx := range collection.Transactions {
+			if tx.ID() == txID {
+				txIndex = len(transactions)
+			}
+			transactions = append(transactions, tx)
+		}
+	}
+
+	if txIndex < 0 {
+		return nil, fmt.Errorf("transaction (%s) not found in collections of block (%s)", txID, blockID)
+	}
+
+	parentID := block.Header.ParentID
+	stateCommit, err := e.execState.StateCommitmentByBlockID(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state commitment for parent block (%s): %w", parentID, err)
+	}
+
+	// return early if state with the given state commitment is not in memory
+	// and already purged.
+	if !e.execState.HasState(stateCommit) {
+		return nil, fmt.Errorf("failed to trace transaction in block (%s): state commitment of parent block not found (%s)", blockID.String(), hex.EncodeToString(stateCommit[:]))
+	}
+
+	blockView := e.execState.NewView(stateCommit)
+
+	return e.computationManager.TraceTransaction(block.Header, transactions, uint32(txIndex), blockView)
+}
+
 func (e *Engine) handleComputationResult(
 	ctx context.Context,
 	result *execution.ComputationResult,
@@ -185,7 +185,9 @@ func runWithEngine(t *testing.T, f func(testingContext)) {
 		checkAuthorizedAtBlock,
 		nil,
 		nil,
+		nil,
 		stopControl,
+		nil,
 	)
 	require.NoError(t, err)
 
@@ -1243,6 +1245,135 @@ func TestExecuteScriptAtBlockID(t *testing.T) {
 
 }
 
+func TestSimulateTransaction(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		runWithEngine(t, func(ctx testingContext) {
+			tx := unittest.TransactionBodyFixture()
+
+			// Ensure block we're about to query against is executable
+			blockA := unittest.ExecutableBlockFixture(nil)
+			blockA.StartState = unittest.StateCommitmentPointerFixture()
+
+			snapshot := new(protocol.Snapshot)
+			snapshot.On("Head").Return(blockA.Block.Header, nil)
+
+			ctx.stateCommitmentExist(blockA.ID(), *blockA.StartState)
+
+			ctx.state.On("AtBlockID", blockA.Block.ID()).Return(snapshot)
+			view := new(delta.View)
+			ctx.executionState.On("NewView", *blockA.StartState).Return(view)
+			ctx.executionState.On("HasState", *blockA.StartState).Return(true)
+
+			expected := &flow.TransactionSimulationResult{
+				TransactionID: tx.ID(),
+				BlockID:       blockA.ID(),
+			}
+			ctx.computationManager.
+				On("SimulateTransaction", &tx, blockA.Block.Header, view, true).
+				Return(expected, nil)
+
+			result, err := ctx.engine.SimulateTransaction(context.Background(), &tx, blockA.Block.ID(), true)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, result)
+
+			// Assert other components were called as expected
+			ctx.computationManager.AssertExpectations(t)
+			ctx.executionState.AssertExpectations(t)
+			ctx.state.AssertExpectations(t)
+		})
+	})
+
+	t.Run("return early when state commitment not exist", func(t *testing.T) {
+		runWithEngine(t, func(ctx testingContext) {
+			tx := unittest.TransactionBodyFixture()
+
+			blockA := unittest.ExecutableBlockFixture(nil)
+			blockA.StartState = unittest.StateCommitmentPointerFixture()
+
+			// make sure blockID to state commitment mapping exist
+			ctx.executionState.On("StateCommitmentByBlockID", mock.Anything, blockA.ID()).Return(*blockA.StartState, nil)
+
+			// but the state commitment does not exist (e.g. purged)
+			ctx.executionState.On("HasState", *blockA.StartState).Return(false)
+
+			_, err := ctx.engine.SimulateTransaction(context.Background(), &tx, blockA.Block.ID(), false)
+			assert.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), "state commitment not found"))
+
+			// Assert other components were called as expected
+			ctx.executionState.AssertExpectations(t)
+			ctx.state.AssertExpectations(t)
+		})
+	})
+}
+
+func TestEstimateTransactionFees(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		runWithEngine(t, func(ctx testingContext) {
+			tx := unittest.TransactionBodyFixture()
+
+			// Ensure the latest sealed block is executed
+			blockA := unittest.ExecutableBlockFixture(nil)
+			blockA.StartState = unittest.StateCommitmentPointerFixture()
+
+			snapshot := new(protocol.Snapshot)
+			snapshot.On("Head").Return(blockA.Block.Header, nil)
+
+			ctx.stateCommitmentExist(blockA.ID(), *blockA.StartState)
+
+			ctx.state.On("Sealed").Return(snapshot)
+			view := new(delta.View)
+			ctx.executionState.On("NewView", *blockA.StartState).Return(view)
+			ctx.executionState.On("HasState", *blockA.StartState).Return(true)
+
+			expected := &flow.TransactionFeesEstimate{
+				TransactionID:   tx.ID(),
+				BlockID:         blockA.ID(),
+				ComputationUsed: 10,
+			}
+			ctx.computationManager.
+				On("EstimateTransactionFees", &tx, blockA.Block.Header, view).
+				Return(expected, nil)
+
+			estimate, err := ctx.engine.EstimateTransactionFees(context.Background(), &tx)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, estimate)
+
+			// Assert other components were called as expected
+			ctx.computationManager.AssertExpectations(t)
+			ctx.executionState.AssertExpectations(t)
+			ctx.state.AssertExpectations(t)
+		})
+	})
+
+	t.Run("return early when state commitment not exist", func(t *testing.T) {
+		runWithEngine(t, func(ctx testingContext) {
+			tx := unittest.TransactionBodyFixture()
+
+			blockA := unittest.ExecutableBlockFixture(nil)
+			blockA.StartState = unittest.StateCommitmentPointerFixture()
+
+			snapshot := new(protocol.Snapshot)
+			snapshot.On("Head").Return(blockA.Block.Header, nil)
+			ctx.state.On("Sealed").Return(snapshot)
+
+			// make sure blockID to state commitment mapping exist
+			ctx.executionState.On("StateCommitmentByBlockID", mock.Anything, blockA.ID()).Return(*blockA.StartState, nil)
+
+			// but the state commitment does not exist (e.g. purged)
+			ctx.executionState.On("HasState", *blockA.StartState).Return(false)
+
+			_, err := ctx.engine.EstimateTransactionFees(context.Background(), &tx)
+			assert.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), "state commitment not found"))
+
+			// Assert other components were called as expected
+			ctx.executionState.AssertExpectations(t)
+			ctx.state.AssertExpectations(t)
+		})
+	})
+}
+
 func TestUnauthorizedNodeDoesNotBroadcastReceipts(t *testing.T) {
 	runWithEngine(t, func(ctx testingContext) {
 
@@ -1426,7 +1557,9 @@ func newIngestionEngine(t *testing.T, ps *mocks.ProtocolState, es *mocks.Executi
 		checkAuthorizedAtBlock,
 		nil,
 		nil,
+		nil,
 		NewStopControl(zerolog.Nop(), false, 0),
+		nil,
 	)
 
 	require.NoError(t, err)
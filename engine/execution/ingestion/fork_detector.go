@@ -0,0 +1,294 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/logging"
+)
+
+// ForkDiagnostics is the diagnostics bundle written when the execution result computed
+// by this node conflicts with the sealed result of the same block.
+type ForkDiagnostics struct {
+	BlockID    flow.Identifier `json:"block_id"`
+	Height     uint64          `json:"height"`
+	DetectedAt time.Time       `json:"detected_at"`
+
+	Seal *flow.Seal `json:"seal"`
+
+	OwnResult *flow.ExecutionResult `json:"own_result"`
+
+	// SealedResult is nil if the sealed result is not known to this node,
+	// in which case only the final state commitments can be compared
+	SealedResult *flow.ExecutionResult `json:"sealed_result"`
+
+	DivergingChunks []ChunkDiff `json:"diverging_chunks"`
+}
+
+// ChunkDiff describes a chunk whose own and sealed versions differ.
+// The fields of a version are empty if the result has no chunk at this index.
+type ChunkDiff struct {
+	Index       uint64 `json:"index"`
+	SystemChunk bool   `json:"system_chunk"`
+
+	// CollectionID is the collection executed in the chunk, it is empty for the system chunk
+	CollectionID flow.Identifier `json:"collection_id"`
+
+	OwnStartState    flow.StateCommitment `json:"own_start_state"`
+	SealedStartState flow.StateCommitment `json:"sealed_start_state"`
+	OwnEndState      flow.StateCommitment `json:"own_end_state"`
+	SealedEndState   flow.StateCommitment `json:"sealed_end_state"`
+
+	OwnEventCollection    flow.Identifier `json:"own_event_collection"`
+	SealedEventCollection flow.Identifier `json:"sealed_event_collection"`
+
+	OwnNumberOfTransactions    uint64 `json:"own_number_of_transactions"`
+	SealedNumberOfTransactions uint64 `json:"sealed_number_of_transactions"`
+
+	// Transactions are the transactions of the chunk's collection
+	Transactions []*flow.TransactionBody `json:"transactions"`
+}
+
+// ForkDetector compares the execution results computed by this node with the sealed
+// results of the same blocks. When they conflict, it writes a diagnostics bundle to
+// its directory, so that the fork can be investigated once the node was halted.
+// The first forked block is persisted, so that execution stays paused across restarts
+// until it is removed with the remove-execution-fork util command.
+type ForkDetector struct {
+	log         zerolog.Logger
+	db          *badger.DB
+	seals       storage.Seals
+	results     storage.ExecutionResults
+	collections storage.Collections
+
+	dir   string
+	crash bool
+
+	mu sync.Mutex
+	// blocks for which a fork was already reported
+	reported map[flow.Identifier]struct{}
+}
+
+// NewForkDetector creates a new ForkDetector, writing the diagnostics bundles to the given
+// directory. If crash is set, the node crashes when a fork is detected, otherwise block
+// execution is paused.
+func NewForkDetector(
+	log zerolog.Logger,
+	db *badger.DB,
+	seals storage.Seals,
+	results storage.ExecutionResults,
+	collections storage.Collections,
+	dir string,
+	crash bool,
+) *ForkDetector {
+	return &ForkDetector{
+		log:         log.With().Str("component", "fork_detector").Logger(),
+		db:          db,
+		seals:       seals,
+		results:     results,
+		collections: collections,
+		dir:         dir,
+		crash:       crash,
+		reported:    make(map[flow.Identifier]struct{}),
+	}
+}
+
+// finalizedSeal returns the finalized seal of the given block, or nil if the block is not sealed yet.
+// No errors are expected during normal operation.
+func (d *ForkDetector) finalizedSeal(blockID flow.Identifier) (*flow.Seal, error) {
+	seal, err := d.seals.FinalizedSealForBlock(blockID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get finalized seal for block %v: %w", blockID, err)
+	}
+	return seal, nil
+}
+
+// forkedBlock returns the ID of the persisted forked block, or nil if no fork was detected.
+// No errors are expected during normal operation.
+func (d *ForkDetector) forkedBlock() (*flow.Identifier, error) {
+	var blockID flow.Identifier
+	err := d.db.View(operation.RetrieveExecutionForkedBlock(&blockID))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve forked block: %w", err)
+	}
+	return &blockID, nil
+}
+
+// storeForkedBlock persists the ID of the forked block. Only the first forked block is kept.
+// No errors are expected during normal operation.
+func (d *ForkDetector) storeForkedBlock(blockID flow.Identifier) error {
+	err := operation.RetryOnConflict(d.db.Update, operation.InsertExecutionForkedBlock(blockID))
+	if err != nil && !errors.Is(err, storage.ErrAlreadyExists) {
+		return fmt.Errorf("could not store forked block: %w", err)
+	}
+	return nil
+}
+
+// check compares the execution result computed by this node for the given block with the
+// block's seal. If they conflict, it persists the forked block, writes a diagnostics bundle
+// and returns true.
+// A fork is only reported once per block, later checks of the same block return false.
+// No errors are expected during normal operation.
+func (d *ForkDetector) check(block *flow.Block, ownResult *flow.ExecutionResult, seal *flow.Seal) (bool, error) {
+	if ownResult.ID() == seal.ResultID {
+		return false, nil
+	}
+
+	blockID := block.ID()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.reported[blockID]; ok {
+		return false, nil
+	}
+	d.reported[blockID] = struct{}{}
+
+	err := d.storeForkedBlock(blockID)
+	if err != nil {
+		return true, err
+	}
+
+	diagnostics := &ForkDiagnostics{
+		BlockID:    blockID,
+		Height:     block.Header.Height,
+		DetectedAt: time.Now().UTC(),
+		Seal:       seal,
+		OwnResult:  ownResult,
+	}
+
+	sealedResult, err := d.results.ByID(seal.ResultID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return true, fmt.Errorf("could not get sealed result %v: %w", seal.ResultID, err)
+	}
+	if err == nil {
+		diagnostics.SealedResult = sealedResult
+		diagnostics.DivergingChunks, err = d.divergingChunks(block, ownResult, sealedResult)
+		if err != nil {
+			return true, fmt.Errorf("could not compare chunks: %w", err)
+		}
+	}
+
+	ownFinalState, _ := ownResult.FinalStateCommitment()
+
+	log := d.log.Error().
+		Hex("block_id", blockID[:]).
+		Uint64("height", block.Header.Height).
+		Hex("own_result_id", logging.Entity(ownResult)).
+		Hex("sealed_result_id", logging.ID(seal.ResultID)).
+		Hex("own_final_state", ownFinalState[:]).
+		Hex("sealed_final_state", seal.FinalState[:]).
+		Int("diverging_chunks", len(diagnostics.DivergingChunks))
+
+	path, err := d.write(diagnostics)
+	if err != nil {
+		log.Err(err).Msg("execution fork detected, could not write diagnostics")
+		return true, nil
+	}
+
+	log.Str("diagnostics", path).Msg("execution fork detected")
+	return true, nil
+}
+
+func (d *ForkDetector) divergingChunks(
+	block *flow.Block,
+	ownResult *flow.ExecutionResult,
+	sealedResult *flow.ExecutionResult,
+) ([]ChunkDiff, error) {
+	count := len(ownResult.Chunks)
+	if len(sealedResult.Chunks) > count {
+		count = len(sealedResult.Chunks)
+	}
+
+	guarantees := block.Payload.Guarantees
+
+	var diffs []ChunkDiff
+	for i := 0; i < count; i++ {
+		diff := ChunkDiff{
+			Index: uint64(i),
+			// the system chunk is the last chunk, after the chunks of the collections
+			SystemChunk: i >= len(guarantees),
+		}
+
+		var own, sealed *flow.Chunk
+		if i < len(ownResult.Chunks) {
+			own = ownResult.Chunks[i]
+			diff.OwnStartState = own.StartState
+			diff.OwnEndState = own.EndState
+			diff.OwnEventCollection = own.EventCollection
+			diff.OwnNumberOfTransactions = own.NumberOfTransactions
+		}
+		if i < len(sealedResult.Chunks) {
+			sealed = sealedResult.Chunks[i]
+			diff.SealedStartState = sealed.StartState
+			diff.SealedEndState = sealed.EndState
+			diff.SealedEventCollection = sealed.EventCollection
+			diff.SealedNumberOfTransactions = sealed.NumberOfTransactions
+		}
+
+		if own != nil && sealed != nil && *own == *sealed {
+			continue
+		}
+
+		if !diff.SystemChunk {
+			diff.CollectionID = guarantees[i].CollectionID
+
+			collection, err := d.collections.ByID(diff.CollectionID)
+			if err != nil {
+				return nil, fmt.Errorf("could not get collection %v of chunk %d: %w", diff.CollectionID, i, err)
+			}
+			diff.Transactions = collection.Transactions
+		}
+
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+func (d *ForkDetector) write(diagnostics *ForkDiagnostics) (string, error) {
+	err := os.MkdirAll(d.dir, 0700)
+	if err != nil {
+		return "", fmt.Errorf("could not create diagnostics dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(diagnostics, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("could not encode diagnostics: %w", err)
+	}
+
+	path := filepath.Join(
+		d.dir,
+		fmt.Sprintf("execution-fork-%d-%s.json", diagnostics.Height, diagnostics.BlockID))
+
+	// write to a temp file first, so that an interrupted write doesn't leave a partial bundle
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return "", fmt.Errorf("could not write diagnostics: %w", err)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return "", fmt.Errorf("could not rename diagnostics: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,153 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestForkDetector(t *testing.T) {
+	block := unittest.BlockFixture()
+	block.Payload.Guarantees = unittest.CollectionGuaranteesFixture(2)
+
+	collection := unittest.CollectionFixture(2)
+	block.Payload.Guarantees[1].CollectionID = collection.ID()
+
+	ownResult := unittest.ExecutionResultFixture(unittest.WithBlock(&block))
+
+	// the sealed result diverges from the second chunk on
+	sealedResult := *ownResult
+	sealedResult.Chunks = make(flow.ChunkList, len(ownResult.Chunks))
+	for i, chunk := range ownResult.Chunks {
+		c := *chunk
+		sealedResult.Chunks[i] = &c
+	}
+	sealedResult.Chunks[1].EndState = unittest.StateCommitmentFixture()
+	sealedResult.Chunks[2].StartState = sealedResult.Chunks[1].EndState
+	sealedResult.Chunks[2].EndState = unittest.StateCommitmentFixture()
+
+	// newDB returns a badger DB, which is closed and removed when the test ends
+	newDB := func(t *testing.T) *badger.DB {
+		dir := unittest.TempDir(t)
+		db := unittest.BadgerDB(t, dir)
+		t.Cleanup(func() {
+			require.NoError(t, db.Close())
+			require.NoError(t, os.RemoveAll(dir))
+		})
+		return db
+	}
+
+	readDiagnostics := func(t *testing.T, dir string) *ForkDiagnostics {
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+
+		data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+		require.NoError(t, err)
+
+		var diagnostics ForkDiagnostics
+		require.NoError(t, json.Unmarshal(data, &diagnostics))
+		return &diagnostics
+	}
+
+	t.Run("same result", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			detector := NewForkDetector(unittest.Logger(), newDB(t), nil, nil, nil, dir, false)
+
+			seal := unittest.Seal.Fixture(unittest.Seal.WithResult(ownResult))
+			forked, err := detector.check(&block, ownResult, seal)
+			require.NoError(t, err)
+			require.False(t, forked)
+
+			files, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			require.Empty(t, files)
+
+			forkedBlockID, err := detector.forkedBlock()
+			require.NoError(t, err)
+			require.Nil(t, forkedBlockID)
+		})
+	})
+
+	t.Run("different result", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			results := storagemock.NewExecutionResults(t)
+			results.On("ByID", sealedResult.ID()).Return(&sealedResult, nil).Once()
+
+			collections := storagemock.NewCollections(t)
+			collections.On("ByID", collection.ID()).Return(&collection, nil).Once()
+
+			db := newDB(t)
+			detector := NewForkDetector(unittest.Logger(), db, nil, results, collections, dir, false)
+
+			seal := unittest.Seal.Fixture(unittest.Seal.WithResult(&sealedResult))
+			forked, err := detector.check(&block, ownResult, seal)
+			require.NoError(t, err)
+			require.True(t, forked)
+
+			diagnostics := readDiagnostics(t, dir)
+			require.Equal(t, block.ID(), diagnostics.BlockID)
+			require.Equal(t, block.Header.Height, diagnostics.Height)
+			require.Equal(t, ownResult.ID(), diagnostics.OwnResult.ID())
+			require.Equal(t, sealedResult.ID(), diagnostics.SealedResult.ID())
+
+			require.Len(t, diagnostics.DivergingChunks, 2)
+
+			diff := diagnostics.DivergingChunks[0]
+			require.Equal(t, uint64(1), diff.Index)
+			require.False(t, diff.SystemChunk)
+			require.Equal(t, collection.ID(), diff.CollectionID)
+			require.Equal(t, ownResult.Chunks[1].EndState, diff.OwnEndState)
+			require.Equal(t, sealedResult.Chunks[1].EndState, diff.SealedEndState)
+			require.Len(t, diff.Transactions, len(collection.Transactions))
+			for i, tx := range collection.Transactions {
+				require.Equal(t, tx.ID(), diff.Transactions[i].ID())
+			}
+
+			diff = diagnostics.DivergingChunks[1]
+			require.Equal(t, uint64(2), diff.Index)
+			require.True(t, diff.SystemChunk)
+			require.Empty(t, diff.Transactions)
+
+			// a fork is reported only once per block
+			forked, err = detector.check(&block, ownResult, seal)
+			require.NoError(t, err)
+			require.False(t, forked)
+
+			// the forked block is persisted, so that execution stays paused after a restart
+			restarted := NewForkDetector(unittest.Logger(), db, nil, nil, nil, dir, false)
+			forkedBlockID, err := restarted.forkedBlock()
+			require.NoError(t, err)
+			require.NotNil(t, forkedBlockID)
+			require.Equal(t, block.ID(), *forkedBlockID)
+		})
+	})
+
+	t.Run("unknown sealed result", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			results := storagemock.NewExecutionResults(t)
+			results.On("ByID", sealedResult.ID()).Return(nil, storage.ErrNotFound).Once()
+
+			detector := NewForkDetector(unittest.Logger(), newDB(t), nil, results, nil, dir, false)
+
+			seal := unittest.Seal.Fixture(unittest.Seal.WithResult(&sealedResult))
+			forked, err := detector.check(&block, ownResult, seal)
+			require.NoError(t, err)
+			require.True(t, forked)
+
+			diagnostics := readDiagnostics(t, dir)
+			require.Nil(t, diagnostics.SealedResult)
+			require.Empty(t, diagnostics.DivergingChunks)
+			require.Equal(t, seal.FinalState, diagnostics.Seal.FinalState)
+		})
+	})
+}
@@ -17,4 +17,12 @@ type IngestRPC interface {
 
 	// GetRegisterAtBlockID returns the value of a register at the given Block id (if available)
 	GetRegisterAtBlockID(ctx context.Context, owner, key []byte, blockID flow.Identifier) ([]byte, error)
+
+	// SimulateTransaction executes a transaction at the given Block id without committing its changes,
+	// optionally skipping the signature and sequence number checks
+	SimulateTransaction(ctx context.Context, tx *flow.TransactionBody, blockID flow.Identifier, skipChecks bool) (*flow.TransactionSimulationResult, error)
+
+	// EstimateTransactionFees executes a transaction at the latest sealed Block with the maximum gas limit,
+	// and returns its resource usage and the fees it would be charged
+	EstimateTransactionFees(ctx context.Context, tx *flow.TransactionBody) (*flow.TransactionFeesEstimate, error)
 }
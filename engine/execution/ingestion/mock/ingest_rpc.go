@@ -15,6 +15,29 @@ type IngestRPC struct {
 	mock.Mock
 }
 
+// EstimateTransactionFees provides a mock function with given fields: ctx, tx
+func (_m *IngestRPC) EstimateTransactionFees(ctx context.Context, tx *flow.TransactionBody) (*flow.TransactionFeesEstimate, error) {
+	ret := _m.Called(ctx, tx)
+
+	var r0 *flow.TransactionFeesEstimate
+	if rf, ok := ret.Get(0).(func(context.Context, *flow.TransactionBody) *flow.TransactionFeesEstimate); ok {
+		r0 = rf(ctx, tx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*flow.TransactionFeesEstimate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *flow.TransactionBody) error); ok {
+		r1 = rf(ctx, tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExecuteScriptAtBlockID provides a mock function with given fields: ctx, script, arguments, blockID
 func (_m *IngestRPC) ExecuteScriptAtBlockID(ctx context.Context, script []byte, arguments [][]byte, blockID flow.Identifier) ([]byte, error) {
 	ret := _m.Called(ctx, script, arguments, blockID)
@@ -84,6 +107,29 @@ func (_m *IngestRPC) GetRegisterAtBlockID(ctx context.Context, owner []byte, key
 	return r0, r1
 }
 
+// SimulateTransaction provides a mock function with given fields: ctx, tx, blockID, skipChecks
+func (_m *IngestRPC) SimulateTransaction(ctx context.Context, tx *flow.TransactionBody, blockID flow.Identifier, skipChecks bool) (*flow.TransactionSimulationResult, error) {
+	ret := _m.Called(ctx, tx, blockID, skipChecks)
+
+	var r0 *flow.TransactionSimulationResult
+	if rf, ok := ret.Get(0).(func(context.Context, *flow.TransactionBody, flow.Identifier, bool) *flow.TransactionSimulationResult); ok {
+		r0 = rf(ctx, tx, blockID, skipChecks)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*flow.TransactionSimulationResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *flow.TransactionBody, flow.Identifier, bool) error); ok {
+		r1 = rf(ctx, tx, blockID, skipChecks)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewIngestRPC interface {
 	mock.TestingT
 	Cleanup(func())
@@ -225,6 +225,26 @@ func (s *StopControl) stopExecution() {
 	}
 }
 
+// executionForkDetected should be called when the execution result of the given block conflicts
+// with the block's sealed result. It pauses execution, or crashes the node if crash is set,
+// regardless of the stop height.
+func (s *StopControl) executionForkDetected(h *flow.Header, crash bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	if crash {
+		s.log.Fatal().Msgf("Crashing as the execution result of block %s at height %d conflicts with the sealed result", h.ID(), h.Height)
+	}
+
+	if s.state == StopControlPaused {
+		return
+	}
+
+	s.log.Debug().Int8("previous_state", int8(s.state)).Int8("new_state", int8(StopControlPaused)).Msg("StopControl state transition")
+	s.state = StopControlPaused
+	s.log.Error().Msgf("Pausing execution as the execution result of block %s at height %d conflicts with the sealed result", h.ID(), h.Height)
+}
+
 // executingBlockHeight should be called while execution of height starts, used for internal tracking of the minimum
 // possible value of height
 func (s *StopControl) executingBlockHeight(height uint64) {
@@ -181,3 +181,22 @@ func TestPausedStateRejectsAllBlocksAndChanged(t *testing.T) {
 
 	execState.AssertExpectations(t)
 }
+
+// TestExecutionForkDetected checks that execution is paused when an execution fork is detected,
+// regardless of the stop height
+func TestExecutionForkDetected(t *testing.T) {
+	sc := NewStopControl(unittest.Logger(), false, 0)
+
+	_, _, err := sc.SetStopHeight(21, false)
+	require.NoError(t, err)
+
+	header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(10))
+	sc.executionForkDetected(header, false)
+
+	require.True(t, sc.IsPaused())
+	require.False(t, sc.blockProcessable(unittest.BlockHeaderFixture(unittest.WithHeaderHeight(11))))
+
+	// the stop height can't be changed anymore
+	_, _, err = sc.SetStopHeight(37, false)
+	require.Error(t, err)
+}
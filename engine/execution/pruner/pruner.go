@@ -0,0 +1,284 @@
+package pruner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/module/util"
+)
+
+const (
+	defaultHeightRangeTarget = uint64(1_000_000)
+	defaultThreshold         = uint64(100_000)
+
+	// defaultBatchSize is the number of heights pruned at once. The pruner checks for
+	// shutdown and reports its progress between batches, so that pruning a large range
+	// of heights (e.g. when pruning is first enabled) does not block the node.
+	defaultBatchSize = uint64(1_000)
+)
+
+// Storage is the storage of the historical execution state pruned by the Pruner.
+type Storage interface {
+	// GetPrunedHeight returns the height up to which the historical execution state was pruned.
+	GetPrunedHeight() (uint64, error)
+
+	// PruneUpToHeight removes the historical execution state of all blocks up to and
+	// including the given height.
+	PruneUpToHeight(height uint64) error
+}
+
+// Progress describes the state of the Pruner.
+type Progress struct {
+	// PrunedHeight is the height up to which the historical execution state was pruned
+	PrunedHeight uint64 `json:"pruned_height"`
+
+	// FulfilledHeight is the latest height which was both executed and sealed
+	FulfilledHeight uint64 `json:"fulfilled_height"`
+
+	HeightRangeTarget uint64 `json:"height_range_target"`
+	Threshold         uint64 `json:"threshold"`
+}
+
+// Pruner is a component responsible for pruning the historical execution state
+// (events, transaction results, chunk data packs, ...) of execution nodes, so that
+// only the state of the most recent sealed heights is kept on disk.
+// It is configured with the following parameters:
+//   - Height range target: The target number of most recent sealed blocks
+//     to keep the execution state of.
+//   - Threshold: The number of block heights that we can exceed
+//     the height range target by before pruning is triggered. This
+//     controls the frequency of pruning.
+//
+// The Pruner consumes a stream of fulfilled height notifications,
+// and triggers pruning once the difference between the fulfilled
+// height and the last pruned height reaches the height range
+// target + threshold.
+// A height is considered fulfilled once it has both been executed and sealed.
+type Pruner struct {
+	storage Storage
+
+	// channels used to send new fulfilled heights and config changes to the worker thread
+	fulfilledHeights      chan uint64
+	thresholdChan         chan uint64
+	heightRangeTargetChan chan uint64
+
+	// mu protects the fields below, which are only written by the worker thread,
+	// from concurrent reads by Progress
+	mu                  sync.RWMutex
+	lastFulfilledHeight uint64
+	lastPrunedHeight    uint64
+
+	// the height range is the range of heights between the last pruned and last fulfilled
+	// heightRangeTarget is the target minimum value for this range, so that after pruning
+	// the height range is equal to the target.
+	heightRangeTarget uint64
+
+	// threshold defines the maximum height range and how frequently pruning is performed.
+	// once the height range reaches `heightRangeTarget+threshold`, `threshold` many blocks
+	// are pruned
+	threshold uint64
+
+	batchSize uint64
+
+	logger  zerolog.Logger
+	metrics module.ExecutionMetrics
+
+	component.Component
+	cm *component.ComponentManager
+}
+
+type PrunerOption func(*Pruner)
+
+// WithHeightRangeTarget is used to configure the pruner with a custom
+// height range target.
+func WithHeightRangeTarget(heightRangeTarget uint64) PrunerOption {
+	return func(p *Pruner) {
+		p.heightRangeTarget = heightRangeTarget
+	}
+}
+
+// WithThreshold is used to configure the pruner with a custom threshold.
+func WithThreshold(threshold uint64) PrunerOption {
+	return func(p *Pruner) {
+		p.threshold = threshold
+	}
+}
+
+// WithBatchSize is used to configure the pruner with a custom number of
+// heights pruned at once.
+func WithBatchSize(batchSize uint64) PrunerOption {
+	return func(p *Pruner) {
+		if batchSize > 0 {
+			p.batchSize = batchSize
+		}
+	}
+}
+
+// NewPruner creates a new Pruner. The fulfilled height is the latest height which
+// is known to be both executed and sealed when the Pruner is created.
+func NewPruner(
+	logger zerolog.Logger,
+	metrics module.ExecutionMetrics,
+	storage Storage,
+	fulfilledHeight uint64,
+	opts ...PrunerOption,
+) (*Pruner, error) {
+	lastPrunedHeight, err := storage.GetPrunedHeight()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pruned height: %w", err)
+	}
+
+	fulfilledHeights := make(chan uint64, 32)
+	fulfilledHeights <- fulfilledHeight
+
+	p := &Pruner{
+		logger:                logger.With().Str("component", "execution_state_pruner").Logger(),
+		storage:               storage,
+		fulfilledHeights:      fulfilledHeights,
+		thresholdChan:         make(chan uint64),
+		heightRangeTargetChan: make(chan uint64),
+		lastFulfilledHeight:   fulfilledHeight,
+		lastPrunedHeight:      lastPrunedHeight,
+		heightRangeTarget:     defaultHeightRangeTarget,
+		threshold:             defaultThreshold,
+		batchSize:             defaultBatchSize,
+		metrics:               metrics,
+	}
+	p.cm = component.NewComponentManagerBuilder().
+		AddWorker(p.loop).
+		Build()
+	p.Component = p.cm
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p, nil
+}
+
+// NotifyFulfilledHeight notifies the Pruner of the latest fulfilled height.
+func (p *Pruner) NotifyFulfilledHeight(height uint64) {
+	if util.CheckClosed(p.cm.ShutdownSignal()) {
+		return
+	}
+
+	select {
+	case p.fulfilledHeights <- height:
+	default:
+	}
+}
+
+// SetHeightRangeTarget updates the Pruner's height range target.
+// This may block for the duration of a pruning batch.
+func (p *Pruner) SetHeightRangeTarget(heightRangeTarget uint64) error {
+	select {
+	case p.heightRangeTargetChan <- heightRangeTarget:
+		return nil
+	case <-p.cm.ShutdownSignal():
+		return component.ErrComponentShutdown
+	}
+}
+
+// SetThreshold update's the Pruner's threshold.
+// This may block for the duration of a pruning batch.
+func (p *Pruner) SetThreshold(threshold uint64) error {
+	select {
+	case p.thresholdChan <- threshold:
+		return nil
+	case <-p.cm.ShutdownSignal():
+		return component.ErrComponentShutdown
+	}
+}
+
+// Progress returns the current state of the Pruner.
+func (p *Pruner) Progress() Progress {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return Progress{
+		PrunedHeight:      p.lastPrunedHeight,
+		FulfilledHeight:   p.lastFulfilledHeight,
+		HeightRangeTarget: p.heightRangeTarget,
+		Threshold:         p.threshold,
+	}
+}
+
+func (p *Pruner) loop(ctx irrecoverable.SignalerContext, ready component.ReadyFunc) {
+	ready()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case height := <-p.fulfilledHeights:
+			if height > p.lastFulfilledHeight {
+				p.mu.Lock()
+				p.lastFulfilledHeight = height
+				p.mu.Unlock()
+			}
+			p.checkPrune(ctx)
+		case heightRangeTarget := <-p.heightRangeTargetChan:
+			p.mu.Lock()
+			p.heightRangeTarget = heightRangeTarget
+			p.mu.Unlock()
+			p.checkPrune(ctx)
+		case threshold := <-p.thresholdChan:
+			p.mu.Lock()
+			p.threshold = threshold
+			p.mu.Unlock()
+			p.checkPrune(ctx)
+		}
+	}
+}
+
+func (p *Pruner) checkPrune(ctx irrecoverable.SignalerContext) {
+	if p.lastFulfilledHeight <= p.heightRangeTarget+p.threshold+p.lastPrunedHeight {
+		return
+	}
+
+	pruneHeight := p.lastFulfilledHeight - p.heightRangeTarget
+
+	p.logger.Info().
+		Uint64("pruned_height", p.lastPrunedHeight).
+		Uint64("prune_height", pruneHeight).
+		Msg("pruning execution state")
+
+	for p.lastPrunedHeight < pruneHeight {
+		// stop pruning on shutdown, the remaining heights are pruned after the restart
+		if util.CheckClosed(ctx.Done()) {
+			return
+		}
+
+		height := pruneHeight
+		if height-p.lastPrunedHeight > p.batchSize {
+			height = p.lastPrunedHeight + p.batchSize
+		}
+
+		start := time.Now()
+
+		if err := p.storage.PruneUpToHeight(height); err != nil {
+			ctx.Throw(fmt.Errorf("failed to prune execution state up to height %d: %w", height, err))
+			return
+		}
+
+		duration := time.Since(start)
+		p.logger.Debug().
+			Uint64("height", height).
+			Dur("duration", duration).
+			Msg("pruned execution state")
+
+		p.metrics.ExecutionStatePruned(height, duration)
+
+		p.mu.Lock()
+		p.lastPrunedHeight = height
+		p.mu.Unlock()
+	}
+
+	p.logger.Info().Uint64("pruned_height", pruneHeight).Msg("pruned execution state")
+}
@@ -0,0 +1,172 @@
+package pruner_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/pruner"
+	"github.com/onflow/flow-go/engine/execution/pruner/mock"
+	"github.com/onflow/flow-go/module/component"
+	"github.com/onflow/flow-go/module/irrecoverable"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// startPruner starts the pruner and returns a function which stops it and
+// checks that no error was thrown.
+func startPruner(t *testing.T, p *pruner.Pruner) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	signalerCtx, errChan := irrecoverable.WithSignaler(ctx)
+
+	p.Start(signalerCtx)
+	unittest.AssertClosesBefore(t, p.Ready(), time.Second)
+
+	return func() {
+		cancel()
+		unittest.AssertClosesBefore(t, p.Done(), time.Second)
+
+		select {
+		case err := <-errChan:
+			require.NoError(t, err)
+		default:
+		}
+	}
+}
+
+func TestBasicPrune(t *testing.T) {
+	storage := mock.NewStorage(t)
+	storage.On("GetPrunedHeight").Return(uint64(0), nil).Once()
+
+	p, err := pruner.NewPruner(
+		zerolog.Nop(),
+		metrics.NewNoopCollector(),
+		storage,
+		0,
+		pruner.WithHeightRangeTarget(10),
+		pruner.WithThreshold(5),
+	)
+	require.NoError(t, err)
+
+	stop := startPruner(t, p)
+
+	// the range is not exceeded yet
+	p.NotifyFulfilledHeight(15)
+
+	pruned := make(chan struct{})
+	storage.On("PruneUpToHeight", uint64(6)).Return(func(height uint64) error {
+		close(pruned)
+		return nil
+	}).Once()
+
+	p.NotifyFulfilledHeight(16)
+	unittest.AssertClosesBefore(t, pruned, time.Second)
+
+	require.Eventually(t, func() bool {
+		return p.Progress().PrunedHeight == 6
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, pruner.Progress{
+		PrunedHeight:      6,
+		FulfilledHeight:   16,
+		HeightRangeTarget: 10,
+		Threshold:         5,
+	}, p.Progress())
+
+	stop()
+}
+
+func TestInitialPruneInBatches(t *testing.T) {
+	storage := mock.NewStorage(t)
+	storage.On("GetPrunedHeight").Return(uint64(10), nil).Once()
+
+	p, err := pruner.NewPruner(
+		zerolog.Nop(),
+		metrics.NewNoopCollector(),
+		storage,
+		100,
+		pruner.WithHeightRangeTarget(10),
+		pruner.WithThreshold(5),
+		pruner.WithBatchSize(40),
+	)
+	require.NoError(t, err)
+
+	var heights []uint64
+	pruned := make(chan struct{})
+	storage.On("PruneUpToHeight", testifymock.Anything).Return(func(height uint64) error {
+		heights = append(heights, height)
+		if height == 90 {
+			close(pruned)
+		}
+		return nil
+	}).Times(2)
+
+	stop := startPruner(t, p)
+	unittest.AssertClosesBefore(t, pruned, time.Second)
+	stop()
+
+	// the heights are pruned in batches of 40, up to the fulfilled height - height range target
+	require.Equal(t, []uint64{50, 90}, heights)
+}
+
+func TestUpdateThreshold(t *testing.T) {
+	storage := mock.NewStorage(t)
+	storage.On("GetPrunedHeight").Return(uint64(0), nil).Once()
+
+	p, err := pruner.NewPruner(
+		zerolog.Nop(),
+		metrics.NewNoopCollector(),
+		storage,
+		15,
+		pruner.WithHeightRangeTarget(10),
+		pruner.WithThreshold(10),
+	)
+	require.NoError(t, err)
+
+	stop := startPruner(t, p)
+
+	pruned := make(chan struct{})
+	storage.On("PruneUpToHeight", uint64(5)).Return(func(height uint64) error {
+		close(pruned)
+		return nil
+	}).Once()
+
+	require.NoError(t, p.SetThreshold(4))
+	unittest.AssertClosesBefore(t, pruned, time.Second)
+
+	stop()
+}
+
+func TestUpdateHeightRangeTarget(t *testing.T) {
+	storage := mock.NewStorage(t)
+	storage.On("GetPrunedHeight").Return(uint64(0), nil).Once()
+
+	p, err := pruner.NewPruner(
+		zerolog.Nop(),
+		metrics.NewNoopCollector(),
+		storage,
+		10,
+		pruner.WithHeightRangeTarget(15),
+		pruner.WithThreshold(0),
+	)
+	require.NoError(t, err)
+
+	stop := startPruner(t, p)
+
+	pruned := make(chan struct{})
+	storage.On("PruneUpToHeight", uint64(5)).Return(func(height uint64) error {
+		close(pruned)
+		return nil
+	}).Once()
+
+	require.NoError(t, p.SetHeightRangeTarget(5))
+	unittest.AssertClosesBefore(t, pruned, time.Second)
+
+	stop()
+
+	require.ErrorIs(t, p.SetHeightRangeTarget(10), component.ErrComponentShutdown)
+}
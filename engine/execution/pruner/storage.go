@@ -0,0 +1,150 @@
+package pruner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// BadgerStorage is the badger backed historical execution state of an execution node.
+// For each pruned height, it removes the events, transaction results, chunk data packs
+// and computation result upload status of the finalized block at that height.
+//
+// The data required by the protocol (execution results, receipts, state commitments
+// and service events) is kept.
+type BadgerStorage struct {
+	db                 *badger.DB
+	headers            *bstorage.Headers
+	results            *bstorage.ExecutionResults
+	chunkDataPacks     *bstorage.ChunkDataPacks
+	events             *bstorage.Events
+	transactionResults *bstorage.TransactionResults
+	uploadStatus       *bstorage.ComputationResultUploadStatus
+}
+
+var _ Storage = (*BadgerStorage)(nil)
+
+// NewBadgerStorage creates a new BadgerStorage. If the pruned height was not persisted
+// yet, it is initialized to the given root height, below which there is no data to prune.
+func NewBadgerStorage(
+	db *badger.DB,
+	rootHeight uint64,
+	headers *bstorage.Headers,
+	results *bstorage.ExecutionResults,
+	chunkDataPacks *bstorage.ChunkDataPacks,
+	events *bstorage.Events,
+	transactionResults *bstorage.TransactionResults,
+	uploadStatus *bstorage.ComputationResultUploadStatus,
+) (*BadgerStorage, error) {
+	err := operation.RetryOnConflict(db.Update, func(tx *badger.Txn) error {
+		var prunedHeight uint64
+		err := operation.RetrieveExecutionStatePrunedHeight(&prunedHeight)(tx)
+		if errors.Is(err, storage.ErrNotFound) {
+			return operation.InsertExecutionStatePrunedHeight(rootHeight)(tx)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize pruned height: %w", err)
+	}
+
+	return &BadgerStorage{
+		db:                 db,
+		headers:            headers,
+		results:            results,
+		chunkDataPacks:     chunkDataPacks,
+		events:             events,
+		transactionResults: transactionResults,
+		uploadStatus:       uploadStatus,
+	}, nil
+}
+
+// GetPrunedHeight returns the height up to which the historical execution state was pruned.
+// No errors are expected during normal operation.
+func (s *BadgerStorage) GetPrunedHeight() (uint64, error) {
+	var height uint64
+	err := s.db.View(operation.RetrieveExecutionStatePrunedHeight(&height))
+	if err != nil {
+		return 0, fmt.Errorf("could not retrieve pruned height: %w", err)
+	}
+	return height, nil
+}
+
+// PruneUpToHeight removes the historical execution state of the finalized blocks above
+// the pruned height, up to and including the given height.
+// The pruned height is updated after each block, so that pruning resumes where it stopped
+// if it is interrupted. Data which was already removed is skipped.
+// No errors are expected during normal operation.
+func (s *BadgerStorage) PruneUpToHeight(height uint64) error {
+	prunedHeight, err := s.GetPrunedHeight()
+	if err != nil {
+		return err
+	}
+
+	for h := prunedHeight + 1; h <= height; h++ {
+		blockID, err := s.headers.BlockIDByHeight(h)
+		if err != nil {
+			return fmt.Errorf("could not get finalized block at height %d: %w", h, err)
+		}
+
+		err = s.pruneBlock(blockID)
+		if err != nil {
+			return fmt.Errorf("could not prune block %v at height %d: %w", blockID, h, err)
+		}
+
+		err = operation.RetryOnConflict(s.db.Update, operation.UpdateExecutionStatePrunedHeight(h))
+		if err != nil {
+			return fmt.Errorf("could not update pruned height: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *BadgerStorage) pruneBlock(blockID flow.Identifier) error {
+	// the result is not found if the block's execution state was synced from other nodes,
+	// in which case there are no chunk data packs to remove
+	result, err := s.results.ByBlockID(blockID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("could not get execution result: %w", err)
+	}
+
+	if err == nil {
+		for _, chunk := range result.Chunks {
+			chunkID := chunk.ID()
+
+			err := s.chunkDataPacks.Remove(chunkID)
+			if err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("could not remove chunk data pack of chunk %v: %w", chunkID, err)
+			}
+
+			err = s.headers.RemoveChunkBlockIndexByChunkID(chunkID)
+			if err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("could not remove block index of chunk %v: %w", chunkID, err)
+			}
+		}
+	}
+
+	err = s.events.RemoveByBlockID(blockID)
+	if err != nil {
+		return fmt.Errorf("could not remove events: %w", err)
+	}
+
+	err = s.transactionResults.RemoveByBlockID(blockID)
+	if err != nil {
+		return fmt.Errorf("could not remove transaction results: %w", err)
+	}
+
+	err = s.uploadStatus.Remove(blockID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("could not remove computation result upload status: %w", err)
+	}
+
+	return nil
+}
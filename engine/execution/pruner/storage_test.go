@@ -0,0 +1,182 @@
+package pruner_test
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/pruner"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+type testStorage struct {
+	headers            *bstorage.Headers
+	results            *bstorage.ExecutionResults
+	collections        *bstorage.Collections
+	chunkDataPacks     *bstorage.ChunkDataPacks
+	events             *bstorage.Events
+	transactionResults *bstorage.TransactionResults
+	uploadStatus       *bstorage.ComputationResultUploadStatus
+}
+
+// newTestStorage creates new storages, so that the data is read from the database
+// rather than from the caches.
+func newTestStorage(db *badger.DB) *testStorage {
+	collector := metrics.NewNoopCollector()
+	collections := bstorage.NewCollections(db, bstorage.NewTransactions(collector, db))
+
+	return &testStorage{
+		headers:            bstorage.NewHeaders(collector, db),
+		results:            bstorage.NewExecutionResults(collector, db),
+		collections:        collections,
+		chunkDataPacks:     bstorage.NewChunkDataPacks(collector, db, collections, 100),
+		events:             bstorage.NewEvents(collector, db),
+		transactionResults: bstorage.NewTransactionResults(collector, db, 100),
+		uploadStatus:       bstorage.NewComputationResultUploadStatus(db),
+	}
+}
+
+func (s *testStorage) badgerStorage(t *testing.T, db *badger.DB, rootHeight uint64) *pruner.BadgerStorage {
+	store, err := pruner.NewBadgerStorage(
+		db,
+		rootHeight,
+		s.headers,
+		s.results,
+		s.chunkDataPacks,
+		s.events,
+		s.transactionResults,
+		s.uploadStatus,
+	)
+	require.NoError(t, err)
+	return store
+}
+
+// storeExecutedBlock stores the execution data of an executed finalized block at the given height.
+func storeExecutedBlock(t *testing.T, db *badger.DB, s *testStorage, height uint64) *flow.ExecutionResult {
+	blockID := unittest.IdentifierFixture()
+	err := db.Update(operation.IndexBlockHeight(height, blockID))
+	require.NoError(t, err)
+
+	result := unittest.ExecutionResultFixture(
+		unittest.WithExecutionResultBlockID(blockID),
+		unittest.WithChunks(2))
+	require.NoError(t, s.results.Store(result))
+	require.NoError(t, s.results.Index(blockID, result.ID()))
+
+	for _, chunk := range result.Chunks {
+		chunkDataPack := unittest.ChunkDataPackFixture(chunk.ID())
+		require.NoError(t, s.collections.Store(chunkDataPack.Collection))
+		require.NoError(t, s.chunkDataPacks.Store(chunkDataPack))
+		require.NoError(t, s.headers.IndexByChunkID(blockID, chunk.ID()))
+	}
+
+	txID := unittest.IdentifierFixture()
+	batch := bstorage.NewBatch(db)
+	err = s.events.BatchStore(blockID, []flow.EventsList{{
+		unittest.EventFixture(flow.EventAccountCreated, 0, 0, txID, 0),
+	}}, batch)
+	require.NoError(t, err)
+	err = s.transactionResults.BatchStore(blockID, []flow.TransactionResult{{TransactionID: txID}}, batch)
+	require.NoError(t, err)
+	require.NoError(t, batch.Flush())
+
+	require.NoError(t, s.uploadStatus.Upsert(blockID, true))
+
+	return result
+}
+
+func requirePruned(t *testing.T, s *testStorage, result *flow.ExecutionResult, pruned bool) {
+	blockID := result.BlockID
+
+	requireErr := func(err error) {
+		if pruned {
+			require.ErrorIs(t, err, storage.ErrNotFound)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	for _, chunk := range result.Chunks {
+		_, err := s.chunkDataPacks.ByChunkID(chunk.ID())
+		requireErr(err)
+
+		_, err = s.headers.IDByChunkID(chunk.ID())
+		requireErr(err)
+	}
+
+	events, err := s.events.ByBlockID(blockID)
+	require.NoError(t, err)
+	require.Equal(t, pruned, len(events) == 0)
+
+	_, err = s.transactionResults.ByBlockIDTransactionIndex(blockID, 0)
+	requireErr(err)
+
+	_, err = s.uploadStatus.ByID(blockID)
+	requireErr(err)
+
+	// the execution result is kept
+	_, err = s.results.ByBlockID(blockID)
+	require.NoError(t, err)
+}
+
+func TestBadgerStoragePruneUpToHeight(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		rootHeight := uint64(10)
+
+		s := newTestStorage(db)
+		store := s.badgerStorage(t, db, rootHeight)
+
+		prunedHeight, err := store.GetPrunedHeight()
+		require.NoError(t, err)
+		require.Equal(t, rootHeight, prunedHeight)
+
+		results := make(map[uint64]*flow.ExecutionResult)
+		for height := rootHeight + 1; height <= rootHeight+4; height++ {
+			results[height] = storeExecutedBlock(t, db, s, height)
+		}
+
+		// a block whose execution state was synced has no execution result
+		err = db.Update(operation.IndexBlockHeight(rootHeight+5, unittest.IdentifierFixture()))
+		require.NoError(t, err)
+
+		err = store.PruneUpToHeight(rootHeight + 2)
+		require.NoError(t, err)
+
+		prunedHeight, err = store.GetPrunedHeight()
+		require.NoError(t, err)
+		require.Equal(t, rootHeight+2, prunedHeight)
+
+		s = newTestStorage(db)
+		for height, result := range results {
+			requirePruned(t, s, result, height <= rootHeight+2)
+		}
+
+		// the pruned height is not reset to the root height
+		store = s.badgerStorage(t, db, rootHeight)
+		prunedHeight, err = store.GetPrunedHeight()
+		require.NoError(t, err)
+		require.Equal(t, rootHeight+2, prunedHeight)
+
+		err = store.PruneUpToHeight(rootHeight + 5)
+		require.NoError(t, err)
+
+		s = newTestStorage(db)
+		for _, result := range results {
+			requirePruned(t, s, result, true)
+		}
+
+		// heights which were not finalized yet can't be pruned
+		err = store.PruneUpToHeight(rootHeight + 6)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		prunedHeight, err = store.GetPrunedHeight()
+		require.NoError(t, err)
+		require.Equal(t, rootHeight+5, prunedHeight)
+	})
+}
@@ -0,0 +1,94 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/model/flow/filter"
+	"github.com/onflow/flow-go/state/protocol"
+)
+
+// accessNodeHostsRefreshInterval is the interval at which the addresses of the staked access
+// nodes are resolved again, as nodes join and leave, and their IP addresses change
+const accessNodeHostsRefreshInterval = time.Minute
+
+// accessNodeHosts holds the IP addresses of the staked access nodes, so that their script
+// requests can be exempt from the per caller quotas.
+// Access nodes connect to execution nodes without TLS, so their requests can only be told
+// apart from other callers by their IP address.
+type accessNodeHosts struct {
+	log    zerolog.Logger
+	state  protocol.State
+	lookup func(ctx context.Context, host string) ([]string, error)
+
+	mu    sync.RWMutex
+	hosts map[string]struct{}
+}
+
+func newAccessNodeHosts(log zerolog.Logger, state protocol.State) *accessNodeHosts {
+	return &accessNodeHosts{
+		log:    log.With().Str("component", "access_node_hosts").Logger(),
+		state:  state,
+		lookup: net.DefaultResolver.LookupHost,
+		hosts:  make(map[string]struct{}),
+	}
+}
+
+// contains returns whether the given host is the IP address of a staked access node.
+func (a *accessNodeHosts) contains(host string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	_, ok := a.hosts[host]
+	return ok
+}
+
+// refresh resolves the IP addresses of the access nodes staked at the latest finalized block.
+// The previous addresses are kept if the access nodes can't be read from the protocol state.
+// Access nodes whose address can't be resolved are skipped.
+func (a *accessNodeHosts) refresh(ctx context.Context) error {
+	identities, err := a.state.Final().Identities(filter.And(
+		filter.HasRole(flow.RoleAccess),
+		filter.HasWeight(true),
+	))
+	if err != nil {
+		return fmt.Errorf("could not get access nodes: %w", err)
+	}
+
+	hosts := make(map[string]struct{}, len(identities))
+	for _, identity := range identities {
+		host, _, err := net.SplitHostPort(identity.Address)
+		if err != nil {
+			host = identity.Address
+		}
+
+		if net.ParseIP(host) != nil {
+			hosts[host] = struct{}{}
+			continue
+		}
+
+		addresses, err := a.lookup(ctx, host)
+		if err != nil {
+			a.log.Debug().Err(err).
+				Hex("node_id", identity.NodeID[:]).
+				Str("address", identity.Address).
+				Msg("could not resolve access node address")
+			continue
+		}
+		for _, address := range addresses {
+			hosts[address] = struct{}{}
+		}
+	}
+
+	a.mu.Lock()
+	a.hosts = hosts
+	a.mu.Unlock()
+
+	return nil
+}
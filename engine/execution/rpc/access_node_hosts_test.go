@@ -0,0 +1,49 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	protocol "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestAccessNodeHosts(t *testing.T) {
+	identities := flow.IdentityList{
+		{NodeID: unittest.IdentifierFixture(), Role: flow.RoleAccess, Address: "10.0.0.1:3569"},
+		{NodeID: unittest.IdentifierFixture(), Role: flow.RoleAccess, Address: "access.example.com:3569"},
+		{NodeID: unittest.IdentifierFixture(), Role: flow.RoleAccess, Address: "unresolvable.example.com:3569"},
+	}
+
+	snapshot := protocol.NewSnapshot(t)
+	snapshot.On("Identities", mock.Anything).Return(identities, nil).Once()
+	state := protocol.NewState(t)
+	state.On("Final").Return(snapshot)
+
+	hosts := newAccessNodeHosts(zerolog.Nop(), state)
+	hosts.lookup = func(_ context.Context, host string) ([]string, error) {
+		if host == "access.example.com" {
+			return []string{"10.0.0.2", "10.0.0.3"}, nil
+		}
+		return nil, fmt.Errorf("no such host")
+	}
+
+	require.False(t, hosts.contains("10.0.0.1"))
+
+	require.NoError(t, hosts.refresh(context.Background()))
+	require.True(t, hosts.contains("10.0.0.1"))
+	require.True(t, hosts.contains("10.0.0.2"))
+	require.True(t, hosts.contains("10.0.0.3"))
+	require.False(t, hosts.contains("10.0.0.4"))
+
+	// the previous addresses are kept if the access nodes can't be read
+	snapshot.On("Identities", mock.Anything).Return(nil, fmt.Errorf("state error")).Once()
+	require.Error(t, hosts.refresh(context.Background()))
+	require.True(t, hosts.contains("10.0.0.1"))
+}
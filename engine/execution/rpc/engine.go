@@ -22,6 +22,7 @@ import (
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/engine/execution/ingestion"
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
 	"github.com/onflow/flow-go/utils/grpcutils"
@@ -32,6 +33,7 @@ type Config struct {
 	ListenAddr        string
 	MaxMsgSize        int  // In bytes
 	RpcMetricsEnabled bool // enable GRPC metrics reporting
+	ScriptQuotas      ScriptQuotaConfig
 }
 
 // Engine implements a gRPC server with a simplified version of the Observation API.
@@ -41,12 +43,15 @@ type Engine struct {
 	handler *handler     // the gRPC service implementation
 	server  *grpc.Server // the gRPC server
 	config  Config
+	// accessNodes holds the addresses of the staked access nodes, nil unless they are exempt from script quotas
+	accessNodes *accessNodeHosts
 }
 
 // New returns a new RPC engine.
 func New(
 	log zerolog.Logger,
 	config Config,
+	metrics module.ExecutionMetrics,
 	e *ingestion.Engine,
 	headers storage.Headers,
 	state protocol.State,
@@ -58,7 +63,7 @@ func New(
 	signerIndicesDecoder hotstuff.BlockSignerDecoder,
 	apiRatelimits map[string]int, // the api rate limit (max calls per second) for each of the gRPC API e.g. Ping->100, ExecuteScriptAtBlockID->300
 	apiBurstLimits map[string]int, // the api burst limit (max calls at the same time) for each of the gRPC API e.g. Ping->50, ExecuteScriptAtBlockID->10
-) *Engine {
+) (*Engine, error) {
 	log = log.With().Str("engine", "rpc").Logger()
 	if config.MaxMsgSize == 0 {
 		config.MaxMsgSize = grpcutils.DefaultMaxMsgSize
@@ -88,6 +93,23 @@ func New(
 
 	server := grpc.NewServer(serverOptions...)
 
+	// scripts are not limited per caller unless a quota is configured
+	var quotas *scriptQuotas
+	var accessNodes *accessNodeHosts
+	if config.ScriptQuotas.Enabled() {
+		var exempt func(host string) bool
+		if config.ScriptQuotas.ExemptAccessNodes {
+			accessNodes = newAccessNodeHosts(log, state)
+			exempt = accessNodes.contains
+		}
+
+		var err error
+		quotas, err = newScriptQuotas(log, metrics, config.ScriptQuotas, exempt)
+		if err != nil {
+			return nil, fmt.Errorf("could not create script quotas: %w", err)
+		}
+	}
+
 	eng := &Engine{
 		log:  log,
 		unit: engine.NewUnit(),
@@ -101,10 +123,12 @@ func New(
 			exeResults:           exeResults,
 			transactionResults:   txResults,
 			commits:              commits,
+			scriptQuotas:         quotas,
 			log:                  log,
 		},
-		server: server,
-		config: config,
+		server:      server,
+		config:      config,
+		accessNodes: accessNodes,
 	}
 
 	if config.RpcMetricsEnabled {
@@ -114,17 +138,30 @@ func New(
 
 	execution.RegisterExecutionAPIServer(eng.server, eng.handler)
 
-	return eng
+	return eng, nil
 }
 
 // Ready returns a ready channel that is closed once the engine has fully
 // started. The RPC engine is ready when the gRPC server has successfully
 // started.
 func (e *Engine) Ready() <-chan struct{} {
+	if e.accessNodes != nil {
+		e.unit.Launch(e.refreshAccessNodes)
+		e.unit.LaunchPeriodically(e.refreshAccessNodes, accessNodeHostsRefreshInterval, 0)
+	}
 	e.unit.Launch(e.serve)
 	return e.unit.Ready()
 }
 
+// refreshAccessNodes resolves the addresses of the staked access nodes, whose script requests
+// are exempt from the per caller quotas.
+func (e *Engine) refreshAccessNodes() {
+	err := e.accessNodes.refresh(e.unit.Ctx())
+	if err != nil {
+		e.log.Warn().Err(err).Msg("could not refresh access node addresses")
+	}
+}
+
 // Done returns a done channel that is closed once the engine has fully stopped.
 // It sends a signal to stop the gRPC server, then closes the channel.
 func (e *Engine) Done() <-chan struct{} {
@@ -161,6 +198,7 @@ type handler struct {
 	transactionResults   storage.TransactionResults
 	log                  zerolog.Logger
 	commits              storage.Commits
+	scriptQuotas         *scriptQuotas // nil if scripts are not limited per caller
 }
 
 var _ execution.ExecutionAPIServer = &handler{}
@@ -180,6 +218,12 @@ func (h *handler) ExecuteScriptAtBlockID(
 		return nil, err
 	}
 
+	release, err := h.scriptQuotas.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	value, err := h.engine.ExecuteScriptAtBlockID(ctx, req.GetScript(), req.GetArguments(), blockID)
 	if err != nil {
 		// return code 3 as this passes the litmus test in our context
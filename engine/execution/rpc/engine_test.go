@@ -18,6 +18,7 @@ import (
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	ingestion "github.com/onflow/flow-go/engine/execution/ingestion/mock"
 	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
 	realstorage "github.com/onflow/flow-go/storage"
 	storage "github.com/onflow/flow-go/storage/mock"
 	"github.com/onflow/flow-go/utils/unittest"
@@ -97,7 +98,40 @@ func (suite *Suite) TestExecuteScriptAtBlockID() {
 		suite.Require().Error(err)
 		errors.Is(err, status.Error(codes.InvalidArgument, ""))
 	})
+}
+
+// TestExecuteScriptAtBlockIDOverQuota tests that the ExecuteScriptAtBlockID API call is rejected
+// once the caller exceeded its script quota
+func (suite *Suite) TestExecuteScriptAtBlockIDOverQuota() {
+	quotas, err := newScriptQuotas(suite.log, metrics.NewNoopCollector(), ScriptQuotaConfig{
+		RateLimit: 0.001,
+		Burst:     1,
+	}, nil)
+	suite.Require().NoError(err)
+
+	mockEngine := new(ingestion.IngestRPC)
+	handler := &handler{
+		engine:       mockEngine,
+		chain:        flow.Mainnet,
+		scriptQuotas: quotas,
+	}
+
+	ctx := context.Background()
+	mockIdentifier := unittest.IdentifierFixture()
+	script := []byte("dummy script")
+	executionReq := execution.ExecuteScriptAtBlockIDRequest{
+		BlockId: mockIdentifier[:],
+		Script:  script,
+	}
+
+	mockEngine.On("ExecuteScriptAtBlockID", ctx, script, [][]byte(nil), mockIdentifier).
+		Return([]byte{9, 10, 11}, nil).Once()
+	_, err = handler.ExecuteScriptAtBlockID(ctx, &executionReq)
+	suite.Require().NoError(err)
 
+	_, err = handler.ExecuteScriptAtBlockID(ctx, &executionReq)
+	suite.Require().Equal(codes.ResourceExhausted, status.Code(err))
+	mockEngine.AssertExpectations(suite.T())
 }
 
 // TestGetEventsForBlockIDs tests the GetEventsForBlockIDs API call
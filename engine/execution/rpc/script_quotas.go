@@ -0,0 +1,384 @@
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/rs/zerolog"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/module"
+)
+
+// DefaultScriptQuotaMaxCallers is the default number of callers whose script quotas are tracked.
+const DefaultScriptQuotaMaxCallers = 10_000
+
+// DefaultScriptExecutionTimeBurst is the default execution time a caller can accrue.
+const DefaultScriptExecutionTimeBurst = 10 * time.Second
+
+// unknownCaller is the caller of requests which have neither an API key nor a peer address
+const unknownCaller = "unknown"
+
+const (
+	// offenderReportInterval is the interval at which the callers with the most rejected
+	// requests are logged. Callers are not used as metric labels, as there are too many.
+	offenderReportInterval = time.Minute
+	// offenderReportSize is the number of callers logged per report
+	offenderReportSize = 10
+)
+
+// ScriptQuotaConfig defines the per caller quotas for executing scripts.
+// A caller is identified by the API key sent in the request metadata, if any,
+// otherwise by the IP address of the peer.
+//
+// Callers are isolated by the number of scripts they execute and by the time spent executing
+// them. Memory is not accounted per caller, it is only bounded per script by the FVM memory
+// limit, and per caller indirectly by the concurrency quota.
+type ScriptQuotaConfig struct {
+	// MaxConcurrency is the max number of scripts executed concurrently per caller, 0 means no limit
+	MaxConcurrency int
+	// RateLimit is the max number of scripts per second per caller, 0 means no limit
+	RateLimit float64
+	// Burst is the max number of scripts per caller allowed at once by the rate limit
+	Burst int
+	// QueueTimeout is how long a request waits for one of the caller's concurrent scripts to
+	// finish, before it is rejected. 0 means requests over the concurrency quota are rejected immediately
+	QueueTimeout time.Duration
+	// ExecutionTimeRate is the script execution time a caller accrues per second, e.g. 0.5 allows a
+	// caller to keep scripts running for half of the time. 0 means no limit
+	ExecutionTimeRate float64
+	// ExecutionTimeBurst is the max execution time a caller can accrue. A caller is rejected once
+	// its scripts used up the accrued time, until it accrued time again
+	ExecutionTimeBurst time.Duration
+	// APIKeyHeader is the metadata key of the API key identifying a caller, empty to only identify
+	// callers by their IP address
+	APIKeyHeader string
+	// MaxCallers is the max number of callers whose quotas are tracked, the least recently
+	// seen callers are dropped beyond this
+	MaxCallers int
+	// ExemptAccessNodes exempts requests from the IP addresses of staked access nodes from the
+	// quotas, as many access nodes may share an address and their requests are already limited
+	// by the access nodes
+	ExemptAccessNodes bool
+}
+
+// Enabled returns true if any quota is configured.
+func (c ScriptQuotaConfig) Enabled() bool {
+	return c.MaxConcurrency > 0 || c.RateLimit > 0 || c.ExecutionTimeRate > 0
+}
+
+// callerQuota holds the quota state of a single caller
+type callerQuota struct {
+	limiter *rate.Limiter // nil if there is no rate limit
+	slots   chan struct{} // nil if there is no concurrency limit
+	time    *timeBudget   // nil if there is no execution time limit
+}
+
+// timeBudget is the execution time a caller has accrued. Unlike a rate limiter, the time is
+// charged after a script was executed, so the budget becomes negative if a script took longer
+// than the accrued time, and the caller is rejected until it is positive again.
+type timeBudget struct {
+	mu        sync.Mutex
+	available time.Duration
+	updated   time.Time
+}
+
+// scriptQuotas enforces the per caller quotas for executing scripts.
+// A nil *scriptQuotas admits all requests.
+type scriptQuotas struct {
+	log     zerolog.Logger
+	metrics module.ExecutionMetrics
+	config  ScriptQuotaConfig
+	callers *lru.Cache
+	// exempt returns whether requests from the given peer host are exempt from the quotas
+	exempt func(host string) bool
+
+	mu sync.Mutex
+	// inflight is the number of scripts being executed by all callers
+	inflight int
+	// rejections counts the rejected requests per caller since the last offender report
+	rejections map[string]uint64
+	lastReport time.Time
+
+	// now returns the current time, it is replaced in tests
+	now func() time.Time
+}
+
+// newScriptQuotas creates the script quotas. exempt may be nil if no callers are exempt.
+func newScriptQuotas(
+	log zerolog.Logger,
+	metrics module.ExecutionMetrics,
+	config ScriptQuotaConfig,
+	exempt func(host string) bool,
+) (*scriptQuotas, error) {
+	if config.MaxCallers == 0 {
+		config.MaxCallers = DefaultScriptQuotaMaxCallers
+	}
+	if config.RateLimit > 0 && config.Burst == 0 {
+		config.Burst = 1
+	}
+	if config.ExecutionTimeRate > 0 && config.ExecutionTimeBurst == 0 {
+		config.ExecutionTimeBurst = DefaultScriptExecutionTimeBurst
+	}
+	if exempt == nil {
+		exempt = func(string) bool { return false }
+	}
+
+	// a caller dropped from the cache while its scripts are still running gets a fresh quota when
+	// it's seen again, which is fine as long as the cache is large compared to the number of
+	// concurrent callers
+	callers, err := lru.New(config.MaxCallers)
+	if err != nil {
+		return nil, fmt.Errorf("could not create callers cache: %w", err)
+	}
+
+	return &scriptQuotas{
+		log:        log.With().Str("component", "script_quotas").Logger(),
+		metrics:    metrics,
+		config:     config,
+		callers:    callers,
+		exempt:     exempt,
+		rejections: make(map[string]uint64),
+		now:        time.Now,
+	}, nil
+}
+
+// acquire admits a script request of the caller of the given context, waiting for up to the
+// queue timeout if the caller is at its concurrency quota. The returned function must be called
+// once the script was executed.
+// Expected errors during normal operation:
+//   - codes.ResourceExhausted if the caller exceeded its quota
+//   - codes.Canceled or codes.DeadlineExceeded if the context was done while waiting
+func (q *scriptQuotas) acquire(ctx context.Context) (func(), error) {
+	if q == nil {
+		return func() {}, nil
+	}
+
+	if host, ok := peerHost(ctx); ok && q.exempt(host) {
+		return q.admit(), nil
+	}
+
+	caller := q.caller(ctx)
+	quota := q.quota(caller)
+
+	if quota.time != nil && !q.hasTime(quota.time) {
+		q.reject(caller, "execution time limit")
+		return nil, status.Errorf(codes.ResourceExhausted,
+			"script execution time limit of %v per second reached, please retry later", q.config.ExecutionTimeRate)
+	}
+
+	if quota.limiter != nil && !quota.limiter.Allow() {
+		q.reject(caller, "rate limit")
+		return nil, status.Errorf(codes.ResourceExhausted,
+			"script rate limit of %v per second reached, please retry later", q.config.RateLimit)
+	}
+
+	if quota.slots != nil {
+		select {
+		case quota.slots <- struct{}{}:
+		default:
+			if q.config.QueueTimeout == 0 {
+				q.reject(caller, "concurrency limit")
+				return nil, status.Errorf(codes.ResourceExhausted,
+					"concurrent script limit of %d reached, please retry later", q.config.MaxConcurrency)
+			}
+
+			timer := time.NewTimer(q.config.QueueTimeout)
+			defer timer.Stop()
+
+			select {
+			case quota.slots <- struct{}{}:
+			case <-timer.C:
+				q.reject(caller, "queue timeout")
+				return nil, status.Errorf(codes.ResourceExhausted,
+					"concurrent script limit of %d reached and no script finished within %s, please retry later",
+					q.config.MaxConcurrency, q.config.QueueTimeout)
+			case <-ctx.Done():
+				return nil, status.FromContextError(ctx.Err()).Err()
+			}
+		}
+	}
+
+	release := q.admit()
+	start := q.now()
+
+	return func() {
+		if quota.time != nil {
+			q.charge(quota.time, q.now().Sub(start))
+		}
+		if quota.slots != nil {
+			<-quota.slots
+		}
+		release()
+	}, nil
+}
+
+// admit reports an admitted request, and returns the function to call once its script was executed.
+func (q *scriptQuotas) admit() func() {
+	q.metrics.ExecutionScriptRequest(true)
+
+	q.mu.Lock()
+	q.inflight++
+	q.metrics.ExecutionScriptsInflight(q.inflight)
+	q.mu.Unlock()
+
+	return func() {
+		q.mu.Lock()
+		q.inflight--
+		q.metrics.ExecutionScriptsInflight(q.inflight)
+		q.mu.Unlock()
+	}
+}
+
+func (q *scriptQuotas) reject(caller string, reason string) {
+	q.log.Debug().
+		Str("caller", caller).
+		Str("reason", reason).
+		Msg("script request over quota rejected")
+	q.metrics.ExecutionScriptRequest(false)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// the number of callers counted is bounded like the number of callers tracked
+	if _, ok := q.rejections[caller]; ok || len(q.rejections) < q.config.MaxCallers {
+		q.rejections[caller]++
+	}
+
+	now := q.now()
+	if now.Sub(q.lastReport) < offenderReportInterval {
+		return
+	}
+	q.reportOffenders()
+	q.rejections = make(map[string]uint64)
+	q.lastReport = now
+}
+
+// reportOffenders logs the callers with the most rejected requests since the last report.
+// Must be called with the lock held.
+func (q *scriptQuotas) reportOffenders() {
+	callers := make([]string, 0, len(q.rejections))
+	for caller := range q.rejections {
+		callers = append(callers, caller)
+	}
+	sort.Slice(callers, func(i, j int) bool {
+		return q.rejections[callers[i]] > q.rejections[callers[j]]
+	})
+	if len(callers) > offenderReportSize {
+		callers = callers[:offenderReportSize]
+	}
+
+	rejections := make([]uint64, len(callers))
+	for i, caller := range callers {
+		rejections[i] = q.rejections[caller]
+	}
+
+	q.log.Warn().
+		Strs("callers", callers).
+		Uints64("rejections", rejections).
+		Int("rejected_callers", len(q.rejections)).
+		Msg("callers with the most script requests rejected over quota")
+}
+
+// hasTime accrues the execution time elapsed since the budget was last updated, and returns
+// whether any time is available.
+func (q *scriptQuotas) hasTime(budget *timeBudget) bool {
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+
+	q.accrue(budget)
+	return budget.available > 0
+}
+
+// charge subtracts the execution time of a script from the budget.
+func (q *scriptQuotas) charge(budget *timeBudget, executionTime time.Duration) {
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+
+	q.accrue(budget)
+	budget.available -= executionTime
+}
+
+// accrue adds the execution time accrued since the budget was last updated, up to the burst.
+// Must be called with the budget's lock held.
+func (q *scriptQuotas) accrue(budget *timeBudget) {
+	now := q.now()
+	budget.available += time.Duration(float64(now.Sub(budget.updated)) * q.config.ExecutionTimeRate)
+	if budget.available > q.config.ExecutionTimeBurst {
+		budget.available = q.config.ExecutionTimeBurst
+	}
+	budget.updated = now
+}
+
+// quota returns the quota of the given caller, creating it if the caller is not tracked yet.
+func (q *scriptQuotas) quota(caller string) *callerQuota {
+	if existing, ok := q.callers.Get(caller); ok {
+		return existing.(*callerQuota)
+	}
+
+	quota := &callerQuota{}
+	if q.config.RateLimit > 0 {
+		quota.limiter = rate.NewLimiter(rate.Limit(q.config.RateLimit), q.config.Burst)
+	}
+	if q.config.MaxConcurrency > 0 {
+		quota.slots = make(chan struct{}, q.config.MaxConcurrency)
+	}
+	if q.config.ExecutionTimeRate > 0 {
+		quota.time = &timeBudget{
+			available: q.config.ExecutionTimeBurst,
+			updated:   q.now(),
+		}
+	}
+
+	// the caller may have been added concurrently, in which case its existing quota is used
+	existing, found, _ := q.callers.PeekOrAdd(caller, quota)
+	if found {
+		return existing.(*callerQuota)
+	}
+	return quota
+}
+
+// caller identifies the caller of the given request context.
+// If an API key is sent, the caller is identified by a hash of the key, so that the key
+// isn't exposed by the logs or metrics.
+func (q *scriptQuotas) caller(ctx context.Context) string {
+	if q.config.APIKeyHeader != "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if keys := md.Get(q.config.APIKeyHeader); len(keys) > 0 && keys[0] != "" {
+				hash := sha256.Sum256([]byte(keys[0]))
+				return "key:" + hex.EncodeToString(hash[:8])
+			}
+		}
+	}
+
+	host, ok := peerHost(ctx)
+	if !ok {
+		return unknownCaller
+	}
+	return host
+}
+
+// peerHost returns the host of the peer of the given request context, usually its IP address.
+func peerHost(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "", false
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String(), true
+	}
+	return host, true
+}
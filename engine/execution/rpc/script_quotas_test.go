@@ -0,0 +1,281 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/module/metrics"
+	mockmodule "github.com/onflow/flow-go/module/mock"
+)
+
+func peerContext(ip string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 3569},
+	})
+}
+
+func requireCode(t *testing.T, err error, code codes.Code) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, code, st.Code())
+}
+
+func TestScriptQuotasCaller(t *testing.T) {
+	quotas, err := newScriptQuotas(zerolog.Nop(), metrics.NewNoopCollector(), ScriptQuotaConfig{
+		MaxConcurrency: 1,
+		APIKeyHeader:   "x-api-key",
+	}, nil)
+	require.NoError(t, err)
+
+	// callers without an API key are identified by their IP address
+	require.Equal(t, "10.0.0.1", quotas.caller(peerContext("10.0.0.1")))
+	require.Equal(t, unknownCaller, quotas.caller(context.Background()))
+
+	// callers with an API key are identified by the hash of the key, regardless of their address
+	ctx := metadata.NewIncomingContext(peerContext("10.0.0.1"), metadata.Pairs("x-api-key", "secret"))
+	caller := quotas.caller(ctx)
+	require.NotContains(t, caller, "secret")
+
+	ctx = metadata.NewIncomingContext(peerContext("10.0.0.2"), metadata.Pairs("x-api-key", "secret"))
+	require.Equal(t, caller, quotas.caller(ctx))
+
+	ctx = metadata.NewIncomingContext(peerContext("10.0.0.1"), metadata.Pairs("x-api-key", "other"))
+	require.NotEqual(t, caller, quotas.caller(ctx))
+}
+
+func TestScriptQuotasConcurrency(t *testing.T) {
+	t.Run("rejects over quota", func(t *testing.T) {
+		// callers are not reported in the metrics, as there are too many of them
+		collector := mockmodule.NewExecutionMetrics(t)
+		collector.On("ExecutionScriptRequest", true).Times(3)
+		collector.On("ExecutionScriptRequest", false).Once()
+		collector.On("ExecutionScriptsInflight", 1).Twice()
+		collector.On("ExecutionScriptsInflight", 2).Twice()
+		collector.On("ExecutionScriptsInflight", 3).Once()
+		collector.On("ExecutionScriptsInflight", 0).Once()
+
+		quotas, err := newScriptQuotas(zerolog.Nop(), collector, ScriptQuotaConfig{
+			MaxConcurrency: 2,
+		}, nil)
+		require.NoError(t, err)
+
+		release1, err := quotas.acquire(peerContext("10.0.0.1"))
+		require.NoError(t, err)
+		release2, err := quotas.acquire(peerContext("10.0.0.1"))
+		require.NoError(t, err)
+
+		_, err = quotas.acquire(peerContext("10.0.0.1"))
+		requireCode(t, err, codes.ResourceExhausted)
+
+		// other callers have their own quota
+		release3, err := quotas.acquire(peerContext("10.0.0.2"))
+		require.NoError(t, err)
+
+		release1()
+		release2()
+		release3()
+	})
+
+	t.Run("queues over quota", func(t *testing.T) {
+		quotas, err := newScriptQuotas(zerolog.Nop(), metrics.NewNoopCollector(), ScriptQuotaConfig{
+			MaxConcurrency: 1,
+			QueueTimeout:   time.Minute,
+		}, nil)
+		require.NoError(t, err)
+
+		release, err := quotas.acquire(peerContext("10.0.0.1"))
+		require.NoError(t, err)
+
+		admitted := make(chan struct{})
+		go func() {
+			release, err := quotas.acquire(peerContext("10.0.0.1"))
+			require.NoError(t, err)
+			release()
+			close(admitted)
+		}()
+
+		select {
+		case <-admitted:
+			t.Fatal("request over quota should be queued")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		release()
+
+		select {
+		case <-admitted:
+		case <-time.After(time.Second):
+			t.Fatal("queued request should be admitted once a script finished")
+		}
+	})
+
+	t.Run("queue timeout", func(t *testing.T) {
+		quotas, err := newScriptQuotas(zerolog.Nop(), metrics.NewNoopCollector(), ScriptQuotaConfig{
+			MaxConcurrency: 1,
+			QueueTimeout:   10 * time.Millisecond,
+		}, nil)
+		require.NoError(t, err)
+
+		release, err := quotas.acquire(peerContext("10.0.0.1"))
+		require.NoError(t, err)
+		defer release()
+
+		_, err = quotas.acquire(peerContext("10.0.0.1"))
+		requireCode(t, err, codes.ResourceExhausted)
+	})
+
+	t.Run("context canceled while queued", func(t *testing.T) {
+		quotas, err := newScriptQuotas(zerolog.Nop(), metrics.NewNoopCollector(), ScriptQuotaConfig{
+			MaxConcurrency: 1,
+			QueueTimeout:   time.Minute,
+		}, nil)
+		require.NoError(t, err)
+
+		release, err := quotas.acquire(peerContext("10.0.0.1"))
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithCancel(peerContext("10.0.0.1"))
+		cancel()
+
+		_, err = quotas.acquire(ctx)
+		requireCode(t, err, codes.Canceled)
+	})
+}
+
+func TestScriptQuotasRateLimit(t *testing.T) {
+	quotas, err := newScriptQuotas(zerolog.Nop(), metrics.NewNoopCollector(), ScriptQuotaConfig{
+		RateLimit: 0.001,
+		Burst:     2,
+	}, nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		release, err := quotas.acquire(peerContext("10.0.0.1"))
+		require.NoError(t, err)
+		release()
+	}
+
+	_, err = quotas.acquire(peerContext("10.0.0.1"))
+	requireCode(t, err, codes.ResourceExhausted)
+
+	release, err := quotas.acquire(peerContext("10.0.0.2"))
+	require.NoError(t, err)
+	release()
+}
+
+func TestScriptQuotasMaxCallers(t *testing.T) {
+	quotas, err := newScriptQuotas(zerolog.Nop(), metrics.NewNoopCollector(), ScriptQuotaConfig{
+		RateLimit:  0.001,
+		Burst:      1,
+		MaxCallers: 1,
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = quotas.acquire(peerContext("10.0.0.1"))
+	require.NoError(t, err)
+	_, err = quotas.acquire(peerContext("10.0.0.2"))
+	require.NoError(t, err)
+
+	// the first caller was dropped, so it gets a fresh quota
+	_, err = quotas.acquire(peerContext("10.0.0.1"))
+	require.NoError(t, err)
+}
+
+func TestScriptQuotasExecutionTime(t *testing.T) {
+	quotas, err := newScriptQuotas(zerolog.Nop(), metrics.NewNoopCollector(), ScriptQuotaConfig{
+		ExecutionTimeRate:  0.5,
+		ExecutionTimeBurst: time.Second,
+	}, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	quotas.now = func() time.Time { return now }
+
+	// a script running longer than the accrued time is not interrupted
+	release, err := quotas.acquire(peerContext("10.0.0.1"))
+	require.NoError(t, err)
+	now = now.Add(2 * time.Second)
+	release()
+
+	// but the caller is rejected until the time it overdrew is accrued again
+	_, err = quotas.acquire(peerContext("10.0.0.1"))
+	requireCode(t, err, codes.ResourceExhausted)
+
+	// other callers have their own execution time
+	release, err = quotas.acquire(peerContext("10.0.0.2"))
+	require.NoError(t, err)
+	release()
+
+	now = now.Add(2*time.Second + time.Millisecond)
+	release, err = quotas.acquire(peerContext("10.0.0.1"))
+	require.NoError(t, err)
+	release()
+}
+
+func TestScriptQuotasExempt(t *testing.T) {
+	quotas, err := newScriptQuotas(zerolog.Nop(), metrics.NewNoopCollector(), ScriptQuotaConfig{
+		RateLimit: 0.001,
+		Burst:     1,
+	}, func(host string) bool {
+		return host == "10.0.0.1"
+	})
+	require.NoError(t, err)
+
+	// exempt hosts are admitted regardless of their quota, even with an API key
+	ctx := metadata.NewIncomingContext(peerContext("10.0.0.1"), metadata.Pairs("x-api-key", "secret"))
+	for i := 0; i < 3; i++ {
+		release, err := quotas.acquire(ctx)
+		require.NoError(t, err)
+		release()
+	}
+
+	_, err = quotas.acquire(peerContext("10.0.0.2"))
+	require.NoError(t, err)
+	_, err = quotas.acquire(peerContext("10.0.0.2"))
+	requireCode(t, err, codes.ResourceExhausted)
+}
+
+func TestScriptQuotasOffenders(t *testing.T) {
+	var logs bytes.Buffer
+	quotas, err := newScriptQuotas(zerolog.New(&logs).Level(zerolog.WarnLevel), metrics.NewNoopCollector(), ScriptQuotaConfig{
+		RateLimit: 0.001,
+		Burst:     1,
+	}, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	quotas.now = func() time.Time { return now }
+	quotas.lastReport = now
+
+	for _, caller := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.2"} {
+		_, _ = quotas.acquire(peerContext(caller))
+		_, _ = quotas.acquire(peerContext(caller))
+	}
+	require.Empty(t, logs.String())
+
+	// the callers with the most rejections are logged once per interval
+	now = now.Add(offenderReportInterval)
+	_, err = quotas.acquire(peerContext("10.0.0.2"))
+	requireCode(t, err, codes.ResourceExhausted)
+
+	var report struct {
+		Callers    []string `json:"callers"`
+		Rejections []uint64 `json:"rejections"`
+	}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &report))
+	require.Equal(t, []string{"10.0.0.2", "10.0.0.1"}, report.Callers)
+	require.Equal(t, []uint64{4, 1}, report.Rejections)
+	require.Empty(t, quotas.rejections)
+}
@@ -104,6 +104,22 @@ func RegisterIDToKey(reg flow.RegisterID) ledger.Key {
 	})
 }
 
+// KeyToRegisterID converts a ledger key into the register ID it encodes.
+// Expected errors:
+// - error if the key is not in the expected owner/key format
+func KeyToRegisterID(key ledger.Key) (flow.RegisterID, error) {
+	if len(key.KeyParts) != 2 ||
+		key.KeyParts[0].Type != KeyPartOwner ||
+		key.KeyParts[1].Type != KeyPartKey {
+		return flow.RegisterID{}, fmt.Errorf("key not in expected format %s", key.String())
+	}
+
+	return flow.NewRegisterID(
+		string(key.KeyParts[0].Value),
+		string(key.KeyParts[1].Value),
+	), nil
+}
+
 // NewExecutionState returns a new execution state access layer for the given ledger storage.
 func NewExecutionState(
 	ls ledger.Ledger,
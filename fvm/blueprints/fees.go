@@ -42,6 +42,9 @@ var setupFeesTransactionTemplate string
 //go:embed scripts/setExecutionMemoryLimit.cdc
 var setExecutionMemoryLimit string
 
+//go:embed scripts/computeFeesScriptTemplate.cdc
+var computeFeesScriptTemplate string
+
 func DeployTxFeesContractTransaction(service, fungibleToken, flowToken, storageFees, flowFees flow.Address) *flow.TransactionBody {
 	contract := contracts.FlowFees(
 		fungibleToken.HexWithPrefix(),
@@ -230,3 +233,28 @@ func SetExecutionMemoryLimitTransaction(
 
 	return tx, nil
 }
+
+// ComputeFeesScript returns a script which computes the fees the FlowFees contract charges
+// for a transaction with the given inclusion and execution effort.  The script returns the
+// inclusion fee (the fee for the inclusion effort alone) and the total fee.
+func ComputeFeesScript(
+	flowFees flow.Address,
+	inclusionEffort uint64,
+	executionEffort uint64,
+) ([]byte, [][]byte) {
+	inclusionEffortArg, err := jsoncdc.Encode(cadence.UFix64(inclusionEffort))
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode inclusion effort: %s", err.Error()))
+	}
+	executionEffortArg, err := jsoncdc.Encode(cadence.UFix64(executionEffort))
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode execution effort: %s", err.Error()))
+	}
+
+	script := templates.ReplaceAddresses(computeFeesScriptTemplate,
+		templates.Environment{
+			FlowFeesAddress: flowFees.Hex(),
+		})
+
+	return []byte(script), [][]byte{inclusionEffortArg, executionEffortArg}
+}
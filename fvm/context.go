@@ -9,6 +9,7 @@ import (
 	"github.com/onflow/flow-go/fvm/environment"
 	reusableRuntime "github.com/onflow/flow-go/fvm/runtime"
 	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/fvm/tracing"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
 )
@@ -183,6 +184,25 @@ func WithExtensiveTracing() Option {
 	}
 }
 
+// WithExecutionTrace sets the execution trace in which the register reads /
+// writes, contract function invocations, emitted events and metering charges
+// of the procedure are recorded.
+func WithExecutionTrace(trace *tracing.ExecutionTrace) Option {
+	return func(ctx Context) Context {
+		ctx.ExecutionTrace = trace
+		return ctx
+	}
+}
+
+// WithComputationProfile sets the profile in which the metered computation
+// and memory usage of the procedure are accumulated.
+func WithComputationProfile(profile *tracing.ComputationProfile) Option {
+	return func(ctx Context) Context {
+		ctx.ComputationProfile = profile
+		return ctx
+	}
+}
+
 // WithBlocks sets the block storage provider for a virtual machine context.
 //
 // The VM uses the block storage provider to provide historical block information to
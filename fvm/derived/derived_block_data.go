@@ -119,6 +119,18 @@ func (block *DerivedBlockData) NewDerivedTransactionData(
 	}, nil
 }
 
+// ProgramStates returns the states captured when the block's cached programs
+// were loaded, keyed by the programs' locations.
+func (block *DerivedBlockData) ProgramStates() map[common.AddressLocation]*state.State {
+	return block.programs.States()
+}
+
+// MeterParamOverridesState returns the state captured when the block's cached
+// meter param overrides were computed, or nil if they are not cached.
+func (block *DerivedBlockData) MeterParamOverridesState() *state.State {
+	return block.meterParamOverrides.States()[struct{}{}]
+}
+
 func (block *DerivedBlockData) NextTxIndexForTestingOnly() uint32 {
 	// NOTE: We can use next tx index from any table since they are identical.
 	return block.programs.NextTxIndexForTestingOnly()
@@ -135,6 +135,21 @@ func (table *DerivedDataTable[TKey, TVal]) EntriesForTestingOnly() map[TKey]*inv
 	return entries
 }
 
+// States returns the states captured when the table's entries were computed,
+// keyed by the entries' keys.  The registers read by an entry's state are the
+// registers from which the entry's value was derived.
+func (table *DerivedDataTable[TKey, TVal]) States() map[TKey]*state.State {
+	table.lock.RLock()
+	defer table.lock.RUnlock()
+
+	states := make(map[TKey]*state.State, len(table.items))
+	for key, entry := range table.items {
+		states[key] = entry.State
+	}
+
+	return states
+}
+
 func (table *DerivedDataTable[TKey, TVal]) InvalidatorsForTestingOnly() chainedTableInvalidators[TKey, TVal] {
 	table.lock.RLock()
 	defer table.lock.RUnlock()
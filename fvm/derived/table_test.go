@@ -890,3 +890,31 @@ func TestTxnDerivedDataGetOrCompute(t *testing.T) {
 		assert.True(t, ok)
 	})
 }
+
+func TestDerivedDataTableStates(t *testing.T) {
+	block := newEmptyTestBlock()
+
+	require.Empty(t, block.States())
+
+	testTxn, err := block.NewTableTransaction(0, 0)
+	require.NoError(t, err)
+
+	value1 := "value1"
+	state1 := &state.State{}
+	testTxn.Set("key1", &value1, state1)
+
+	value2 := "value2"
+	state2 := &state.State{}
+	testTxn.Set("key2", &value2, state2)
+
+	// uncommitted entries are not included.
+	require.Empty(t, block.States())
+
+	err = testTxn.Commit()
+	require.NoError(t, err)
+
+	states := block.States()
+	require.Equal(t, 2, len(states))
+	require.Same(t, state1, states["key1"])
+	require.Same(t, state2, states["key2"])
+}
@@ -192,10 +192,11 @@ func (emitter *eventEmitter) EmitEvent(event cadence.Event) error {
 
 	eventEmitError := emitter.eventCollection.AppendEvent(flowEvent, payloadSize)
 	// skip limit if payer is service account
-	if !isServiceAccount {
+	if !isServiceAccount && eventEmitError != nil {
 		return eventEmitError
 	}
 
+	emitter.tracer.ExecutionTrace.RecordEvent(flowEvent)
 	return nil
 }
 
@@ -56,13 +56,21 @@ func (sys *SystemContracts) Invoke(
 		Name:    spec.LocationName,
 	}
 
+	function := contractLocation.String() + "." + spec.FunctionName
+
 	span := sys.tracer.StartSpanFromRoot(trace.FVMInvokeContractFunction)
 	span.SetAttributes(
 		attribute.String(
 			"transaction.ContractFunctionCall",
-			contractLocation.String()+"."+spec.FunctionName))
+			function))
 	defer span.End()
 
+	sys.tracer.ExecutionTrace.RecordFunctionEnter(function)
+	defer sys.tracer.ExecutionTrace.RecordFunctionExit(function)
+
+	sys.tracer.ComputationProfile.RecordFunctionEnter(function)
+	defer sys.tracer.ComputationProfile.RecordFunctionExit()
+
 	runtime := sys.runtime.BorrowCadenceRuntime()
 	defer sys.runtime.ReturnCadenceRuntime(runtime)
 
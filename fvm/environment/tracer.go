@@ -6,6 +6,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	otelTrace "go.opentelemetry.io/otel/trace"
 
+	"github.com/onflow/flow-go/fvm/tracing"
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/module/trace"
 )
@@ -15,6 +16,14 @@ type TracerParams struct {
 	ExtensiveTracing bool
 
 	RootSpan otelTrace.Span
+
+	// ExecutionTrace, when non-nil, records the operations of the procedure's
+	// execution for debugging.
+	ExecutionTrace *tracing.ExecutionTrace
+
+	// ComputationProfile, when non-nil, accumulates the computation and
+	// memory usage of the procedure's execution for profiling.
+	ComputationProfile *tracing.ComputationProfile
 }
 
 func DefaultTracerParams() TracerParams {
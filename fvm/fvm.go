@@ -85,6 +85,55 @@ func (vm *VirtualMachine) Run(
 	proc Procedure,
 	v state.View,
 ) error {
+	derivedTxnData, err := vm.run(ctx, proc, v)
+	if err != nil {
+		return err
+	}
+
+	// Note: it is safe to skip committing derived data for non-normal
+	// transactions (i.e., bootstrap and script) since these do not invalidate
+	// derived data entries.
+	if proc.Type() == TransactionProcedureType {
+		// NOTE: It is not safe to ignore derivedTxnData' commit error for
+		// transactions that trigger derived data invalidation.
+		return derivedTxnData.Commit()
+	}
+
+	return nil
+}
+
+// RunUncommitted runs a procedure against a ledger in the given context,
+// but, unlike Run, does not commit the procedure's derived data.
+//
+// This allows transactions to be executed speculatively and out of order,
+// against a snapshot older than the transaction index (see
+// TransactionProcedure.InitialSnapshotTxIndex).  The caller is responsible
+// for validating and committing the returned derived transaction data in
+// transaction index order, and for discarding the transaction's results if
+// the validation fails.
+//
+// Committing a script's derived data is also allowed, since scripts never
+// invalidate derived data entries.  This can be used to load programs into
+// the context's derived block data.
+func (vm *VirtualMachine) RunUncommitted(
+	ctx Context,
+	proc Procedure,
+	v state.View,
+) (
+	*derived.DerivedTransactionData,
+	error,
+) {
+	return vm.run(ctx, proc, v)
+}
+
+func (vm *VirtualMachine) run(
+	ctx Context,
+	proc Procedure,
+	v state.View,
+) (
+	*derived.DerivedTransactionData,
+	error,
+) {
 	derivedBlockData := ctx.DerivedBlockData
 	if derivedBlockData == nil {
 		derivedBlockData = derived.NewEmptyDerivedBlockDataWithTransactionOffset(
@@ -103,11 +152,11 @@ func (vm *VirtualMachine) Run(
 			proc.InitialSnapshotTime(),
 			proc.ExecutionTime())
 	default:
-		return fmt.Errorf("invalid proc type: %v", proc.Type())
+		return nil, fmt.Errorf("invalid proc type: %v", proc.Type())
 	}
 
 	if err != nil {
-		return fmt.Errorf("error creating derived transaction data: %w", err)
+		return nil, fmt.Errorf("error creating derived transaction data: %w", err)
 	}
 
 	txnState := state.NewTransactionState(
@@ -116,22 +165,15 @@ func (vm *VirtualMachine) Run(
 			WithMeterParameters(getBasicMeterParameters(ctx, proc)).
 			WithMaxKeySizeAllowed(ctx.MaxStateKeySize).
 			WithMaxValueSizeAllowed(ctx.MaxStateValueSize))
+	txnState.SetExecutionTrace(ctx.ExecutionTrace)
+	txnState.SetComputationProfile(ctx.ComputationProfile)
 
 	err = Run(proc.NewExecutor(ctx, txnState, derivedTxnData))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Note: it is safe to skip committing derived data for non-normal
-	// transactions (i.e., bootstrap and script) since these do not invalidate
-	// derived data entries.
-	if proc.Type() == TransactionProcedureType {
-		// NOTE: It is not safe to ignore derivedTxnData' commit error for
-		// transactions that trigger derived data invalidation.
-		return derivedTxnData.Commit()
-	}
-
-	return nil
+	return derivedTxnData, nil
 }
 
 // GetAccount returns an account by address or an error if none exists.
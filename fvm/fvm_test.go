@@ -26,6 +26,7 @@ import (
 	errors "github.com/onflow/flow-go/fvm/errors"
 	"github.com/onflow/flow-go/fvm/meter"
 	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/fvm/tracing"
 	"github.com/onflow/flow-go/fvm/utils"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/utils/unittest"
@@ -603,6 +604,143 @@ func TestEventLimits(t *testing.T) {
 	})
 }
 
+func TestExecutionTrace(t *testing.T) {
+	chain, vm := createChainAndVm(flow.Testnet)
+	derivedBlockData := derived.NewEmptyDerivedBlockData()
+
+	ctx := fvm.NewContext(
+		fvm.WithChain(chain),
+		fvm.WithAuthorizationChecksEnabled(false),
+		fvm.WithSequenceNumberCheckAndIncrementEnabled(false),
+		fvm.WithDerivedBlockData(derivedBlockData),
+	)
+
+	ledger := testutil.RootBootstrappedLedger(vm, ctx)
+
+	txBody := flow.NewTransactionBody().
+		SetScript([]byte(`
+			transaction {
+				prepare(signer: AuthAccount) {
+					AuthAccount(payer: signer)
+				}
+			}
+		`)).
+		SetPayer(chain.ServiceAddress()).
+		AddAuthorizer(chain.ServiceAddress())
+
+	trace := tracing.NewExecutionTrace()
+	tx := fvm.Transaction(txBody, derivedBlockData.NextTxIndexForTestingOnly())
+	err := vm.Run(fvm.NewContextFromParent(ctx, fvm.WithExecutionTrace(trace)), tx, ledger)
+	require.NoError(t, err)
+	require.NoError(t, tx.Err)
+
+	counts := make(map[tracing.EntryKind]int)
+	var functions []string
+	var events []string
+	for i, entry := range trace.Entries() {
+		require.Equal(t, uint64(i), entry.Index)
+		counts[entry.Kind]++
+
+		switch entry.Kind {
+		case tracing.EntryKindFunctionEnter, tracing.EntryKindFunctionExit:
+			functions = append(functions, string(entry.Kind)+" "+entry.Function)
+		case tracing.EntryKindEvent:
+			events = append(events, entry.EventType)
+		}
+	}
+
+	require.NotZero(t, counts[tracing.EntryKindRegisterRead])
+	require.NotZero(t, counts[tracing.EntryKindRegisterWrite])
+	require.NotZero(t, counts[tracing.EntryKindComputation])
+	require.NotZero(t, counts[tracing.EntryKindMemory])
+
+	setupNewAccount := fmt.Sprintf(
+		"%s.FlowServiceAccount.setupNewAccount",
+		chain.ServiceAddress().Hex())
+	require.Equal(
+		t,
+		[]string{
+			string(tracing.EntryKindFunctionEnter) + " " + setupNewAccount,
+			string(tracing.EntryKindFunctionExit) + " " + setupNewAccount,
+		},
+		functions)
+
+	require.Len(t, events, len(tx.Events))
+	for i, event := range tx.Events {
+		require.Equal(t, string(event.Type), events[i])
+	}
+
+	t.Run("untraced execution is identical", func(t *testing.T) {
+		tracedCtx := fvm.NewContextFromParent(
+			ctx,
+			fvm.WithDerivedBlockData(derived.NewEmptyDerivedBlockData()),
+			fvm.WithExecutionTrace(tracing.NewExecutionTrace()))
+		tracedTx := fvm.Transaction(txBody, 0)
+		err := vm.Run(tracedCtx, tracedTx, ledger.NewChild())
+		require.NoError(t, err)
+
+		untracedCtx := fvm.NewContextFromParent(
+			ctx,
+			fvm.WithDerivedBlockData(derived.NewEmptyDerivedBlockData()))
+		untracedTx := fvm.Transaction(txBody, 0)
+		err = vm.Run(untracedCtx, untracedTx, ledger.NewChild())
+		require.NoError(t, err)
+
+		require.Equal(t, untracedTx.ComputationUsed, tracedTx.ComputationUsed)
+		require.Equal(t, untracedTx.Events, tracedTx.Events)
+	})
+}
+
+func TestComputationProfile(t *testing.T) {
+	chain, vm := createChainAndVm(flow.Testnet)
+	derivedBlockData := derived.NewEmptyDerivedBlockData()
+
+	ctx := fvm.NewContext(
+		fvm.WithChain(chain),
+		fvm.WithAuthorizationChecksEnabled(false),
+		fvm.WithSequenceNumberCheckAndIncrementEnabled(false),
+		fvm.WithDerivedBlockData(derivedBlockData),
+	)
+
+	ledger := testutil.RootBootstrappedLedger(vm, ctx)
+
+	txBody := flow.NewTransactionBody().
+		SetScript([]byte(`
+			transaction {
+				prepare(signer: AuthAccount) {
+					AuthAccount(payer: signer)
+				}
+			}
+		`)).
+		SetPayer(chain.ServiceAddress()).
+		AddAuthorizer(chain.ServiceAddress())
+
+	profile := tracing.NewComputationProfile("transaction")
+	tx := fvm.Transaction(txBody, derivedBlockData.NextTxIndexForTestingOnly())
+	err := vm.Run(fvm.NewContextFromParent(ctx, fvm.WithComputationProfile(profile)), tx, ledger)
+	require.NoError(t, err)
+	require.NoError(t, tx.Err)
+
+	prof := profile.Profile(map[string]string{"transaction_id": tx.ID.String()})
+	require.NoError(t, prof.CheckValid())
+
+	var computation, memory int64
+	for _, sample := range prof.Sample {
+		require.Equal(t, []string{tx.ID.String()}, sample.Label["transaction_id"])
+
+		// the leaf is the metered kind, the root is the transaction
+		require.Len(t, sample.Location, 2)
+		require.Equal(t, "transaction", sample.Location[1].Line[0].Function.Name)
+
+		computation += sample.Value[0]
+		memory += sample.Value[1]
+	}
+
+	require.NotZero(t, computation)
+	require.LessOrEqual(t, uint64(computation), tx.ComputationUsed)
+	require.NotZero(t, memory)
+}
+
 // TestHappyPathSigning checks that a signing a transaction with `Sign` doesn't produce an error.
 // Transaction verification tests are in `TestVerifySignatureFromTransaction`.
 func TestHappyPathTransactionSigning(t *testing.T) {
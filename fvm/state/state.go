@@ -146,6 +146,16 @@ func (s *State) InteractionUsed() uint64 {
 	return s.meter.TotalBytesOfStorageInteractions()
 }
 
+// TotalBytesReadFromStorage returns the total number of bytes read from the ledger
+func (s *State) TotalBytesReadFromStorage() uint64 {
+	return s.meter.TotalBytesReadFromStorage()
+}
+
+// TotalBytesWrittenToStorage returns the total number of bytes written to the ledger
+func (s *State) TotalBytesWrittenToStorage() uint64 {
+	return s.meter.TotalBytesWrittenToStorage()
+}
+
 // RegisterUpdates returns the lists of register id / value that were updated.
 func (s *State) RegisterUpdates() ([]flow.RegisterID, []flow.RegisterValue) {
 	return s.view.RegisterUpdates()
@@ -6,6 +6,7 @@ import (
 	"github.com/onflow/cadence/runtime/common"
 
 	"github.com/onflow/flow-go/fvm/meter"
+	"github.com/onflow/flow-go/fvm/tracing"
 	"github.com/onflow/flow-go/model/flow"
 )
 
@@ -28,6 +29,14 @@ type TransactionState struct {
 	// NOTE: The first frame is always the main transaction, and is not
 	// poppable during the course of the transaction.
 	nestedTransactions []nestedTransactionStackFrame
+
+	// When non-nil, register reads / writes and metering charges of all
+	// (nested) transactions are recorded in the execution trace.
+	executionTrace *tracing.ExecutionTrace
+
+	// When non-nil, the weighted metering charges of all (nested)
+	// transactions are accumulated in the computation profile.
+	computationProfile *tracing.ComputationProfile
 }
 
 // Opaque identifier used for Restarting nested transactions
@@ -57,6 +66,20 @@ func NewTransactionState(
 	}
 }
 
+// SetExecutionTrace sets the execution trace in which the register reads /
+// writes and metering charges of the transaction are recorded.
+func (s *TransactionState) SetExecutionTrace(trace *tracing.ExecutionTrace) {
+	s.executionTrace = trace
+}
+
+// SetComputationProfile sets the profile in which the weighted metering
+// charges of the transaction are accumulated.
+func (s *TransactionState) SetComputationProfile(
+	profile *tracing.ComputationProfile,
+) {
+	s.computationProfile = profile
+}
+
 func (s *TransactionState) current() nestedTransactionStackFrame {
 	return s.nestedTransactions[s.NumNestedTransactions()]
 }
@@ -291,10 +314,11 @@ func (s *TransactionState) Resume(pausedState *State) {
 
 // AttachAndCommit commits the changes in the cached nested transaction state
 // to the current (nested) transaction.
+//
+// Note: The cached state is not modified since it may be shared by
+// concurrently executing transactions.
 func (s *TransactionState) AttachAndCommit(cachedState *State) error {
-	s.push(cachedState, nil)
-	_, err := s.mergeIntoParent()
-	return err
+	return s.current().state.MergeState(cachedState)
 }
 
 // RestartNestedTransaction merges all changes that belongs to the nested
@@ -338,7 +362,13 @@ func (s *TransactionState) Get(
 	flow.RegisterValue,
 	error,
 ) {
-	return s.currentState().Get(owner, key, enforceLimit)
+	value, err := s.currentState().Get(owner, key, enforceLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.executionTrace.RecordRegisterRead(owner, key, value)
+	return value, nil
 }
 
 func (s *TransactionState) Set(
@@ -347,7 +377,13 @@ func (s *TransactionState) Set(
 	value flow.RegisterValue,
 	enforceLimit bool,
 ) error {
-	return s.currentState().Set(owner, key, value, enforceLimit)
+	err := s.currentState().Set(owner, key, value, enforceLimit)
+	if err != nil {
+		return err
+	}
+
+	s.executionTrace.RecordRegisterWrite(owner, key, value)
+	return nil
 }
 
 func (s *TransactionState) UpdatedAddresses() []flow.Address {
@@ -358,6 +394,11 @@ func (s *TransactionState) MeterComputation(
 	kind common.ComputationKind,
 	intensity uint,
 ) error {
+	s.executionTrace.RecordComputation(kind, intensity)
+	s.computationProfile.RecordComputation(
+		kind,
+		intensity,
+		s.currentState().Meter().ComputationWeights())
 	return s.currentState().MeterComputation(kind, intensity)
 }
 
@@ -365,6 +406,11 @@ func (s *TransactionState) MeterMemory(
 	kind common.MemoryKind,
 	intensity uint,
 ) error {
+	s.executionTrace.RecordMemory(kind, intensity)
+	s.computationProfile.RecordMemory(
+		kind,
+		intensity,
+		s.currentState().Meter().MemoryWeights())
 	return s.currentState().MeterMemory(kind, intensity)
 }
 
@@ -392,6 +438,14 @@ func (s *TransactionState) InteractionUsed() uint64 {
 	return s.currentState().InteractionUsed()
 }
 
+func (s *TransactionState) TotalBytesReadFromStorage() uint64 {
+	return s.currentState().TotalBytesReadFromStorage()
+}
+
+func (s *TransactionState) TotalBytesWrittenToStorage() uint64 {
+	return s.currentState().TotalBytesWrittenToStorage()
+}
+
 func (s *TransactionState) MeterEmittedEvent(byteSize uint64) error {
 	return s.currentState().MeterEmittedEvent(byteSize)
 }
@@ -0,0 +1,239 @@
+package tracing
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/onflow/cadence/runtime/common"
+
+	"github.com/onflow/flow-go/fvm/meter"
+)
+
+const (
+	ProfileSampleTypeComputation = "computation"
+	ProfileSampleTypeMemory      = "memory"
+)
+
+type profileSampleKey struct {
+	stack string
+	leaf  string
+}
+
+type profileSample struct {
+	// stack lists the sample's frames, from the root to the leaf.
+	stack []string
+
+	// computation is in the meter's internal precision, see
+	// meter.MeterExecutionInternalPrecisionBytes.
+	computation uint64
+	memory      uint64
+}
+
+// ComputationProfile accumulates the weighted computation and memory metered
+// during a procedure's execution, attributed to the contract functions which
+// were executing when the usage was metered.
+//
+// Every sample's stack starts with the profile's root frame, followed by the
+// contract functions invoked by the FVM, and ends with the metered
+// computation / memory kind.  Function calls made inside Cadence programs are
+// not visible to the FVM, so their usage is attributed to the innermost
+// contract function invoked by the FVM, or to the root frame.  Usage which is
+// not metered (e.g. while limits are disabled) is not recorded either.
+//
+// All methods are no-ops on a nil profile, so that call sites do not need to
+// check whether profiling is enabled.  ComputationProfile is not safe for
+// concurrent use, and should only be used to profile a single procedure.
+type ComputationProfile struct {
+	root string
+
+	stack    []string
+	stackKey string
+
+	samples       []*profileSample
+	sampleIndices map[profileSampleKey]int
+}
+
+// NewComputationProfile returns a new empty computation profile, whose samples
+// are rooted at the given frame (e.g. "transaction").
+func NewComputationProfile(root string) *ComputationProfile {
+	p := &ComputationProfile{
+		root: root,
+	}
+	p.Reset()
+	return p
+}
+
+// Reset discards all samples of the profile, so that it can be reused to
+// profile a re-execution of the procedure.
+func (p *ComputationProfile) Reset() {
+	if p == nil {
+		return
+	}
+
+	p.stack = []string{p.root}
+	p.stackKey = p.root
+	p.samples = nil
+	p.sampleIndices = make(map[profileSampleKey]int)
+}
+
+func (p *ComputationProfile) updateStackKey() {
+	p.stackKey = strings.Join(p.stack, "\n")
+}
+
+// RecordFunctionEnter records that the contract function was invoked.
+func (p *ComputationProfile) RecordFunctionEnter(function string) {
+	if p == nil {
+		return
+	}
+
+	p.stack = append(p.stack, function)
+	p.updateStackKey()
+}
+
+// RecordFunctionExit records that the innermost contract function returned.
+func (p *ComputationProfile) RecordFunctionExit() {
+	if p == nil || len(p.stack) < 2 {
+		return
+	}
+
+	p.stack = p.stack[:len(p.stack)-1]
+	p.updateStackKey()
+}
+
+func (p *ComputationProfile) sample(leaf string) *profileSample {
+	key := profileSampleKey{
+		stack: p.stackKey,
+		leaf:  leaf,
+	}
+
+	index, ok := p.sampleIndices[key]
+	if !ok {
+		stack := make([]string, 0, len(p.stack)+1)
+		stack = append(stack, p.stack...)
+		stack = append(stack, leaf)
+
+		index = len(p.samples)
+		p.sampleIndices[key] = index
+		p.samples = append(p.samples, &profileSample{stack: stack})
+	}
+
+	return p.samples[index]
+}
+
+// RecordComputation records a computation metering charge, weighted by the
+// given weights.  Charges of kinds without weight are not metered, and are
+// therefore ignored.
+func (p *ComputationProfile) RecordComputation(
+	kind common.ComputationKind,
+	intensity uint,
+	weights meter.ExecutionEffortWeights,
+) {
+	if p == nil {
+		return
+	}
+
+	weight, ok := weights[kind]
+	if !ok || weight == 0 || intensity == 0 {
+		return
+	}
+
+	p.sample(kind.String()).computation += weight * uint64(intensity)
+}
+
+// RecordMemory records a memory metering charge, weighted by the given
+// weights.  Charges of kinds without weight are not metered, and are
+// therefore ignored.
+func (p *ComputationProfile) RecordMemory(
+	kind common.MemoryKind,
+	intensity uint,
+	weights meter.ExecutionMemoryWeights,
+) {
+	if p == nil {
+		return
+	}
+
+	weight, ok := weights[kind]
+	if !ok || weight == 0 || intensity == 0 {
+		return
+	}
+
+	p.sample(kind.String()).memory += weight * uint64(intensity)
+}
+
+// Profile returns the profile in the pprof format, so that it can be analyzed
+// with the standard pprof tools.  The given labels are attached to every
+// sample, e.g. to identify the profiled transaction once profiles are merged.
+//
+// Computation is reported in computation units.  Since the conversion from
+// the meter's internal precision happens per sample, the samples may add up
+// to slightly less than the computation used by the procedure.
+func (p *ComputationProfile) Profile(labels map[string]string) *profile.Profile {
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: ProfileSampleTypeComputation, Unit: "count"},
+			{Type: ProfileSampleTypeMemory, Unit: "bytes"},
+		},
+		DefaultSampleType: ProfileSampleTypeComputation,
+		PeriodType:        &profile.ValueType{Type: ProfileSampleTypeComputation, Unit: "count"},
+		Period:            1,
+		TimeNanos:         time.Now().UnixNano(),
+	}
+
+	if p == nil {
+		return prof
+	}
+
+	var sampleLabels map[string][]string
+	if len(labels) > 0 {
+		sampleLabels = make(map[string][]string, len(labels))
+		for key, value := range labels {
+			sampleLabels[key] = []string{value}
+		}
+	}
+
+	locations := make(map[string]*profile.Location)
+	location := func(name string) *profile.Location {
+		loc, ok := locations[name]
+		if ok {
+			return loc
+		}
+
+		function := &profile.Function{
+			ID:         uint64(len(prof.Function) + 1),
+			Name:       name,
+			SystemName: name,
+		}
+		prof.Function = append(prof.Function, function)
+
+		loc = &profile.Location{
+			ID:   uint64(len(prof.Location) + 1),
+			Line: []profile.Line{{Function: function}},
+		}
+		prof.Location = append(prof.Location, loc)
+		locations[name] = loc
+
+		return loc
+	}
+
+	for _, sample := range p.samples {
+		computation := sample.computation >> meter.MeterExecutionInternalPrecisionBytes
+		if computation == 0 && sample.memory == 0 {
+			continue
+		}
+
+		// pprof lists the locations of a sample from the leaf to the root
+		sampleLocations := make([]*profile.Location, 0, len(sample.stack))
+		for i := len(sample.stack) - 1; i >= 0; i-- {
+			sampleLocations = append(sampleLocations, location(sample.stack[i]))
+		}
+
+		prof.Sample = append(prof.Sample, &profile.Sample{
+			Location: sampleLocations,
+			Value:    []int64{int64(computation), int64(sample.memory)},
+			Label:    sampleLabels,
+		})
+	}
+
+	return prof
+}
@@ -0,0 +1,122 @@
+package tracing_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/fvm/meter"
+	"github.com/onflow/flow-go/fvm/tracing"
+)
+
+var (
+	testComputationWeights = meter.ExecutionEffortWeights{
+		common.ComputationKindStatement: 2 << meter.MeterExecutionInternalPrecisionBytes,
+		common.ComputationKindLoop:      1 << (meter.MeterExecutionInternalPrecisionBytes - 1),
+	}
+	testMemoryWeights = meter.ExecutionMemoryWeights{
+		common.MemoryKindActivation: 10,
+	}
+)
+
+// sampleStacks returns the values of the profile's samples keyed by their
+// stack, from the root to the leaf, joined by "/".
+func sampleStacks(prof *profile.Profile) map[string][]int64 {
+	stacks := make(map[string][]int64, len(prof.Sample))
+	for _, sample := range prof.Sample {
+		stack := ""
+		for i := len(sample.Location) - 1; i >= 0; i-- {
+			if stack != "" {
+				stack += "/"
+			}
+			stack += sample.Location[i].Line[0].Function.Name
+		}
+		stacks[stack] = sample.Value
+	}
+	return stacks
+}
+
+func TestComputationProfile_Nil(t *testing.T) {
+	var p *tracing.ComputationProfile
+
+	require.NotPanics(t, func() {
+		p.RecordFunctionEnter("A.0000000000000001.Contract.f")
+		p.RecordComputation(common.ComputationKindStatement, 1, testComputationWeights)
+		p.RecordMemory(common.MemoryKindActivation, 1, testMemoryWeights)
+		p.RecordFunctionExit()
+		p.Reset()
+	})
+
+	prof := p.Profile(nil)
+	require.NoError(t, prof.CheckValid())
+	require.Empty(t, prof.Sample)
+}
+
+func TestComputationProfile_Profile(t *testing.T) {
+	p := tracing.NewComputationProfile("transaction")
+
+	p.RecordComputation(common.ComputationKindStatement, 1, testComputationWeights)
+	p.RecordFunctionEnter("A.0000000000000001.Contract.f")
+	p.RecordComputation(common.ComputationKindStatement, 2, testComputationWeights)
+	p.RecordMemory(common.MemoryKindActivation, 3, testMemoryWeights)
+	p.RecordFunctionEnter("A.0000000000000001.Contract.g")
+	p.RecordComputation(common.ComputationKindLoop, 3, testComputationWeights)
+	p.RecordFunctionExit()
+	p.RecordComputation(common.ComputationKindStatement, 1, testComputationWeights)
+	// kinds without weight are not metered
+	p.RecordComputation(common.ComputationKindFunctionInvocation, 1, testComputationWeights)
+	p.RecordMemory(common.MemoryKindBigInt, 1, testMemoryWeights)
+	p.RecordFunctionExit()
+	// exiting the root frame is ignored
+	p.RecordFunctionExit()
+	p.RecordMemory(common.MemoryKindActivation, 1, testMemoryWeights)
+
+	prof := p.Profile(map[string]string{"transaction_id": "abc"})
+	require.NoError(t, prof.CheckValid())
+
+	require.Equal(t, tracing.ProfileSampleTypeComputation, prof.DefaultSampleType)
+	require.Len(t, prof.SampleType, 2)
+	require.Equal(t, tracing.ProfileSampleTypeComputation, prof.SampleType[0].Type)
+	require.Equal(t, tracing.ProfileSampleTypeMemory, prof.SampleType[1].Type)
+
+	require.Equal(
+		t,
+		map[string][]int64{
+			"transaction/Statement":                                {2, 0},
+			"transaction/Activation":                               {0, 10},
+			"transaction/A.0000000000000001.Contract.f/Statement":  {6, 0},
+			"transaction/A.0000000000000001.Contract.f/Activation": {0, 30},
+			// 3 loops with a weight of 1/2 computation unit each
+			"transaction/A.0000000000000001.Contract.f/A.0000000000000001.Contract.g/Loop": {1, 0},
+		},
+		sampleStacks(prof))
+
+	for _, sample := range prof.Sample {
+		require.Equal(t, map[string][]string{"transaction_id": {"abc"}}, sample.Label)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, prof.Write(buf))
+
+		parsed, err := profile.Parse(buf)
+		require.NoError(t, err)
+		require.Equal(t, sampleStacks(prof), sampleStacks(parsed))
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		p.RecordFunctionEnter("A.0000000000000001.Contract.f")
+		p.Reset()
+		p.RecordComputation(common.ComputationKindStatement, 1, testComputationWeights)
+
+		require.Equal(
+			t,
+			map[string][]int64{
+				"transaction/Statement": {2, 0},
+			},
+			sampleStacks(p.Profile(nil)))
+	})
+}
@@ -0,0 +1,167 @@
+package tracing
+
+import (
+	"encoding/hex"
+
+	"github.com/onflow/cadence/runtime/common"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// EntryKind is the kind of operation recorded by an execution trace entry.
+type EntryKind string
+
+const (
+	EntryKindRegisterRead  EntryKind = "register_read"
+	EntryKindRegisterWrite EntryKind = "register_write"
+	EntryKindFunctionEnter EntryKind = "function_enter"
+	EntryKindFunctionExit  EntryKind = "function_exit"
+	EntryKindEvent         EntryKind = "event"
+	EntryKindComputation   EntryKind = "computation"
+	EntryKindMemory        EntryKind = "memory"
+)
+
+// Entry is a single operation recorded by an execution trace.  Only the fields
+// relevant to the entry's kind are set.  Register owners, keys and values are
+// hex encoded.
+type Entry struct {
+	// Index is the position of the entry in the trace.
+	Index uint64    `json:"index"`
+	Kind  EntryKind `json:"kind"`
+
+	Owner string `json:"owner,omitempty"`
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value,omitempty"`
+
+	Function string `json:"function,omitempty"`
+
+	EventType  string `json:"event_type,omitempty"`
+	EventIndex uint32 `json:"event_index,omitempty"`
+
+	MeteringKind string `json:"metering_kind,omitempty"`
+	Intensity    uint   `json:"intensity,omitempty"`
+}
+
+// ExecutionTrace records, in order, the register reads and writes, contract
+// function invocations, emitted events and metering charges of a procedure's
+// execution.
+//
+// The trace does not contain any timing information, so executing the same
+// procedure against the same state always produces the same trace.
+//
+// All methods are no-ops on a nil trace, so that call sites do not need to
+// check whether tracing is enabled.  ExecutionTrace is not safe for concurrent
+// use, and should only be used to trace a single procedure.
+type ExecutionTrace struct {
+	entries []Entry
+}
+
+// NewExecutionTrace returns a new empty execution trace.
+func NewExecutionTrace() *ExecutionTrace {
+	return &ExecutionTrace{}
+}
+
+func (trace *ExecutionTrace) record(entry Entry) {
+	entry.Index = uint64(len(trace.entries))
+	trace.entries = append(trace.entries, entry)
+}
+
+// RecordRegisterRead records that the register was read, and had the given value.
+func (trace *ExecutionTrace) RecordRegisterRead(owner string, key string, value flow.RegisterValue) {
+	if trace == nil {
+		return
+	}
+
+	trace.record(Entry{
+		Kind:  EntryKindRegisterRead,
+		Owner: hex.EncodeToString([]byte(owner)),
+		Key:   hex.EncodeToString([]byte(key)),
+		Value: hex.EncodeToString(value),
+	})
+}
+
+// RecordRegisterWrite records that the given value was written to the register.
+func (trace *ExecutionTrace) RecordRegisterWrite(owner string, key string, value flow.RegisterValue) {
+	if trace == nil {
+		return
+	}
+
+	trace.record(Entry{
+		Kind:  EntryKindRegisterWrite,
+		Owner: hex.EncodeToString([]byte(owner)),
+		Key:   hex.EncodeToString([]byte(key)),
+		Value: hex.EncodeToString(value),
+	})
+}
+
+// RecordFunctionEnter records that the contract function was invoked.
+func (trace *ExecutionTrace) RecordFunctionEnter(function string) {
+	if trace == nil {
+		return
+	}
+
+	trace.record(Entry{
+		Kind:     EntryKindFunctionEnter,
+		Function: function,
+	})
+}
+
+// RecordFunctionExit records that the contract function returned.
+func (trace *ExecutionTrace) RecordFunctionExit(function string) {
+	if trace == nil {
+		return
+	}
+
+	trace.record(Entry{
+		Kind:     EntryKindFunctionExit,
+		Function: function,
+	})
+}
+
+// RecordEvent records that the event was emitted.
+func (trace *ExecutionTrace) RecordEvent(event flow.Event) {
+	if trace == nil {
+		return
+	}
+
+	trace.record(Entry{
+		Kind:       EntryKindEvent,
+		EventType:  string(event.Type),
+		EventIndex: event.EventIndex,
+	})
+}
+
+// RecordComputation records a computation metering charge.
+func (trace *ExecutionTrace) RecordComputation(kind common.ComputationKind, intensity uint) {
+	if trace == nil {
+		return
+	}
+
+	trace.record(Entry{
+		Kind:         EntryKindComputation,
+		MeteringKind: kind.String(),
+		Intensity:    intensity,
+	})
+}
+
+// RecordMemory records a memory metering charge.
+func (trace *ExecutionTrace) RecordMemory(kind common.MemoryKind, intensity uint) {
+	if trace == nil {
+		return
+	}
+
+	trace.record(Entry{
+		Kind:         EntryKindMemory,
+		MeteringKind: kind.String(),
+		Intensity:    intensity,
+	})
+}
+
+// Entries returns the recorded entries, in the order they were recorded.
+func (trace *ExecutionTrace) Entries() []Entry {
+	if trace == nil {
+		return nil
+	}
+
+	return trace.entries
+}
@@ -0,0 +1,155 @@
+package tracing_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/fvm/tracing"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func TestExecutionTrace_Nil(t *testing.T) {
+	var trace *tracing.ExecutionTrace
+
+	require.NotPanics(t, func() {
+		trace.RecordRegisterRead("owner", "key", []byte{1})
+		trace.RecordRegisterWrite("owner", "key", []byte{1})
+		trace.RecordFunctionEnter("A.0000000000000001.Contract.f")
+		trace.RecordFunctionExit("A.0000000000000001.Contract.f")
+		trace.RecordEvent(flow.Event{Type: "flow.AccountCreated"})
+		trace.RecordComputation(common.ComputationKindStatement, 1)
+		trace.RecordMemory(common.MemoryKindActivation, 1)
+	})
+	require.Empty(t, trace.Entries())
+}
+
+func newTestTrace() *tracing.ExecutionTrace {
+	trace := tracing.NewExecutionTrace()
+	trace.RecordFunctionEnter("A.0000000000000001.Contract.f")
+	trace.RecordRegisterRead("owner", "key", []byte{1})
+	trace.RecordComputation(common.ComputationKindStatement, 2)
+	trace.RecordRegisterWrite("owner", "key", []byte{2})
+	trace.RecordEvent(flow.Event{Type: "flow.AccountCreated", EventIndex: 3})
+	trace.RecordMemory(common.MemoryKindActivation, 4)
+	trace.RecordFunctionExit("A.0000000000000001.Contract.f")
+	return trace
+}
+
+func TestExecutionTrace_Entries(t *testing.T) {
+	trace := newTestTrace()
+
+	require.Equal(
+		t,
+		[]tracing.Entry{
+			{
+				Index:    0,
+				Kind:     tracing.EntryKindFunctionEnter,
+				Function: "A.0000000000000001.Contract.f",
+			},
+			{
+				Index: 1,
+				Kind:  tracing.EntryKindRegisterRead,
+				Owner: "6f776e6572",
+				Key:   "6b6579",
+				Value: "01",
+			},
+			{
+				Index:        2,
+				Kind:         tracing.EntryKindComputation,
+				MeteringKind: common.ComputationKindStatement.String(),
+				Intensity:    2,
+			},
+			{
+				Index: 3,
+				Kind:  tracing.EntryKindRegisterWrite,
+				Owner: "6f776e6572",
+				Key:   "6b6579",
+				Value: "02",
+			},
+			{
+				Index:      4,
+				Kind:       tracing.EntryKindEvent,
+				EventType:  "flow.AccountCreated",
+				EventIndex: 3,
+			},
+			{
+				Index:        5,
+				Kind:         tracing.EntryKindMemory,
+				MeteringKind: common.MemoryKindActivation.String(),
+				Intensity:    4,
+			},
+			{
+				Index:    6,
+				Kind:     tracing.EntryKindFunctionExit,
+				Function: "A.0000000000000001.Contract.f",
+			},
+		},
+		trace.Entries())
+}
+
+func TestExecutionTrace_Export(t *testing.T) {
+	trace := newTestTrace()
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := trace.Export(&buf, tracing.FormatJSON)
+		require.NoError(t, err)
+
+		var entries []tracing.Entry
+		err = json.Unmarshal(buf.Bytes(), &entries)
+		require.NoError(t, err)
+		require.Equal(t, trace.Entries(), entries)
+	})
+
+	t.Run("chrome", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := trace.Export(&buf, tracing.FormatChrome)
+		require.NoError(t, err)
+
+		var chrome struct {
+			TraceEvents []struct {
+				Name      string `json:"name"`
+				Phase     string `json:"ph"`
+				Timestamp uint64 `json:"ts"`
+			} `json:"traceEvents"`
+		}
+		err = json.Unmarshal(buf.Bytes(), &chrome)
+		require.NoError(t, err)
+		require.Len(t, chrome.TraceEvents, len(trace.Entries()))
+
+		first := chrome.TraceEvents[0]
+		require.Equal(t, "A.0000000000000001.Contract.f", first.Name)
+		require.Equal(t, "B", first.Phase)
+
+		last := chrome.TraceEvents[len(chrome.TraceEvents)-1]
+		require.Equal(t, "A.0000000000000001.Contract.f", last.Name)
+		require.Equal(t, "E", last.Phase)
+		require.Equal(t, uint64(6), last.Timestamp)
+
+		event := chrome.TraceEvents[4]
+		require.Equal(t, "flow.AccountCreated", event.Name)
+		require.Equal(t, "i", event.Phase)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		err := trace.Export(&bytes.Buffer{}, tracing.Format("unknown"))
+		require.Error(t, err)
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := tracing.ParseFormat("json")
+	require.NoError(t, err)
+	require.Equal(t, tracing.FormatJSON, format)
+
+	format, err = tracing.ParseFormat("chrome")
+	require.NoError(t, err)
+	require.Equal(t, tracing.FormatChrome, format)
+
+	_, err = tracing.ParseFormat("xml")
+	require.Error(t, err)
+}
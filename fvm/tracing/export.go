@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Format is the format an execution trace is exported in.
+type Format string
+
+const (
+	// FormatJSON exports the trace entries as a JSON array.
+	FormatJSON Format = "json"
+	// FormatChrome exports the trace in the Chrome trace event format, which
+	// can be loaded in chrome://tracing or Perfetto.
+	FormatChrome Format = "chrome"
+)
+
+// ParseFormat parses the name of an export format.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case FormatJSON, FormatChrome:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown trace format %q, expected %q or %q", name, FormatJSON, FormatChrome)
+	}
+}
+
+// ChromeTraceEvent is an event of the Chrome trace event format.
+type ChromeTraceEvent struct {
+	Name      string                 `json:"name"`
+	Category  string                 `json:"cat"`
+	Phase     string                 `json:"ph"`
+	Timestamp uint64                 `json:"ts"`
+	ProcessID int                    `json:"pid"`
+	ThreadID  int                    `json:"tid"`
+	Scope     string                 `json:"s,omitempty"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+}
+
+// ChromeTrace is a trace in the Chrome trace event format.
+type ChromeTrace struct {
+	TraceEvents     []ChromeTraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string             `json:"displayTimeUnit"`
+}
+
+// ChromeTrace converts the execution trace to the Chrome trace event format.
+//
+// Since the execution trace has no timing information, the index of an entry
+// is used as its timestamp.  Function invocations are converted to duration
+// events, and all other entries to instant events.
+func (trace *ExecutionTrace) ChromeTrace() ChromeTrace {
+	entries := trace.Entries()
+
+	events := make([]ChromeTraceEvent, 0, len(entries))
+	for _, entry := range entries {
+		event := ChromeTraceEvent{
+			Name:      string(entry.Kind),
+			Category:  string(entry.Kind),
+			Phase:     "i",
+			Timestamp: entry.Index,
+			ProcessID: 1,
+			ThreadID:  1,
+			Scope:     "t",
+		}
+
+		switch entry.Kind {
+		case EntryKindFunctionEnter, EntryKindFunctionExit:
+			event.Name = entry.Function
+			event.Category = "function"
+			event.Phase = "B"
+			if entry.Kind == EntryKindFunctionExit {
+				event.Phase = "E"
+			}
+			event.Scope = ""
+		case EntryKindRegisterRead, EntryKindRegisterWrite:
+			event.Args = map[string]interface{}{
+				"owner": entry.Owner,
+				"key":   entry.Key,
+				"value": entry.Value,
+			}
+		case EntryKindEvent:
+			event.Name = entry.EventType
+			event.Args = map[string]interface{}{
+				"event_index": entry.EventIndex,
+			}
+		case EntryKindComputation, EntryKindMemory:
+			event.Name = entry.MeteringKind
+			event.Args = map[string]interface{}{
+				"intensity": entry.Intensity,
+			}
+		}
+
+		events = append(events, event)
+	}
+
+	return ChromeTrace{
+		TraceEvents:     events,
+		DisplayTimeUnit: "ns",
+	}
+}
+
+// Export writes the execution trace to the writer in the given format.
+func (trace *ExecutionTrace) Export(w io.Writer, format Format) error {
+	var value interface{}
+	switch format {
+	case FormatJSON:
+		entries := trace.Entries()
+		if entries == nil {
+			entries = []Entry{}
+		}
+		value = entries
+	case FormatChrome:
+		value = trace.ChromeTrace()
+	default:
+		return fmt.Errorf("unknown trace format %q", format)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(value)
+	if err != nil {
+		return fmt.Errorf("could not encode execution trace: %w", err)
+	}
+
+	return nil
+}
@@ -36,6 +36,8 @@ type TransactionProcedure struct {
 	ComputationUsed        uint64
 	ComputationIntensities meter.MeteredComputationIntensities
 	MemoryEstimate         uint64
+	StorageBytesRead       uint64
+	StorageBytesWritten    uint64
 	Err                    errors.CodedError
 	TraceSpan              otelTrace.Span
 }
@@ -382,9 +382,9 @@ func (executor *transactionExecutor) commit(
 	executor.proc.Logs = append(executor.proc.Logs, executor.env.Logs()...)
 	executor.proc.ComputationUsed += executor.env.ComputationUsed()
 	executor.proc.MemoryEstimate += executor.env.MemoryEstimate()
-	if executor.proc.IsSampled() {
-		executor.proc.ComputationIntensities = executor.env.ComputationIntensities()
-	}
+	executor.proc.ComputationIntensities = executor.env.ComputationIntensities()
+	executor.proc.StorageBytesRead += executor.txnState.TotalBytesReadFromStorage()
+	executor.proc.StorageBytesWritten += executor.txnState.TotalBytesWrittenToStorage()
 
 	// if tx failed this will only contain fee deduction events
 	executor.proc.Events = append(executor.proc.Events, executor.env.Events()...)
@@ -11,6 +11,7 @@ import (
 	"golang.org/x/sync/semaphore"
 
 	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	realWAL "github.com/onflow/flow-go/ledger/complete/wal"
 	"github.com/onflow/flow-go/module/lifecycle"
@@ -46,8 +47,11 @@ type checkpointResult struct {
 // This will be resolved automaticaly after the forest LRU Cache
 // (code outside checkpointing) is replaced by something like a FIFO queue.
 type Compactor struct {
-	checkpointer                         *realWAL.Checkpointer
-	wal                                  realWAL.LedgerWAL
+	checkpointer *realWAL.Checkpointer
+	wal          realWAL.LedgerWAL
+	// forest is the ledger state, which the tries in the checkpointing queue are pinned in while
+	// they are written to a checkpoint, so that their payloads are not released when evicted
+	forest                               *mtrie.Forest
 	trieQueue                            *realWAL.TrieQueue
 	logger                               zerolog.Logger
 	lm                                   *lifecycle.LifecycleManager
@@ -57,6 +61,19 @@ type Compactor struct {
 	stopCh                               chan chan struct{}
 	trieUpdateCh                         <-chan *WALTrieUpdate
 	triggerCheckpointOnNextSegmentFinish *atomic.Bool // to trigger checkpoint manually
+
+	// deltaCheckpoints is the number of delta checkpoints created between two full checkpoints.
+	deltaCheckpoints uint
+	// lastCheckpoint is the last checkpoint created by the Compactor, used as base of the next
+	// delta checkpoint. It is only accessed by the (single) checkpointing goroutine.
+	lastCheckpoint *deltaCheckpointBase
+}
+
+// deltaCheckpointBase is a checkpoint which delta checkpoints can be based on.
+type deltaCheckpointBase struct {
+	num    int
+	tries  []*trie.MTrie
+	deltas uint // number of delta checkpoints in the chain up to this checkpoint
 }
 
 // NewCompactor creates new Compactor which writes WAL record and triggers
@@ -76,6 +93,37 @@ func NewCompactor(
 	checkpointDistance uint,
 	checkpointsToKeep uint,
 	triggerCheckpointOnNextSegmentFinish *atomic.Bool,
+) (*Compactor, error) {
+	return NewCompactorWithDeltaCheckpoints(
+		l,
+		w,
+		logger,
+		checkpointCapacity,
+		checkpointDistance,
+		checkpointsToKeep,
+		0,
+		triggerCheckpointOnNextSegmentFinish,
+	)
+}
+
+// NewCompactorWithDeltaCheckpoints creates new Compactor like NewCompactor, which creates up to
+// deltaCheckpoints delta checkpoints between two full checkpoints.
+// A delta checkpoint only contains the trie nodes created since the previous checkpoint, which is
+// much faster to create and much smaller than a full checkpoint of all tries. However, loading
+// a delta checkpoint requires loading the chain of checkpoints it is based on.
+// The first checkpoint created by the Compactor is always a full checkpoint.
+// With deltaCheckpoints 0, only full checkpoints are created.
+// CAUTION: the tries of the previous checkpoint are kept in memory until the next checkpoint
+// is created, even if they were removed from the checkpointing queue.
+func NewCompactorWithDeltaCheckpoints(
+	l *Ledger,
+	w realWAL.LedgerWAL,
+	logger zerolog.Logger,
+	checkpointCapacity uint,
+	checkpointDistance uint,
+	checkpointsToKeep uint,
+	deltaCheckpoints uint,
+	triggerCheckpointOnNextSegmentFinish *atomic.Bool,
 ) (*Compactor, error) {
 	if checkpointDistance < 1 {
 		checkpointDistance = 1
@@ -105,6 +153,7 @@ func NewCompactor(
 	return &Compactor{
 		checkpointer:                         checkpointer,
 		wal:                                  w,
+		forest:                               l.forest,
 		trieQueue:                            trieQueue,
 		logger:                               logger.With().Str("ledger_mod", "compactor").Logger(),
 		stopCh:                               make(chan chan struct{}),
@@ -114,6 +163,7 @@ func NewCompactor(
 		checkpointDistance:                   checkpointDistance,
 		checkpointsToKeep:                    checkpointsToKeep,
 		triggerCheckpointOnNextSegmentFinish: triggerCheckpointOnNextSegmentFinish,
+		deltaCheckpoints:                     deltaCheckpoints,
 	}, nil
 }
 
@@ -255,10 +305,13 @@ Loop:
 
 				go func() {
 					defer checkpointSem.Release(1)
+					defer c.unpin(checkpointTries)
 					err := c.checkpoint(ctx, checkpointTries, checkpointNum)
 					checkpointResultCh <- checkpointResult{checkpointNum, err}
 				}()
 			} else {
+				c.unpin(checkpointTries)
+
 				// Failed to get semaphore because checkpointing is running.
 				// Try again when active segment is finalized.
 				c.logger.Info().Msgf("compactor delayed checkpoint %d because prior checkpointing is ongoing", nextCheckpointNum)
@@ -288,11 +341,29 @@ Loop:
 // Since this function is only for checkpointing, Compactor isn't affected by returned error.
 func (c *Compactor) checkpoint(ctx context.Context, tries []*trie.MTrie, checkpointNum int) error {
 
-	err := createCheckpoint(c.checkpointer, c.logger, tries, checkpointNum)
+	base := c.lastCheckpoint
+	if base != nil && base.deltas >= c.deltaCheckpoints {
+		base = nil
+	}
+
+	var err error
+	if base == nil {
+		err = createCheckpoint(c.checkpointer, c.logger, tries, checkpointNum)
+	} else {
+		err = createDeltaCheckpoint(c.checkpointer, c.logger, base, tries, checkpointNum)
+	}
 	if err != nil {
 		return &createCheckpointError{num: checkpointNum, err: err}
 	}
 
+	if c.deltaCheckpoints > 0 {
+		created := &deltaCheckpointBase{num: checkpointNum, tries: tries}
+		if base != nil {
+			created.deltas = base.deltas + 1
+		}
+		c.lastCheckpoint = created
+	}
+
 	// Return if context is canceled.
 	select {
 	case <-ctx.Done():
@@ -343,7 +414,35 @@ func createCheckpoint(checkpointer *realWAL.Checkpointer, logger zerolog.Logger,
 	return nil
 }
 
+// createDeltaCheckpoint creates delta checkpoint with given checkpointNum and tries, based on the given checkpoint.
+// Errors indicate that checkpoint file can't be created.
+// Caller should handle returned errors by retrying checkpointing when appropriate.
+func createDeltaCheckpoint(
+	checkpointer *realWAL.Checkpointer,
+	logger zerolog.Logger,
+	base *deltaCheckpointBase,
+	tries []*trie.MTrie,
+	checkpointNum int,
+) error {
+
+	logger.Info().Msgf("serializing delta checkpoint %d with %v tries, based on checkpoint %d", checkpointNum, len(tries), base.num)
+
+	startTime := time.Now()
+
+	fileName := realWAL.NumberToFilename(checkpointNum)
+	err := realWAL.StoreDeltaCheckpoint(checkpointer.Dir(), fileName, &logger, base.num, base.tries, tries)
+	if err != nil {
+		return fmt.Errorf("error serializing delta checkpoint (%d): %w", checkpointNum, err)
+	}
+
+	duration := time.Since(startTime)
+	logger.Info().Float64("total_time_s", duration.Seconds()).Msgf("created delta checkpoint %d", checkpointNum)
+
+	return nil
+}
+
 // cleanupCheckpoints deletes prior checkpoint files if needed.
+// Checkpoints which the kept delta checkpoints are based on are not deleted.
 // Since the function is side-effect free, all failures are simply a no-op.
 func cleanupCheckpoints(checkpointer *realWAL.Checkpointer, checkpointsToKeep int) error {
 	// Don't list checkpoints if we keep them all
@@ -358,7 +457,15 @@ func cleanupCheckpoints(checkpointer *realWAL.Checkpointer, checkpointsToKeep in
 		// if condition guarantees this never fails
 		checkpointsToRemove := checkpoints[:len(checkpoints)-int(checkpointsToKeep)]
 
+		required, err := deltaCheckpointBases(checkpointer, checkpoints[len(checkpoints)-int(checkpointsToKeep):])
+		if err != nil {
+			return err
+		}
+
 		for _, checkpoint := range checkpointsToRemove {
+			if _, ok := required[checkpoint]; ok {
+				continue
+			}
 			err := checkpointer.RemoveCheckpoint(checkpoint)
 			if err != nil {
 				return fmt.Errorf("cannot remove checkpoint %d: %w", checkpoint, err)
@@ -368,6 +475,29 @@ func cleanupCheckpoints(checkpointer *realWAL.Checkpointer, checkpointsToKeep in
 	return nil
 }
 
+// deltaCheckpointBases returns the checkpoints which the given checkpoints are (directly or
+// indirectly) based on.
+func deltaCheckpointBases(checkpointer *realWAL.Checkpointer, checkpoints []int) (map[int]struct{}, error) {
+	bases := make(map[int]struct{})
+	for _, checkpoint := range checkpoints {
+		for {
+			base, isDelta, err := checkpointer.CheckpointBase(checkpoint)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read base of checkpoint %d: %w", checkpoint, err)
+			}
+			if !isDelta {
+				break
+			}
+			if _, ok := bases[base]; ok {
+				break
+			}
+			bases[base] = struct{}{}
+			checkpoint = base
+		}
+	}
+	return bases, nil
+}
+
 // processTrieUpdate writes trie update to WAL, updates activeSegmentNum,
 // and returns tries for checkpointing if needed.
 // It sends WAL update result, receives updated trie, and pushes updated trie to trieQueue.
@@ -389,9 +519,6 @@ func (c *Compactor) processTrieUpdate(
 	// - incremented by 1 from previous segment number (new segment)
 	segmentNum, skipped, updateErr := c.wal.RecordUpdate(update.Update)
 
-	// Send result of WAL update
-	update.ResultCh <- updateErr
-
 	// This ensures that updated trie matches WAL update.
 	defer func() {
 		// Wait for updated trie
@@ -404,6 +531,13 @@ func (c *Compactor) processTrieUpdate(
 		trieQueue.Push(trie)
 	}()
 
+	// Send result of WAL update, which lets the ledger add the updated trie to the forest.
+	// The result is sent after the tries for checkpointing are pinned, as adding the updated
+	// trie can evict the oldest of them from the forest.
+	defer func() {
+		update.ResultCh <- updateErr
+	}()
+
 	if activeSegmentNum == -1 {
 		// Recover from failure to get active segment number at initialization.
 		return segmentNum, -1, nil
@@ -444,12 +578,21 @@ func (c *Compactor) processTrieUpdate(
 	// It doesn't include trie for this update
 	// until updated trie is received and added to trieQueue.
 	tries := trieQueue.Tries()
+	c.forest.Pin(tries)
 
 	checkpointNum = nextCheckpointNum
 
 	return activeSegmentNum, checkpointNum, tries
 }
 
+// unpin unpins the tries which were pinned for checkpointing.
+func (c *Compactor) unpin(tries []*trie.MTrie) {
+	err := c.forest.Unpin(tries)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("compactor failed to release payloads of unpinned tries")
+	}
+}
+
 // createCheckpointError creates a checkpoint creation error.
 type createCheckpointError struct {
 	num int
@@ -523,6 +523,143 @@ func TestCompactorAccuracy(t *testing.T) {
 	})
 }
 
+// TestCompactorDeltaCheckpoints tests that the compactor creates delta checkpoints between full
+// checkpoints, that delta checkpoints match the replayed WAL, and that the checkpoints which the
+// kept delta checkpoints are based on are not removed.
+func TestCompactorDeltaCheckpoints(t *testing.T) {
+
+	const (
+		numInsPerStep      = 2
+		pathByteSize       = 32
+		minPayloadByteSize = 2<<11 - 256 // 3840 bytes
+		maxPayloadByteSize = 2 << 11     // 4096 bytes
+		size               = 20
+		checkpointDistance = 2
+		checkpointsToKeep  = 2
+		deltaCheckpoints   = 3
+		forestCapacity     = 500
+	)
+
+	metricsCollector := &metrics.NoopCollector{}
+
+	unittest.RunWithTempDir(t, func(dir string) {
+
+		wal, err := realWAL.NewDiskWAL(unittest.Logger(), nil, metrics.NewNoopCollector(), dir, forestCapacity, pathByteSize, 32*1024)
+		require.NoError(t, err)
+
+		l, err := NewLedger(wal, forestCapacity, metricsCollector, zerolog.Logger{}, DefaultPathFinderVersion)
+		require.NoError(t, err)
+
+		compactor, err := NewCompactorWithDeltaCheckpoints(l, wal, unittest.Logger(), forestCapacity, checkpointDistance, checkpointsToKeep, deltaCheckpoints, atomic.NewBool(false))
+		require.NoError(t, err)
+
+		co := CompactorObserver{fromBound: size/2 - 1, done: make(chan struct{})}
+		compactor.Subscribe(&co)
+
+		<-compactor.Ready()
+
+		rootHash := trie.EmptyTrieRootHash()
+		for i := 0; i < size+2; i++ {
+			time.Sleep(LedgerUpdateDelay)
+
+			payloads := testutils.RandomPayloads(numInsPerStep, minPayloadByteSize, maxPayloadByteSize)
+
+			keys := make([]ledger.Key, len(payloads))
+			values := make([]ledger.Value, len(payloads))
+			for i, p := range payloads {
+				k, err := p.Key()
+				require.NoError(t, err)
+				keys[i] = k
+				values[i] = p.Value()
+			}
+
+			update, err := ledger.NewUpdate(ledger.State(rootHash), keys, values)
+			require.NoError(t, err)
+
+			newState, _, err := l.Set(update)
+			require.NoError(t, err)
+
+			rootHash = ledger.RootHash(newState)
+		}
+
+		select {
+		case <-co.done:
+		case <-time.After(60 * time.Second):
+			assert.FailNow(t, "timed out")
+		}
+
+		<-l.Done()
+		<-compactor.Done()
+
+		checkpointer, err := wal.NewCheckpointer()
+		require.NoError(t, err)
+
+		nums, err := checkpointer.Checkpoints()
+		require.NoError(t, err)
+
+		// the latest checkpoints are kept along with the checkpoints they are based on
+		expected := make(map[int]struct{})
+		deltas := 0
+		for _, checkpoint := range nums[len(nums)-checkpointsToKeep:] {
+			expected[checkpoint] = struct{}{}
+			for {
+				base, isDelta, err := checkpointer.CheckpointBase(checkpoint)
+				require.NoError(t, err)
+				if !isDelta {
+					break
+				}
+				deltas++
+				expected[base] = struct{}{}
+				checkpoint = base
+			}
+		}
+		require.Len(t, nums, len(expected))
+		require.Greater(t, deltas, 0)
+		require.LessOrEqual(t, len(nums), deltaCheckpoints+checkpointsToKeep)
+
+		for _, n := range nums {
+			testCheckpointedTriesMatchReplayedTriesFromSegments(t, checkpointer, n, dir, true)
+		}
+	})
+}
+
+// TestCleanupDeltaCheckpoints tests that checkpoints which kept delta checkpoints are based on are not removed.
+func TestCleanupDeltaCheckpoints(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		logger := unittest.Logger()
+
+		wal, err := realWAL.NewDiskWAL(logger, nil, metrics.NewNoopCollector(), dir, 10, 32, 32*1024)
+		require.NoError(t, err)
+
+		checkpointer, err := wal.NewCheckpointer()
+		require.NoError(t, err)
+
+		tries := []*trie.MTrie{trie.NewEmptyMTrie()}
+
+		// 1 and 4 are full checkpoints, 2 and 3 are based on 1, and 5 is based on 4
+		require.NoError(t, createCheckpoint(checkpointer, logger, tries, 1))
+		base := &deltaCheckpointBase{num: 1, tries: tries}
+		require.NoError(t, createDeltaCheckpoint(checkpointer, logger, base, tries, 2))
+		base = &deltaCheckpointBase{num: 2, tries: tries}
+		require.NoError(t, createDeltaCheckpoint(checkpointer, logger, base, tries, 3))
+		require.NoError(t, createCheckpoint(checkpointer, logger, tries, 4))
+		base = &deltaCheckpointBase{num: 4, tries: tries}
+		require.NoError(t, createDeltaCheckpoint(checkpointer, logger, base, tries, 5))
+
+		require.NoError(t, cleanupCheckpoints(checkpointer, 3))
+		nums, err := checkpointer.Checkpoints()
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 2, 3, 4, 5}, nums)
+
+		require.NoError(t, cleanupCheckpoints(checkpointer, 2))
+		nums, err = checkpointer.Checkpoints()
+		require.NoError(t, err)
+		require.Equal(t, []int{4, 5}, nums)
+
+		<-wal.Done()
+	})
+}
+
 // TestCompactorTriggeredByAdminTool tests that the compactor will listen to the signal from admin tool
 // to trigger checkpoint when current segment file is finished.
 func TestCompactorTriggeredByAdminTool(t *testing.T) {
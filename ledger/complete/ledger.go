@@ -13,6 +13,7 @@ import (
 	"github.com/onflow/flow-go/ledger/common/hash"
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	realWAL "github.com/onflow/flow-go/ledger/complete/wal"
 	"github.com/onflow/flow-go/module"
@@ -49,10 +50,24 @@ func NewLedger(
 	metrics module.LedgerMetrics,
 	log zerolog.Logger,
 	pathFinderVer uint8) (*Ledger, error) {
+	return NewLedgerWithPayloadStorage(wal, capacity, metrics, log, pathFinderVer, nil)
+}
+
+// NewLedgerWithPayloadStorage creates a new trie-backed ledger storage with persistence, which keeps
+// the payloads of the trie leaves in the given storage rather than in memory.
+// The payloads are kept in memory if the storage is nil.
+func NewLedgerWithPayloadStorage(
+	wal realWAL.LedgerWAL,
+	capacity int,
+	metrics module.LedgerMetrics,
+	log zerolog.Logger,
+	pathFinderVer uint8,
+	payloads node.PayloadStorage,
+) (*Ledger, error) {
 
 	logger := log.With().Str("ledger_mod", "complete").Logger()
 
-	forest, err := mtrie.NewForest(capacity, metrics, nil)
+	forest, err := mtrie.NewForestWithPayloadStorage(capacity, metrics, nil, payloads)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create forest: %w", err)
 	}
@@ -303,6 +318,17 @@ func (l *Ledger) Prove(query *ledger.Query) (proof ledger.Proof, err error) {
 	return proofToGo, err
 }
 
+// Diff returns the registers, whose payloads differ between the two given states.
+// Registers are added, removed or modified when going from the first state to the second state.
+func (l *Ledger) Diff(from ledger.State, to ledger.State) (*ledger.TrieDiff, error) {
+	diff, err := l.forest.Diff(ledger.RootHash(from), ledger.RootHash(to))
+	if err != nil {
+		return nil, fmt.Errorf("could not get diff between states %v and %v: %w", from, to, err)
+	}
+
+	return diff, nil
+}
+
 // MemSize return the amount of memory used by ledger
 // TODO implement an approximate MemSize method
 func (l *Ledger) MemSize() (int64, error) {
@@ -375,7 +401,10 @@ func (l *Ledger) ExportCheckpointAt(
 		// preCheckpointReporters, which doesn't use the payloads.
 	} else {
 		// get all payloads
-		payloads = t.AllPayloads()
+		payloads, err = t.AllPayloads()
+		if err != nil {
+			return ledger.State(hash.DummyHash), fmt.Errorf("cannot get payloads of trie: %w", err)
+		}
 		payloadSize := len(payloads)
 
 		// migrate payloads
@@ -467,7 +496,10 @@ func (l *Ledger) ExportCheckpointAt(
 	if noMigration {
 		// when there is no mgiration, we generate the payloads now before
 		// running the postCheckpointReporters
-		payloads = newTrie.AllPayloads()
+		payloads, err = newTrie.AllPayloads()
+		if err != nil {
+			return ledger.State(hash.DummyHash), fmt.Errorf("cannot get payloads of trie: %w", err)
+		}
 	}
 
 	// running post checkpoint reporters
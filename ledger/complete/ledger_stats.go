@@ -1,6 +1,8 @@
 package complete
 
 import (
+	"fmt"
+
 	"github.com/schollz/progressbar/v3"
 
 	"github.com/onflow/flow-go/ledger"
@@ -29,7 +31,10 @@ func (l *Ledger) CollectStats(payloadCallBack func(payload *ledger.Payload)) (*L
 		for itr := flattener.NewUniqueNodeIterator(trie.RootNode(), visitedNodes); itr.Next(); {
 			n := itr.Value()
 			if n.IsLeaf() {
-				payload := n.Payload()
+				payload, err := n.Payload()
+				if err != nil {
+					return nil, fmt.Errorf("cannot get payload of leaf: %w", err)
+				}
 				leafNodeCounter++
 				payloadCallBack(payload)
 			} else {
@@ -490,6 +490,65 @@ func TestLedger_Proof(t *testing.T) {
 	})
 }
 
+func TestLedger_Diff(t *testing.T) {
+	wal := &fixtures.NoopWAL{}
+	led, err := complete.NewLedger(wal, 100, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion)
+	require.NoError(t, err)
+
+	compactor := fixtures.NewNoopCompactor(led)
+	<-compactor.Ready()
+	defer func() {
+		<-led.Done()
+		<-compactor.Done()
+	}()
+
+	curS := led.InitialState()
+
+	u := testutils.UpdateFixture()
+	u.SetState(curS)
+
+	firstS, _, err := led.Set(u)
+	require.NoError(t, err)
+
+	// modify the first register, and remove the second register
+	keys := u.Keys()
+	u, err = ledger.NewUpdate(firstS, keys, []ledger.Value{[]byte{'x'}, {}})
+	require.NoError(t, err)
+
+	secondS, _, err := led.Set(u)
+	require.NoError(t, err)
+
+	t.Run("added registers", func(t *testing.T) {
+		diff, err := led.Diff(curS, firstS)
+		require.NoError(t, err)
+		assert.Len(t, diff.Added, 2)
+		assert.Empty(t, diff.Removed)
+		assert.Empty(t, diff.Modified)
+	})
+
+	t.Run("modified and removed registers", func(t *testing.T) {
+		diff, err := led.Diff(firstS, secondS)
+		require.NoError(t, err)
+		assert.Empty(t, diff.Added)
+
+		require.Len(t, diff.Removed, 1)
+		removedKey, err := diff.Removed[0].Key()
+		require.NoError(t, err)
+		assert.True(t, removedKey.Equals(&keys[1]))
+
+		require.Len(t, diff.Modified, 1)
+		modifiedKey, err := diff.Modified[0].After.Key()
+		require.NoError(t, err)
+		assert.True(t, modifiedKey.Equals(&keys[0]))
+		assert.Equal(t, ledger.Value([]byte{'x'}), diff.Modified[0].After.Value())
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		_, err := led.Diff(firstS, ledger.State(testutils.RootHashFixture()))
+		require.Error(t, err)
+	})
+}
+
 func Test_WAL(t *testing.T) {
 	const (
 		numInsPerStep      = 2
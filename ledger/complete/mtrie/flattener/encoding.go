@@ -47,9 +47,14 @@ const payloadEncodingVersion = 1
 // WARNING: The returned buffer is likely to share the same underlying array as
 // the scratch buffer. Caller is responsible for copying or using returned buffer
 // before scratch buffer is used again.
-func encodeLeafNode(n *node.Node, scratch []byte) []byte {
+func encodeLeafNode(n *node.Node, scratch []byte) ([]byte, error) {
 
-	encPayloadSize := ledger.EncodedPayloadLengthWithoutPrefix(n.Payload(), payloadEncodingVersion)
+	payload, err := n.Payload()
+	if err != nil {
+		return nil, fmt.Errorf("could not get payload of leaf node: %w", err)
+	}
+
+	encPayloadSize := ledger.EncodedPayloadLengthWithoutPrefix(payload, payloadEncodingVersion)
 
 	encodedNodeSize := encNodeTypeSize +
 		encHeightSize +
@@ -93,9 +98,9 @@ func encodeLeafNode(n *node.Node, scratch []byte) []byte {
 
 	// EncodeAndAppendPayloadWithoutPrefix appends encoded payload to the resliced buf.
 	// Returned buf is resliced to include appended payload.
-	buf = ledger.EncodeAndAppendPayloadWithoutPrefix(buf[:pos], n.Payload(), payloadEncodingVersion)
+	buf = ledger.EncodeAndAppendPayloadWithoutPrefix(buf[:pos], payload, payloadEncodingVersion)
 
-	return buf
+	return buf, nil
 }
 
 // encodeInterimNode encodes interim node in the following format:
@@ -159,11 +164,12 @@ func encodeInterimNode(n *node.Node, lchildIndex uint64, rchildIndex uint64, scr
 // WARNING: The returned buffer is likely to share the same underlying array as
 // the scratch buffer. Caller is responsible for copying or using returned buffer
 // before scratch buffer is used again.
-func EncodeNode(n *node.Node, lchildIndex uint64, rchildIndex uint64, scratch []byte) []byte {
+// No errors are expected during normal operation.
+func EncodeNode(n *node.Node, lchildIndex uint64, rchildIndex uint64, scratch []byte) ([]byte, error) {
 	if n.IsLeaf() {
 		return encodeLeafNode(n, scratch)
 	}
-	return encodeInterimNode(n, lchildIndex, rchildIndex, scratch)
+	return encodeInterimNode(n, lchildIndex, rchildIndex, scratch), nil
 }
 
 // ReadNode reconstructs a node from data read from reader.
@@ -268,6 +274,63 @@ func ReadNode(reader io.Reader, scratch []byte, getNode func(nodeIndex uint64) (
 	return n, nil
 }
 
+// ReadNodeChildIndices reads a serialized node from reader without reconstructing it.
+// It returns true if the node is a leaf, otherwise it returns the indices of the node's children.
+// The path and payload of leaf nodes are skipped, so reading leaf nodes doesn't allocate.
+// Scratch buffer is used to avoid allocs.
+// If len(scratch) < 1024, then a new buffer will be allocated and used.
+func ReadNodeChildIndices(reader io.Reader, scratch []byte) (isLeaf bool, lchildIndex uint64, rchildIndex uint64, err error) {
+
+	const minBufSize = 1024
+
+	if len(scratch) < minBufSize {
+		scratch = make([]byte, minBufSize)
+	}
+
+	// fixLengthSize is the size of shared data of leaf node and interim node
+	const fixLengthSize = encNodeTypeSize + encHeightSize + encHashSize
+
+	_, err = io.ReadFull(reader, scratch[:fixLengthSize])
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("failed to read fixed-length part of serialized node: %w", err)
+	}
+
+	nType := scratch[0]
+
+	switch nType {
+	case byte(leafNodeType):
+		// Read path (32 bytes) and payload size (4 bytes)
+		_, err := io.ReadFull(reader, scratch[:encPathSize+encPayloadLengthSize])
+		if err != nil {
+			return false, 0, 0, fmt.Errorf("failed to read path and payload length of serialized node: %w", err)
+		}
+
+		size := int64(binary.BigEndian.Uint32(scratch[encPathSize:]))
+
+		_, err = io.CopyN(io.Discard, reader, size)
+		if err != nil {
+			return false, 0, 0, fmt.Errorf("failed to skip payload of serialized node: %w", err)
+		}
+
+		return true, 0, 0, nil
+
+	case byte(interimNodeType):
+		// Read left and right child index (16 bytes)
+		_, err := io.ReadFull(reader, scratch[:encNodeIndexSize*2])
+		if err != nil {
+			return false, 0, 0, fmt.Errorf("failed to read child index of serialized node: %w", err)
+		}
+
+		lchildIndex = binary.BigEndian.Uint64(scratch)
+		rchildIndex = binary.BigEndian.Uint64(scratch[encNodeIndexSize:])
+
+		return false, lchildIndex, rchildIndex, nil
+
+	default:
+		return false, 0, 0, fmt.Errorf("failed to decode node type %d", nType)
+	}
+}
+
 // EncodeTrie encodes trie in the following format:
 // - root node index (8 byte)
 // - allocated reg count (8 byte)
@@ -356,6 +419,32 @@ func ReadTrie(reader io.Reader, scratch []byte, getNode func(nodeIndex uint64) (
 	return mtrie, nil
 }
 
+// ReadTrieRootIndex reads a serialized trie from reader without reconstructing it,
+// and returns the trie's root hash and the index of its root node.
+func ReadTrieRootIndex(reader io.Reader, scratch []byte) (ledger.RootHash, uint64, error) {
+
+	if len(scratch) < encodedTrieSize {
+		scratch = make([]byte, encodedTrieSize)
+	}
+
+	_, err := io.ReadFull(reader, scratch[:encodedTrieSize])
+	if err != nil {
+		return ledger.RootHash{}, 0, fmt.Errorf("failed to read serialized trie: %w", err)
+	}
+
+	rootIndex := binary.BigEndian.Uint64(scratch)
+
+	// Skip trie reg count and reg size
+	pos := encNodeIndexSize + encRegCountSize + encRegSizeSize
+
+	rootHash, err := hash.ToHash(scratch[pos : pos+encHashSize])
+	if err != nil {
+		return ledger.RootHash{}, 0, fmt.Errorf("failed to decode hash of serialized trie: %w", err)
+	}
+
+	return ledger.RootHash(rootHash), rootIndex, nil
+}
+
 // readPayloadFromReader reads and decodes payload from reader.
 // Returned payload is a copy.
 func readPayloadFromReader(reader io.Reader, scratch []byte) (*ledger.Payload, error) {
@@ -106,7 +106,8 @@ func TestLeafNodeEncodingDecoding(t *testing.T) {
 			}
 
 			for _, scratch := range scratchBuffers {
-				encodedNode := flattener.EncodeNode(tc.node, 0, 0, scratch)
+				encodedNode, err := flattener.EncodeNode(tc.node, 0, 0, scratch)
+				require.NoError(t, err)
 				assert.Equal(t, tc.encodedNode, encodedNode)
 
 				if len(scratch) > 0 {
@@ -164,7 +165,8 @@ func TestRandomLeafNodeEncodingDecoding(t *testing.T) {
 
 		n := node.NewNode(height, nil, nil, paths[i], payloads[i], hashValue)
 
-		encodedNode := flattener.EncodeNode(n, 0, 0, writeScratch)
+		encodedNode, err := flattener.EncodeNode(n, 0, 0, writeScratch)
+		require.NoError(t, err)
 
 		if len(writeScratch) >= len(encodedNode) {
 			// reuse scratch buffer
@@ -225,7 +227,8 @@ func TestInterimNodeEncodingDecoding(t *testing.T) {
 		}
 
 		for _, scratch := range scratchBuffers {
-			data := flattener.EncodeNode(interimNode, lchildIndex, rchildIndex, scratch)
+			data, err := flattener.EncodeNode(interimNode, lchildIndex, rchildIndex, scratch)
+			require.NoError(t, err)
 			assert.Equal(t, encodedInterimNode, data)
 		}
 	})
@@ -372,3 +375,57 @@ func TestTrieEncodingDecoding(t *testing.T) {
 		})
 	}
 }
+
+func TestReadNodeChildIndices(t *testing.T) {
+	path := testutils.PathByUint8(0)
+	payload := testutils.RandomPayload(1, 5000)
+	leafNode := node.NewNode(255, nil, nil, ledger.Path(path), payload, hash.Hash([32]byte{1, 1, 1}))
+	interimNode := node.NewNode(256, leafNode, nil, ledger.DummyPath, nil, hash.Hash([32]byte{2, 2, 2}))
+
+	var buf bytes.Buffer
+	encodedLeafNode, err := flattener.EncodeNode(leafNode, 0, 0, nil)
+	require.NoError(t, err)
+	buf.Write(encodedLeafNode)
+	encodedInterimNode, err := flattener.EncodeNode(interimNode, 1, 0, nil)
+	require.NoError(t, err)
+	buf.Write(encodedInterimNode)
+
+	reader := bytes.NewReader(buf.Bytes())
+	scratch := make([]byte, 1024)
+
+	// the payload is larger than the scratch buffer, and is skipped
+	isLeaf, lchildIndex, rchildIndex, err := flattener.ReadNodeChildIndices(reader, scratch)
+	require.NoError(t, err)
+	require.True(t, isLeaf)
+	require.Equal(t, uint64(0), lchildIndex)
+	require.Equal(t, uint64(0), rchildIndex)
+
+	isLeaf, lchildIndex, rchildIndex, err = flattener.ReadNodeChildIndices(reader, scratch)
+	require.NoError(t, err)
+	require.False(t, isLeaf)
+	require.Equal(t, uint64(1), lchildIndex)
+	require.Equal(t, uint64(0), rchildIndex)
+
+	require.Equal(t, 0, reader.Len())
+}
+
+func TestReadTrieRootIndex(t *testing.T) {
+	rootNode := node.NewNode(256, nil, nil, ledger.DummyPath, nil, hash.Hash([32]byte{2, 2, 2}))
+
+	mtrie, err := trie.NewMTrie(rootNode, 7, 1234)
+	require.NoError(t, err)
+
+	reader := bytes.NewReader(flattener.EncodeTrie(mtrie, 21, nil))
+	rootHash, rootIndex, err := flattener.ReadTrieRootIndex(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, mtrie.RootHash(), rootHash)
+	require.Equal(t, uint64(21), rootIndex)
+	require.Equal(t, 0, reader.Len())
+}
+
+// payloadSize returns the size of the payload of the given leaf node.
+func payloadSize(t *testing.T, n *node.Node) uint64 {
+	payload, err := n.Payload()
+	require.NoError(t, err)
+	return uint64(payload.Size())
+}
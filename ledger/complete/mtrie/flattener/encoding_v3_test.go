@@ -75,7 +75,7 @@ func TestNodeV3Decoding(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, leafNode1, newNode)
 		require.Equal(t, uint64(1), regCount)
-		require.Equal(t, uint64(leafNode1.Payload().Size()), regSize)
+		require.Equal(t, payloadSize(t, leafNode1), regSize)
 	})
 
 	t.Run("interim node", func(t *testing.T) {
@@ -83,9 +83,9 @@ func TestNodeV3Decoding(t *testing.T) {
 		newNode, regCount, regSize, err := flattener.ReadNodeFromCheckpointV3AndEarlier(reader, func(nodeIndex uint64) (*node.Node, uint64, uint64, error) {
 			switch nodeIndex {
 			case leafNode1Index:
-				return leafNode1, 1, uint64(leafNode1.Payload().Size()), nil
+				return leafNode1, 1, payloadSize(t, leafNode1), nil
 			case leafNode2Index:
-				return leafNode2, 1, uint64(leafNode2.Payload().Size()), nil
+				return leafNode2, 1, payloadSize(t, leafNode2), nil
 			default:
 				return nil, 0, 0, fmt.Errorf("unexpected child node index %d ", nodeIndex)
 			}
@@ -93,7 +93,7 @@ func TestNodeV3Decoding(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, interimNode, newNode)
 		require.Equal(t, uint64(2), regCount)
-		require.Equal(t, uint64(leafNode1.Payload().Size()+leafNode2.Payload().Size()), regSize)
+		require.Equal(t, payloadSize(t, leafNode1)+payloadSize(t, leafNode2), regSize)
 	})
 }
 
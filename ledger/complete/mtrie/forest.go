@@ -2,9 +2,11 @@ package mtrie
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/module"
 )
@@ -28,6 +30,18 @@ type Forest struct {
 	forestCapacity int
 	onTreeEvicted  func(tree *trie.MTrie)
 	metrics        module.LedgerMetrics
+	// payloads stores the payloads of the leaves outside of memory, nil if payloads are kept in memory
+	payloads node.PayloadStorage
+	// evicted holds the tries dropped from the forest, whose payloads are not released yet.
+	// It is appended to while the trie cache is locked, so it is protected by its own lock.
+	evicted     []*trie.MTrie
+	evictedLock sync.Mutex
+	// retained holds the dropped tries, whose payloads are not released as they are pinned
+	retained []*trie.MTrie
+	// pinned holds the number of pins of each pinned trie
+	pinned map[*trie.MTrie]int
+	// releaseLock protects retained and pinned, and serializes the release of payloads
+	releaseLock sync.Mutex
 }
 
 // NewForest returns a new instance of memory forest.
@@ -37,12 +51,49 @@ type Forest struct {
 // Make sure you chose a sufficiently large forestCapacity, such that, when reaching the capacity, the
 // Least Recently Added trie will never be needed again.
 func NewForest(forestCapacity int, metrics module.LedgerMetrics, onTreeEvicted func(tree *trie.MTrie)) (*Forest, error) {
-	forest := &Forest{tries: NewTrieCache(uint(forestCapacity), onTreeEvicted),
+	return NewForestWithPayloadStorage(forestCapacity, metrics, onTreeEvicted, nil)
+}
+
+// NewForestWithPayloadStorage returns a new instance of forest, which keeps the payloads of the
+// leaves in the given storage rather than in memory. The interim nodes, and thereby all hashes,
+// are kept in memory. Payloads are loaded from the storage on access, so reads, updates and proofs
+// yield the same results as for a forest keeping the payloads in memory.
+// The payloads are kept in memory if the storage is nil.
+//
+// When a trie is evicted, the payloads only referenced by its leaves are released from the storage.
+// Hence, an evicted trie can't be read anymore, unless it was pinned before its eviction (see Pin).
+// Only the payloads are moved to the storage. Moving deep subtries to disk, which would also
+// reduce the memory held by the interim nodes, is not implemented: all nodes stay in memory.
+//
+// See NewForest for the CAUTION on forestCapacity.
+func NewForestWithPayloadStorage(
+	forestCapacity int,
+	metrics module.LedgerMetrics,
+	onTreeEvicted func(tree *trie.MTrie),
+	payloads node.PayloadStorage,
+) (*Forest, error) {
+	forest := &Forest{
 		forestCapacity: forestCapacity,
 		onTreeEvicted:  onTreeEvicted,
 		metrics:        metrics,
+		payloads:       payloads,
+		pinned:         make(map[*trie.MTrie]int),
 	}
 
+	evictionCallback := onTreeEvicted
+	if payloads != nil {
+		evictionCallback = func(tree *trie.MTrie) {
+			forest.evictedLock.Lock()
+			forest.evicted = append(forest.evicted, tree)
+			forest.evictedLock.Unlock()
+
+			if onTreeEvicted != nil {
+				onTreeEvicted(tree)
+			}
+		}
+	}
+	forest.tries = NewTrieCache(uint(forestCapacity), evictionCallback)
+
 	// add trie with no allocated registers
 	emptyTrie := trie.NewEmptyMTrie()
 	err := forest.AddTrie(emptyTrie)
@@ -82,7 +133,10 @@ func (f *Forest) ValueSizes(r *ledger.TrieRead) ([]int, error) {
 		pathOrgIndex[path] = append(indices, i)
 	}
 
-	sizes := trie.UnsafeValueSizes(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	sizes, err := trie.UnsafeValueSizes(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	if err != nil {
+		return nil, fmt.Errorf("could not read value sizes: %w", err)
+	}
 
 	// reconstruct value sizes in the same key order that called the method
 	orderedValueSizes := make([]int, len(r.Paths))
@@ -109,7 +163,10 @@ func (f *Forest) ReadSingleValue(r *ledger.TrieReadSingleValue) (ledger.Value, e
 		return nil, err
 	}
 
-	payload := trie.ReadSinglePayload(r.Path)
+	payload, err := trie.ReadSinglePayload(r.Path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read value: %w", err)
+	}
 	return payload.Value().DeepCopy(), nil
 }
 
@@ -129,7 +186,10 @@ func (f *Forest) Read(r *ledger.TrieRead) ([]ledger.Value, error) {
 
 	// call ReadSinglePayload if there is only one path
 	if len(r.Paths) == 1 {
-		payload := trie.ReadSinglePayload(r.Paths[0])
+		payload, err := trie.ReadSinglePayload(r.Paths[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not read value: %w", err)
+		}
 		return []ledger.Value{payload.Value().DeepCopy()}, nil
 	}
 
@@ -149,7 +209,10 @@ func (f *Forest) Read(r *ledger.TrieRead) ([]ledger.Value, error) {
 		pathOrgIndex[path] = append(indices, i)
 	}
 
-	payloads := trie.UnsafeRead(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	payloads, err := trie.UnsafeRead(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	if err != nil {
+		return nil, fmt.Errorf("could not read values: %w", err)
+	}
 
 	// reconstruct the payloads in the same key order that called the method
 	orderedValues := make([]ledger.Value, len(r.Paths))
@@ -236,6 +299,14 @@ func (f *Forest) NewTrie(u *ledger.TrieUpdate) (*trie.MTrie, error) {
 		return nil, fmt.Errorf("constructing updated trie failed: %w", err)
 	}
 
+	// only the payloads of the new leaves need to be stored, as all other nodes are shared with the parent trie
+	if f.payloads != nil {
+		err = newTrie.StorePayloads(parentTrie, f.payloads)
+		if err != nil {
+			return nil, fmt.Errorf("storing payloads of updated trie failed: %w", err)
+		}
+	}
+
 	f.metrics.LatestTrieRegCount(newTrie.AllocatedRegCount())
 	f.metrics.LatestTrieRegCountDiff(int64(newTrie.AllocatedRegCount() - parentTrie.AllocatedRegCount()))
 	f.metrics.LatestTrieRegSize(newTrie.AllocatedRegSize())
@@ -297,10 +368,28 @@ func (f *Forest) Proofs(r *ledger.TrieRead) (*ledger.TrieBatchProof, error) {
 		stateTrie = newTrie
 	}
 
-	bp := stateTrie.UnsafeProofs(r.Paths)
+	bp, err := stateTrie.UnsafeProofs(r.Paths)
+	if err != nil {
+		return nil, fmt.Errorf("could not create proofs: %w", err)
+	}
 	return bp, nil
 }
 
+// Diff returns the registers, whose payloads differ between the tries with the given root hashes.
+func (f *Forest) Diff(fromRootHash, toRootHash ledger.RootHash) (*ledger.TrieDiff, error) {
+	fromTrie, err := f.GetTrie(fromRootHash)
+	if err != nil {
+		return nil, err
+	}
+
+	toTrie, err := f.GetTrie(toRootHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromTrie.Diff(toTrie)
+}
+
 // HasTrie returns true if trie exist at specific rootHash
 func (f *Forest) HasTrie(rootHash ledger.RootHash) bool {
 	_, found := f.tries.Get(rootHash)
@@ -324,6 +413,16 @@ func (f *Forest) GetTries() ([]*trie.MTrie, error) {
 
 // AddTries adds a trie to the forest
 func (f *Forest) AddTries(newTries []*trie.MTrie) error {
+	// tries which were not created by the forest, e.g. tries loaded from a checkpoint, might still
+	// keep their payloads in memory. As such tries share most of their nodes, their payloads are
+	// stored at once, so that every node is visited only once.
+	if f.payloads != nil {
+		err := trie.StoreTriePayloads(newTries, f.payloads)
+		if err != nil {
+			return fmt.Errorf("storing payloads of tries failed: %w", err)
+		}
+	}
+
 	for _, t := range newTries {
 		err := f.AddTrie(t)
 		if err != nil {
@@ -341,13 +440,127 @@ func (f *Forest) AddTrie(newTrie *trie.MTrie) error {
 
 	// TODO: check Thread safety
 	rootHash := newTrie.RootHash()
-	if _, found := f.tries.Get(rootHash); found {
-		// do no op
+	if existing, found := f.tries.Get(rootHash); found {
+		// do no op, except releasing the payloads stored for the duplicate
+		if f.payloads != nil && existing != newTrie && newTrie.PayloadsStored() {
+			return f.drop(newTrie)
+		}
 		return nil
 	}
+
+	// tries which were not created by the forest, e.g. tries loaded from a checkpoint,
+	// might still keep their payloads in memory
+	if f.payloads != nil && !newTrie.PayloadsStored() {
+		err := trie.StoreTriePayloads([]*trie.MTrie{newTrie}, f.payloads)
+		if err != nil {
+			return fmt.Errorf("storing payloads of trie failed: %w", err)
+		}
+	}
+
 	f.tries.Push(newTrie)
 	f.metrics.ForestNumberOfTrees(uint64(f.tries.Count()))
 
+	if f.payloads != nil {
+		err := f.releasePayloads()
+		if err != nil {
+			return fmt.Errorf("releasing payloads of evicted tries failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Pin prevents the payloads of the given tries from being released when the tries are evicted,
+// until they are unpinned. This allows reading the tries after their eviction, e.g. while they
+// are written to a checkpoint. Tries can be pinned multiple times, and are unpinned after being
+// unpinned as often.
+// Pinning is a no-op if the forest keeps the payloads in memory.
+func (f *Forest) Pin(tries []*trie.MTrie) {
+	if f.payloads == nil {
+		return
+	}
+
+	f.releaseLock.Lock()
+	defer f.releaseLock.Unlock()
+
+	for _, t := range tries {
+		f.pinned[t]++
+	}
+}
+
+// Unpin removes a pin from each of the given tries, and releases the payloads of the unpinned
+// tries which were evicted in the meantime.
+// No errors are expected during normal operation.
+func (f *Forest) Unpin(tries []*trie.MTrie) error {
+	if f.payloads == nil {
+		return nil
+	}
+
+	f.releaseLock.Lock()
+	for _, t := range tries {
+		f.pinned[t]--
+		if f.pinned[t] <= 0 {
+			delete(f.pinned, t)
+		}
+	}
+	f.releaseLock.Unlock()
+
+	err := f.releasePayloads()
+	if err != nil {
+		return fmt.Errorf("releasing payloads of unpinned tries failed: %w", err)
+	}
+	return nil
+}
+
+// drop releases the payloads of the given trie, which is not added to the forest.
+// No errors are expected during normal operation.
+func (f *Forest) drop(t *trie.MTrie) error {
+	f.evictedLock.Lock()
+	f.evicted = append(f.evicted, t)
+	f.evictedLock.Unlock()
+
+	err := f.releasePayloads()
+	if err != nil {
+		return fmt.Errorf("releasing payloads of dropped trie failed: %w", err)
+	}
+	return nil
+}
+
+// releasePayloads releases the payloads of the dropped tries, which are neither held by the
+// forest nor pinned. The payloads of a dropped trie are released while the other dropped tries are
+// still considered held, so the payloads shared by several dropped tries are released only once.
+// No errors are expected during normal operation.
+func (f *Forest) releasePayloads() error {
+	f.releaseLock.Lock()
+	defer f.releaseLock.Unlock()
+
+	f.evictedLock.Lock()
+	dropped := append(f.retained, f.evicted...)
+	f.evicted = nil
+	f.evictedLock.Unlock()
+	f.retained = nil
+
+	if len(dropped) == 0 {
+		return nil
+	}
+
+	held := f.tries.Tries()
+	for t := range f.pinned {
+		held = append(held, t)
+	}
+
+	for i, t := range dropped {
+		if _, ok := f.pinned[t]; ok {
+			f.retained = append(f.retained, t)
+			continue
+		}
+
+		remaining := append(held[:len(held):len(held)], dropped[i+1:]...)
+		err := t.ReleasePayloads(remaining, f.payloads)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -373,6 +586,13 @@ func (f *Forest) PurgeCacheExcept(rootHash ledger.RootHash) error {
 	}
 	f.tries.Purge()
 	f.tries.Push(trie)
+
+	if f.payloads != nil {
+		err := f.releasePayloads()
+		if err != nil {
+			return fmt.Errorf("releasing payloads of purged tries failed: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -380,3 +600,8 @@ func (f *Forest) PurgeCacheExcept(rootHash ledger.RootHash) error {
 func (f *Forest) Size() int {
 	return f.tries.Count()
 }
+
+// PayloadStorage returns the storage of the payloads of the leaves, nil if payloads are kept in memory
+func (f *Forest) PayloadStorage() node.PayloadStorage {
+	return f.payloads
+}
@@ -11,8 +11,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
 	prf "github.com/onflow/flow-go/ledger/common/proof"
 	"github.com/onflow/flow-go/ledger/common/testutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadstore"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/ledger/partial/ptrie"
 	"github.com/onflow/flow-go/module/metrics"
@@ -1093,3 +1096,186 @@ func TestPurgeCacheExcept(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, forest.tries.Count())
 }
+
+// TestPayloadStorage tests that a forest storing the payloads on disk yields the same
+// root hashes, values and proofs as a forest keeping the payloads in memory.
+func TestPayloadStorage(t *testing.T) {
+	store, err := payloadstore.Open(t.TempDir(), 10)
+	require.NoError(t, err)
+	defer store.Close()
+
+	// a trie built outside of the forest, e.g. loaded from a checkpoint
+	paths := testutils.RandomPaths(50)
+	payloads := testutils.RandomPayloads(50, 1, 100)
+	deref := make([]ledger.Payload, len(payloads))
+	for i, payload := range payloads {
+		deref[i] = *payload
+	}
+	loadedTrie, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), paths, deref, true)
+	require.NoError(t, err)
+
+	inMemory, err := NewForest(10, &metrics.NoopCollector{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, inMemory.AddTrie(loadedTrie))
+
+	stored, err := NewForestWithPayloadStorage(10, &metrics.NoopCollector{}, nil, store)
+	require.NoError(t, err)
+
+	// copy the trie, as the payloads of the trie added to the stored forest are moved to the storage
+	loadedCopy, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), paths, deref, true)
+	require.NoError(t, err)
+	require.NoError(t, stored.AddTrie(loadedCopy))
+	require.True(t, loadedCopy.PayloadsStored())
+
+	rootHash := loadedTrie.RootHash()
+	require.Equal(t, rootHash, loadedCopy.RootHash())
+
+	// Read and Proofs require unique paths, but registers are written more than once
+	written := make(map[ledger.Path]struct{})
+	for _, path := range paths {
+		written[path] = struct{}{}
+	}
+
+	for i := 0; i < 10; i++ {
+		// update existing and new registers, including removals
+		updatePaths := append(testutils.RandomPaths(10), paths[rand.Intn(len(paths))])
+		updatePayloads := testutils.RandomPayloads(len(updatePaths), 0, 100)
+		update := &ledger.TrieUpdate{RootHash: rootHash, Paths: updatePaths, Payloads: updatePayloads}
+
+		inMemoryRoot, err := inMemory.Update(update)
+		require.NoError(t, err)
+
+		update = &ledger.TrieUpdate{RootHash: rootHash, Paths: updatePaths, Payloads: updatePayloads}
+		storedRoot, err := stored.Update(update)
+		require.NoError(t, err)
+		require.Equal(t, inMemoryRoot, storedRoot)
+
+		for _, path := range updatePaths {
+			written[path] = struct{}{}
+		}
+		rootHash = storedRoot
+	}
+
+	storedTrie, err := stored.GetTrie(rootHash)
+	require.NoError(t, err)
+	require.True(t, storedTrie.PayloadsStored())
+	require.True(t, storedTrie.IsAValidTrie())
+
+	// the payloads of all leaves are stored
+	var leaves []*node.Node
+	var collect func(n *node.Node)
+	collect = func(n *node.Node) {
+		if n == nil {
+			return
+		}
+		if n.IsLeaf() {
+			leaves = append(leaves, n)
+			return
+		}
+		collect(n.LeftChild())
+		collect(n.RightChild())
+	}
+	collect(storedTrie.RootNode())
+	require.NotEmpty(t, leaves)
+	for _, leaf := range leaves {
+		require.True(t, leaf.IsPayloadStored())
+	}
+
+	// include paths which were never written
+	readPaths := testutils.RandomPaths(5)
+	for path := range written {
+		readPaths = append(readPaths, path)
+	}
+
+	inMemoryValues, err := inMemory.Read(&ledger.TrieRead{RootHash: rootHash, Paths: readPaths})
+	require.NoError(t, err)
+	storedValues, err := stored.Read(&ledger.TrieRead{RootHash: rootHash, Paths: readPaths})
+	require.NoError(t, err)
+	require.Equal(t, inMemoryValues, storedValues)
+
+	inMemoryProof, err := inMemory.Proofs(&ledger.TrieRead{RootHash: rootHash, Paths: sortedCopy(readPaths)})
+	require.NoError(t, err)
+	storedProof, err := stored.Proofs(&ledger.TrieRead{RootHash: rootHash, Paths: sortedCopy(readPaths)})
+	require.NoError(t, err)
+	require.Equal(t, inMemoryProof, storedProof)
+	require.True(t, prf.VerifyTrieBatchProof(storedProof, ledger.State(rootHash)))
+}
+
+// TestPayloadStorageEviction tests that the payloads only referenced by evicted tries are
+// removed from the storage, unless the evicted tries are pinned.
+func TestPayloadStorageEviction(t *testing.T) {
+	store, err := payloadstore.Open(t.TempDir(), 10)
+	require.NoError(t, err)
+	defer store.Close()
+
+	// the forest holds the two most recent tries
+	forest, err := NewForestWithPayloadStorage(2, &metrics.NoopCollector{}, nil, store)
+	require.NoError(t, err)
+
+	p1 := pathByUint8s([]uint8{uint8(53), uint8(74)})
+	p2 := pathByUint8s([]uint8{uint8(116), uint8(74)})
+
+	update := func(rootHash ledger.RootHash, paths []ledger.Path, values ...string) *trie.MTrie {
+		payloads := make([]*ledger.Payload, len(values))
+		for i, value := range values {
+			payloads[i] = payloadBySlices([]byte{'A'}, []byte(value))
+		}
+		newRootHash, err := forest.Update(&ledger.TrieUpdate{RootHash: rootHash, Paths: paths, Payloads: payloads})
+		require.NoError(t, err)
+		newTrie, err := forest.GetTrie(newRootHash)
+		require.NoError(t, err)
+		return newTrie
+	}
+
+	trie1 := update(forest.GetEmptyRootHash(), []ledger.Path{p1, p2}, "a", "b")
+	keys1 := storedPayloadKeys(t, trie1)
+
+	// the first trie is evicted while it is pinned
+	forest.Pin([]*trie.MTrie{trie1})
+	trie2 := update(trie1.RootHash(), []ledger.Path{p1}, "c")
+	keys2 := storedPayloadKeys(t, trie2)
+	trie3 := update(trie2.RootHash(), []ledger.Path{p1}, "d")
+	require.False(t, forest.HasTrie(trie1.RootHash()))
+
+	_, err = store.Load(keys1[p1])
+	require.NoError(t, err)
+
+	// the payload only referenced by the first trie is removed once the trie is unpinned,
+	// while the payload shared with the held tries is kept
+	require.NoError(t, forest.Unpin([]*trie.MTrie{trie1}))
+	_, err = store.Load(keys1[p1])
+	require.Error(t, err)
+	_, err = store.Load(keys1[p2])
+	require.NoError(t, err)
+
+	// the payload only referenced by the second trie is removed once it is evicted
+	trie4 := update(trie3.RootHash(), []ledger.Path{p1}, "e")
+	require.False(t, forest.HasTrie(trie2.RootHash()))
+	_, err = store.Load(keys2[p1])
+	require.Error(t, err)
+
+	values, err := forest.Read(&ledger.TrieRead{RootHash: trie4.RootHash(), Paths: []ledger.Path{p1, p2}})
+	require.NoError(t, err)
+	require.Equal(t, []ledger.Value{ledger.Value("e"), ledger.Value("b")}, values)
+}
+
+// storedPayloadKeys returns the keys of the stored payloads of the leaves of the trie, by path
+func storedPayloadKeys(t *testing.T, tr *trie.MTrie) map[ledger.Path]hash.Hash {
+	keys := make(map[ledger.Path]hash.Hash)
+	var collect func(n *node.Node)
+	collect = func(n *node.Node) {
+		if n == nil {
+			return
+		}
+		if n.IsLeaf() {
+			key, ok := n.StoredPayloadKey()
+			require.True(t, ok)
+			keys[*n.Path()] = key
+			return
+		}
+		collect(n.LeftChild())
+		collect(n.RightChild())
+	}
+	collect(tr.RootNode())
+	return keys
+}
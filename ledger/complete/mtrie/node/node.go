@@ -35,10 +35,51 @@ type Node struct {
 	rChild    *Node           // Right Child
 	height    int             // height where the Node is at
 	path      ledger.Path     // the storage path (dummy value for interim nodes)
-	payload   *ledger.Payload // the payload this node is storing (leaf nodes only)
+	payload   *ledger.Payload // the payload this node is storing (leaf nodes only), nil if the payload is stored
+	stored    *storedPayload  // the reference to the stored payload (leaf nodes only), nil if the payload is in memory
 	hashValue hash.Hash       // hash value of node (cached)
 }
 
+// PayloadStorage stores the payloads of leaf nodes outside of memory. Only the
+// reference to the stored payload is kept in memory, the payload is loaded on access.
+// Payloads are never modified, so a payload can be stored under a key derived from its content.
+//
+// As the same payload can be referenced by many leaves, the storage counts the references to
+// each stored payload: Store and Retain add references, Release removes them. A payload is
+// removed from the storage once its last reference is released.
+type PayloadStorage interface {
+	// Store stores the payloads of the leaves with the given paths, and returns
+	// the keys to load the payloads with. A reference is added for each payload.
+	Store(paths []ledger.Path, payloads []*ledger.Payload) ([]hash.Hash, error)
+
+	// Load returns the payload stored under the given key.
+	// All errors are unexpected, as a payload can't be loaded only if the storage is broken.
+	Load(key hash.Hash) (*ledger.Payload, error)
+
+	// Retain adds a reference to each of the payloads stored under the given keys.
+	Retain(keys []hash.Hash) error
+
+	// Release removes a reference from each of the payloads stored under the given keys,
+	// and removes the payloads without references from the storage.
+	Release(keys []hash.Hash) error
+}
+
+// storedPayload references a payload stored in a PayloadStorage
+type storedPayload struct {
+	storage PayloadStorage
+	key     hash.Hash
+}
+
+// load returns the stored payload.
+// All errors are unexpected, as the storage is broken if the payload can't be loaded.
+func (s *storedPayload) load() (*ledger.Payload, error) {
+	payload, err := s.storage.Load(s.key)
+	if err != nil {
+		return nil, fmt.Errorf("could not load stored payload %v: %w", s.key, err)
+	}
+	return payload, nil
+}
+
 // NewNode creates a new Node.
 // UNCHECKED requirement: combination of values must conform to
 // a valid node type (see documentation of `Node` for details)
@@ -75,7 +116,7 @@ func NewLeaf(path ledger.Path,
 		path:    path,
 		payload: payload,
 	}
-	n.hashValue = n.computeHash()
+	n.hashValue = n.computeHash(payload)
 	return n
 }
 
@@ -89,7 +130,7 @@ func NewInterimNode(height int, lchild, rchild *Node) *Node {
 		height:  height,
 		payload: nil,
 	}
-	n.hashValue = n.computeHash()
+	n.hashValue = n.computeHash(nil)
 	return n
 }
 
@@ -122,11 +163,11 @@ func NewInterimCompactifiedNode(height int, lChild, rChild *Node) *Node {
 	// an empty subtrie => in total we have one allocated register, which we represent as single leaf node
 	if rChild == nil && lChild.IsLeaf() {
 		h := hash.HashInterNode(lChild.hashValue, ledger.GetDefaultHashForHeight(lChild.height))
-		return &Node{height: height, path: lChild.path, payload: lChild.payload, hashValue: h}
+		return &Node{height: height, path: lChild.path, payload: lChild.payload, stored: lChild.stored, hashValue: h}
 	}
 	if lChild == nil && rChild.IsLeaf() {
 		h := hash.HashInterNode(ledger.GetDefaultHashForHeight(rChild.height), rChild.hashValue)
-		return &Node{height: height, path: rChild.path, payload: rChild.payload, hashValue: h}
+		return &Node{height: height, path: rChild.path, payload: rChild.payload, stored: rChild.stored, hashValue: h}
 	}
 
 	// CASE (b): both children contain some allocated registers => we can't compactify; return a full interim leaf
@@ -143,13 +184,13 @@ func (n *Node) IsDefaultNode() bool {
 	return n.hashValue == ledger.GetDefaultHashForHeight(n.height)
 }
 
-// computeHash returns the hashValue of the node
-func (n *Node) computeHash() hash.Hash {
+// computeHash returns the hashValue of the node, given the payload of a leaf node
+func (n *Node) computeHash(payload *ledger.Payload) hash.Hash {
 	// check for leaf node
 	if n.lChild == nil && n.rChild == nil {
 		// if payload is non-nil, compute the hash based on the payload content
-		if n.payload != nil {
-			return ledger.ComputeCompactValue(hash.Hash(n.path), n.payload.Value(), n.height)
+		if payload != nil {
+			return ledger.ComputeCompactValue(hash.Hash(n.path), payload.Value(), n.height)
 		}
 		// if payload is nil, return the default hash
 		return ledger.GetDefaultHashForHeight(n.height)
@@ -180,7 +221,13 @@ func verifyCachedHashRecursive(n *Node) bool {
 		return false
 	}
 
-	computedHash := n.computeHash()
+	// a leaf whose stored payload can't be loaded can't be verified
+	payload, err := n.Payload()
+	if err != nil {
+		return false
+	}
+
+	computedHash := n.computeHash(payload)
 	return n.hashValue == computedHash
 }
 
@@ -212,9 +259,79 @@ func (n *Node) Path() *ledger.Path {
 }
 
 // Payload returns the the Node's payload.
+// If the payload is stored in a PayloadStorage, it is loaded from the storage.
 // Do NOT MODIFY returned slices!
-func (n *Node) Payload() *ledger.Payload {
-	return n.payload
+// An error is only returned if the payload is stored and can't be loaded, which is unexpected
+// unless the storage is broken, or all tries referencing the leaf were evicted from the forest.
+func (n *Node) Payload() (*ledger.Payload, error) {
+	if n.stored != nil {
+		return n.stored.load()
+	}
+	return n.payload, nil
+}
+
+// IsPayloadStored returns true if and only if the Node's payload is stored in a PayloadStorage.
+func (n *Node) IsPayloadStored() bool {
+	return n != nil && n.stored != nil
+}
+
+// StoredPayloadKey returns the key of the Node's payload in the PayloadStorage,
+// and false if the payload isn't stored.
+func (n *Node) StoredPayloadKey() (hash.Hash, bool) {
+	if !n.IsPayloadStored() {
+		return hash.DummyHash, false
+	}
+	return n.stored.key, true
+}
+
+// StorePayloads moves the payloads of the given leaves to the given storage, so that
+// only the references to the stored payloads are kept in memory.
+// UNSAFE: as the leaves are modified IN-PLACE, they must not be accessed concurrently.
+// The leaves must not have been published yet, e.g. because they were just created
+// by an update, or loaded from a checkpoint.
+// No errors are expected during normal operation.
+func StorePayloads(leaves []*Node, storage PayloadStorage) error {
+	if len(leaves) == 0 {
+		return nil
+	}
+
+	paths := make([]ledger.Path, len(leaves))
+	payloads := make([]*ledger.Payload, len(leaves))
+	for i, leaf := range leaves {
+		paths[i] = leaf.path
+		payloads[i] = leaf.payload
+	}
+
+	keys, err := storage.Store(paths, payloads)
+	if err != nil {
+		return fmt.Errorf("could not store payloads: %w", err)
+	}
+
+	for i, leaf := range leaves {
+		leaf.stored = &storedPayload{storage: storage, key: keys[i]}
+		leaf.payload = nil
+	}
+	return nil
+}
+
+// RetainPayloads adds a reference to the stored payloads of the given leaves, which were
+// created as copies of other leaves referencing the same stored payloads.
+// No errors are expected during normal operation.
+func RetainPayloads(leaves []*Node, storage PayloadStorage) error {
+	if len(leaves) == 0 {
+		return nil
+	}
+
+	keys := make([]hash.Hash, len(leaves))
+	for i, leaf := range leaves {
+		keys[i] = leaf.stored.key
+	}
+
+	err := storage.Retain(keys)
+	if err != nil {
+		return fmt.Errorf("could not retain payloads: %w", err)
+	}
+	return nil
 }
 
 // LeftChild returns the the Node's left child.
@@ -244,8 +361,11 @@ func (n *Node) FmtStr(prefix string, subpath string) string {
 		left = fmt.Sprintf("\n%v", n.lChild.FmtStr(prefix+"\t", subpath+"0"))
 	}
 	payloadSize := 0
-	if n.payload != nil {
-		payloadSize = n.payload.Size()
+	payload, err := n.Payload()
+	if err != nil {
+		payloadSize = -1 // the stored payload can't be loaded
+	} else if payload != nil {
+		payloadSize = payload.Size()
 	}
 	hashStr := hex.EncodeToString(n.hashValue[:])
 	hashStr = hashStr[:3] + "..." + hashStr[len(hashStr)-3:]
@@ -253,20 +373,27 @@ func (n *Node) FmtStr(prefix string, subpath string) string {
 }
 
 // AllPayloads returns the payload of this node and all payloads of the subtrie
-func (n *Node) AllPayloads() []ledger.Payload {
+// No errors are expected during normal operation.
+func (n *Node) AllPayloads() ([]ledger.Payload, error) {
 	return n.appendSubtreePayloads([]ledger.Payload{})
 }
 
 // appendSubtreePayloads appends the payloads of the subtree with this node as root
 // to the provided Payload slice. Follows same pattern as Go's native append method.
-func (n *Node) appendSubtreePayloads(result []ledger.Payload) []ledger.Payload {
+func (n *Node) appendSubtreePayloads(result []ledger.Payload) ([]ledger.Payload, error) {
 	if n == nil {
-		return result
+		return result, nil
 	}
 	if n.IsLeaf() {
-		return append(result, *n.Payload())
+		payload, err := n.Payload()
+		if err != nil {
+			return nil, err
+		}
+		return append(result, *payload), nil
+	}
+	result, err := n.lChild.appendSubtreePayloads(result)
+	if err != nil {
+		return nil, err
 	}
-	result = n.lChild.appendSubtreePayloads(result)
-	result = n.rChild.appendSubtreePayloads(result)
-	return result
+	return n.rChild.appendSubtreePayloads(result)
 }
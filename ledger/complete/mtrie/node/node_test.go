@@ -100,7 +100,9 @@ func Test_AllPayloads(t *testing.T) {
 	n3 := node.NewLeaf(path, payload, 1)
 	n4 := node.NewInterimNode(1, n1, n2)
 	n5 := node.NewInterimNode(2, n4, n3)
-	require.Equal(t, 3, len(n5.AllPayloads()))
+	allPayloads, err := n5.AllPayloads()
+	require.NoError(t, err)
+	require.Equal(t, 3, len(allPayloads))
 }
 
 func Test_VerifyCachedHash(t *testing.T) {
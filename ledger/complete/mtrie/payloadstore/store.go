@@ -0,0 +1,258 @@
+package payloadstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+)
+
+// DefaultCacheSize is the default number of payloads cached in memory
+const DefaultCacheSize = 100_000
+
+const (
+	codePayload    = byte(1) // prefix of the keys of the encoded payloads
+	codeReferences = byte(2) // prefix of the keys of the reference counts of the payloads
+
+	// maxBatchSize is the maximum size of the payloads written in a single transaction,
+	// well below the transaction size limit of badger.
+	maxBatchSize = 4 << 20
+)
+
+// Store is a node.PayloadStorage keeping the payloads in a badger database on disk.
+// The most recently stored or loaded payloads are cached in memory.
+//
+// Payloads are stored under the hash of their path and encoded payload, so that the same
+// payload is stored only once, even if it is referenced by the leaves of many tries.
+// Next to each payload, the number of leaves referencing it is stored, and the payload is
+// removed once the last reference is released, i.e. once all tries holding a leaf that
+// references it were evicted from the forest.
+//
+// The stored payloads are only referenced by the tries in memory, which are rebuilt from the
+// checkpoint and WAL on startup, so all payloads stored before are dropped when the store is opened.
+type Store struct {
+	db    *badger.DB
+	cache *lru.Cache
+	// mu serializes the updates of the reference counts, which are read and written
+	// in the same transaction, so that concurrent updates don't conflict
+	mu sync.Mutex
+}
+
+var _ node.PayloadStorage = (*Store)(nil)
+
+// Open opens the store in the given directory, caching up to cacheSize payloads in memory.
+// All payloads stored in the directory before are dropped.
+func Open(dir string, cacheSize int) (*Store, error) {
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("could not create payload cache: %w", err)
+	}
+
+	db, err := badger.Open(badger.DefaultOptions(dir).WithLogger(nil))
+	if err != nil {
+		return nil, fmt.Errorf("could not open payload store: %w", err)
+	}
+
+	err = db.DropAll()
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("could not drop previously stored payloads: %w", err)
+	}
+
+	return &Store{
+		db:    db,
+		cache: cache,
+	}, nil
+}
+
+// Store stores the payloads of the leaves with the given paths, and returns the keys to load the payloads with.
+// A reference is added for each payload, also if the same payload was stored before.
+// No errors are expected during normal operation.
+func (s *Store) Store(paths []ledger.Path, payloads []*ledger.Payload) ([]hash.Hash, error) {
+	keys := make([]hash.Hash, len(payloads))
+	encoded := make([][]byte, len(payloads))
+	for i, payload := range payloads {
+		encoded[i] = ledger.EncodeAndAppendPayloadWithoutPrefix(nil, payload, ledger.PayloadVersion)
+		keys[i] = hash.HashLeaf(hash.Hash(paths[i]), encoded[i])
+	}
+
+	_, err := s.updateReferences(keys, encoded, 1)
+	if err != nil {
+		return nil, fmt.Errorf("could not store payloads: %w", err)
+	}
+
+	// recently written payloads are likely read again soon
+	for i, payload := range payloads {
+		s.cache.Add(keys[i], payload)
+	}
+
+	return keys, nil
+}
+
+// Load returns the payload stored under the given key.
+// All errors are unexpected, as only keys returned by Store are loaded while they are referenced.
+func (s *Store) Load(key hash.Hash) (*ledger.Payload, error) {
+	if cached, ok := s.cache.Get(key); ok {
+		return cached.(*ledger.Payload), nil
+	}
+
+	var encoded []byte
+	err := s.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get(payloadKey(key))
+		if err != nil {
+			return err
+		}
+		encoded, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not load payload %v: %w", key, err)
+	}
+
+	// the value is a copy, so the decoded payload can reference it
+	payload, err := ledger.DecodePayloadWithoutPrefix(encoded, true, ledger.PayloadVersion)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode payload %v: %w", key, err)
+	}
+
+	s.cache.Add(key, payload)
+
+	return payload, nil
+}
+
+// Retain adds a reference to each of the payloads stored under the given keys.
+// No errors are expected during normal operation.
+func (s *Store) Retain(keys []hash.Hash) error {
+	_, err := s.updateReferences(keys, nil, 1)
+	if err != nil {
+		return fmt.Errorf("could not retain payloads: %w", err)
+	}
+	return nil
+}
+
+// Release removes a reference from each of the payloads stored under the given keys,
+// and removes the payloads without references.
+// No errors are expected during normal operation.
+func (s *Store) Release(keys []hash.Hash) error {
+	removed, err := s.updateReferences(keys, nil, -1)
+	if err != nil {
+		return fmt.Errorf("could not release payloads: %w", err)
+	}
+
+	for _, key := range removed {
+		s.cache.Remove(key)
+	}
+	return nil
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// updateReferences adds delta to the reference counts of the payloads with the given keys, and
+// returns the keys of the removed payloads. A payload is written if its first reference is added,
+// which requires the encoded payloads to be given, and removed once its last reference is removed.
+// The keys are updated in a sequence of transactions, which are each below the transaction size limit.
+// No errors are expected during normal operation.
+func (s *Store) updateReferences(keys []hash.Hash, encoded [][]byte, delta int64) ([]hash.Hash, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed []hash.Hash
+	for start := 0; start < len(keys); {
+		err := s.db.Update(func(tx *badger.Txn) error {
+			size := 0
+			for ; start < len(keys) && size < maxBatchSize; start++ {
+				key := keys[start]
+
+				count, err := readReferences(tx, key)
+				if err != nil {
+					return err
+				}
+
+				count += delta
+				switch {
+				case count < 0:
+					return fmt.Errorf("payload %v is released more often than it was referenced", key)
+
+				case count == 0:
+					err = tx.Delete(payloadKey(key))
+					if err != nil {
+						return fmt.Errorf("could not remove payload %v: %w", key, err)
+					}
+					err = tx.Delete(referencesKey(key))
+					if err != nil {
+						return fmt.Errorf("could not remove references of payload %v: %w", key, err)
+					}
+					removed = append(removed, key)
+
+				case count == delta: // first reference
+					if encoded == nil {
+						return fmt.Errorf("payload %v is not stored", key)
+					}
+					err = tx.Set(payloadKey(key), encoded[start])
+					if err != nil {
+						return fmt.Errorf("could not write payload %v: %w", key, err)
+					}
+					size += len(encoded[start])
+				}
+
+				if count > 0 {
+					err = tx.Set(referencesKey(key), encodeReferences(count))
+					if err != nil {
+						return fmt.Errorf("could not write references of payload %v: %w", key, err)
+					}
+				}
+				size += 2 * (1 + len(key))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return removed, nil
+}
+
+// readReferences returns the number of references to the payload with the given key, which
+// is 0 if the payload is not stored.
+func readReferences(tx *badger.Txn, key hash.Hash) (int64, error) {
+	item, err := tx.Get(referencesKey(key))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("could not read references of payload %v: %w", key, err)
+	}
+
+	var count int64
+	err = item.Value(func(val []byte) error {
+		count = int64(binary.BigEndian.Uint64(val))
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not decode references of payload %v: %w", key, err)
+	}
+	return count, nil
+}
+
+func encodeReferences(count int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(count))
+}
+
+func payloadKey(key hash.Hash) []byte {
+	return append([]byte{codePayload}, key[:]...)
+}
+
+func referencesKey(key hash.Hash) []byte {
+	return append([]byte{codeReferences}, key[:]...)
+}
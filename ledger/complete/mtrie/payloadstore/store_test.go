@@ -0,0 +1,85 @@
+package payloadstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/testutils"
+)
+
+func TestStoreAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	// the cache is smaller than the number of payloads, so payloads are loaded from disk
+	store, err := Open(dir, 2)
+	require.NoError(t, err)
+
+	paths := testutils.RandomPaths(10)
+	payloads := testutils.RandomPayloads(10, 1, 100)
+
+	keys, err := store.Store(paths, payloads)
+	require.NoError(t, err)
+	require.Len(t, keys, len(payloads))
+
+	for i, key := range keys {
+		payload, err := store.Load(key)
+		require.NoError(t, err)
+		require.True(t, payloads[i].Equals(payload))
+	}
+
+	// the same payload is stored under the same key
+	sameKeys, err := store.Store(paths[:1], []*ledger.Payload{payloads[0].DeepCopy()})
+	require.NoError(t, err)
+	require.Equal(t, keys[0], sameKeys[0])
+
+	// the same payload at a different path is stored under a different key
+	otherKeys, err := store.Store(paths[1:2], payloads[:1])
+	require.NoError(t, err)
+	require.NotEqual(t, keys[0], otherKeys[0])
+
+	require.NoError(t, store.Close())
+
+	// payloads stored before are dropped when the store is opened
+	store, err = Open(dir, 2)
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.Load(keys[0])
+	require.Error(t, err)
+}
+
+func TestReferences(t *testing.T) {
+	store, err := Open(t.TempDir(), 10)
+	require.NoError(t, err)
+	defer store.Close()
+
+	paths := testutils.RandomPaths(2)
+	payloads := testutils.RandomPayloads(2, 1, 100)
+
+	keys, err := store.Store(paths, payloads)
+	require.NoError(t, err)
+
+	// the first payload is referenced three times, the second once
+	_, err = store.Store(paths[:1], payloads[:1])
+	require.NoError(t, err)
+	require.NoError(t, store.Retain(keys[:1]))
+
+	require.NoError(t, store.Release(keys))
+	_, err = store.Load(keys[1])
+	require.Error(t, err)
+
+	require.NoError(t, store.Release(keys[:1]))
+	payload, err := store.Load(keys[0])
+	require.NoError(t, err)
+	require.True(t, payloads[0].Equals(payload))
+
+	require.NoError(t, store.Release(keys[:1]))
+	_, err = store.Load(keys[0])
+	require.Error(t, err)
+
+	// payloads without references can neither be released nor retained
+	require.Error(t, store.Release(keys[:1]))
+	require.Error(t, store.Retain(keys[1:]))
+}
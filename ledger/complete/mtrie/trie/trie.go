@@ -1,6 +1,7 @@
 package trie
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/bitutils"
+	"github.com/onflow/flow-go/ledger/common/hash"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
 )
 
@@ -33,9 +35,10 @@ import (
 //     between v and a tree leaf. The height of a tree is the height of its root.
 //     The height of a Trie is always the height of the fully-expanded tree.
 type MTrie struct {
-	root     *node.Node
-	regCount uint64 // number of registers allocated in the trie
-	regSize  uint64 // size of registers allocated in the trie
+	root           *node.Node
+	regCount       uint64 // number of registers allocated in the trie
+	regSize        uint64 // size of registers allocated in the trie
+	payloadsStored bool   // true if the payloads of all leaves are stored in a node.PayloadStorage
 }
 
 // NewEmptyMTrie returns an empty Mtrie (root is nil)
@@ -62,6 +65,18 @@ func NewMTrie(root *node.Node, regCount uint64, regSize uint64) (*MTrie, error)
 	}, nil
 }
 
+// NewMTrieWithStoredPayloads returns a Mtrie given the root, whose leaves all have their payloads
+// stored in a node.PayloadStorage, e.g. because they were stored while the trie was loaded from a checkpoint.
+// UNCHECKED requirement: the payloads of all leaves are stored
+func NewMTrieWithStoredPayloads(root *node.Node, regCount uint64, regSize uint64) (*MTrie, error) {
+	mt, err := NewMTrie(root, regCount, regSize)
+	if err != nil {
+		return nil, err
+	}
+	mt.payloadsStored = true
+	return mt, nil
+}
+
 // RootHash returns the trie's root hash.
 // Concurrency safe (as Tries are immutable structures by convention)
 func (mt *MTrie) RootHash() ledger.RootHash {
@@ -109,11 +124,16 @@ func (mt *MTrie) String() string {
 //     the size operation completes, the order of `path` and `sizes` are such that
 //     for `path[i]` the corresponding register value size is referenced by `sizes[i]`.
 //
+// No errors are expected during normal operation.
+//
 // TODO move consistency checks from Forest into Trie to obtain a safe, self-contained API
-func (mt *MTrie) UnsafeValueSizes(paths []ledger.Path) []int {
+func (mt *MTrie) UnsafeValueSizes(paths []ledger.Path) ([]int, error) {
 	sizes := make([]int, len(paths)) // pre-allocate slice for the result
-	valueSizes(sizes, paths, mt.root)
-	return sizes
+	err := valueSizes(sizes, paths, mt.root)
+	if err != nil {
+		return nil, err
+	}
+	return sizes, nil
 }
 
 // valueSizes returns value sizes of all the registers in `paths“ in subtree with `head` as root node.
@@ -121,22 +141,25 @@ func (mt *MTrie) UnsafeValueSizes(paths []ledger.Path) []int {
 // CAUTION:
 //   - while reading the payloads, `paths` is permuted IN-PLACE for optimized processing.
 //   - unchecked requirement: all paths must go through the `head` node
-func valueSizes(sizes []int, paths []ledger.Path, head *node.Node) {
+func valueSizes(sizes []int, paths []ledger.Path, head *node.Node) error {
 	// check for empty paths
 	if len(paths) == 0 {
-		return
+		return nil
 	}
 
 	// path not found
 	if head == nil {
-		return
+		return nil
 	}
 
 	// reached a leaf node
 	if head.IsLeaf() {
 		for i, p := range paths {
 			if *head.Path() == p {
-				payload := head.Payload()
+				payload, err := head.Payload()
+				if err != nil {
+					return err
+				}
 				if payload != nil {
 					sizes[i] = payload.Value().Size()
 				}
@@ -144,7 +167,7 @@ func valueSizes(sizes []int, paths []ledger.Path, head *node.Node) {
 				// doesn't require paths being deduplicated.
 			}
 		}
-		return
+		return nil
 	}
 
 	// reached an interim node with only one path
@@ -166,8 +189,7 @@ func valueSizes(sizes []int, paths []ledger.Path, head *node.Node) {
 			}
 		}
 
-		valueSizes(sizes, paths, head)
-		return
+		return valueSizes(sizes, paths, head)
 	}
 
 	// reached an interim node with more than one paths
@@ -183,32 +205,41 @@ func valueSizes(sizes []int, paths []ledger.Path, head *node.Node) {
 	// read values from left and right subtrees in parallel
 	parallelRecursionThreshold := 32 // threshold to avoid the parallelization going too deep in the recursion
 	if len(lpaths) < parallelRecursionThreshold || len(rpaths) < parallelRecursionThreshold {
-		valueSizes(lsizes, lpaths, head.LeftChild())
-		valueSizes(rsizes, rpaths, head.RightChild())
-	} else {
-		// concurrent read of left and right subtree
-		wg := sync.WaitGroup{}
-		wg.Add(1)
-		go func() {
-			valueSizes(lsizes, lpaths, head.LeftChild())
-			wg.Done()
-		}()
-		valueSizes(rsizes, rpaths, head.RightChild())
-		wg.Wait() // wait for all threads
+		err := valueSizes(lsizes, lpaths, head.LeftChild())
+		if err != nil {
+			return err
+		}
+		return valueSizes(rsizes, rpaths, head.RightChild())
+	}
+
+	// concurrent read of left and right subtree
+	var lErr error
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		lErr = valueSizes(lsizes, lpaths, head.LeftChild())
+		wg.Done()
+	}()
+	rErr := valueSizes(rsizes, rpaths, head.RightChild())
+	wg.Wait() // wait for all threads
+	if lErr != nil {
+		return lErr
 	}
+	return rErr
 }
 
 // ReadSinglePayload reads and returns a payload for a single path.
-func (mt *MTrie) ReadSinglePayload(path ledger.Path) *ledger.Payload {
+// No errors are expected during normal operation.
+func (mt *MTrie) ReadSinglePayload(path ledger.Path) (*ledger.Payload, error) {
 	return readSinglePayload(path, mt.root)
 }
 
 // readSinglePayload reads and returns a payload for a single path in subtree with `head` as root node.
-func readSinglePayload(path ledger.Path, head *node.Node) *ledger.Payload {
+func readSinglePayload(path ledger.Path, head *node.Node) (*ledger.Payload, error) {
 	pathBytes := path[:]
 
 	if head == nil {
-		return ledger.EmptyPayload()
+		return ledger.EmptyPayload(), nil
 	}
 
 	depth := ledger.NodeMaxHeight - head.Height() // distance to the tree root
@@ -228,7 +259,7 @@ func readSinglePayload(path ledger.Path, head *node.Node) *ledger.Payload {
 		return head.Payload()
 	}
 
-	return ledger.EmptyPayload()
+	return ledger.EmptyPayload(), nil
 }
 
 // UnsafeRead reads payloads for the given paths.
@@ -240,11 +271,16 @@ func readSinglePayload(path ledger.Path, head *node.Node) *ledger.Payload {
 //     the read operation completes, the order of `path` and `payloads` are such that
 //     for `path[i]` the corresponding register value is referenced by 0`payloads[i]`.
 //
+// No errors are expected during normal operation.
+//
 // TODO move consistency checks from Forest into Trie to obtain a safe, self-contained API
-func (mt *MTrie) UnsafeRead(paths []ledger.Path) []*ledger.Payload {
+func (mt *MTrie) UnsafeRead(paths []ledger.Path) ([]*ledger.Payload, error) {
 	payloads := make([]*ledger.Payload, len(paths)) // pre-allocate slice for the result
-	read(payloads, paths, mt.root)
-	return payloads
+	err := read(payloads, paths, mt.root)
+	if err != nil {
+		return nil, err
+	}
+	return payloads, nil
 }
 
 // read reads all the registers in subtree with `head` as root node. For each
@@ -252,10 +288,10 @@ func (mt *MTrie) UnsafeRead(paths []ledger.Path) []*ledger.Payload {
 // CAUTION:
 //   - while reading the payloads, `paths` is permuted IN-PLACE for optimized processing.
 //   - unchecked requirement: all paths must go through the `head` node
-func read(payloads []*ledger.Payload, paths []ledger.Path, head *node.Node) {
+func read(payloads []*ledger.Payload, paths []ledger.Path, head *node.Node) error {
 	// check for empty paths
 	if len(paths) == 0 {
-		return
+		return nil
 	}
 
 	// path not found
@@ -263,26 +299,34 @@ func read(payloads []*ledger.Payload, paths []ledger.Path, head *node.Node) {
 		for i := range paths {
 			payloads[i] = ledger.EmptyPayload()
 		}
-		return
+		return nil
 	}
 
 	// reached a leaf node
 	if head.IsLeaf() {
 		for i, p := range paths {
 			if *head.Path() == p {
-				payloads[i] = head.Payload()
+				payload, err := head.Payload()
+				if err != nil {
+					return err
+				}
+				payloads[i] = payload
 			} else {
 				payloads[i] = ledger.EmptyPayload()
 			}
 		}
-		return
+		return nil
 	}
 
 	// reached an interim node
 	if len(paths) == 1 {
 		// call readSinglePayload to skip partition and recursive calls when there is only one path
-		payloads[0] = readSinglePayload(paths[0], head)
-		return
+		payload, err := readSinglePayload(paths[0], head)
+		if err != nil {
+			return err
+		}
+		payloads[0] = payload
+		return nil
 	}
 
 	// partition step to quick sort the paths:
@@ -296,19 +340,27 @@ func read(payloads []*ledger.Payload, paths []ledger.Path, head *node.Node) {
 	// read values from left and right subtrees in parallel
 	parallelRecursionThreshold := 32 // threshold to avoid the parallelization going too deep in the recursion
 	if len(lpaths) < parallelRecursionThreshold || len(rpaths) < parallelRecursionThreshold {
-		read(lpayloads, lpaths, head.LeftChild())
-		read(rpayloads, rpaths, head.RightChild())
-	} else {
-		// concurrent read of left and right subtree
-		wg := sync.WaitGroup{}
-		wg.Add(1)
-		go func() {
-			read(lpayloads, lpaths, head.LeftChild())
-			wg.Done()
-		}()
-		read(rpayloads, rpaths, head.RightChild())
-		wg.Wait() // wait for all threads
+		err := read(lpayloads, lpaths, head.LeftChild())
+		if err != nil {
+			return err
+		}
+		return read(rpayloads, rpaths, head.RightChild())
 	}
+
+	// concurrent read of left and right subtree
+	var lErr error
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		lErr = read(lpayloads, lpaths, head.LeftChild())
+		wg.Done()
+	}()
+	rErr := read(rpayloads, rpaths, head.RightChild())
+	wg.Wait() // wait for all threads
+	if lErr != nil {
+		return lErr
+	}
+	return rErr
 }
 
 // NewTrieWithUpdatedRegisters constructs a new trie containing all registers from the parent trie,
@@ -335,7 +387,7 @@ func NewTrieWithUpdatedRegisters(
 	updatedPayloads []ledger.Payload,
 	prune bool,
 ) (*MTrie, uint16, error) {
-	updatedRoot, regCountDelta, regSizeDelta, lowestHeightTouched := update(
+	updatedRoot, regCountDelta, regSizeDelta, lowestHeightTouched, err := update(
 		ledger.NodeMaxHeight,
 		parentTrie.root,
 		updatedPaths,
@@ -343,6 +395,9 @@ func NewTrieWithUpdatedRegisters(
 		nil,
 		prune,
 	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("updating registers failed: %w", err)
+	}
 
 	updatedTrieRegCount := int64(parentTrie.AllocatedRegCount()) + regCountDelta
 	updatedTrieRegSize := int64(parentTrie.AllocatedRegSize()) + regSizeDelta
@@ -362,6 +417,7 @@ type updateResult struct {
 	allocatedRegCountDelta int64
 	allocatedRegSizeDelta  int64
 	lowestHeightTouched    int
+	err                    error
 }
 
 // update traverses the subtree, updates the stored registers, and returns:
@@ -369,6 +425,7 @@ type updateResult struct {
 //   - allocated register count delta in subtrie (allocatedRegCountDelta)
 //   - allocated register size delta in subtrie (allocatedRegSizeDelta)
 //   - lowest height reached during recursive update in subtrie (lowestHeightTouched)
+//   - error, if the stored payload of a leaf on the updated paths can't be loaded
 //
 // allocatedRegCountDelta and allocatedRegSizeDelta are used to compute updated
 // trie's allocated register count and size.  lowestHeightTouched is used to
@@ -382,26 +439,30 @@ func update(
 	nodeHeight int, parentNode *node.Node,
 	paths []ledger.Path, payloads []ledger.Payload, compactLeaf *node.Node,
 	prune bool,
-) (n *node.Node, allocatedRegCountDelta int64, allocatedRegSizeDelta int64, lowestHeightTouched int) {
+) (n *node.Node, allocatedRegCountDelta int64, allocatedRegSizeDelta int64, lowestHeightTouched int, err error) {
 	// No new paths to write
 	if len(paths) == 0 {
 		// check is a compactLeaf from a higher height is still left.
 		if compactLeaf != nil {
+			payload, err := compactLeaf.Payload()
+			if err != nil {
+				return nil, 0, 0, 0, err
+			}
 			// create a new node for the compact leaf path and payload. The old node shouldn't
 			// be recycled as it is still used by the tree copy before the update.
-			n = node.NewLeaf(*compactLeaf.Path(), compactLeaf.Payload(), nodeHeight)
-			return n, 0, 0, nodeHeight
+			n = node.NewLeaf(*compactLeaf.Path(), payload, nodeHeight)
+			return n, 0, 0, nodeHeight, nil
 		}
-		return parentNode, 0, 0, nodeHeight
+		return parentNode, 0, 0, nodeHeight, nil
 	}
 
 	if len(paths) == 1 && parentNode == nil && compactLeaf == nil {
 		n = node.NewLeaf(paths[0], payloads[0].DeepCopy(), nodeHeight)
 		if payloads[0].IsEmpty() {
 			// Unallocated register doesn't affect allocatedRegCountDelta and allocatedRegSizeDelta.
-			return n, 0, 0, nodeHeight
+			return n, 0, 0, nodeHeight, nil
 		}
-		return n, 1, int64(payloads[0].Size()), nodeHeight
+		return n, 1, int64(payloads[0].Size()), nodeHeight, nil
 	}
 
 	if parentNode != nil && parentNode.IsLeaf() { // if we're here then compactLeaf == nil
@@ -410,24 +471,29 @@ func update(
 		parentPath := *parentNode.Path()
 		for i, p := range paths {
 			if p == parentPath {
+				parentPayload, err := parentNode.Payload()
+				if err != nil {
+					return nil, 0, 0, 0, err
+				}
+
 				// the case where the recursion stops: only one path to update
 				if len(paths) == 1 {
-					if !parentNode.Payload().ValueEquals(&payloads[i]) {
+					if !parentPayload.ValueEquals(&payloads[i]) {
 						n = node.NewLeaf(paths[i], payloads[i].DeepCopy(), nodeHeight)
 
 						allocatedRegCountDelta, allocatedRegSizeDelta =
-							computeAllocatedRegDeltas(parentNode.Payload(), &payloads[i])
+							computeAllocatedRegDeltas(parentPayload, &payloads[i])
 
-						return n, allocatedRegCountDelta, allocatedRegSizeDelta, nodeHeight
+						return n, allocatedRegCountDelta, allocatedRegSizeDelta, nodeHeight, nil
 					}
 					// avoid creating a new node when the same payload is written
-					return parentNode, 0, 0, nodeHeight
+					return parentNode, 0, 0, nodeHeight, nil
 				}
 				// the case where the recursion carries on: len(paths)>1
 				found = true
 
 				allocatedRegCountDelta, allocatedRegSizeDelta =
-					computeAllocatedRegDeltasFromHigherHeight(parentNode.Payload())
+					computeAllocatedRegDeltasFromHigherHeight(parentPayload)
 
 				break
 			}
@@ -476,11 +542,15 @@ func update(
 	var lRegCountDelta, rRegCountDelta int64
 	var lRegSizeDelta, rRegSizeDelta int64
 	var lLowestHeightTouched, rLowestHeightTouched int
+	var lErr, rErr error
 	parallelRecursionThreshold := 16
 	if len(lpaths) < parallelRecursionThreshold || len(rpaths) < parallelRecursionThreshold {
 		// runtime optimization: if there are _no_ updates for either left or right sub-tree, proceed single-threaded
-		lChild, lRegCountDelta, lRegSizeDelta, lLowestHeightTouched = update(nodeHeight-1, lchildParent, lpaths, lpayloads, lcompactLeaf, prune)
-		rChild, rRegCountDelta, rRegSizeDelta, rLowestHeightTouched = update(nodeHeight-1, rchildParent, rpaths, rpayloads, rcompactLeaf, prune)
+		lChild, lRegCountDelta, lRegSizeDelta, lLowestHeightTouched, lErr = update(nodeHeight-1, lchildParent, lpaths, lpayloads, lcompactLeaf, prune)
+		if lErr != nil {
+			return nil, 0, 0, 0, lErr
+		}
+		rChild, rRegCountDelta, rRegSizeDelta, rLowestHeightTouched, rErr = update(nodeHeight-1, rchildParent, rpaths, rpayloads, rcompactLeaf, prune)
 	} else {
 		// runtime optimization: process the left child is a separate thread
 
@@ -491,15 +561,21 @@ func update(
 		// channel is faster and uses fewer allocs/op in this case.
 		results := make(chan updateResult, 1)
 		go func(retChan chan<- updateResult) {
-			child, regCountDelta, regSizeDelta, lowestHeightTouched := update(nodeHeight-1, lchildParent, lpaths, lpayloads, lcompactLeaf, prune)
-			retChan <- updateResult{child, regCountDelta, regSizeDelta, lowestHeightTouched}
+			child, regCountDelta, regSizeDelta, lowestHeightTouched, err := update(nodeHeight-1, lchildParent, lpaths, lpayloads, lcompactLeaf, prune)
+			retChan <- updateResult{child, regCountDelta, regSizeDelta, lowestHeightTouched, err}
 		}(results)
 
-		rChild, rRegCountDelta, rRegSizeDelta, rLowestHeightTouched = update(nodeHeight-1, rchildParent, rpaths, rpayloads, rcompactLeaf, prune)
+		rChild, rRegCountDelta, rRegSizeDelta, rLowestHeightTouched, rErr = update(nodeHeight-1, rchildParent, rpaths, rpayloads, rcompactLeaf, prune)
 
 		// Wait for results from goroutine.
 		ret := <-results
-		lChild, lRegCountDelta, lRegSizeDelta, lLowestHeightTouched = ret.child, ret.allocatedRegCountDelta, ret.allocatedRegSizeDelta, ret.lowestHeightTouched
+		lChild, lRegCountDelta, lRegSizeDelta, lLowestHeightTouched, lErr = ret.child, ret.allocatedRegCountDelta, ret.allocatedRegSizeDelta, ret.lowestHeightTouched, ret.err
+		if lErr != nil {
+			return nil, 0, 0, 0, lErr
+		}
+	}
+	if rErr != nil {
+		return nil, 0, 0, 0, rErr
 	}
 
 	allocatedRegCountDelta += lRegCountDelta + rRegCountDelta
@@ -511,18 +587,18 @@ func update(
 	// unchanged. This is only sufficient for interim nodes (for leaf nodes, the children
 	// might be unchanged, i.e. both nil, but the payload could have changed).
 	if !parentNode.IsLeaf() && lChild == lchildParent && rChild == rchildParent {
-		return parentNode, 0, 0, lowestHeightTouched
+		return parentNode, 0, 0, lowestHeightTouched, nil
 	}
 
 	// In case the parent node was a leaf, we _cannot reuse_ it, because we potentially
 	// updated registers in the sub-trie
 	if prune {
 		n = node.NewInterimCompactifiedNode(nodeHeight, lChild, rChild)
-		return n, allocatedRegCountDelta, allocatedRegSizeDelta, lowestHeightTouched
+		return n, allocatedRegCountDelta, allocatedRegSizeDelta, lowestHeightTouched, nil
 	}
 
 	n = node.NewInterimNode(nodeHeight, lChild, rChild)
-	return n, allocatedRegCountDelta, allocatedRegSizeDelta, lowestHeightTouched
+	return n, allocatedRegCountDelta, allocatedRegSizeDelta, lowestHeightTouched, nil
 }
 
 // computeAllocatedRegDeltasFromHigherHeight returns the deltas
@@ -565,10 +641,14 @@ func computeAllocatedRegDeltas(oldPayload, newPayload *ledger.Payload) (allocate
 // UNSAFE: requires _all_ paths to have a length of mt.Height bits.
 // Paths in the input query don't have to be deduplicated, though deduplication would
 // result in allocating less dynamic memory to store the proofs.
-func (mt *MTrie) UnsafeProofs(paths []ledger.Path) *ledger.TrieBatchProof {
+// No errors are expected during normal operation.
+func (mt *MTrie) UnsafeProofs(paths []ledger.Path) (*ledger.TrieBatchProof, error) {
 	batchProofs := ledger.NewTrieBatchProofWithEmptyProofs(len(paths))
-	prove(mt.root, paths, batchProofs.Proofs)
-	return batchProofs
+	err := prove(mt.root, paths, batchProofs.Proofs)
+	if err != nil {
+		return nil, err
+	}
+	return batchProofs, nil
 }
 
 // prove traverses the subtree and stores proofs for the given register paths in
@@ -577,17 +657,17 @@ func (mt *MTrie) UnsafeProofs(paths []ledger.Path) *ledger.TrieBatchProof {
 // UNSAFE: method requires the following conditions to be satisfied:
 //   - paths all share the same common prefix [0 : mt.maxHeight-1 - nodeHeight)
 //     (excluding the bit at index headHeight)
-func prove(head *node.Node, paths []ledger.Path, proofs []*ledger.TrieProof) {
+func prove(head *node.Node, paths []ledger.Path, proofs []*ledger.TrieProof) error {
 	// check for empty paths
 	if len(paths) == 0 {
-		return
+		return nil
 	}
 
 	// we've reached the end of a trie
 	// and path is not found (noninclusion proof)
 	if head == nil {
 		// by default, proofs are non-inclusion proofs
-		return
+		return nil
 	}
 
 	// we've reached a leaf
@@ -595,13 +675,17 @@ func prove(head *node.Node, paths []ledger.Path, proofs []*ledger.TrieProof) {
 		for i, path := range paths {
 			// value matches (inclusion proof)
 			if *head.Path() == path {
+				payload, err := head.Payload()
+				if err != nil {
+					return err
+				}
 				proofs[i].Path = *head.Path()
-				proofs[i].Payload = head.Payload()
+				proofs[i].Payload = payload
 				proofs[i].Inclusion = true
 			}
 		}
 		// by default, proofs are non-inclusion proofs
-		return
+		return nil
 	}
 
 	// increment steps for all the proofs
@@ -621,23 +705,31 @@ func prove(head *node.Node, paths []ledger.Path, proofs []*ledger.TrieProof) {
 	if len(lpaths) < parallelRecursionThreshold || len(rpaths) < parallelRecursionThreshold {
 		// runtime optimization: below the parallelRecursionThreshold, we proceed single-threaded
 		addSiblingTrieHashToProofs(head.RightChild(), depth, lproofs)
-		prove(head.LeftChild(), lpaths, lproofs)
+		err := prove(head.LeftChild(), lpaths, lproofs)
+		if err != nil {
+			return err
+		}
 
 		addSiblingTrieHashToProofs(head.LeftChild(), depth, rproofs)
-		prove(head.RightChild(), rpaths, rproofs)
-	} else {
-		wg := sync.WaitGroup{}
-		wg.Add(1)
-		go func() {
-			addSiblingTrieHashToProofs(head.RightChild(), depth, lproofs)
-			prove(head.LeftChild(), lpaths, lproofs)
-			wg.Done()
-		}()
+		return prove(head.RightChild(), rpaths, rproofs)
+	}
 
-		addSiblingTrieHashToProofs(head.LeftChild(), depth, rproofs)
-		prove(head.RightChild(), rpaths, rproofs)
-		wg.Wait()
+	var lErr error
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		addSiblingTrieHashToProofs(head.RightChild(), depth, lproofs)
+		lErr = prove(head.LeftChild(), lpaths, lproofs)
+		wg.Done()
+	}()
+
+	addSiblingTrieHashToProofs(head.LeftChild(), depth, rproofs)
+	rErr := prove(head.RightChild(), rpaths, rproofs)
+	wg.Wait()
+	if lErr != nil {
+		return lErr
 	}
+	return rErr
 }
 
 // addSiblingTrieHashToProofs inspects the sibling Trie and adds its root hash
@@ -670,6 +762,110 @@ func addSiblingTrieHashToProofs(siblingTrie *node.Node, depth int, proofs []*led
 	}
 }
 
+// appendLeaves appends the paths and payloads of all the leaves with non-empty payloads
+// in the subtrie to the given slices, in ascending path order.
+func appendLeaves(paths []ledger.Path, payloads []*ledger.Payload, head *node.Node) ([]ledger.Path, []*ledger.Payload, error) {
+	if head == nil {
+		return paths, payloads, nil
+	}
+
+	if head.IsLeaf() {
+		payload, err := head.Payload()
+		if err != nil {
+			return nil, nil, err
+		}
+		if payload.IsEmpty() {
+			return paths, payloads, nil
+		}
+		return append(paths, *head.Path()), append(payloads, payload), nil
+	}
+
+	paths, payloads, err := appendLeaves(paths, payloads, head.LeftChild())
+	if err != nil {
+		return nil, nil, err
+	}
+	return appendLeaves(paths, payloads, head.RightChild())
+}
+
+// Diff returns the registers, whose payloads differ between this trie and the other trie.
+// Both tries are traversed in parallel, skipping all subtries with the same root hash, as
+// they hold the same registers. Hence, only the nodes on the paths to the differing
+// registers are visited. The registers are returned in ascending path order.
+// No errors are expected during normal operation.
+func (mt *MTrie) Diff(other *MTrie) (*ledger.TrieDiff, error) {
+	diff := ledger.NewTrieDiff()
+	err := diffNodes(mt.root, other.root, diff)
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// diffNodes adds the registers, whose payloads differ between the two subtries, to the diff.
+// UNSAFE: both subtries are expected to be at the same position in their tries.
+func diffNodes(before, after *node.Node, diff *ledger.TrieDiff) error {
+	// subtries with the same hash hold the same registers. As a nil subtrie is empty,
+	// its hash is the default hash at the height of the other subtrie.
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		if after.Hash() == ledger.GetDefaultHashForHeight(after.Height()) {
+			return nil
+		}
+	case after == nil:
+		if before.Hash() == ledger.GetDefaultHashForHeight(before.Height()) {
+			return nil
+		}
+	default:
+		if before.Hash() == after.Hash() {
+			return nil
+		}
+	}
+
+	// both subtries are interim nodes, so their children are compared
+	if before != nil && after != nil && !before.IsLeaf() && !after.IsLeaf() {
+		err := diffNodes(before.LeftChild(), after.LeftChild(), diff)
+		if err != nil {
+			return err
+		}
+		return diffNodes(before.RightChild(), after.RightChild(), diff)
+	}
+
+	// at least one of the subtries is empty or a compactified leaf, so the registers of both subtries are
+	// compared by path. As they are in ascending path order, they can be merged in a single pass.
+	beforePaths, beforePayloads, err := appendLeaves(nil, nil, before)
+	if err != nil {
+		return err
+	}
+	afterPaths, afterPayloads, err := appendLeaves(nil, nil, after)
+	if err != nil {
+		return err
+	}
+
+	i, j := 0, 0
+	for i < len(beforePaths) || j < len(afterPaths) {
+		switch {
+		case j == len(afterPaths) || (i < len(beforePaths) && bytes.Compare(beforePaths[i][:], afterPaths[j][:]) < 0):
+			diff.Removed = append(diff.Removed, beforePayloads[i])
+			i++
+		case i == len(beforePaths) || bytes.Compare(beforePaths[i][:], afterPaths[j][:]) > 0:
+			diff.Added = append(diff.Added, afterPayloads[j])
+			j++
+		default:
+			if !beforePayloads[i].Equals(afterPayloads[j]) {
+				diff.Modified = append(diff.Modified, &ledger.PayloadModification{
+					Before: beforePayloads[i],
+					After:  afterPayloads[j],
+				})
+			}
+			i++
+			j++
+		}
+	}
+	return nil
+}
+
 // Equals compares two tries for equality.
 // Tries are equal iff they store the same data (i.e. root hash matches)
 // and their number and height are identical
@@ -698,7 +894,11 @@ func (mt *MTrie) DumpAsJSON(w io.Writer) error {
 func dumpAsJSON(n *node.Node, encoder *json.Encoder) error {
 	if n.IsLeaf() {
 		if n != nil {
-			err := encoder.Encode(n.Payload())
+			payload, err := n.Payload()
+			if err != nil {
+				return err
+			}
+			err = encoder.Encode(payload)
 			if err != nil {
 				return err
 			}
@@ -727,8 +927,186 @@ func EmptyTrieRootHash() ledger.RootHash {
 	return ledger.RootHash(ledger.GetDefaultHashForHeight(ledger.NodeMaxHeight))
 }
 
+// StorePayloads moves the in-memory payloads of the leaves, which the trie created when it was
+// updated from the given parent trie, to the given storage, so that only the references to the
+// stored payloads are kept in memory.
+// Subtries shared with the parent trie are skipped, as their payloads were already stored when the
+// parent trie was added to the forest. The leaves which the update copied from stored leaves of the
+// parent trie (e.g. compactified leaves moved up) reference the stored payloads as well, so a
+// reference is added to their payloads.
+// UNSAFE: the trie must not be accessed concurrently, i.e. it must not have been published yet.
+// No errors are expected during normal operation.
+func (mt *MTrie) StorePayloads(parent *MTrie, storage node.PayloadStorage) error {
+	unstored, copied := newLeaves(nil, nil, mt.root, parent.root)
+
+	err := node.StorePayloads(unstored, storage)
+	if err != nil {
+		return err
+	}
+
+	err = node.RetainPayloads(copied, storage)
+	if err != nil {
+		return err
+	}
+
+	mt.payloadsStored = true
+	return nil
+}
+
+// StoreTriePayloads moves the in-memory payloads of the leaves of the given tries to the given storage,
+// for tries which were not created by an update of a trie in the forest, e.g. tries loaded from a
+// checkpoint without storing their payloads on the fly.
+// Each node is visited once, even if it is shared by many of the tries, and the leaves already
+// referencing a stored payload are skipped, as their references were added when they were stored.
+// UNSAFE: the tries must not be accessed concurrently, i.e. they must not have been published yet.
+// No errors are expected during normal operation.
+func StoreTriePayloads(tries []*MTrie, storage node.PayloadStorage) error {
+	visited := make(map[*node.Node]struct{})
+
+	var leaves []*node.Node
+	for _, mt := range tries {
+		if !mt.payloadsStored {
+			leaves = unstoredLeaves(leaves, mt.root, visited)
+		}
+	}
+
+	err := node.StorePayloads(leaves, storage)
+	if err != nil {
+		return err
+	}
+
+	for _, mt := range tries {
+		mt.payloadsStored = true
+	}
+	return nil
+}
+
+// ReleasePayloads releases the stored payloads of the leaves which are only held by this trie,
+// when the trie is dropped while the given tries are still held.
+// A node is only held by this trie if none of the other tries holds it at the same position, as
+// tries share subtries at their position (copy-on-write) and the update never moves a node to
+// another position. The traversal stops at the nodes held by the other tries, so only the nodes
+// on the paths to the nodes held only by this trie are visited.
+// No errors are expected during normal operation.
+func (mt *MTrie) ReleasePayloads(held []*MTrie, storage node.PayloadStorage) error {
+	others := make(map[*node.Node]struct{}, len(held))
+	for _, other := range held {
+		if other.root != nil {
+			others[other.root] = struct{}{}
+		}
+	}
+
+	keys := droppedPayloadKeys(nil, mt.root, others)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	err := storage.Release(keys)
+	if err != nil {
+		return fmt.Errorf("could not release payloads: %w", err)
+	}
+	return nil
+}
+
+// PayloadsStored returns true if the payloads of all leaves of the trie are stored in a node.PayloadStorage.
+func (mt *MTrie) PayloadsStored() bool {
+	return mt.payloadsStored
+}
+
+// newLeaves appends the leaves of the subtrie with the given root, which are not shared with the
+// parent subtrie at the same position, to the given slices: leaves with in-memory payloads to unstored,
+// leaves copied from stored leaves to copied. The traversal stops at the nodes shared with the parent
+// subtrie, as copy-on-write keeps unchanged subtries at their position.
+func newLeaves(unstored, copied []*node.Node, n *node.Node, parent *node.Node) ([]*node.Node, []*node.Node) {
+	if n == nil || n == parent {
+		return unstored, copied
+	}
+	if n.IsLeaf() {
+		if n.IsPayloadStored() {
+			copied = append(copied, n)
+		} else if hasPayload(n) {
+			unstored = append(unstored, n)
+		}
+		return unstored, copied
+	}
+
+	var lParent, rParent *node.Node
+	if parent != nil {
+		lParent, rParent = parent.LeftChild(), parent.RightChild()
+	}
+	unstored, copied = newLeaves(unstored, copied, n.LeftChild(), lParent)
+	return newLeaves(unstored, copied, n.RightChild(), rParent)
+}
+
+// unstoredLeaves appends the leaves of the subtrie with the given root, whose payloads are in memory,
+// to the given slice. The nodes already visited are skipped, and the visited nodes are added.
+func unstoredLeaves(leaves []*node.Node, n *node.Node, visited map[*node.Node]struct{}) []*node.Node {
+	if n == nil {
+		return leaves
+	}
+	if _, ok := visited[n]; ok {
+		return leaves
+	}
+	visited[n] = struct{}{}
+
+	if n.IsLeaf() {
+		if !n.IsPayloadStored() && hasPayload(n) {
+			leaves = append(leaves, n)
+		}
+		return leaves
+	}
+
+	leaves = unstoredLeaves(leaves, n.LeftChild(), visited)
+	return unstoredLeaves(leaves, n.RightChild(), visited)
+}
+
+// hasPayload returns true if the leaf holds an in-memory payload.
+func hasPayload(leaf *node.Node) bool {
+	// the payload is in memory, so it is returned without error
+	payload, _ := leaf.Payload()
+	return payload != nil
+}
+
+// droppedPayloadKeys appends the keys of the stored payloads of the leaves in the subtrie with the
+// given root, which are not held by any of the other subtries at the same position, to the given slice.
+func droppedPayloadKeys(keys []hash.Hash, n *node.Node, others map[*node.Node]struct{}) []hash.Hash {
+	if n == nil {
+		return keys
+	}
+	if _, ok := others[n]; ok {
+		return keys
+	}
+
+	if n.IsLeaf() {
+		if key, ok := n.StoredPayloadKey(); ok {
+			keys = append(keys, key)
+		}
+		return keys
+	}
+
+	// the children of the other subtries at the position of the children. As most of the
+	// other subtries share their children, there are far fewer children than other subtries.
+	lOthers := make(map[*node.Node]struct{})
+	rOthers := make(map[*node.Node]struct{})
+	for other := range others {
+		if other.IsLeaf() {
+			continue
+		}
+		if lChild := other.LeftChild(); lChild != nil {
+			lOthers[lChild] = struct{}{}
+		}
+		if rChild := other.RightChild(); rChild != nil {
+			rOthers[rChild] = struct{}{}
+		}
+	}
+
+	keys = droppedPayloadKeys(keys, n.LeftChild(), lOthers)
+	return droppedPayloadKeys(keys, n.RightChild(), rOthers)
+}
+
 // AllPayloads returns all payloads
-func (mt *MTrie) AllPayloads() []ledger.Payload {
+// No errors are expected during normal operation.
+func (mt *MTrie) AllPayloads() ([]ledger.Payload, error) {
 	return mt.root.AllPayloads()
 }
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
+	"fmt"
 	"math"
 	"math/rand"
 	"sort"
@@ -708,13 +709,15 @@ func Test_Pruning(t *testing.T) {
 				queryPaths = append(queryPaths, path)
 			}
 
-			payloads := activeTrie.UnsafeRead(queryPaths)
+			payloads, err := activeTrie.UnsafeRead(queryPaths)
+			require.NoError(t, err)
 			for i, pp := range payloads {
 				expectedPayload := allPaths[queryPaths[i]]
 				require.True(t, pp.Equals(&expectedPayload))
 			}
 
-			payloads = activeTrieWithPruning.UnsafeRead(queryPaths)
+			payloads, err = activeTrieWithPruning.UnsafeRead(queryPaths)
+			require.NoError(t, err)
 			for i, pp := range payloads {
 				expectedPayload := allPaths[queryPaths[i]]
 				require.True(t, pp.Equals(&expectedPayload))
@@ -737,7 +740,8 @@ func TestValueSizes(t *testing.T) {
 	t.Run("empty trie", func(t *testing.T) {
 		path := testutils.PathByUint16LeftPadded(0)
 		pathsToGetValueSize := []ledger.Path{path}
-		sizes := emptyTrie.UnsafeValueSizes(pathsToGetValueSize)
+		sizes, err := emptyTrie.UnsafeValueSizes(pathsToGetValueSize)
+		require.NoError(t, err)
 		require.Equal(t, len(pathsToGetValueSize), len(sizes))
 		require.Equal(t, 0, sizes[0])
 	})
@@ -759,7 +763,8 @@ func TestValueSizes(t *testing.T) {
 
 		pathsToGetValueSize := []ledger.Path{path1, path2}
 
-		sizes := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		sizes, err := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		require.NoError(t, err)
 		require.Equal(t, len(pathsToGetValueSize), len(sizes))
 		require.Equal(t, payload1.Value().Size(), sizes[0])
 		require.Equal(t, 0, sizes[1])
@@ -801,7 +806,8 @@ func TestValueSizes(t *testing.T) {
 		}
 
 		// Test value sizes for a mix of existent and non-existent paths.
-		sizes := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		sizes, err := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		require.NoError(t, err)
 		require.Equal(t, len(pathsToGetValueSize), len(sizes))
 		for i, p := range pathsToGetValueSize {
 			switch p {
@@ -817,13 +823,15 @@ func TestValueSizes(t *testing.T) {
 
 		// Test value size for a single existent path
 		pathsToGetValueSize = []ledger.Path{path1}
-		sizes = newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		sizes, err = newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		require.NoError(t, err)
 		require.Equal(t, len(pathsToGetValueSize), len(sizes))
 		require.Equal(t, payload1.Value().Size(), sizes[0])
 
 		// Test value size for a single non-existent path
 		pathsToGetValueSize = []ledger.Path{testutils.PathByUint16(3 << 12)}
-		sizes = newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		sizes, err = newTrie.UnsafeValueSizes(pathsToGetValueSize)
+		require.NoError(t, err)
 		require.Equal(t, len(pathsToGetValueSize), len(sizes))
 		require.Equal(t, 0, sizes[0])
 	})
@@ -852,7 +860,8 @@ func TestValueSizesWithDuplicatePaths(t *testing.T) {
 		path1, path2, path3,
 	}
 
-	sizes := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+	sizes, err := newTrie.UnsafeValueSizes(pathsToGetValueSize)
+	require.NoError(t, err)
 	require.Equal(t, len(pathsToGetValueSize), len(sizes))
 	for i, p := range pathsToGetValueSize {
 		switch p {
@@ -1116,7 +1125,8 @@ func TestReadSinglePayload(t *testing.T) {
 		savedRootHash := emptyTrie.RootHash()
 
 		path := testutils.PathByUint16LeftPadded(0)
-		payload := emptyTrie.ReadSinglePayload(path)
+		payload, err := emptyTrie.ReadSinglePayload(path)
+		require.NoError(t, err)
 		require.True(t, payload.IsEmpty())
 		require.Equal(t, savedRootHash, emptyTrie.RootHash())
 	})
@@ -1137,13 +1147,15 @@ func TestReadSinglePayload(t *testing.T) {
 		savedRootHash := newTrie.RootHash()
 
 		// Get payload for existent path path
-		retPayload := newTrie.ReadSinglePayload(path1)
+		retPayload, err := newTrie.ReadSinglePayload(path1)
+		require.NoError(t, err)
 		require.Equal(t, payload1, retPayload)
 		require.Equal(t, savedRootHash, newTrie.RootHash())
 
 		// Get payload for non-existent path
 		path2 := testutils.PathByUint16LeftPadded(1)
-		retPayload = newTrie.ReadSinglePayload(path2)
+		retPayload, err = newTrie.ReadSinglePayload(path2)
+		require.NoError(t, err)
 		require.True(t, retPayload.IsEmpty())
 		require.Equal(t, savedRootHash, newTrie.RootHash())
 	})
@@ -1185,7 +1197,8 @@ func TestReadSinglePayload(t *testing.T) {
 		for i := 0; i < 16; i++ {
 			path := testutils.PathByUint16(uint16(i << 12))
 
-			retPayload := newTrie.ReadSinglePayload(path)
+			retPayload, err := newTrie.ReadSinglePayload(path)
+			require.NoError(t, err)
 			require.Equal(t, savedRootHash, newTrie.RootHash())
 			switch path {
 			case path1:
@@ -1198,3 +1211,123 @@ func TestReadSinglePayload(t *testing.T) {
 		}
 	})
 }
+
+// TestDiff tests that the diff of two tries includes all added, removed and modified registers.
+func TestDiff(t *testing.T) {
+
+	emptyTrie := trie.NewEmptyMTrie()
+
+	// update returns a new trie with the given registers updated. The given slices are not modified.
+	update := func(t *testing.T, parent *trie.MTrie, paths []ledger.Path, payloads []*ledger.Payload, prune bool) *trie.MTrie {
+		updatePaths := make([]ledger.Path, len(paths))
+		copy(updatePaths, paths)
+		updatePayloads := make([]ledger.Payload, len(payloads))
+		for i, payload := range payloads {
+			updatePayloads[i] = *payload
+		}
+
+		newTrie, _, err := trie.NewTrieWithUpdatedRegisters(parent, updatePaths, updatePayloads, prune)
+		require.NoError(t, err)
+		return newTrie
+	}
+
+	requirePayloads := func(t *testing.T, expected []*ledger.Payload, actual []*ledger.Payload) {
+		require.Equal(t, len(expected), len(actual))
+		for _, payload := range expected {
+			found := false
+			for _, other := range actual {
+				if payload.Equals(other) {
+					found = true
+					break
+				}
+			}
+			require.True(t, found, "payload %v not found", payload)
+		}
+	}
+
+	paths := testutils.RandomPaths(1000)
+	payloads := testutils.RandomPayloads(len(paths), 1, 100)
+
+	for _, prune := range []bool{true, false} {
+		baseTrie := update(t, emptyTrie, paths, payloads, prune)
+
+		t.Run(fmt.Sprintf("same trie (prune %v)", prune), func(t *testing.T) {
+			diff, err := baseTrie.Diff(baseTrie)
+			require.NoError(t, err)
+			require.True(t, diff.IsEmpty())
+
+			diff, err = emptyTrie.Diff(emptyTrie)
+			require.NoError(t, err)
+			require.True(t, diff.IsEmpty())
+		})
+
+		t.Run(fmt.Sprintf("empty trie (prune %v)", prune), func(t *testing.T) {
+			diff, err := emptyTrie.Diff(baseTrie)
+			require.NoError(t, err)
+			requirePayloads(t, payloads, diff.Added)
+			require.Empty(t, diff.Removed)
+			require.Empty(t, diff.Modified)
+
+			diff, err = baseTrie.Diff(emptyTrie)
+			require.NoError(t, err)
+			require.Empty(t, diff.Added)
+			requirePayloads(t, payloads, diff.Removed)
+			require.Empty(t, diff.Modified)
+		})
+
+		t.Run(fmt.Sprintf("updated trie (prune %v)", prune), func(t *testing.T) {
+			addedPaths := testutils.RandomPaths(50)
+			addedPayloads := testutils.RandomPayloads(len(addedPaths), 1, 100)
+
+			removedPaths := paths[:50]
+			removedPayloads := payloads[:50]
+
+			modifiedPaths := paths[50:100]
+			modifiedPayloads := make([]*ledger.Payload, len(modifiedPaths))
+			for i, payload := range payloads[50:100] {
+				key, err := payload.Key()
+				require.NoError(t, err)
+				modifiedPayloads[i] = ledger.NewPayload(key, testutils.RandomValues(1, 1, 100)[0])
+			}
+
+			// the same value is written to unmodified registers
+			unmodifiedPaths := paths[100:150]
+			unmodifiedPayloads := payloads[100:150]
+
+			updatePaths := append([]ledger.Path{}, addedPaths...)
+			updatePaths = append(updatePaths, removedPaths...)
+			updatePaths = append(updatePaths, modifiedPaths...)
+			updatePaths = append(updatePaths, unmodifiedPaths...)
+
+			updatePayloads := append([]*ledger.Payload{}, addedPayloads...)
+			for range removedPaths {
+				updatePayloads = append(updatePayloads, ledger.EmptyPayload())
+			}
+			updatePayloads = append(updatePayloads, modifiedPayloads...)
+			updatePayloads = append(updatePayloads, unmodifiedPayloads...)
+
+			updatedTrie := update(t, baseTrie, updatePaths, updatePayloads, prune)
+
+			diff, err := baseTrie.Diff(updatedTrie)
+			require.NoError(t, err)
+			requirePayloads(t, addedPayloads, diff.Added)
+			requirePayloads(t, removedPayloads, diff.Removed)
+
+			before := make([]*ledger.Payload, len(diff.Modified))
+			after := make([]*ledger.Payload, len(diff.Modified))
+			for i, modification := range diff.Modified {
+				before[i] = modification.Before
+				after[i] = modification.After
+			}
+			requirePayloads(t, payloads[50:100], before)
+			requirePayloads(t, modifiedPayloads, after)
+
+			// the reverse diff swaps added and removed registers
+			reverse, err := updatedTrie.Diff(baseTrie)
+			require.NoError(t, err)
+			requirePayloads(t, removedPayloads, reverse.Added)
+			requirePayloads(t, addedPayloads, reverse.Removed)
+			require.Len(t, reverse.Modified, len(modifiedPayloads))
+		})
+	}
+}
@@ -0,0 +1,498 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/bitutils"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+)
+
+const MagicBytesCheckpointDelta uint16 = 0x2138
+
+// VersionDeltaV1 is the first version of delta checkpoint files, see StoreDeltaCheckpoint for the format.
+const VersionDeltaV1 uint16 = 0x01
+
+const (
+	encBaseCheckpointSize = 8
+	encDeltaEntryTypeSize = 1
+	encTrieIndexSize      = 2
+	encNodeDepthSize      = 2
+	deltaHeaderSize       = headerSize + encBaseCheckpointSize + encTrieCountSize
+)
+
+// types of the entries of a delta checkpoint
+const (
+	deltaEntryBaseNode byte = 0 // reference to a node of the base checkpoint
+	deltaEntryNode     byte = 1 // node created since the base checkpoint
+)
+
+// deltaBaseCandidate is a node of the base tries, which a node at the same position in the
+// delta tries might be identical to.
+type deltaBaseCandidate struct {
+	trieIndex uint16
+	node      *node.Node
+}
+
+// StoreDeltaCheckpoint writes the given tries to a delta checkpoint file, which only contains
+// the nodes created since the base checkpoint. Nodes of the tries, which are also nodes of the
+// base tries, are stored as references to the node in the base checkpoint.
+// The baseTries must be the tries of the base checkpoint, in the same order as stored in it.
+//
+// Delta checkpoint file consists of:
+//   - header: magic (2 bytes) + version (2 bytes) + base checkpoint number (8 bytes) + base trie count (2 bytes)
+//   - a list of entries, each starting with the entry type (1 byte), which is either:
+//     -- a reference to a node of the base checkpoint: index of the base trie (2 bytes) + depth
+//     of the node (2 bytes) + path to the node (32 bytes) + node hash (32 bytes)
+//     -- an encoded node, see EncodeNode(), where references to other nodes are by entry index
+//   - a list of encoded tries, each referencing their respective root node by entry index
+//   - footer: entry count (8 bytes) + trie count (2 bytes)
+//   - CRC32 file checksum (4 bytes)
+//
+// As with full checkpoints, referencing to other entries by index 0 means nil, and entries are
+// listed in Descendents-First-Relationship order.
+//
+// Loading a delta checkpoint requires its base checkpoint to be in the same directory,
+// which might itself be a delta checkpoint.
+func StoreDeltaCheckpoint(
+	dir string,
+	fileName string,
+	logger *zerolog.Logger,
+	baseCheckpoint int,
+	baseTries []*trie.MTrie,
+	tries []*trie.MTrie,
+) (
+	errToReturn error,
+) {
+	if len(baseTries) > int(^uint16(0)) {
+		return fmt.Errorf("too many base tries: %d", len(baseTries))
+	}
+	if len(tries) > int(^uint16(0)) {
+		return fmt.Errorf("too many tries: %d", len(tries))
+	}
+
+	writer, err := CreateCheckpointWriterForFile(dir, fileName, logger)
+	if err != nil {
+		return fmt.Errorf("could not create writer: %w", err)
+	}
+	defer func() {
+		errToReturn = closeAndMergeError(writer, errToReturn)
+	}()
+
+	crc32Writer := NewCRC32Writer(writer)
+
+	// 4096 bytes are large enough for almost all payloads and 100% of interim nodes,
+	// a larger buffer is allocated otherwise.
+	scratch := make([]byte, 1024*4)
+
+	header := scratch[:deltaHeaderSize]
+	binary.BigEndian.PutUint16(header, MagicBytesCheckpointDelta)
+	binary.BigEndian.PutUint16(header[encMagicSize:], VersionDeltaV1)
+	binary.BigEndian.PutUint64(header[headerSize:], uint64(baseCheckpoint))
+	binary.BigEndian.PutUint16(header[headerSize+encBaseCheckpointSize:], uint16(len(baseTries)))
+
+	_, err = crc32Writer.Write(header)
+	if err != nil {
+		return fmt.Errorf("cannot write delta checkpoint header: %w", err)
+	}
+
+	w := &deltaWriter{
+		writer:  crc32Writer,
+		scratch: scratch,
+		indices: make(map[*node.Node]uint64),
+		counter: 1,
+	}
+
+	rootIndices := make([]uint64, len(tries))
+	for i, t := range tries {
+		candidates := make([]deltaBaseCandidate, 0, len(baseTries))
+		for baseIndex, baseTrie := range baseTries {
+			candidates = appendCandidate(candidates, uint16(baseIndex), baseTrie.RootNode())
+		}
+
+		rootIndices[i], err = w.store(t.RootNode(), 0, ledger.Path{}, candidates)
+		if err != nil {
+			return fmt.Errorf("cannot store nodes of trie %d: %w", i, err)
+		}
+	}
+
+	for i, t := range tries {
+		encTrie := flattener.EncodeTrie(t, rootIndices[i], w.scratch)
+		_, err = crc32Writer.Write(encTrie)
+		if err != nil {
+			return fmt.Errorf("cannot write delta checkpoint trie: %w", err)
+		}
+	}
+
+	footer := scratch[:encNodeCountSize+encTrieCountSize]
+	binary.BigEndian.PutUint64(footer, w.counter-1)
+	binary.BigEndian.PutUint16(footer[encNodeCountSize:], uint16(len(tries)))
+
+	_, err = crc32Writer.Write(footer)
+	if err != nil {
+		return fmt.Errorf("cannot write delta checkpoint footer: %w", err)
+	}
+
+	// Write CRC32 sum
+	crc32buf := scratch[:crc32SumSize]
+	binary.BigEndian.PutUint32(crc32buf, crc32Writer.Crc32())
+
+	_, err = writer.Write(crc32buf)
+	if err != nil {
+		return fmt.Errorf("cannot write CRC32: %w", err)
+	}
+
+	logger.Info().
+		Int("base_checkpoint", baseCheckpoint).
+		Uint64("new_nodes", w.newNodes).
+		Uint64("base_nodes", w.baseNodes).
+		Msgf("stored %d tries in delta checkpoint %s", len(tries), fileName)
+
+	return nil
+}
+
+// deltaWriter writes the entries of a delta checkpoint.
+type deltaWriter struct {
+	writer    io.Writer
+	scratch   []byte
+	indices   map[*node.Node]uint64 // entry index of the nodes written so far
+	counter   uint64                // index of the next entry, starting at 1 as 0 marks nil
+	newNodes  uint64
+	baseNodes uint64
+}
+
+// store writes the entries of the subtrie with root n at the given depth and path, descendents first,
+// and returns the entry index of n. The candidates are the distinct base nodes at the same position.
+func (w *deltaWriter) store(n *node.Node, depth int, path ledger.Path, candidates []deltaBaseCandidate) (uint64, error) {
+	if n == nil {
+		return 0, nil
+	}
+	if index, ok := w.indices[n]; ok {
+		return index, nil
+	}
+
+	for _, candidate := range candidates {
+		if candidate.node != n {
+			continue
+		}
+
+		entry := w.scratch[:encDeltaEntryTypeSize+encTrieIndexSize+encNodeDepthSize+ledger.PathLen+hash.HashLen]
+		entry[0] = deltaEntryBaseNode
+		pos := encDeltaEntryTypeSize
+		binary.BigEndian.PutUint16(entry[pos:], candidate.trieIndex)
+		pos += encTrieIndexSize
+		binary.BigEndian.PutUint16(entry[pos:], uint16(depth))
+		pos += encNodeDepthSize
+		copy(entry[pos:], path[:])
+		pos += ledger.PathLen
+		h := n.Hash()
+		copy(entry[pos:], h[:])
+
+		_, err := w.writer.Write(entry)
+		if err != nil {
+			return 0, fmt.Errorf("cannot write base node reference: %w", err)
+		}
+
+		w.baseNodes++
+		return w.add(n), nil
+	}
+
+	var lIndex, rIndex uint64
+	if !n.IsLeaf() {
+		var lCandidates, rCandidates []deltaBaseCandidate
+		for _, candidate := range candidates {
+			lCandidates = appendCandidate(lCandidates, candidate.trieIndex, candidate.node.LeftChild())
+			rCandidates = appendCandidate(rCandidates, candidate.trieIndex, candidate.node.RightChild())
+		}
+
+		var err error
+		lIndex, err = w.store(n.LeftChild(), depth+1, path, lCandidates)
+		if err != nil {
+			return 0, err
+		}
+
+		rPath := path
+		bitutils.SetBit(rPath[:], depth)
+		rIndex, err = w.store(n.RightChild(), depth+1, rPath, rCandidates)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	_, err := w.writer.Write([]byte{deltaEntryNode})
+	if err != nil {
+		return 0, fmt.Errorf("cannot write entry type: %w", err)
+	}
+
+	encNode, err := flattener.EncodeNode(n, lIndex, rIndex, w.scratch)
+	if err != nil {
+		return 0, fmt.Errorf("cannot encode node: %w", err)
+	}
+	_, err = w.writer.Write(encNode)
+	if err != nil {
+		return 0, fmt.Errorf("cannot write node: %w", err)
+	}
+
+	w.newNodes++
+	return w.add(n), nil
+}
+
+func (w *deltaWriter) add(n *node.Node) uint64 {
+	index := w.counter
+	w.indices[n] = index
+	w.counter++
+	return index
+}
+
+// appendCandidate appends the given base node to the candidates, unless it is nil or already a candidate.
+// Base tries usually share most of their nodes, so there are only a few distinct candidates per position.
+func appendCandidate(candidates []deltaBaseCandidate, trieIndex uint16, n *node.Node) []deltaBaseCandidate {
+	if n == nil {
+		return candidates
+	}
+	for _, candidate := range candidates {
+		if candidate.node == n {
+			return candidates
+		}
+	}
+	return append(candidates, deltaBaseCandidate{trieIndex: trieIndex, node: n})
+}
+
+// DeltaCheckpointBase returns the number of the base checkpoint of the given checkpoint file,
+// and false if the file is not a delta checkpoint.
+func DeltaCheckpointBase(filepath string) (int, bool, error) {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot open checkpoint file %s: %w", filepath, err)
+	}
+	defer f.Close()
+
+	header := make([]byte, deltaHeaderSize)
+	_, err = io.ReadFull(f, header[:headerSize])
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot read header: %w", err)
+	}
+
+	if binary.BigEndian.Uint16(header) != MagicBytesCheckpointDelta {
+		return 0, false, nil
+	}
+
+	_, err = io.ReadFull(f, header[headerSize:])
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot read delta checkpoint header: %w", err)
+	}
+
+	return int(binary.BigEndian.Uint64(header[headerSize:])), true, nil
+}
+
+// readDeltaCheckpoint decodes delta checkpoint file and returns the list of tries.
+// The base checkpoints are loaded from the directory of the delta checkpoint file.
+// Checkpoint file header (magic) is verified by the caller.
+// If the payload storage is not nil, the payloads of the leaves are moved to the storage while the
+// base checkpoints and the delta checkpoint are read. The payloads only referenced by the tries of
+// the base checkpoint, which are not part of the delta checkpoint, are released again.
+func readDeltaCheckpoint(f *os.File, logger *zerolog.Logger, payloads node.PayloadStorage) ([]*trie.MTrie, error) {
+	scratch := make([]byte, 1024*4) // must not be less than 1024
+
+	// footer offset: entry count (8 bytes) + tries count (2 bytes) + CRC32 sum (4 bytes)
+	const footerOffset = encNodeCountSize + encTrieCountSize + crc32SumSize
+	const footerSize = encNodeCountSize + encTrieCountSize // footer doesn't include crc32 sum
+
+	_, err := f.Seek(-footerOffset, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("cannot seek to footer: %w", err)
+	}
+
+	footer := make([]byte, footerSize)
+	_, err = io.ReadFull(f, footer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	entriesCount := binary.BigEndian.Uint64(footer)
+	triesCount := binary.BigEndian.Uint16(footer[encNodeCountSize:])
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("cannot seek to start of file: %w", err)
+	}
+
+	var bufReader io.Reader = bufio.NewReaderSize(f, defaultBufioReadSize)
+	crcReader := NewCRC32Reader(bufReader)
+	var reader io.Reader = crcReader
+
+	header := make([]byte, deltaHeaderSize)
+	_, err = io.ReadFull(reader, header)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read header: %w", err)
+	}
+
+	version := binary.BigEndian.Uint16(header[encMagicSize:])
+	if version != VersionDeltaV1 {
+		return nil, fmt.Errorf("unsupported delta checkpoint version %x", version)
+	}
+
+	baseCheckpoint := int(binary.BigEndian.Uint64(header[headerSize:]))
+	baseTriesCount := binary.BigEndian.Uint16(header[headerSize+encBaseCheckpointSize:])
+
+	dir := filepath.Dir(f.Name())
+	logger.Info().Msgf("reading delta checkpoint file, loading base checkpoint %d", baseCheckpoint)
+
+	baseTries, err := LoadCheckpointWithPayloadStorage(filepath.Join(dir, NumberToFilename(baseCheckpoint)), logger, payloads)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load base checkpoint %d: %w", baseCheckpoint, err)
+	}
+	if len(baseTries) != int(baseTriesCount) {
+		return nil, fmt.Errorf("base checkpoint %d has %d tries, but delta checkpoint was created with %d base tries",
+			baseCheckpoint, len(baseTries), baseTriesCount)
+	}
+
+	// entries's element at index 0 is a special, meaning nil.
+	entries := make([]*node.Node, entriesCount+1)
+
+	batch := newPayloadBatch(payloads)
+
+	logging := logProgress("reading delta checkpoint entries", int(entriesCount), logger)
+
+	for i := uint64(1); i <= entriesCount; i++ {
+		_, err := io.ReadFull(reader, scratch[:encDeltaEntryTypeSize])
+		if err != nil {
+			return nil, fmt.Errorf("cannot read type of entry %d: %w", i, err)
+		}
+
+		var n *node.Node
+		switch scratch[0] {
+		case deltaEntryBaseNode:
+			n, err = readBaseNodeReference(reader, scratch, baseTries)
+		case deltaEntryNode:
+			n, err = flattener.ReadNode(reader, scratch, func(entryIndex uint64) (*node.Node, error) {
+				if entryIndex >= i {
+					return nil, fmt.Errorf("sequence of serialized nodes does not satisfy Descendents-First-Relationship")
+				}
+				return entries[entryIndex], nil
+			})
+		default:
+			err = fmt.Errorf("unknown entry type %d", scratch[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read entry %d: %w", i, err)
+		}
+		err = batch.add(n)
+		if err != nil {
+			return nil, fmt.Errorf("cannot store payload of entry %d: %w", i, err)
+		}
+
+		entries[i] = n
+		logging(i)
+	}
+
+	err = batch.flush()
+	if err != nil {
+		return nil, fmt.Errorf("cannot store payloads of entries: %w", err)
+	}
+
+	tries := make([]*trie.MTrie, triesCount)
+	for i := uint16(0); i < triesCount; i++ {
+		trie, err := flattener.ReadTrie(reader, scratch, func(entryIndex uint64) (*node.Node, error) {
+			if entryIndex >= uint64(len(entries)) {
+				return nil, fmt.Errorf("sequence of stored entries doesn't contain node")
+			}
+			return entries[entryIndex], nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot read trie %d: %w", i, err)
+		}
+		tries[i] = trie
+	}
+
+	// Read footer again for crc32 computation
+	_, err = io.ReadFull(reader, footer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	crc32buf := scratch[:crc32SumSize]
+	_, err = io.ReadFull(bufReader, crc32buf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CRC32: %w", err)
+	}
+
+	readCrc32 := binary.BigEndian.Uint32(crc32buf)
+	calculatedCrc32 := crcReader.Crc32()
+	if calculatedCrc32 != readCrc32 {
+		return nil, fmt.Errorf("checkpoint checksum failed! File contains %x but calculated crc32 is %x", readCrc32, calculatedCrc32)
+	}
+
+	if payloads == nil {
+		return tries, nil
+	}
+
+	// the base tries are dropped, so the payloads only referenced by them are released
+	for i, baseTrie := range baseTries {
+		held := append(tries[:len(tries):len(tries)], baseTries[i+1:]...)
+		err = baseTrie.ReleasePayloads(held, payloads)
+		if err != nil {
+			return nil, fmt.Errorf("cannot release payloads of base trie %d: %w", i, err)
+		}
+	}
+
+	return batch.withStoredPayloads(tries)
+}
+
+// readBaseNodeReference reads a reference to a node of the base tries and returns the node.
+// The referenced node is found by following the path from the root of the base trie
+// down to the node's depth, and is verified against the stored node hash.
+func readBaseNodeReference(reader io.Reader, scratch []byte, baseTries []*trie.MTrie) (*node.Node, error) {
+	ref := scratch[:encTrieIndexSize+encNodeDepthSize+ledger.PathLen+hash.HashLen]
+	_, err := io.ReadFull(reader, ref)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read base node reference: %w", err)
+	}
+
+	trieIndex := binary.BigEndian.Uint16(ref)
+	pos := encTrieIndexSize
+	depth := int(binary.BigEndian.Uint16(ref[pos:]))
+	pos += encNodeDepthSize
+	path := ref[pos : pos+ledger.PathLen]
+	pos += ledger.PathLen
+	nodeHash, err := hash.ToHash(ref[pos:])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode node hash: %w", err)
+	}
+
+	if int(trieIndex) >= len(baseTries) {
+		return nil, fmt.Errorf("base trie index %d out of range, base checkpoint has %d tries", trieIndex, len(baseTries))
+	}
+	if depth > ledger.NodeMaxHeight {
+		return nil, fmt.Errorf("base node depth %d exceeds max height %d", depth, ledger.NodeMaxHeight)
+	}
+
+	n := baseTries[trieIndex].RootNode()
+	for i := 0; i < depth && n != nil; i++ {
+		if bitutils.ReadBit(path, i) == 0 {
+			n = n.LeftChild()
+		} else {
+			n = n.RightChild()
+		}
+	}
+
+	if n == nil {
+		return nil, fmt.Errorf("base trie %d has no node at depth %d of path %x", trieIndex, depth, path)
+	}
+	if n.Hash() != nodeHash {
+		return nil, fmt.Errorf("base trie %d has node with hash %v at depth %d of path %x, but %v was referenced",
+			trieIndex, n.Hash(), depth, path, nodeHash)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,236 @@
+package wal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// updateTries returns n tries, each updating random registers of the previous one, starting from the given trie.
+func updateTries(t *testing.T, activeTrie *trie.MTrie, n int) []*trie.MTrie {
+	tries := make([]*trie.MTrie, 0, n)
+	for i := 0; i < n; i++ {
+		paths, payloads := randNPathPayloads(20)
+		var err error
+		activeTrie, _, err = trie.NewTrieWithUpdatedRegisters(activeTrie, paths, payloads, false)
+		require.NoError(t, err)
+		tries = append(tries, activeTrie)
+	}
+	return tries
+}
+
+func TestDeltaCheckpoint(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		logger := unittest.Logger()
+
+		baseTries := createMultipleRandomTries(t)
+		require.NoError(t, StoreCheckpointV6SingleThread(baseTries, dir, NumberToFilename(1), &logger))
+
+		// the delta contains some of the base tries, and tries created from the last base trie
+		tries := append(baseTries[len(baseTries)-5:], updateTries(t, baseTries[len(baseTries)-1], 10)...)
+		require.NoError(t, StoreDeltaCheckpoint(dir, NumberToFilename(2), &logger, 1, baseTries, tries))
+
+		base, isDelta, err := DeltaCheckpointBase(filepath.Join(dir, NumberToFilename(2)))
+		require.NoError(t, err)
+		require.True(t, isDelta)
+		require.Equal(t, 1, base)
+
+		_, isDelta, err = DeltaCheckpointBase(filepath.Join(dir, NumberToFilename(1)))
+		require.NoError(t, err)
+		require.False(t, isDelta)
+
+		decoded, err := LoadCheckpoint(filepath.Join(dir, NumberToFilename(2)), &logger)
+		require.NoError(t, err)
+		requireTriesEqual(t, tries, decoded)
+
+		// the delta only contains the new nodes
+		fullFiles, err := filepath.Glob(filePathPattern(dir, NumberToFilename(1)))
+		require.NoError(t, err)
+		fullSize := int64(0)
+		for _, file := range fullFiles {
+			info, err := os.Stat(file)
+			require.NoError(t, err)
+			fullSize += info.Size()
+		}
+		deltaInfo, err := os.Stat(filepath.Join(dir, NumberToFilename(2)))
+		require.NoError(t, err)
+		require.Less(t, deltaInfo.Size(), fullSize/2)
+	})
+}
+
+func TestDeltaCheckpointChain(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		logger := unittest.Logger()
+
+		baseTries := createMultipleRandomTries(t)
+		require.NoError(t, StoreCheckpointV6SingleThread(baseTries, dir, NumberToFilename(1), &logger))
+
+		// each delta is based on the previous delta
+		tries := baseTries
+		for i := 2; i <= 4; i++ {
+			newTries := updateTries(t, tries[len(tries)-1], 10)
+			require.NoError(t, StoreDeltaCheckpoint(dir, NumberToFilename(i), &logger, i-1, tries, newTries))
+			tries = newTries
+		}
+
+		decoded, err := LoadCheckpoint(filepath.Join(dir, NumberToFilename(4)), &logger)
+		require.NoError(t, err)
+		requireTriesEqual(t, tries, decoded)
+
+		// the delta can't be loaded without its base
+		require.NoError(t, deleteCheckpointFiles(dir, NumberToFilename(3)))
+		_, err = LoadCheckpoint(filepath.Join(dir, NumberToFilename(4)), &logger)
+		require.Error(t, err)
+	})
+}
+
+// TestDeltaCheckpointWithPayloadStorage tests that the payloads are moved to the storage while
+// the delta checkpoint and its base are read, and the payloads of the dropped base tries are released.
+func TestDeltaCheckpointWithPayloadStorage(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		logger := unittest.Logger()
+		storage := newMemoryPayloadStorage()
+
+		baseTries := createMultipleRandomTries(t)
+		require.NoError(t, StoreCheckpointV6SingleThread(baseTries, dir, NumberToFilename(1), &logger))
+
+		tries := append(baseTries[len(baseTries)-1:], updateTries(t, baseTries[len(baseTries)-1], 10)...)
+		require.NoError(t, StoreDeltaCheckpoint(dir, NumberToFilename(2), &logger, 1, baseTries, tries))
+
+		decoded, err := LoadCheckpointWithPayloadStorage(filepath.Join(dir, NumberToFilename(2)), &logger, storage)
+		require.NoError(t, err)
+		requireTriesEqual(t, tries, decoded)
+
+		for i, decodedTrie := range decoded {
+			require.True(t, decodedTrie.PayloadsStored())
+
+			expected, err := tries[i].AllPayloads()
+			require.NoError(t, err)
+			actual, err := decodedTrie.AllPayloads()
+			require.NoError(t, err)
+			require.ElementsMatch(t, expected, actual)
+		}
+
+		// only the decoded tries reference stored payloads, as the payloads only referenced by
+		// the dropped base tries were released
+		for i, decodedTrie := range decoded {
+			require.NoError(t, decodedTrie.ReleasePayloads(decoded[i+1:], storage))
+		}
+		require.Empty(t, storage.payloads)
+	})
+}
+
+// memoryPayloadStorage is a node.PayloadStorage keeping the payloads with their reference counts in memory.
+type memoryPayloadStorage struct {
+	mu         sync.Mutex
+	payloads   map[hash.Hash]*ledger.Payload
+	references map[hash.Hash]int
+	next       uint64
+}
+
+func newMemoryPayloadStorage() *memoryPayloadStorage {
+	return &memoryPayloadStorage{
+		payloads:   make(map[hash.Hash]*ledger.Payload),
+		references: make(map[hash.Hash]int),
+	}
+}
+
+func (s *memoryPayloadStorage) Store(_ []ledger.Path, payloads []*ledger.Payload) ([]hash.Hash, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]hash.Hash, len(payloads))
+	for i, payload := range payloads {
+		s.next++
+		binary.BigEndian.PutUint64(keys[i][:], s.next)
+		s.payloads[keys[i]] = payload
+		s.references[keys[i]] = 1
+	}
+	return keys, nil
+}
+
+func (s *memoryPayloadStorage) Load(key hash.Hash) (*ledger.Payload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payload, ok := s.payloads[key]
+	if !ok {
+		return nil, fmt.Errorf("payload %v not found", key)
+	}
+	return payload, nil
+}
+
+func (s *memoryPayloadStorage) Retain(keys []hash.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if _, ok := s.payloads[key]; !ok {
+			return fmt.Errorf("payload %v not found", key)
+		}
+		s.references[key]++
+	}
+	return nil
+}
+
+func (s *memoryPayloadStorage) Release(keys []hash.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if _, ok := s.payloads[key]; !ok {
+			return fmt.Errorf("payload %v not found", key)
+		}
+		s.references[key]--
+		if s.references[key] == 0 {
+			delete(s.payloads, key)
+			delete(s.references, key)
+		}
+	}
+	return nil
+}
+
+func TestDeltaCheckpointEmptyTries(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		logger := unittest.Logger()
+
+		emptyTries := []*trie.MTrie{trie.NewEmptyMTrie()}
+		require.NoError(t, StoreCheckpointV6SingleThread(emptyTries, dir, NumberToFilename(1), &logger))
+
+		tries := append(emptyTries, createSimpleTrie(t)...)
+		require.NoError(t, StoreDeltaCheckpoint(dir, NumberToFilename(2), &logger, 1, emptyTries, tries))
+
+		decoded, err := LoadCheckpoint(filepath.Join(dir, NumberToFilename(2)), &logger)
+		require.NoError(t, err)
+		requireTriesEqual(t, tries, decoded)
+	})
+}
+
+func TestDeltaCheckpointMismatchingBase(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		logger := unittest.Logger()
+
+		baseTries := createMultipleRandomTries(t)
+		require.NoError(t, StoreCheckpointV6SingleThread(baseTries, dir, NumberToFilename(1), &logger))
+
+		tries := updateTries(t, baseTries[len(baseTries)-1], 2)
+		require.NoError(t, StoreDeltaCheckpoint(dir, NumberToFilename(2), &logger, 1, baseTries, tries))
+
+		// replace the base by a checkpoint with other tries
+		require.NoError(t, deleteCheckpointFiles(dir, NumberToFilename(1)))
+		require.NoError(t, StoreCheckpointV6SingleThread(createMultipleRandomTries(t), dir, NumberToFilename(1), &logger))
+
+		_, err := LoadCheckpoint(filepath.Join(dir, NumberToFilename(2)), &logger)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,85 @@
+package wal
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+)
+
+// payloadBatchSize is the number of leaves whose payloads are stored at once while reading a checkpoint
+const payloadBatchSize = 10_000
+
+// payloadBatch stores the payloads of the leaves read from a checkpoint in batches, so that
+// the payloads are moved out of memory while the checkpoint is read, rather than after all
+// tries were read. A nil batch keeps the payloads in memory.
+//
+// If reading the checkpoint fails, the payloads stored so far are not released. They are
+// dropped when the storage is opened next time.
+type payloadBatch struct {
+	storage node.PayloadStorage
+	leaves  []*node.Node
+}
+
+// newPayloadBatch returns a batch storing the payloads in the given storage,
+// or nil if the storage is nil.
+func newPayloadBatch(storage node.PayloadStorage) *payloadBatch {
+	if storage == nil {
+		return nil
+	}
+	return &payloadBatch{
+		storage: storage,
+		leaves:  make([]*node.Node, 0, payloadBatchSize),
+	}
+}
+
+// add adds the given node to the batch if it is a leaf with an in-memory payload,
+// and stores the payloads of the batch once it is full.
+// UNSAFE: the node must not be accessed concurrently, i.e. it must have just been read.
+// No errors are expected during normal operation.
+func (b *payloadBatch) add(n *node.Node) error {
+	if b == nil || n == nil || !n.IsLeaf() || n.IsPayloadStored() {
+		return nil
+	}
+
+	b.leaves = append(b.leaves, n)
+	if len(b.leaves) < payloadBatchSize {
+		return nil
+	}
+	return b.flush()
+}
+
+// flush stores the payloads of the leaves in the batch.
+// No errors are expected during normal operation.
+func (b *payloadBatch) flush() error {
+	if b == nil || len(b.leaves) == 0 {
+		return nil
+	}
+
+	err := node.StorePayloads(b.leaves, b.storage)
+	if err != nil {
+		return fmt.Errorf("could not store payloads of checkpoint leaves: %w", err)
+	}
+
+	b.leaves = b.leaves[:0]
+	return nil
+}
+
+// withStoredPayloads returns the given tries marked as tries whose payloads are all stored,
+// as all leaves of the tries were added to the batch while reading the checkpoint.
+// The tries are returned unchanged if the batch is nil.
+func (b *payloadBatch) withStoredPayloads(tries []*trie.MTrie) ([]*trie.MTrie, error) {
+	if b == nil {
+		return tries, nil
+	}
+
+	stored := make([]*trie.MTrie, len(tries))
+	for i, t := range tries {
+		var err error
+		stored[i], err = trie.NewMTrieWithStoredPayloads(t.RootNode(), t.AllocatedRegCount(), t.AllocatedRegSize())
+		if err != nil {
+			return nil, fmt.Errorf("could not mark payloads of trie %d as stored: %w", i, err)
+		}
+	}
+	return stored, nil
+}
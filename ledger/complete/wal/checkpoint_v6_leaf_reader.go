@@ -0,0 +1,928 @@
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/bitutils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+)
+
+// ErrTrieNotFound is returned when a checkpoint doesn't contain the requested trie
+var ErrTrieNotFound = errors.New("trie not found in checkpoint")
+
+// keyPartOwner is the type of the key part holding the register owner,
+// same as the execution state's KeyPartOwner.
+const keyPartOwner = uint16(0)
+
+// LeafFilter limits the leaves read by a TrieLeafIterator.
+// The zero value doesn't limit the leaves.
+type LeafFilter struct {
+	// PathPrefixes limits the leaves to the ones with a path starting with any of the prefixes.
+	// Only the subtrie part files containing such paths are read, the leaves of the part files read
+	// are filtered by their path.
+	PathPrefixes [][]byte
+	// Owners limits the leaves to the registers of any of the owners.
+	// Since the path of a register doesn't depend on its owner only, this doesn't limit
+	// the part files read.
+	Owners [][]byte
+}
+
+// TrieLeafIterator iterates over the leaves of a single trie stored in a V6 checkpoint,
+// without building the trie in memory. The leaves of each part file are read in the
+// order they are stored in the file, which isn't necessarily the order of their paths.
+//
+// Usage:
+//
+//	it, err := OpenTrieLeafIteratorV6(dir, fileName, rootHash, filter, logger)
+//	...
+//	defer it.Close()
+//	for it.Next() {
+//		path, payload := it.Value()
+//		...
+//	}
+//	if it.Err() != nil {
+//		...
+//	}
+type TrieLeafIterator struct {
+	filter   LeafFilter
+	owners   map[string]struct{}
+	sources  []func() (*leafReader, error) // open the part files to read leaves from after the current one
+	current  *leafReader                   // reader of the part file leaves are currently read from
+	leafPath ledger.Path
+	payload  *ledger.Payload
+	err      error
+}
+
+// nodeIndices is a node of a checkpoint part file, without its hash, path, and payload.
+type nodeIndices struct {
+	isLeaf      bool
+	lchildIndex uint64
+	rchildIndex uint64
+}
+
+// OpenTrieLeafIteratorV6 opens an iterator over the leaves of the trie with the given root hash,
+// stored in the V6 checkpoint with the given file name. The leaves can be limited with the filter.
+//
+// Only the top level part file, and the subtrie part files containing the selected leaves are read.
+// The subtrie part files are read one at a time, and each is read twice: first the child indices of
+// its nodes are streamed to a temporary file to find the leaves of the trie, then the leaves are read.
+// Only a bit per node of the part file is kept in memory.
+//
+// Expected errors:
+//   - ErrTrieNotFound if the checkpoint doesn't contain the trie
+//
+// All other errors are exceptions, e.g. the checkpoint is missing or corrupted.
+func OpenTrieLeafIteratorV6(
+	dir string,
+	fileName string,
+	rootHash ledger.RootHash,
+	filter LeafFilter,
+	logger *zerolog.Logger,
+) (*TrieLeafIterator, error) {
+	lg := logger.With().
+		Str("checkpoint_file", filePathCheckpointHeader(dir, fileName)).
+		Str("root_hash", rootHash.String()).
+		Logger()
+
+	index, err := readCheckpointV6Index(dir, fileName, &lg)
+	if err != nil {
+		return nil, err
+	}
+
+	rootIndex, ok := index.rootIndex(rootHash)
+	if !ok {
+		return nil, fmt.Errorf("could not find trie %v in checkpoint %v: %w", rootHash, fileName, ErrTrieNotFound)
+	}
+
+	subtrieChecksums := index.subtrieChecksums
+	subtrieNodeCounts := index.subtrieNodeCounts
+	totalSubtrieNodeCount := index.totalSubtrieNodeCount
+	topLevelNodes := index.topLevelNodes
+
+	// find the top level leaves and the subtrie roots of the trie, limited by the path prefixes
+	topLevelLeaves := bitutils.MakeBitVector(len(topLevelNodes))
+	hasTopLevelLeaves := false
+	subtrieRoots := make([][]uint64, len(subtrieNodeCounts))
+
+	var walk func(index uint64, depth int, prefixes [][]byte) error
+	walk = func(index uint64, depth int, prefixes [][]byte) error {
+		if index == 0 || (prefixes != nil && len(prefixes) == 0) {
+			return nil
+		}
+
+		if index <= totalSubtrieNodeCount {
+			offset := index - 1
+			for part, count := range subtrieNodeCounts {
+				if offset < count {
+					// the leaves below the subtrie root are filtered by path while they are read
+					subtrieRoots[part] = append(subtrieRoots[part], offset+1)
+					return nil
+				}
+				offset -= count
+			}
+		}
+
+		topIndex := index - totalSubtrieNodeCount
+		if topIndex >= uint64(len(topLevelNodes)) {
+			return fmt.Errorf("could not find node by index %v", index)
+		}
+
+		n := topLevelNodes[topIndex]
+		if n.isLeaf {
+			bitutils.SetBit(topLevelLeaves, int(topIndex))
+			hasTopLevelLeaves = true
+			return nil
+		}
+
+		err := walk(n.lchildIndex, depth+1, narrowPrefixes(prefixes, depth, 0))
+		if err != nil {
+			return err
+		}
+		return walk(n.rchildIndex, depth+1, narrowPrefixes(prefixes, depth, 1))
+	}
+
+	var prefixes [][]byte
+	if len(filter.PathPrefixes) > 0 {
+		prefixes = filter.PathPrefixes
+	}
+
+	err = walk(rootIndex, 0, prefixes)
+	if err != nil {
+		return nil, fmt.Errorf("could not find nodes of trie %v: %w", rootHash, err)
+	}
+
+	sources := make([]func() (*leafReader, error), 0, len(subtrieRoots)+1)
+
+	if hasTopLevelLeaves {
+		sources = append(sources, func() (*leafReader, error) {
+			return openTopLevelLeafReader(dir, fileName, topLevelLeaves)
+		})
+	}
+
+	partsToRead := 0
+	for part, roots := range subtrieRoots {
+		if len(roots) == 0 {
+			continue
+		}
+		part, roots := part, roots
+		partsToRead++
+		sources = append(sources, func() (*leafReader, error) {
+			return openSubtrieLeafReader(dir, fileName, part, subtrieChecksums[part], roots, &lg)
+		})
+	}
+
+	lg.Info().
+		Bool("top_level_leaves", hasTopLevelLeaves).
+		Int("subtrie_files", partsToRead).
+		Msg("opened trie leaf iterator of v6 checkpoint")
+
+	var owners map[string]struct{}
+	if len(filter.Owners) > 0 {
+		owners = make(map[string]struct{}, len(filter.Owners))
+		for _, owner := range filter.Owners {
+			owners[string(owner)] = struct{}{}
+		}
+	}
+
+	return &TrieLeafIterator{
+		filter:  filter,
+		owners:  owners,
+		sources: sources,
+	}, nil
+}
+
+// Next advances the iterator to the next leaf, and returns false if there are no more leaves
+// or an error occurred, see Err.
+func (it *TrieLeafIterator) Next() bool {
+	for it.err == nil {
+		if it.current == nil {
+			if len(it.sources) == 0 {
+				return false
+			}
+
+			open := it.sources[0]
+			it.sources = it.sources[1:]
+
+			it.current, it.err = open()
+			continue
+		}
+
+		leaf, err := it.current.read()
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		if leaf == nil {
+			it.err = it.current.close()
+			it.current = nil
+			continue
+		}
+
+		match, err := it.matches(leaf)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if !match {
+			continue
+		}
+
+		payload, err := leaf.Payload()
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.leafPath = *leaf.Path()
+		it.payload = payload
+		return true
+	}
+	return false
+}
+
+// Value returns the path and payload of the current leaf.
+func (it *TrieLeafIterator) Value() (ledger.Path, *ledger.Payload) {
+	return it.leafPath, it.payload
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *TrieLeafIterator) Err() error {
+	return it.err
+}
+
+// Close closes the part file currently read.
+func (it *TrieLeafIterator) Close() error {
+	if it.current == nil {
+		return nil
+	}
+	err := it.current.close()
+	it.current = nil
+	it.sources = nil
+	return err
+}
+
+func (it *TrieLeafIterator) matches(leaf *node.Node) (bool, error) {
+	if len(it.filter.PathPrefixes) > 0 {
+		path := leaf.Path()
+		match := false
+		for _, prefix := range it.filter.PathPrefixes {
+			if bytes.HasPrefix(path[:], prefix) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false, nil
+		}
+	}
+
+	if it.owners != nil {
+		payload, err := leaf.Payload()
+		if err != nil {
+			return false, err
+		}
+		key, err := payload.Key()
+		if err != nil {
+			return false, fmt.Errorf("could not decode key of leaf %v: %w", leaf.Path(), err)
+		}
+		if len(key.KeyParts) == 0 || key.KeyParts[0].Type != keyPartOwner {
+			return false, nil
+		}
+		if _, ok := it.owners[string(key.KeyParts[0].Value)]; !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// leafBatchSize is the number of leaves added to the trie at once by LoadTrieV6.
+const leafBatchSize = 10_000
+
+// LoadTrieV6 rebuilds the trie with the given root hash from its leaves stored in the V6 checkpoint
+// with the given file name, without loading the other tries of the checkpoint (see OpenTrieLeafIteratorV6).
+// The rebuilt trie doesn't share any nodes with other tries.
+//
+// Expected errors:
+//   - ErrTrieNotFound if the checkpoint doesn't contain the trie
+//
+// All other errors are exceptions, e.g. the checkpoint is missing or corrupted.
+func LoadTrieV6(dir string, fileName string, rootHash ledger.RootHash, logger *zerolog.Logger) (*trie.MTrie, error) {
+	it, err := OpenTrieLeafIteratorV6(dir, fileName, rootHash, LeafFilter{}, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	mt := trie.NewEmptyMTrie()
+	paths := make([]ledger.Path, 0, leafBatchSize)
+	payloads := make([]ledger.Payload, 0, leafBatchSize)
+
+	update := func() error {
+		if len(paths) == 0 {
+			return nil
+		}
+		var err error
+		mt, _, err = trie.NewTrieWithUpdatedRegisters(mt, paths, payloads, true)
+		if err != nil {
+			return fmt.Errorf("could not add leaves to trie: %w", err)
+		}
+		paths = paths[:0]
+		payloads = payloads[:0]
+		return nil
+	}
+
+	for it.Next() {
+		path, payload := it.Value()
+		paths = append(paths, path)
+		payloads = append(payloads, *payload)
+
+		if len(paths) == leafBatchSize {
+			err = update()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if it.Err() != nil {
+		return nil, fmt.Errorf("could not read leaves of trie %v: %w", rootHash, it.Err())
+	}
+
+	err = update()
+	if err != nil {
+		return nil, err
+	}
+
+	if mt.RootHash() != rootHash {
+		return nil, fmt.Errorf("rebuilt trie has root hash %v, expected %v", mt.RootHash(), rootHash)
+	}
+
+	return mt, nil
+}
+
+// ReadTrieRootHashesV6 returns the root hashes of the tries stored in the V6 checkpoint with the
+// given file name, in the order they are stored. Only the top level part file is read, and the
+// node counts of the subtrie part files.
+// All errors are exceptions, e.g. the checkpoint is missing or corrupted.
+func ReadTrieRootHashesV6(dir string, fileName string, logger *zerolog.Logger) ([]ledger.RootHash, error) {
+	lg := logger.With().Str("checkpoint_file", filePathCheckpointHeader(dir, fileName)).Logger()
+
+	index, err := readCheckpointV6Index(dir, fileName, &lg)
+	if err != nil {
+		return nil, err
+	}
+
+	rootHashes := make([]ledger.RootHash, len(index.trieRoots))
+	for i, root := range index.trieRoots {
+		rootHashes[i] = root.rootHash
+	}
+	return rootHashes, nil
+}
+
+// IsCheckpointV6 returns true if the given file is the header file of a V6 checkpoint, which can
+// be read selectively with ReadTrieRootHashesV6 and OpenTrieLeafIteratorV6.
+func IsCheckpointV6(filepath string) (bool, error) {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return false, fmt.Errorf("cannot open checkpoint file %s: %w", filepath, err)
+	}
+	defer f.Close()
+
+	magicBytes, version, err := readFileHeader(f)
+	if err != nil {
+		return false, err
+	}
+
+	return magicBytes == MagicBytesCheckpointHeader && version == VersionV6, nil
+}
+
+// checkpointV6Index holds the node counts of the subtrie part files of a V6 checkpoint, and the
+// child indices of its top level nodes and the root node indices of its tries.
+type checkpointV6Index struct {
+	subtrieChecksums      []uint32
+	subtrieNodeCounts     []uint64
+	totalSubtrieNodeCount uint64
+	topLevelNodes         []nodeIndices // topLevelNodes[0] is unused, as index 0 means nil
+	trieRoots             []trieRootIndex
+}
+
+// trieRootIndex is the root node index of a trie stored in a V6 checkpoint.
+type trieRootIndex struct {
+	rootHash ledger.RootHash
+	index    uint64
+}
+
+// readCheckpointV6Index reads the header and top level part file of the V6 checkpoint with the
+// given file name, and the node counts of its subtrie part files.
+func readCheckpointV6Index(dir string, fileName string, logger *zerolog.Logger) (*checkpointV6Index, error) {
+	subtrieChecksums, topTrieChecksum, err := readCheckpointHeader(filePathCheckpointHeader(dir, fileName), logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not read header: %w", err)
+	}
+
+	subtrieNodeCounts := make([]uint64, len(subtrieChecksums))
+	totalSubtrieNodeCount := uint64(0)
+	for i, checksum := range subtrieChecksums {
+		subtrieNodeCounts[i], err = readSubtrieNodeCount(dir, fileName, i, checksum)
+		if err != nil {
+			return nil, fmt.Errorf("could not read node count of %v-th subtrie file: %w", i, err)
+		}
+		totalSubtrieNodeCount += subtrieNodeCounts[i]
+	}
+
+	topLevelNodes, trieRoots, err := readTopLevelNodeIndices(dir, fileName, totalSubtrieNodeCount, topTrieChecksum, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not read top level nodes: %w", err)
+	}
+
+	return &checkpointV6Index{
+		subtrieChecksums:      subtrieChecksums,
+		subtrieNodeCounts:     subtrieNodeCounts,
+		totalSubtrieNodeCount: totalSubtrieNodeCount,
+		topLevelNodes:         topLevelNodes,
+		trieRoots:             trieRoots,
+	}, nil
+}
+
+// rootIndex returns the root node index of the trie with the given root hash,
+// and false if the checkpoint doesn't contain the trie.
+func (c *checkpointV6Index) rootIndex(rootHash ledger.RootHash) (uint64, bool) {
+	for _, root := range c.trieRoots {
+		if root.rootHash == rootHash {
+			return root.index, true
+		}
+	}
+	return 0, false
+}
+
+// narrowPrefixes returns the path prefixes of the leaves below the child of a node at the given depth,
+// with bit being 0 for the left child and 1 for the right child.
+// nil means all leaves below match, an empty result means no leaves below match.
+func narrowPrefixes(prefixes [][]byte, depth int, bit int) [][]byte {
+	if prefixes == nil {
+		return nil
+	}
+
+	narrowed := make([][]byte, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		if len(prefix)*8 <= depth {
+			// the node is below the prefix, so all leaves below match
+			return nil
+		}
+		if bitutils.ReadBit(prefix, depth) == bit {
+			narrowed = append(narrowed, prefix)
+		}
+	}
+	return narrowed
+}
+
+// readSubtrieNodeCount returns the node count of the subtrie part file with the given index,
+// and validates its checksum with the one in the checkpoint header.
+func readSubtrieNodeCount(dir string, fileName string, index int, checksum uint32) (
+	nodeCount uint64,
+	errToReturn error,
+) {
+	filepath, _, err := filePathSubTries(dir, fileName, index)
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.Open(filepath)
+	if err != nil {
+		return 0, fmt.Errorf("could not open file %v: %w", filepath, err)
+	}
+	defer func(file *os.File) {
+		errToReturn = closeAndMergeError(file, errToReturn)
+	}(f)
+
+	err = validateFileHeader(MagicBytesCheckpointSubtrie, VersionV6, f)
+	if err != nil {
+		return 0, err
+	}
+
+	nodeCount, expectedSum, err := readSubTriesFooter(f)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read sub trie node count: %w", err)
+	}
+
+	if checksum != expectedSum {
+		return 0, fmt.Errorf("mismatch checksum in subtrie file. checksum from checkpoint header %v does not "+
+			"match with the checksum in subtrie file %v", checksum, expectedSum)
+	}
+
+	return nodeCount, nil
+}
+
+// readTopLevelNodeIndices reads the top level part file, and returns the indices of the top level nodes
+// and the root node index of each trie, in the order the tries are stored.
+// topLevelNodes[0] is unused, as index 0 means nil.
+func readTopLevelNodeIndices(
+	dir string,
+	fileName string,
+	totalSubtrieNodeCount uint64,
+	topTrieChecksum uint32,
+	logger *zerolog.Logger,
+) (
+	topLevelNodes []nodeIndices,
+	trieRoots []trieRootIndex,
+	errToReturn error,
+) {
+	filepath, _ := filePathTopTries(dir, fileName)
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open file %v: %w", filepath, err)
+	}
+	defer func(file *os.File) {
+		evictErr := evictFileFromLinuxPageCache(file, false, logger)
+		if evictErr != nil {
+			logger.Warn().Msgf("failed to evict top trie file %s from Linux page cache: %s", filepath, evictErr)
+		}
+		errToReturn = closeAndMergeError(file, errToReturn)
+	}(file)
+
+	err = validateFileHeader(MagicBytesCheckpointToptrie, VersionV6, file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	topLevelNodesCount, triesCount, expectedSum, err := readTopTriesFooter(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read top tries footer: %w", err)
+	}
+
+	if topTrieChecksum != expectedSum {
+		return nil, nil, fmt.Errorf("mismatch top trie checksum, header file has %v, toptrie file has %v",
+			topTrieChecksum, expectedSum)
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not seek to 0: %w", err)
+	}
+
+	reader := NewCRC32Reader(bufio.NewReaderSize(file, defaultBufioReadSize))
+
+	readSubtrieNodeCount, err := readTopLevelFileHeader(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if readSubtrieNodeCount != totalSubtrieNodeCount {
+		return nil, nil, fmt.Errorf("mismatch subtrie node count, read from disk (%v), but got actual node count (%v)",
+			readSubtrieNodeCount, totalSubtrieNodeCount)
+	}
+
+	scratch := make([]byte, 1024*4)
+
+	topLevelNodes = make([]nodeIndices, topLevelNodesCount+1)
+	for i := uint64(1); i <= topLevelNodesCount; i++ {
+		isLeaf, lchildIndex, rchildIndex, err := flattener.ReadNodeChildIndices(reader, scratch)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read node at index %d: %w", i, err)
+		}
+		if lchildIndex >= i+totalSubtrieNodeCount || rchildIndex >= i+totalSubtrieNodeCount {
+			return nil, nil, fmt.Errorf("sequence of serialized nodes does not satisfy Descendents-First-Relationship")
+		}
+		topLevelNodes[i] = nodeIndices{isLeaf: isLeaf, lchildIndex: lchildIndex, rchildIndex: rchildIndex}
+	}
+
+	trieRoots = make([]trieRootIndex, 0, triesCount)
+	for i := uint16(0); i < triesCount; i++ {
+		rootHash, rootIndex, err := flattener.ReadTrieRootIndex(reader, scratch)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read root trie at index %d: %w", i, err)
+		}
+		trieRoots = append(trieRoots, trieRootIndex{rootHash: rootHash, index: rootIndex})
+	}
+
+	// read footer and discard, since we only care about checksum
+	_, err = io.ReadFull(reader, scratch[:encNodeCountSize+encTrieCountSize])
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	actualSum := reader.Crc32()
+	if actualSum != expectedSum {
+		return nil, nil, fmt.Errorf("invalid checksum in top level trie, expected %v, actual %v",
+			expectedSum, actualSum)
+	}
+
+	return topLevelNodes, trieRoots, nil
+}
+
+// readTopLevelFileHeader reads the header of the top level part file, and returns the subtrie node count.
+func readTopLevelFileHeader(reader io.Reader) (uint64, error) {
+	_, _, err := readFileHeader(reader)
+	if err != nil {
+		return 0, fmt.Errorf("could not read version for top trie: %w", err)
+	}
+
+	buf := make([]byte, encNodeCountSize)
+	_, err = io.ReadFull(reader, buf)
+	if err != nil {
+		return 0, fmt.Errorf("could not read subtrie node count: %w", err)
+	}
+
+	count, err := decodeNodeCount(buf)
+	if err != nil {
+		return 0, fmt.Errorf("could not decode node count: %w", err)
+	}
+	return count, nil
+}
+
+// leafReader reads the selected leaves of a part file.
+type leafReader struct {
+	file    *os.File
+	reader  io.Reader
+	leaves  []byte // bit vector, the bit at index i is set if the node at index i is a selected leaf
+	next    uint64 // index of the next node to read
+	end     uint64 // index after the last selected leaf, where reading stops
+	scratch []byte
+}
+
+func newLeafReader(file *os.File, reader io.Reader, leaves []byte) *leafReader {
+	// reading stops after the last selected leaf
+	end := uint64(len(leaves) * 8)
+	for end > 1 && bitutils.ReadBit(leaves, int(end-1)) == 0 {
+		end--
+	}
+
+	return &leafReader{
+		file:    file,
+		reader:  reader,
+		leaves:  leaves,
+		next:    1,
+		end:     end,
+		scratch: make([]byte, 1024*4),
+	}
+}
+
+// read returns the next selected leaf, or nil if all selected leaves were read.
+func (r *leafReader) read() (*node.Node, error) {
+	for ; r.next < r.end; r.next++ {
+		if bitutils.ReadBit(r.leaves, int(r.next)) == 0 {
+			_, _, _, err := flattener.ReadNodeChildIndices(r.reader, r.scratch)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read node at index %d: %w", r.next, err)
+			}
+			continue
+		}
+
+		leaf, err := flattener.ReadNode(r.reader, r.scratch, func(nodeIndex uint64) (*node.Node, error) {
+			return nil, fmt.Errorf("node at index %d is not a leaf", r.next)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot read leaf at index %d: %w", r.next, err)
+		}
+
+		r.next++
+		return leaf, nil
+	}
+	return nil, nil
+}
+
+func (r *leafReader) close() error {
+	return r.file.Close()
+}
+
+// openTopLevelLeafReader opens a reader of the given leaves of the top level part file.
+// The checksum of the file was verified by readTopLevelNodeIndices.
+func openTopLevelLeafReader(dir string, fileName string, leaves []byte) (*leafReader, error) {
+	filepath, _ := filePathTopTries(dir, fileName)
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file %v: %w", filepath, err)
+	}
+
+	reader := bufio.NewReaderSize(file, defaultBufioReadSize)
+
+	_, err = readTopLevelFileHeader(reader)
+	if err != nil {
+		return nil, closeAndMergeError(file, err)
+	}
+
+	return newLeafReader(file, reader, leaves), nil
+}
+
+// openSubtrieLeafReader finds the leaves below the given subtrie roots in the subtrie part file
+// with the given index, and opens a reader of these leaves.
+func openSubtrieLeafReader(
+	dir string,
+	fileName string,
+	index int,
+	checksum uint32,
+	roots []uint64,
+	logger *zerolog.Logger,
+) (*leafReader, error) {
+	filepath, _, err := filePathSubTries(dir, fileName, index)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := readSubtrieLeaves(filepath, roots, checksum, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not find leaves of %v-th subtrie file: %w", index, err)
+	}
+
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file %v: %w", filepath, err)
+	}
+
+	reader := bufio.NewReaderSize(file, defaultBufioReadSize)
+
+	_, _, err = readFileHeader(reader)
+	if err != nil {
+		return nil, closeAndMergeError(file, fmt.Errorf("could not read version for subtrie: %w", err))
+	}
+
+	return newLeafReader(file, reader, leaves), nil
+}
+
+// encNodeIndicesSize is the size of a node in the temporary node indices file:
+// 1 byte leaf flag, 8 bytes left child index, 8 bytes right child index.
+const encNodeIndicesSize = 1 + 8 + 8
+
+// readSubtrieLeaves returns a bit vector of the leaves below the given roots in the subtrie part file,
+// and verifies the checksum of the file.
+//
+// The children of a node are stored before the node, so the nodes below the roots can't be found
+// while the file is read. Instead of holding the child indices of all nodes in memory, they are
+// written to a temporary file, which is then read backwards from the highest root: as all parents
+// of a node are visited before the node, the bits of the reachable nodes are set in a single pass.
+func readSubtrieLeaves(filepath string, roots []uint64, checksum uint32, logger *zerolog.Logger) (
+	leaves []byte,
+	errToReturn error,
+) {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file %v: %w", filepath, err)
+	}
+	defer func(file *os.File) {
+		evictErr := evictFileFromLinuxPageCache(file, false, logger)
+		if evictErr != nil {
+			logger.Warn().Msgf("failed to evict subtrie file %s from Linux page cache: %s", filepath, evictErr)
+		}
+		errToReturn = closeAndMergeError(file, errToReturn)
+	}(f)
+
+	err = validateFileHeader(MagicBytesCheckpointSubtrie, VersionV6, f)
+	if err != nil {
+		return nil, err
+	}
+
+	nodesCount, expectedSum, err := readSubTriesFooter(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read sub trie node count: %w", err)
+	}
+
+	if checksum != expectedSum {
+		return nil, fmt.Errorf("mismatch checksum in subtrie file. checksum from checkpoint header %v does not "+
+			"match with the checksum in subtrie file %v", checksum, expectedSum)
+	}
+
+	// the children of a node are stored before the node, so only the nodes
+	// up to the highest subtrie root are needed
+	maxIndex := uint64(0)
+	for _, root := range roots {
+		if root > maxIndex {
+			maxIndex = root
+		}
+	}
+	if maxIndex > nodesCount {
+		return nil, fmt.Errorf("subtrie root index %v exceeds node count %v", maxIndex, nodesCount)
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("cannot seek to start of file: %w", err)
+	}
+
+	indices, err := os.CreateTemp("", "checkpoint-node-indices-*")
+	if err != nil {
+		return nil, fmt.Errorf("could not create node indices file: %w", err)
+	}
+	defer func(file *os.File) {
+		errToReturn = closeAndMergeError(file, errToReturn)
+		removeErr := os.Remove(file.Name())
+		if removeErr != nil {
+			logger.Warn().Msgf("failed to remove node indices file %s: %s", file.Name(), removeErr)
+		}
+	}(indices)
+
+	reader := NewCRC32Reader(bufio.NewReaderSize(f, defaultBufioReadSize))
+	writer := bufio.NewWriterSize(indices, defaultBufioWriteSize)
+
+	_, _, err = readFileHeader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("could not read version again for subtrie: %w", err)
+	}
+
+	scratch := make([]byte, 1024*4)
+	logging := logProgress("reading sub trie node indices", int(nodesCount), logger)
+
+	for i := uint64(1); i <= nodesCount; i++ {
+		isLeaf, lchildIndex, rchildIndex, err := flattener.ReadNodeChildIndices(reader, scratch)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read node %d: %w", i, err)
+		}
+		if lchildIndex >= i || rchildIndex >= i {
+			return nil, fmt.Errorf("sequence of serialized nodes does not satisfy Descendents-First-Relationship")
+		}
+		// remaining nodes are still read to verify the checksum
+		if i <= maxIndex {
+			_, err = writer.Write(encodeNodeIndices(scratch[:0], isLeaf, lchildIndex, rchildIndex))
+			if err != nil {
+				return nil, fmt.Errorf("cannot write indices of node %d: %w", i, err)
+			}
+		}
+		logging(i)
+	}
+
+	// read footer and discard, since we only care about checksum
+	_, err = io.ReadFull(reader, scratch[:encNodeCountSize])
+	if err != nil {
+		return nil, fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	actualSum := reader.Crc32()
+	if actualSum != expectedSum {
+		return nil, fmt.Errorf("invalid checksum in subtrie checkpoint, expected %v, actual %v",
+			expectedSum, actualSum)
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return nil, fmt.Errorf("cannot write node indices: %w", err)
+	}
+
+	// leaves[i] is set for the nodes found below the roots, and cleared again for interim nodes
+	leaves = bitutils.MakeBitVector(int(maxIndex) + 1)
+	for _, root := range roots {
+		bitutils.SetBit(leaves, int(root))
+	}
+
+	buf := make([]byte, defaultBufioReadSize-defaultBufioReadSize%encNodeIndicesSize)
+	batchSize := uint64(len(buf) / encNodeIndicesSize)
+
+	for last := maxIndex; last > 0; {
+		first := uint64(1)
+		if last > batchSize {
+			first = last - batchSize + 1
+		}
+
+		batch := buf[:(last-first+1)*encNodeIndicesSize]
+		_, err = indices.ReadAt(batch, int64(first-1)*encNodeIndicesSize)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read indices of nodes %d to %d: %w", first, last, err)
+		}
+
+		for i := last; i >= first; i-- {
+			if bitutils.ReadBit(leaves, int(i)) == 0 {
+				continue
+			}
+
+			isLeaf, lchildIndex, rchildIndex := decodeNodeIndices(batch[(i-first)*encNodeIndicesSize:])
+			if isLeaf {
+				continue
+			}
+
+			bitutils.ClearBit(leaves, int(i))
+			if lchildIndex != 0 {
+				bitutils.SetBit(leaves, int(lchildIndex))
+			}
+			if rchildIndex != 0 {
+				bitutils.SetBit(leaves, int(rchildIndex))
+			}
+		}
+
+		last = first - 1
+	}
+
+	return leaves, nil
+}
+
+func encodeNodeIndices(buf []byte, isLeaf bool, lchildIndex uint64, rchildIndex uint64) []byte {
+	var flag byte
+	if isLeaf {
+		flag = 1
+	}
+	buf = append(buf, flag)
+	buf = binary.BigEndian.AppendUint64(buf, lchildIndex)
+	return binary.BigEndian.AppendUint64(buf, rchildIndex)
+}
+
+func decodeNodeIndices(buf []byte) (isLeaf bool, lchildIndex uint64, rchildIndex uint64) {
+	return buf[0] == 1, binary.BigEndian.Uint64(buf[1:9]), binary.BigEndian.Uint64(buf[9:17])
+}
@@ -0,0 +1,174 @@
+package wal
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// trieLeaves returns the payloads of the leaves of the trie matching the filter, by path
+func trieLeaves(t *testing.T, tr *trie.MTrie, match func(ledger.Path, *ledger.Payload) bool) map[ledger.Path]*ledger.Payload {
+	leaves := make(map[ledger.Path]*ledger.Payload)
+	for itr := flattener.NewNodeIterator(tr.RootNode()); itr.Next(); {
+		n := itr.Value()
+		if !n.IsLeaf() {
+			continue
+		}
+		payload, err := n.Payload()
+		require.NoError(t, err)
+		if match(*n.Path(), payload) {
+			leaves[*n.Path()] = payload
+		}
+	}
+	return leaves
+}
+
+// iterateLeaves returns the payloads of the leaves read by the iterator, by path
+func iterateLeaves(t *testing.T, it *TrieLeafIterator) map[ledger.Path]*ledger.Payload {
+	leaves := make(map[ledger.Path]*ledger.Payload)
+	for it.Next() {
+		path, payload := it.Value()
+		_, ok := leaves[path]
+		require.False(t, ok, "leaf %v read twice", path)
+		leaves[path] = payload
+	}
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	return leaves
+}
+
+func requireLeavesEqual(t *testing.T, expected, actual map[ledger.Path]*ledger.Payload) {
+	require.Equal(t, len(expected), len(actual))
+	for path, payload := range expected {
+		actualPayload, ok := actual[path]
+		require.True(t, ok, "leaf %v is missing", path)
+		require.True(t, payload.Equals(actualPayload), "payload of leaf %v is different", path)
+	}
+}
+
+func TestTrieLeafIteratorV6(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		logger := unittest.Logger()
+		fileName := "checkpoint"
+
+		tries := append(createSimpleTrie(t), createMultipleRandomTries(t)...)
+		require.NoError(t, StoreCheckpointV6Concurrently(tries, dir, fileName, &logger))
+
+		all := func(ledger.Path, *ledger.Payload) bool { return true }
+
+		t.Run("all leaves", func(t *testing.T) {
+			for _, tr := range []*trie.MTrie{tries[0], tries[1], tries[50], tries[len(tries)-1]} {
+				it, err := OpenTrieLeafIteratorV6(dir, fileName, tr.RootHash(), LeafFilter{}, &logger)
+				require.NoError(t, err)
+				requireLeavesEqual(t, trieLeaves(t, tr, all), iterateLeaves(t, it))
+			}
+		})
+
+		t.Run("path prefixes", func(t *testing.T) {
+			tr := tries[len(tries)-1]
+
+			// prefixes shorter and longer than the subtrie level
+			prefixes := [][]byte{{0x12}, {0x80}, {0xff, 0x01}}
+			filter := LeafFilter{PathPrefixes: prefixes}
+			match := func(path ledger.Path, _ *ledger.Payload) bool {
+				for _, prefix := range prefixes {
+					if bytes.HasPrefix(path[:], prefix) {
+						return true
+					}
+				}
+				return false
+			}
+
+			it, err := OpenTrieLeafIteratorV6(dir, fileName, tr.RootHash(), filter, &logger)
+			require.NoError(t, err)
+
+			// only the subtrie part files with matching paths are read, which are
+			// the part files 0b0001, 0b1000, and 0b1111
+			require.Len(t, it.sources, 3)
+
+			requireLeavesEqual(t, trieLeaves(t, tr, match), iterateLeaves(t, it))
+
+			// an empty prefix matches all leaves
+			it, err = OpenTrieLeafIteratorV6(dir, fileName, tr.RootHash(), LeafFilter{PathPrefixes: [][]byte{{}}}, &logger)
+			require.NoError(t, err)
+			requireLeavesEqual(t, trieLeaves(t, tr, all), iterateLeaves(t, it))
+		})
+
+		t.Run("owners", func(t *testing.T) {
+			tr := tries[len(tries)-1]
+
+			var owners [][]byte
+			for _, payload := range trieLeaves(t, tr, all) {
+				key, err := payload.Key()
+				require.NoError(t, err)
+				owners = append(owners, key.KeyParts[0].Value)
+				if len(owners) == 3 {
+					break
+				}
+			}
+
+			match := func(_ ledger.Path, payload *ledger.Payload) bool {
+				key, err := payload.Key()
+				require.NoError(t, err)
+				for _, owner := range owners {
+					if bytes.Equal(key.KeyParts[0].Value, owner) {
+						return true
+					}
+				}
+				return false
+			}
+
+			it, err := OpenTrieLeafIteratorV6(dir, fileName, tr.RootHash(), LeafFilter{Owners: owners}, &logger)
+			require.NoError(t, err)
+
+			expected := trieLeaves(t, tr, match)
+			require.Len(t, expected, len(owners))
+			requireLeavesEqual(t, expected, iterateLeaves(t, it))
+		})
+
+		t.Run("root hashes", func(t *testing.T) {
+			rootHashes, err := ReadTrieRootHashesV6(dir, fileName, &logger)
+			require.NoError(t, err)
+			require.Len(t, rootHashes, len(tries))
+			for i, tr := range tries {
+				require.Equal(t, tr.RootHash(), rootHashes[i])
+			}
+
+			isV6, err := IsCheckpointV6(filepath.Join(dir, fileName))
+			require.NoError(t, err)
+			require.True(t, isV6)
+		})
+
+		t.Run("load trie", func(t *testing.T) {
+			tr := tries[len(tries)-1]
+			loaded, err := LoadTrieV6(dir, fileName, tr.RootHash(), &logger)
+			require.NoError(t, err)
+			require.Equal(t, tr.RootHash(), loaded.RootHash())
+			require.Equal(t, tr.AllocatedRegCount(), loaded.AllocatedRegCount())
+
+			_, err = LoadTrieV6(dir, fileName, ledger.RootHash(unittest.StateCommitmentFixture()), &logger)
+			require.ErrorIs(t, err, ErrTrieNotFound)
+		})
+
+		t.Run("trie not found", func(t *testing.T) {
+			_, err := OpenTrieLeafIteratorV6(dir, fileName, ledger.RootHash(unittest.StateCommitmentFixture()), LeafFilter{}, &logger)
+			require.ErrorIs(t, err, ErrTrieNotFound)
+		})
+
+		t.Run("close before all leaves were read", func(t *testing.T) {
+			it, err := OpenTrieLeafIteratorV6(dir, fileName, tries[len(tries)-1].RootHash(), LeafFilter{}, &logger)
+			require.NoError(t, err)
+
+			require.True(t, it.Next())
+			require.NoError(t, it.Close())
+			require.False(t, it.Next())
+		})
+	})
+}
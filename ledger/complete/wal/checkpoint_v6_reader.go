@@ -31,7 +31,10 @@ var ErrEOFNotReached = errors.New("expect to reach EOF, but actually didn't")
 // it returns (nil, os.ErrNotExist) if a certain file is missing, use (os.IsNotExist to check)
 // it returns (nil, ErrEOFNotReached) if a certain part file is malformed
 // it returns (nil, err) if running into any exception
-func readCheckpointV6(headerFile *os.File, logger *zerolog.Logger) ([]*trie.MTrie, error) {
+//
+// If the payload storage is not nil, the payloads of the leaves are moved to the storage while
+// the checkpoint is read, and the returned tries are marked as tries with stored payloads.
+func readCheckpointV6(headerFile *os.File, logger *zerolog.Logger, payloads node.PayloadStorage) ([]*trie.MTrie, error) {
 	// the full path of header file
 	headerPath := headerFile.Name()
 	dir, fileName := filepath.Split(headerPath)
@@ -53,7 +56,7 @@ func readCheckpointV6(headerFile *os.File, logger *zerolog.Logger) ([]*trie.MTri
 
 	// TODO making number of goroutine configable for reading subtries, which can help us
 	// test the code on machines that don't have as much RAM as EN by using fewer goroutines.
-	subtrieNodes, err := readSubTriesConcurrently(dir, fileName, subtrieChecksums, &lg)
+	subtrieNodes, err := readSubTriesConcurrently(dir, fileName, subtrieChecksums, &lg, payloads)
 	if err != nil {
 		return nil, fmt.Errorf("could not read subtrie from dir: %w", err)
 	}
@@ -61,7 +64,7 @@ func readCheckpointV6(headerFile *os.File, logger *zerolog.Logger) ([]*trie.MTri
 	lg.Info().Uint32("topsum", topTrieChecksum).
 		Msg("finish reading all v6 subtrie files, start reading top level tries")
 
-	tries, err := readTopLevelTries(dir, fileName, subtrieNodes, topTrieChecksum, &lg)
+	tries, err := readTopLevelTries(dir, fileName, subtrieNodes, topTrieChecksum, &lg, payloads)
 	if err != nil {
 		return nil, fmt.Errorf("could not read top level nodes or tries: %w", err)
 	}
@@ -97,7 +100,7 @@ func OpenAndReadCheckpointV6(dir string, fileName string, logger *zerolog.Logger
 		errToReturn = closeAndMergeError(file, errToReturn)
 	}(f)
 
-	return readCheckpointV6(f, logger)
+	return readCheckpointV6(f, logger, nil)
 }
 
 func filePathCheckpointHeader(dir string, fileName string) string {
@@ -278,7 +281,13 @@ type resultReadSubTrie struct {
 	Err   error
 }
 
-func readSubTriesConcurrently(dir string, fileName string, subtrieChecksums []uint32, logger *zerolog.Logger) ([][]*node.Node, error) {
+func readSubTriesConcurrently(
+	dir string,
+	fileName string,
+	subtrieChecksums []uint32,
+	logger *zerolog.Logger,
+	payloads node.PayloadStorage,
+) ([][]*node.Node, error) {
 
 	numOfSubTries := len(subtrieChecksums)
 	jobs := make(chan jobReadSubtrie, numOfSubTries)
@@ -301,7 +310,7 @@ func readSubTriesConcurrently(dir string, fileName string, subtrieChecksums []ui
 	for i := 0; i < nWorker; i++ {
 		go func() {
 			for job := range jobs {
-				nodes, err := readCheckpointSubTrie(dir, fileName, job.Index, job.Checksum, logger)
+				nodes, err := readCheckpointSubTrie(dir, fileName, job.Index, job.Checksum, logger, payloads)
 				job.Result <- &resultReadSubTrie{
 					Nodes: nodes,
 					Err:   err,
@@ -330,7 +339,14 @@ func readSubTriesConcurrently(dir string, fileName string, subtrieChecksums []ui
 // 2. nodes
 // 3. node count
 // 4. checksum
-func readCheckpointSubTrie(dir string, fileName string, index int, checksum uint32, logger *zerolog.Logger) (
+func readCheckpointSubTrie(
+	dir string,
+	fileName string,
+	index int,
+	checksum uint32,
+	logger *zerolog.Logger,
+	payloads node.PayloadStorage,
+) (
 	subtrieRootNodes []*node.Node,
 	errToReturn error,
 ) {
@@ -386,6 +402,9 @@ func readCheckpointSubTrie(dir string, fileName string, index int, checksum uint
 	scratch := make([]byte, 1024*4) // must not be less than 1024
 	logging := logProgress(fmt.Sprintf("reading %v-th sub trie roots", index), int(nodesCount), logger)
 
+	// each part file is read by its own goroutine, so each has its own batch
+	batch := newPayloadBatch(payloads)
+
 	nodes := make([]*node.Node, nodesCount+1) //+1 for 0 index meaning nil
 	for i := uint64(1); i <= nodesCount; i++ {
 		node, err := flattener.ReadNode(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
@@ -397,10 +416,19 @@ func readCheckpointSubTrie(dir string, fileName string, index int, checksum uint
 		if err != nil {
 			return nil, fmt.Errorf("cannot read node %d: %w", i, err)
 		}
+		err = batch.add(node)
+		if err != nil {
+			return nil, fmt.Errorf("cannot store payload of node %d: %w", i, err)
+		}
 		nodes[i] = node
 		logging(i)
 	}
 
+	err = batch.flush()
+	if err != nil {
+		return nil, fmt.Errorf("cannot store payloads of %v-th subtrie: %w", index, err)
+	}
+
 	// read footer and discard, since we only care about checksum
 	_, err = io.ReadFull(reader, scratch[:encNodeCountSize])
 	if err != nil {
@@ -468,7 +496,14 @@ func readSubTriesFooter(f *os.File) (uint64, uint32, error) {
 // 5. node count
 // 6. trie count
 // 7. checksum
-func readTopLevelTries(dir string, fileName string, subtrieNodes [][]*node.Node, topTrieChecksum uint32, logger *zerolog.Logger) (
+func readTopLevelTries(
+	dir string,
+	fileName string,
+	subtrieNodes [][]*node.Node,
+	topTrieChecksum uint32,
+	logger *zerolog.Logger,
+	payloads node.PayloadStorage,
+) (
 	rootTries []*trie.MTrie,
 	errToReturn error,
 ) {
@@ -546,6 +581,9 @@ func readTopLevelTries(dir string, fileName string, subtrieNodes [][]*node.Node,
 	// be large enough to handle almost all payloads and 100% of interim nodes.
 	scratch := make([]byte, 1024*4) // must not be less than 1024
 
+	// the top level tries can contain leaves, if a subtrie holds a single register
+	batch := newPayloadBatch(payloads)
+
 	// read the nodes from subtrie level to the root level
 	for i := uint64(1); i <= topLevelNodesCount; i++ {
 		node, err := flattener.ReadNode(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
@@ -558,10 +596,19 @@ func readTopLevelTries(dir string, fileName string, subtrieNodes [][]*node.Node,
 		if err != nil {
 			return nil, fmt.Errorf("cannot read node at index %d: %w", i, err)
 		}
+		err = batch.add(node)
+		if err != nil {
+			return nil, fmt.Errorf("cannot store payload of node at index %d: %w", i, err)
+		}
 
 		topLevelNodes[i] = node
 	}
 
+	err = batch.flush()
+	if err != nil {
+		return nil, fmt.Errorf("cannot store payloads of top level nodes: %w", err)
+	}
+
 	// read the trie root nodes
 	for i := uint16(0); i < triesCount; i++ {
 		trie, err := flattener.ReadTrie(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
@@ -598,7 +645,7 @@ func readTopLevelTries(dir string, fileName string, subtrieNodes [][]*node.Node,
 		return nil, fmt.Errorf("fail to read top trie file: %w", err)
 	}
 
-	return tries, nil
+	return batch.withStoredPayloads(tries)
 }
 
 func readFileHeader(reader io.Reader) (uint16, uint16, error) {
@@ -175,7 +175,7 @@ func TestEncodeSubTrie(t *testing.T) {
 				uniqueIndices, nodeCount, checksum)
 
 			// all the nodes
-			nodes, err := readCheckpointSubTrie(dir, file, index, checksum, &logger)
+			nodes, err := readCheckpointSubTrie(dir, file, index, checksum, &logger, nil)
 			require.NoError(t, err)
 
 			for _, root := range roots {
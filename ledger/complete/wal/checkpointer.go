@@ -230,7 +230,7 @@ func (c *Checkpointer) Checkpoint(to int) (err error) {
 			return err
 		}, func(rootHash ledger.RootHash) error {
 			return nil
-		}, true)
+		}, true, nil)
 
 	if err != nil {
 		return fmt.Errorf("cannot replay WAL: %w", err)
@@ -566,8 +566,11 @@ func storeUniqueNodes(
 			}
 		}
 
-		encNode := flattener.EncodeNode(n, lchildIndex, rchildIndex, scratch)
-		_, err := writer.Write(encNode)
+		encNode, err := flattener.EncodeNode(n, lchildIndex, rchildIndex, scratch)
+		if err != nil {
+			return 0, fmt.Errorf("cannot encode node: %w", err)
+		}
+		_, err = writer.Write(encNode)
 		if err != nil {
 			return 0, fmt.Errorf("cannot serialize node: %w", err)
 		}
@@ -605,13 +608,31 @@ func getNodesAtLevel(root *node.Node, level uint) []*node.Node {
 }
 
 func (c *Checkpointer) LoadCheckpoint(checkpoint int) ([]*trie.MTrie, error) {
+	return c.loadCheckpoint(checkpoint, nil)
+}
+
+// loadCheckpoint loads the given checkpoint, moving the payloads to the given storage while reading
+// the checkpoint, unless the storage is nil (see LoadCheckpointWithPayloadStorage).
+func (c *Checkpointer) loadCheckpoint(checkpoint int, payloads node.PayloadStorage) ([]*trie.MTrie, error) {
 	filepath := path.Join(c.dir, NumberToFilename(checkpoint))
-	return LoadCheckpoint(filepath, &c.wal.log)
+	return LoadCheckpointWithPayloadStorage(filepath, &c.wal.log, payloads)
+}
+
+// CheckpointBase returns the number of the base checkpoint of the given checkpoint,
+// and false if the checkpoint is not a delta checkpoint.
+func (c *Checkpointer) CheckpointBase(checkpoint int) (int, bool, error) {
+	return DeltaCheckpointBase(path.Join(c.dir, NumberToFilename(checkpoint)))
 }
 
 func (c *Checkpointer) LoadRootCheckpoint() ([]*trie.MTrie, error) {
+	return c.loadRootCheckpoint(nil)
+}
+
+// loadRootCheckpoint loads the root checkpoint, moving the payloads to the given storage while reading
+// the checkpoint, unless the storage is nil (see LoadCheckpointWithPayloadStorage).
+func (c *Checkpointer) loadRootCheckpoint(payloads node.PayloadStorage) ([]*trie.MTrie, error) {
 	filepath := path.Join(c.dir, bootstrap.FilenameWALRootCheckpoint)
-	return LoadCheckpoint(filepath, &c.wal.log)
+	return LoadCheckpointWithPayloadStorage(filepath, &c.wal.log, payloads)
 }
 
 func (c *Checkpointer) HasRootCheckpoint() (bool, error) {
@@ -633,7 +654,17 @@ func (c *Checkpointer) RemoveCheckpoint(checkpoint int) error {
 	return deleteCheckpointFiles(c.dir, name)
 }
 
-func LoadCheckpoint(filepath string, logger *zerolog.Logger) (
+func LoadCheckpoint(filepath string, logger *zerolog.Logger) ([]*trie.MTrie, error) {
+	return LoadCheckpointWithPayloadStorage(filepath, logger, nil)
+}
+
+// LoadCheckpointWithPayloadStorage loads the checkpoint like LoadCheckpoint, and moves the payloads of
+// the leaves to the given storage while the checkpoint is read, so that the payloads of all tries are
+// never held in memory at once. The returned tries are marked as tries whose payloads are stored.
+// Only V6 and delta checkpoints are read this way. The tries of checkpoints in older versions keep
+// their payloads in memory, which are stored once the tries are added to the forest.
+// The payloads are kept in memory if the storage is nil.
+func LoadCheckpointWithPayloadStorage(filepath string, logger *zerolog.Logger, payloads node.PayloadStorage) (
 	tries []*trie.MTrie,
 	errToReturn error) {
 	file, err := os.Open(filepath)
@@ -650,10 +681,10 @@ func LoadCheckpoint(filepath string, logger *zerolog.Logger) (
 		errToReturn = closeAndMergeError(file, errToReturn)
 	}()
 
-	return readCheckpoint(file, logger)
+	return readCheckpoint(file, logger, payloads)
 }
 
-func readCheckpoint(f *os.File, logger *zerolog.Logger) ([]*trie.MTrie, error) {
+func readCheckpoint(f *os.File, logger *zerolog.Logger, payloads node.PayloadStorage) ([]*trie.MTrie, error) {
 
 	// Read header: magic (2 bytes) + version (2 bytes)
 	header := make([]byte, headerSize)
@@ -672,6 +703,10 @@ func readCheckpoint(f *os.File, logger *zerolog.Logger) ([]*trie.MTrie, error) {
 		return nil, fmt.Errorf("cannot seek to start of file: %w", err)
 	}
 
+	if magicBytes == MagicBytesCheckpointDelta {
+		return readDeltaCheckpoint(f, logger, payloads)
+	}
+
 	if magicBytes != MagicBytesCheckpointHeader {
 		return nil, fmt.Errorf("unknown file format. Magic constant %x does not match expected %x", magicBytes, MagicBytesCheckpointHeader)
 	}
@@ -684,7 +719,7 @@ func readCheckpoint(f *os.File, logger *zerolog.Logger) ([]*trie.MTrie, error) {
 	case VersionV5:
 		return readCheckpointV5(f, logger)
 	case VersionV6:
-		return readCheckpointV6(f, logger)
+		return readCheckpointV6(f, logger, payloads)
 	default:
 		return nil, fmt.Errorf("unsupported file version %x", version)
 	}
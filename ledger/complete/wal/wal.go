@@ -10,6 +10,7 @@ import (
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/module"
 )
@@ -86,8 +87,15 @@ func (w *DiskWAL) RecordDelete(rootHash ledger.RootHash) error {
 	return nil
 }
 
+// ReplayOnForest rebuilds the tries of the forest from the checkpoint and WAL segments.
+// If the forest stores the payloads of the leaves, the payloads are moved to the storage
+// while the checkpoint is read.
 func (w *DiskWAL) ReplayOnForest(forest *mtrie.Forest) error {
-	return w.Replay(
+	from, to, err := w.Segments()
+	if err != nil {
+		return fmt.Errorf("could not find segments: %w", err)
+	}
+	err = w.replay(from, to,
 		func(tries []*trie.MTrie) error {
 			err := forest.AddTries(tries)
 			if err != nil {
@@ -102,7 +110,13 @@ func (w *DiskWAL) ReplayOnForest(forest *mtrie.Forest) error {
 		func(rootHash ledger.RootHash) error {
 			return nil
 		},
+		true,
+		forest.PayloadStorage(),
 	)
+	if err != nil {
+		return fmt.Errorf("could not replay segments [%v:%v]: %w", from, to, err)
+	}
+	return nil
 }
 
 func (w *DiskWAL) Segments() (first, last int, err error) {
@@ -118,7 +132,7 @@ func (w *DiskWAL) Replay(
 	if err != nil {
 		return fmt.Errorf("could not find segments: %w", err)
 	}
-	err = w.replay(from, to, checkpointFn, updateFn, deleteFn, true)
+	err = w.replay(from, to, checkpointFn, updateFn, deleteFn, true, nil)
 	if err != nil {
 		return fmt.Errorf("could not replay segments [%v:%v]: %w", from, to, err)
 	}
@@ -134,7 +148,7 @@ func (w *DiskWAL) ReplayLogsOnly(
 	if err != nil {
 		return fmt.Errorf("could not find segments: %w", err)
 	}
-	err = w.replay(from, to, checkpointFn, updateFn, deleteFn, false)
+	err = w.replay(from, to, checkpointFn, updateFn, deleteFn, false, nil)
 	if err != nil {
 		return fmt.Errorf("could not replay WAL only for segments [%v:%v]: %w", from, to, err)
 	}
@@ -147,6 +161,7 @@ func (w *DiskWAL) replay(
 	updateFn func(update *ledger.TrieUpdate) error,
 	deleteFn func(rootHash ledger.RootHash) error,
 	useCheckpoints bool,
+	payloads node.PayloadStorage,
 ) error {
 
 	w.log.Info().Msgf("loading checkpoint with WAL from %d to %d", from, to)
@@ -185,7 +200,7 @@ func (w *DiskWAL) replay(
 
 			w.log.Info().Int("checkpoint", latestCheckpoint).Msg("loading checkpoint")
 
-			forestSequencing, err := checkpointer.LoadCheckpoint(latestCheckpoint)
+			forestSequencing, err := checkpointer.loadCheckpoint(latestCheckpoint, payloads)
 			if err != nil {
 				w.log.Warn().Int("checkpoint", latestCheckpoint).Err(err).
 					Msg("checkpoint loading failed")
@@ -222,7 +237,7 @@ func (w *DiskWAL) replay(
 		if hasRootCheckpoint {
 			w.log.Info().Msgf("loading root checkpoint")
 
-			flattenedForest, err := checkpointer.LoadRootCheckpoint()
+			flattenedForest, err := checkpointer.loadRootCheckpoint(payloads)
 			if err != nil {
 				return fmt.Errorf("cannot load root checkpoint: %w", err)
 			}
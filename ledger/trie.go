@@ -158,6 +158,39 @@ func (u *TrieUpdate) Equals(other *TrieUpdate) bool {
 	return true
 }
 
+// TrieDiff holds the registers, whose payloads differ between two tries.
+// Registers with empty payloads are considered as not allocated.
+type TrieDiff struct {
+	Added    []*Payload             // payloads of registers only allocated in the second trie
+	Removed  []*Payload             // payloads of registers only allocated in the first trie
+	Modified []*PayloadModification // payloads of registers allocated in both tries with different payloads
+}
+
+// PayloadModification captures the payloads of a register in two tries
+type PayloadModification struct {
+	Before *Payload
+	After  *Payload
+}
+
+// NewTrieDiff creates a new instance of an empty TrieDiff
+func NewTrieDiff() *TrieDiff {
+	return &TrieDiff{
+		Added:    make([]*Payload, 0),
+		Removed:  make([]*Payload, 0),
+		Modified: make([]*PayloadModification, 0),
+	}
+}
+
+// Size returns the number of registers, whose payloads differ
+func (d *TrieDiff) Size() int {
+	return len(d.Added) + len(d.Removed) + len(d.Modified)
+}
+
+// IsEmpty returns true if the payloads of all registers are equal
+func (d *TrieDiff) IsEmpty() bool {
+	return d.Size() == 0
+}
+
 // RootHash captures the root hash of a trie
 type RootHash hash.Hash
 
@@ -0,0 +1,66 @@
+package flow
+
+// TransactionRole is the role an account had in a transaction.
+type TransactionRole uint8
+
+const (
+	// TransactionRoleAuthorizer is assigned to accounts that authorized the transaction.
+	TransactionRoleAuthorizer TransactionRole = iota + 1
+	// TransactionRolePayer is assigned to the account that paid for the transaction.
+	TransactionRolePayer
+	// TransactionRoleProposer is assigned to the account that provided the proposal key.
+	TransactionRoleProposer
+	// TransactionRoleInteraction is assigned to accounts with contracts that emitted events
+	// during the transaction.
+	TransactionRoleInteraction
+)
+
+// String returns the string representation of the role.
+func (r TransactionRole) String() string {
+	switch r {
+	case TransactionRoleAuthorizer:
+		return "authorizer"
+	case TransactionRolePayer:
+		return "payer"
+	case TransactionRoleProposer:
+		return "proposer"
+	case TransactionRoleInteraction:
+		return "interaction"
+	default:
+		return "unknown"
+	}
+}
+
+// AccountTransaction links an account to a transaction it was involved in.
+type AccountTransaction struct {
+	Address          Address
+	BlockHeight      uint64
+	TransactionID    Identifier
+	TransactionIndex uint32
+	Roles            []TransactionRole
+}
+
+// AccountTransactionCursor identifies the position of an entry in an account's transaction history.
+// Entries are ordered by block height and transaction index.
+type AccountTransactionCursor struct {
+	BlockHeight      uint64
+	TransactionIndex uint32
+}
+
+// Cursor returns the position of the entry in the account's transaction history.
+func (a AccountTransaction) Cursor() AccountTransactionCursor {
+	return AccountTransactionCursor{
+		BlockHeight:      a.BlockHeight,
+		TransactionIndex: a.TransactionIndex,
+	}
+}
+
+// HasRole returns true if the account had the given role in the transaction.
+func (a AccountTransaction) HasRole(role TransactionRole) bool {
+	for _, r := range a.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
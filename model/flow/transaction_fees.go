@@ -0,0 +1,34 @@
+package flow
+
+// TransactionFeesEstimate is the estimated resource usage and fees of a transaction, obtained by
+// executing the transaction against the execution state as of a block with the maximum gas limit.
+type TransactionFeesEstimate struct {
+	// TransactionID is the ID of the estimated transaction.
+	TransactionID Identifier
+	// BlockID is the ID of the block whose execution state the transaction was executed against.
+	BlockID Identifier
+	// ComputationUsed is the computation metered while executing the transaction.
+	ComputationUsed uint64
+	// ComputationIntensities are the intensities metered per computation kind, keyed by the
+	// Cadence computation kind.
+	ComputationIntensities map[uint]uint
+	// MemoryEstimate is the estimate of the memory used while executing the transaction.
+	MemoryEstimate uint64
+	// StorageBytesRead is the number of bytes the transaction read from the ledger.
+	StorageBytesRead uint64
+	// StorageBytesWritten is the number of bytes the transaction wrote to the ledger.
+	StorageBytesWritten uint64
+	// InclusionFee is the fee charged for the inclusion effort of the transaction, as a UFix64 value.
+	InclusionFee uint64
+	// ExecutionFee is the fee charged for the execution effort of the transaction, as a UFix64 value.
+	ExecutionFee uint64
+	// ErrorCode is the code of the error the transaction failed with, or 0 if it succeeded.
+	ErrorCode uint16
+	// ErrorMessage is the message of the error the transaction failed with, or empty if it succeeded.
+	ErrorMessage string
+}
+
+// TotalFee returns the total fee charged for the transaction, as a UFix64 value.
+func (e TransactionFeesEstimate) TotalFee() uint64 {
+	return e.InclusionFee + e.ExecutionFee
+}
@@ -0,0 +1,23 @@
+package flow
+
+// TransactionSimulationResult is the result of executing a transaction against the execution state
+// as of a block, without submitting the transaction or persisting its changes.
+type TransactionSimulationResult struct {
+	// TransactionID is the ID of the simulated transaction.
+	TransactionID Identifier
+	// BlockID is the ID of the block whose execution state the transaction was executed against.
+	BlockID Identifier
+	// Events are the events emitted by the transaction.
+	Events []Event
+	// ErrorCode is the code of the error the transaction failed with, or 0 if it succeeded.
+	ErrorCode uint16
+	// ErrorMessage is the message of the error the transaction failed with, or empty if it succeeded.
+	ErrorMessage string
+	// ComputationUsed is the computation metered while executing the transaction.
+	ComputationUsed uint64
+	// MemoryEstimate is the estimate of the memory used while executing the transaction.
+	MemoryEstimate uint64
+	// WriteSet contains the registers updated by the transaction, sorted by register ID.
+	WriteSet RegisterEntries
+}
+
@@ -0,0 +1,159 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution/computation"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// ScriptExecutor executes scripts, reads accounts and simulates transactions at a given block height.
+type ScriptExecutor interface {
+	// ExecuteAtBlockHeight executes the script with the given arguments against the state as of
+	// the block height, and returns the json-cdc encoded result.
+	// Expected errors:
+	// - storage.ErrHeightNotIndexed if the state at the height is not available
+	ExecuteAtBlockHeight(ctx context.Context, script []byte, arguments [][]byte, height uint64) ([]byte, error)
+
+	// GetAccountAtBlockHeight returns the account as of the block height.
+	// Expected errors:
+	// - storage.ErrHeightNotIndexed if the state at the height is not available
+	GetAccountAtBlockHeight(ctx context.Context, address flow.Address, height uint64) (*flow.Account, error)
+
+	// SimulateTransactionAtBlockHeight executes the transaction against the state as of the block
+	// height, without persisting its changes. The signature and sequence number checks are skipped
+	// if skipChecks is true.
+	// Expected errors:
+	// - storage.ErrHeightNotIndexed if the state at the height is not available
+	SimulateTransactionAtBlockHeight(ctx context.Context, tx *flow.TransactionBody, height uint64, skipChecks bool) (*flow.TransactionSimulationResult, error)
+
+	// EstimateTransactionFeesAtBlockHeight executes the transaction against the state as of the block
+	// height with the maximum gas limit, and returns its resource usage and fees.
+	// Expected errors:
+	// - storage.ErrHeightNotIndexed if the state at the height is not available
+	EstimateTransactionFeesAtBlockHeight(ctx context.Context, tx *flow.TransactionBody, height uint64) (*flow.TransactionFeesEstimate, error)
+}
+
+// Scripts executes scripts, reads accounts and simulates transactions locally through the FVM, using the register index
+// built from execution data as the source of state.
+type Scripts struct {
+	log       zerolog.Logger
+	manager   computation.ComputationManager
+	headers   storage.Headers
+	registers storage.RegisterIndex
+}
+
+var _ ScriptExecutor = (*Scripts)(nil)
+
+func NewScripts(
+	log zerolog.Logger,
+	manager computation.ComputationManager,
+	headers storage.Headers,
+	registers storage.RegisterIndex,
+) *Scripts {
+	return &Scripts{
+		log:       log.With().Str("module", "local_scripts").Logger(),
+		manager:   manager,
+		headers:   headers,
+		registers: registers,
+	}
+}
+
+// ExecuteAtBlockHeight executes the script against the indexed state as of the block height.
+// Expected errors:
+// - storage.ErrHeightNotIndexed if the state at the height is not indexed
+// - storage.ErrNotFound if no finalized block exists at the height
+func (s *Scripts) ExecuteAtBlockHeight(ctx context.Context, script []byte, arguments [][]byte, height uint64) ([]byte, error) {
+	header, view, err := s.snapshotAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.manager.ExecuteScript(ctx, script, arguments, header, view)
+}
+
+// GetAccountAtBlockHeight returns the account from the indexed state as of the block height.
+// Expected errors:
+// - storage.ErrHeightNotIndexed if the state at the height is not indexed
+// - storage.ErrNotFound if no finalized block exists at the height
+func (s *Scripts) GetAccountAtBlockHeight(ctx context.Context, address flow.Address, height uint64) (*flow.Account, error) {
+	header, view, err := s.snapshotAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.manager.GetAccount(address, header, view)
+}
+
+// SimulateTransactionAtBlockHeight executes the transaction against the indexed state as of the
+// block height, without persisting its changes.
+// Expected errors:
+// - storage.ErrHeightNotIndexed if the state at the height is not indexed
+// - storage.ErrNotFound if no finalized block exists at the height
+func (s *Scripts) SimulateTransactionAtBlockHeight(_ context.Context, tx *flow.TransactionBody, height uint64, skipChecks bool) (*flow.TransactionSimulationResult, error) {
+	header, view, err := s.snapshotAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.manager.SimulateTransaction(tx, header, view, skipChecks)
+}
+
+// EstimateTransactionFeesAtBlockHeight executes the transaction against the indexed state as of
+// the block height with the maximum gas limit, and returns its resource usage and fees.
+// Expected errors:
+// - storage.ErrHeightNotIndexed if the state at the height is not indexed
+// - storage.ErrNotFound if no finalized block exists at the height
+func (s *Scripts) EstimateTransactionFeesAtBlockHeight(_ context.Context, tx *flow.TransactionBody, height uint64) (*flow.TransactionFeesEstimate, error) {
+	header, view, err := s.snapshotAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.manager.EstimateTransactionFees(tx, header, view)
+}
+
+// snapshotAtHeight returns the block header and a read-only view of the indexed state as of the
+// given height.
+func (s *Scripts) snapshotAtHeight(height uint64) (*flow.Header, *delta.View, error) {
+	first, err := s.registers.FirstHeight()
+	if err != nil {
+		return nil, nil, err
+	}
+	latest, err := s.registers.LatestHeight()
+	if err != nil {
+		return nil, nil, err
+	}
+	if height < first || height > latest {
+		return nil, nil, fmt.Errorf("state for height %d is not indexed (indexed range: %d-%d): %w",
+			height, first, latest, storage.ErrHeightNotIndexed)
+	}
+
+	header, err := s.headers.ByHeight(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get header for height %d: %w", height, err)
+	}
+
+	return header, delta.NewView(s.registerReader(height)), nil
+}
+
+// registerReader returns a function that reads register values as of the given height.
+func (s *Scripts) registerReader(height uint64) delta.GetRegisterFunc {
+	return func(owner, key string) (flow.RegisterValue, error) {
+		value, err := s.registers.Get(flow.NewRegisterID(owner, key), height)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				// registers that were never set are empty
+				return nil, nil
+			}
+			return nil, fmt.Errorf("could not get register (%x, %x) at height %d: %w", owner, key, height, err)
+		}
+		return value, nil
+	}
+}
@@ -469,6 +469,16 @@ type AccessMetrics interface {
 
 	// ConnectionFromPoolEvicted tracks the number of times a cached connection is evicted from the cache
 	ConnectionFromPoolEvicted()
+
+	// ScriptResultCacheHit tracks the number of times a script result is served from the script result cache
+	ScriptResultCacheHit()
+
+	// ScriptResultCacheMiss tracks the number of times a cacheable script result is not found in the script result cache
+	ScriptResultCacheMiss()
+
+	// ExecutionNodeHealth reports the tracked latency, error rate and lag behind the sealed height of an execution node,
+	// and whether requests to the node are currently suspended
+	ExecutionNodeHealth(nodeID flow.Identifier, latency time.Duration, errorRate float64, lag uint64, circuitOpen bool)
 }
 
 type ExecutionResultStats struct {
@@ -534,6 +544,22 @@ type ExecutionMetrics interface {
 	// ExecutionScriptExecuted reports the time and memory spent on executing an script
 	ExecutionScriptExecuted(dur time.Duration, compUsed, memoryUsed, memoryEstimate uint64)
 
+	// ExecutionProgramsCacheWarmedUp reports the time spent loading the programs persisted by the
+	// programs cache on startup, and the number of persisted programs which were loaded (hits) or
+	// dropped because their dependencies changed (misses)
+	ExecutionProgramsCacheWarmedUp(dur time.Duration, hits, misses int)
+
+	// ExecutionStatePruned reports the height up to which historical execution state was pruned,
+	// and the time spent pruning it
+	ExecutionStatePruned(height uint64, dur time.Duration)
+
+	// ExecutionScriptRequest reports a script request, which was either admitted or rejected
+	// because its caller exceeded its quota
+	ExecutionScriptRequest(admitted bool)
+
+	// ExecutionScriptsInflight reports the number of admitted scripts being executed
+	ExecutionScriptsInflight(inflight int)
+
 	// ExecutionCollectionRequestSent reports when a request for a collection is sent to a collection node
 	ExecutionCollectionRequestSent()
 
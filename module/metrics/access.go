@@ -1,8 +1,12 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/onflow/flow-go/model/flow"
 )
 
 type AccessCollector struct {
@@ -13,6 +17,12 @@ type AccessCollector struct {
 	connectionInvalidated prometheus.Counter
 	connectionUpdated     prometheus.Counter
 	connectionEvicted     prometheus.Counter
+	scriptCacheHits       prometheus.Counter
+	scriptCacheMisses     prometheus.Counter
+	enLatency             *prometheus.GaugeVec
+	enErrorRate           *prometheus.GaugeVec
+	enLag                 *prometheus.GaugeVec
+	enCircuitOpen         *prometheus.GaugeVec
 }
 
 func NewAccessCollector() *AccessCollector {
@@ -59,6 +69,42 @@ func NewAccessCollector() *AccessCollector {
 			Subsystem: subsystemConnectionPool,
 			Help:      "counter for the number of times a cached connection is evicted from the connection pool",
 		}),
+		scriptCacheHits: promauto.NewCounter(prometheus.CounterOpts{
+			Name:      "hits_total",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemScriptCache,
+			Help:      "counter for the number of script executions served from the script result cache",
+		}),
+		scriptCacheMisses: promauto.NewCounter(prometheus.CounterOpts{
+			Name:      "misses_total",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemScriptCache,
+			Help:      "counter for the number of cacheable script executions not found in the script result cache",
+		}),
+		enLatency: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name:      "latency_seconds",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemExecutionNodeHealth,
+			Help:      "moving average of the latency of requests to an execution node",
+		}, []string{LabelNodeID}),
+		enErrorRate: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name:      "error_rate",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemExecutionNodeHealth,
+			Help:      "moving average of the rate of failed requests to an execution node",
+		}, []string{LabelNodeID}),
+		enLag: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name:      "lag_blocks",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemExecutionNodeHealth,
+			Help:      "number of blocks an execution node lags behind the sealed height",
+		}, []string{LabelNodeID}),
+		enCircuitOpen: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name:      "circuit_open",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemExecutionNodeHealth,
+			Help:      "whether requests to an execution node are suspended (1) or not (0)",
+		}, []string{LabelNodeID}),
 	}
 
 	return ac
@@ -92,3 +138,24 @@ func (ac *AccessCollector) ConnectionFromPoolUpdated() {
 func (ac *AccessCollector) ConnectionFromPoolEvicted() {
 	ac.connectionEvicted.Inc()
 }
+
+func (ac *AccessCollector) ScriptResultCacheHit() {
+	ac.scriptCacheHits.Inc()
+}
+
+func (ac *AccessCollector) ScriptResultCacheMiss() {
+	ac.scriptCacheMisses.Inc()
+}
+
+func (ac *AccessCollector) ExecutionNodeHealth(nodeID flow.Identifier, latency time.Duration, errorRate float64, lag uint64, circuitOpen bool) {
+	id := nodeID.String()
+	ac.enLatency.WithLabelValues(id).Set(latency.Seconds())
+	ac.enErrorRate.WithLabelValues(id).Set(errorRate)
+	ac.enLag.WithLabelValues(id).Set(float64(lag))
+
+	open := 0.0
+	if circuitOpen {
+		open = 1.0
+	}
+	ac.enCircuitOpen.WithLabelValues(id).Set(open)
+}
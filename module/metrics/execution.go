@@ -70,6 +70,13 @@ type ExecutionCollector struct {
 	scriptMemoryUsage                      prometheus.Histogram
 	scriptMemoryEstimate                   prometheus.Histogram
 	scriptMemoryDifference                 prometheus.Histogram
+	programsCacheWarmUpTime                prometheus.Gauge
+	programsCacheWarmUpHits                prometheus.Counter
+	programsCacheWarmUpMisses              prometheus.Counter
+	statePrunedHeight                      prometheus.Gauge
+	statePruneDuration                     prometheus.Histogram
+	scriptRequests                         *prometheus.CounterVec
+	scriptsInflight                        prometheus.Gauge
 	numberOfAccounts                       prometheus.Gauge
 	chunkDataPackRequestProcessedTotal     prometheus.Counter
 	chunkDataPackProofSize                 prometheus.Histogram
@@ -643,6 +650,56 @@ func NewExecutionCollector(tracer module.Tracer) *ExecutionCollector {
 			Help:      "indicates if the state sync is active",
 		}),
 
+		programsCacheWarmUpTime: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "programs_cache_warm_up_time_seconds",
+			Help:      "the time spent loading the programs persisted by the programs cache on startup",
+		}),
+
+		programsCacheWarmUpHits: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "programs_cache_warm_up_hits_total",
+			Help:      "the number of persisted programs loaded on startup",
+		}),
+
+		programsCacheWarmUpMisses: promauto.NewCounter(prometheus.CounterOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "programs_cache_warm_up_misses_total",
+			Help:      "the number of persisted programs dropped on startup, because their dependencies changed or they failed to load",
+		}),
+
+		statePrunedHeight: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemStateStorage,
+			Name:      "pruned_height",
+			Help:      "the height up to which historical execution state was pruned",
+		}),
+
+		statePruneDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemStateStorage,
+			Name:      "prune_duration_ms",
+			Help:      "the duration of pruning historical execution state in milliseconds",
+			Buckets:   []float64{100, 500, 1000, 5000, 10000, 30000, 60000, 300000},
+		}),
+
+		scriptRequests: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "script_requests_total",
+			Help:      "the number of script requests, admitted or rejected because the caller exceeded its quota",
+		}, []string{LabelScriptResult}),
+
+		scriptsInflight: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "scripts_inflight",
+			Help:      "the number of admitted scripts being executed",
+		}),
+
 		numberOfAccounts: promauto.NewGauge(prometheus.GaugeOpts{
 			Namespace: namespaceExecution,
 			Subsystem: subsystemRuntime,
@@ -742,6 +799,36 @@ func (ec *ExecutionCollector) ExecutionScriptExecuted(dur time.Duration, compUse
 	ec.scriptMemoryDifference.Observe(float64(memoryEstimated) - float64(memoryUsed))
 }
 
+// ExecutionProgramsCacheWarmedUp reports the time spent loading the programs persisted by the
+// programs cache on startup, and the number of persisted programs which were loaded or dropped.
+func (ec *ExecutionCollector) ExecutionProgramsCacheWarmedUp(dur time.Duration, hits, misses int) {
+	ec.programsCacheWarmUpTime.Set(dur.Seconds())
+	ec.programsCacheWarmUpHits.Add(float64(hits))
+	ec.programsCacheWarmUpMisses.Add(float64(misses))
+}
+
+// ExecutionStatePruned reports the height up to which historical execution state was pruned,
+// and the time spent pruning it.
+func (ec *ExecutionCollector) ExecutionStatePruned(height uint64, dur time.Duration) {
+	ec.statePrunedHeight.Set(float64(height))
+	ec.statePruneDuration.Observe(float64(dur.Milliseconds()))
+}
+
+// ExecutionScriptRequest reports a script request, which was either admitted or rejected
+// because its caller exceeded its quota.
+func (ec *ExecutionCollector) ExecutionScriptRequest(admitted bool) {
+	result := "admitted"
+	if !admitted {
+		result = "rejected"
+	}
+	ec.scriptRequests.WithLabelValues(result).Inc()
+}
+
+// ExecutionScriptsInflight reports the number of admitted scripts being executed.
+func (ec *ExecutionCollector) ExecutionScriptsInflight(inflight int) {
+	ec.scriptsInflight.Set(float64(inflight))
+}
+
 // ExecutionStateStorageDiskTotal reports the total storage size of the execution state on disk in bytes
 func (ec *ExecutionCollector) ExecutionStateStorageDiskTotal(bytes int64) {
 	ec.stateStorageDiskTotal.Set(float64(bytes))
@@ -131,3 +131,5 @@ const ExecutionDataRequestRetryable = "retryable"
 
 const LabelViolationReason = "reason"
 const LabelRateLimitReason = "reason"
+
+const LabelScriptResult = "result"
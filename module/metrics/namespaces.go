@@ -40,6 +40,8 @@ const (
 	subsystemTransactionTiming     = "transaction_timing"
 	subsystemTransactionSubmission = "transaction_submission"
 	subsystemConnectionPool        = "connection_pool"
+	subsystemScriptCache           = "script_cache"
+	subsystemExecutionNodeHealth   = "execution_node_health"
 )
 
 // Observer subsystem
@@ -125,6 +125,8 @@ func (nc *NoopCollector) NewConnectionEstablished()
 func (nc *NoopCollector) ConnectionFromPoolInvalidated()                                        {}
 func (nc *NoopCollector) ConnectionFromPoolUpdated()                                            {}
 func (nc *NoopCollector) ConnectionFromPoolEvicted()                                            {}
+func (nc *NoopCollector) ScriptResultCacheHit()                                                 {}
+func (nc *NoopCollector) ScriptResultCacheMiss()                                                {}
 func (nc *NoopCollector) StartBlockReceivedToExecuted(blockID flow.Identifier)                  {}
 func (nc *NoopCollector) FinishBlockReceivedToExecuted(blockID flow.Identifier)                 {}
 func (nc *NoopCollector) ExecutionComputationUsedPerBlock(computation uint64)                   {}
@@ -133,11 +135,17 @@ func (nc *NoopCollector) ExecutionLastExecutedBlockHeight(height uint64)
 func (nc *NoopCollector) ExecutionBlockExecuted(_ time.Duration, _ module.ExecutionResultStats) {}
 func (nc *NoopCollector) ExecutionCollectionExecuted(_ time.Duration, _ module.ExecutionResultStats) {
 }
+func (nc *NoopCollector) ExecutionNodeHealth(flow.Identifier, time.Duration, float64, uint64, bool) {
+}
 func (nc *NoopCollector) ExecutionBlockExecutionEffortVectorComponent(_ string, _ uint) {}
 func (nc *NoopCollector) ExecutionTransactionExecuted(_ time.Duration, _, _, _ uint64, _, _ int, _ bool) {
 }
 func (nc *NoopCollector) ExecutionChunkDataPackGenerated(_, _ int)                         {}
 func (nc *NoopCollector) ExecutionScriptExecuted(dur time.Duration, compUsed, _, _ uint64) {}
+func (nc *NoopCollector) ExecutionProgramsCacheWarmedUp(_ time.Duration, _, _ int)         {}
+func (nc *NoopCollector) ExecutionStatePruned(_ uint64, _ time.Duration)                   {}
+func (nc *NoopCollector) ExecutionScriptRequest(_ bool)                                    {}
+func (nc *NoopCollector) ExecutionScriptsInflight(_ int)                                   {}
 func (nc *NoopCollector) ForestApproxMemorySize(bytes uint64)                              {}
 func (nc *NoopCollector) ForestNumberOfTrees(number uint64)                                {}
 func (nc *NoopCollector) LatestTrieRegCount(number uint64)                                 {}
@@ -2,7 +2,12 @@
 
 package mock
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	flow "github.com/onflow/flow-go/model/flow"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
 
 // AccessMetrics is an autogenerated mock type for the AccessMetrics type
 type AccessMetrics struct {
@@ -34,11 +39,26 @@ func (_m *AccessMetrics) ConnectionFromPoolUpdated() {
 	_m.Called()
 }
 
+// ExecutionNodeHealth provides a mock function with given fields: nodeID, latency, errorRate, lag, circuitOpen
+func (_m *AccessMetrics) ExecutionNodeHealth(nodeID flow.Identifier, latency time.Duration, errorRate float64, lag uint64, circuitOpen bool) {
+	_m.Called(nodeID, latency, errorRate, lag, circuitOpen)
+}
+
 // NewConnectionEstablished provides a mock function with given fields:
 func (_m *AccessMetrics) NewConnectionEstablished() {
 	_m.Called()
 }
 
+// ScriptResultCacheHit provides a mock function with given fields:
+func (_m *AccessMetrics) ScriptResultCacheHit() {
+	_m.Called()
+}
+
+// ScriptResultCacheMiss provides a mock function with given fields:
+func (_m *AccessMetrics) ScriptResultCacheMiss() {
+	_m.Called()
+}
+
 // TotalConnectionsInPool provides a mock function with given fields: connectionCount, connectionPoolSize
 func (_m *AccessMetrics) TotalConnectionsInPool(connectionCount uint, connectionPoolSize uint) {
 	_m.Called(connectionCount, connectionPoolSize)
@@ -76,11 +76,31 @@ func (_m *ExecutionMetrics) ExecutionLastExecutedBlockHeight(height uint64) {
 	_m.Called(height)
 }
 
+// ExecutionProgramsCacheWarmedUp provides a mock function with given fields: dur, hits, misses
+func (_m *ExecutionMetrics) ExecutionProgramsCacheWarmedUp(dur time.Duration, hits int, misses int) {
+	_m.Called(dur, hits, misses)
+}
+
 // ExecutionScriptExecuted provides a mock function with given fields: dur, compUsed, memoryUsed, memoryEstimate
 func (_m *ExecutionMetrics) ExecutionScriptExecuted(dur time.Duration, compUsed uint64, memoryUsed uint64, memoryEstimate uint64) {
 	_m.Called(dur, compUsed, memoryUsed, memoryEstimate)
 }
 
+// ExecutionScriptRequest provides a mock function with given fields: admitted
+func (_m *ExecutionMetrics) ExecutionScriptRequest(admitted bool) {
+	_m.Called(admitted)
+}
+
+// ExecutionScriptsInflight provides a mock function with given fields: inflight
+func (_m *ExecutionMetrics) ExecutionScriptsInflight(inflight int) {
+	_m.Called(inflight)
+}
+
+// ExecutionStatePruned provides a mock function with given fields: height, dur
+func (_m *ExecutionMetrics) ExecutionStatePruned(height uint64, dur time.Duration) {
+	_m.Called(height, dur)
+}
+
 // ExecutionStorageStateCommitment provides a mock function with given fields: bytes
 func (_m *ExecutionMetrics) ExecutionStorageStateCommitment(bytes int64) {
 	_m.Called(bytes)
@@ -0,0 +1,164 @@
+package profiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/rs/zerolog"
+	"go.uber.org/multierr"
+	pb "google.golang.org/genproto/googleapis/devtools/cloudprofiler/v2"
+
+	"github.com/onflow/flow-go/engine"
+)
+
+// ProfileCollectorConfig profile collector parameters.
+type ProfileCollectorConfig struct {
+	// Name is the prefix of the names of the written profiles.
+	Name string
+	// ProfileType is the type with which the profiles are uploaded.
+	ProfileType pb.ProfileType
+
+	Dir      string
+	Interval time.Duration
+}
+
+// ProfileCollector collects pprof profiles generated outside of the go
+// runtime (e.g. the computation profiles of executed transactions).  The
+// collected profiles are merged, and every interval the merged profile is
+// written to the profile dir and uploaded, the same way as the profiles of
+// the AutoProfiler.
+type ProfileCollector struct {
+	unit     *engine.Unit
+	log      zerolog.Logger
+	cfg      ProfileCollectorConfig
+	uploader Uploader
+
+	mu sync.Mutex
+	// merged is nil when no profile was collected since the last write.
+	merged *profile.Profile
+}
+
+// NewProfileCollector creates a new ProfileCollector, which writes the
+// collected profiles every interval once it is ready.
+func NewProfileCollector(log zerolog.Logger, uploader Uploader, cfg ProfileCollectorConfig) (*ProfileCollector, error) {
+	err := os.MkdirAll(cfg.Dir, os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("could not create profile dir %v: %w", cfg.Dir, err)
+	}
+
+	return &ProfileCollector{
+		unit:     engine.NewUnit(),
+		log:      log.With().Str("component", "profile_collector").Str("profileName", cfg.Name).Logger(),
+		cfg:      cfg,
+		uploader: uploader,
+	}, nil
+}
+
+// AddProfile merges the profile into the profile that is written next.
+// Profiles which are not compatible with the previously added profiles (e.g.
+// with different sample types) are dropped.
+func (c *ProfileCollector) AddProfile(prof *profile.Profile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.merged == nil {
+		c.merged = prof.Copy()
+		return
+	}
+
+	merged, err := profile.Merge([]*profile.Profile{c.merged, prof})
+	if err != nil {
+		c.log.Warn().Err(err).Msg("failed to merge profile, dropping it")
+		return
+	}
+	c.merged = merged
+}
+
+func (c *ProfileCollector) Ready() <-chan struct{} {
+	c.unit.LaunchPeriodically(c.writeAndUpload, c.cfg.Interval, c.cfg.Interval)
+	return c.unit.Ready()
+}
+
+func (c *ProfileCollector) Done() <-chan struct{} {
+	return c.unit.Done()
+}
+
+// takeProfile returns the merged profile, and resets the collection.  It
+// returns nil if no profile was collected.
+func (c *ProfileCollector) takeProfile() *profile.Profile {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prof := c.merged
+	c.merged = nil
+	return prof
+}
+
+func (c *ProfileCollector) writeAndUpload() {
+	prof := c.takeProfile()
+	if prof == nil {
+		return
+	}
+
+	path := filepath.Join(c.cfg.Dir, fmt.Sprintf("%s-%s", c.cfg.Name, time.Now().Format(time.RFC3339)))
+	logger := c.log.With().Str("profilePath", path).Logger()
+
+	err := c.write(path, prof)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to write profile")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	err = c.uploader.Upload(ctx, path, c.cfg.ProfileType)
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to upload profile")
+		return
+	}
+
+	logger.Info().Int("samples", len(prof.Sample)).Msg("collected profile written")
+}
+
+// write writes the profile to a temp file which is renamed to the given path
+// once complete, so that partially written profiles are never uploaded.
+func (c *ProfileCollector) write(path string, prof *profile.Profile) (err error) {
+	f, err := os.CreateTemp(c.cfg.Dir, "profile")
+	if err != nil {
+		return fmt.Errorf("failed to create temp profile: %w", err)
+	}
+
+	// Remove temp file if it still exists.
+	defer func() {
+		if _, statErr := os.Stat(f.Name()); errors.Is(statErr, os.ErrNotExist) {
+			return
+		}
+		multierr.AppendInto(&err, os.Remove(f.Name()))
+	}()
+
+	err = prof.Write(f)
+	multierr.AppendInto(&err, f.Close())
+	if err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+
+	// default CreateTemp permissions are 0600.
+	err = os.Chmod(f.Name(), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to set profile permissions: %w", err)
+	}
+
+	err = os.Rename(f.Name(), path)
+	if err != nil {
+		return fmt.Errorf("failed to rename profile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package profiler_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	pb "google.golang.org/genproto/googleapis/devtools/cloudprofiler/v2"
+
+	"github.com/onflow/flow-go/module/profiler"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+type recordingUploader struct {
+	mu      sync.Mutex
+	uploads map[string]pb.ProfileType
+}
+
+func (u *recordingUploader) Upload(_ context.Context, filename string, pt pb.ProfileType) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.uploads[filename] = pt
+	return nil
+}
+
+func (u *recordingUploader) files() map[string]pb.ProfileType {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	files := make(map[string]pb.ProfileType, len(u.uploads))
+	for filename, pt := range u.uploads {
+		files[filename] = pt
+	}
+	return files
+}
+
+func testProfile(function string, value int64) *profile.Profile {
+	f := &profile.Function{ID: 1, Name: function}
+	loc := &profile.Location{ID: 1, Line: []profile.Line{{Function: f}}}
+	return &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "computation", Unit: "count"}},
+		PeriodType: &profile.ValueType{Type: "computation", Unit: "count"},
+		Period:     1,
+		Sample: []*profile.Sample{
+			{Location: []*profile.Location{loc}, Value: []int64{value}},
+		},
+		Location: []*profile.Location{loc},
+		Function: []*profile.Function{f},
+	}
+}
+
+func TestProfileCollector(t *testing.T) {
+	unittest.RunWithTempDir(t, func(tempDir string) {
+		uploader := &recordingUploader{uploads: make(map[string]pb.ProfileType)}
+
+		c, err := profiler.NewProfileCollector(
+			zerolog.Nop(),
+			uploader,
+			profiler.ProfileCollectorConfig{
+				Name:        "computation",
+				ProfileType: pb.ProfileType_CPU,
+				Dir:         tempDir,
+				Interval:    100 * time.Millisecond,
+			})
+		require.NoError(t, err)
+
+		c.AddProfile(testProfile("a", 1))
+		c.AddProfile(testProfile("a", 2))
+		c.AddProfile(testProfile("b", 4))
+		// incompatible profiles are dropped
+		c.AddProfile(&profile.Profile{
+			SampleType: []*profile.ValueType{{Type: "memory", Unit: "bytes"}},
+			PeriodType: &profile.ValueType{Type: "memory", Unit: "bytes"},
+		})
+
+		unittest.AssertClosesBefore(t, c.Ready(), 5*time.Second)
+
+		require.Eventually(t, func() bool {
+			return len(uploader.files()) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		unittest.AssertClosesBefore(t, c.Done(), 5*time.Second)
+
+		// only a single profile is written, as no profile was added after the first write
+		files := uploader.files()
+		require.Len(t, files, 1)
+
+		for filename, pt := range files {
+			require.Equal(t, pb.ProfileType_CPU, pt)
+			require.True(t, strings.HasPrefix(filename, tempDir+"/computation-"))
+
+			f, err := os.Open(filename)
+			require.NoError(t, err)
+			defer f.Close()
+
+			prof, err := profile.Parse(f)
+			require.NoError(t, err)
+
+			values := make(map[string]int64)
+			for _, sample := range prof.Sample {
+				values[sample.Location[0].Line[0].Function.Name] += sample.Value[0]
+			}
+			require.Equal(t, map[string]int64{"a": 3, "b": 4}, values)
+		}
+
+		// the temp files are removed
+		dirEnts, err := os.ReadDir(tempDir)
+		require.NoError(t, err)
+		require.Len(t, dirEnts, 1)
+	})
+}
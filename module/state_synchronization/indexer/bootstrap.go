@@ -0,0 +1,114 @@
+package indexer
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// bootstrapBatchSize is the number of registers stored at once while bootstrapping the register index
+const bootstrapBatchSize = 1_000
+
+// RegisterBootstrapper is implemented by register indexes that can be initialized with the full
+// register set at a first height.
+type RegisterBootstrapper interface {
+	// IsBootstrapped returns whether the index was bootstrapped.
+	IsBootstrapped() bool
+
+	// StoreBootstrapEntries stores a part of the register set the index is bootstrapped with
+	// at the given height.
+	StoreBootstrapEntries(height uint64, entries flow.RegisterEntries) error
+
+	// FinishBootstrap marks the index as bootstrapped at the given height, once all registers
+	// were stored.
+	FinishBootstrap(height uint64) error
+}
+
+// BootstrapFromCheckpoint initializes the register index with all registers of the trie with the
+// given root hash from the V6 checkpoint file. Only the leaves of that trie are read from the
+// checkpoint, the other tries aren't loaded. The registers are indexed at the given height, which
+// must be the height of the block whose sealed state commitment is the root hash.
+//
+// The registers are stored in batches while they are read, so the register set is never held in
+// memory. The index is only marked as bootstrapped after the last batch was stored. If bootstrapping
+// is interrupted, the index isn't bootstrapped on the next start, and bootstrapping is retried. The
+// retry must use the same root hash and height, as registers stored before are not removed.
+//
+// If the index was already bootstrapped, this is a no-op.
+// No errors are expected during normal operation.
+func BootstrapFromCheckpoint(
+	log zerolog.Logger,
+	registers RegisterBootstrapper,
+	checkpointFile string,
+	rootHash ledger.RootHash,
+	height uint64,
+) error {
+	if registers.IsBootstrapped() {
+		return nil
+	}
+
+	lg := log.With().
+		Str("checkpoint_file", checkpointFile).
+		Str("root_hash", rootHash.String()).
+		Uint64("height", height).
+		Logger()
+
+	lg.Info().Msg("bootstrapping register index from checkpoint")
+	start := time.Now()
+
+	// only read the leaves of the requested trie, instead of loading all tries of the checkpoint
+	dir, fileName := filepath.Split(checkpointFile)
+	it, err := wal.OpenTrieLeafIteratorV6(dir, fileName, rootHash, wal.LeafFilter{}, &lg)
+	if err != nil {
+		return fmt.Errorf("could not open checkpoint: %w", err)
+	}
+	defer it.Close()
+
+	count := 0
+	batch := make(flow.RegisterEntries, 0, bootstrapBatchSize)
+	for it.Next() {
+		_, payload := it.Value()
+		id, value, err := payloadToRegister(payload)
+		if err != nil {
+			return fmt.Errorf("could not convert payload: %w", err)
+		}
+		batch = append(batch, flow.RegisterEntry{Key: id, Value: value})
+
+		if len(batch) < bootstrapBatchSize {
+			continue
+		}
+		err = registers.StoreBootstrapEntries(height, batch)
+		if err != nil {
+			return fmt.Errorf("could not store registers: %w", err)
+		}
+		count += len(batch)
+		batch = batch[:0]
+	}
+	if it.Err() != nil {
+		return fmt.Errorf("could not read checkpoint: %w", it.Err())
+	}
+
+	err = registers.StoreBootstrapEntries(height, batch)
+	if err != nil {
+		return fmt.Errorf("could not store registers: %w", err)
+	}
+	count += len(batch)
+
+	err = registers.FinishBootstrap(height)
+	if err != nil {
+		return fmt.Errorf("could not bootstrap register index: %w", err)
+	}
+
+	lg.Info().
+		Int("register_count", count).
+		Dur("duration_ms", time.Since(start)).
+		Msg("bootstrapped register index from checkpoint")
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package indexer_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/state_synchronization/indexer"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestBootstrapFromCheckpoint(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		log := unittest.Logger()
+
+		regA := flow.NewRegisterID("owner", "a")
+		regB := flow.NewRegisterID("owner", "b")
+
+		newTrie := func(parent *trie.MTrie, id flow.RegisterID, value []byte) *trie.MTrie {
+			key := state.RegisterIDToKey(id)
+			path, err := pathfinder.KeyToPath(key, complete.DefaultPathFinderVersion)
+			require.NoError(t, err)
+			updated, _, err := trie.NewTrieWithUpdatedRegisters(parent, []ledger.Path{path}, []ledger.Payload{*ledger.NewPayload(key, value)}, true)
+			require.NoError(t, err)
+			return updated
+		}
+
+		// the checkpoint contains two tries, only the registers of the requested one are indexed
+		first := newTrie(trie.NewEmptyMTrie(), regA, []byte("a1"))
+		second := newTrie(first, regB, []byte("b2"))
+
+		fileName := "checkpoint.00000010"
+		require.NoError(t, wal.StoreCheckpointV6SingleThread([]*trie.MTrie{first, second}, dir, fileName, &log))
+		checkpointFile := filepath.Join(dir, fileName)
+
+		unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+			registers, err := bstorage.NewRegisters(db)
+			require.NoError(t, err)
+
+			height := uint64(10)
+			require.NoError(t, indexer.BootstrapFromCheckpoint(log, registers, checkpointFile, first.RootHash(), height))
+			require.True(t, registers.IsBootstrapped())
+
+			value, err := registers.Get(regA, height)
+			require.NoError(t, err)
+			assert.Equal(t, flow.RegisterValue("a1"), value)
+
+			_, err = registers.Get(regB, height)
+			require.Error(t, err)
+
+			// bootstrapping again is a no-op, even for another trie
+			require.NoError(t, indexer.BootstrapFromCheckpoint(log, registers, checkpointFile, second.RootHash(), height))
+		})
+
+		unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+			registers, err := bstorage.NewRegisters(db)
+			require.NoError(t, err)
+
+			err = indexer.BootstrapFromCheckpoint(log, registers, checkpointFile, ledger.RootHash(unittest.StateCommitmentFixture()), 10)
+			assert.ErrorIs(t, err, wal.ErrTrieNotFound)
+			assert.False(t, registers.IsBootstrapped())
+		})
+	})
+}
+
+// interruptedBootstrapper fails to store the registers after the given number of batches
+type interruptedBootstrapper struct {
+	*bstorage.Registers
+	batches   int
+	failAfter int
+}
+
+func (b *interruptedBootstrapper) StoreBootstrapEntries(height uint64, entries flow.RegisterEntries) error {
+	if b.batches == b.failAfter {
+		return fmt.Errorf("interrupted")
+	}
+	b.batches++
+	return b.Registers.StoreBootstrapEntries(height, entries)
+}
+
+func TestBootstrapFromCheckpoint_Batches(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		log := unittest.Logger()
+
+		// enough registers for a partial batch after two full batches
+		ids := make([]flow.RegisterID, 2_500)
+		paths := make([]ledger.Path, len(ids))
+		payloads := make([]ledger.Payload, len(ids))
+		for i := range ids {
+			ids[i] = flow.NewRegisterID("owner", fmt.Sprintf("key%d", i))
+			key := state.RegisterIDToKey(ids[i])
+			path, err := pathfinder.KeyToPath(key, complete.DefaultPathFinderVersion)
+			require.NoError(t, err)
+			paths[i] = path
+			payloads[i] = *ledger.NewPayload(key, []byte(fmt.Sprintf("value%d", i)))
+		}
+		rootTrie, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), paths, payloads, true)
+		require.NoError(t, err)
+
+		fileName := "checkpoint.00000010"
+		require.NoError(t, wal.StoreCheckpointV6SingleThread([]*trie.MTrie{rootTrie}, dir, fileName, &log))
+		checkpointFile := filepath.Join(dir, fileName)
+
+		unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+			registers, err := bstorage.NewRegisters(db)
+			require.NoError(t, err)
+
+			height := uint64(10)
+			interrupted := &interruptedBootstrapper{Registers: registers, failAfter: 1}
+			err = indexer.BootstrapFromCheckpoint(log, interrupted, checkpointFile, rootTrie.RootHash(), height)
+			require.Error(t, err)
+			assert.False(t, registers.IsBootstrapped())
+
+			// the interrupted bootstrap is retried on the next start
+			reopened, err := bstorage.NewRegisters(db)
+			require.NoError(t, err)
+			require.False(t, reopened.IsBootstrapped())
+
+			counting := &interruptedBootstrapper{Registers: reopened, failAfter: -1}
+			require.NoError(t, indexer.BootstrapFromCheckpoint(log, counting, checkpointFile, rootTrie.RootHash(), height))
+			assert.Equal(t, 3, counting.batches)
+			require.True(t, reopened.IsBootstrapped())
+
+			for i, id := range ids {
+				value, err := reopened.Get(id, height)
+				require.NoError(t, err)
+				assert.Equal(t, flow.RegisterValue(fmt.Sprintf("value%d", i)), value)
+			}
+		})
+	})
+}
@@ -0,0 +1,115 @@
+package indexer
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/logging"
+)
+
+// Indexer builds a height-indexed register store from the execution data received by the
+// ExecutionDataRequester. For each sealed block, it applies the register updates contained in the
+// TrieUpdates of all chunks, so that the state as of any indexed height can be read locally.
+//
+// The index must be bootstrapped (see BootstrapFromCheckpoint) with the full state at a height
+// lower than the first execution data it receives.
+type Indexer struct {
+	log       zerolog.Logger
+	registers storage.RegisterIndex
+	headers   storage.Headers
+}
+
+// New returns a new register Indexer.
+func New(log zerolog.Logger, registers storage.RegisterIndex, headers storage.Headers) *Indexer {
+	return &Indexer{
+		log:       log.With().Str("module", "register_indexer").Logger(),
+		registers: registers,
+		headers:   headers,
+	}
+}
+
+// OnExecutionData is registered as a consumer of the ExecutionDataRequester, which delivers
+// execution data for each sealed block in consecutive height order, at least once.
+func (i *Indexer) OnExecutionData(executionData *execution_data.BlockExecutionData) {
+	lg := i.log.With().Hex("block_id", logging.ID(executionData.BlockID)).Logger()
+
+	err := i.IndexBlockData(executionData)
+	if err != nil {
+		// the index can only progress in consecutive height order, so any failure leaves it in a
+		// state where it cannot serve new heights
+		lg.Fatal().Err(err).Msg("could not index execution data")
+		return
+	}
+
+	lg.Trace().Msg("indexed execution data")
+}
+
+// IndexBlockData indexes all register updates from the given block's execution data.
+// Execution data for heights that are already indexed is skipped.
+// No errors are expected during normal operation.
+func (i *Indexer) IndexBlockData(executionData *execution_data.BlockExecutionData) error {
+	header, err := i.headers.ByBlockID(executionData.BlockID)
+	if err != nil {
+		return fmt.Errorf("could not get header for block %v: %w", executionData.BlockID, err)
+	}
+
+	latest, err := i.registers.LatestHeight()
+	if err != nil {
+		return fmt.Errorf("could not get latest indexed height: %w", err)
+	}
+
+	if header.Height < latest {
+		// already indexed
+		return nil
+	}
+
+	// collect the latest value of each register. Chunks are ordered by execution, so later
+	// updates overwrite earlier ones.
+	updates := make(map[flow.RegisterID]flow.RegisterValue)
+	for _, chunk := range executionData.ChunkExecutionDatas {
+		if chunk.TrieUpdate == nil {
+			continue
+		}
+
+		for _, payload := range chunk.TrieUpdate.Payloads {
+			id, value, err := payloadToRegister(payload)
+			if err != nil {
+				return fmt.Errorf("could not convert payload for block %v: %w", executionData.BlockID, err)
+			}
+			updates[id] = value
+		}
+	}
+
+	entries := make(flow.RegisterEntries, 0, len(updates))
+	for id, value := range updates {
+		entries = append(entries, flow.RegisterEntry{Key: id, Value: value})
+	}
+
+	err = i.registers.Store(entries, header.Height)
+	if err != nil {
+		return fmt.Errorf("could not store registers for height %d: %w", header.Height, err)
+	}
+
+	return nil
+}
+
+// payloadToRegister converts a ledger payload into the register ID and value it encodes.
+func payloadToRegister(payload *ledger.Payload) (flow.RegisterID, flow.RegisterValue, error) {
+	key, err := payload.Key()
+	if err != nil {
+		return flow.RegisterID{}, nil, fmt.Errorf("could not decode payload key: %w", err)
+	}
+
+	id, err := state.KeyToRegisterID(key)
+	if err != nil {
+		return flow.RegisterID{}, nil, err
+	}
+
+	return id, payload.Value(), nil
+}
@@ -0,0 +1,104 @@
+package indexer_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/executiondatasync/execution_data"
+	"github.com/onflow/flow-go/module/state_synchronization/indexer"
+	"github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func payload(id flow.RegisterID, value []byte) *ledger.Payload {
+	return ledger.NewPayload(state.RegisterIDToKey(id), value)
+}
+
+func TestIndexer_IndexBlockData(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		registers, err := bstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		regA := flow.NewRegisterID("owner", "a")
+		regB := flow.NewRegisterID("owner", "b")
+
+		rootHeight := uint64(10)
+		require.NoError(t, registers.Bootstrap(rootHeight, flow.RegisterEntries{{Key: regA, Value: []byte("a10")}}))
+
+		headers := storagemock.NewHeaders(t)
+		idx := indexer.New(unittest.Logger(), registers, headers)
+
+		header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(rootHeight + 1))
+		headers.On("ByBlockID", header.ID()).Return(header, nil)
+
+		execData := &execution_data.BlockExecutionData{
+			BlockID: header.ID(),
+			ChunkExecutionDatas: []*execution_data.ChunkExecutionData{
+				{
+					TrieUpdate: &ledger.TrieUpdate{
+						Payloads: []*ledger.Payload{
+							payload(regA, []byte("a11-first")),
+							payload(regB, []byte("b11")),
+						},
+					},
+				},
+				// chunks without trie updates are skipped
+				{},
+				{
+					TrieUpdate: &ledger.TrieUpdate{
+						Payloads: []*ledger.Payload{
+							payload(regA, []byte("a11")),
+						},
+					},
+				},
+			},
+		}
+
+		require.NoError(t, idx.IndexBlockData(execData))
+
+		// delivering the same data again is a no-op
+		require.NoError(t, idx.IndexBlockData(execData))
+
+		latest, err := registers.LatestHeight()
+		require.NoError(t, err)
+		assert.Equal(t, rootHeight+1, latest)
+
+		// later chunks overwrite earlier updates to the same register
+		value, err := registers.Get(regA, rootHeight+1)
+		require.NoError(t, err)
+		assert.Equal(t, flow.RegisterValue("a11"), value)
+
+		value, err = registers.Get(regB, rootHeight+1)
+		require.NoError(t, err)
+		assert.Equal(t, flow.RegisterValue("b11"), value)
+
+		// the previous height still returns the bootstrapped state
+		value, err = registers.Get(regA, rootHeight)
+		require.NoError(t, err)
+		assert.Equal(t, flow.RegisterValue("a10"), value)
+
+		_, err = registers.Get(regB, rootHeight)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+func TestIndexer_UnknownBlock(t *testing.T) {
+	registers := storagemock.NewRegisterIndex(t)
+	headers := storagemock.NewHeaders(t)
+	idx := indexer.New(unittest.Logger(), registers, headers)
+
+	blockID := unittest.IdentifierFixture()
+	headers.On("ByBlockID", blockID).Return(nil, storage.ErrNotFound)
+
+	err := idx.IndexBlockData(&execution_data.BlockExecutionData{BlockID: blockID})
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// AccountTransactions is an index of the transactions each account was involved in, ordered by
+// block height and transaction index.
+type AccountTransactions interface {
+	// Store indexes the given entries. If an entry already exists for the same account and
+	// transaction, the roles of both entries are merged.
+	// No errors are expected during normal operation.
+	Store(entries []flow.AccountTransaction) error
+
+	// ByAddress returns up to limit entries for the given account, ordered from the most recent to
+	// the oldest transaction. If after is not nil, only entries older than the cursor are returned.
+	// No errors are expected during normal operation.
+	ByAddress(address flow.Address, limit uint, after *flow.AccountTransactionCursor) ([]flow.AccountTransaction, error)
+}
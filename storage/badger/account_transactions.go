@@ -0,0 +1,58 @@
+package badger
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// AccountTransactions implements storage.AccountTransactions on top of a badger database.
+type AccountTransactions struct {
+	db *badger.DB
+}
+
+var _ storage.AccountTransactions = (*AccountTransactions)(nil)
+
+// NewAccountTransactions returns a new account transaction index backed by the given database.
+func NewAccountTransactions(db *badger.DB) *AccountTransactions {
+	return &AccountTransactions{
+		db: db,
+	}
+}
+
+// Store indexes the given entries. If an entry already exists for the same account and
+// transaction, the roles of both entries are merged.
+// No errors are expected during normal operation.
+func (a *AccountTransactions) Store(entries []flow.AccountTransaction) error {
+	// entries may be written concurrently for the same transaction by different sources, so the
+	// read-modify-write of each entry is retried on conflicts
+	err := operation.RetryOnConflict(a.db.Update, func(tx *badger.Txn) error {
+		for _, entry := range entries {
+			err := operation.UpsertAccountTransaction(entry)(tx)
+			if err != nil {
+				return fmt.Errorf("could not index transaction %v for account %v: %w", entry.TransactionID, entry.Address, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not store account transactions: %w", err)
+	}
+	return nil
+}
+
+// ByAddress returns up to limit entries for the given account, ordered from the most recent to
+// the oldest transaction. If after is not nil, only entries older than the cursor are returned.
+// No errors are expected during normal operation.
+func (a *AccountTransactions) ByAddress(address flow.Address, limit uint, after *flow.AccountTransactionCursor) ([]flow.AccountTransaction, error) {
+	var entries []flow.AccountTransaction
+	err := a.db.View(operation.LookupAccountTransactions(address, limit, after, &entries))
+	if err != nil {
+		return nil, fmt.Errorf("could not lookup transactions for account %v: %w", address, err)
+	}
+	return entries, nil
+}
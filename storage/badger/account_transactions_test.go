@@ -0,0 +1,121 @@
+package badger_test
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	badgerstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestAccountTransactions_StoreAndPaginate(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		index := badgerstorage.NewAccountTransactions(db)
+
+		address := unittest.RandomAddressFixture()
+		other := unittest.RandomAddressFixture()
+
+		// entries are stored out of order, and include an entry for another account with the
+		// same position
+		positions := []flow.AccountTransactionCursor{
+			{BlockHeight: 11, TransactionIndex: 0},
+			{BlockHeight: 10, TransactionIndex: 2},
+			{BlockHeight: 12, TransactionIndex: 1},
+			{BlockHeight: 10, TransactionIndex: 0},
+			{BlockHeight: 12, TransactionIndex: 0},
+		}
+		entries := make([]flow.AccountTransaction, 0, len(positions))
+		for _, p := range positions {
+			entries = append(entries, flow.AccountTransaction{
+				Address:          address,
+				BlockHeight:      p.BlockHeight,
+				TransactionID:    unittest.IdentifierFixture(),
+				TransactionIndex: p.TransactionIndex,
+				Roles:            []flow.TransactionRole{flow.TransactionRoleAuthorizer},
+			})
+		}
+		err := index.Store(entries)
+		require.NoError(t, err)
+
+		err = index.Store([]flow.AccountTransaction{{
+			Address:          other,
+			BlockHeight:      12,
+			TransactionID:    unittest.IdentifierFixture(),
+			TransactionIndex: 1,
+			Roles:            []flow.TransactionRole{flow.TransactionRolePayer},
+		}})
+		require.NoError(t, err)
+
+		expected := []flow.AccountTransactionCursor{
+			{BlockHeight: 12, TransactionIndex: 1},
+			{BlockHeight: 12, TransactionIndex: 0},
+			{BlockHeight: 11, TransactionIndex: 0},
+			{BlockHeight: 10, TransactionIndex: 2},
+			{BlockHeight: 10, TransactionIndex: 0},
+		}
+
+		// page through the entries 2 at a time
+		var actual []flow.AccountTransactionCursor
+		var cursor *flow.AccountTransactionCursor
+		for {
+			page, err := index.ByAddress(address, 2, cursor)
+			require.NoError(t, err)
+			if len(page) == 0 {
+				break
+			}
+			for _, entry := range page {
+				assert.Equal(t, address, entry.Address)
+				actual = append(actual, entry.Cursor())
+			}
+			next := page[len(page)-1].Cursor()
+			cursor = &next
+		}
+		assert.Equal(t, expected, actual)
+
+		// all entries are returned if the limit is large enough
+		page, err := index.ByAddress(address, 100, nil)
+		require.NoError(t, err)
+		assert.Len(t, page, len(expected))
+
+		// unknown accounts have no entries
+		page, err = index.ByAddress(unittest.RandomAddressFixture(), 100, nil)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+	})
+}
+
+func TestAccountTransactions_MergeRoles(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		index := badgerstorage.NewAccountTransactions(db)
+
+		entry := flow.AccountTransaction{
+			Address:          unittest.RandomAddressFixture(),
+			BlockHeight:      10,
+			TransactionID:    unittest.IdentifierFixture(),
+			TransactionIndex: 3,
+			Roles:            []flow.TransactionRole{flow.TransactionRolePayer, flow.TransactionRoleProposer},
+		}
+		err := index.Store([]flow.AccountTransaction{entry})
+		require.NoError(t, err)
+
+		// the same transaction indexed again from events adds the interaction role
+		entry.Roles = []flow.TransactionRole{flow.TransactionRoleInteraction, flow.TransactionRolePayer}
+		err = index.Store([]flow.AccountTransaction{entry})
+		require.NoError(t, err)
+
+		page, err := index.ByAddress(entry.Address, 10, nil)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+
+		assert.Equal(t, entry.TransactionID, page[0].TransactionID)
+		assert.ElementsMatch(t, []flow.TransactionRole{
+			flow.TransactionRolePayer,
+			flow.TransactionRoleProposer,
+			flow.TransactionRoleInteraction,
+		}, page[0].Roles)
+	})
+}
@@ -122,3 +122,31 @@ func TestEventRetrieveWithoutStore(t *testing.T) {
 
 	})
 }
+
+func TestEventRemoveByBlockID(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		metrics := metrics.NewNoopCollector()
+		store := badgerstorage.NewEvents(metrics, db)
+
+		blockID := unittest.IdentifierFixture()
+		event := unittest.EventFixture(flow.EventAccountCreated, 0, 0, unittest.IdentifierFixture(), 0)
+
+		batch := badgerstorage.NewBatch(db)
+		err := store.BatchStore(blockID, []flow.EventsList{{event}}, batch)
+		require.NoError(t, err)
+		err = batch.Flush()
+		require.NoError(t, err)
+
+		events, err := store.ByBlockID(blockID)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+
+		// the removed events are evicted from the cache
+		err = store.RemoveByBlockID(blockID)
+		require.NoError(t, err)
+
+		events, err = store.ByBlockID(blockID)
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+}
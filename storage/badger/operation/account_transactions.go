@@ -0,0 +1,140 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// accountTransactionValue is the value stored for each entry of the account transaction index.
+// The address, height and transaction index are part of the key.
+type accountTransactionValue struct {
+	TransactionID flow.Identifier
+	Roles         []flow.TransactionRole
+}
+
+// accountTransactionPrefix returns the key prefix shared by all entries of the given account.
+func accountTransactionPrefix(address flow.Address) []byte {
+	return makePrefix(codeAccountTransaction, address)
+}
+
+// accountTransactionKey returns the key of the entry at the given position. Height and transaction
+// index are stored inverted, so that entries are sorted from the most recent to the oldest.
+func accountTransactionKey(address flow.Address, height uint64, txIndex uint32) []byte {
+	return makePrefix(codeAccountTransaction, address, ^height, ^txIndex)
+}
+
+// UpsertAccountTransaction indexes the given entry. If an entry already exists for the same
+// account and position, the roles of both entries are merged.
+// No errors are expected during normal operation.
+func UpsertAccountTransaction(entry flow.AccountTransaction) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		key := accountTransactionKey(entry.Address, entry.BlockHeight, entry.TransactionIndex)
+
+		var existing accountTransactionValue
+		err := retrieve(key, &existing)(tx)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("could not retrieve existing entry: %w", err)
+		}
+
+		value := accountTransactionValue{
+			TransactionID: entry.TransactionID,
+			Roles:         existing.Roles,
+		}
+		for _, role := range entry.Roles {
+			if !containsRole(value.Roles, role) {
+				value.Roles = append(value.Roles, role)
+			}
+		}
+
+		return upsert(key, value)(tx)
+	}
+}
+
+// LookupAccountTransactions retrieves up to limit entries of the given account, ordered from the
+// most recent to the oldest. If after is not nil, only entries older than the cursor are returned.
+// No errors are expected during normal operation.
+func LookupAccountTransactions(
+	address flow.Address,
+	limit uint,
+	after *flow.AccountTransactionCursor,
+	entries *[]flow.AccountTransaction,
+) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		prefix := accountTransactionPrefix(address)
+
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		seek := prefix
+		var skip []byte
+		if after != nil {
+			seek = accountTransactionKey(address, after.BlockHeight, after.TransactionIndex)
+			skip = seek
+		}
+
+		result := make([]flow.AccountTransaction, 0)
+		for it.Seek(seek); it.ValidForPrefix(prefix) && uint(len(result)) < limit; it.Next() {
+			item := it.Item()
+			key := item.KeyCopy(nil)
+
+			// the cursor points at the last entry that was already returned
+			if skip != nil && bytes.Equal(key, skip) {
+				continue
+			}
+
+			height, txIndex, err := decodeAccountTransactionKey(key, len(prefix))
+			if err != nil {
+				return err
+			}
+
+			var value accountTransactionValue
+			err = item.Value(func(val []byte) error {
+				return msgpack.Unmarshal(val, &value)
+			})
+			if err != nil {
+				return fmt.Errorf("could not decode account transaction: %w", err)
+			}
+
+			result = append(result, flow.AccountTransaction{
+				Address:          address,
+				BlockHeight:      height,
+				TransactionID:    value.TransactionID,
+				TransactionIndex: txIndex,
+				Roles:            value.Roles,
+			})
+		}
+
+		*entries = result
+		return nil
+	}
+}
+
+// decodeAccountTransactionKey returns the height and transaction index encoded in the key.
+func decodeAccountTransactionKey(key []byte, prefixLen int) (uint64, uint32, error) {
+	if len(key) != prefixLen+12 {
+		return 0, 0, fmt.Errorf("invalid account transaction key length: %d", len(key))
+	}
+	height := binary.BigEndian.Uint64(key[prefixLen : prefixLen+8])
+	txIndex := binary.BigEndian.Uint32(key[prefixLen+8:])
+	return ^height, ^txIndex, nil
+}
+
+func containsRole(roles []flow.TransactionRole, role flow.TransactionRole) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
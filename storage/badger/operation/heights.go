@@ -55,3 +55,15 @@ func UpdateLastCompleteBlockHeight(height uint64) func(*badger.Txn) error {
 func RetrieveLastCompleteBlockHeight(height *uint64) func(*badger.Txn) error {
 	return retrieve(makePrefix(codeLastCompleteBlockHeight), height)
 }
+
+func InsertExecutionStatePrunedHeight(height uint64) func(*badger.Txn) error {
+	return insert(makePrefix(codeExecutionStatePruned), height)
+}
+
+func UpdateExecutionStatePrunedHeight(height uint64) func(*badger.Txn) error {
+	return update(makePrefix(codeExecutionStatePruned), height)
+}
+
+func RetrieveExecutionStatePrunedHeight(height *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeExecutionStatePruned), height)
+}
@@ -107,3 +107,27 @@ func TestLastCompleteBlockHeightInsertIfNotExists(t *testing.T) {
 		assert.Equal(t, retrieved, height1)
 	})
 }
+
+func TestExecutionStatePrunedHeightInsertUpdateRetrieve(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		height := uint64(1337)
+
+		err := db.Update(InsertExecutionStatePrunedHeight(height))
+		require.Nil(t, err)
+
+		var retrieved uint64
+		err = db.View(RetrieveExecutionStatePrunedHeight(&retrieved))
+		require.Nil(t, err)
+
+		assert.Equal(t, retrieved, height)
+
+		height = 9999
+		err = db.Update(UpdateExecutionStatePrunedHeight(height))
+		require.Nil(t, err)
+
+		err = db.View(RetrieveExecutionStatePrunedHeight(&retrieved))
+		require.Nil(t, err)
+
+		assert.Equal(t, retrieved, height)
+	})
+}
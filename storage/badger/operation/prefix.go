@@ -31,6 +31,7 @@ const (
 	codeExecutedBlock           = 23 // latest executed block with max height
 	codeRootHeight              = 24 // the height of the first loaded block
 	codeLastCompleteBlockHeight = 25 // the height of the last block for which all collections were received
+	codeExecutionStatePruned    = 26 // the height up to which historical execution data was pruned
 
 	// codes for single entity storage
 	// 31 was used for identities before epochs
@@ -96,10 +97,19 @@ const (
 	codeIndexCollectionByTransaction = 203
 	codeIndexResultApprovalByChunk   = 204
 
+	// registers indexed by height on access/observer nodes
+	codeRegister             = 206
+	codeRegisterFirstHeight  = 207
+	codeRegisterLatestHeight = 208
+
+	// transactions indexed by the accounts involved on access nodes
+	codeAccountTransaction = 209
+
 	// TEMPORARY codes
 	blockedNodeIDs = 205 // manual override for adding node IDs to list of ejected nodes, applies to networking layer only
 
 	// internal failure information that should be preserved across restarts
+	codeExecutionForkedBlock            = 253 // block whose own execution result conflicts with its sealed result on execution nodes
 	codeExecutionFork                   = 254
 	codeEpochEmergencyFallbackTriggered = 255
 )
@@ -131,6 +141,8 @@ func b(v interface{}) []byte {
 		return []byte{byte(i)}
 	case flow.Identifier:
 		return i[:]
+	case flow.Address:
+		return i[:]
 	case flow.ChainID:
 		return []byte(i)
 	default:
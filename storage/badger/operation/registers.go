@@ -0,0 +1,99 @@
+package operation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/vmihailenco/msgpack/v4"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// registerPrefix returns the key prefix shared by all values of the given register. Owner and key
+// are length prefixed, so the prefix of one register is never the prefix of another register.
+func registerPrefix(id flow.RegisterID) []byte {
+	return makePrefix(codeRegister, uint32(len(id.Owner)), id.Owner, uint32(len(id.Key)), id.Key)
+}
+
+// registerKey returns the key for the value of the register at the given height. The height is
+// stored inverted, so that values are sorted from the highest to the lowest height.
+func registerKey(id flow.RegisterID, height uint64) []byte {
+	return append(registerPrefix(id), b(^height)...)
+}
+
+// BatchInsertRegister inserts the value of the register at the given height, overwriting any
+// existing value for the same height.
+func BatchInsertRegister(height uint64, entry flow.RegisterEntry) func(batch *badger.WriteBatch) error {
+	return batchWrite(registerKey(entry.Key, height), entry.Value)
+}
+
+// RetrieveRegister retrieves the value of the register as of the given height, which is the value
+// stored at the highest height less than or equal to the provided height.
+// Expected errors:
+// - storage.ErrNotFound if the register has no value at or below the height
+func RetrieveRegister(id flow.RegisterID, height uint64, value *flow.RegisterValue) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		prefix := registerPrefix(id)
+
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		opts.PrefetchValues = false
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		// since heights are inverted, the first key at or after the seek key is the value at the
+		// highest height less than or equal to the requested height
+		it.Seek(registerKey(id, height))
+		if !it.ValidForPrefix(prefix) {
+			return storage.ErrNotFound
+		}
+
+		err := it.Item().Value(func(val []byte) error {
+			return msgpack.Unmarshal(val, value)
+		})
+		if err != nil {
+			return fmt.Errorf("could not decode register value: %w", err)
+		}
+
+		return nil
+	}
+}
+
+func InsertRegisterFirstHeight(height uint64) func(*badger.Txn) error {
+	return insert(makePrefix(codeRegisterFirstHeight), height)
+}
+
+func RetrieveRegisterFirstHeight(height *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeRegisterFirstHeight), height)
+}
+
+func InsertRegisterLatestHeight(height uint64) func(*badger.Txn) error {
+	return insert(makePrefix(codeRegisterLatestHeight), height)
+}
+
+func UpdateRegisterLatestHeight(height uint64) func(*badger.Txn) error {
+	return update(makePrefix(codeRegisterLatestHeight), height)
+}
+
+func RetrieveRegisterLatestHeight(height *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeRegisterLatestHeight), height)
+}
+
+// IsRegisterIndexBootstrapped returns whether the register index has been bootstrapped.
+func IsRegisterIndexBootstrapped(bootstrapped *bool) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		_, err := tx.Get(makePrefix(codeRegisterFirstHeight))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			*bootstrapped = false
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not check register index first height: %w", err)
+		}
+		*bootstrapped = true
+		return nil
+	}
+}
@@ -75,3 +75,17 @@ func RemoveExecutionForkEvidence() func(*badger.Txn) error {
 func RetrieveExecutionForkEvidence(conflictingSeals *[]*flow.IncorporatedResultSeal) func(*badger.Txn) error {
 	return retrieve(makePrefix(codeExecutionFork), conflictingSeals)
 }
+
+// InsertExecutionForkedBlock persists the ID of the block whose execution result computed by this
+// execution node conflicts with the block's sealed result, so that execution stays paused across restarts.
+func InsertExecutionForkedBlock(blockID flow.Identifier) func(*badger.Txn) error {
+	return insert(makePrefix(codeExecutionForkedBlock), blockID)
+}
+
+func RemoveExecutionForkedBlock() func(*badger.Txn) error {
+	return remove(makePrefix(codeExecutionForkedBlock))
+}
+
+func RetrieveExecutionForkedBlock(blockID *flow.Identifier) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeExecutionForkedBlock), blockID)
+}
@@ -72,7 +72,8 @@ func LookupTransactionResultsByBlockIDUsingIndex(blockID flow.Identifier, txResu
 	return traverse(makePrefix(codeTransactionResultIndex, blockID), txErrIterFunc)
 }
 
-// RemoveTransactionResultsByBlockID removes the transaction results for the given blockID
+// RemoveTransactionResultsByBlockID removes the transaction results for the given blockID,
+// including the index of the results by transaction index
 func RemoveTransactionResultsByBlockID(blockID flow.Identifier) func(*badger.Txn) error {
 	return func(txn *badger.Txn) error {
 
@@ -82,6 +83,12 @@ func RemoveTransactionResultsByBlockID(blockID flow.Identifier) func(*badger.Txn
 			return fmt.Errorf("could not remove transaction results for block %v: %w", blockID, err)
 		}
 
+		prefix = makePrefix(codeTransactionResultIndex, blockID)
+		err = removeByPrefix(prefix)(txn)
+		if err != nil {
+			return fmt.Errorf("could not remove transaction result indices for block %v: %w", blockID, err)
+		}
+
 		return nil
 	}
 }
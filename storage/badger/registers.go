@@ -0,0 +1,217 @@
+package badger
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"go.uber.org/atomic"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// Registers implements storage.RegisterIndex on top of a badger database. The index must be
+// bootstrapped with the full register set at a first height before updates can be stored.
+type Registers struct {
+	db           *badger.DB
+	bootstrapped *atomic.Bool
+	firstHeight  *atomic.Uint64
+	latestHeight *atomic.Uint64
+}
+
+var _ storage.RegisterIndex = (*Registers)(nil)
+
+// NewRegisters returns a new register index backed by the given database.
+// No errors are expected during normal operation.
+func NewRegisters(db *badger.DB) (*Registers, error) {
+	r := &Registers{
+		db:           db,
+		bootstrapped: atomic.NewBool(false),
+		firstHeight:  atomic.NewUint64(0),
+		latestHeight: atomic.NewUint64(0),
+	}
+
+	var bootstrapped bool
+	err := db.View(operation.IsRegisterIndexBootstrapped(&bootstrapped))
+	if err != nil {
+		return nil, fmt.Errorf("could not check if register index is bootstrapped: %w", err)
+	}
+	if !bootstrapped {
+		return r, nil
+	}
+
+	var firstHeight, latestHeight uint64
+	err = db.View(func(tx *badger.Txn) error {
+		if err := operation.RetrieveRegisterFirstHeight(&firstHeight)(tx); err != nil {
+			return fmt.Errorf("could not retrieve first height: %w", err)
+		}
+		if err := operation.RetrieveRegisterLatestHeight(&latestHeight)(tx); err != nil {
+			return fmt.Errorf("could not retrieve latest height: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.firstHeight.Store(firstHeight)
+	r.latestHeight.Store(latestHeight)
+	r.bootstrapped.Store(true)
+
+	return r, nil
+}
+
+// IsBootstrapped returns whether the index was bootstrapped.
+func (r *Registers) IsBootstrapped() bool {
+	return r.bootstrapped.Load()
+}
+
+// Bootstrap initializes the index with the complete set of registers at the given height, which
+// becomes the first indexed height. It is the same as storing all registers with a single call to
+// StoreBootstrapEntries, and then calling FinishBootstrap.
+// Expected errors:
+// - storage.ErrAlreadyExists if the index was already bootstrapped
+func (r *Registers) Bootstrap(height uint64, entries flow.RegisterEntries) error {
+	err := r.StoreBootstrapEntries(height, entries)
+	if err != nil {
+		return err
+	}
+
+	return r.FinishBootstrap(height)
+}
+
+// StoreBootstrapEntries stores a part of the register set the index is bootstrapped with at the
+// given height, so that large register sets can be stored in batches. The index is only
+// bootstrapped once FinishBootstrap is called. If bootstrapping is interrupted before, the index
+// isn't bootstrapped when it is reopened, and the registers can be stored again.
+// Expected errors:
+// - storage.ErrAlreadyExists if the index was already bootstrapped
+func (r *Registers) StoreBootstrapEntries(height uint64, entries flow.RegisterEntries) error {
+	if r.bootstrapped.Load() {
+		return storage.ErrAlreadyExists
+	}
+
+	return r.storeEntries(height, entries)
+}
+
+// FinishBootstrap marks the index as bootstrapped at the given height, which becomes the first
+// indexed height. All registers must have been stored with StoreBootstrapEntries at this height.
+// Expected errors:
+// - storage.ErrAlreadyExists if the index was already bootstrapped
+func (r *Registers) FinishBootstrap(height uint64) error {
+	if r.bootstrapped.Load() {
+		return storage.ErrAlreadyExists
+	}
+
+	// heights are written after all registers, so a partially bootstrapped index is never used
+	err := r.db.Update(func(tx *badger.Txn) error {
+		if err := operation.InsertRegisterFirstHeight(height)(tx); err != nil {
+			return fmt.Errorf("could not insert first height: %w", err)
+		}
+		if err := operation.InsertRegisterLatestHeight(height)(tx); err != nil {
+			return fmt.Errorf("could not insert latest height: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not bootstrap register index heights: %w", err)
+	}
+
+	r.firstHeight.Store(height)
+	r.latestHeight.Store(height)
+	r.bootstrapped.Store(true)
+
+	return nil
+}
+
+// Get returns the value of the register as of the given height.
+// Expected errors:
+// - storage.ErrHeightNotIndexed if the height is outside of the indexed range
+// - storage.ErrNotFound if the register has no value as of the height
+func (r *Registers) Get(id flow.RegisterID, height uint64) (flow.RegisterValue, error) {
+	if !r.bootstrapped.Load() || height < r.firstHeight.Load() || height > r.latestHeight.Load() {
+		return nil, fmt.Errorf("height %d is not indexed: %w", height, storage.ErrHeightNotIndexed)
+	}
+
+	var value flow.RegisterValue
+	err := r.db.View(operation.RetrieveRegister(id, height, &value))
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve register (%x, %x) at height %d: %w", id.Owner, id.Key, height, err)
+	}
+
+	return value, nil
+}
+
+// FirstHeight returns the lowest height for which registers are indexed.
+// Expected errors:
+// - storage.ErrHeightNotIndexed if the index was not bootstrapped
+func (r *Registers) FirstHeight() (uint64, error) {
+	if !r.bootstrapped.Load() {
+		return 0, storage.ErrHeightNotIndexed
+	}
+	return r.firstHeight.Load(), nil
+}
+
+// LatestHeight returns the highest height for which registers are indexed.
+// Expected errors:
+// - storage.ErrHeightNotIndexed if the index was not bootstrapped
+func (r *Registers) LatestHeight() (uint64, error) {
+	if !r.bootstrapped.Load() {
+		return 0, storage.ErrHeightNotIndexed
+	}
+	return r.latestHeight.Load(), nil
+}
+
+// Store indexes the register updates for the given height.
+// Expected errors:
+// - storage.ErrHeightNotIndexed if the index was not bootstrapped
+// - error if the height is not consecutive to the latest height
+func (r *Registers) Store(entries flow.RegisterEntries, height uint64) error {
+	if !r.bootstrapped.Load() {
+		return storage.ErrHeightNotIndexed
+	}
+
+	latest := r.latestHeight.Load()
+	if height != latest && height != latest+1 {
+		return fmt.Errorf("must store registers with the next height %d, but got %d", latest+1, height)
+	}
+
+	err := r.storeEntries(height, entries)
+	if err != nil {
+		return err
+	}
+
+	if height == latest {
+		// re-indexing the latest height, e.g. after a crash before the height was persisted
+		return nil
+	}
+
+	err = operation.RetryOnConflict(r.db.Update, operation.UpdateRegisterLatestHeight(height))
+	if err != nil {
+		return fmt.Errorf("could not update latest height: %w", err)
+	}
+	r.latestHeight.Store(height)
+
+	return nil
+}
+
+// storeEntries writes the register entries for the given height in a single write batch.
+func (r *Registers) storeEntries(height uint64, entries flow.RegisterEntries) error {
+	batch := NewBatch(r.db)
+	writeBatch := batch.GetWriter()
+
+	for _, entry := range entries {
+		err := operation.BatchInsertRegister(height, entry)(writeBatch)
+		if err != nil {
+			return fmt.Errorf("could not add register (%x, %x) to batch: %w", entry.Key.Owner, entry.Key.Key, err)
+		}
+	}
+
+	err := batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not store registers at height %d: %w", height, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package badger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	badgerstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestRegisters_NotBootstrapped(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		registers, err := badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+		assert.False(t, registers.IsBootstrapped())
+
+		_, err = registers.Get(flow.NewRegisterID("owner", "key"), 10)
+		assert.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+
+		err = registers.Store(flow.RegisterEntries{}, 10)
+		assert.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+	})
+}
+
+func TestRegisters_StoreAndGet(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		registers, err := badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		regA := flow.NewRegisterID("owner", "a")
+		regB := flow.NewRegisterID("owner", "b")
+		// same owner and key bytes split differently must not collide
+		regC := flow.NewRegisterID("ownera", "")
+
+		rootHeight := uint64(100)
+		err = registers.Bootstrap(rootHeight, flow.RegisterEntries{
+			{Key: regA, Value: []byte("a100")},
+			{Key: regC, Value: []byte("c100")},
+		})
+		require.NoError(t, err)
+
+		err = registers.Bootstrap(rootHeight, flow.RegisterEntries{})
+		assert.ErrorIs(t, err, storage.ErrAlreadyExists)
+
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: regB, Value: []byte("b101")}}, rootHeight+1))
+		require.NoError(t, registers.Store(flow.RegisterEntries{}, rootHeight+2))
+		require.NoError(t, registers.Store(flow.RegisterEntries{{Key: regA, Value: []byte("a103")}}, rootHeight+3))
+
+		// heights must be consecutive
+		err = registers.Store(flow.RegisterEntries{}, rootHeight+5)
+		assert.Error(t, err)
+
+		latest, err := registers.LatestHeight()
+		require.NoError(t, err)
+		assert.Equal(t, rootHeight+3, latest)
+
+		tests := []struct {
+			id       flow.RegisterID
+			height   uint64
+			expected []byte
+			err      error
+		}{
+			{id: regA, height: rootHeight, expected: []byte("a100")},
+			{id: regA, height: rootHeight + 2, expected: []byte("a100")},
+			{id: regA, height: rootHeight + 3, expected: []byte("a103")},
+			{id: regB, height: rootHeight, err: storage.ErrNotFound},
+			{id: regB, height: rootHeight + 1, expected: []byte("b101")},
+			{id: regB, height: rootHeight + 3, expected: []byte("b101")},
+			{id: regC, height: rootHeight + 3, expected: []byte("c100")},
+			{id: regA, height: rootHeight - 1, err: storage.ErrHeightNotIndexed},
+			{id: regA, height: rootHeight + 4, err: storage.ErrHeightNotIndexed},
+		}
+
+		for _, test := range tests {
+			value, err := registers.Get(test.id, test.height)
+			if test.err != nil {
+				assert.True(t, errors.Is(err, test.err), "unexpected error for %v at %d: %v", test.id, test.height, err)
+				continue
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		}
+
+		// the index state is restored when reopened
+		reopened, err := badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+		first, err := reopened.FirstHeight()
+		require.NoError(t, err)
+		assert.Equal(t, rootHeight, first)
+		latest, err = reopened.LatestHeight()
+		require.NoError(t, err)
+		assert.Equal(t, rootHeight+3, latest)
+	})
+}
+
+func TestRegisters_InterruptedBootstrap(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		registers, err := badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		regA := flow.NewRegisterID("owner", "a")
+		regB := flow.NewRegisterID("owner", "b")
+		rootHeight := uint64(100)
+
+		// the first batch is stored, but bootstrapping stops before it is finished
+		err = registers.StoreBootstrapEntries(rootHeight, flow.RegisterEntries{{Key: regA, Value: []byte("a100")}})
+		require.NoError(t, err)
+		assert.False(t, registers.IsBootstrapped())
+
+		reopened, err := badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+		assert.False(t, reopened.IsBootstrapped())
+
+		_, err = reopened.Get(regA, rootHeight)
+		assert.ErrorIs(t, err, storage.ErrHeightNotIndexed)
+
+		// bootstrapping is retried from the start
+		require.NoError(t, reopened.StoreBootstrapEntries(rootHeight, flow.RegisterEntries{{Key: regA, Value: []byte("a100")}}))
+		require.NoError(t, reopened.StoreBootstrapEntries(rootHeight, flow.RegisterEntries{{Key: regB, Value: []byte("b100")}}))
+		require.NoError(t, reopened.FinishBootstrap(rootHeight))
+		assert.True(t, reopened.IsBootstrapped())
+
+		value, err := reopened.Get(regB, rootHeight)
+		require.NoError(t, err)
+		assert.Equal(t, flow.RegisterValue("b100"), value)
+
+		err = reopened.StoreBootstrapEntries(rootHeight, flow.RegisterEntries{})
+		assert.ErrorIs(t, err, storage.ErrAlreadyExists)
+		err = reopened.FinishBootstrap(rootHeight)
+		assert.ErrorIs(t, err, storage.ErrAlreadyExists)
+	})
+}
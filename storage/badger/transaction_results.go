@@ -233,7 +233,26 @@ func (tr *TransactionResults) ByBlockID(blockID flow.Identifier) ([]flow.Transac
 	return transactionResults, nil
 }
 
-// RemoveByBlockID removes transaction results by block ID
+// RemoveByBlockID removes transaction results by block ID, and evicts them from the caches
 func (tr *TransactionResults) RemoveByBlockID(blockID flow.Identifier) error {
-	return tr.db.Update(operation.RemoveTransactionResultsByBlockID(blockID))
+	var txResults []flow.TransactionResult
+	err := tr.db.Update(func(tx *badger.Txn) error {
+		// the transaction IDs of the results are needed to evict them from the cache
+		err := operation.LookupTransactionResultsByBlockIDUsingIndex(blockID, &txResults)(tx)
+		if err != nil {
+			return fmt.Errorf("could not look up transaction results: %w", err)
+		}
+		return operation.RemoveTransactionResultsByBlockID(blockID)(tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, result := range txResults {
+		tr.cache.Remove(KeyFromBlockIDTransactionID(blockID, result.TransactionID))
+		tr.indexCache.Remove(KeyFromBlockIDIndex(blockID, uint32(i)))
+	}
+	tr.blockCache.Remove(KeyFromBlockID(blockID))
+
+	return nil
 }
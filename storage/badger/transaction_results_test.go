@@ -67,6 +67,45 @@ func TestBatchStoringTransactionResults(t *testing.T) {
 	})
 }
 
+func TestRemovingTransactionResults(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		metrics := metrics.NewNoopCollector()
+		store := bstorage.NewTransactionResults(metrics, db, 1000)
+
+		blockID := unittest.IdentifierFixture()
+		txResults := make([]flow.TransactionResult, 0)
+		for i := 0; i < 10; i++ {
+			txResults = append(txResults, flow.TransactionResult{
+				TransactionID: unittest.IdentifierFixture(),
+			})
+		}
+		writeBatch := bstorage.NewBatch(db)
+		err := store.BatchStore(blockID, txResults, writeBatch)
+		require.NoError(t, err)
+
+		err = writeBatch.Flush()
+		require.NoError(t, err)
+
+		_, err = store.ByBlockID(blockID)
+		require.NoError(t, err)
+
+		err = store.RemoveByBlockID(blockID)
+		require.NoError(t, err)
+
+		// both the results and their index are removed, including from the caches
+		for i, txResult := range txResults {
+			_, err := store.ByBlockIDTransactionID(blockID, txResult.TransactionID)
+			assert.ErrorIs(t, err, storage.ErrNotFound)
+
+			_, err = store.ByBlockIDTransactionIndex(blockID, uint32(i))
+			assert.ErrorIs(t, err, storage.ErrNotFound)
+		}
+		results, err := store.ByBlockID(blockID)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
 func TestReadingNotStoreTransaction(t *testing.T) {
 	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
 		metrics := metrics.NewNoopCollector()
@@ -14,4 +14,8 @@ var (
 
 	ErrAlreadyExists = errors.New("key already exists")
 	ErrDataMismatch  = errors.New("data for key is different")
+
+	// ErrHeightNotIndexed is returned when data that is indexed sequentially is queried by a
+	// given block height, and that data is unavailable (e.g. outside the indexed range).
+	ErrHeightNotIndexed = errors.New("data for block height not available")
 )
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// RegisterIndex is a height-indexed store of register values. For every register, it stores the
+// value at each height where the register was updated, which allows reading the state as of any
+// indexed height.
+type RegisterIndex interface {
+	// Get returns the value of the register as of the given height, which is the value stored at
+	// the highest height less than or equal to the provided height.
+	// Expected errors:
+	// - storage.ErrHeightNotIndexed if the height is outside of the indexed range
+	// - storage.ErrNotFound if the register has no value as of the height
+	Get(ID flow.RegisterID, height uint64) (flow.RegisterValue, error)
+
+	// LatestHeight returns the highest height for which registers are indexed.
+	LatestHeight() (uint64, error)
+
+	// FirstHeight returns the lowest height for which registers are indexed.
+	FirstHeight() (uint64, error)
+
+	// Store indexes the register updates for the given height. The height must be exactly one
+	// greater than the latest indexed height, but storing the latest height again is allowed
+	// and overwrites the previous entries.
+	// Expected errors:
+	// - storage.ErrHeightNotIndexed if the index was not bootstrapped
+	// - error if the height is not consecutive to the latest height
+	Store(entries flow.RegisterEntries, height uint64) error
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/tracing"
 	"github.com/onflow/flow-go/model/flow"
 )
 
@@ -72,6 +73,33 @@ func (d *RemoteDebugger) RunTransactionAtBlockID(txBody *flow.TransactionBody, b
 	return tx.Err, nil
 }
 
+// TraceTransactionAtBlockID runs the transaction the same way as RunTransactionAtBlockID, and
+// returns the execution trace of the transaction, which records the register reads and writes,
+// contract function invocations, emitted events and metering charges of the execution.
+func (d *RemoteDebugger) TraceTransactionAtBlockID(txBody *flow.TransactionBody, blockID flow.Identifier, regCachePath string) (trace *tracing.ExecutionTrace, txErr, processError error) {
+	view := NewRemoteView(d.grpcAddress, WithBlockID(blockID))
+	defer view.Done()
+
+	trace = tracing.NewExecutionTrace()
+	blockCtx := fvm.NewContextFromParent(
+		d.ctx,
+		fvm.WithBlockHeader(d.ctx.BlockHeader),
+		fvm.WithExecutionTrace(trace))
+	if len(regCachePath) > 0 {
+		view.Cache = newFileRegisterCache(regCachePath)
+	}
+	tx := fvm.Transaction(txBody, 0)
+	err := d.vm.Run(blockCtx, tx, view)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = view.Cache.Persist()
+	if err != nil {
+		return nil, nil, err
+	}
+	return trace, tx.Err, nil
+}
+
 func (d *RemoteDebugger) RunScript(code []byte, arguments [][]byte) (value cadence.Value, scriptError, processError error) {
 	view := NewRemoteView(d.grpcAddress)
 	scriptCtx := fvm.NewContextFromParent(d.ctx, fvm.WithBlockHeader(d.ctx.BlockHeader))